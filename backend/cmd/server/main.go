@@ -12,6 +12,7 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"go.temporal.io/sdk/client"
 	"gorm.io/gorm"
 
 	// Internal Packages - API Handlers
@@ -24,6 +25,7 @@ import (
 	"wowperf/internal/api/raiderio"
 	userHandler "wowperf/internal/api/user"
 	apiWarcraftlogs "wowperf/internal/api/warcraftlogs"
+	wclAuthHandler "wowperf/internal/api/warcraftlogs/auth"
 
 	// Internal Packages - Services
 	auth "wowperf/internal/services/auth"
@@ -37,8 +39,10 @@ import (
 	mythicplusUpdate "wowperf/internal/services/raiderio/mythicplus"
 	userService "wowperf/internal/services/user"
 	warcraftlogs "wowperf/internal/services/warcraftlogs"
+	wclAuth "wowperf/internal/services/warcraftlogs/auth"
 	warcraftLogsLeaderboard "wowperf/internal/services/warcraftlogs/dungeons"
 	warcraftLogsMythicPlusBuildAnalysis "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
+	warcraftLogsBuildsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
 
 	// Internal Packages - Database
 	"wowperf/internal/database"
@@ -57,6 +61,7 @@ type AppServices struct {
 	Auth                         *auth.AuthService
 	GoogleAuth                   *googleauthService.GoogleAuthService
 	BattleNet                    *bnetAuth.BattleNetAuthService
+	WarcraftLogsAuth             *wclAuth.WarcraftLogsAuthService
 	User                         *userService.UserService
 	Blizzard                     *serviceBlizzard.Service
 	Character                    characterService.CharacterServiceInterface
@@ -66,6 +71,10 @@ type AppServices struct {
 	LeaderboardAnalysis          *warcraftLogsLeaderboard.GlobalLeaderboardAnalysisService
 	RankingsUpdater              *warcraftLogsLeaderboard.RankingsUpdater
 	MythicPlusBuildsAnalysis     *warcraftLogsMythicPlusBuildAnalysis.BuildAnalysisService
+	MythicPlusDungeonsAnalysis   *warcraftLogsMythicPlusBuildAnalysis.DungeonAnalysisService
+	ReportAnalysis               *warcraftLogsMythicPlusBuildAnalysis.ReportAnalysisService
+	RaidAnalysis                 *warcraftLogsMythicPlusBuildAnalysis.RaidAnalysisService
+	PlayerAnalysis               *warcraftLogsMythicPlusBuildAnalysis.PlayerAnalysisService
 	SpecEvolutionMetricsAnalysis *warcraftLogsLeaderboard.SpecEvolutionMetricsAnalysisService
 }
 
@@ -75,6 +84,7 @@ type AppHandlers struct {
 	GoogleAuth       *googleauthHandler.GoogleAuthHandler
 	User             *userHandler.UserHandler
 	BattleNet        *bnetAuthHandler.BattleNetAuthHandler
+	WarcraftLogsAuth *wclAuthHandler.WarcraftLogsAuthHandler
 	Characters       *charactersHandler.CharactersHandler
 	RaiderIO         *raiderio.Handler
 	Blizzard         *apiBlizzard.Handler
@@ -111,6 +121,9 @@ func initializeServices(db *gorm.DB, cacheService cache.CacheService, cacheManag
 		return nil, fmt.Errorf("failed to initialize battle.net auth service: %w", err)
 	}
 
+	// WarcraftLogs OAuth service for the private reports
+	warcraftLogsAuthService := wclAuth.NewWarcraftLogsAuthService(db, redisClient)
+
 	emailConfig, err := email.NewConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize email config: %w", err)
@@ -164,6 +177,23 @@ func initializeServices(db *gorm.DB, cacheService cache.CacheService, cacheManag
 	globalLeaderboardService := warcraftLogsLeaderboard.NewGlobalLeaderboardService(db)
 	globalLeaderboardAnalysisService := warcraftLogsLeaderboard.NewGlobalLeaderboardAnalysisService(db)
 	mythicPlusBuildsAnalysisService := warcraftLogsMythicPlusBuildAnalysis.NewBuildAnalysisService(db)
+	mythicPlusDungeonsAnalysisService := warcraftLogsMythicPlusBuildAnalysis.NewDungeonAnalysisService(db)
+
+	// Temporal client for the on-demand report analyses, connected on the first workflow submitted
+	temporalAddress := os.Getenv("TEMPORAL_ADDRESS")
+	if temporalAddress == "" {
+		temporalAddress = "localhost:7233"
+	}
+	temporalClient, err := client.NewLazyClient(client.Options{
+		HostPort:  temporalAddress,
+		Namespace: warcraftLogsBuildsModels.DefaultNamespace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize temporal client: %w", err)
+	}
+	reportAnalysisService := warcraftLogsMythicPlusBuildAnalysis.NewReportAnalysisService(db, temporalClient)
+	raidAnalysisService := warcraftLogsMythicPlusBuildAnalysis.NewRaidAnalysisService(db)
+	playerAnalysisService := warcraftLogsMythicPlusBuildAnalysis.NewPlayerAnalysisService(db)
 	specEvolutionMetricsAnalysisService := warcraftLogsLeaderboard.NewSpecEvolutionMetricsAnalysisService(db)
 	rankingsUpdater := warcraftLogsLeaderboard.NewRankingsUpdater(
 		db,
@@ -176,6 +206,7 @@ func initializeServices(db *gorm.DB, cacheService cache.CacheService, cacheManag
 		Auth:                         authService,
 		GoogleAuth:                   googleAuthService,
 		BattleNet:                    battleNetService,
+		WarcraftLogsAuth:             warcraftLogsAuthService,
 		User:                         userSvc,
 		Blizzard:                     blizzardService,
 		Character:                    characterSvc,
@@ -185,6 +216,10 @@ func initializeServices(db *gorm.DB, cacheService cache.CacheService, cacheManag
 		LeaderboardAnalysis:          globalLeaderboardAnalysisService,
 		RankingsUpdater:              rankingsUpdater,
 		MythicPlusBuildsAnalysis:     mythicPlusBuildsAnalysisService,
+		MythicPlusDungeonsAnalysis:   mythicPlusDungeonsAnalysisService,
+		ReportAnalysis:               reportAnalysisService,
+		RaidAnalysis:                 raidAnalysisService,
+		PlayerAnalysis:               playerAnalysisService,
 		SpecEvolutionMetricsAnalysis: specEvolutionMetricsAnalysisService,
 	}, nil
 }
@@ -192,18 +227,23 @@ func initializeServices(db *gorm.DB, cacheService cache.CacheService, cacheManag
 // Initialisation des handlers
 func initializeHandlers(services *AppServices, db *gorm.DB, cacheService cache.CacheService, cacheManagers CacheManagers) *AppHandlers {
 	return &AppHandlers{
-		Auth:       authHandler.NewAuthHandler(services.Auth),
-		GoogleAuth: googleauthHandler.NewGoogleAuthHandler(services.GoogleAuth, services.Auth),
-		User:       userHandler.NewUserHandler(services.User),
-		BattleNet:  bnetAuthHandler.NewBattleNetAuthHandler(services.BattleNet),
-		Characters: charactersHandler.NewCharactersHandler(services.Character, services.Blizzard),
-		RaiderIO:   raiderio.NewHandler(services.RaiderIO, db, cacheService, cacheManagers.RaiderIO),
-		Blizzard:   apiBlizzard.NewHandler(services.Blizzard, db, cacheService, cacheManagers.Blizzard),
+		Auth:             authHandler.NewAuthHandler(services.Auth),
+		GoogleAuth:       googleauthHandler.NewGoogleAuthHandler(services.GoogleAuth, services.Auth),
+		User:             userHandler.NewUserHandler(services.User),
+		BattleNet:        bnetAuthHandler.NewBattleNetAuthHandler(services.BattleNet),
+		WarcraftLogsAuth: wclAuthHandler.NewWarcraftLogsAuthHandler(services.WarcraftLogsAuth, services.ReportAnalysis),
+		Characters:       charactersHandler.NewCharactersHandler(services.Character, services.Blizzard),
+		RaiderIO:         raiderio.NewHandler(services.RaiderIO, db, cacheService, cacheManagers.RaiderIO),
+		Blizzard:         apiBlizzard.NewHandler(services.Blizzard, db, cacheService, cacheManagers.Blizzard),
 		WarcraftLogs: apiWarcraftlogs.NewHandler(
 			services.LeaderBoard,
 			services.LeaderboardAnalysis,
 			services.MythicPlusBuildsAnalysis,
+			services.MythicPlusDungeonsAnalysis,
 			services.SpecEvolutionMetricsAnalysis,
+			services.ReportAnalysis,
+			services.RaidAnalysis,
+			services.PlayerAnalysis,
 			services.WarcraftLogs,
 			db,
 			cacheService,
@@ -227,9 +267,10 @@ func setupRoutes(
 	r.GET("/csrf-token", csrfMiddleware.GetCSRFToken())
 
 	// Authentication routes
-	handlers.Auth.RegisterRoutes(r)                     // Auth Routes
-	handlers.GoogleAuth.RegisterRoutes(r)               // Google OAuth Routes
-	handlers.BattleNet.RegisterRoutes(r, jwtMiddleware) // Blizzard Battle.Net OAuth Routes
+	handlers.Auth.RegisterRoutes(r)                            // Auth Routes
+	handlers.GoogleAuth.RegisterRoutes(r)                      // Google OAuth Routes
+	handlers.BattleNet.RegisterRoutes(r, jwtMiddleware)        // Blizzard Battle.Net OAuth Routes
+	handlers.WarcraftLogsAuth.RegisterRoutes(r, jwtMiddleware) // WarcraftLogs OAuth Routes
 
 	// Protected API routes
 	apiGroup := r.Group("")
@@ -13,6 +13,9 @@ import (
 	"wowperf/internal/services/raiderio"
 	"wowperf/internal/services/warcraftlogs"
 
+	// Stockage des archives des rapports
+	buildsArchive "wowperf/internal/services/warcraftlogs/mythicplus/builds/archive"
+
 	// Scheduler pour la configuration de la queue
 	scheduler "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/scheduler"
 	buildsDefinitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
@@ -53,6 +56,12 @@ func main() {
 		logger.Fatalf("Failed to load WarcraftLogs config: %v", err)
 	}
 
+	// Stockage objet des rapports archivés par le workflow de rétention
+	archiveStore, err := buildsArchive.NewObjectStore(config.Retention.Store)
+	if err != nil {
+		logger.Fatalf("Failed to initialize report archive store: %v", err)
+	}
+
 	// Initialiser les features
 	logger.Printf("Initializing features")
 
@@ -62,6 +71,7 @@ func main() {
 		db,
 		warcraftLogsClient,
 		int(config.Rankings.MaxRankingsPerSpec),
+		archiveStore,
 	)
 
 	// Initialiser la feature player rankings
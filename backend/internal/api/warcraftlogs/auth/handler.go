@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	reportsAnalysis "wowperf/internal/api/warcraftlogs/mythicplus/reports"
+	wclAuth "wowperf/internal/services/warcraftlogs/auth"
+	mythicplusAnalytics "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
+)
+
+// WarcraftLogsAuthHandler handles WarcraftLogs authentication endpoints
+type WarcraftLogsAuthHandler struct {
+	WarcraftLogsAuthService *wclAuth.WarcraftLogsAuthService
+	ReportAnalysisService   *mythicplusAnalytics.ReportAnalysisService
+}
+
+// NewWarcraftLogsAuthHandler creates a new WarcraftLogsAuthHandler
+func NewWarcraftLogsAuthHandler(
+	warcraftLogsAuthService *wclAuth.WarcraftLogsAuthService,
+	reportAnalysisService *mythicplusAnalytics.ReportAnalysisService,
+) *WarcraftLogsAuthHandler {
+	return &WarcraftLogsAuthHandler{
+		WarcraftLogsAuthService: warcraftLogsAuthService,
+		ReportAnalysisService:   reportAnalysisService,
+	}
+}
+
+// RegisterRoutes registers all WarcraftLogs authentication routes
+func (h *WarcraftLogsAuthHandler) RegisterRoutes(r *gin.Engine, requireAuth gin.HandlerFunc) {
+	warcraftLogs := r.Group("/auth/warcraftlogs")
+	{
+		// Protected routes requiring user authentication
+		authed := warcraftLogs.Group("")
+		authed.Use(requireAuth)
+		{
+			authed.GET("/link", h.InitiateAuth)
+			authed.GET("/callback", h.HandleCallback)
+			authed.GET("/status", h.GetLinkStatus)
+			authed.POST("/unlink", h.UnlinkAccount)
+
+			// Reports of the linked account, including the private ones
+			authed.GET("/reports", h.GetUserReports)
+
+			// Analysis of a report with the linked account, the job is only polled by its owner
+			authed.POST("/reports/analysis", h.SubmitReportAnalysis)
+			authed.GET("/reports/analysis/:jobId", h.GetReportAnalysisJob)
+		}
+	}
+}
+
+// InitiateAuth returns the WarcraftLogs authorization URL
+func (h *WarcraftLogsAuthHandler) InitiateAuth(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+			"code":  "user_not_authenticated",
+		})
+		return
+	}
+
+	authURL, err := h.WarcraftLogsAuthService.InitiateAuth(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to initiate WarcraftLogs authentication",
+			"code":    "auth_initiation_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":  authURL,
+		"code": "auth_url_generated",
+	})
+}
+
+// HandleCallback exchanges the authorization code and links the WarcraftLogs account
+func (h *WarcraftLogsAuthHandler) HandleCallback(c *gin.Context) {
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required OAuth parameters",
+			"code":  "invalid_oauth_params",
+		})
+		return
+	}
+
+	authenticatedUserID := c.GetUint("user_id")
+	if authenticatedUserID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+			"code":  "user_not_authenticated",
+		})
+		return
+	}
+
+	token, stateUserID, err := h.WarcraftLogsAuthService.ExchangeCodeForToken(c.Request.Context(), code, state)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, wclAuth.ErrInvalidState) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to exchange code for token",
+			"code":    "token_exchange_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The user who initiated the flow must be the authenticated user
+	if stateUserID != authenticatedUserID {
+		log.Printf("SECURITY ALERT: WarcraftLogs state user ID (%d) != authenticated user ID (%d)",
+			stateUserID, authenticatedUserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "OAuth state mismatch - security violation",
+			"code":  "state_user_mismatch",
+		})
+		return
+	}
+
+	profile, err := h.WarcraftLogsAuthService.LinkUserAccount(c.Request.Context(), token, stateUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to link WarcraftLogs account",
+			"code":    "link_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "WarcraftLogs authentication successful",
+		"code":    "auth_successful",
+		"name":    profile.Name,
+		"guilds":  profile.Guilds,
+		"linked":  true,
+	})
+}
+
+// GetLinkStatus returns the current WarcraftLogs link status
+func (h *WarcraftLogsAuthHandler) GetLinkStatus(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	status, err := h.WarcraftLogsAuthService.GetUserWarcraftLogsStatus(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get WarcraftLogs status",
+			"code":    "status_check_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// UnlinkAccount removes the WarcraftLogs account link
+func (h *WarcraftLogsAuthHandler) UnlinkAccount(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.WarcraftLogsAuthService.UnlinkUserAccount(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to unlink WarcraftLogs account",
+			"code":    "unlink_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "WarcraftLogs account successfully unlinked",
+		"code":    "unlink_successful",
+	})
+}
+
+// GetUserReports returns the recent reports of the user and of their guilds, including the private ones
+func (h *WarcraftLogsAuthHandler) GetUserReports(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid limit parameter",
+			"code":  "invalid_limit",
+		})
+		return
+	}
+
+	reports, err := h.WarcraftLogsAuthService.GetUserReports(c.Request.Context(), userID, limit)
+	if err != nil {
+		if errors.Is(err, wclAuth.ErrAccountNotLinked) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "WarcraftLogs account not linked",
+				"code":  "warcraftlogs_not_linked",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get WarcraftLogs reports",
+			"code":    "reports_fetch_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// SubmitReportAnalysis starts the analysis of a report with the linked account, so private reports can be analyzed
+func (h *WarcraftLogsAuthHandler) SubmitReportAnalysis(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	status, err := h.WarcraftLogsAuthService.GetUserWarcraftLogsStatus(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get WarcraftLogs status",
+			"code":    "status_check_failed",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !status.Linked {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "WarcraftLogs account not linked",
+			"code":  "warcraftlogs_not_linked",
+		})
+		return
+	}
+
+	reportsAnalysis.SubmitReportAnalysis(c, h.ReportAnalysisService, &userID)
+}
+
+// GetReportAnalysisJob returns the status of a report analysis submitted by the user
+func (h *WarcraftLogsAuthHandler) GetReportAnalysisJob(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+			"code":  "user_not_authenticated",
+		})
+		return
+	}
+
+	reportsAnalysis.GetReportAnalysisJob(c, h.ReportAnalysisService, &userID)
+}
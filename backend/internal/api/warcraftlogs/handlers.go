@@ -13,6 +13,10 @@ import (
 	mythicplus "wowperf/internal/api/warcraftlogs/mythicplus"
 	mythicplusbuildsAnalysis "wowperf/internal/api/warcraftlogs/mythicplus/builds"
 	character "wowperf/internal/api/warcraftlogs/mythicplus/character"
+	mythicplusdungeonsAnalysis "wowperf/internal/api/warcraftlogs/mythicplus/dungeons"
+	mythicplusplayers "wowperf/internal/api/warcraftlogs/mythicplus/players"
+	mythicplusreportsAnalysis "wowperf/internal/api/warcraftlogs/mythicplus/reports"
+	raidsAnalysis "wowperf/internal/api/warcraftlogs/raids"
 
 	middleware "wowperf/middleware/cache"
 	"wowperf/pkg/cache"
@@ -32,8 +36,12 @@ type Handler struct {
 		Leaderboard   *mythicplus.DungeonLeaderboardHandler
 		Analysis      *mythicplus.GlobalLeaderboardAnalysisHandler
 		Builds        *mythicplusbuildsAnalysis.MythicPlusBuildsAnalysisHandler
+		Dungeons      *mythicplusdungeonsAnalysis.MythicPlusDungeonsAnalysisHandler
+		Reports       *mythicplusreportsAnalysis.MythicPlusReportAnalysisHandler
+		Players       *mythicplusplayers.MythicPlusPlayersHandler
 		SpecEvolution *mythicplus.SpecEvolutionMetricsAnalysisHandler
 	}
+	Raids        *raidsAnalysis.RaidsAnalysisHandler
 	cache        cache.CacheService
 	cacheManager *middleware.CacheManager
 }
@@ -42,7 +50,11 @@ func NewHandler(
 	globalService *leaderboard.GlobalLeaderboardService,
 	analysisService *leaderboard.GlobalLeaderboardAnalysisService,
 	buildsAnalysisService *mythicplusanalytics.BuildAnalysisService,
+	dungeonsAnalysisService *mythicplusanalytics.DungeonAnalysisService,
 	specEvolutionService *leaderboard.SpecEvolutionMetricsAnalysisService,
+	reportAnalysisService *mythicplusanalytics.ReportAnalysisService,
+	raidAnalysisService *mythicplusanalytics.RaidAnalysisService,
+	playerAnalysisService *mythicplusanalytics.PlayerAnalysisService,
 	warcraftLogsService *service.WarcraftLogsClientService,
 	db *gorm.DB,
 	cache cache.CacheService,
@@ -60,6 +72,9 @@ func NewHandler(
 			Leaderboard   *mythicplus.DungeonLeaderboardHandler
 			Analysis      *mythicplus.GlobalLeaderboardAnalysisHandler
 			Builds        *mythicplusbuildsAnalysis.MythicPlusBuildsAnalysisHandler
+			Dungeons      *mythicplusdungeonsAnalysis.MythicPlusDungeonsAnalysisHandler
+			Reports       *mythicplusreportsAnalysis.MythicPlusReportAnalysisHandler
+			Players       *mythicplusplayers.MythicPlusPlayersHandler
 			SpecEvolution *mythicplus.SpecEvolutionMetricsAnalysisHandler
 		}{
 			Dungeon:       mythicplus.NewDungeonLeaderboardHandler(warcraftLogsService),
@@ -67,8 +82,12 @@ func NewHandler(
 			Leaderboard:   mythicplus.NewDungeonLeaderboardHandler(warcraftLogsService),
 			Analysis:      mythicplus.NewGlobalLeaderboardAnalysisHandler(analysisService),
 			Builds:        mythicplusbuildsAnalysis.NewMythicPlusBuildsAnalysisHandler(buildsAnalysisService),
+			Dungeons:      mythicplusdungeonsAnalysis.NewMythicPlusDungeonsAnalysisHandler(dungeonsAnalysisService),
+			Reports:       mythicplusreportsAnalysis.NewMythicPlusReportAnalysisHandler(reportAnalysisService),
+			Players:       mythicplusplayers.NewMythicPlusPlayersHandler(playerAnalysisService),
 			SpecEvolution: mythicplus.NewSpecEvolutionMetricsAnalysisHandler(specEvolutionService),
 		},
+		Raids:        raidsAnalysis.NewRaidsAnalysisHandler(raidAnalysisService),
 		cache:        cache,
 		cacheManager: cacheManager,
 	}
@@ -114,9 +133,11 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 				// Talent builds
 				builds.GET("/talents/top", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetTopTalentBuilds)
 				builds.GET("/talents/dungeons", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetTalentBuildsByDungeon)
+				builds.GET("/talents/tree", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetTalentTreePickRates)
 
 				// Stats
 				builds.GET("/stats", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetStatPriorities)
+				builds.GET("/stats/weights", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetStatWeights)
 
 				// Optimal build
 				builds.GET("/optimal", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetOptimalBuild)
@@ -126,6 +147,73 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 
 				// Class spec summary
 				builds.GET("/summary", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetClassSpecSummary)
+
+				// DPS and HPS percentiles per dungeon and key level bracket
+				builds.GET("/performance", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetPerformancePercentiles)
+
+				// Major cooldowns per minute, interrupts and dispels percentiles per dungeon, ranked within the role
+				builds.GET("/ability-usage", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetAbilityUsage)
+
+				// Run pacing timeline and median time to each boss per key level
+				builds.GET("/pacing", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetDungeonPacing)
+				builds.GET("/pacing/report", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetRunPacing)
+
+				// Validation rates of the extracted builds per spec and per run
+				builds.GET("/validation", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetBuildValidationRates)
+				builds.GET("/validation/runs", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetRunValidationRates)
+
+				// Potion and healthstone usage per dungeon, with their correlation with success and key level
+				builds.GET("/consumables", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetConsumableUsage)
+
+				// Weekly trends
+				builds.GET("/trends/items", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetItemTrends)
+				builds.GET("/trends/talents", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetTalentTrends)
+				builds.GET("/trends/stats", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetStatTrends)
+
+				// Patch registry
+				builds.GET("/patches", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Builds.GetPatches)
+			}
+
+			// Dungeons combat analysis for Mythic+
+			dungeons := mythicplus.Group("/dungeons")
+			{
+				// Biggest killers across all dungeons for the last week
+				dungeons.GET("/deaths/top-killers", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Dungeons.GetTopKillers)
+
+				// Most played and most successful group compositions across all dungeons
+				dungeons.GET("/compositions", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Dungeons.GetCompositions)
+
+				// Death analysis of a dungeon per key level bracket
+				dungeons.GET("/:encounterId/deaths", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Dungeons.GetDungeonDeathAnalysis)
+
+				// Damage taken hotspots of a dungeon per key level bracket and role
+				dungeons.GET("/:encounterId/damage-taken", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Dungeons.GetDungeonDamageTaken)
+
+				// Group compositions of a dungeon per key level bracket
+				dungeons.GET("/:encounterId/compositions", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Dungeons.GetDungeonCompositions)
+			}
+
+			// On-demand analysis of a report, not cached as the status is polled
+			reports := mythicplus.Group("/reports/analysis")
+			{
+				// Submit a report code or URL, returns the job to poll
+				reports.POST("", h.MythicPlus.Reports.SubmitReportAnalysis)
+
+				// Status of the analysis, with the comparison once completed
+				reports.GET("/:jobId", h.MythicPlus.Reports.GetReportAnalysisJob)
+			}
+
+			// Players search, profiles and identities across renames and realm transfers
+			players := mythicplus.Group("/players")
+			{
+				// Names and realms used by a player
+				players.GET("/aliases", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Players.GetPlayerAliases)
+
+				// Search players by name, realm and region
+				players.GET("/search", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Players.SearchPlayers)
+
+				// Best runs, score, spec history and builds of a player
+				players.GET("/profile", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Players.GetPlayerProfile)
 			}
 
 			// Evolution metrics routes
@@ -177,6 +265,26 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 			// Get the global leaderboard by spec
 			mythicplus.GET("/global/leaderboard/spec", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetSpecLeaderboard)
 
+			// Get a page of the leaderboard with filters, pages are cached per cursor
+			mythicplus.GET("/global/leaderboard/page", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetLeaderboardPage)
+
+			// Get the global leaderboard as it was at a date
+			mythicplus.GET("/global/leaderboard/history", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetLeaderboardAsOf)
+
+			// Get the final standings of a season
+			mythicplus.GET("/global/leaderboard/season", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetSeasonFinalLeaderboard)
+
+			// Get the rankings snapshots
+			mythicplus.GET("/global/leaderboard/snapshots", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetRankingsSnapshots)
+
+			// Get the guild and realm leaderboards
+			mythicplus.GET("/global/leaderboard/guilds", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetGuildLeaderboard)
+			mythicplus.GET("/global/leaderboard/realms", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetRealmLeaderboard)
+
+			// Get the evolution of a guild or a realm over the rankings snapshots
+			mythicplus.GET("/global/leaderboard/guilds/history", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetGuildHistory)
+			mythicplus.GET("/global/leaderboard/realms/history", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Global.GetRealmHistory)
+
 			// Analysis routes
 			// Get average global scores per spec
 			mythicplus.GET("/analysis/specs/avg-scores", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Analysis.GetSpecGlobalScores)
@@ -202,6 +310,35 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 			// Get top 5 players per role
 			mythicplus.GET("/analysis/players/top-roles", h.cacheManager.CacheMiddleware(routeConfig), h.MythicPlus.Analysis.GetTop5PlayersPerRole)
 		}
+
+		// Raid routes
+		raids := warcraftlogs.Group("/raids")
+		{
+			// Raids and bosses with builds statistics
+			raids.GET("", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetRaids)
+
+			// Builds analysis for a raid boss at a difficulty
+			boss := raids.Group("/:raid/:boss/:difficulty/builds")
+			{
+				// Popular items by slot
+				boss.GET("/items", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetPopularItemsBySlot)
+
+				// Enchant usage
+				boss.GET("/enchants", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetEnchantUsage)
+
+				// Gem usage
+				boss.GET("/gems", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetGemUsage)
+
+				// Talent builds
+				boss.GET("/talents", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetTopTalentBuilds)
+
+				// Stats
+				boss.GET("/stats", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetStatPriorities)
+
+				// Optimal build
+				boss.GET("/optimal", h.cacheManager.CacheMiddleware(routeConfig), h.Raids.GetOptimalBuild)
+			}
+		}
 	}
 }
 
@@ -1,15 +1,18 @@
 package WarcraftLogsMythicPlusBuildsAnalysis
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 	service "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
 )
 
@@ -32,13 +35,19 @@ func NewMythicPlusBuildsAnalysisHandler(analysisService *service.BuildAnalysisSe
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
 // @Param encounter_id query int false "Encounter ID to filter results"
+// @Param order query string false "Ordering: popularity (default) or performance"
+// @Param patch query string false "Patch name, current (default) or all"
+// @Param affix query string false "Comma-separated affix IDs the runs must have"
+// @Param minKey query int false "Lowest keystone level"
+// @Param maxKey query int false "Highest keystone level"
 // @Success 200 {array} service.ItemPopularity
+// @Success 200 {array} service.ChoiceLift "With order=performance"
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/items [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetPopularItemsBySlot(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
@@ -57,6 +66,39 @@ func (h *MythicPlusBuildsAnalysisHandler) GetPopularItemsBySlot(c *gin.Context)
 		encounterID = &encID
 	}
 
+	order, ok := parseOrder(c)
+	if !ok {
+		return
+	}
+
+	segment, ok := h.parseSegmentFilter(c)
+	if !ok {
+		return
+	}
+
+	if order == service.OrderPerformance {
+		// The performance ordering spans every patch, the current patch default does not apply to it
+		if c.Query("patch") == "" {
+			segment.Patch = ""
+		}
+		if segment.IsSet() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "patch, affix and key level filters are not supported with order=performance"})
+			return
+		}
+		h.getPerformanceWeightedChoices(c, class, spec, warcraftlogsBuilds.LiftChoiceItem, encounterID)
+		return
+	}
+
+	if segment.IsSet() {
+		items, err := h.MythicPlusBuildsAnalysisService.GetSegmentedPopularItemsBySlot(c.Request.Context(), class, spec, encounterID, segment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
 	items, err := h.MythicPlusBuildsAnalysisService.GetPopularItemsBySlot(c.Request.Context(), class, spec, encounterID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -74,13 +116,17 @@ func (h *MythicPlusBuildsAnalysisHandler) GetPopularItemsBySlot(c *gin.Context)
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param patch query string false "Patch name, current (default) or all"
+// @Param affix query string false "Comma-separated affix IDs the runs must have"
+// @Param minKey query int false "Lowest keystone level"
+// @Param maxKey query int false "Highest keystone level"
 // @Success 200 {array} service.GlobalItemPopularity
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/items/global [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetGlobalPopularItemsBySlot(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
@@ -89,6 +135,21 @@ func (h *MythicPlusBuildsAnalysisHandler) GetGlobalPopularItemsBySlot(c *gin.Con
 
 	log.Printf("DEBUG: Handler will use class='%s' spec='%s'", class, spec)
 
+	segment, ok := h.parseSegmentFilter(c)
+	if !ok {
+		return
+	}
+
+	if segment.IsSet() {
+		items, err := h.MythicPlusBuildsAnalysisService.GetSegmentedGlobalPopularItemsBySlot(c.Request.Context(), class, spec, segment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
 	items, err := h.MythicPlusBuildsAnalysisService.GetGlobalPopularItemsBySlot(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -106,19 +167,41 @@ func (h *MythicPlusBuildsAnalysisHandler) GetGlobalPopularItemsBySlot(c *gin.Con
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param order query string false "Ordering: popularity (default) or performance"
+// @Param encounter_id query int false "Encounter ID to filter results, only with order=performance"
 // @Success 200 {array} service.EnchantUsage
+// @Success 200 {array} service.ChoiceLift "With order=performance"
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/enchants [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetEnchantUsage(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
 		return
 	}
 
+	order, ok := parseOrder(c)
+	if !ok {
+		return
+	}
+	if order == service.OrderPerformance {
+		var encounterID *int
+		if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+			encID, err := strconv.Atoi(encIDStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+				return
+			}
+			encounterID = &encID
+		}
+
+		h.getPerformanceWeightedChoices(c, class, spec, warcraftlogsBuilds.LiftChoiceEnchant, encounterID)
+		return
+	}
+
 	enchants, err := h.MythicPlusBuildsAnalysisService.GetEnchantUsage(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -136,19 +219,41 @@ func (h *MythicPlusBuildsAnalysisHandler) GetEnchantUsage(c *gin.Context) {
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param order query string false "Ordering: popularity (default) or performance"
+// @Param encounter_id query int false "Encounter ID to filter results, only with order=performance"
 // @Success 200 {array} service.GemUsage
+// @Success 200 {array} service.ChoiceLift "With order=performance"
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/gems [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetGemUsage(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
 		return
 	}
 
+	order, ok := parseOrder(c)
+	if !ok {
+		return
+	}
+	if order == service.OrderPerformance {
+		var encounterID *int
+		if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+			encID, err := strconv.Atoi(encIDStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+				return
+			}
+			encounterID = &encID
+		}
+
+		h.getPerformanceWeightedChoices(c, class, spec, warcraftlogsBuilds.LiftChoiceGem, encounterID)
+		return
+	}
+
 	gems, err := h.MythicPlusBuildsAnalysisService.GetGemUsage(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -166,13 +271,17 @@ func (h *MythicPlusBuildsAnalysisHandler) GetGemUsage(c *gin.Context) {
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param patch query string false "Patch name, current (default) or all"
+// @Param affix query string false "Comma-separated affix IDs the runs must have"
+// @Param minKey query int false "Lowest keystone level"
+// @Param maxKey query int false "Highest keystone level"
 // @Success 200 {array} service.TalentBuild
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/talents/top [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetTopTalentBuilds(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	log.Printf("DEBUG: Handler will use class='%s' spec='%s'", class, spec)
 
@@ -181,6 +290,21 @@ func (h *MythicPlusBuildsAnalysisHandler) GetTopTalentBuilds(c *gin.Context) {
 		return
 	}
 
+	segment, ok := h.parseSegmentFilter(c)
+	if !ok {
+		return
+	}
+
+	if segment.IsSet() {
+		builds, err := h.MythicPlusBuildsAnalysisService.GetSegmentedTopTalentBuilds(c.Request.Context(), class, spec, segment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, builds)
+		return
+	}
+
 	builds, err := h.MythicPlusBuildsAnalysisService.GetTopTalentBuilds(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -198,13 +322,17 @@ func (h *MythicPlusBuildsAnalysisHandler) GetTopTalentBuilds(c *gin.Context) {
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param patch query string false "Patch name, current (default) or all"
+// @Param affix query string false "Comma-separated affix IDs the runs must have"
+// @Param minKey query int false "Lowest keystone level"
+// @Param maxKey query int false "Highest keystone level"
 // @Success 200 {array} service.DungeonTalentBuild
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/talents/dungeons [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetTalentBuildsByDungeon(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
@@ -213,6 +341,21 @@ func (h *MythicPlusBuildsAnalysisHandler) GetTalentBuildsByDungeon(c *gin.Contex
 
 	log.Printf("DEBUG: Handler will use class='%s' spec='%s'", class, spec)
 
+	segment, ok := h.parseSegmentFilter(c)
+	if !ok {
+		return
+	}
+
+	if segment.IsSet() {
+		talents, err := h.MythicPlusBuildsAnalysisService.GetSegmentedTalentBuildsByDungeon(c.Request.Context(), class, spec, segment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, talents)
+		return
+	}
+
 	talents, err := h.MythicPlusBuildsAnalysisService.GetTalentBuildsByDungeon(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -222,6 +365,52 @@ func (h *MythicPlusBuildsAnalysisHandler) GetTalentBuildsByDungeon(c *gin.Contex
 	c.JSON(http.StatusOK, talents)
 }
 
+// GetTalentTreePickRates returns the talent tree of a specific class and spec annotated with node pick rates
+// @Summary Get talent tree pick rates
+// @Description Returns the talent tree of a spec with the pick rate of each node, choice node entry and hero talent tree
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Success 200 {object} service.AnnotatedTalentTree
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Talent tree not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/talents/tree [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetTalentTreePickRates(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	var encounterID *int
+	if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+		encID, err := strconv.Atoi(encIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+			return
+		}
+		encounterID = &encID
+	}
+
+	tree, err := h.MythicPlusBuildsAnalysisService.GetTalentTreePickRates(c.Request.Context(), class, spec, encounterID)
+	if err != nil {
+		if errors.Is(err, service.ErrTalentTreeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
 // GetStatPriorities returns stat priority statistics for a specific class and spec
 // @Summary Get stat priorities
 // @Description Returns stat priority statistics for a specific class and spec
@@ -230,19 +419,38 @@ func (h *MythicPlusBuildsAnalysisHandler) GetTalentBuildsByDungeon(c *gin.Contex
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param patch query string false "Patch name, current (default) or all"
+// @Param affix query string false "Comma-separated affix IDs the runs must have"
+// @Param minKey query int false "Lowest keystone level"
+// @Param maxKey query int false "Highest keystone level"
 // @Success 200 {array} service.StatPriority
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/stats [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetStatPriorities(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
 		return
 	}
 
+	segment, ok := h.parseSegmentFilter(c)
+	if !ok {
+		return
+	}
+
+	if segment.IsSet() {
+		stats, err := h.MythicPlusBuildsAnalysisService.GetSegmentedStatPriorities(c.Request.Context(), class, spec, segment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
 	stats, err := h.MythicPlusBuildsAnalysisService.GetStatPriorities(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -252,6 +460,43 @@ func (h *MythicPlusBuildsAnalysisHandler) GetStatPriorities(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetStatWeights returns the estimated secondary stat weights for a specific class and spec
+// @Summary Get stat weights
+// @Description Returns the weight of each secondary stat relative to the best one, estimated by a regression of the performance on the stat ratings of the builds
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param metric query string false "Performance metric (keystone_level, score, dps, hps)"
+// @Success 200 {array} service.StatWeight
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/stats/weights [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetStatWeights(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	metric := strings.ToLower(c.Query("metric"))
+	if metric != "" && !warcraftlogsBuilds.IsValidStatWeightMetric(metric) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric, expected one of keystone_level, score, dps, hps"})
+		return
+	}
+
+	weights, err := h.MythicPlusBuildsAnalysisService.GetStatWeights(c.Request.Context(), class, spec, metric)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, weights)
+}
+
 // GetOptimalBuild returns the optimal build for a specific class and spec
 // @Summary Get optimal build
 // @Description Returns the optimal build for a specific class and spec
@@ -260,19 +505,38 @@ func (h *MythicPlusBuildsAnalysisHandler) GetStatPriorities(c *gin.Context) {
 // @Produce json
 // @Param class query string true "Class name"
 // @Param spec query string true "Specialization name"
+// @Param patch query string false "Patch name, current (default) or all"
+// @Param affix query string false "Comma-separated affix IDs the runs must have"
+// @Param minKey query int false "Lowest keystone level"
+// @Param maxKey query int false "Highest keystone level"
 // @Success 200 {object} service.OptimalBuild
 // @Failure 400 {object} string "Bad request"
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/optimal [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetOptimalBuild(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
 		return
 	}
 
+	segment, ok := h.parseSegmentFilter(c)
+	if !ok {
+		return
+	}
+
+	if segment.IsSet() {
+		optimalBuild, err := h.MythicPlusBuildsAnalysisService.GetSegmentedOptimalBuild(c.Request.Context(), class, spec, segment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, optimalBuild)
+		return
+	}
+
 	optimalBuild, err := h.MythicPlusBuildsAnalysisService.GetOptimalBuild(c.Request.Context(), class, spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -294,7 +558,7 @@ func (h *MythicPlusBuildsAnalysisHandler) GetOptimalBuild(c *gin.Context) {
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/specs/comparison [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetSpecComparison(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
+	class := NormalizeWoWTerms(c.Query("class"))
 
 	if class == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class parameter is required"})
@@ -323,8 +587,8 @@ func (h *MythicPlusBuildsAnalysisHandler) GetSpecComparison(c *gin.Context) {
 // @Failure 500 {object} string "Internal server error"
 // @Router /warcraftlogs/mythicplus/builds/analysis/summary [get]
 func (h *MythicPlusBuildsAnalysisHandler) GetClassSpecSummary(c *gin.Context) {
-	class := normalizeWoWTerms(c.Query("class"))
-	spec := normalizeWoWTerms(c.Query("spec"))
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
 
 	if class == "" || spec == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
@@ -340,7 +604,613 @@ func (h *MythicPlusBuildsAnalysisHandler) GetClassSpecSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, summary)
 }
 
-func normalizeWoWTerms(term string) string {
+// GetPerformancePercentiles returns the DPS and HPS percentiles for a specific class and spec
+// @Summary Get performance percentiles
+// @Description Returns the p25, p50, p75 and p95 DPS and HPS of a class and spec per dungeon and key level bracket
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param metric query string false "Metric to return (dps, hps)"
+// @Success 200 {array} service.PerformancePercentiles
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/performance [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetPerformancePercentiles(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	var encounterID *int
+	if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+		encID, err := strconv.Atoi(encIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+			return
+		}
+		encounterID = &encID
+	}
+
+	bracket := c.DefaultQuery("bracket", warcraftlogsBuilds.KeyLevelBracketAll)
+	if !warcraftlogsBuilds.IsValidKeyLevelBracket(bracket) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	metric := strings.ToLower(c.Query("metric"))
+	if metric != "" && metric != warcraftlogsBuilds.PerformanceMetricDPS && metric != warcraftlogsBuilds.PerformanceMetricHPS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric, expected one of dps, hps"})
+		return
+	}
+
+	percentiles, err := h.MythicPlusBuildsAnalysisService.GetPerformancePercentiles(c.Request.Context(), class, spec, encounterID, bracket, metric)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, percentiles)
+}
+
+// GetAbilityUsage returns the major cooldowns, interrupts and dispels percentiles for a specific class and spec
+// @Summary Get ability usage
+// @Description Returns the p25, p50, p75 and p95 major cooldowns per minute, interrupts and dispels of a class and spec per dungeon and key level bracket, ranked among the specs of the same role
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param metric query string false "Metric to return (cooldowns_per_minute, interrupts, dispels)"
+// @Success 200 {array} service.AbilityUsagePercentiles
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/ability-usage [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetAbilityUsage(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	var encounterID *int
+	if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+		encID, err := strconv.Atoi(encIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+			return
+		}
+		encounterID = &encID
+	}
+
+	bracket := c.DefaultQuery("bracket", warcraftlogsBuilds.KeyLevelBracketAll)
+	if !warcraftlogsBuilds.IsValidKeyLevelBracket(bracket) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	metric := strings.ToLower(c.Query("metric"))
+	if metric != "" && !warcraftlogsBuilds.IsValidAbilityUsageMetric(metric) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric, expected one of cooldowns_per_minute, interrupts, dispels"})
+		return
+	}
+
+	usage, err := h.MythicPlusBuildsAnalysisService.GetAbilityUsage(c.Request.Context(), class, spec, encounterID, bracket, metric)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetRunPacing returns the pacing timeline of a run
+// @Summary Get run pacing
+// @Description Returns the trash and boss segments of a run with their delta versus the median timed run of the same dungeon and key level
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param code query string true "Report code"
+// @Param fight_id query int true "Fight ID of the run"
+// @Success 200 {object} service.RunPacing
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Run pacing not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/pacing/report [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetRunPacing(c *gin.Context) {
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code parameter is required"})
+		return
+	}
+
+	fightID, err := strconv.Atoi(c.Query("fight_id"))
+	if err != nil || fightID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fight_id format"})
+		return
+	}
+
+	pacing, err := h.MythicPlusBuildsAnalysisService.GetRunPacing(c.Request.Context(), code, fightID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pacing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run pacing not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pacing)
+}
+
+// GetDungeonPacing returns the median time to each boss of a dungeon per key level
+// @Summary Get dungeon pacing
+// @Description Returns the median time to each boss, boss duration and trash before each boss of a dungeon per key level, for all runs and for the timed runs
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param encounter_id query int true "Encounter ID of the dungeon"
+// @Param keystone_level query int false "Key level to filter results"
+// @Success 200 {array} service.BossPacing
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/pacing [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetDungeonPacing(c *gin.Context) {
+	encounterID, err := strconv.Atoi(c.Query("encounter_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "encounter_id parameter is required"})
+		return
+	}
+
+	var keystoneLevel *int
+	if levelStr := c.Query("keystone_level"); levelStr != "" {
+		level, err := strconv.Atoi(levelStr)
+		if err != nil || level <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid keystone_level format"})
+			return
+		}
+		keystoneLevel = &level
+	}
+
+	pacing, err := h.MythicPlusBuildsAnalysisService.GetDungeonPacing(c.Request.Context(), encounterID, keystoneLevel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pacing)
+}
+
+// GetBuildValidationRates returns the validation rate of the extracted builds per class and spec
+// @Summary Get build validation rates
+// @Description Returns the percentage of extracted builds that passed the validation per class and spec, with the number of builds rejected for each reason
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string false "Class name"
+// @Param spec query string false "Specialization name"
+// @Param days query int false "Number of days of extraction (default: 7)"
+// @Success 200 {array} service.SpecValidationRate
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/validation [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetBuildValidationRates(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	days, ok := parseValidationDays(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.MythicPlusBuildsAnalysisService.GetBuildValidationRates(c.Request.Context(), class, spec, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// GetRunValidationRates returns the validation rate of the builds of each run
+// @Summary Get run validation rates
+// @Description Returns the runs with the most builds rejected by the validation, with their validation rate and rejection reasons
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param days query int false "Number of days of extraction (default: 7)"
+// @Param limit query int false "Number of runs (default: 50, max: 500)"
+// @Success 200 {array} service.RunValidationRate
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/validation/runs [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetRunValidationRates(c *gin.Context) {
+	var encounterID *int
+	if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+		encID, err := strconv.Atoi(encIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+			return
+		}
+		encounterID = &encID
+	}
+
+	days, ok := parseValidationDays(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit, expected a number between 1 and 500"})
+		return
+	}
+
+	rates, err := h.MythicPlusBuildsAnalysisService.GetRunValidationRates(c.Request.Context(), encounterID, days, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// parseValidationDays reads the number of days of the validation routes, writing a bad request when it is invalid
+func parseValidationDays(c *gin.Context) (int, bool) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days, expected a number between 1 and 365"})
+		return 0, false
+	}
+	return days, true
+}
+
+// GetConsumableUsage returns the potion and healthstone usage for a specific class and spec
+// @Summary Get consumable usage
+// @Description Returns the potion and healthstone usage rates of a class and spec per dungeon, with their correlation with the run success and the key level
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Success 200 {array} service.ConsumableUsage
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/consumables [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetConsumableUsage(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	var encounterID *int
+	if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+		encID, err := strconv.Atoi(encIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+			return
+		}
+		encounterID = &encID
+	}
+
+	bracket := c.DefaultQuery("bracket", warcraftlogsBuilds.KeyLevelBracketAll)
+	if !warcraftlogsBuilds.IsValidKeyLevelBracket(bracket) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	usage, err := h.MythicPlusBuildsAnalysisService.GetConsumableUsage(c.Request.Context(), class, spec, encounterID, bracket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetItemTrends returns the weekly popularity of the items of a slot for a specific class and spec
+// @Summary Get item trends
+// @Description Returns the usage of the items of a slot for each weekly reset period, from the snapshots of the equipment analysis
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param slot query int true "Item slot"
+// @Param item_id query int false "Item ID, the most used items of the slot are returned when omitted"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param from query string false "First period to return (YYYY-MM-DD)"
+// @Param to query string false "Last period to return (YYYY-MM-DD)"
+// @Param limit query int false "Number of items returned without item_id (default 5, max 20)"
+// @Success 200 {array} service.ItemTrend
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/trends/items [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetItemTrends(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	slot, err := strconv.Atoi(c.Query("slot"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slot parameter is required and must be a number"})
+		return
+	}
+
+	var itemID *int
+	if itemIDStr := c.Query("item_id"); itemIDStr != "" {
+		id, err := strconv.Atoi(itemIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item_id format"})
+			return
+		}
+		itemID = &id
+	}
+
+	filter, ok := parseTrendFilter(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := parseTrendLimit(c)
+	if !ok {
+		return
+	}
+
+	trends, err := h.MythicPlusBuildsAnalysisService.GetItemTrends(c.Request.Context(), class, spec, slot, itemID, filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// GetTalentTrends returns the weekly popularity of the talent imports for a specific class and spec
+// @Summary Get talent trends
+// @Description Returns the usage of the talent imports for each weekly reset period, from the snapshots of the talent analysis
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param talent_import query string false "Talent import, the most used imports are returned when omitted"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param from query string false "First period to return (YYYY-MM-DD)"
+// @Param to query string false "Last period to return (YYYY-MM-DD)"
+// @Param limit query int false "Number of imports returned without talent_import (default 5, max 20)"
+// @Success 200 {array} service.TalentTrend
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/trends/talents [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetTalentTrends(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	filter, ok := parseTrendFilter(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := parseTrendLimit(c)
+	if !ok {
+		return
+	}
+
+	trends, err := h.MythicPlusBuildsAnalysisService.GetTalentTrends(c.Request.Context(), class, spec, c.Query("talent_import"), filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// GetStatTrends returns the weekly average stats for a specific class and spec
+// @Summary Get stat trends
+// @Description Returns the average value of each stat for each weekly reset period, from the snapshots of the stat analysis
+// @Tags Mythic+ Builds Analysis
+// @Accept json
+// @Produce json
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Param encounter_id query int false "Encounter ID to filter results"
+// @Param from query string false "First period to return (YYYY-MM-DD)"
+// @Param to query string false "Last period to return (YYYY-MM-DD)"
+// @Success 200 {array} service.StatTrend
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/trends/stats [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetStatTrends(c *gin.Context) {
+	class := NormalizeWoWTerms(c.Query("class"))
+	spec := NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return
+	}
+
+	filter, ok := parseTrendFilter(c)
+	if !ok {
+		return
+	}
+
+	trends, err := h.MythicPlusBuildsAnalysisService.GetStatTrends(c.Request.Context(), class, spec, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// GetPatches returns the patch registry
+// @Summary Get patches
+// @Description Returns the game patches the rankings, reports and builds are tagged with, ordered by start date
+// @Tags Mythic+ Builds Analysis
+// @Produce json
+// @Success 200 {array} warcraftlogsBuilds.GamePatch
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/builds/analysis/patches [get]
+func (h *MythicPlusBuildsAnalysisHandler) GetPatches(c *gin.Context) {
+	patches, err := h.MythicPlusBuildsAnalysisService.GetPatches(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, patches)
+}
+
+// parseTrendFilter reads the encounter and the period bounds of the trends routes, writing a bad request when they are invalid
+func parseTrendFilter(c *gin.Context) (service.TrendFilter, bool) {
+	var filter service.TrendFilter
+
+	if encIDStr := c.Query("encounter_id"); encIDStr != "" {
+		encID, err := strconv.Atoi(encIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounter_id format"})
+			return filter, false
+		}
+		filter.EncounterID = &encID
+	}
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from format, expected YYYY-MM-DD"})
+			return filter, false
+		}
+		filter.From = &from
+	}
+
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to format, expected YYYY-MM-DD"})
+			return filter, false
+		}
+		// Include the periods starting during the last day
+		to = to.Add(24*time.Hour - time.Nanosecond)
+		filter.To = &to
+	}
+
+	return filter, true
+}
+
+// parseTrendLimit reads the number of series of the trends routes, writing a bad request when it is invalid
+func parseTrendLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit < 1 || limit > 20 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit, expected a number between 1 and 20"})
+		return 0, false
+	}
+	return limit, true
+}
+
+// parseSegmentFilter reads the patch, affix and key level filters of the builds routes, writing an error when they are invalid
+// The patch defaults to the current patch, "all" disables the patch filter.
+func (h *MythicPlusBuildsAnalysisHandler) parseSegmentFilter(c *gin.Context) (service.SegmentFilter, bool) {
+	var filter service.SegmentFilter
+
+	patch, err := h.MythicPlusBuildsAnalysisService.ResolvePatch(c.Request.Context(), strings.ToLower(c.DefaultQuery("patch", warcraftlogsBuilds.PatchCurrent)))
+	if err != nil {
+		if errors.Is(err, warcraftlogsBuilds.ErrUnknownPatch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return filter, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if patch != nil {
+		filter.Patch = patch.Name
+	}
+
+	if affixStr := c.Query("affix"); affixStr != "" {
+		for _, part := range strings.Split(affixStr, ",") {
+			affixID, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil || affixID <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid affix format, expected comma-separated affix IDs"})
+				return filter, false
+			}
+			filter.Affixes = append(filter.Affixes, affixID)
+		}
+	}
+
+	if minKeyStr := c.Query("minKey"); minKeyStr != "" {
+		minKey, err := strconv.Atoi(minKeyStr)
+		if err != nil || minKey < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid minKey format"})
+			return filter, false
+		}
+		filter.MinKeyLevel = minKey
+	}
+
+	if maxKeyStr := c.Query("maxKey"); maxKeyStr != "" {
+		maxKey, err := strconv.Atoi(maxKeyStr)
+		if err != nil || maxKey < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid maxKey format"})
+			return filter, false
+		}
+		filter.MaxKeyLevel = maxKey
+	}
+
+	if filter.MinKeyLevel > 0 && filter.MaxKeyLevel > 0 && filter.MinKeyLevel > filter.MaxKeyLevel {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minKey must not be greater than maxKey"})
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// parseOrder reads the ordering of the items, enchants and gems routes, writing a bad request when it is unknown
+func parseOrder(c *gin.Context) (string, bool) {
+	order := strings.ToLower(c.DefaultQuery("order", service.OrderPopularity))
+	if !service.IsValidOrder(order) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order, expected popularity or performance"})
+		return "", false
+	}
+	return order, true
+}
+
+// getPerformanceWeightedChoices writes the choices of a type ordered by weighted lift
+func (h *MythicPlusBuildsAnalysisHandler) getPerformanceWeightedChoices(c *gin.Context, class, spec, choiceType string, encounterID *int) {
+	choices, err := h.MythicPlusBuildsAnalysisService.GetPerformanceWeightedChoices(c.Request.Context(), class, spec, choiceType, encounterID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, choices)
+}
+
+// NormalizeWoWTerms converts a class or spec name from the query to the format stored in the statistics
+func NormalizeWoWTerms(term string) string {
 	// Special cases for composed names
 	term = strings.ToLower(term)
 
@@ -385,9 +1255,15 @@ Top talent builds
 Talent builds by dungeon
 /warcraftlogs/mythicplus/builds/analysis/talents/dungeons?class=priest&spec=discipline
 
+Talent tree pick rates
+/warcraftlogs/mythicplus/builds/analysis/talents/tree?class=priest&spec=discipline&encounter_id=12648
+
 Stat priorities
 /warcraftlogs/mythicplus/builds/analysis/stats?class=priest&spec=discipline
 
+Stat weights
+/warcraftlogs/mythicplus/builds/analysis/stats/weights?class=priest&spec=discipline&metric=score
+
 Optimal build
 /warcraftlogs/mythicplus/builds/analysis/optimal?class=priest&spec=discipline
 
@@ -398,4 +1274,43 @@ Class spec summary
 Spec comparison
 /warcraftlogs/mythicplus/builds/analysis/specs/comparison?class=priest
 
+Performance percentiles
+/warcraftlogs/mythicplus/builds/analysis/performance?class=priest&spec=discipline&encounter_id=12648&bracket=12%2B
+
+Ability usage
+/warcraftlogs/mythicplus/builds/analysis/ability-usage?class=warrior&spec=fury&metric=cooldowns_per_minute
+
+Run pacing
+/warcraftlogs/mythicplus/builds/analysis/pacing/report?code=g9Lhy8JmkV1xQ3Gj&fight_id=26
+
+Dungeon pacing
+/warcraftlogs/mythicplus/builds/analysis/pacing?encounter_id=12660&keystone_level=15
+
+Build validation rates per spec
+/warcraftlogs/mythicplus/builds/analysis/validation?class=priest&spec=discipline&days=7
+
+Runs with the most rejected builds
+/warcraftlogs/mythicplus/builds/analysis/validation/runs?encounter_id=12660&days=7&limit=50
+
+Weekly item trends of a slot
+/warcraftlogs/mythicplus/builds/analysis/trends/items?class=priest&spec=discipline&slot=0&from=2026-09-01
+
+Weekly talent import trends
+/warcraftlogs/mythicplus/builds/analysis/trends/talents?class=priest&spec=discipline&limit=3
+
+Weekly stat trends
+/warcraftlogs/mythicplus/builds/analysis/trends/stats?class=priest&spec=discipline
+
+Items, talents, stats and optimal build restricted to an affix set and a key level range
+/warcraftlogs/mythicplus/builds/analysis/items?class=priest&spec=discipline&affix=9,10&minKey=12
+/warcraftlogs/mythicplus/builds/analysis/talents/top?class=priest&spec=discipline&minKey=7&maxKey=11
+/warcraftlogs/mythicplus/builds/analysis/stats?class=priest&spec=discipline&affix=152&maxKey=6
+
+Patches of the registry
+/warcraftlogs/mythicplus/builds/analysis/patches
+
+Items, talents, stats and optimal build default to the current patch, restricted to another patch or spanning every patch
+/warcraftlogs/mythicplus/builds/analysis/items?class=priest&spec=discipline&patch=11.1.0
+/warcraftlogs/mythicplus/builds/analysis/optimal?class=priest&spec=discipline&patch=all
+
 */
@@ -0,0 +1,240 @@
+package WarcraftLogsMythicPlusDungeonsAnalysis
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	service "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
+)
+
+// MythicPlusDungeonsAnalysisHandler handles API endpoints for the mythic + dungeons combat analysis
+type MythicPlusDungeonsAnalysisHandler struct {
+	MythicPlusDungeonAnalysisService *service.DungeonAnalysisService
+}
+
+// NewMythicPlusDungeonsAnalysisHandler creates a new MythicPlusDungeonsAnalysisHandler
+func NewMythicPlusDungeonsAnalysisHandler(analysisService *service.DungeonAnalysisService) *MythicPlusDungeonsAnalysisHandler {
+	return &MythicPlusDungeonsAnalysisHandler{MythicPlusDungeonAnalysisService: analysisService}
+}
+
+// GetDungeonDeathAnalysis returns the death analysis of a dungeon for a key level bracket
+// @Summary Get dungeon death analysis
+// @Description Returns the top killing abilities, the specs dying the most and when deaths happen in the run
+// @Tags Mythic+ Dungeons Analysis
+// @Accept json
+// @Produce json
+// @Param encounterId path int true "Encounter ID of the dungeon"
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param limit query int false "Number of killing abilities to return"
+// @Success 200 {object} service.DungeonDeathAnalysis
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/dungeons/{encounterId}/deaths [get]
+func (h *MythicPlusDungeonsAnalysisHandler) GetDungeonDeathAnalysis(c *gin.Context) {
+	encounterID, err := strconv.Atoi(c.Param("encounterId"))
+	if err != nil || encounterID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounterId format"})
+		return
+	}
+
+	bracket, ok := parseBracket(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	limit := parseLimit(c, 10)
+
+	analysis, err := h.MythicPlusDungeonAnalysisService.GetDungeonDeathAnalysis(c.Request.Context(), encounterID, bracket, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetTopKillers returns the abilities killing the most players across all dungeons this week
+// @Summary Get the biggest killers of the week
+// @Description Returns the abilities killing the most players across all dungeons for the last analysis window
+// @Tags Mythic+ Dungeons Analysis
+// @Accept json
+// @Produce json
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param limit query int false "Number of abilities to return"
+// @Success 200 {array} service.TopKiller
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/dungeons/deaths/top-killers [get]
+func (h *MythicPlusDungeonsAnalysisHandler) GetTopKillers(c *gin.Context) {
+	bracket, ok := parseBracket(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	limit := parseLimit(c, 10)
+
+	killers, err := h.MythicPlusDungeonAnalysisService.GetTopKillers(c.Request.Context(), bracket, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, killers)
+}
+
+// GetDungeonDamageTaken returns the damage taken hotspots of a dungeon for a key level bracket
+// @Summary Get dungeon damage taken hotspots
+// @Description Returns the most damaging enemy abilities normalized by key level and run duration, with the damage taken by the groups including each spec
+// @Tags Mythic+ Dungeons Analysis
+// @Accept json
+// @Produce json
+// @Param encounterId path int true "Encounter ID of the dungeon"
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param role query string false "Role of the specs to compare (tank, healer, dps)"
+// @Param limit query int false "Number of abilities to return"
+// @Success 200 {object} service.DungeonDamageTakenAnalysis
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/dungeons/{encounterId}/damage-taken [get]
+func (h *MythicPlusDungeonsAnalysisHandler) GetDungeonDamageTaken(c *gin.Context) {
+	encounterID, err := strconv.Atoi(c.Param("encounterId"))
+	if err != nil || encounterID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounterId format"})
+		return
+	}
+
+	bracket, ok := parseBracket(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	role := c.Query("role")
+	if role != "" && role != "tank" && role != "healer" && role != "dps" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role, expected one of tank, healer, dps"})
+		return
+	}
+
+	limit := parseLimit(c, 10)
+
+	analysis, err := h.MythicPlusDungeonAnalysisService.GetDungeonDamageTakenAnalysis(c.Request.Context(), encounterID, bracket, role, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetCompositions returns the most played or most successful group compositions across all dungeons
+// @Summary Get group compositions
+// @Description Returns the complete group compositions (1 tank, 1 healer, 3 dps) of the reports with their usage and success rate
+// @Tags Mythic+ Dungeons Analysis
+// @Accept json
+// @Produce json
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param min_usage query int false "Minimum number of runs of a composition"
+// @Param sort query string false "Sort order (usage, success)"
+// @Param limit query int false "Number of compositions to return"
+// @Success 200 {array} service.CompositionStat
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/dungeons/compositions [get]
+func (h *MythicPlusDungeonsAnalysisHandler) GetCompositions(c *gin.Context) {
+	h.getCompositions(c, 0)
+}
+
+// GetDungeonCompositions returns the most played or most successful group compositions of a dungeon
+// @Summary Get dungeon group compositions
+// @Description Returns the complete group compositions (1 tank, 1 healer, 3 dps) of a dungeon with their usage and success rate
+// @Tags Mythic+ Dungeons Analysis
+// @Accept json
+// @Produce json
+// @Param encounterId path int true "Encounter ID of the dungeon"
+// @Param bracket query string false "Key level bracket (all, 2-6, 7-11, 12+)"
+// @Param min_usage query int false "Minimum number of runs of a composition"
+// @Param sort query string false "Sort order (usage, success)"
+// @Param limit query int false "Number of compositions to return"
+// @Success 200 {array} service.CompositionStat
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/dungeons/{encounterId}/compositions [get]
+func (h *MythicPlusDungeonsAnalysisHandler) GetDungeonCompositions(c *gin.Context) {
+	encounterID, err := strconv.Atoi(c.Param("encounterId"))
+	if err != nil || encounterID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encounterId format"})
+		return
+	}
+
+	h.getCompositions(c, encounterID)
+}
+
+// getCompositions parses the composition query parameters and returns the compositions of a dungeon (0 for all)
+func (h *MythicPlusDungeonsAnalysisHandler) getCompositions(c *gin.Context, encounterID int) {
+	bracket, ok := parseBracket(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bracket, expected one of all, 2-6, 7-11, 12+"})
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort", service.CompositionSortUsage)
+	if sortBy != service.CompositionSortUsage && sortBy != service.CompositionSortSuccess {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort, expected one of usage, success"})
+		return
+	}
+
+	minUsage, err := strconv.Atoi(c.DefaultQuery("min_usage", "5"))
+	if err != nil || minUsage < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_usage parameter"})
+		return
+	}
+
+	limit := parseLimit(c, 20)
+
+	compositions, err := h.MythicPlusDungeonAnalysisService.GetCompositionStats(c.Request.Context(), encounterID, bracket, minUsage, limit, sortBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, compositions)
+}
+
+// parseBracket reads the key level bracket query parameter, defaulting to all key levels
+func parseBracket(c *gin.Context) (string, bool) {
+	bracket := c.DefaultQuery("bracket", warcraftlogsBuilds.KeyLevelBracketAll)
+	return bracket, warcraftlogsBuilds.IsValidKeyLevelBracket(bracket)
+}
+
+// parseLimit reads the limit query parameter, falling back on the default value when invalid
+func parseLimit(c *gin.Context, defaultLimit int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		return defaultLimit
+	}
+	return limit
+}
+
+/*
+
+Death analysis of a dungeon
+/warcraftlogs/mythicplus/dungeons/12661/deaths?bracket=12%2B&limit=10
+
+Biggest killers of the week across all dungeons
+/warcraftlogs/mythicplus/dungeons/deaths/top-killers?bracket=all&limit=20
+
+Damage taken hotspots of a dungeon compared between the healer specs
+/warcraftlogs/mythicplus/dungeons/12661/damage-taken?bracket=12%2B&role=healer&limit=10
+
+Most played compositions across all dungeons
+/warcraftlogs/mythicplus/dungeons/compositions?bracket=all&min_usage=5&limit=20
+
+Most successful compositions of a dungeon in high keys
+/warcraftlogs/mythicplus/dungeons/12661/compositions?bracket=12%2B&sort=success&min_usage=10
+
+*/
@@ -0,0 +1,225 @@
+package warcraftlogs
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	dungeons "wowperf/internal/services/warcraftlogs/dungeons"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseGroupQuery parses the common parameters of the guild and realm leaderboards
+func parseGroupQuery(c *gin.Context, scope string) (dungeons.GroupLeaderboardQuery, error) {
+	query := dungeons.GroupLeaderboardQuery{
+		Scope:   scope,
+		Region:  c.Query("region"),
+		Realm:   c.Query("realm"),
+		Guild:   c.Query("guild"),
+		OrderBy: c.Query("orderBy"),
+		Limit:   getLimitParam(c),
+	}
+
+	if topNStr := c.Query("top_n"); topNStr != "" {
+		topN, err := strconv.Atoi(topNStr)
+		if err != nil || topN < 1 {
+			return query, errors.New("invalid top_n")
+		}
+		query.TopN = topN
+	}
+
+	if thresholdsStr := c.Query("thresholds"); thresholdsStr != "" {
+		for _, value := range strings.Split(thresholdsStr, ",") {
+			threshold, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || threshold < 0 {
+				return query, errors.New("invalid thresholds")
+			}
+			query.Thresholds = append(query.Thresholds, threshold)
+		}
+	}
+
+	if dateStr := c.Query("date"); dateStr != "" {
+		asOf, err := parseAsOfDate(dateStr)
+		if err != nil {
+			return query, errors.New("invalid date format. Use YYYY-MM-DD or RFC3339")
+		}
+		query.AsOf = &asOf
+	}
+	query.SeasonSlug = c.Query("season")
+
+	return query, nil
+}
+
+// getDaysParam returns the days query parameter, 30 by default
+func getDaysParam(c *gin.Context) (int, error) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		return 0, errors.New("invalid days")
+	}
+	return days, nil
+}
+
+// GetGuildLeaderboard returns the guild leaderboard
+// @Summary Get the guild leaderboard
+// @Description Returns the guilds ranked by the average score of their best players, the number of players above score thresholds, their member count or their best player. Only the players qualified for the global leaderboard are counted, and they belong to the guild of their latest ranked run. The leaderboard is read from the latest rankings snapshot, from the snapshot at a date or from the final standings of a season.
+// @Tags Mythic+ Leaderboard
+// @Produce json
+// @Param orderBy query string false "Ranking metric (top_average, players_above, member_count, best_score), top_average by default"
+// @Param top_n query int false "Number of best players averaged, 5 by default and 50 at most"
+// @Param thresholds query string false "Comma separated score thresholds, 2500,3000,3500 by default. players_above ranks by the first one"
+// @Param region query string false "Filter by region (EU, US, KR, TW)"
+// @Param realm query string false "Filter by the realm of the guilds"
+// @Param guild query string false "Search guilds by name, ranks are kept"
+// @Param date query string false "Date of the rankings, as YYYY-MM-DD for the end of the day or RFC3339"
+// @Param season query string false "Season slug, for the final standings of the season"
+// @Param limit query int false "Number of guilds, 100 by default"
+// @Success 200 {object} dungeons.GroupLeaderboard
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/guilds [get]
+func (h *GlobalLeaderboardHandler) GetGuildLeaderboard(c *gin.Context) {
+	h.getGroupLeaderboard(c, dungeons.GroupScopeGuild)
+}
+
+// GetRealmLeaderboard returns the realm leaderboard
+// @Summary Get the realm leaderboard
+// @Description Returns the realms ranked by the average score of their best players, the number of players above score thresholds, their player count or their best player. Only the players qualified for the global leaderboard are counted. The leaderboard is read from the latest rankings snapshot, from the snapshot at a date or from the final standings of a season.
+// @Tags Mythic+ Leaderboard
+// @Produce json
+// @Param orderBy query string false "Ranking metric (top_average, players_above, member_count, best_score), top_average by default"
+// @Param top_n query int false "Number of best players averaged, 5 by default and 50 at most"
+// @Param thresholds query string false "Comma separated score thresholds, 2500,3000,3500 by default. players_above ranks by the first one"
+// @Param region query string false "Filter by region (EU, US, KR, TW)"
+// @Param date query string false "Date of the rankings, as YYYY-MM-DD for the end of the day or RFC3339"
+// @Param season query string false "Season slug, for the final standings of the season"
+// @Param limit query int false "Number of realms, 100 by default"
+// @Success 200 {object} dungeons.GroupLeaderboard
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/realms [get]
+func (h *GlobalLeaderboardHandler) GetRealmLeaderboard(c *gin.Context) {
+	h.getGroupLeaderboard(c, dungeons.GroupScopeRealm)
+}
+
+// getGroupLeaderboard returns the guild or realm leaderboard
+func (h *GlobalLeaderboardHandler) getGroupLeaderboard(c *gin.Context, scope string) {
+	query, err := parseGroupQuery(c, scope)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaderboard, err := h.rankingsService.GetGroupLeaderboard(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, dungeons.ErrInvalidLeaderboardFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error getting %s leaderboard: %v", scope, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get " + scope + " leaderboard"})
+		return
+	}
+	if leaderboard == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rankings found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// GetGuildHistory returns the evolution of a guild
+// @Summary Get the history of a guild
+// @Description Returns the rank and the metrics of a guild in each rankings snapshot of the last days. Ranks are computed among all guilds, or among the guilds of a region.
+// @Tags Mythic+ Leaderboard History
+// @Produce json
+// @Param guild_id query int true "WarcraftLogs guild ID"
+// @Param days query int false "Number of days, 30 by default and 365 at most"
+// @Param orderBy query string false "Ranking metric (top_average, players_above, member_count, best_score), top_average by default"
+// @Param top_n query int false "Number of best players averaged, 5 by default and 50 at most"
+// @Param thresholds query string false "Comma separated score thresholds, 2500,3000,3500 by default"
+// @Param region query string false "Rank among the guilds of a region (EU, US, KR, TW)"
+// @Success 200 {object} dungeons.GroupHistory
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/guilds/history [get]
+func (h *GlobalLeaderboardHandler) GetGuildHistory(c *gin.Context) {
+	guildID, err := strconv.Atoi(c.Query("guild_id"))
+	if err != nil || guildID < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid guild_id"})
+		return
+	}
+	days, err := getDaysParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := parseGroupQuery(c, dungeons.GroupScopeGuild)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.rankingsService.GetGuildHistory(c.Request.Context(), query, guildID, days)
+	if err != nil {
+		if errors.Is(err, dungeons.ErrInvalidLeaderboardFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error getting history of guild %d: %v", guildID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get guild history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetRealmHistory returns the evolution of a realm
+// @Summary Get the history of a realm
+// @Description Returns the rank and the metrics of a realm in each rankings snapshot of the last days. Ranks are computed among the realms of its region.
+// @Tags Mythic+ Leaderboard History
+// @Produce json
+// @Param realm query string true "Realm name or slug"
+// @Param region query string true "Region of the realm (EU, US, KR, TW)"
+// @Param days query int false "Number of days, 30 by default and 365 at most"
+// @Param orderBy query string false "Ranking metric (top_average, players_above, member_count, best_score), top_average by default"
+// @Param top_n query int false "Number of best players averaged, 5 by default and 50 at most"
+// @Param thresholds query string false "Comma separated score thresholds, 2500,3000,3500 by default"
+// @Success 200 {object} dungeons.GroupHistory
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/realms/history [get]
+func (h *GlobalLeaderboardHandler) GetRealmHistory(c *gin.Context) {
+	realm, region := c.Query("realm"), c.Query("region")
+	if realm == "" || region == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Realm and region parameters are required"})
+		return
+	}
+	days, err := getDaysParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := parseGroupQuery(c, dungeons.GroupScopeRealm)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.rankingsService.GetRealmHistory(c.Request.Context(), query, realm, region, days)
+	if err != nil {
+		if errors.Is(err, dungeons.ErrInvalidLeaderboardFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error getting history of realm %s-%s: %v", realm, region, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get realm history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
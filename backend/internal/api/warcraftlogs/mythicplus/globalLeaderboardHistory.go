@@ -0,0 +1,152 @@
+package warcraftlogs
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	dungeons "wowperf/internal/services/warcraftlogs/dungeons"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseAsOfDate parses a date as RFC3339, or as a day which includes the whole day
+func parseAsOfDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// getLimitParam returns the limit query parameter, 100 by default
+func getLimitParam(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		limit = 100
+	}
+	return limit
+}
+
+// GetLeaderboardAsOf returns the global leaderboard as it was at a date
+// @Summary Get the global leaderboard at a date
+// @Description Returns the global leaderboard of the latest rankings snapshot captured at the date, with optional role, class and spec filters
+// @Tags Mythic+ Leaderboard History
+// @Produce json
+// @Param date query string true "Date, as YYYY-MM-DD for the end of the day or RFC3339"
+// @Param role query string false "Filter by role (Tank, Healer, DPS)"
+// @Param class query string false "Filter by class name"
+// @Param spec query string false "Filter by spec name"
+// @Param limit query int false "Number of players, 100 by default"
+// @Param orderBy query string false "Sort column, score by default"
+// @Param direction query string false "Sort direction (ASC, DESC)"
+// @Success 200 {object} dungeons.HistoricalLeaderboard
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/history [get]
+func (h *GlobalLeaderboardHandler) GetLeaderboardAsOf(c *gin.Context) {
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Date parameter is required"})
+		return
+	}
+	asOf, err := parseAsOfDate(dateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD or RFC3339"})
+		return
+	}
+
+	orderBy, direction := getOrderParams(c)
+
+	leaderboard, err := h.rankingsService.GetGlobalLeaderboardAsOf(c.Request.Context(), asOf,
+		c.Query("role"), c.Query("class"), c.Query("spec"), getLimitParam(c), orderBy, direction)
+	if err != nil {
+		if errors.Is(err, dungeons.ErrInvalidLeaderboardFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error getting leaderboard as of %s: %v", dateStr, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard history"})
+		return
+	}
+	if leaderboard == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rankings captured at this date"})
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// GetSeasonFinalLeaderboard returns the final standings of a season
+// @Summary Get the final standings of a season
+// @Description Returns the global leaderboard of the archived snapshot of a season, with optional role, class and spec filters
+// @Tags Mythic+ Leaderboard History
+// @Produce json
+// @Param season query string true "Season slug"
+// @Param role query string false "Filter by role (Tank, Healer, DPS)"
+// @Param class query string false "Filter by class name"
+// @Param spec query string false "Filter by spec name"
+// @Param limit query int false "Number of players, 100 by default"
+// @Param orderBy query string false "Sort column, score by default"
+// @Param direction query string false "Sort direction (ASC, DESC)"
+// @Success 200 {object} dungeons.HistoricalLeaderboard
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/season [get]
+func (h *GlobalLeaderboardHandler) GetSeasonFinalLeaderboard(c *gin.Context) {
+	season := c.Query("season")
+	if season == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Season parameter is required"})
+		return
+	}
+
+	orderBy, direction := getOrderParams(c)
+
+	leaderboard, err := h.rankingsService.GetSeasonFinalLeaderboard(c.Request.Context(), season,
+		c.Query("role"), c.Query("class"), c.Query("spec"), getLimitParam(c), orderBy, direction)
+	if err != nil {
+		if errors.Is(err, dungeons.ErrInvalidLeaderboardFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error getting final standings of season %s: %v", season, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get season final standings"})
+		return
+	}
+	if leaderboard == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No final standings for this season"})
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// GetRankingsSnapshots returns the rankings snapshots
+// @Summary Get the rankings snapshots
+// @Description Returns the rankings snapshots from the latest one, optionally filtered by season and restricted to the final standings of the seasons
+// @Tags Mythic+ Leaderboard History
+// @Produce json
+// @Param season query string false "Season slug"
+// @Param final query bool false "Only the final standings of the seasons"
+// @Success 200 {array} models.PlayerRankingsSnapshot
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/snapshots [get]
+func (h *GlobalLeaderboardHandler) GetRankingsSnapshots(c *gin.Context) {
+	finalOnly := c.Query("final") == "true"
+
+	snapshots, err := h.rankingsService.GetRankingsSnapshots(c.Request.Context(), c.Query("season"), finalOnly)
+	if err != nil {
+		log.Printf("Error getting rankings snapshots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rankings snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
@@ -0,0 +1,91 @@
+package warcraftlogs
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	dungeons "wowperf/internal/services/warcraftlogs/dungeons"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseScoreParam parses an optional score query parameter
+func parseScoreParam(c *gin.Context, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	score, err := strconv.ParseFloat(value, 64)
+	if err != nil || score < 0 {
+		return nil, errors.New("invalid " + name)
+	}
+	return &score, nil
+}
+
+// GetLeaderboardPage returns a page of the leaderboard with the cursor of the next page
+// @Summary Get a page of the leaderboard
+// @Description Returns a page of the leaderboard of the latest rankings snapshot, filtered by region, realm, class, spec, role, score range and dungeon. The next pages are read with next_cursor from the same snapshot, so paging stays consistent while the rankings are updated.
+// @Tags Mythic+ Leaderboard
+// @Produce json
+// @Param cursor query string false "Cursor of the page, from next_cursor of the previous page"
+// @Param limit query int false "Number of players, 100 by default and 1000 at most"
+// @Param region query string false "Filter by region (EU, US, KR, TW)"
+// @Param realm query string false "Filter by realm name or slug"
+// @Param class query string false "Filter by class name"
+// @Param spec query string false "Filter by spec name"
+// @Param role query string false "Filter by role (Tank, Healer, DPS)"
+// @Param min_score query number false "Minimum score"
+// @Param max_score query number false "Maximum score"
+// @Param dungeon_id query int false "Rank the best run of the players in a dungeon, by encounter ID"
+// @Success 200 {object} dungeons.LeaderboardPage
+// @Failure 400 {object} gin.H
+// @Failure 410 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /warcraftlogs/mythicplus/global/leaderboard/page [get]
+func (h *GlobalLeaderboardHandler) GetLeaderboardPage(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	filter := dungeons.LeaderboardFilter{
+		Region: c.Query("region"),
+		Realm:  c.Query("realm"),
+		Class:  c.Query("class"),
+		Spec:   c.Query("spec"),
+		Role:   c.Query("role"),
+	}
+	if filter.MinScore, err = parseScoreParam(c, "min_score"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.MaxScore, err = parseScoreParam(c, "max_score"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if dungeonIDStr := c.Query("dungeon_id"); dungeonIDStr != "" {
+		if filter.DungeonID, err = strconv.Atoi(dungeonIDStr); err != nil || filter.DungeonID < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dungeon_id"})
+			return
+		}
+	}
+
+	page, err := h.rankingsService.GetLeaderboardPage(c.Request.Context(), filter, c.Query("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, dungeons.ErrInvalidLeaderboardFilter), errors.Is(err, dungeons.ErrInvalidLeaderboardCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, dungeons.ErrExpiredLeaderboardCursor):
+			c.JSON(http.StatusGone, gin.H{"error": "The rankings of this cursor are no longer available, restart from the first page"})
+		default:
+			log.Printf("Error getting leaderboard page: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard page"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,151 @@
+package WarcraftLogsMythicPlusPlayers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	service "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
+)
+
+// MythicPlusPlayersHandler handles API endpoints for the canonical identities of the players
+type MythicPlusPlayersHandler struct {
+	PlayerAnalysisService *service.PlayerAnalysisService
+}
+
+// NewMythicPlusPlayersHandler creates a new MythicPlusPlayersHandler
+func NewMythicPlusPlayersHandler(playerAnalysisService *service.PlayerAnalysisService) *MythicPlusPlayersHandler {
+	return &MythicPlusPlayersHandler{PlayerAnalysisService: playerAnalysisService}
+}
+
+// GetPlayerAliases returns the names and realms used by a player across its renames and realm transfers
+// @Summary Get the alias history of a player
+// @Description Returns the canonical identity of a player with the names and realms it used, from the latest one. The player is found by identity ID, or by one of its names and realms.
+// @Tags Mythic+ Players
+// @Produce json
+// @Param identity_id query int false "Player identity ID"
+// @Param name query string false "Character name, required without identity_id"
+// @Param server query string false "Realm name or slug, required without identity_id"
+// @Param region query string false "Region (EU, US, KR, TW)"
+// @Success 200 {object} service.PlayerAliasHistory
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Player not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/players/aliases [get]
+func (h *MythicPlusPlayersHandler) GetPlayerAliases(c *gin.Context) {
+	identityID, ok := parseIdentityID(c)
+	if !ok {
+		return
+	}
+
+	name := c.Query("name")
+	server := c.Query("server")
+	if identityID == nil && (name == "" || server == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "identity_id or name and server are required"})
+		return
+	}
+
+	history, err := h.PlayerAnalysisService.GetPlayerAliasHistory(c.Request.Context(), name, server, c.Query("region"), identityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if history == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// SearchPlayers returns the players matching a name in the rankings and the Raider.IO runs
+// @Summary Search players
+// @Description Returns the players whose name matches the search, tolerating typos and partial names. Exact names come first, then the closest ones.
+// @Tags Mythic+ Players
+// @Produce json
+// @Param q query string true "Character name, at least 2 characters"
+// @Param server query string false "Realm name or slug"
+// @Param region query string false "Region (EU, US, KR, TW)"
+// @Param limit query int false "Number of players, 20 by default and 100 at most"
+// @Success 200 {array} service.PlayerSearchResult
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/players/search [get]
+func (h *MythicPlusPlayersHandler) SearchPlayers(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must have at least 2 characters"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	results, err := h.PlayerAnalysisService.SearchPlayers(c.Request.Context(), query, c.Query("server"), c.Query("region"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetPlayerProfile returns the profile of a player
+// @Summary Get the profile of a player
+// @Description Returns the best run per dungeon, the score of the active season, the spec history and the latest builds of a player. The player is found by identity ID, or by its name and realm.
+// @Tags Mythic+ Players
+// @Produce json
+// @Param identity_id query int false "Player identity ID"
+// @Param name query string false "Character name, required without identity_id"
+// @Param server query string false "Realm name or slug, required without identity_id"
+// @Param region query string false "Region (EU, US, KR, TW)"
+// @Success 200 {object} service.PlayerProfile
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Player not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/players/profile [get]
+func (h *MythicPlusPlayersHandler) GetPlayerProfile(c *gin.Context) {
+	identityID, ok := parseIdentityID(c)
+	if !ok {
+		return
+	}
+
+	name := c.Query("name")
+	server := c.Query("server")
+	if identityID == nil && (name == "" || server == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "identity_id or name and server are required"})
+		return
+	}
+
+	profile, err := h.PlayerAnalysisService.GetPlayerProfile(c.Request.Context(), name, server, c.Query("region"), identityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// parseIdentityID parses the optional identity_id query parameter, responding with a bad request when it is invalid
+func parseIdentityID(c *gin.Context) (*uint, bool) {
+	identityIDStr := c.Query("identity_id")
+	if identityIDStr == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseUint(identityIDStr, 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identity_id"})
+		return nil, false
+	}
+	value := uint(id)
+	return &value, true
+}
@@ -0,0 +1,107 @@
+package WarcraftLogsMythicPlusReportsAnalysis
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	service "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
+)
+
+// MythicPlusReportAnalysisHandler handles API endpoints for the on-demand analysis of a report
+type MythicPlusReportAnalysisHandler struct {
+	ReportAnalysisService *service.ReportAnalysisService
+}
+
+// NewMythicPlusReportAnalysisHandler creates a new MythicPlusReportAnalysisHandler
+func NewMythicPlusReportAnalysisHandler(reportAnalysisService *service.ReportAnalysisService) *MythicPlusReportAnalysisHandler {
+	return &MythicPlusReportAnalysisHandler{ReportAnalysisService: reportAnalysisService}
+}
+
+// SubmitReportAnalysisRequest is the body of a report analysis request
+type SubmitReportAnalysisRequest struct {
+	Report  string `json:"report" binding:"required"` // Report code or report URL
+	FightID int    `json:"fight_id"`                  // Optional, overrides the fight of the URL
+}
+
+// SubmitReportAnalysis starts the analysis of a public report
+// @Summary Submit a report analysis
+// @Description Starts the comparison of the players of a report with the top players of the same spec and dungeon
+// @Tags Mythic+ Reports Analysis
+// @Accept json
+// @Produce json
+// @Param request body SubmitReportAnalysisRequest true "Report code or URL"
+// @Success 202 {object} service.ReportAnalysisJobStatus
+// @Failure 400 {object} string "Bad request"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/reports/analysis [post]
+func (h *MythicPlusReportAnalysisHandler) SubmitReportAnalysis(c *gin.Context) {
+	SubmitReportAnalysis(c, h.ReportAnalysisService, nil)
+}
+
+// GetReportAnalysisJob returns the status of a report analysis, with its result once completed
+// @Summary Get a report analysis
+// @Description Returns the status of a report analysis job, the comparison is returned once the job is completed. Jobs submitted with a linked WarcraftLogs account are only returned on /auth/warcraftlogs/reports/analysis/{jobId}
+// @Tags Mythic+ Reports Analysis
+// @Accept json
+// @Produce json
+// @Param jobId path string true "ID of the report analysis job"
+// @Success 200 {object} service.ReportAnalysisJobStatus
+// @Failure 404 {object} string "Not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/mythicplus/reports/analysis/{jobId} [get]
+func (h *MythicPlusReportAnalysisHandler) GetReportAnalysisJob(c *gin.Context) {
+	GetReportAnalysisJob(c, h.ReportAnalysisService, nil)
+}
+
+// GetReportAnalysisJob returns the status of the job of the jobId parameter
+// userID is the authenticated user, the jobs submitted with a linked account are only returned to their owner.
+func GetReportAnalysisJob(c *gin.Context, reportAnalysisService *service.ReportAnalysisService, userID *uint) {
+	job, err := reportAnalysisService.GetReportAnalysisJob(c.Request.Context(), c.Param("jobId"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrReportAnalysisJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// SubmitReportAnalysis validates the request and submits the analysis
+// userID is set when the report is fetched with the WarcraftLogs account of the user.
+func SubmitReportAnalysis(c *gin.Context, reportAnalysisService *service.ReportAnalysisService, userID *uint) {
+	var request SubmitReportAnalysisRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "report is required"})
+		return
+	}
+	if request.FightID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fight_id"})
+		return
+	}
+
+	job, err := reportAnalysisService.SubmitReportAnalysis(c.Request.Context(), request.Report, request.FightID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReportReference) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+/*
+	POST /warcraftlogs/mythicplus/reports/analysis {"report": "https://www.warcraftlogs.com/reports/g9Lhy8JmkV1xQ3Gj#fight=26"}
+	POST /warcraftlogs/mythicplus/reports/analysis {"report": "g9Lhy8JmkV1xQ3Gj", "fight_id": 26}
+	GET  /warcraftlogs/mythicplus/reports/analysis/{jobId}   // Poll until status is completed or failed
+
+	POST /auth/warcraftlogs/reports/analysis {"report": "g9Lhy8JmkV1xQ3Gj"}   // Private reports of the linked account
+	GET  /auth/warcraftlogs/reports/analysis/{jobId}   // Jobs of the linked account are only polled by their owner
+*/
@@ -2,11 +2,14 @@
 package warcraftlogs
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 	dungeons "wowperf/internal/services/warcraftlogs/dungeons"
 
 	"github.com/gin-gonic/gin"
@@ -22,8 +25,24 @@ func NewSpecEvolutionMetricsAnalysisHandler(evolutionService *dungeons.SpecEvolu
 	return &SpecEvolutionMetricsAnalysisHandler{evolutionService: evolutionService}
 }
 
+// parsePatch reads the patch of the evolution routes, writing an error when it is invalid
+// The patch defaults to the current patch, "all" disables the patch filter.
+func (h *SpecEvolutionMetricsAnalysisHandler) parsePatch(c *gin.Context) (*warcraftlogsBuilds.GamePatch, bool) {
+	patch, err := h.evolutionService.GetPatch(c.Request.Context(), strings.ToLower(c.DefaultQuery("patch", warcraftlogsBuilds.PatchCurrent)))
+	if err != nil {
+		if errors.Is(err, warcraftlogsBuilds.ErrUnknownPatch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		log.Printf("Error resolving patch: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve patch"})
+		return nil, false
+	}
+	return patch, true
+}
+
 // GetSpecEvolution retrieves the evolution of metrics for a specialization
-// GET /warcraftlogs/mythicplus/evolution/spec?spec=Restoration&class=Druid&period=7&dungeon_id=1&date=2023-05-11
+// GET /warcraftlogs/mythicplus/evolution/spec?spec=Restoration&class=Druid&period=7&dungeon_id=1&date=2023-05-11&patch=current
 func (h *SpecEvolutionMetricsAnalysisHandler) GetSpecEvolution(c *gin.Context) {
 	spec := c.Query("spec")
 	if spec == "" {
@@ -62,8 +81,13 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetSpecEvolution(c *gin.Context) {
 
 	isGlobal := dungeonID == nil
 
+	patch, ok := h.parsePatch(c)
+	if !ok {
+		return
+	}
+
 	// Get evolution data
-	results, err := h.evolutionService.GetSpecEvolution(c.Request.Context(), spec, class, period, dungeonID, date, isGlobal)
+	results, err := h.evolutionService.GetSpecEvolution(c.Request.Context(), spec, class, period, dungeonID, date, isGlobal, patch)
 	if err != nil {
 		log.Printf("Error getting spec evolution: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spec evolution data"})
@@ -74,7 +98,7 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetSpecEvolution(c *gin.Context) {
 }
 
 // GetCurrentRanking retrieves the current ranking of all specializations
-// GET /warcraftlogs/mythicplus/evolution/ranking?role=dps&date=2023-05-11&is_global=true
+// GET /warcraftlogs/mythicplus/evolution/ranking?role=dps&date=2023-05-11&is_global=true&patch=current
 func (h *SpecEvolutionMetricsAnalysisHandler) GetCurrentRanking(c *gin.Context) {
 	roleStr := c.Query("role")
 	dateStr := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
@@ -96,8 +120,13 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetCurrentRanking(c *gin.Context)
 		rolePtr = &roleStr
 	}
 
+	patch, ok := h.parsePatch(c)
+	if !ok {
+		return
+	}
+
 	// Get ranking data
-	results, err := h.evolutionService.GetCurrentRanking(c.Request.Context(), rolePtr, date, isGlobal)
+	results, err := h.evolutionService.GetCurrentRanking(c.Request.Context(), rolePtr, date, isGlobal, patch)
 	if err != nil {
 		log.Printf("Error getting current ranking: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current ranking data"})
@@ -108,9 +137,14 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetCurrentRanking(c *gin.Context)
 }
 
 // GetLatestMetricsDate retrieves the latest date for which metrics are available
-// GET /warcraftlogs/mythicplus/evolution/latest-date
+// GET /warcraftlogs/mythicplus/evolution/latest-date?patch=current
 func (h *SpecEvolutionMetricsAnalysisHandler) GetLatestMetricsDate(c *gin.Context) {
-	latestDate, err := h.evolutionService.GetLatestMetricsDate(c.Request.Context())
+	patch, ok := h.parsePatch(c)
+	if !ok {
+		return
+	}
+
+	latestDate, err := h.evolutionService.GetLatestMetricsDate(c.Request.Context(), patch)
 	if err != nil {
 		log.Printf("Error getting latest metrics date: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get latest metrics date"})
@@ -172,7 +206,7 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetRoleSpecs(c *gin.Context) {
 }
 
 // GetSpecHistoricalData retrieves historical data for a spec over multiple dates
-// GET /warcraftlogs/mythicplus/evolution/spec/history?spec=Restoration&class=Druid&days=30&is_global=true&dungeon_id=1
+// GET /warcraftlogs/mythicplus/evolution/spec/history?spec=Restoration&class=Druid&days=30&is_global=true&dungeon_id=1&patch=current
 func (h *SpecEvolutionMetricsAnalysisHandler) GetSpecHistoricalData(c *gin.Context) {
 	spec := c.Query("spec")
 	if spec == "" {
@@ -209,8 +243,13 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetSpecHistoricalData(c *gin.Conte
 		}
 	}
 
+	patch, ok := h.parsePatch(c)
+	if !ok {
+		return
+	}
+
 	// Get historical data
-	results, err := h.evolutionService.GetSpecHistoricalData(c.Request.Context(), spec, class, days, isGlobal, dungeonID)
+	results, err := h.evolutionService.GetSpecHistoricalData(c.Request.Context(), spec, class, days, isGlobal, dungeonID, patch)
 	if err != nil {
 		log.Printf("Error getting spec historical data: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spec historical data"})
@@ -234,7 +273,7 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetAvailableDungeons(c *gin.Contex
 }
 
 // GetTopSpecsForDungeon retrieves the top performing specs for a specific dungeon
-// GET /warcraftlogs/mythicplus/evolution/dungeons/top-specs?dungeon_id=1&limit=10
+// GET /warcraftlogs/mythicplus/evolution/dungeons/top-specs?dungeon_id=1&limit=10&patch=current
 func (h *SpecEvolutionMetricsAnalysisHandler) GetTopSpecsForDungeon(c *gin.Context) {
 	dungeonIDStr := c.Query("dungeon_id")
 	if dungeonIDStr == "" {
@@ -256,8 +295,13 @@ func (h *SpecEvolutionMetricsAnalysisHandler) GetTopSpecsForDungeon(c *gin.Conte
 		limit = 10 // Default value
 	}
 
+	patch, ok := h.parsePatch(c)
+	if !ok {
+		return
+	}
+
 	// Get top specs
-	results, err := h.evolutionService.GetTopSpecsForDungeon(c.Request.Context(), dungeonID, limit)
+	results, err := h.evolutionService.GetTopSpecsForDungeon(c.Request.Context(), dungeonID, limit, patch)
 	if err != nil {
 		log.Printf("Error getting top specs for dungeon: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top specs for dungeon"})
@@ -281,7 +325,8 @@ Parameters:
 - class: string, optional, name of the class
 - period: int, optional, 7 or 30
 - dungeon_id: int, optional, id of the dungeon
-- date: string, optional, date in the format YYYY-MM-DD
+- date: string, optional, date in the format YYYY-MM-DD, clamped to the last day of the patch
+- patch: string, optional, patch name, current (default) or all. Metrics captured before the patch are not compared
 
 Example :
 GET /warcraftlogs/mythicplus/evolution/spec?spec=Restoration&class=Druid&period=7&dungeon_id=12648&date=2025-05-11
@@ -294,6 +339,7 @@ Parameters:
 - role: string, optional, name of the role
 - date: string, optional, date in the format YYYY-MM-DD
 - is_global: boolean, optional, true or false
+- patch: string, optional, patch name, current (default) or all
 
 Example :
 GET /warcraftlogs/mythicplus/evolution/ranking?role=dps&date=2025-05-11&is_global=true
@@ -303,7 +349,7 @@ GET /warcraftlogs/mythicplus/evolution/ranking?role=dps&date=2025-05-11&is_globa
 GET /warcraftlogs/mythicplus/evolution/latest-date
 
 Parameters:
-- None
+- patch: string, optional, patch name, current (default) or all
 
 Example :
 GET /warcraftlogs/mythicplus/evolution/latest-date
@@ -348,6 +394,7 @@ Parameters:
 - days: int, optional, number of days to get data for
 - is_global: boolean, optional, true or false
 - dungeon_id: int, optional, id of the dungeon
+- patch: string, optional, patch name, current (default) or all. The days of an ended patch are counted back from its end
 
 Example :
 GET /warcraftlogs/mythicplus/evolution/spec/history?spec=Restoration&class=Druid&days=30&is_global=true&dungeon_id=12648
@@ -369,6 +416,7 @@ GET /warcraftlogs/mythicplus/evolution/dungeons/top-specs
 Parameters:
 - dungeon_id: int, mandatory, id of the dungeon
 - limit: int, optional, number of specs to get
+- patch: string, optional, patch name, current (default) or all
 
 Example :
 GET /warcraftlogs/mythicplus/evolution/dungeons/top-specs?dungeon_id=12648&limit=10
@@ -391,6 +439,10 @@ GET /warcraftlogs/mythicplus/evolution/spec?spec=Restoration&class=Druid&period=
 # Get the complete history over 30 days
 GET /warcraftlogs/mythicplus/evolution/spec/history?spec=Restoration&class=Druid&days=30
 
+# Get the history of the last 30 days of a previous patch, or across every patch
+GET /warcraftlogs/mythicplus/evolution/spec/history?spec=Restoration&class=Druid&days=30&patch=11.1.0
+GET /warcraftlogs/mythicplus/evolution/spec/history?spec=Restoration&class=Druid&days=30&patch=all
+
 2. Analyse performance over time per role
 
 # Get the DPS ranking
@@ -0,0 +1,275 @@
+package WarcraftLogsRaidsAnalysis
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	buildsAnalysis "wowperf/internal/api/warcraftlogs/mythicplus/builds"
+	service "wowperf/internal/services/warcraftlogs/mythicplus/analytics"
+)
+
+// RaidsAnalysisHandler handles API endpoints for the raid bosses builds analysis
+type RaidsAnalysisHandler struct {
+	RaidAnalysisService *service.RaidAnalysisService
+}
+
+// NewRaidsAnalysisHandler creates a new RaidsAnalysisHandler
+func NewRaidsAnalysisHandler(analysisService *service.RaidAnalysisService) *RaidsAnalysisHandler {
+	return &RaidsAnalysisHandler{RaidAnalysisService: analysisService}
+}
+
+// GetRaids returns the raids and the bosses with builds statistics
+// @Summary Get raids
+// @Description Returns the raids and the bosses targeted by the builds analysis
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Success 200 {array} service.Raid
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids [get]
+func (h *RaidsAnalysisHandler) GetRaids(c *gin.Context) {
+	raids, err := h.RaidAnalysisService.GetRaids(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, raids)
+}
+
+// GetPopularItemsBySlot returns the most popular items for each slot for a specific class, spec and boss
+// @Summary Get popular items by slot for a raid boss
+// @Description Returns the most popular items for each slot for a specific class, spec and raid boss
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Param raid path string true "Raid slug"
+// @Param boss path string true "Boss slug"
+// @Param difficulty path string true "Raid difficulty (normal, heroic, mythic or 3, 4, 5)"
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Success 200 {array} service.ItemPopularity
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Raid boss not found at this difficulty"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids/{raid}/{boss}/{difficulty}/builds/items [get]
+func (h *RaidsAnalysisHandler) GetPopularItemsBySlot(c *gin.Context) {
+	class, spec, boss, ok := h.parseBossRequest(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.RaidAnalysisService.GetPopularItemsBySlot(c.Request.Context(), class, spec, boss.EncounterID, boss.Difficulty)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// GetEnchantUsage returns enchant usage statistics for a specific class, spec and boss
+// @Summary Get enchant usage for a raid boss
+// @Description Returns enchant usage statistics for a specific class, spec and raid boss
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Param raid path string true "Raid slug"
+// @Param boss path string true "Boss slug"
+// @Param difficulty path string true "Raid difficulty (normal, heroic, mythic or 3, 4, 5)"
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Success 200 {array} service.EnchantUsage
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Raid boss not found at this difficulty"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids/{raid}/{boss}/{difficulty}/builds/enchants [get]
+func (h *RaidsAnalysisHandler) GetEnchantUsage(c *gin.Context) {
+	class, spec, boss, ok := h.parseBossRequest(c)
+	if !ok {
+		return
+	}
+
+	enchants, err := h.RaidAnalysisService.GetEnchantUsage(c.Request.Context(), class, spec, boss.EncounterID, boss.Difficulty)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, enchants)
+}
+
+// GetGemUsage returns gem usage statistics for a specific class, spec and boss
+// @Summary Get gem usage for a raid boss
+// @Description Returns gem usage statistics for a specific class, spec and raid boss
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Param raid path string true "Raid slug"
+// @Param boss path string true "Boss slug"
+// @Param difficulty path string true "Raid difficulty (normal, heroic, mythic or 3, 4, 5)"
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Success 200 {array} service.GemUsage
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Raid boss not found at this difficulty"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids/{raid}/{boss}/{difficulty}/builds/gems [get]
+func (h *RaidsAnalysisHandler) GetGemUsage(c *gin.Context) {
+	class, spec, boss, ok := h.parseBossRequest(c)
+	if !ok {
+		return
+	}
+
+	gems, err := h.RaidAnalysisService.GetGemUsage(c.Request.Context(), class, spec, boss.EncounterID, boss.Difficulty)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gems)
+}
+
+// GetTopTalentBuilds returns the top talent builds for a specific class, spec and boss
+// @Summary Get top talent builds for a raid boss
+// @Description Returns the top talent builds for a specific class, spec and raid boss
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Param raid path string true "Raid slug"
+// @Param boss path string true "Boss slug"
+// @Param difficulty path string true "Raid difficulty (normal, heroic, mythic or 3, 4, 5)"
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Success 200 {array} service.TalentBuild
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Raid boss not found at this difficulty"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids/{raid}/{boss}/{difficulty}/builds/talents [get]
+func (h *RaidsAnalysisHandler) GetTopTalentBuilds(c *gin.Context) {
+	class, spec, boss, ok := h.parseBossRequest(c)
+	if !ok {
+		return
+	}
+
+	builds, err := h.RaidAnalysisService.GetTopTalentBuilds(c.Request.Context(), class, spec, boss.EncounterID, boss.Difficulty)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, builds)
+}
+
+// GetStatPriorities returns stat priority statistics for a specific class, spec and boss
+// @Summary Get stat priorities for a raid boss
+// @Description Returns stat priority statistics for a specific class, spec and raid boss
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Param raid path string true "Raid slug"
+// @Param boss path string true "Boss slug"
+// @Param difficulty path string true "Raid difficulty (normal, heroic, mythic or 3, 4, 5)"
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Success 200 {array} service.StatPriority
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Raid boss not found at this difficulty"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids/{raid}/{boss}/{difficulty}/builds/stats [get]
+func (h *RaidsAnalysisHandler) GetStatPriorities(c *gin.Context) {
+	class, spec, boss, ok := h.parseBossRequest(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.RaidAnalysisService.GetStatPriorities(c.Request.Context(), class, spec, boss.EncounterID, boss.Difficulty)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetOptimalBuild returns the optimal build for a specific class, spec and boss
+// @Summary Get optimal build for a raid boss
+// @Description Returns the most popular talents, stat priority and items for a specific class, spec and raid boss
+// @Tags Raids Builds Analysis
+// @Produce json
+// @Param raid path string true "Raid slug"
+// @Param boss path string true "Boss slug"
+// @Param difficulty path string true "Raid difficulty (normal, heroic, mythic or 3, 4, 5)"
+// @Param class query string true "Class name"
+// @Param spec query string true "Specialization name"
+// @Success 200 {object} service.OptimalBuild
+// @Failure 400 {object} string "Bad request"
+// @Failure 404 {object} string "Raid boss not found at this difficulty"
+// @Failure 500 {object} string "Internal server error"
+// @Router /warcraftlogs/raids/{raid}/{boss}/{difficulty}/builds/optimal [get]
+func (h *RaidsAnalysisHandler) GetOptimalBuild(c *gin.Context) {
+	class, spec, boss, ok := h.parseBossRequest(c)
+	if !ok {
+		return
+	}
+
+	build, err := h.RaidAnalysisService.GetOptimalBuild(c.Request.Context(), class, spec, boss.EncounterID, boss.Difficulty)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, build)
+}
+
+// raidDifficulties maps the difficulty names of the routes to the WarcraftLogs raid difficulties
+var raidDifficulties = map[string]int{
+	"normal": 3,
+	"heroic": 4,
+	"mythic": 5,
+}
+
+// parseDifficulty reads a raid difficulty given by name or by its WarcraftLogs value
+func parseDifficulty(value string) (int, bool) {
+	if difficulty, ok := raidDifficulties[strings.ToLower(value)]; ok {
+		return difficulty, true
+	}
+	difficulty, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	for _, known := range raidDifficulties {
+		if difficulty == known {
+			return difficulty, true
+		}
+	}
+	return 0, false
+}
+
+// parseBossRequest reads the class and spec query parameters and resolves the raid boss of the path at its difficulty
+// It writes the error response and returns false when the request is invalid
+func (h *RaidsAnalysisHandler) parseBossRequest(c *gin.Context) (string, string, *service.RaidBoss, bool) {
+	class := buildsAnalysis.NormalizeWoWTerms(c.Query("class"))
+	spec := buildsAnalysis.NormalizeWoWTerms(c.Query("spec"))
+
+	if class == "" || spec == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class and spec parameters are required"})
+		return "", "", nil, false
+	}
+
+	difficulty, ok := parseDifficulty(c.Param("difficulty"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "difficulty must be normal, heroic or mythic"})
+		return "", "", nil, false
+	}
+
+	boss, err := h.RaidAnalysisService.GetRaidBoss(c.Request.Context(), c.Param("raid"), c.Param("boss"), difficulty)
+	if err != nil {
+		if errors.Is(err, service.ErrRaidBossNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return "", "", nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", "", nil, false
+	}
+
+	return class, spec, boss, true
+}
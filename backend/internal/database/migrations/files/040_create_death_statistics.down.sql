@@ -0,0 +1,9 @@
+-- 040_create_death_statistics.down.sql
+
+-- Drop indexes for death_statistics table
+DROP INDEX IF EXISTS idx_death_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_death_statistics_bracket_type;
+DROP INDEX IF EXISTS idx_death_statistics_encounter_id;
+
+-- Drop death_statistics table
+DROP TABLE IF EXISTS death_statistics;
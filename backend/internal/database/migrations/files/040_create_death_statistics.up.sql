@@ -0,0 +1,40 @@
+-- 040_create_death_statistics.up.sql
+-- This migration creates the death_statistics table used by the death analysis workflow.
+-- Statistics are computed per dungeon and key level bracket from the stored reports.
+
+CREATE TABLE IF NOT EXISTS death_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    encounter_id INTEGER NOT NULL,
+    key_level_bracket VARCHAR(20) NOT NULL,
+    statistic_type VARCHAR(20) NOT NULL,
+
+    ability_id INTEGER NOT NULL DEFAULT 0,
+    ability_name VARCHAR(255),
+    ability_icon VARCHAR(255),
+
+    class VARCHAR(255),
+    spec VARCHAR(255),
+
+    time_bucket_start INTEGER NOT NULL DEFAULT 0,
+    time_bucket_end INTEGER NOT NULL DEFAULT 0,
+
+    death_count INTEGER NOT NULL DEFAULT 0,
+    deaths_per_run FLOAT NOT NULL DEFAULT 0,
+    percentage FLOAT NOT NULL DEFAULT 0,
+    avg_death_time FLOAT NOT NULL DEFAULT 0,
+
+    runs_analyzed INTEGER NOT NULL DEFAULT 0,
+    avg_keystone_level FLOAT NOT NULL DEFAULT 0,
+
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_death_statistics_encounter_id ON death_statistics(encounter_id);
+CREATE INDEX idx_death_statistics_bracket_type ON death_statistics(key_level_bracket, statistic_type);
+CREATE INDEX idx_death_statistics_deleted_at ON death_statistics(deleted_at);
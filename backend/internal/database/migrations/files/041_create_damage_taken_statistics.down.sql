@@ -0,0 +1,9 @@
+-- 041_create_damage_taken_statistics.down.sql
+
+-- Drop indexes for damage_taken_statistics table
+DROP INDEX IF EXISTS idx_damage_taken_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_damage_taken_statistics_bracket_type;
+DROP INDEX IF EXISTS idx_damage_taken_statistics_encounter_id;
+
+-- Drop damage_taken_statistics table
+DROP TABLE IF EXISTS damage_taken_statistics;
@@ -0,0 +1,41 @@
+-- 041_create_damage_taken_statistics.up.sql
+-- This migration creates the damage_taken_statistics table used by the damage taken analysis workflow.
+-- Statistics are computed per dungeon and key level bracket from the stored reports.
+
+CREATE TABLE IF NOT EXISTS damage_taken_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    encounter_id INTEGER NOT NULL,
+    key_level_bracket VARCHAR(20) NOT NULL,
+    statistic_type VARCHAR(20) NOT NULL,
+
+    ability_id INTEGER NOT NULL,
+    ability_name VARCHAR(255),
+    ability_icon VARCHAR(255),
+    ability_type INTEGER NOT NULL DEFAULT 0,
+
+    role VARCHAR(20),
+    class VARCHAR(255),
+    spec VARCHAR(255),
+
+    avg_total_damage FLOAT NOT NULL DEFAULT 0,
+    avg_damage_per_second FLOAT NOT NULL DEFAULT 0,
+    avg_normalized_dps FLOAT NOT NULL DEFAULT 0,
+    percentage FLOAT NOT NULL DEFAULT 0,
+    occurrence_rate FLOAT NOT NULL DEFAULT 0,
+    relative_to_average FLOAT NOT NULL DEFAULT 0,
+
+    runs_analyzed INTEGER NOT NULL DEFAULT 0,
+    avg_keystone_level FLOAT NOT NULL DEFAULT 0,
+
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_damage_taken_statistics_encounter_id ON damage_taken_statistics(encounter_id);
+CREATE INDEX idx_damage_taken_statistics_bracket_type ON damage_taken_statistics(key_level_bracket, statistic_type);
+CREATE INDEX idx_damage_taken_statistics_deleted_at ON damage_taken_statistics(deleted_at);
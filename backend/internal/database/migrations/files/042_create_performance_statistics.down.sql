@@ -0,0 +1,9 @@
+-- 042_create_performance_statistics.down.sql
+
+-- Drop indexes for performance_statistics table
+DROP INDEX IF EXISTS idx_performance_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_performance_statistics_encounter_bracket;
+DROP INDEX IF EXISTS idx_performance_statistics_class_spec;
+
+-- Drop performance_statistics table
+DROP TABLE IF EXISTS performance_statistics;
@@ -0,0 +1,37 @@
+-- 042_create_performance_statistics.up.sql
+-- This migration creates the performance_statistics table used by the performance analysis workflow.
+-- It stores the DPS and HPS percentiles per spec, dungeon and key level bracket.
+
+CREATE TABLE IF NOT EXISTS performance_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    role VARCHAR(20),
+
+    encounter_id INTEGER NOT NULL,
+    key_level_bracket VARCHAR(20) NOT NULL,
+    metric VARCHAR(10) NOT NULL,
+
+    sample_size INTEGER NOT NULL DEFAULT 0,
+    avg_value FLOAT NOT NULL DEFAULT 0,
+    min_value FLOAT NOT NULL DEFAULT 0,
+    max_value FLOAT NOT NULL DEFAULT 0,
+    p25 FLOAT NOT NULL DEFAULT 0,
+    p50 FLOAT NOT NULL DEFAULT 0,
+    p75 FLOAT NOT NULL DEFAULT 0,
+    p95 FLOAT NOT NULL DEFAULT 0,
+
+    avg_keystone_level FLOAT NOT NULL DEFAULT 0,
+
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_performance_statistics_class_spec ON performance_statistics(class, spec);
+CREATE INDEX idx_performance_statistics_encounter_bracket ON performance_statistics(encounter_id, key_level_bracket);
+CREATE INDEX idx_performance_statistics_deleted_at ON performance_statistics(deleted_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_group_compositions_encounter_level;
+DROP INDEX IF EXISTS idx_group_compositions_report_fight;
@@ -0,0 +1,13 @@
+-- 043_add_group_compositions_unique_fight.up.sql
+-- This migration makes group_compositions unique per fight so the reports workflow can upsert them.
+-- It also adds an index for the composition analytics per dungeon and keystone level.
+
+-- Keep a single composition per fight before adding the unique constraint
+DELETE FROM group_compositions a
+    USING group_compositions b
+    WHERE a.report_code = b.report_code
+      AND a.fight_id = b.fight_id
+      AND a.id < b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_compositions_report_fight ON group_compositions(report_code, fight_id);
+CREATE INDEX IF NOT EXISTS idx_group_compositions_encounter_level ON group_compositions(encounter_id, keystone_level);
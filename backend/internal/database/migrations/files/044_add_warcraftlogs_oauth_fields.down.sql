@@ -0,0 +1,12 @@
+-- Rollback WarcraftLogs OAuth fields from users table
+-- Migration 044: WarcraftLogs account linking - ROLLBACK
+
+-- Drop indexes first
+DROP INDEX IF EXISTS users_warcraft_logs_id_unique;
+
+-- Drop columns
+ALTER TABLE users DROP COLUMN IF EXISTS warcraft_logs_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS encrypted_warcraft_logs_refresh_token;
+ALTER TABLE users DROP COLUMN IF EXISTS encrypted_warcraft_logs_access_token;
+ALTER TABLE users DROP COLUMN IF EXISTS warcraft_logs_name;
+ALTER TABLE users DROP COLUMN IF EXISTS warcraft_logs_id;
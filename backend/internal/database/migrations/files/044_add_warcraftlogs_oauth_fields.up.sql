@@ -0,0 +1,12 @@
+-- Add WarcraftLogs OAuth fields to users table
+-- Migration 044: WarcraftLogs account linking for private reports
+
+-- Add WarcraftLogs OAuth columns
+ALTER TABLE users ADD COLUMN warcraft_logs_id VARCHAR(255);
+ALTER TABLE users ADD COLUMN warcraft_logs_name VARCHAR(255);
+ALTER TABLE users ADD COLUMN encrypted_warcraft_logs_access_token BYTEA;
+ALTER TABLE users ADD COLUMN encrypted_warcraft_logs_refresh_token BYTEA;
+ALTER TABLE users ADD COLUMN warcraft_logs_expires_at TIMESTAMP;
+
+-- Add unique constraint on warcraft_logs_id (only if not null)
+CREATE UNIQUE INDEX users_warcraft_logs_id_unique ON users(warcraft_logs_id) WHERE warcraft_logs_id IS NOT NULL;
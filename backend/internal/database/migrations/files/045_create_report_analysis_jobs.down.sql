@@ -0,0 +1,11 @@
+-- 045_create_report_analysis_jobs.down.sql
+
+-- Drop indexes for report_analysis_jobs table
+DROP INDEX IF EXISTS idx_report_analysis_jobs_deleted_at;
+DROP INDEX IF EXISTS idx_report_analysis_jobs_status;
+DROP INDEX IF EXISTS idx_report_analysis_jobs_user_id;
+DROP INDEX IF EXISTS idx_report_analysis_jobs_report_code;
+DROP INDEX IF EXISTS idx_report_analysis_jobs_job_id;
+
+-- Drop report_analysis_jobs table
+DROP TABLE IF EXISTS report_analysis_jobs;
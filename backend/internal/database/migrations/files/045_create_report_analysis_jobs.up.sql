@@ -0,0 +1,29 @@
+-- 045_create_report_analysis_jobs.up.sql
+-- This migration creates the report_analysis_jobs table used by the on-demand report analysis workflow.
+-- A job tracks the status and the result of the comparison of a report against the stored build statistics.
+
+CREATE TABLE IF NOT EXISTS report_analysis_jobs (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    job_id VARCHAR(36) NOT NULL,
+    report_code VARCHAR(255) NOT NULL,
+    fight_id INTEGER NOT NULL DEFAULT 0,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    workflow_id VARCHAR(255),
+    result JSONB,
+
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_analysis_jobs_job_id ON report_analysis_jobs(job_id);
+CREATE INDEX IF NOT EXISTS idx_report_analysis_jobs_report_code ON report_analysis_jobs(report_code);
+CREATE INDEX IF NOT EXISTS idx_report_analysis_jobs_user_id ON report_analysis_jobs(user_id);
+CREATE INDEX IF NOT EXISTS idx_report_analysis_jobs_status ON report_analysis_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_report_analysis_jobs_deleted_at ON report_analysis_jobs(deleted_at);
@@ -0,0 +1,467 @@
+-- 046_create_raid_encounters.up.sql
+-- This migration creates the raid_encounters table listing the raid bosses targeted by the builds pipeline.
+-- Raid bosses share the statistics tables with the Mythic+ dungeons (one row set per encounter_id),
+-- so the Mythic+ analytics functions are redefined to ignore the encounters registered as raid bosses.
+-- get_popular_items_by_slot still returns a raid boss when its encounter_id is explicitly requested.
+
+CREATE TABLE IF NOT EXISTS raid_encounters (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+    encounter_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    raid_name VARCHAR(255) NOT NULL,
+    raid_slug VARCHAR(255) NOT NULL,
+    zone_id INTEGER NOT NULL DEFAULT 0,
+    difficulty INTEGER NOT NULL,
+    CONSTRAINT raid_encounters_encounter_id_key UNIQUE (encounter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_raid_encounters_raid_slug ON raid_encounters(raid_slug);
+CREATE INDEX IF NOT EXISTS idx_raid_encounters_deleted_at ON raid_encounters(deleted_at);
+
+-- Function: get_popular_items_by_slot (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_popular_items_by_slot(p_class TEXT, p_spec TEXT, p_encounter_id INTEGER DEFAULT NULL) 
+RETURNS TABLE (
+    encounter_id INTEGER,
+    item_slot INTEGER,
+    item_id INTEGER,
+    item_name TEXT,
+    item_icon TEXT,
+    item_quality INTEGER,
+    item_level NUMERIC,
+    usage_count INTEGER,
+    usage_percentage NUMERIC,
+    avg_keystone_level NUMERIC,
+    rank BIGINT
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH ranked_items AS (
+        SELECT 
+            bs.encounter_id,
+            bs.item_slot, 
+            bs.item_id, 
+            bs.item_name::TEXT, 
+            bs.item_icon::TEXT,
+            bs.item_quality,
+            bs.item_level,
+            bs.usage_count,
+            bs.usage_percentage,
+            bs.avg_keystone_level,
+            ROW_NUMBER() OVER (PARTITION BY bs.encounter_id, bs.item_slot ORDER BY bs.usage_count DESC) as item_rank
+        FROM build_statistics bs
+        WHERE bs.class = p_class
+        AND bs.spec = p_spec
+        AND (bs.encounter_id = p_encounter_id OR (p_encounter_id IS NULL AND NOT EXISTS (
+            SELECT 1 FROM raid_encounters re WHERE re.encounter_id = bs.encounter_id
+        )))
+    )
+    SELECT 
+        ri.encounter_id,
+        ri.item_slot, 
+        ri.item_id, 
+        ri.item_name, 
+        ri.item_icon,
+        ri.item_quality,
+        ri.item_level,
+        ri.usage_count,
+        ri.usage_percentage,
+        ri.avg_keystone_level,
+        ri.item_rank as rank
+    FROM ranked_items ri
+    WHERE ri.item_rank <= 4
+    ORDER BY ri.encounter_id, ri.item_slot, ri.item_rank;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_global_popular_items_by_slot (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_global_popular_items_by_slot(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    item_slot INTEGER,
+    item_id INTEGER,
+    item_name TEXT,
+    item_icon TEXT,
+    item_quality INTEGER,
+    item_level NUMERIC,
+    usage_count INTEGER,
+    usage_percentage NUMERIC,
+    avg_keystone_level NUMERIC,
+    rank BIGINT
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH item_stats AS (
+        -- Group all items with the same ID and sum their usages
+        SELECT
+            bs.item_slot,
+            bs.item_id,
+            MAX(bs.item_name)::TEXT AS item_name,
+            MAX(bs.item_icon)::TEXT AS item_icon,
+            MAX(bs.item_quality) AS item_quality,
+            -- Use the max level for display
+            MAX(bs.item_level) AS item_level,
+            SUM(bs.usage_count) AS total_usage,
+            SUM(bs.usage_count * bs.avg_keystone_level) / NULLIF(SUM(bs.usage_count), 0) AS avg_key_level
+        FROM build_statistics bs
+        WHERE bs.class = p_class AND bs.spec = p_spec
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = bs.encounter_id)
+        GROUP BY bs.item_slot, bs.item_id
+    ),
+    slot_totals AS (
+        -- Calculate the total for each slot
+        SELECT 
+            is_tot.item_slot, 
+            SUM(is_tot.total_usage) AS slot_total
+        FROM item_stats is_tot
+        GROUP BY is_tot.item_slot
+    ),
+    final_ranked AS (
+        -- Calculate the percentages and rank the items
+        SELECT 
+            is_rank.item_slot,
+            is_rank.item_id,
+            is_rank.item_name,
+            is_rank.item_icon,
+            is_rank.item_quality,
+            is_rank.item_level,
+            is_rank.total_usage::INTEGER AS usage_count,
+            ROUND((is_rank.total_usage * 100.0 / NULLIF(st.slot_total, 0))::NUMERIC, 2) AS usage_percentage,
+            ROUND(is_rank.avg_key_level::NUMERIC, 2) AS avg_keystone_level,
+            ROW_NUMBER() OVER (PARTITION BY is_rank.item_slot ORDER BY is_rank.total_usage DESC) AS item_rank
+        FROM item_stats is_rank
+        JOIN slot_totals st ON is_rank.item_slot = st.item_slot
+    )
+    SELECT 
+        fr.item_slot,
+        fr.item_id,
+        fr.item_name,
+        fr.item_icon,
+        fr.item_quality,
+        fr.item_level,
+        fr.usage_count,
+        fr.usage_percentage,
+        fr.avg_keystone_level,
+        fr.item_rank as rank
+    FROM final_ranked fr
+    WHERE fr.item_rank <= 4
+    ORDER BY fr.item_slot, fr.item_rank;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_enchant_usage (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_enchant_usage(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    item_slot INTEGER,
+    permanent_enchant_id INTEGER,
+    permanent_enchant_name TEXT,
+    usage_count BIGINT,
+    avg_keystone_level NUMERIC,
+    avg_item_level NUMERIC,
+    max_keystone_level BIGINT,
+    rank BIGINT
+) AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        bs.item_slot,
+        bs.permanent_enchant_id,
+        bs.permanent_enchant_name::TEXT,
+        COUNT(*)::BIGINT as usage_count,
+        AVG(bs.avg_keystone_level) as avg_keystone_level,
+        AVG(bs.avg_item_level) as avg_item_level,
+        MAX(bs.max_keystone_level)::BIGINT as max_keystone_level,
+        ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC)::BIGINT as rank
+    FROM build_statistics bs
+    WHERE bs.class = p_class
+    AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = bs.encounter_id)
+    AND bs.spec = p_spec
+    AND bs.has_permanent_enchant = true
+    GROUP BY bs.item_slot, bs.permanent_enchant_id, bs.permanent_enchant_name
+    ORDER BY bs.item_slot, ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_gem_usage (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_gem_usage(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    item_slot INTEGER,
+    gems_count INTEGER,
+    gem_ids_array INTEGER[],
+    gem_icons_array TEXT[],
+    gem_levels_array NUMERIC[],
+    usage_count BIGINT,
+    avg_keystone_level NUMERIC,
+    avg_item_level NUMERIC,
+    rank BIGINT
+) AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        bs.item_slot,
+        bs.gems_count,
+        COALESCE(bs.gem_ids, ARRAY[]::INTEGER[]) as gem_ids_array,
+        COALESCE(bs.gem_icons, ARRAY[]::TEXT[]) as gem_icons_array,
+        COALESCE(bs.gem_levels, ARRAY[]::NUMERIC[]) as gem_levels_array,
+        COUNT(*)::BIGINT as usage_count,
+        AVG(bs.avg_keystone_level) as avg_keystone_level,
+        AVG(bs.avg_item_level) as avg_item_level,
+        ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC)::BIGINT as rank
+    FROM build_statistics bs
+    WHERE bs.class = p_class
+    AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = bs.encounter_id)
+    AND bs.spec = p_spec
+    AND bs.has_gems = true
+    GROUP BY bs.item_slot, bs.gems_count, bs.gem_ids, bs.gem_icons, bs.gem_levels
+    ORDER BY bs.item_slot, ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_top_talent_builds (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_top_talent_builds(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    talent_import TEXT,
+    total_usage BIGINT,
+    avg_usage_percentage NUMERIC,
+    avg_keystone_level NUMERIC
+) AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        ts.talent_import::TEXT,
+        SUM(ts.usage_count)::BIGINT as total_usage,
+        AVG(ts.usage_percentage) as avg_usage_percentage,
+        AVG(ts.avg_keystone_level) as avg_keystone_level
+    FROM talent_statistics ts
+    WHERE ts.class = p_class
+    AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ts.encounter_id)
+    AND ts.spec = p_spec
+    GROUP BY ts.talent_import
+    ORDER BY SUM(ts.usage_count) DESC
+    LIMIT 3;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_stat_priorities (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_stat_priorities(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    stat_name TEXT,
+    stat_category TEXT,
+    avg_value DOUBLE PRECISION, -- Changed from NUMERIC to DOUBLE PRECISION
+    min_value DOUBLE PRECISION, -- Changed from NUMERIC to DOUBLE PRECISION
+    max_value DOUBLE PRECISION, -- Changed from NUMERIC to DOUBLE PRECISION
+    total_samples BIGINT,
+    avg_keystone_level NUMERIC,
+    priority_rank BIGINT
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH stat_aggregates AS (
+        SELECT 
+            ss.stat_name::TEXT,
+            ss.stat_category::TEXT,
+            AVG(ss.avg_value) as avg_value,
+            MIN(ss.min_value) as min_value,
+            MAX(ss.max_value) as max_value,
+            SUM(ss.sample_size)::BIGINT as total_samples,
+            AVG(ss.avg_keystone_level) as avg_keystone_level
+        FROM stat_statistics ss
+        WHERE ss.class = p_class
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ss.encounter_id)
+        AND ss.spec = p_spec
+        GROUP BY ss.stat_name, ss.stat_category
+    )
+    SELECT 
+        sa.stat_name,
+        sa.stat_category,
+        sa.avg_value,
+        sa.min_value,
+        sa.max_value,
+        sa.total_samples,
+        sa.avg_keystone_level,
+        ROW_NUMBER() OVER (PARTITION BY sa.stat_category ORDER BY sa.avg_value DESC)::BIGINT as priority_rank
+    FROM stat_aggregates sa
+    ORDER BY sa.stat_category, ROW_NUMBER() OVER (PARTITION BY sa.stat_category ORDER BY sa.avg_value DESC);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_optimal_build (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_optimal_build(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    top_talent_import TEXT,
+    stat_priority TEXT,
+    top_items JSON
+) AS $$
+DECLARE
+    items_json JSONB;
+BEGIN
+    -- Get the top talent import
+    SELECT COALESCE(ts.talent_import, '') INTO top_talent_import
+    FROM talent_statistics ts
+    WHERE ts.class = p_class
+    AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ts.encounter_id)
+    AND ts.spec = p_spec
+    GROUP BY ts.talent_import
+    ORDER BY SUM(ts.usage_count) DESC
+    LIMIT 1;
+    
+    -- Get the stat priority string
+    SELECT COALESCE(STRING_AGG(s.stat_name, ' > ' ORDER BY s.avg_value DESC), '') INTO stat_priority
+    FROM (
+        SELECT 
+            ss.stat_name, AVG(ss.avg_value) as avg_value
+        FROM stat_statistics ss
+        WHERE ss.class = p_class
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ss.encounter_id)
+        AND ss.spec = p_spec
+        AND ss.stat_category = 'secondary'
+        GROUP BY ss.stat_name
+    ) s;
+    
+    -- Get the top items JSON
+    SELECT 
+        COALESCE(
+            jsonb_object_agg(
+                b.item_slot::text, 
+                jsonb_build_object(
+                    'name', COALESCE(b.item_name, 'Unknown'),
+                    'icon', COALESCE(b.item_icon, ''),
+                    'quality', COALESCE(b.item_quality, 0),
+                    'usage_count', COALESCE(b.usage_count, 0)
+                )
+            ),
+            '{}'::jsonb
+        ) INTO items_json
+    FROM (
+        SELECT DISTINCT ON (bs.item_slot)
+            bs.item_slot, bs.item_name, bs.item_icon, bs.item_quality, bs.usage_count
+        FROM build_statistics bs
+        WHERE bs.class = p_class
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = bs.encounter_id)
+        AND bs.spec = p_spec
+        ORDER BY bs.item_slot, bs.usage_count DESC
+    ) b;
+    
+    -- Return a single row with all values
+    RETURN QUERY
+    SELECT 
+        top_talent_import,
+        stat_priority,
+        CASE WHEN items_json = '{}'::jsonb THEN NULL ELSE items_json::json END;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_spec_comparison (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_spec_comparison(p_class TEXT) 
+RETURNS TABLE (
+    spec TEXT,
+    avg_keystone_level NUMERIC,
+    max_keystone_level BIGINT,
+    avg_item_level NUMERIC,
+    dungeons_count BIGINT,
+    stat_priority TEXT
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH spec_stats AS (
+        SELECT 
+            ts.spec::TEXT,
+            AVG(ts.avg_keystone_level) as avg_keystone_level,
+            MAX(ts.max_keystone_level)::BIGINT as max_keystone_level,
+            AVG(ts.avg_item_level) as avg_item_level,
+            COUNT(DISTINCT ts.encounter_id)::BIGINT as dungeons_count
+        FROM talent_statistics ts
+        WHERE ts.class = p_class
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ts.encounter_id)
+        GROUP BY ts.spec
+    ),
+    spec_stat_priorities AS (
+        SELECT 
+            sv.spec,
+            STRING_AGG(sv.stat_name, ' > ' ORDER BY sv.avg_stat_val DESC)::TEXT as stat_priority
+        FROM (
+            SELECT 
+                ss.spec::TEXT, 
+                ss.stat_name::TEXT,
+                AVG(ss.avg_value) as avg_stat_val
+            FROM stat_statistics ss
+            WHERE ss.class = p_class
+            AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ss.encounter_id)
+            AND ss.stat_category = 'secondary'
+            GROUP BY ss.spec, ss.stat_name
+        ) as sv
+        GROUP BY sv.spec
+    )
+    SELECT 
+        ss.spec,
+        ss.avg_keystone_level,
+        ss.max_keystone_level,
+        ss.avg_item_level,
+        ss.dungeons_count,
+        ssp.stat_priority
+    FROM spec_stats ss
+    LEFT JOIN spec_stat_priorities ssp ON ss.spec = ssp.spec
+    ORDER BY ss.avg_keystone_level DESC;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: get_class_spec_summary (Mythic+ encounters only)
+CREATE OR REPLACE FUNCTION get_class_spec_summary(p_class TEXT, p_spec TEXT) 
+RETURNS TABLE (
+    avg_keystone_level NUMERIC,
+    max_keystone_level BIGINT,
+    avg_item_level NUMERIC,
+    top_talent_import TEXT,
+    stat_priority TEXT,
+    dungeons_count BIGINT
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH talent_stats AS (
+        SELECT 
+            AVG(ts.avg_keystone_level) as avg_keystone_level,
+            MAX(ts.max_keystone_level)::BIGINT as max_keystone_level,
+            AVG(ts.avg_item_level) as avg_item_level,
+            COUNT(DISTINCT ts.encounter_id)::BIGINT as dungeons_count
+        FROM talent_statistics ts
+        WHERE ts.class = p_class
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ts.encounter_id)
+        AND ts.spec = p_spec
+    ),
+    top_talent AS (
+        SELECT ts.talent_import::TEXT
+        FROM talent_statistics ts
+        WHERE ts.class = p_class
+        AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ts.encounter_id)
+        AND ts.spec = p_spec
+        GROUP BY ts.talent_import
+        ORDER BY SUM(ts.usage_count) DESC
+        LIMIT 1
+    ),
+    stat_priority AS (
+        SELECT STRING_AGG(s.stat_name, ' > ' ORDER BY s.avg_value DESC)::TEXT as stat_priority
+        FROM (
+            SELECT 
+                ss.stat_name::TEXT, AVG(ss.avg_value) as avg_value
+            FROM stat_statistics ss
+            WHERE ss.class = p_class
+            AND NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = ss.encounter_id)
+            AND ss.spec = p_spec
+            AND ss.stat_category = 'secondary'
+            GROUP BY ss.stat_name
+        ) s
+    )
+    SELECT 
+        tst.avg_keystone_level,
+        tst.max_keystone_level,
+        tst.avg_item_level,
+        tt.talent_import,
+        sp.stat_priority,
+        tst.dungeons_count
+    FROM talent_stats tst
+    CROSS JOIN top_talent tt
+    CROSS JOIN stat_priority sp;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+ALTER TABLE player_builds DROP COLUMN IF EXISTS healthstone_use;
+ALTER TABLE player_builds DROP COLUMN IF EXISTS potion_use;
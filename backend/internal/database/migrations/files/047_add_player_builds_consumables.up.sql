@@ -0,0 +1,6 @@
+-- 047_add_player_builds_consumables.up.sql
+-- This migration stores the potions and healthstones used by each player build.
+-- Builds extracted before this migration keep NULL values and are ignored by the consumable analytics.
+
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS potion_use INTEGER;
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS healthstone_use INTEGER;
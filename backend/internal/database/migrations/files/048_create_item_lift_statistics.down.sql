@@ -0,0 +1,10 @@
+-- 048_create_item_lift_statistics.down.sql
+
+-- Drop indexes for item_lift_statistics table
+DROP INDEX IF EXISTS idx_item_lift_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_item_lift_statistics_choice;
+DROP INDEX IF EXISTS idx_item_lift_statistics_encounter_id;
+DROP INDEX IF EXISTS idx_item_lift_statistics_class_spec;
+
+-- Drop item_lift_statistics table
+DROP TABLE IF EXISTS item_lift_statistics;
@@ -0,0 +1,38 @@
+-- 048_create_item_lift_statistics.up.sql
+-- This migration creates the item_lift_statistics table.
+-- It stores, for each item, enchant and gem choice of a spec, its usage among the top keys compared to the whole population.
+
+CREATE TABLE IF NOT EXISTS item_lift_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+
+    choice_type VARCHAR(20) NOT NULL,
+    item_slot INTEGER,
+    choice_id INTEGER,
+    choice_name VARCHAR(255),
+    choice_icon VARCHAR(255),
+
+    top_keystone_level INTEGER DEFAULT 0,
+    top_count INTEGER DEFAULT 0,
+    top_sample_size INTEGER DEFAULT 0,
+    top_usage_rate NUMERIC DEFAULT 0,
+
+    population_count INTEGER DEFAULT 0,
+    population_sample_size INTEGER DEFAULT 0,
+    population_usage_rate NUMERIC DEFAULT 0,
+
+    lift NUMERIC DEFAULT 0,
+    confidence NUMERIC DEFAULT 0,
+    weighted_lift NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_lift_statistics_class_spec ON item_lift_statistics(class, spec);
+CREATE INDEX IF NOT EXISTS idx_item_lift_statistics_encounter_id ON item_lift_statistics(encounter_id);
+CREATE INDEX IF NOT EXISTS idx_item_lift_statistics_choice ON item_lift_statistics(choice_type, item_slot, choice_id);
+CREATE INDEX IF NOT EXISTS idx_item_lift_statistics_deleted_at ON item_lift_statistics(deleted_at);
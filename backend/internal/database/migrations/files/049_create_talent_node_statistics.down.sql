@@ -0,0 +1,10 @@
+-- 049_create_talent_node_statistics.down.sql
+
+-- Drop indexes for talent_node_statistics table
+DROP INDEX IF EXISTS idx_talent_node_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_talent_node_statistics_node;
+DROP INDEX IF EXISTS idx_talent_node_statistics_encounter_id;
+DROP INDEX IF EXISTS idx_talent_node_statistics_class_spec;
+
+-- Drop talent_node_statistics table
+DROP TABLE IF EXISTS talent_node_statistics;
@@ -0,0 +1,27 @@
+-- 049_create_talent_node_statistics.up.sql
+-- This migration creates the talent_node_statistics table.
+-- It stores the pick rate of each talent node entry per spec and dungeon, decoded from the talent tree of the player builds.
+
+CREATE TABLE IF NOT EXISTS talent_node_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+
+    node_id INTEGER NOT NULL,
+    entry_id INTEGER NOT NULL,
+
+    pick_count INTEGER DEFAULT 0,
+    sample_size INTEGER DEFAULT 0,
+    pick_rate NUMERIC DEFAULT 0,
+    avg_rank NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_talent_node_statistics_class_spec ON talent_node_statistics(class, spec);
+CREATE INDEX IF NOT EXISTS idx_talent_node_statistics_encounter_id ON talent_node_statistics(encounter_id);
+CREATE INDEX IF NOT EXISTS idx_talent_node_statistics_node ON talent_node_statistics(node_id, entry_id);
+CREATE INDEX IF NOT EXISTS idx_talent_node_statistics_deleted_at ON talent_node_statistics(deleted_at);
@@ -0,0 +1,9 @@
+-- 050_create_stat_weight_statistics.down.sql
+
+-- Drop indexes for stat_weight_statistics table
+DROP INDEX IF EXISTS idx_stat_weight_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_stat_weight_statistics_metric;
+DROP INDEX IF EXISTS idx_stat_weight_statistics_class_spec;
+
+-- Drop stat_weight_statistics table
+DROP TABLE IF EXISTS stat_weight_statistics;
@@ -0,0 +1,30 @@
+-- 050_create_stat_weight_statistics.up.sql
+-- This migration creates the stat_weight_statistics table.
+-- It stores the relative weight of each secondary stat per spec and performance metric, estimated by a regression over the player builds.
+
+CREATE TABLE IF NOT EXISTS stat_weight_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+
+    metric VARCHAR(50) NOT NULL,
+    stat_name VARCHAR(50) NOT NULL,
+
+    coefficient NUMERIC DEFAULT 0,
+    std_error NUMERIC DEFAULT 0,
+
+    weight NUMERIC DEFAULT 0,
+    ci_lower NUMERIC DEFAULT 0,
+    ci_upper NUMERIC DEFAULT 0,
+
+    sample_size INTEGER DEFAULT 0,
+    r_squared NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_weight_statistics_class_spec ON stat_weight_statistics(class, spec);
+CREATE INDEX IF NOT EXISTS idx_stat_weight_statistics_metric ON stat_weight_statistics(metric);
+CREATE INDEX IF NOT EXISTS idx_stat_weight_statistics_deleted_at ON stat_weight_statistics(deleted_at);
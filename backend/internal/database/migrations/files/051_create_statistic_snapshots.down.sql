@@ -0,0 +1,19 @@
+-- 051_create_statistic_snapshots.down.sql
+
+-- Drop indexes for stat_statistic_snapshots table
+DROP INDEX IF EXISTS idx_stat_statistic_snapshots_deleted_at;
+DROP INDEX IF EXISTS idx_stat_statistic_snapshots_period;
+
+-- Drop indexes for talent_statistic_snapshots table
+DROP INDEX IF EXISTS idx_talent_statistic_snapshots_deleted_at;
+DROP INDEX IF EXISTS idx_talent_statistic_snapshots_period;
+
+-- Drop indexes for build_statistic_snapshots table
+DROP INDEX IF EXISTS idx_build_statistic_snapshots_deleted_at;
+DROP INDEX IF EXISTS idx_build_statistic_snapshots_item;
+DROP INDEX IF EXISTS idx_build_statistic_snapshots_period;
+
+-- Drop snapshot tables
+DROP TABLE IF EXISTS stat_statistic_snapshots;
+DROP TABLE IF EXISTS talent_statistic_snapshots;
+DROP TABLE IF EXISTS build_statistic_snapshots;
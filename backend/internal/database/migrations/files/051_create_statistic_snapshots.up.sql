@@ -0,0 +1,74 @@
+-- 051_create_statistic_snapshots.up.sql
+-- This migration creates the weekly snapshot tables of the build, talent and stat statistics.
+-- The analysis workflows overwrite the statistics on each run, the snapshots keep one copy per weekly reset period for the trend charts.
+
+CREATE TABLE IF NOT EXISTS build_statistic_snapshots (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    period_start TIMESTAMP NOT NULL,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+
+    item_slot INTEGER,
+    item_id INTEGER,
+    item_name VARCHAR(255),
+    item_icon VARCHAR(255),
+
+    usage_count INTEGER DEFAULT 0,
+    usage_percentage NUMERIC DEFAULT 0,
+    avg_item_level NUMERIC DEFAULT 0,
+    avg_keystone_level NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_build_statistic_snapshots_period ON build_statistic_snapshots(class, spec, period_start);
+CREATE INDEX IF NOT EXISTS idx_build_statistic_snapshots_item ON build_statistic_snapshots(item_slot, item_id);
+CREATE INDEX IF NOT EXISTS idx_build_statistic_snapshots_deleted_at ON build_statistic_snapshots(deleted_at);
+
+CREATE TABLE IF NOT EXISTS talent_statistic_snapshots (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    period_start TIMESTAMP NOT NULL,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+
+    talent_import TEXT,
+
+    usage_count INTEGER DEFAULT 0,
+    usage_percentage NUMERIC DEFAULT 0,
+    avg_keystone_level NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_talent_statistic_snapshots_period ON talent_statistic_snapshots(class, spec, period_start);
+CREATE INDEX IF NOT EXISTS idx_talent_statistic_snapshots_deleted_at ON talent_statistic_snapshots(deleted_at);
+
+CREATE TABLE IF NOT EXISTS stat_statistic_snapshots (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    period_start TIMESTAMP NOT NULL,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+
+    stat_name VARCHAR(50) NOT NULL,
+    stat_category VARCHAR(50) NOT NULL,
+
+    avg_value NUMERIC DEFAULT 0,
+    sample_size INTEGER DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_statistic_snapshots_period ON stat_statistic_snapshots(class, spec, period_start);
+CREATE INDEX IF NOT EXISTS idx_stat_statistic_snapshots_deleted_at ON stat_statistic_snapshots(deleted_at);
@@ -0,0 +1,21 @@
+-- 052_create_statistic_segments.down.sql
+
+-- Drop indexes for stat_statistic_segments table
+DROP INDEX IF EXISTS idx_stat_statistic_segments_deleted_at;
+DROP INDEX IF EXISTS idx_stat_statistic_segments_affixes;
+DROP INDEX IF EXISTS idx_stat_statistic_segments_class_spec;
+
+-- Drop indexes for talent_statistic_segments table
+DROP INDEX IF EXISTS idx_talent_statistic_segments_deleted_at;
+DROP INDEX IF EXISTS idx_talent_statistic_segments_affixes;
+DROP INDEX IF EXISTS idx_talent_statistic_segments_class_spec;
+
+-- Drop indexes for build_statistic_segments table
+DROP INDEX IF EXISTS idx_build_statistic_segments_deleted_at;
+DROP INDEX IF EXISTS idx_build_statistic_segments_affixes;
+DROP INDEX IF EXISTS idx_build_statistic_segments_class_spec;
+
+-- Drop segment tables
+DROP TABLE IF EXISTS stat_statistic_segments;
+DROP TABLE IF EXISTS talent_statistic_segments;
+DROP TABLE IF EXISTS build_statistic_segments;
@@ -0,0 +1,79 @@
+-- 052_create_statistic_segments.up.sql
+-- This migration creates the segmented build, talent and stat statistics.
+-- Each row aggregates the builds of one keystone level and one affix set, so the analysis routes can filter
+-- by affix and key level range by summing the matching segments instead of scanning the player builds.
+
+CREATE TABLE IF NOT EXISTS build_statistic_segments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+    keystone_level INTEGER,
+    affixes INTEGER[],
+
+    item_slot INTEGER,
+    item_id INTEGER,
+    item_name VARCHAR(255),
+    item_icon VARCHAR(255),
+    item_quality INTEGER DEFAULT 0,
+    item_level NUMERIC DEFAULT 0,
+
+    usage_count INTEGER DEFAULT 0,
+    avg_item_level NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_build_statistic_segments_class_spec ON build_statistic_segments(class, spec, encounter_id, keystone_level);
+CREATE INDEX IF NOT EXISTS idx_build_statistic_segments_affixes ON build_statistic_segments USING GIN (affixes);
+CREATE INDEX IF NOT EXISTS idx_build_statistic_segments_deleted_at ON build_statistic_segments(deleted_at);
+
+CREATE TABLE IF NOT EXISTS talent_statistic_segments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+    keystone_level INTEGER,
+    affixes INTEGER[],
+
+    talent_import TEXT,
+
+    usage_count INTEGER DEFAULT 0,
+    avg_item_level NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_talent_statistic_segments_class_spec ON talent_statistic_segments(class, spec, encounter_id, keystone_level);
+CREATE INDEX IF NOT EXISTS idx_talent_statistic_segments_affixes ON talent_statistic_segments USING GIN (affixes);
+CREATE INDEX IF NOT EXISTS idx_talent_statistic_segments_deleted_at ON talent_statistic_segments(deleted_at);
+
+CREATE TABLE IF NOT EXISTS stat_statistic_segments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    encounter_id INTEGER,
+    keystone_level INTEGER,
+    affixes INTEGER[],
+
+    stat_name VARCHAR(50) NOT NULL,
+    stat_category VARCHAR(50) NOT NULL,
+
+    avg_value NUMERIC DEFAULT 0,
+    min_value NUMERIC DEFAULT 0,
+    max_value NUMERIC DEFAULT 0,
+    sample_size INTEGER DEFAULT 0,
+    avg_item_level NUMERIC DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_statistic_segments_class_spec ON stat_statistic_segments(class, spec, encounter_id, keystone_level);
+CREATE INDEX IF NOT EXISTS idx_stat_statistic_segments_affixes ON stat_statistic_segments USING GIN (affixes);
+CREATE INDEX IF NOT EXISTS idx_stat_statistic_segments_deleted_at ON stat_statistic_segments(deleted_at);
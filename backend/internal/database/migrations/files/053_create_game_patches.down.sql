@@ -0,0 +1,25 @@
+-- 053_create_game_patches.down.sql
+
+-- Drop patch indexes
+DROP INDEX IF EXISTS idx_stat_statistic_segments_patch;
+DROP INDEX IF EXISTS idx_talent_statistic_segments_patch;
+DROP INDEX IF EXISTS idx_build_statistic_segments_patch;
+DROP INDEX IF EXISTS idx_player_builds_patch;
+DROP INDEX IF EXISTS idx_warcraft_logs_reports_patch;
+DROP INDEX IF EXISTS idx_class_rankings_patch;
+
+-- Drop patch columns
+ALTER TABLE stat_statistic_segments DROP COLUMN IF EXISTS patch;
+ALTER TABLE talent_statistic_segments DROP COLUMN IF EXISTS patch;
+ALTER TABLE build_statistic_segments DROP COLUMN IF EXISTS patch;
+ALTER TABLE player_builds DROP COLUMN IF EXISTS patch;
+ALTER TABLE warcraft_logs_reports DROP COLUMN IF EXISTS patch;
+ALTER TABLE class_rankings DROP COLUMN IF EXISTS patch;
+
+-- Drop indexes for game_patches table
+DROP INDEX IF EXISTS idx_game_patches_deleted_at;
+DROP INDEX IF EXISTS idx_game_patches_starts_at;
+DROP INDEX IF EXISTS idx_game_patches_name;
+
+-- Drop game_patches table
+DROP TABLE IF EXISTS game_patches;
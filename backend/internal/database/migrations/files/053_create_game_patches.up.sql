@@ -0,0 +1,73 @@
+-- 053_create_game_patches.up.sql
+-- This migration creates the game patch registry and tags the rankings, reports, builds and statistic segments
+-- with the patch live when their run started, so the analytics can be restricted to a single patch.
+-- Patches without an end date are live. game_version restricts a patch to the reports of a WarcraftLogs game version.
+
+CREATE TABLE IF NOT EXISTS game_patches (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    name VARCHAR(20) NOT NULL,
+    label VARCHAR(255),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    game_version INTEGER
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_game_patches_name ON game_patches(name);
+CREATE INDEX IF NOT EXISTS idx_game_patches_starts_at ON game_patches(starts_at);
+CREATE INDEX IF NOT EXISTS idx_game_patches_deleted_at ON game_patches(deleted_at);
+
+-- The War Within patches, release dates of the US region
+INSERT INTO game_patches (name, label, starts_at, ends_at) VALUES
+    ('11.0.2', 'The War Within', '2024-08-26 15:00:00', '2024-10-22 15:00:00'),
+    ('11.0.5', '20th Anniversary', '2024-10-22 15:00:00', '2024-12-17 15:00:00'),
+    ('11.0.7', 'Siren Isle', '2024-12-17 15:00:00', '2025-02-25 15:00:00'),
+    ('11.1.0', 'Undermined', '2025-02-25 15:00:00', '2025-04-22 15:00:00'),
+    ('11.1.5', 'Nightfall', '2025-04-22 15:00:00', '2025-06-17 15:00:00'),
+    ('11.1.7', 'Legacy of Arathor', '2025-06-17 15:00:00', '2025-08-05 15:00:00'),
+    ('11.2.0', 'Ghosts of K''aresh', '2025-08-05 15:00:00', '2025-10-07 15:00:00'),
+    ('11.2.5', 'Legion Remix', '2025-10-07 15:00:00', NULL)
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE class_rankings ADD COLUMN IF NOT EXISTS patch VARCHAR(20);
+ALTER TABLE warcraft_logs_reports ADD COLUMN IF NOT EXISTS patch VARCHAR(20);
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS patch VARCHAR(20);
+ALTER TABLE build_statistic_segments ADD COLUMN IF NOT EXISTS patch VARCHAR(20);
+ALTER TABLE talent_statistic_segments ADD COLUMN IF NOT EXISTS patch VARCHAR(20);
+ALTER TABLE stat_statistic_segments ADD COLUMN IF NOT EXISTS patch VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_class_rankings_patch ON class_rankings(patch);
+CREATE INDEX IF NOT EXISTS idx_warcraft_logs_reports_patch ON warcraft_logs_reports(patch);
+CREATE INDEX IF NOT EXISTS idx_player_builds_patch ON player_builds(patch);
+CREATE INDEX IF NOT EXISTS idx_build_statistic_segments_patch ON build_statistic_segments(patch);
+CREATE INDEX IF NOT EXISTS idx_talent_statistic_segments_patch ON talent_statistic_segments(patch);
+CREATE INDEX IF NOT EXISTS idx_stat_statistic_segments_patch ON stat_statistic_segments(patch);
+
+-- Tag the existing rankings from the start time of their run
+UPDATE class_rankings cr
+SET patch = p.name
+FROM game_patches p
+WHERE cr.start_time > 0
+  AND p.deleted_at IS NULL
+  AND p.starts_at <= to_timestamp(cr.start_time / 1000.0) AT TIME ZONE 'UTC'
+  AND (p.ends_at IS NULL OR to_timestamp(cr.start_time / 1000.0) AT TIME ZONE 'UTC' < p.ends_at);
+
+-- Tag the existing reports from their ranking and game version
+UPDATE warcraft_logs_reports r
+SET patch = p.name
+FROM class_rankings cr, game_patches p
+WHERE cr.report_code = r.code
+  AND cr.report_fight_id = r.fight_id
+  AND cr.patch = p.name
+  AND (p.game_version IS NULL OR r.game_version = 0 OR p.game_version = r.game_version);
+
+-- Tag the existing builds from their report
+UPDATE player_builds pb
+SET patch = r.patch
+FROM warcraft_logs_reports r
+WHERE pb.report_code = r.code
+  AND pb.fight_id = r.fight_id
+  AND r.patch IS NOT NULL;
@@ -0,0 +1,9 @@
+-- 054_create_report_archives.down.sql
+
+-- Drop indexes for report_archives table
+DROP INDEX IF EXISTS idx_report_archives_deleted_at;
+DROP INDEX IF EXISTS idx_report_archives_archived_at;
+DROP INDEX IF EXISTS idx_report_archives_report_data_type;
+
+-- Drop report_archives table
+DROP TABLE IF EXISTS report_archives;
@@ -0,0 +1,28 @@
+-- 054_create_report_archives.up.sql
+-- This migration creates the manifest of the report data moved to the object store by the retention workflow.
+-- Each row points to the compressed copy of one jsonb column of a report, the column itself is set to NULL.
+-- restored_at is set while the data is copied back in the report for a reprocessing.
+
+CREATE TABLE IF NOT EXISTS report_archives (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    report_code VARCHAR(255) NOT NULL,
+    fight_id INTEGER NOT NULL,
+    data_type VARCHAR(50) NOT NULL,
+
+    storage VARCHAR(20) NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    size_bytes BIGINT DEFAULT 0,
+    compressed_bytes BIGINT DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL,
+
+    archived_at TIMESTAMP NOT NULL,
+    restored_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_archives_report_data_type ON report_archives(report_code, fight_id, data_type);
+CREATE INDEX IF NOT EXISTS idx_report_archives_archived_at ON report_archives(archived_at);
+CREATE INDEX IF NOT EXISTS idx_report_archives_deleted_at ON report_archives(deleted_at);
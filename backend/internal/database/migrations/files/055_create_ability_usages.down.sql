@@ -0,0 +1,22 @@
+-- 055_create_ability_usages.down.sql
+
+-- Drop the fetch tracking column of the reports
+ALTER TABLE warcraft_logs_reports DROP COLUMN IF EXISTS ability_usage_fetched_at;
+
+-- Drop indexes for ability_usage_statistics table
+DROP INDEX IF EXISTS idx_ability_usage_statistics_deleted_at;
+DROP INDEX IF EXISTS idx_ability_usage_statistics_metric;
+DROP INDEX IF EXISTS idx_ability_usage_statistics_encounter_bracket;
+DROP INDEX IF EXISTS idx_ability_usage_statistics_class_spec;
+
+-- Drop ability_usage_statistics table
+DROP TABLE IF EXISTS ability_usage_statistics;
+
+-- Drop indexes for report_ability_usages table
+DROP INDEX IF EXISTS idx_report_ability_usages_deleted_at;
+DROP INDEX IF EXISTS idx_report_ability_usages_class_spec;
+DROP INDEX IF EXISTS idx_report_ability_usages_encounter;
+DROP INDEX IF EXISTS idx_report_ability_usages_report_actor;
+
+-- Drop report_ability_usages table
+DROP TABLE IF EXISTS report_ability_usages;
@@ -0,0 +1,70 @@
+-- 055_create_ability_usages.up.sql
+-- This migration creates the ability usage tables, filled from the casts, interrupts and dispels events of the stored reports.
+-- report_ability_usages holds the counts of each player of a run, ability_usage_statistics their distribution per spec,
+-- dungeon and key level bracket. ability_usage_fetched_at marks the reports whose events have been fetched.
+
+CREATE TABLE IF NOT EXISTS report_ability_usages (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    report_code VARCHAR(255) NOT NULL,
+    fight_id INTEGER NOT NULL,
+    encounter_id INTEGER,
+    keystone_level INTEGER DEFAULT 0,
+
+    actor_id INTEGER NOT NULL,
+    player_name VARCHAR(255),
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    role VARCHAR(20),
+
+    fight_duration BIGINT DEFAULT 0,
+    casts INTEGER DEFAULT 0,
+    cooldown_casts INTEGER DEFAULT 0,
+    cooldowns_tracked BOOLEAN DEFAULT FALSE,
+    interrupts INTEGER DEFAULT 0,
+    dispels INTEGER DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_ability_usages_report_actor ON report_ability_usages(report_code, fight_id, actor_id);
+CREATE INDEX IF NOT EXISTS idx_report_ability_usages_encounter ON report_ability_usages(encounter_id);
+CREATE INDEX IF NOT EXISTS idx_report_ability_usages_class_spec ON report_ability_usages(class, spec);
+CREATE INDEX IF NOT EXISTS idx_report_ability_usages_deleted_at ON report_ability_usages(deleted_at);
+
+CREATE TABLE IF NOT EXISTS ability_usage_statistics (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+    role VARCHAR(20),
+
+    encounter_id INTEGER,
+    key_level_bracket VARCHAR(20) NOT NULL,
+    metric VARCHAR(50) NOT NULL,
+
+    sample_size INTEGER DEFAULT 0,
+    avg_value NUMERIC DEFAULT 0,
+    min_value NUMERIC DEFAULT 0,
+    max_value NUMERIC DEFAULT 0,
+    p25 NUMERIC DEFAULT 0,
+    p50 NUMERIC DEFAULT 0,
+    p75 NUMERIC DEFAULT 0,
+    p95 NUMERIC DEFAULT 0,
+
+    avg_keystone_level NUMERIC DEFAULT 0,
+
+    period_start TIMESTAMP,
+    period_end TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ability_usage_statistics_class_spec ON ability_usage_statistics(class, spec);
+CREATE INDEX IF NOT EXISTS idx_ability_usage_statistics_encounter_bracket ON ability_usage_statistics(encounter_id, key_level_bracket);
+CREATE INDEX IF NOT EXISTS idx_ability_usage_statistics_metric ON ability_usage_statistics(metric);
+CREATE INDEX IF NOT EXISTS idx_ability_usage_statistics_deleted_at ON ability_usage_statistics(deleted_at);
+
+ALTER TABLE warcraft_logs_reports ADD COLUMN IF NOT EXISTS ability_usage_fetched_at TIMESTAMP;
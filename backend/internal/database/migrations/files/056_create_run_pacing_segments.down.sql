@@ -0,0 +1,12 @@
+-- 056_create_run_pacing_segments.down.sql
+
+-- Drop indexes for run_pacing_segments table
+DROP INDEX IF EXISTS idx_run_pacing_segments_deleted_at;
+DROP INDEX IF EXISTS idx_run_pacing_segments_encounter_level;
+DROP INDEX IF EXISTS idx_run_pacing_segments_report_segment;
+
+-- Drop run_pacing_segments table
+DROP TABLE IF EXISTS run_pacing_segments;
+
+-- Drop the fight timeline of the reports
+ALTER TABLE warcraft_logs_reports DROP COLUMN IF EXISTS fights;
@@ -0,0 +1,32 @@
+-- 056_create_run_pacing_segments.up.sql
+-- This migration stores the Mythic+ fight timeline of the reports (fight bounds and dungeon pulls)
+-- and creates run_pacing_segments, the boss and trash segments of each run built from this timeline.
+
+ALTER TABLE warcraft_logs_reports ADD COLUMN IF NOT EXISTS fights JSONB;
+
+CREATE TABLE IF NOT EXISTS run_pacing_segments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    report_code VARCHAR(255) NOT NULL,
+    fight_id INTEGER NOT NULL,
+    encounter_id INTEGER NOT NULL,
+    keystone_level INTEGER DEFAULT 0,
+    keystone_time BIGINT DEFAULT 0,
+    timer_ms BIGINT DEFAULT 0,
+    timed BOOLEAN DEFAULT FALSE,
+
+    segment_index INTEGER NOT NULL,
+    segment_type VARCHAR(20) NOT NULL,
+    boss_encounter_id INTEGER DEFAULT 0,
+    name VARCHAR(255),
+    start_offset BIGINT DEFAULT 0,
+    end_offset BIGINT DEFAULT 0,
+    duration BIGINT DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_run_pacing_segments_report_segment ON run_pacing_segments(report_code, fight_id, segment_index);
+CREATE INDEX IF NOT EXISTS idx_run_pacing_segments_encounter_level ON run_pacing_segments(encounter_id, keystone_level, segment_type, boss_encounter_id);
+CREATE INDEX IF NOT EXISTS idx_run_pacing_segments_deleted_at ON run_pacing_segments(deleted_at);
@@ -0,0 +1,10 @@
+-- 057_create_quarantined_player_builds.down.sql
+
+-- Drop indexes for quarantined_player_builds table
+DROP INDEX IF EXISTS idx_quarantined_player_builds_deleted_at;
+DROP INDEX IF EXISTS idx_quarantined_player_builds_created_at;
+DROP INDEX IF EXISTS idx_quarantined_player_builds_class_spec;
+DROP INDEX IF EXISTS idx_quarantined_player_builds_report_actor;
+
+-- Drop quarantined_player_builds table
+DROP TABLE IF EXISTS quarantined_player_builds;
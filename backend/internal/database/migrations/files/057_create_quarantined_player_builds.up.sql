@@ -0,0 +1,35 @@
+-- 057_create_quarantined_player_builds.up.sql
+-- This migration creates quarantined_player_builds, the builds rejected by the validation stage of the builds workflow.
+-- A rejected build keeps the extracted data with the reasons of its rejection, and is never used by the statistics.
+
+CREATE TABLE IF NOT EXISTS quarantined_player_builds (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    player_name VARCHAR(255) NOT NULL,
+    class VARCHAR(255) NOT NULL,
+    spec VARCHAR(255) NOT NULL,
+
+    report_code VARCHAR(255) NOT NULL,
+    fight_id INTEGER NOT NULL,
+    actor_id INTEGER NOT NULL,
+    encounter_id INTEGER DEFAULT 0,
+    keystone_level INTEGER DEFAULT 0,
+    patch VARCHAR(20),
+
+    item_level NUMERIC,
+    talent_import TEXT,
+    talent_tree JSONB,
+    gear JSONB,
+    stats JSONB,
+
+    reasons TEXT[] NOT NULL,
+    batch_id VARCHAR(255)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantined_player_builds_report_actor ON quarantined_player_builds(report_code, fight_id, actor_id);
+CREATE INDEX IF NOT EXISTS idx_quarantined_player_builds_class_spec ON quarantined_player_builds(class, spec);
+CREATE INDEX IF NOT EXISTS idx_quarantined_player_builds_created_at ON quarantined_player_builds(created_at);
+CREATE INDEX IF NOT EXISTS idx_quarantined_player_builds_deleted_at ON quarantined_player_builds(deleted_at);
@@ -0,0 +1,196 @@
+-- 058_create_player_identities.down.sql
+
+-- Restore the spec_global_score_averages view of migration 036
+DROP VIEW IF EXISTS spec_global_score_averages;
+CREATE OR REPLACE VIEW spec_global_score_averages AS
+WITH specplayerscores AS (
+    SELECT 
+        player_rankings.class,
+        player_rankings.spec,
+        player_rankings.name,
+        player_rankings.server_name,
+        player_rankings.server_region,
+        (sum(player_rankings.score))::numeric(10,2) AS total_score,
+        min((player_rankings.role)::text) AS role
+    FROM player_rankings
+    WHERE player_rankings.deleted_at IS NULL
+      AND player_rankings.server_region != 'CN'
+    GROUP BY player_rankings.class, player_rankings.spec, player_rankings.name, 
+             player_rankings.server_name, player_rankings.server_region
+    HAVING (count(DISTINCT player_rankings.dungeon_id) = 8)
+), 
+top10players AS (
+    SELECT 
+        class,
+        spec,
+        total_score,
+        role,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) as rn
+    FROM specplayerscores
+),
+specaverages AS (
+    SELECT 
+        class,
+        spec,
+        (avg(total_score))::numeric(10,2) AS avg_global_score,
+        (max(total_score))::numeric(10,2) AS max_global_score,
+        (min(total_score))::numeric(10,2) AS min_global_score,
+        count(*) AS player_count,
+        min(role) AS role
+    FROM top10players
+    WHERE rn <= 10
+    GROUP BY class, spec
+)
+SELECT 
+    specaverages.class,
+    specaverages.spec,
+    lower(concat(specaverages.class, '-', replace((specaverages.spec)::text, ' '::text, '-'::text))) AS slug,
+    specaverages.avg_global_score,
+    specaverages.max_global_score,
+    specaverages.min_global_score,
+    specaverages.player_count,
+    specaverages.role,
+    rank() OVER (ORDER BY specaverages.avg_global_score DESC) AS overall_rank,
+    rank() OVER (PARTITION BY specaverages.role ORDER BY specaverages.avg_global_score DESC) AS role_rank
+FROM specaverages
+ORDER BY specaverages.avg_global_score DESC;
+
+-- Restore the class_global_score_averages view of migration 024
+DROP VIEW IF EXISTS class_global_score_averages;
+CREATE OR REPLACE VIEW class_global_score_averages AS
+WITH ClassPlayerScores AS (
+    SELECT 
+        class,
+        name,
+        server_name,
+        server_region,
+        CAST(SUM(score) AS numeric(10,2)) AS total_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL
+    GROUP BY class, name, server_name, server_region
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+)
+SELECT 
+    class,
+    CAST(AVG(total_score) AS numeric(10,2)) AS avg_global_score,
+    COUNT(*) AS player_count
+FROM ClassPlayerScores
+GROUP BY class
+ORDER BY avg_global_score DESC;
+
+-- Restore the top_5_players_per_role view of migration 024
+DROP VIEW IF EXISTS top_5_players_per_role;
+CREATE OR REPLACE VIEW top_5_players_per_role AS
+WITH RolePlayerScores AS (
+    SELECT 
+        name,
+        server_name,
+        server_region,
+        class,
+        spec,
+        role,
+        CAST(SUM(score) AS numeric(10,2)) AS total_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN' -- Exclude CN players
+    GROUP BY name, server_name, server_region, class, spec, role
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+),
+RankedPlayers AS (
+    SELECT 
+        name,
+        server_name,
+        server_region,
+        class,
+        spec,
+        role,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY role ORDER BY total_score DESC) AS rank
+    FROM RolePlayerScores
+)
+SELECT 
+    name,
+    server_name,
+    server_region,
+    class,
+    spec,
+    role,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 5
+ORDER BY role, total_score DESC;
+
+-- Restore the top_10_players_per_spec view of migration 023
+DROP VIEW IF EXISTS top_10_players_per_spec;
+CREATE VIEW top_10_players_per_spec AS
+WITH SpecPlayerScores AS (
+    SELECT 
+        class,
+        spec,
+        name,
+        server_name,
+        server_region,
+        CAST(SUM(score) AS numeric(10,2)) AS total_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN'
+    GROUP BY class, spec, name, server_name, server_region
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+),
+RankedPlayers AS (
+    SELECT 
+        class,
+        spec,
+        name,
+        server_name,
+        server_region,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) AS rank
+    FROM SpecPlayerScores
+)
+SELECT 
+    class,
+    spec,
+    name,
+    server_name,
+    server_region,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 10
+ORDER BY class, spec, total_score DESC;
+
+-- Drop the identity of the rankings and the builds
+DROP INDEX IF EXISTS idx_player_rankings_player_identity_id;
+ALTER TABLE player_rankings DROP COLUMN IF EXISTS player_identity_id;
+
+DROP INDEX IF EXISTS idx_class_rankings_player_identity_id;
+ALTER TABLE class_rankings DROP COLUMN IF EXISTS player_identity_id;
+
+DROP INDEX IF EXISTS idx_player_builds_player_identity_id;
+ALTER TABLE player_builds DROP COLUMN IF EXISTS player_identity_id;
+ALTER TABLE player_builds DROP COLUMN IF EXISTS character_guid;
+ALTER TABLE player_builds DROP COLUMN IF EXISTS server_name;
+
+ALTER TABLE warcraft_logs_reports DROP COLUMN IF EXISTS ranked_characters;
+
+-- Drop the latest aliases view
+DROP VIEW IF EXISTS latest_player_aliases;
+
+-- Drop indexes for player_aliases table
+DROP INDEX IF EXISTS idx_player_aliases_deleted_at;
+DROP INDEX IF EXISTS idx_player_aliases_name;
+DROP INDEX IF EXISTS idx_player_aliases_identity_name;
+
+-- Drop player_aliases table
+DROP TABLE IF EXISTS player_aliases;
+
+-- Drop indexes for player_identities table
+DROP INDEX IF EXISTS idx_player_identities_deleted_at;
+DROP INDEX IF EXISTS idx_player_identities_name;
+DROP INDEX IF EXISTS idx_player_identities_blizzard_character;
+DROP INDEX IF EXISTS idx_player_identities_warcraft_logs_character;
+
+-- Drop player_identities table
+DROP TABLE IF EXISTS player_identities;
@@ -0,0 +1,288 @@
+-- 058_create_player_identities.up.sql
+-- This migration creates player_identities, the canonical identity of a character across its renames and realm transfers,
+-- and player_aliases, the names and realms under which each identity was observed.
+-- The rankings and the builds are linked to the identities, and the global score views count each identity once.
+
+CREATE TABLE IF NOT EXISTS player_identities (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    name VARCHAR(255) NOT NULL,
+    server_name VARCHAR(255),
+    region VARCHAR(50),
+    class VARCHAR(255),
+
+    warcraft_logs_character_id BIGINT,
+    blizzard_character_id BIGINT,
+
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    merged_into_id INTEGER REFERENCES player_identities(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_identities_warcraft_logs_character ON player_identities(warcraft_logs_character_id) WHERE warcraft_logs_character_id IS NOT NULL AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_identities_blizzard_character ON player_identities(blizzard_character_id, region) WHERE blizzard_character_id IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_player_identities_name ON player_identities(LOWER(name), region);
+CREATE INDEX IF NOT EXISTS idx_player_identities_deleted_at ON player_identities(deleted_at);
+
+CREATE TABLE IF NOT EXISTS player_aliases (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    player_identity_id INTEGER NOT NULL REFERENCES player_identities(id),
+    name VARCHAR(255) NOT NULL,
+    server_name VARCHAR(255) NOT NULL DEFAULT '',
+    region VARCHAR(50) NOT NULL DEFAULT '',
+    source VARCHAR(50),
+
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_aliases_identity_name ON player_aliases(player_identity_id, name, server_name, region);
+CREATE INDEX IF NOT EXISTS idx_player_aliases_name ON player_aliases(LOWER(name), region);
+CREATE INDEX IF NOT EXISTS idx_player_aliases_deleted_at ON player_aliases(deleted_at);
+
+-- View latest_player_aliases
+-- The identity currently using each name and realm, used to link the rankings that only have a name and a realm.
+-- Realm names are compared without spaces, dashes and apostrophes ("Tarren Mill" and "TarrenMill" are the same realm).
+CREATE OR REPLACE VIEW latest_player_aliases AS
+SELECT DISTINCT ON (LOWER(name), LOWER(REGEXP_REPLACE(server_name, '[^[:alnum:]]', '', 'g')), region)
+    player_identity_id,
+    LOWER(name) AS name_key,
+    LOWER(REGEXP_REPLACE(server_name, '[^[:alnum:]]', '', 'g')) AS server_key,
+    region
+FROM player_aliases
+WHERE deleted_at IS NULL
+ORDER BY LOWER(name), LOWER(REGEXP_REPLACE(server_name, '[^[:alnum:]]', '', 'g')), region, last_seen_at DESC;
+
+-- Characters of the reports with their Warcraft Logs IDs
+ALTER TABLE warcraft_logs_reports ADD COLUMN IF NOT EXISTS ranked_characters JSONB;
+
+-- Realm, game GUID and identity of the players of the builds
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS server_name VARCHAR(255);
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS character_guid BIGINT;
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS player_identity_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_player_builds_player_identity_id ON player_builds(player_identity_id);
+
+-- Identity of the players of the rankings
+ALTER TABLE class_rankings ADD COLUMN IF NOT EXISTS player_identity_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_class_rankings_player_identity_id ON class_rankings(player_identity_id);
+
+ALTER TABLE player_rankings ADD COLUMN IF NOT EXISTS player_identity_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_player_rankings_player_identity_id ON player_rankings(player_identity_id);
+
+-- View spec_global_score_averages (Updated)
+-- The best score of each dungeon is summed per player identity, so a renamed or transferred player is counted once.
+-- Players without identity are still identified by their name and realm.
+DROP VIEW IF EXISTS spec_global_score_averages;
+CREATE OR REPLACE VIEW spec_global_score_averages AS
+WITH bestdungeonscores AS (
+    SELECT
+        player_rankings.class,
+        player_rankings.spec,
+        COALESCE(player_rankings.player_identity_id::text,
+                 concat(player_rankings.name, '-', player_rankings.server_name, '-', player_rankings.server_region)) AS player_key,
+        player_rankings.dungeon_id,
+        max(player_rankings.score) AS best_score,
+        min((player_rankings.role)::text) AS role
+    FROM player_rankings
+    WHERE player_rankings.deleted_at IS NULL
+      AND player_rankings.server_region != 'CN'
+    GROUP BY 1, 2, 3, 4
+),
+specplayerscores AS (
+    SELECT
+        class,
+        spec,
+        player_key,
+        (sum(best_score))::numeric(10,2) AS total_score,
+        min(role) AS role
+    FROM bestdungeonscores
+    GROUP BY class, spec, player_key
+    HAVING (count(DISTINCT dungeon_id) = 8)
+),
+top10players AS (
+    SELECT 
+        class,
+        spec,
+        total_score,
+        role,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) as rn
+    FROM specplayerscores
+),
+specaverages AS (
+    SELECT 
+        class,
+        spec,
+        (avg(total_score))::numeric(10,2) AS avg_global_score,
+        (max(total_score))::numeric(10,2) AS max_global_score,
+        (min(total_score))::numeric(10,2) AS min_global_score,
+        count(*) AS player_count,
+        min(role) AS role
+    FROM top10players
+    WHERE rn <= 10
+    GROUP BY class, spec
+)
+SELECT 
+    specaverages.class,
+    specaverages.spec,
+    lower(concat(specaverages.class, '-', replace((specaverages.spec)::text, ' '::text, '-'::text))) AS slug,
+    specaverages.avg_global_score,
+    specaverages.max_global_score,
+    specaverages.min_global_score,
+    specaverages.player_count,
+    specaverages.role,
+    rank() OVER (ORDER BY specaverages.avg_global_score DESC) AS overall_rank,
+    rank() OVER (PARTITION BY specaverages.role ORDER BY specaverages.avg_global_score DESC) AS role_rank
+FROM specaverages
+ORDER BY specaverages.avg_global_score DESC;
+
+-- View class_global_score_averages (Updated)
+-- Each identity is counted once per class, with the best score of each dungeon.
+DROP VIEW IF EXISTS class_global_score_averages;
+CREATE OR REPLACE VIEW class_global_score_averages AS
+WITH BestDungeonScores AS (
+    SELECT 
+        class,
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL
+    GROUP BY 1, 2, 3
+),
+ClassPlayerScores AS (
+    SELECT 
+        class,
+        player_key,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY class, player_key
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+)
+SELECT 
+    class,
+    CAST(AVG(total_score) AS numeric(10,2)) AS avg_global_score,
+    COUNT(*) AS player_count
+FROM ClassPlayerScores
+GROUP BY class
+ORDER BY avg_global_score DESC;
+
+-- View top_5_players_per_role (Updated)
+-- Each identity appears once, under the name and realm of its latest run.
+DROP VIEW IF EXISTS top_5_players_per_role;
+CREATE OR REPLACE VIEW top_5_players_per_role AS
+WITH BestDungeonScores AS (
+    SELECT 
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+        MAX(start_time) AS last_start_time,
+        class,
+        spec,
+        role,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN' -- Exclude CN players
+    GROUP BY player_key, class, spec, role, dungeon_id
+),
+RolePlayerScores AS (
+    SELECT 
+        (ARRAY_AGG(name ORDER BY last_start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] AS server_region,
+        class,
+        spec,
+        role,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY player_key, class, spec, role
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+),
+RankedPlayers AS (
+    SELECT 
+        name,
+        server_name,
+        server_region,
+        class,
+        spec,
+        role,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY role ORDER BY total_score DESC) AS rank
+    FROM RolePlayerScores
+)
+SELECT 
+    name,
+    server_name,
+    server_region,
+    class,
+    spec,
+    role,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 5
+ORDER BY role, total_score DESC;
+
+-- View top_10_players_per_spec (Updated)
+-- Each identity appears once, under the name and realm of its latest run.
+DROP VIEW IF EXISTS top_10_players_per_spec;
+CREATE OR REPLACE VIEW top_10_players_per_spec AS
+WITH BestDungeonScores AS (
+    SELECT 
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+        MAX(start_time) AS last_start_time,
+        class,
+        spec,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN'
+    GROUP BY player_key, class, spec, dungeon_id
+),
+SpecPlayerScores AS (
+    SELECT 
+        class,
+        spec,
+        (ARRAY_AGG(name ORDER BY last_start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] AS server_region,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY player_key, class, spec
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+),
+RankedPlayers AS (
+    SELECT 
+        class,
+        spec,
+        name,
+        server_name,
+        server_region,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) AS rank
+    FROM SpecPlayerScores
+)
+SELECT 
+    class,
+    spec,
+    name,
+    server_name,
+    server_region,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 10
+ORDER BY class, spec, total_score DESC;
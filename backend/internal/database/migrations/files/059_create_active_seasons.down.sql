@@ -0,0 +1,218 @@
+-- 059_create_active_seasons.down.sql
+
+-- Restore the spec_global_score_averages view of migration 058
+DROP VIEW IF EXISTS spec_global_score_averages;
+CREATE OR REPLACE VIEW spec_global_score_averages AS
+WITH bestdungeonscores AS (
+    SELECT
+        player_rankings.class,
+        player_rankings.spec,
+        COALESCE(player_rankings.player_identity_id::text,
+                 concat(player_rankings.name, '-', player_rankings.server_name, '-', player_rankings.server_region)) AS player_key,
+        player_rankings.dungeon_id,
+        max(player_rankings.score) AS best_score,
+        min((player_rankings.role)::text) AS role
+    FROM player_rankings
+    WHERE player_rankings.deleted_at IS NULL
+      AND player_rankings.server_region != 'CN'
+    GROUP BY 1, 2, 3, 4
+),
+specplayerscores AS (
+    SELECT
+        class,
+        spec,
+        player_key,
+        (sum(best_score))::numeric(10,2) AS total_score,
+        min(role) AS role
+    FROM bestdungeonscores
+    GROUP BY class, spec, player_key
+    HAVING (count(DISTINCT dungeon_id) = 8)
+),
+top10players AS (
+    SELECT 
+        class,
+        spec,
+        total_score,
+        role,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) as rn
+    FROM specplayerscores
+),
+specaverages AS (
+    SELECT 
+        class,
+        spec,
+        (avg(total_score))::numeric(10,2) AS avg_global_score,
+        (max(total_score))::numeric(10,2) AS max_global_score,
+        (min(total_score))::numeric(10,2) AS min_global_score,
+        count(*) AS player_count,
+        min(role) AS role
+    FROM top10players
+    WHERE rn <= 10
+    GROUP BY class, spec
+)
+SELECT 
+    specaverages.class,
+    specaverages.spec,
+    lower(concat(specaverages.class, '-', replace((specaverages.spec)::text, ' '::text, '-'::text))) AS slug,
+    specaverages.avg_global_score,
+    specaverages.max_global_score,
+    specaverages.min_global_score,
+    specaverages.player_count,
+    specaverages.role,
+    rank() OVER (ORDER BY specaverages.avg_global_score DESC) AS overall_rank,
+    rank() OVER (PARTITION BY specaverages.role ORDER BY specaverages.avg_global_score DESC) AS role_rank
+FROM specaverages
+ORDER BY specaverages.avg_global_score DESC;
+
+-- Restore the class_global_score_averages view of migration 058
+DROP VIEW IF EXISTS class_global_score_averages;
+CREATE OR REPLACE VIEW class_global_score_averages AS
+WITH BestDungeonScores AS (
+    SELECT 
+        class,
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL
+    GROUP BY 1, 2, 3
+),
+ClassPlayerScores AS (
+    SELECT 
+        class,
+        player_key,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY class, player_key
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+)
+SELECT 
+    class,
+    CAST(AVG(total_score) AS numeric(10,2)) AS avg_global_score,
+    COUNT(*) AS player_count
+FROM ClassPlayerScores
+GROUP BY class
+ORDER BY avg_global_score DESC;
+
+-- Restore the top_5_players_per_role view of migration 058
+DROP VIEW IF EXISTS top_5_players_per_role;
+CREATE OR REPLACE VIEW top_5_players_per_role AS
+WITH BestDungeonScores AS (
+    SELECT 
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+        MAX(start_time) AS last_start_time,
+        class,
+        spec,
+        role,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN' -- Exclude CN players
+    GROUP BY player_key, class, spec, role, dungeon_id
+),
+RolePlayerScores AS (
+    SELECT 
+        (ARRAY_AGG(name ORDER BY last_start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] AS server_region,
+        class,
+        spec,
+        role,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY player_key, class, spec, role
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+),
+RankedPlayers AS (
+    SELECT 
+        name,
+        server_name,
+        server_region,
+        class,
+        spec,
+        role,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY role ORDER BY total_score DESC) AS rank
+    FROM RolePlayerScores
+)
+SELECT 
+    name,
+    server_name,
+    server_region,
+    class,
+    spec,
+    role,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 5
+ORDER BY role, total_score DESC;
+
+-- Restore the top_10_players_per_spec view of migration 058
+DROP VIEW IF EXISTS top_10_players_per_spec;
+CREATE OR REPLACE VIEW top_10_players_per_spec AS
+WITH BestDungeonScores AS (
+    SELECT 
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+        MAX(start_time) AS last_start_time,
+        class,
+        spec,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN'
+    GROUP BY player_key, class, spec, dungeon_id
+),
+SpecPlayerScores AS (
+    SELECT 
+        class,
+        spec,
+        (ARRAY_AGG(name ORDER BY last_start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] AS server_region,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY player_key, class, spec
+    HAVING COUNT(DISTINCT dungeon_id) = 8
+),
+RankedPlayers AS (
+    SELECT 
+        class,
+        spec,
+        name,
+        server_name,
+        server_region,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) AS rank
+    FROM SpecPlayerScores
+)
+SELECT 
+    class,
+    spec,
+    name,
+    server_name,
+    server_region,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 10
+ORDER BY class, spec, total_score DESC;
+
+-- Drop the active season function and view
+DROP FUNCTION IF EXISTS active_season_dungeon_count();
+DROP VIEW IF EXISTS active_season_dungeons;
+
+-- Drop indexes for active_seasons table
+DROP INDEX IF EXISTS idx_active_seasons_deleted_at;
+DROP INDEX IF EXISTS idx_active_seasons_region;
+
+-- Drop active_seasons table
+DROP TABLE IF EXISTS active_seasons;
@@ -0,0 +1,294 @@
+-- 059_create_active_seasons.up.sql
+-- This migration creates active_seasons, the Mythic+ season used by the rankings in each region.
+-- A region without active season uses the season running at the current date, so a season rollover only needs a data change.
+-- The global score views require every dungeon of the active season instead of a fixed number of dungeons.
+
+CREATE TABLE IF NOT EXISTS active_seasons (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    region VARCHAR(10) NOT NULL,
+    season_id INTEGER NOT NULL REFERENCES seasons(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_active_seasons_region ON active_seasons(region) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_active_seasons_deleted_at ON active_seasons(deleted_at);
+
+-- View active_season_dungeons
+-- The dungeons of the active season of each region, with their Warcraft Logs encounter IDs.
+-- The configured season of the region is used first, then the latest season started in the region and not yet ended.
+CREATE OR REPLACE VIEW active_season_dungeons AS
+WITH regions(region) AS (
+    VALUES ('US'), ('EU'), ('TW'), ('KR'), ('CN')
+),
+season_dates AS (
+    SELECT
+        r.region,
+        s.id AS season_id,
+        s.slug AS season_slug,
+        CASE r.region
+            WHEN 'US' THEN s.starts_us
+            WHEN 'EU' THEN s.starts_eu
+            WHEN 'TW' THEN s.starts_tw
+            WHEN 'KR' THEN s.starts_kr
+            WHEN 'CN' THEN s.starts_cn
+        END AS starts_at,
+        CASE r.region
+            WHEN 'US' THEN s.ends_us
+            WHEN 'EU' THEN s.ends_eu
+            WHEN 'TW' THEN s.ends_tw
+            WHEN 'KR' THEN s.ends_kr
+            WHEN 'CN' THEN s.ends_cn
+        END AS ends_at
+    FROM regions r
+    CROSS JOIN seasons s
+    WHERE s.deleted_at IS NULL
+),
+current_seasons AS (
+    SELECT DISTINCT ON (sd.region)
+        sd.region,
+        sd.season_id,
+        sd.season_slug
+    FROM season_dates sd
+    LEFT JOIN active_seasons a ON a.region = sd.region AND a.deleted_at IS NULL
+    WHERE (a.season_id IS NOT NULL AND a.season_id = sd.season_id)
+       OR (a.season_id IS NULL AND sd.starts_at <= NOW() AND (sd.ends_at IS NULL OR sd.ends_at > NOW()))
+    ORDER BY sd.region, sd.starts_at DESC
+)
+SELECT
+    cs.region,
+    cs.season_id,
+    cs.season_slug,
+    d.id AS dungeon_id,
+    d.encounter_id,
+    d.name AS dungeon_name,
+    d.slug AS dungeon_slug
+FROM current_seasons cs
+JOIN season_dungeons sdg ON sdg.season_id = cs.season_id AND sdg.deleted_at IS NULL
+JOIN dungeons d ON d.id = sdg.dungeon_id AND d.deleted_at IS NULL
+WHERE d.encounter_id IS NOT NULL;
+
+-- Function active_season_dungeon_count
+-- Number of dungeons a player must complete to have a global score: the largest pool of the active seasons.
+-- Returns 8, the size of the pools so far, while no season is seeded.
+CREATE OR REPLACE FUNCTION active_season_dungeon_count()
+RETURNS INTEGER AS $$
+    SELECT COALESCE(MAX(dungeon_count), 8)::INTEGER
+    FROM (
+        SELECT COUNT(DISTINCT encounter_id) AS dungeon_count
+        FROM active_season_dungeons
+        GROUP BY region
+    ) pools;
+$$ LANGUAGE sql STABLE;
+
+-- View spec_global_score_averages (Updated)
+-- Players must complete every dungeon of the active season.
+DROP VIEW IF EXISTS spec_global_score_averages;
+CREATE OR REPLACE VIEW spec_global_score_averages AS
+WITH bestdungeonscores AS (
+    SELECT
+        player_rankings.class,
+        player_rankings.spec,
+        COALESCE(player_rankings.player_identity_id::text,
+                 concat(player_rankings.name, '-', player_rankings.server_name, '-', player_rankings.server_region)) AS player_key,
+        player_rankings.dungeon_id,
+        max(player_rankings.score) AS best_score,
+        min((player_rankings.role)::text) AS role
+    FROM player_rankings
+    WHERE player_rankings.deleted_at IS NULL
+      AND player_rankings.server_region != 'CN'
+    GROUP BY 1, 2, 3, 4
+),
+specplayerscores AS (
+    SELECT
+        class,
+        spec,
+        player_key,
+        (sum(best_score))::numeric(10,2) AS total_score,
+        min(role) AS role
+    FROM bestdungeonscores
+    GROUP BY class, spec, player_key
+    HAVING (count(DISTINCT dungeon_id) = active_season_dungeon_count())
+),
+top10players AS (
+    SELECT 
+        class,
+        spec,
+        total_score,
+        role,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) as rn
+    FROM specplayerscores
+),
+specaverages AS (
+    SELECT 
+        class,
+        spec,
+        (avg(total_score))::numeric(10,2) AS avg_global_score,
+        (max(total_score))::numeric(10,2) AS max_global_score,
+        (min(total_score))::numeric(10,2) AS min_global_score,
+        count(*) AS player_count,
+        min(role) AS role
+    FROM top10players
+    WHERE rn <= 10
+    GROUP BY class, spec
+)
+SELECT 
+    specaverages.class,
+    specaverages.spec,
+    lower(concat(specaverages.class, '-', replace((specaverages.spec)::text, ' '::text, '-'::text))) AS slug,
+    specaverages.avg_global_score,
+    specaverages.max_global_score,
+    specaverages.min_global_score,
+    specaverages.player_count,
+    specaverages.role,
+    rank() OVER (ORDER BY specaverages.avg_global_score DESC) AS overall_rank,
+    rank() OVER (PARTITION BY specaverages.role ORDER BY specaverages.avg_global_score DESC) AS role_rank
+FROM specaverages
+ORDER BY specaverages.avg_global_score DESC;
+
+-- View class_global_score_averages (Updated)
+-- Players must complete every dungeon of the active season.
+DROP VIEW IF EXISTS class_global_score_averages;
+CREATE OR REPLACE VIEW class_global_score_averages AS
+WITH BestDungeonScores AS (
+    SELECT 
+        class,
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL
+    GROUP BY 1, 2, 3
+),
+ClassPlayerScores AS (
+    SELECT 
+        class,
+        player_key,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY class, player_key
+    HAVING COUNT(DISTINCT dungeon_id) = active_season_dungeon_count()
+)
+SELECT 
+    class,
+    CAST(AVG(total_score) AS numeric(10,2)) AS avg_global_score,
+    COUNT(*) AS player_count
+FROM ClassPlayerScores
+GROUP BY class
+ORDER BY avg_global_score DESC;
+
+-- View top_5_players_per_role (Updated)
+-- Players must complete every dungeon of the active season.
+DROP VIEW IF EXISTS top_5_players_per_role;
+CREATE OR REPLACE VIEW top_5_players_per_role AS
+WITH BestDungeonScores AS (
+    SELECT 
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+        MAX(start_time) AS last_start_time,
+        class,
+        spec,
+        role,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN' -- Exclude CN players
+    GROUP BY player_key, class, spec, role, dungeon_id
+),
+RolePlayerScores AS (
+    SELECT 
+        (ARRAY_AGG(name ORDER BY last_start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] AS server_region,
+        class,
+        spec,
+        role,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY player_key, class, spec, role
+    HAVING COUNT(DISTINCT dungeon_id) = active_season_dungeon_count()
+),
+RankedPlayers AS (
+    SELECT 
+        name,
+        server_name,
+        server_region,
+        class,
+        spec,
+        role,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY role ORDER BY total_score DESC) AS rank
+    FROM RolePlayerScores
+)
+SELECT 
+    name,
+    server_name,
+    server_region,
+    class,
+    spec,
+    role,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 5
+ORDER BY role, total_score DESC;
+
+-- View top_10_players_per_spec (Updated)
+-- Players must complete every dungeon of the active season.
+DROP VIEW IF EXISTS top_10_players_per_spec;
+CREATE OR REPLACE VIEW top_10_players_per_spec AS
+WITH BestDungeonScores AS (
+    SELECT 
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+        MAX(start_time) AS last_start_time,
+        class,
+        spec,
+        dungeon_id,
+        MAX(score) AS best_score
+    FROM player_rankings
+    WHERE deleted_at IS NULL 
+    AND server_region <> 'CN'
+    GROUP BY player_key, class, spec, dungeon_id
+),
+SpecPlayerScores AS (
+    SELECT 
+        class,
+        spec,
+        (ARRAY_AGG(name ORDER BY last_start_time DESC))[1] AS name,
+        (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] AS server_name,
+        (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] AS server_region,
+        CAST(SUM(best_score) AS numeric(10,2)) AS total_score
+    FROM BestDungeonScores
+    GROUP BY player_key, class, spec
+    HAVING COUNT(DISTINCT dungeon_id) = active_season_dungeon_count()
+),
+RankedPlayers AS (
+    SELECT 
+        class,
+        spec,
+        name,
+        server_name,
+        server_region,
+        total_score,
+        ROW_NUMBER() OVER (PARTITION BY class, spec ORDER BY total_score DESC) AS rank
+    FROM SpecPlayerScores
+)
+SELECT 
+    class,
+    spec,
+    name,
+    server_name,
+    server_region,
+    total_score,
+    rank
+FROM RankedPlayers
+WHERE rank <= 10
+ORDER BY class, spec, total_score DESC;
@@ -0,0 +1,13 @@
+-- 060_create_player_rankings_snapshots.down.sql
+
+DROP INDEX IF EXISTS idx_player_rankings_history_deleted_at;
+DROP INDEX IF EXISTS idx_player_rankings_history_identity;
+DROP INDEX IF EXISTS idx_player_rankings_history_snapshot_score;
+DROP INDEX IF EXISTS idx_player_rankings_history_snapshot;
+DROP TABLE IF EXISTS player_rankings_history;
+
+DROP INDEX IF EXISTS idx_player_rankings_snapshots_deleted_at;
+DROP INDEX IF EXISTS idx_player_rankings_snapshots_final;
+DROP INDEX IF EXISTS idx_player_rankings_snapshots_season;
+DROP INDEX IF EXISTS idx_player_rankings_snapshots_captured_at;
+DROP TABLE IF EXISTS player_rankings_snapshots;
@@ -0,0 +1,65 @@
+-- 060_create_player_rankings_snapshots.up.sql
+-- This migration creates the versioned snapshots of player_rankings.
+-- Each rankings update is swapped into player_rankings and copied into player_rankings_history in the same transaction,
+-- so a failed update keeps the previous rankings and past updates stay browsable.
+-- The last snapshot of a season is kept as the final standings of the season once the next season starts.
+
+CREATE TABLE IF NOT EXISTS player_rankings_snapshots (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    season_id INTEGER REFERENCES seasons(id),
+    captured_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    source VARCHAR(50) NOT NULL,
+    ranking_count INTEGER NOT NULL DEFAULT 0,
+    dungeon_count INTEGER NOT NULL DEFAULT 0,
+    is_final BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_rankings_snapshots_captured_at ON player_rankings_snapshots(captured_at DESC);
+CREATE INDEX IF NOT EXISTS idx_player_rankings_snapshots_season ON player_rankings_snapshots(season_id, captured_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_rankings_snapshots_final ON player_rankings_snapshots(season_id) WHERE is_final AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_player_rankings_snapshots_deleted_at ON player_rankings_snapshots(deleted_at);
+
+-- Rankings of each snapshot, with the columns of player_rankings
+CREATE TABLE IF NOT EXISTS player_rankings_history (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    snapshot_id INTEGER NOT NULL REFERENCES player_rankings_snapshots(id) ON DELETE CASCADE,
+    dungeon_id INTEGER,
+    name VARCHAR(255),
+    class VARCHAR(255),
+    spec VARCHAR(255),
+    role VARCHAR(255),
+    amount DOUBLE PRECISION,
+    hard_mode_level INTEGER,
+    duration BIGINT,
+    start_time BIGINT,
+    report_code VARCHAR(255),
+    report_fight_id INTEGER,
+    report_start_time BIGINT,
+    guild_id INTEGER,
+    guild_name VARCHAR(255),
+    guild_faction INTEGER,
+    server_id INTEGER,
+    server_name VARCHAR(255),
+    server_region VARCHAR(50),
+    bracket_data INTEGER,
+    faction INTEGER,
+    affixes INTEGER[],
+    medal VARCHAR(50),
+    score DOUBLE PRECISION,
+    leaderboard INTEGER DEFAULT 0,
+    player_identity_id INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_rankings_history_snapshot ON player_rankings_history(snapshot_id, dungeon_id);
+CREATE INDEX IF NOT EXISTS idx_player_rankings_history_snapshot_score ON player_rankings_history(snapshot_id, score DESC);
+CREATE INDEX IF NOT EXISTS idx_player_rankings_history_identity ON player_rankings_history(player_identity_id);
+CREATE INDEX IF NOT EXISTS idx_player_rankings_history_deleted_at ON player_rankings_history(deleted_at);
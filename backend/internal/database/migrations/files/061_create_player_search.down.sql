@@ -0,0 +1,13 @@
+-- 061_create_player_search.down.sql
+
+DROP VIEW IF EXISTS player_search_entries;
+
+DROP INDEX IF EXISTS idx_class_rankings_player_name_trgm;
+DROP INDEX IF EXISTS idx_player_rankings_name_trgm;
+DROP INDEX IF EXISTS idx_mythicplus_run_characters_name_trgm;
+
+DROP INDEX IF EXISTS idx_mythicplus_run_characters_deleted_at;
+DROP INDEX IF EXISTS idx_mythicplus_run_characters_region;
+DROP INDEX IF EXISTS idx_mythicplus_run_characters_character_id;
+DROP INDEX IF EXISTS idx_mythicplus_run_characters_run_id;
+DROP TABLE IF EXISTS mythicplus_run_characters;
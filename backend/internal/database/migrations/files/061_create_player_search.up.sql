@@ -0,0 +1,77 @@
+-- 061_create_player_search.up.sql
+-- This migration creates the player search over the Warcraft Logs rankings and the Raider.IO runs.
+-- mythicplus_run_characters stores the characters of the Raider.IO runs, the team compositions only keep classes and specs.
+-- Names are matched with trigrams, so a search tolerates typos and partial names.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS mythicplus_run_characters (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES mythicplus_runs(id) ON DELETE CASCADE,
+    character_id BIGINT,
+    name VARCHAR(255) NOT NULL,
+    realm_name VARCHAR(255),
+    realm_slug VARCHAR(255),
+    region VARCHAR(10),
+    class_name VARCHAR(50) NOT NULL,
+    spec_name VARCHAR(50) NOT NULL,
+    role VARCHAR(20) NOT NULL, -- tank, healer, dps
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_mythicplus_run_characters_run_id ON mythicplus_run_characters(run_id);
+CREATE INDEX IF NOT EXISTS idx_mythicplus_run_characters_character_id ON mythicplus_run_characters(character_id);
+CREATE INDEX IF NOT EXISTS idx_mythicplus_run_characters_region ON mythicplus_run_characters(region);
+CREATE INDEX IF NOT EXISTS idx_mythicplus_run_characters_deleted_at ON mythicplus_run_characters(deleted_at);
+
+-- Trigram indexes for the fuzzy matching of names
+CREATE INDEX IF NOT EXISTS idx_mythicplus_run_characters_name_trgm ON mythicplus_run_characters USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_player_rankings_name_trgm ON player_rankings USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_class_rankings_player_name_trgm ON class_rankings USING GIN (LOWER(player_name) gin_trgm_ops);
+
+-- View player_search_entries
+-- Every appearance of a character in the rankings and the Raider.IO runs, with its source.
+CREATE OR REPLACE VIEW player_search_entries AS
+SELECT
+    pr.name,
+    pr.server_name,
+    UPPER(pr.server_region) AS region,
+    pr.class,
+    pr.spec,
+    pr.score,
+    TO_TIMESTAMP(pr.start_time / 1000.0) AS seen_at,
+    pr.player_identity_id,
+    'warcraftlogs_rankings' AS source
+FROM player_rankings pr
+WHERE pr.deleted_at IS NULL
+UNION ALL
+SELECT
+    cr.player_name AS name,
+    cr.server_name,
+    UPPER(cr.server_region) AS region,
+    cr.class,
+    cr.spec,
+    cr.score,
+    TO_TIMESTAMP(cr.start_time / 1000.0) AS seen_at,
+    cr.player_identity_id,
+    'warcraftlogs_class_rankings' AS source
+FROM class_rankings cr
+WHERE cr.deleted_at IS NULL
+UNION ALL
+SELECT
+    mrc.name,
+    mrc.realm_name AS server_name,
+    UPPER(mrc.region) AS region,
+    mrc.class_name AS class,
+    mrc.spec_name AS spec,
+    mr.score,
+    mr.completed_at AS seen_at,
+    NULL::INTEGER AS player_identity_id,
+    'raiderio_runs' AS source
+FROM mythicplus_run_characters mrc
+JOIN mythicplus_runs mr ON mr.id = mrc.run_id AND mr.deleted_at IS NULL
+WHERE mrc.deleted_at IS NULL;
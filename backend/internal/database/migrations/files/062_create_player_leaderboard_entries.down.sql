@@ -0,0 +1,8 @@
+-- 062_create_player_leaderboard_entries.down.sql
+
+DROP FUNCTION IF EXISTS populate_player_leaderboard_entries(INTEGER, INTEGER);
+
+DROP INDEX IF EXISTS idx_player_leaderboard_entries_class_spec;
+DROP INDEX IF EXISTS idx_player_leaderboard_entries_region;
+DROP INDEX IF EXISTS idx_player_leaderboard_entries_page;
+DROP TABLE IF EXISTS player_leaderboard_entries;
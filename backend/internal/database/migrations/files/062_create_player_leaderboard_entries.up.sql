@@ -0,0 +1,103 @@
+-- 062_create_player_leaderboard_entries.up.sql
+-- This migration creates the leaderboard entries of each rankings snapshot, for the cursor-paginated leaderboards.
+-- Entries are computed once per snapshot, so paging is a keyset scan on an index and stays consistent while new snapshots are written.
+-- dungeon_id 0 holds the global entries, the other entries hold the best run of a player in a dungeon.
+
+CREATE TABLE IF NOT EXISTS player_leaderboard_entries (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    snapshot_id INTEGER NOT NULL REFERENCES player_rankings_snapshots(id) ON DELETE CASCADE,
+    dungeon_id INTEGER NOT NULL DEFAULT 0,
+    player_key TEXT NOT NULL,
+    player_identity_id INTEGER,
+    name VARCHAR(255),
+    server_name VARCHAR(255),
+    server_key VARCHAR(255),
+    server_region VARCHAR(50),
+    class VARCHAR(255),
+    spec VARCHAR(255),
+    role VARCHAR(255),
+    total_score NUMERIC(10, 2) NOT NULL,
+    dungeon_count INTEGER NOT NULL,
+    best_medal VARCHAR(50),
+    qualified BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_leaderboard_entries_page ON player_leaderboard_entries(snapshot_id, dungeon_id, total_score DESC, id);
+CREATE INDEX IF NOT EXISTS idx_player_leaderboard_entries_region ON player_leaderboard_entries(snapshot_id, server_region);
+CREATE INDEX IF NOT EXISTS idx_player_leaderboard_entries_class_spec ON player_leaderboard_entries(snapshot_id, class, spec);
+
+-- Function populate_player_leaderboard_entries
+-- Computes the entries of a snapshot, with the same grouping as the global leaderboard:
+-- players are grouped by identity, or by name and realm without identity, and keep their latest name and realm.
+-- Global entries are qualified when the player completed the required number of dungeons, dungeon entries always are.
+CREATE OR REPLACE FUNCTION populate_player_leaderboard_entries(p_snapshot_id INTEGER, p_required_dungeons INTEGER)
+RETURNS INTEGER AS $$
+DECLARE
+    inserted INTEGER;
+BEGIN
+    INSERT INTO player_leaderboard_entries (
+        snapshot_id, dungeon_id, player_key, player_identity_id, name, server_name, server_key, server_region,
+        class, spec, role, total_score, dungeon_count, best_medal, qualified
+    )
+    WITH best_scores AS (
+        SELECT
+            COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+            MAX(player_identity_id) AS player_identity_id,
+            (ARRAY_AGG(name ORDER BY start_time DESC))[1] AS name,
+            (ARRAY_AGG(server_name ORDER BY start_time DESC))[1] AS server_name,
+            (ARRAY_AGG(server_region ORDER BY start_time DESC))[1] AS server_region,
+            MAX(start_time) AS last_start_time,
+            class,
+            spec,
+            role,
+            dungeon_id,
+            MAX(medal) AS best_medal,
+            MAX(score) AS best_score
+        FROM player_rankings_history
+        WHERE snapshot_id = p_snapshot_id
+            AND deleted_at IS NULL
+        GROUP BY 1, class, spec, role, dungeon_id
+    ),
+    entries AS (
+        SELECT
+            dungeon_id, player_key, player_identity_id, name, server_name, server_region,
+            class, spec, role,
+            ROUND(CAST(best_score AS numeric), 2) AS total_score,
+            1 AS dungeon_count,
+            best_medal,
+            TRUE AS qualified
+        FROM best_scores
+        UNION ALL
+        SELECT
+            0,
+            player_key,
+            MAX(player_identity_id),
+            (ARRAY_AGG(name ORDER BY last_start_time DESC))[1],
+            (ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1],
+            (ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1],
+            class, spec, role,
+            ROUND(CAST(SUM(best_score) AS numeric), 2),
+            COUNT(DISTINCT dungeon_id),
+            MAX(best_medal),
+            COUNT(DISTINCT dungeon_id) = p_required_dungeons
+        FROM best_scores
+        GROUP BY player_key, class, spec, role
+    )
+    SELECT
+        p_snapshot_id, dungeon_id, player_key, player_identity_id, name, server_name,
+        LOWER(REGEXP_REPLACE(server_name, '[^[:alnum:]]', '', 'g')), UPPER(server_region),
+        class, spec, role, total_score, dungeon_count, best_medal, qualified
+    FROM entries;
+
+    GET DIAGNOSTICS inserted = ROW_COUNT;
+    RETURN inserted;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Entries of the snapshots written before this migration
+SELECT populate_player_leaderboard_entries(id, dungeon_count)
+FROM player_rankings_snapshots
+WHERE deleted_at IS NULL
+ORDER BY id;
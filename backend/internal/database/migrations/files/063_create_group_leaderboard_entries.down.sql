@@ -0,0 +1,8 @@
+-- 063_create_group_leaderboard_entries.down.sql
+
+DROP FUNCTION IF EXISTS populate_group_leaderboard_entries(INTEGER);
+
+DROP INDEX IF EXISTS idx_group_leaderboard_entries_realm;
+DROP INDEX IF EXISTS idx_group_leaderboard_entries_guild;
+DROP INDEX IF EXISTS idx_group_leaderboard_entries_snapshot;
+DROP TABLE IF EXISTS group_leaderboard_entries;
@@ -0,0 +1,144 @@
+-- 063_create_group_leaderboard_entries.up.sql
+-- This migration creates the guild and realm aggregates of each rankings snapshot.
+-- Players belong to the guild and realm of their latest run in the snapshot, from the Warcraft Logs guild fields of the rankings.
+-- The scores of the players are kept sorted, so top-N averages and score thresholds are computed when querying.
+-- Aggregates are kept per snapshot, so the history of a guild or a realm follows the snapshots.
+
+CREATE TABLE IF NOT EXISTS group_leaderboard_entries (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    snapshot_id INTEGER NOT NULL REFERENCES player_rankings_snapshots(id) ON DELETE CASCADE,
+    scope VARCHAR(10) NOT NULL, -- guild, realm
+    guild_id INTEGER NOT NULL DEFAULT 0,
+    guild_name VARCHAR(255),
+    guild_faction INTEGER,
+    server_name VARCHAR(255),
+    server_key VARCHAR(255),
+    server_region VARCHAR(50),
+    member_count INTEGER NOT NULL,
+    best_score NUMERIC(10, 2) NOT NULL,
+    player_scores NUMERIC(10, 2)[] NOT NULL, -- Sorted from the best score
+    best_timed_keys JSONB NOT NULL DEFAULT '{}' -- Encounter ID -> best timed keystone level
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_leaderboard_entries_snapshot ON group_leaderboard_entries(snapshot_id, scope, server_region);
+CREATE INDEX IF NOT EXISTS idx_group_leaderboard_entries_guild ON group_leaderboard_entries(scope, guild_id, snapshot_id);
+CREATE INDEX IF NOT EXISTS idx_group_leaderboard_entries_realm ON group_leaderboard_entries(scope, server_key, server_region, snapshot_id);
+
+-- Function populate_group_leaderboard_entries
+-- Computes the guild and realm aggregates of a snapshot from its rankings and its player leaderboard entries.
+-- The score of a player is the best global score among its specs, timed keys are the runs with a medal.
+CREATE OR REPLACE FUNCTION populate_group_leaderboard_entries(p_snapshot_id INTEGER)
+RETURNS INTEGER AS $$
+DECLARE
+    inserted INTEGER;
+BEGIN
+    INSERT INTO group_leaderboard_entries (
+        snapshot_id, scope, guild_id, guild_name, guild_faction, server_name, server_key, server_region,
+        member_count, best_score, player_scores, best_timed_keys
+    )
+    WITH player_scores AS (
+        SELECT player_key, MAX(total_score) AS score
+        FROM player_leaderboard_entries
+        WHERE snapshot_id = p_snapshot_id
+            AND dungeon_id = 0
+        GROUP BY player_key
+    ),
+    player_runs AS (
+        SELECT
+            COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+            guild_id,
+            guild_name,
+            guild_faction,
+            server_name,
+            LOWER(REGEXP_REPLACE(server_name, '[^[:alnum:]]', '', 'g')) AS server_key,
+            UPPER(server_region) AS server_region,
+            dungeon_id,
+            hard_mode_level,
+            medal,
+            start_time
+        FROM player_rankings_history
+        WHERE snapshot_id = p_snapshot_id
+            AND deleted_at IS NULL
+    ),
+    members AS (
+        SELECT DISTINCT ON (pr.player_key)
+            pr.player_key, pr.guild_id, pr.guild_name, pr.guild_faction,
+            pr.server_name, pr.server_key, pr.server_region, ps.score
+        FROM player_runs pr
+        JOIN player_scores ps ON ps.player_key = pr.player_key
+        ORDER BY pr.player_key, pr.start_time DESC
+    ),
+    timed_keys AS (
+        SELECT player_key, dungeon_id, MAX(hard_mode_level) AS keystone_level
+        FROM player_runs
+        WHERE medal IN ('gold', 'silver', 'bronze')
+        GROUP BY player_key, dungeon_id
+    ),
+    guild_keys AS (
+        SELECT guild_id, jsonb_object_agg(dungeon_id::text, keystone_level) AS best_timed_keys
+        FROM (
+            SELECT m.guild_id, tk.dungeon_id, MAX(tk.keystone_level) AS keystone_level
+            FROM members m
+            JOIN timed_keys tk ON tk.player_key = m.player_key
+            WHERE m.guild_id > 0
+            GROUP BY m.guild_id, tk.dungeon_id
+        ) keys
+        GROUP BY guild_id
+    ),
+    realm_keys AS (
+        SELECT server_key, server_region, jsonb_object_agg(dungeon_id::text, keystone_level) AS best_timed_keys
+        FROM (
+            SELECT m.server_key, m.server_region, tk.dungeon_id, MAX(tk.keystone_level) AS keystone_level
+            FROM members m
+            JOIN timed_keys tk ON tk.player_key = m.player_key
+            GROUP BY m.server_key, m.server_region, tk.dungeon_id
+        ) keys
+        GROUP BY server_key, server_region
+    )
+    SELECT
+        p_snapshot_id,
+        'guild',
+        m.guild_id,
+        MAX(m.guild_name),
+        MAX(m.guild_faction),
+        MODE() WITHIN GROUP (ORDER BY m.server_name),
+        MODE() WITHIN GROUP (ORDER BY m.server_key),
+        MODE() WITHIN GROUP (ORDER BY m.server_region),
+        COUNT(*),
+        MAX(m.score),
+        ARRAY_AGG(m.score ORDER BY m.score DESC),
+        COALESCE(MAX(gk.best_timed_keys::text)::jsonb, '{}'::jsonb)
+    FROM members m
+    LEFT JOIN guild_keys gk ON gk.guild_id = m.guild_id
+    WHERE m.guild_id > 0
+    GROUP BY m.guild_id
+    UNION ALL
+    SELECT
+        p_snapshot_id,
+        'realm',
+        0,
+        NULL,
+        NULL,
+        MODE() WITHIN GROUP (ORDER BY m.server_name),
+        m.server_key,
+        m.server_region,
+        COUNT(*),
+        MAX(m.score),
+        ARRAY_AGG(m.score ORDER BY m.score DESC),
+        COALESCE(MAX(rk.best_timed_keys::text)::jsonb, '{}'::jsonb)
+    FROM members m
+    LEFT JOIN realm_keys rk ON rk.server_key = m.server_key AND rk.server_region = m.server_region
+    GROUP BY m.server_key, m.server_region;
+
+    GET DIAGNOSTICS inserted = ROW_COUNT;
+    RETURN inserted;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Aggregates of the snapshots written before this migration
+SELECT populate_group_leaderboard_entries(id)
+FROM player_rankings_snapshots
+WHERE deleted_at IS NULL
+ORDER BY id;
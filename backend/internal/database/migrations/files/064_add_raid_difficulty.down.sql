@@ -0,0 +1,39 @@
+-- 064_add_raid_difficulty.down.sql
+-- The statistics of the raid bosses are kept for a single difficulty, the last one registered.
+
+DROP INDEX IF EXISTS idx_player_builds_encounter_difficulty;
+DROP INDEX IF EXISTS idx_class_rankings_encounter_difficulty;
+
+DELETE FROM stat_statistics ss USING raid_encounters re
+WHERE re.encounter_id = ss.encounter_id AND re.difficulty <> ss.difficulty
+AND re.id = (SELECT MAX(id) FROM raid_encounters WHERE encounter_id = ss.encounter_id);
+DELETE FROM talent_statistics ts USING raid_encounters re
+WHERE re.encounter_id = ts.encounter_id AND re.difficulty <> ts.difficulty
+AND re.id = (SELECT MAX(id) FROM raid_encounters WHERE encounter_id = ts.encounter_id);
+DELETE FROM build_statistics bs USING raid_encounters re
+WHERE re.encounter_id = bs.encounter_id AND re.difficulty <> bs.difficulty
+AND re.id = (SELECT MAX(id) FROM raid_encounters WHERE encounter_id = bs.encounter_id);
+
+ALTER TABLE stat_statistics DROP CONSTRAINT IF EXISTS stat_statistics_unique;
+ALTER TABLE stat_statistics ADD CONSTRAINT stat_statistics_unique
+UNIQUE (class, spec, encounter_id, stat_name);
+
+ALTER TABLE talent_statistics DROP CONSTRAINT IF EXISTS talent_statistics_unique;
+ALTER TABLE talent_statistics ADD CONSTRAINT talent_statistics_unique
+UNIQUE (class, spec, encounter_id, talent_import);
+
+ALTER TABLE build_statistics DROP CONSTRAINT IF EXISTS build_statistics_unique;
+ALTER TABLE build_statistics ADD CONSTRAINT build_statistics_unique
+UNIQUE (class, spec, encounter_id, item_slot, item_id);
+
+ALTER TABLE stat_statistics DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE talent_statistics DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE build_statistics DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE player_builds DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE reports DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE class_rankings DROP COLUMN IF EXISTS difficulty;
+
+DELETE FROM raid_encounters re USING raid_encounters newer
+WHERE newer.encounter_id = re.encounter_id AND newer.id > re.id;
+ALTER TABLE raid_encounters DROP CONSTRAINT IF EXISTS raid_encounters_encounter_difficulty_key;
+ALTER TABLE raid_encounters ADD CONSTRAINT raid_encounters_encounter_id_key UNIQUE (encounter_id);
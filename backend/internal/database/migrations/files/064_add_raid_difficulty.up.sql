@@ -0,0 +1,53 @@
+-- 064_add_raid_difficulty.up.sql
+-- This migration keys the raid bosses and their statistics by encounter and difficulty,
+-- so a boss can be targeted at several difficulties (e.g. Heroic and Mythic).
+-- difficulty is 0 for the Mythic+ dungeons, 3 Normal, 4 Heroic and 5 Mythic for the raid bosses.
+-- Existing raid rows get the single difficulty their boss was registered with.
+
+-- Raid bosses
+ALTER TABLE raid_encounters DROP CONSTRAINT IF EXISTS raid_encounters_encounter_id_key;
+ALTER TABLE raid_encounters ADD CONSTRAINT raid_encounters_encounter_difficulty_key UNIQUE (encounter_id, difficulty);
+
+-- Rankings, reports and builds carry the difficulty of their fight
+ALTER TABLE class_rankings ADD COLUMN IF NOT EXISTS difficulty INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS difficulty INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE player_builds ADD COLUMN IF NOT EXISTS difficulty INTEGER NOT NULL DEFAULT 0;
+
+-- Statistics served by the raid routes
+ALTER TABLE build_statistics ADD COLUMN IF NOT EXISTS difficulty INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE talent_statistics ADD COLUMN IF NOT EXISTS difficulty INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE stat_statistics ADD COLUMN IF NOT EXISTS difficulty INTEGER NOT NULL DEFAULT 0;
+
+UPDATE class_rankings cr SET difficulty = re.difficulty
+FROM raid_encounters re WHERE re.encounter_id = cr.encounter_id AND re.deleted_at IS NULL;
+
+UPDATE reports r SET difficulty = re.difficulty
+FROM raid_encounters re WHERE re.encounter_id = r.encounter_id AND re.deleted_at IS NULL;
+
+UPDATE player_builds pb SET difficulty = re.difficulty
+FROM raid_encounters re WHERE re.encounter_id = pb.encounter_id AND re.deleted_at IS NULL;
+
+UPDATE build_statistics bs SET difficulty = re.difficulty
+FROM raid_encounters re WHERE re.encounter_id = bs.encounter_id AND re.deleted_at IS NULL;
+
+UPDATE talent_statistics ts SET difficulty = re.difficulty
+FROM raid_encounters re WHERE re.encounter_id = ts.encounter_id AND re.deleted_at IS NULL;
+
+UPDATE stat_statistics ss SET difficulty = re.difficulty
+FROM raid_encounters re WHERE re.encounter_id = ss.encounter_id AND re.deleted_at IS NULL;
+
+-- Unique keys of the statistics include the difficulty
+ALTER TABLE build_statistics DROP CONSTRAINT IF EXISTS build_statistics_unique;
+ALTER TABLE build_statistics ADD CONSTRAINT build_statistics_unique
+UNIQUE (class, spec, encounter_id, difficulty, item_slot, item_id);
+
+ALTER TABLE talent_statistics DROP CONSTRAINT IF EXISTS talent_statistics_unique;
+ALTER TABLE talent_statistics ADD CONSTRAINT talent_statistics_unique
+UNIQUE (class, spec, encounter_id, difficulty, talent_import);
+
+ALTER TABLE stat_statistics DROP CONSTRAINT IF EXISTS stat_statistics_unique;
+ALTER TABLE stat_statistics ADD CONSTRAINT stat_statistics_unique
+UNIQUE (class, spec, encounter_id, difficulty, stat_name);
+
+CREATE INDEX IF NOT EXISTS idx_class_rankings_encounter_difficulty ON class_rankings(encounter_id, difficulty);
+CREATE INDEX IF NOT EXISTS idx_player_builds_encounter_difficulty ON player_builds(encounter_id, difficulty);
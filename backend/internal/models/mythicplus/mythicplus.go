@@ -28,6 +28,16 @@ type Season struct {
 	Dungeons        []Dungeon      `gorm:"many2many:season_dungeons;"`
 }
 
+// ActiveSeason represents the season used by the rankings in a region
+// A region without active season uses the season running at the current date
+// Example: EU -> TWW Season 2
+type ActiveSeason struct {
+	*gorm.Model `json:"-"`
+	Region      string `gorm:"type:varchar(10);not null"`
+	SeasonID    uint   `gorm:"not null"`
+	Season      *Season
+}
+
 // SeasonalAffix represents a seasonal affix in the Mythic+ dungeon
 // No more SeasonalAffix since DF season 1 but here for reference to older seasons
 // Example: Seasonal affix for Dragonflight S1
@@ -0,0 +1,33 @@
+package raiderioMythicPlusRunsModels
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MythicPlusRunCharacter représente un personnage du roster d'une run, utilisé par la recherche de joueurs
+type MythicPlusRunCharacter struct {
+	ID          uint   `gorm:"primaryKey"`
+	RunID       uint   `gorm:"index;not null"`
+	CharacterID int64  `gorm:"index"`
+	Name        string `gorm:"index;not null"`
+	RealmName   string
+	RealmSlug   string
+	Region      string `gorm:"index"`
+	ClassName   string `gorm:"not null"`
+	SpecName    string `gorm:"not null"`
+	Role        string `gorm:"not null"` // tank, healer, dps
+
+	// Relations
+	Run MythicPlusRuns `gorm:"foreignKey:RunID"`
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (MythicPlusRunCharacter) TableName() string {
+	return "mythicplus_run_characters"
+}
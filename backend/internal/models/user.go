@@ -38,6 +38,13 @@ type User struct {
 	GoogleID    *string `gorm:"uniqueIndex" json:"google_id"`
 	GoogleEmail *string `json:"google_email"`
 
+	// WarcraftLogs OAuth fields - used to access the private reports of the user
+	WarcraftLogsID                    *string    `gorm:"uniqueIndex" json:"warcraft_logs_id"`
+	WarcraftLogsName                  *string    `json:"warcraft_logs_name"`
+	EncryptedWarcraftLogsAccessToken  []byte     `gorm:"type:bytea" json:"-"`
+	EncryptedWarcraftLogsRefreshToken []byte     `gorm:"type:bytea" json:"-"`
+	WarcraftLogsExpiresAt             *time.Time `json:"-"`
+
 	// Character relationship
 	FavoriteCharacterID *uint           `json:"favorite_character_id"`
 	Characters          []UserCharacter `gorm:"foreignKey:UserID" json:"characters,omitempty"`
@@ -218,6 +225,62 @@ func (u *User) UnlinkGoogleAccount() {
 	u.GoogleEmail = nil
 }
 
+// === WARCRAFTLOGS OAUTH METHODS ===
+
+// SetWarcraftLogsTokens encrypts and sets the WarcraftLogs access and refresh tokens
+func (u *User) SetWarcraftLogsTokens(accessToken, refreshToken string, expiresAt time.Time) error {
+	if accessToken == "" {
+		return fmt.Errorf("access token is empty")
+	}
+
+	encryptedAccess, err := crypto.Encrypt([]byte(accessToken))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+
+	// WarcraftLogs does not always send back a new refresh token, keep the existing one in that case
+	if refreshToken != "" {
+		encryptedRefresh, err := crypto.Encrypt([]byte(refreshToken))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt refresh token: %w", err)
+		}
+		u.EncryptedWarcraftLogsRefreshToken = encryptedRefresh
+	}
+
+	u.EncryptedWarcraftLogsAccessToken = encryptedAccess
+	u.WarcraftLogsExpiresAt = &expiresAt
+	return nil
+}
+
+// GetWarcraftLogsAccessToken returns the decrypted WarcraftLogs access token
+func (u *User) GetWarcraftLogsAccessToken() (string, error) {
+	if len(u.EncryptedWarcraftLogsAccessToken) == 0 {
+		return "", fmt.Errorf("no warcraftlogs access token found")
+	}
+	decrypted, err := crypto.Decrypt(u.EncryptedWarcraftLogsAccessToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt warcraftlogs access token: %w", err)
+	}
+	return string(decrypted), nil
+}
+
+// GetWarcraftLogsRefreshToken returns the decrypted WarcraftLogs refresh token, empty if none was provided
+func (u *User) GetWarcraftLogsRefreshToken() (string, error) {
+	if len(u.EncryptedWarcraftLogsRefreshToken) == 0 {
+		return "", nil
+	}
+	decrypted, err := crypto.Decrypt(u.EncryptedWarcraftLogsRefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt warcraftlogs refresh token: %w", err)
+	}
+	return string(decrypted), nil
+}
+
+// IsWarcraftLogsLinked checks if a WarcraftLogs account is linked to the user
+func (u *User) IsWarcraftLogsLinked() bool {
+	return u.WarcraftLogsID != nil && len(u.EncryptedWarcraftLogsAccessToken) > 0
+}
+
 // HasMultipleAuthMethods vérifie si l'utilisateur a plusieurs méthodes d'auth
 func (u *User) HasMultipleAuthMethods() bool {
 	methods := 0
@@ -0,0 +1,104 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Ability usage metrics
+const (
+	AbilityUsageMetricCooldownsPerMinute = "cooldowns_per_minute" // Major cooldown casts per minute of run
+	AbilityUsageMetricInterrupts         = "interrupts"           // Interrupts per run
+	AbilityUsageMetricDispels            = "dispels"              // Dispels per run
+)
+
+// AbilityUsageMetrics lists the ability usage metrics
+var AbilityUsageMetrics = []string{
+	AbilityUsageMetricCooldownsPerMinute,
+	AbilityUsageMetricInterrupts,
+	AbilityUsageMetricDispels,
+}
+
+// IsValidAbilityUsageMetric reports whether a metric is an ability usage metric
+func IsValidAbilityUsageMetric(metric string) bool {
+	for _, valid := range AbilityUsageMetrics {
+		if metric == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportAbilityUsage represents the casts, interrupts and dispels of a player during a run
+// The counts come from the events of the report fight.
+type ReportAbilityUsage struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Run information
+	ReportCode    string `gorm:"type:varchar(255);not null"`
+	FightID       int    `gorm:"not null"`
+	EncounterID   uint   `gorm:"index"`
+	KeystoneLevel int    `gorm:"default:0"`
+
+	// Player information, ActorID is the ID of the player in the report
+	ActorID    int    `gorm:"not null"`
+	PlayerName string `gorm:"type:varchar(255)"`
+	Class      string `gorm:"type:varchar(255);not null"`
+	Spec       string `gorm:"type:varchar(255);not null"`
+	Role       string `gorm:"type:varchar(20)"` // "tank", "healer" or "dps"
+
+	// Usage counts
+	FightDuration    int64 `gorm:"default:0"` // Duration of the run in ms
+	Casts            int   `gorm:"default:0"`
+	CooldownCasts    int   `gorm:"default:0"`     // Casts of the major cooldowns of the spec
+	CooldownsTracked bool  `gorm:"default:false"` // False when no major cooldown is configured for the spec
+	Interrupts       int   `gorm:"default:0"`
+	Dispels          int   `gorm:"default:0"`
+}
+
+func (ReportAbilityUsage) TableName() string {
+	return "report_ability_usages"
+}
+
+// AbilityUsageStatistic represents the distribution of an ability usage metric of a spec for a dungeon and a key level bracket
+type AbilityUsageStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+	Role  string `gorm:"type:varchar(20)"` // "tank", "healer" or "dps"
+
+	// Encounter information
+	EncounterID     uint   `gorm:"index"`
+	KeyLevelBracket string `gorm:"type:varchar(20);not null;index"` // "all", "2-6", "7-11" or "12+"
+	Metric          string `gorm:"type:varchar(50);not null"`       // "cooldowns_per_minute", "interrupts" or "dispels"
+
+	// Distribution
+	SampleSize int     `gorm:"default:0"` // Number of players in the sample
+	AvgValue   float64 `gorm:"default:0"`
+	MinValue   float64 `gorm:"default:0"`
+	MaxValue   float64 `gorm:"default:0"`
+	P25        float64 `gorm:"column:p25;default:0"`
+	P50        float64 `gorm:"column:p50;default:0"`
+	P75        float64 `gorm:"column:p75;default:0"`
+	P95        float64 `gorm:"column:p95;default:0"`
+
+	// Sample information
+	AvgKeystoneLevel float64 `gorm:"default:0"`
+
+	// Analysis window
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (AbilityUsageStatistic) TableName() string {
+	return "ability_usage_statistics"
+}
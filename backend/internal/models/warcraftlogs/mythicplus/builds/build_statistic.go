@@ -20,6 +20,7 @@ type BuildStatistic struct {
 
 	// Encounter ID of the build
 	EncounterID uint `gorm:"index"`
+	Difficulty  uint `gorm:"not null;default:0"` // 0 for Mythic+, raid difficulty for the raid bosses
 
 	// base item informations
 	ItemSlot int `gorm:"index"`
@@ -0,0 +1,92 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Reasons of the rejection of a player build by the validation stage
+const (
+	ValidationReasonInsufficientItems     = "insufficient_items"       // Fewer equipped items than BuildValidationRules.MinItems
+	ValidationReasonItemLevelOutOfBounds  = "item_level_out_of_bounds" // Item level of the build outside of the bounds
+	ValidationReasonZeroStats             = "zero_stats"               // Stats missing or all equal to 0
+	ValidationReasonMissingTalents        = "missing_talents"          // No talent import string
+	ValidationReasonUndecodableTalents    = "undecodable_talents"      // Talent import string that cannot be decoded
+	ValidationReasonSpecTalentMismatch    = "spec_talent_mismatch"     // Talent import string of another spec
+	ValidationReasonUnknownSpecialization = "unknown_specialization"   // Class and spec not found in SpecIDs
+)
+
+// BuildValidationRules holds the bounds of the rule-based checks of the player builds
+type BuildValidationRules struct {
+	MinItems     int     // Minimum number of equipped items
+	MinItemLevel float64 // Minimum item level of the build
+	MaxItemLevel float64 // Maximum item level of the build
+}
+
+// DefaultBuildValidationRules returns the rules used by the builds workflow
+// The item level bounds are wide enough to accept every season, they only reject corrupted values.
+func DefaultBuildValidationRules() BuildValidationRules {
+	return BuildValidationRules{
+		MinItems:     12,
+		MinItemLevel: 400,
+		MaxItemLevel: 800,
+	}
+}
+
+// SpecIDs maps the class and spec names of Warcraft Logs to the specialization IDs of the game
+// The names are stored without spaces, as in the player builds ("DeathKnight", "BeastMastery").
+var SpecIDs = map[string]map[string]int{
+	"DeathKnight": {"Blood": 250, "Frost": 251, "Unholy": 252},
+	"DemonHunter": {"Havoc": 577, "Vengeance": 581},
+	"Druid":       {"Balance": 102, "Feral": 103, "Guardian": 104, "Restoration": 105},
+	"Evoker":      {"Devastation": 1467, "Preservation": 1468, "Augmentation": 1473},
+	"Hunter":      {"BeastMastery": 253, "Marksmanship": 254, "Survival": 255},
+	"Mage":        {"Arcane": 62, "Fire": 63, "Frost": 64},
+	"Monk":        {"Brewmaster": 268, "Windwalker": 269, "Mistweaver": 270},
+	"Paladin":     {"Holy": 65, "Protection": 66, "Retribution": 70},
+	"Priest":      {"Discipline": 256, "Holy": 257, "Shadow": 258},
+	"Rogue":       {"Assassination": 259, "Outlaw": 260, "Subtlety": 261},
+	"Shaman":      {"Elemental": 262, "Enhancement": 263, "Restoration": 264},
+	"Warlock":     {"Affliction": 265, "Demonology": 266, "Destruction": 267},
+	"Warrior":     {"Arms": 71, "Fury": 72, "Protection": 73},
+}
+
+// QuarantinedPlayerBuild is a player build rejected by the validation stage of the builds workflow
+// It keeps the extracted data of the build and the reasons of its rejection, and is not used by the statistics.
+type QuarantinedPlayerBuild struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Player information
+	PlayerName string `gorm:"type:varchar(255);not null"`
+	Class      string `gorm:"type:varchar(255);not null"`
+	Spec       string `gorm:"type:varchar(255);not null"`
+
+	// Run information
+	ReportCode    string `gorm:"type:varchar(255);not null"`
+	FightID       int    `gorm:"not null"`
+	ActorID       int    `gorm:"not null"`
+	EncounterID   uint
+	KeystoneLevel int
+	Patch         string `gorm:"type:varchar(20)"`
+
+	// Extracted build
+	ItemLevel    float64        `gorm:"type:numeric"`
+	TalentImport string         `gorm:"column:talent_import;type:text"`
+	TalentTree   datatypes.JSON `gorm:"type:jsonb"`
+	Gear         datatypes.JSON `gorm:"type:jsonb"`
+	Stats        datatypes.JSON `gorm:"type:jsonb"`
+
+	// Validation
+	Reasons pq.StringArray `gorm:"type:text[];not null"`
+	BatchID string         `gorm:"type:varchar(255)"`
+}
+
+func (QuarantinedPlayerBuild) TableName() string {
+	return "quarantined_player_builds"
+}
@@ -19,9 +19,13 @@ type ClassRanking struct {
 	Class      string `gorm:"type:varchar(255);not null"`
 	Spec       string `gorm:"type:varchar(255);not null"`
 
+	// Identity of the player across renames and transfers, linked once a report of the player is extracted
+	PlayerIdentityID *uint `gorm:"index"`
+
 	// Dungeon info
 	DungeonID   uint `gorm:"-"`
 	EncounterID uint `gorm:"index"`
+	Difficulty  uint `gorm:"not null;default:0"` // 0 for Mythic+, raid difficulty for the raid bosses
 
 	// Run info
 	Amount        float64
@@ -51,6 +55,7 @@ type ClassRanking struct {
 	// Other
 	Faction int
 	Affixes pq.Int64Array `gorm:"type:integer[]"`
+	Patch   string        `gorm:"type:varchar(20);index"` // Patch live when the run started, see GamePatch
 
 	// Tracking fields for workflow processing
 	ReportProcessingStatus string     `gorm:"column:report_processing_status;default:pending"`
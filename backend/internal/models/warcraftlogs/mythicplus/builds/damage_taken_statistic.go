@@ -0,0 +1,58 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Damage taken statistic types
+const (
+	DamageTakenStatisticTypeAbility = "ability" // Damage taken grouped by enemy ability
+	DamageTakenStatisticTypeSpec    = "spec"    // Damage taken by ability for the runs including a spec
+)
+
+// DamageTakenStatistic represents aggregated damage taken data for a dungeon and a key level bracket
+// Damage is normalized by run duration (damage per second) and by keystone level scaling
+type DamageTakenStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Encounter information
+	EncounterID     uint   `gorm:"index"`
+	KeyLevelBracket string `gorm:"type:varchar(20);not null;index"` // "all", "2-6", "7-11" or "12+"
+	StatisticType   string `gorm:"type:varchar(20);not null;index"` // "ability" or "spec"
+
+	// Enemy ability
+	AbilityID   int    `gorm:"not null"`
+	AbilityName string `gorm:"type:varchar(255)"`
+	AbilityIcon string `gorm:"type:varchar(255)"`
+	AbilityType int    `gorm:"default:0"` // Spell school
+
+	// Player classification (for "spec" statistics)
+	Role  string `gorm:"type:varchar(20)"` // "tank", "healer" or "dps"
+	Class string `gorm:"type:varchar(255)"`
+	Spec  string `gorm:"type:varchar(255)"`
+
+	// Damage metrics
+	AvgTotalDamage     float64 `gorm:"default:0"` // Average damage taken per run from the ability
+	AvgDamagePerSecond float64 `gorm:"default:0"` // Average damage taken per second of run
+	AvgNormalizedDPS   float64 `gorm:"default:0"` // Average damage per second normalized to a +2 key
+	Percentage         float64 `gorm:"default:0"` // Share of the damage taken in the bracket
+	OccurrenceRate     float64 `gorm:"default:0"` // Share of the runs where the ability dealt damage
+	RelativeToAverage  float64 `gorm:"default:0"` // Normalized DPS compared with all runs (1.0 = average, for "spec" statistics)
+
+	// Sample information
+	RunsAnalyzed     int     `gorm:"default:0"` // Number of runs of the sample
+	AvgKeystoneLevel float64 `gorm:"default:0"` // Average keystone level of the sample
+
+	// Analysis window
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (DamageTakenStatistic) TableName() string {
+	return "damage_taken_statistics"
+}
@@ -0,0 +1,58 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Death statistic types
+const (
+	DeathStatisticTypeAbility = "ability" // Deaths grouped by killing ability
+	DeathStatisticTypeSpec    = "spec"    // Deaths grouped by class/spec
+	DeathStatisticTypeTiming  = "timing"  // Deaths grouped by moment of the run
+)
+
+// DeathStatistic represents aggregated death data for a dungeon and a key level bracket
+type DeathStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Encounter information
+	EncounterID     uint   `gorm:"index"`
+	KeyLevelBracket string `gorm:"type:varchar(20);not null;index"` // "all", "2-6", "7-11" or "12+"
+	StatisticType   string `gorm:"type:varchar(20);not null;index"` // "ability", "spec" or "timing"
+
+	// Killing ability (for "ability" statistics)
+	AbilityID   int    `gorm:"default:0"`
+	AbilityName string `gorm:"type:varchar(255)"`
+	AbilityIcon string `gorm:"type:varchar(255)"`
+
+	// Player classification (for "spec" statistics)
+	Class string `gorm:"type:varchar(255)"`
+	Spec  string `gorm:"type:varchar(255)"`
+
+	// Run progression (for "timing" statistics), percentage of the run elapsed
+	TimeBucketStart int `gorm:"default:0"`
+	TimeBucketEnd   int `gorm:"default:0"`
+
+	// Death metrics
+	DeathCount   int     `gorm:"default:0"` // Number of deaths
+	DeathsPerRun float64 `gorm:"default:0"` // Average number of deaths per run
+	Percentage   float64 `gorm:"default:0"` // Share of all deaths in the bracket
+	AvgDeathTime float64 `gorm:"default:0"` // Average time of death in ms since the start of the run, over the runs with a fight timeline
+
+	// Sample information
+	RunsAnalyzed     int     `gorm:"default:0"` // Number of runs in the bracket
+	AvgKeystoneLevel float64 `gorm:"default:0"` // Average keystone level of the deaths
+
+	// Analysis window
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (DeathStatistic) TableName() string {
+	return "death_statistics"
+}
@@ -0,0 +1,104 @@
+package warcraftlogsBuilds
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Patch filter values accepted besides a patch name
+const (
+	PatchCurrent = "current"
+	PatchAll     = "all"
+)
+
+// ErrUnknownPatch is returned when a patch name is not in the registry
+var ErrUnknownPatch = errors.New("unknown patch")
+
+// GamePatch represents a named game patch of the registry
+// Rankings, reports and builds are tagged with the patch live when their run started.
+type GamePatch struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	Name        string     `gorm:"type:varchar(20);uniqueIndex;not null"` // e.g. "11.1.0"
+	Label       string     `gorm:"type:varchar(255)"`                     // e.g. "Undermined"
+	StartsAt    time.Time  `gorm:"not null;index"`
+	EndsAt      *time.Time // nil while the patch is live
+	GameVersion *int       // WarcraftLogs game version of the reports, nil matches any version
+}
+
+func (GamePatch) TableName() string {
+	return "game_patches"
+}
+
+// Contains reports whether a time falls within the patch
+func (p *GamePatch) Contains(at time.Time) bool {
+	if at.Before(p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || at.Before(*p.EndsAt)
+}
+
+// MatchesGameVersion reports whether a report game version belongs to the patch, 0 matches any patch
+func (p *GamePatch) MatchesGameVersion(gameVersion int) bool {
+	return p.GameVersion == nil || gameVersion == 0 || *p.GameVersion == gameVersion
+}
+
+// ResolvePatch returns the patch live at a time for a game version, nil if none matches
+func ResolvePatch(patches []*GamePatch, at time.Time, gameVersion int) *GamePatch {
+	var resolved *GamePatch
+	for _, patch := range patches {
+		if !patch.Contains(at) || !patch.MatchesGameVersion(gameVersion) {
+			continue
+		}
+		if resolved == nil || patch.StartsAt.After(resolved.StartsAt) {
+			resolved = patch
+		}
+	}
+	return resolved
+}
+
+// GetGamePatches retrieves the patch registry, ordered by start date
+func GetGamePatches(db *gorm.DB) ([]*GamePatch, error) {
+	var patches []*GamePatch
+	if err := db.Order("starts_at").Find(&patches).Error; err != nil {
+		return nil, fmt.Errorf("failed to get game patches: %w", err)
+	}
+	return patches, nil
+}
+
+// FindGamePatch resolves a patch filter value to a patch of the registry
+// PatchAll resolves to nil, PatchCurrent to the live patch or, if the registry has none, to its latest patch.
+func FindGamePatch(db *gorm.DB, name string) (*GamePatch, error) {
+	if name == "" || name == PatchAll {
+		return nil, nil
+	}
+
+	patches, err := GetGamePatches(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == PatchCurrent {
+		if patch := ResolvePatch(patches, time.Now(), 0); patch != nil {
+			return patch, nil
+		}
+		if len(patches) == 0 {
+			return nil, nil
+		}
+		return patches[len(patches)-1], nil
+	}
+
+	for _, patch := range patches {
+		if patch.Name == name {
+			return patch, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownPatch, name)
+}
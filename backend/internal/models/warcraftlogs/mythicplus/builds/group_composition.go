@@ -3,6 +3,7 @@ package warcraftlogsBuilds
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -15,10 +16,10 @@ type GroupComposition struct {
 
 	ReportCode  string `gorm:"type:varchar(255)"`
 	FightID     int
-	TankSpecs   []string `gorm:"type:text[]"`
-	HealerSpecs []string `gorm:"type:text[]"`
-	DpsSpecs    []string `gorm:"type:text[]"`
-	Success     bool
+	TankSpecs   pq.StringArray `gorm:"type:text[]"` // "Class - Spec" of the tanks
+	HealerSpecs pq.StringArray `gorm:"type:text[]"` // "Class - Spec" of the healers
+	DpsSpecs    pq.StringArray `gorm:"type:text[]"` // "Class - Spec" of the dps, sorted by class then spec
+	Success     bool           // Run completed within the dungeon timer
 
 	DungeonID     uint `gorm:"index"`
 	EncounterID   uint `gorm:"index"`
@@ -0,0 +1,57 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Choice types of the item lift statistics
+const (
+	LiftChoiceItem    = "item"    // Equipped item
+	LiftChoiceEnchant = "enchant" // Permanent enchant
+	LiftChoiceGem     = "gem"     // Socketed gem
+)
+
+// ItemLiftStatistic compares the usage of an item, enchant or gem among the top keys of a spec with its usage in the whole spec population
+// A lift above 1 means the choice is over-represented in the top keys.
+type ItemLiftStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Class and spec of the builds
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Encounter ID of the builds
+	EncounterID uint `gorm:"index"`
+
+	// Choice information
+	ChoiceType string `gorm:"type:varchar(20);not null;index"` // "item", "enchant" or "gem"
+	ItemSlot   int    `gorm:"index"`
+	ChoiceID   int    `gorm:"index"` // Item, enchant or gem ID
+	ChoiceName string `gorm:"type:varchar(255)"`
+	ChoiceIcon string `gorm:"type:varchar(255)"`
+
+	// Top keys sample, builds at or above the top keystone level
+	TopKeystoneLevel int     `gorm:"default:0"`
+	TopCount         int     `gorm:"default:0"`
+	TopSampleSize    int     `gorm:"default:0"`
+	TopUsageRate     float64 `gorm:"default:0"` // Percentage of the top builds using the choice
+
+	// Whole spec population
+	PopulationCount      int     `gorm:"default:0"`
+	PopulationSampleSize int     `gorm:"default:0"`
+	PopulationUsageRate  float64 `gorm:"default:0"` // Percentage of the builds using the choice
+
+	// Lift scores
+	Lift         float64 `gorm:"default:0"` // TopUsageRate / PopulationUsageRate
+	Confidence   float64 `gorm:"default:0"` // 0-1, from a two-proportion z-test between the top builds and the others
+	WeightedLift float64 `gorm:"default:0"` // Lift shrunk towards 1 by the confidence, used for the performance ordering
+}
+
+func (ItemLiftStatistic) TableName() string {
+	return "item_lift_statistics"
+}
@@ -0,0 +1,48 @@
+package warcraftlogsBuilds
+
+// Key level brackets used to segment the Mythic+ statistics
+const (
+	KeyLevelBracketAll  = "all"  // All key levels combined
+	KeyLevelBracketLow  = "2-6"  // Keys from +2 to +6
+	KeyLevelBracketMid  = "7-11" // Keys from +7 to +11
+	KeyLevelBracketHigh = "12+"  // Keys +12 and above
+)
+
+// KeyLevelBrackets lists the key level brackets in ascending order (without the "all" bracket)
+var KeyLevelBrackets = []string{KeyLevelBracketLow, KeyLevelBracketMid, KeyLevelBracketHigh}
+
+// GetKeyLevelBracket returns the bracket matching a keystone level
+func GetKeyLevelBracket(keystoneLevel int) string {
+	switch {
+	case keystoneLevel >= 12:
+		return KeyLevelBracketHigh
+	case keystoneLevel >= 7:
+		return KeyLevelBracketMid
+	default:
+		return KeyLevelBracketLow
+	}
+}
+
+// IsValidKeyLevelBracket checks if a bracket is one of the known brackets
+func IsValidKeyLevelBracket(bracket string) bool {
+	switch bracket {
+	case KeyLevelBracketAll, KeyLevelBracketLow, KeyLevelBracketMid, KeyLevelBracketHigh:
+		return true
+	}
+	return false
+}
+
+// GetKeyLevelBracketBounds returns the keystone levels covered by a bracket
+// A max level of 0 means the bracket has no upper bound
+func GetKeyLevelBracketBounds(bracket string) (int, int) {
+	switch bracket {
+	case KeyLevelBracketLow:
+		return 2, 6
+	case KeyLevelBracketMid:
+		return 7, 11
+	case KeyLevelBracketHigh:
+		return 12, 0
+	default:
+		return 0, 0
+	}
+}
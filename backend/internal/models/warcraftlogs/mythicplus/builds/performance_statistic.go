@@ -0,0 +1,52 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Performance metrics
+const (
+	PerformanceMetricDPS = "dps" // Damage done per second
+	PerformanceMetricHPS = "hps" // Healing done per second
+)
+
+// PerformanceStatistic represents the throughput distribution of a spec for a dungeon and a key level bracket
+type PerformanceStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+	Role  string `gorm:"type:varchar(20)"` // "tank", "healer" or "dps"
+
+	// Encounter information
+	EncounterID     uint   `gorm:"index"`
+	KeyLevelBracket string `gorm:"type:varchar(20);not null;index"` // "all", "2-6", "7-11" or "12+"
+	Metric          string `gorm:"type:varchar(10);not null"`       // "dps" or "hps"
+
+	// Distribution
+	SampleSize int     `gorm:"default:0"` // Number of players in the sample
+	AvgValue   float64 `gorm:"default:0"`
+	MinValue   float64 `gorm:"default:0"`
+	MaxValue   float64 `gorm:"default:0"`
+	P25        float64 `gorm:"column:p25;default:0"`
+	P50        float64 `gorm:"column:p50;default:0"`
+	P75        float64 `gorm:"column:p75;default:0"`
+	P95        float64 `gorm:"column:p95;default:0"`
+
+	// Sample information
+	AvgKeystoneLevel float64 `gorm:"default:0"`
+
+	// Analysis window
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (PerformanceStatistic) TableName() string {
+	return "performance_statistics"
+}
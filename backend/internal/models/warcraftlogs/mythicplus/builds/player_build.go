@@ -19,6 +19,11 @@ type PlayerBuild struct {
 	PlayerName string `gorm:"type:varchar(255);not null"`
 	Class      string `gorm:"type:varchar(255);not null"`
 	Spec       string `gorm:"type:varchar(255);not null"`
+	ServerName string `gorm:"type:varchar(255)"`
+
+	// Identity of the player across renames and transfers, see PlayerIdentity
+	CharacterGUID    int64 `gorm:"column:character_guid"`
+	PlayerIdentityID *uint `gorm:"index"`
 
 	// Report information
 	ReportCode string `gorm:"type:varchar(255)"`
@@ -34,12 +39,18 @@ type PlayerBuild struct {
 	Gear      datatypes.JSON `gorm:"type:jsonb"`
 	Stats     datatypes.JSON `gorm:"type:jsonb"`
 
+	// Consumables used during the fight, nil for builds extracted before they were stored
+	PotionUse      *int `gorm:"column:potion_use"`
+	HealthstoneUse *int `gorm:"column:healthstone_use"`
+
 	// Dungeon information
 	EncounterID uint `gorm:"index"`
+	Difficulty  uint `gorm:"not null;default:0"` // 0 for Mythic+, raid difficulty for the raid bosses
 
 	// Mythic+ information
 	KeystoneLevel int           `gorm:"index"`
 	Affixes       pq.Int64Array `gorm:"type:integer[]"`
+	Patch         string        `gorm:"type:varchar(20);index"` // Copied from the report
 
 	// Analysis status and timestamps
 	EquipmentProcessedAt *time.Time `gorm:"column:equipment_processed_at"`
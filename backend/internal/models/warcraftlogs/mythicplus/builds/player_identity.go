@@ -0,0 +1,111 @@
+package warcraftlogsBuilds
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// PlayerSourceBuild is the source of the players observed in the player details of a report
+const PlayerSourceBuild = "player_builds"
+
+// PlayerIdentity is the canonical identity of a character across its renames and realm transfers
+// Name, ServerName and Region are the latest known ones. An identity merged into another one is soft deleted
+// and keeps the ID of the identity it was merged into.
+type PlayerIdentity struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	Name       string `gorm:"type:varchar(255);not null"`
+	ServerName string `gorm:"type:varchar(255)"`
+	Region     string `gorm:"type:varchar(50)"`
+	Class      string `gorm:"type:varchar(255)"`
+
+	WarcraftLogsCharacterID *int64 `gorm:"column:warcraft_logs_character_id"` // Canonical ID of the character on Warcraft Logs
+	BlizzardCharacterID     *int64 `gorm:"column:blizzard_character_id"`      // GUID of the character in the game, unique per region
+
+	FirstSeenAt  time.Time
+	LastSeenAt   time.Time
+	MergedIntoID *uint
+}
+
+func (PlayerIdentity) TableName() string {
+	return "player_identities"
+}
+
+// PlayerAlias is a name and realm under which a player identity was observed
+type PlayerAlias struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	PlayerIdentityID uint   `gorm:"not null;index"`
+	Name             string `gorm:"type:varchar(255);not null"`
+	ServerName       string `gorm:"type:varchar(255)"`
+	Region           string `gorm:"type:varchar(50)"`
+	Source           string `gorm:"type:varchar(50)"`
+
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+func (PlayerAlias) TableName() string {
+	return "player_aliases"
+}
+
+// RankedCharacter is a character of a report with rankings, as returned by Warcraft Logs
+// CanonicalID follows the character across its renames and transfers, it is 0 when the character never moved.
+type RankedCharacter struct {
+	ID          int64  `json:"id"`
+	CanonicalID int64  `json:"canonicalID"`
+	Name        string `json:"name"`
+	Server      struct {
+		Name   string `json:"name"`
+		Slug   string `json:"slug"`
+		Region struct {
+			CompactName string `json:"compactName"`
+		} `json:"region"`
+	} `json:"server"`
+}
+
+// WarcraftLogsCharacterID returns the canonical ID of the character
+func (c RankedCharacter) WarcraftLogsCharacterID() int64 {
+	if c.CanonicalID > 0 {
+		return c.CanonicalID
+	}
+	return c.ID
+}
+
+// PlayerObservation is a player seen in a run, resolved to a PlayerIdentity
+// The character IDs are 0 when unknown. IdentityID is set by the resolution.
+type PlayerObservation struct {
+	Name                    string
+	ServerName              string
+	Region                  string
+	Class                   string
+	WarcraftLogsCharacterID int64
+	BlizzardCharacterID     int64
+	ReportCode              string
+	FightID                 int
+	Source                  string
+	SeenAt                  time.Time
+
+	IdentityID uint
+}
+
+// NormalizeServerName returns the key used to compare realm names
+// Example: "Tarren Mill", "TarrenMill" and "tarren-mill" -> "tarrenmill"
+func NormalizeServerName(server string) string {
+	var builder strings.Builder
+	for _, r := range server {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,28 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RaidEncounter represents a raid boss targeted by the builds pipeline
+// Its statistics are stored with the Mythic+ ones, keyed by encounter ID and difficulty.
+type RaidEncounter struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	EncounterID uint   `gorm:"uniqueIndex:raid_encounters_encounter_difficulty_key;not null"`
+	Name        string `gorm:"type:varchar(255);not null"`
+	Slug        string `gorm:"type:varchar(255);not null"`
+	RaidName    string `gorm:"type:varchar(255);not null"`
+	RaidSlug    string `gorm:"type:varchar(255);not null;index"`
+	ZoneID      uint   `gorm:"not null;default:0"`
+	Difficulty  uint   `gorm:"uniqueIndex:raid_encounters_encounter_difficulty_key;not null"` // 3 Normal, 4 Heroic, 5 Mythic
+}
+
+func (RaidEncounter) TableName() string {
+	return "raid_encounters"
+}
@@ -14,6 +14,7 @@ type Report struct {
 	Code        string `gorm:"primaryKey;type:varchar(255)"`
 	FightID     int    `gorm:"primaryKey;autoIncrement:false"` // Part of composite primary key
 	EncounterID uint   `gorm:"index"`
+	Difficulty  uint   `gorm:"not null;default:0"` // 0 for Mythic+, raid difficulty for the raid bosses
 
 	// base data
 	TotalTime int64
@@ -30,10 +31,12 @@ type Report struct {
 	PlayerDetailsDps     datatypes.JSON `gorm:"type:jsonb;column:player_details_dps"`
 	PlayerDetailsHealers datatypes.JSON `gorm:"type:jsonb;column:player_details_healers"`
 	PlayerDetailsTanks   datatypes.JSON `gorm:"type:jsonb;column:player_details_tanks"`
+	RankedCharacters     datatypes.JSON `gorm:"type:jsonb"` // Characters with their Warcraft Logs IDs, see RankedCharacter
 
 	// combat data
 	LogVersion  int
 	GameVersion int
+	Patch       string `gorm:"type:varchar(20);index"` // Tagged from the ranking of the run, see GamePatch
 
 	// mythic+ data
 	KeystoneLevel   int            `gorm:"column:keystonelevel"`
@@ -41,6 +44,7 @@ type Report struct {
 	Affixes         pq.Int64Array  `gorm:"type:integer[]"`
 	FriendlyPlayers pq.Int64Array  `gorm:"type:integer[]"`
 	TalentCodes     datatypes.JSON `gorm:"type:jsonb"`
+	Fights          datatypes.JSON `gorm:"type:jsonb"` // Fight bounds and dungeon pulls, see ReportFightTimeline
 
 	// raw data
 	RawData datatypes.JSON `gorm:"type:jsonb"`
@@ -54,6 +58,9 @@ type Report struct {
 	BuildExtractionStatus string     `gorm:"column:build_extraction_status;default:pending"`
 	BuildExtractionAt     *time.Time `gorm:"column:build_extraction_at"`
 	ProcessingBatchID     string     `gorm:"column:processing_batch_id"`
+
+	// Casts, interrupts and dispels events fetch tracking, see ReportAbilityUsage
+	AbilityUsageFetchedAt *time.Time `gorm:"column:ability_usage_fetched_at"`
 }
 
 func (Report) TableName() string {
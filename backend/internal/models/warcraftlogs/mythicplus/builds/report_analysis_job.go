@@ -0,0 +1,107 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Status of a report analysis job
+const (
+	ReportAnalysisStatusPending   = "pending"
+	ReportAnalysisStatusRunning   = "running"
+	ReportAnalysisStatusCompleted = "completed"
+	ReportAnalysisStatusFailed    = "failed"
+)
+
+// ReportAnalysisJob represents an on-demand analysis of a WarcraftLogs report submitted by a user
+// The analysis runs in a Temporal workflow, the result is stored as JSON once completed.
+type ReportAnalysisJob struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	JobID      string `gorm:"type:varchar(36);uniqueIndex;not null"`
+	ReportCode string `gorm:"type:varchar(255);not null;index"`
+	FightID    int    // 0 to analyze every Mythic+ fight of the report
+	UserID     *uint  `gorm:"index"` // User whose WarcraftLogs token is used for private reports
+
+	Status     string         `gorm:"type:varchar(20);not null;index"`
+	Error      string         `gorm:"type:text"`
+	WorkflowID string         `gorm:"type:varchar(255)"`
+	Result     datatypes.JSON `gorm:"type:jsonb"` // ReportAnalysisResult
+
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+func (ReportAnalysisJob) TableName() string {
+	return "report_analysis_jobs"
+}
+
+// ReportAnalysisResult is the result of a report analysis
+type ReportAnalysisResult struct {
+	ReportCode string          `json:"report_code"`
+	Fights     []FightAnalysis `json:"fights"`
+}
+
+// FightAnalysis is the comparison of the players of a Mythic+ fight against the stored statistics
+type FightAnalysis struct {
+	FightID       int                     `json:"fight_id"`
+	EncounterID   uint                    `json:"encounter_id"`
+	KeystoneLevel int                     `json:"keystone_level"`
+	KeystoneTime  int64                   `json:"keystone_time"`
+	Players       []PlayerBuildComparison `json:"players"`
+}
+
+// PlayerBuildComparison is the comparison of a player build against the builds of the top players of the same spec and dungeon
+type PlayerBuildComparison struct {
+	PlayerName          string            `json:"player_name"`
+	Class               string            `json:"class"`
+	Spec                string            `json:"spec"`
+	ItemLevel           float64           `json:"item_level"`
+	StatisticsAvailable bool              `json:"statistics_available"`
+	Items               []ItemComparison  `json:"items"`
+	Talents             *TalentComparison `json:"talents,omitempty"`
+	Stats               []StatComparison  `json:"stats"`
+}
+
+// ItemComparison compares the item of a slot with the most used item of the top players
+type ItemComparison struct {
+	Slot               int     `json:"slot"`
+	ItemID             int     `json:"item_id"`
+	ItemName           string  `json:"item_name"`
+	ItemLevel          int     `json:"item_level"`
+	UsagePercentage    float64 `json:"usage_percentage"` // Usage of the player item by the top players
+	PopularItemID      int     `json:"popular_item_id"`
+	PopularItemName    string  `json:"popular_item_name"`
+	PopularItemIcon    string  `json:"popular_item_icon"`
+	PopularUsage       float64 `json:"popular_usage_percentage"`
+	IsPopular          bool    `json:"is_popular"`
+	PermanentEnchantID int     `json:"permanent_enchant_id"`
+	PopularEnchantID   int     `json:"popular_enchant_id"`
+	PopularEnchantName string  `json:"popular_enchant_name"`
+	HasPopularEnchant  bool    `json:"has_popular_enchant"`
+}
+
+// TalentComparison compares the talent loadout with the most used loadouts of the top players
+type TalentComparison struct {
+	TalentImport        string  `json:"talent_import"`
+	UsagePercentage     float64 `json:"usage_percentage"` // Usage of the player loadout by the top players
+	PopularTalentImport string  `json:"popular_talent_import"`
+	PopularUsage        float64 `json:"popular_usage_percentage"`
+	IsPopular           bool    `json:"is_popular"`
+}
+
+// StatComparison compares a stat of the player with the average of the top players
+type StatComparison struct {
+	StatName      string  `json:"stat_name"`
+	StatCategory  string  `json:"stat_category"`
+	Value         float64 `json:"value"`
+	AvgValue      float64 `json:"avg_value"`
+	MinValue      float64 `json:"min_value"`
+	MaxValue      float64 `json:"max_value"`
+	DifferencePct float64 `json:"difference_percentage"` // (value - avg) / avg * 100
+}
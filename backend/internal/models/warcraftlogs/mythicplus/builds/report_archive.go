@@ -0,0 +1,88 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Report data types that can be archived, named after their column in warcraft_logs_reports
+const (
+	ReportDataRaw                  = "raw_data"
+	ReportDataDamageDone           = "damage_done"
+	ReportDataHealingDone          = "healing_done"
+	ReportDataDamageTaken          = "damage_taken"
+	ReportDataDeathEvents          = "death_events"
+	ReportDataPlayerDetailsDps     = "player_details_dps"
+	ReportDataPlayerDetailsHealers = "player_details_healers"
+	ReportDataPlayerDetailsTanks   = "player_details_tanks"
+)
+
+// ReportArchiveDataTypes lists the report data types that can be archived
+// The combat tables and the player details are read by the dungeon analyses and the build extraction
+// over lookbacks longer than any retention, so only the raw data is moved to the archive store.
+var ReportArchiveDataTypes = []string{
+	ReportDataRaw,
+}
+
+// ReportRestoreDataTypes lists the report data types that can be restored
+// It keeps the data types archived by the previous retention policies, so their archives can be brought back.
+var ReportRestoreDataTypes = []string{
+	ReportDataRaw,
+	ReportDataDamageDone,
+	ReportDataHealingDone,
+	ReportDataDamageTaken,
+	ReportDataDeathEvents,
+	ReportDataPlayerDetailsDps,
+	ReportDataPlayerDetailsHealers,
+	ReportDataPlayerDetailsTanks,
+}
+
+// IsValidReportArchiveDataType reports whether a data type can be archived
+// The data type is also the column name, so it must be checked before being used in a query.
+func IsValidReportArchiveDataType(dataType string) bool {
+	return containsDataType(ReportArchiveDataTypes, dataType)
+}
+
+// IsValidReportRestoreDataType reports whether a data type can be restored
+func IsValidReportRestoreDataType(dataType string) bool {
+	return containsDataType(ReportRestoreDataTypes, dataType)
+}
+
+// containsDataType reports whether a data type is in the list
+func containsDataType(dataTypes []string, dataType string) bool {
+	for _, valid := range dataTypes {
+		if dataType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportArchive represents the manifest entry of a report data type moved to the object store
+// The column of the report is cleared once archived and filled again when the archive is restored.
+type ReportArchive struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Archived report data
+	ReportCode string `gorm:"type:varchar(255);not null;index"`
+	FightID    int    `gorm:"not null"`
+	DataType   string `gorm:"type:varchar(50);not null"`
+
+	// Archive object
+	Storage         string `gorm:"type:varchar(20);not null"` // "local" or "s3"
+	ObjectKey       string `gorm:"type:varchar(512);not null"`
+	SizeBytes       int64  // Size of the JSON before compression
+	CompressedBytes int64
+	Checksum        string `gorm:"type:varchar(64);not null"` // SHA-256 of the JSON
+
+	ArchivedAt time.Time  `gorm:"not null"`
+	RestoredAt *time.Time // Set while the data is back in the report
+}
+
+func (ReportArchive) TableName() string {
+	return "report_archives"
+}
@@ -0,0 +1,62 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Segment types of a run pacing timeline
+const (
+	PacingSegmentTrash = "trash" // Pulls between two bosses, or after the last boss
+	PacingSegmentBoss  = "boss"  // Pulls of a boss, wipes included, until its kill
+)
+
+// ReportFightTimeline is the content of Report.Fights: the bounds of the Mythic+ fight and its dungeon pulls
+// Timestamps are relative to the start of the report, in milliseconds.
+type ReportFightTimeline struct {
+	ID           int           `json:"id"`
+	StartTime    int64         `json:"startTime"`
+	EndTime      int64         `json:"endTime"`
+	DungeonPulls []DungeonPull `json:"dungeonPulls"`
+}
+
+// DungeonPull is a pull of a Mythic+ fight, EncounterID is 0 for trash pulls
+type DungeonPull struct {
+	ID          int    `json:"id"`
+	EncounterID uint   `json:"encounterID"`
+	Name        string `json:"name"`
+	StartTime   int64  `json:"startTime"`
+	EndTime     int64  `json:"endTime"`
+	Kill        bool   `json:"kill"`
+}
+
+// RunPacingSegment is a segment of the pacing timeline of a run
+// Offsets are relative to the start of the run, in milliseconds. BossEncounterID is the boss
+// of the segment, or the boss following a trash segment (0 for the trash after the last boss).
+type RunPacingSegment struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	ReportCode    string `gorm:"type:varchar(255)"`
+	FightID       int
+	EncounterID   uint `gorm:"index"` // Dungeon of the run
+	KeystoneLevel int
+	KeystoneTime  int64 // Duration of the run, in milliseconds
+	TimerMS       int64 // Dungeon timer, 0 when unknown
+	Timed         bool  // Run completed within the dungeon timer
+
+	SegmentIndex    int
+	SegmentType     string `gorm:"type:varchar(20)"`
+	BossEncounterID uint
+	Name            string `gorm:"type:varchar(255)"`
+	StartOffset     int64
+	EndOffset       int64
+	Duration        int64
+}
+
+func (RunPacingSegment) TableName() string {
+	return "run_pacing_segments"
+}
@@ -0,0 +1,67 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Performance metrics the secondary stats are regressed against
+const (
+	StatWeightMetricKeystoneLevel = "keystone_level" // Keystone level of the run
+	StatWeightMetricScore         = "score"          // Mythic+ score of the ranking
+	StatWeightMetricDPS           = "dps"            // Damage per second in the report
+	StatWeightMetricHPS           = "hps"            // Healing per second in the report
+)
+
+// StatWeightMetrics lists every metric supported by the stat weights analysis
+var StatWeightMetrics = []string{
+	StatWeightMetricKeystoneLevel,
+	StatWeightMetricScore,
+	StatWeightMetricDPS,
+	StatWeightMetricHPS,
+}
+
+// IsValidStatWeightMetric checks if a metric is supported by the stat weights analysis
+func IsValidStatWeightMetric(metric string) bool {
+	switch metric {
+	case StatWeightMetricKeystoneLevel, StatWeightMetricScore, StatWeightMetricDPS, StatWeightMetricHPS:
+		return true
+	}
+	return false
+}
+
+// StatWeightStatistic represents the estimated weight of a secondary stat for a spec
+// The weights come from a linear regression of the performance metric on the secondary stat ratings,
+// controlled by the item level, and are relative to the most valuable stat of the spec.
+type StatWeightStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Class and spec of the builds
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Regression target and regressor
+	Metric   string `gorm:"type:varchar(50);not null;index"` // "keystone_level", "score", "dps" or "hps"
+	StatName string `gorm:"type:varchar(50);not null"`
+
+	// Raw regression coefficient, in metric points per rating point
+	Coefficient float64 `gorm:"default:0"`
+	StdError    float64 `gorm:"default:0"`
+
+	// Relative weight, the best stat of the spec is 1
+	Weight  float64 `gorm:"default:0"`
+	CILower float64 `gorm:"column:ci_lower;default:0"` // Lower bound of the 95% confidence interval of the weight
+	CIUpper float64 `gorm:"column:ci_upper;default:0"` // Upper bound of the 95% confidence interval of the weight
+
+	// Fit information
+	SampleSize int     `gorm:"default:0"` // Number of builds in the regression
+	RSquared   float64 `gorm:"column:r_squared;default:0"`
+}
+
+func (StatWeightStatistic) TableName() string {
+	return "stat_weight_statistics"
+}
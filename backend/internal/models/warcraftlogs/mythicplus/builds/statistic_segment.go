@@ -0,0 +1,127 @@
+package warcraftlogsBuilds
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// SortedAffixes returns a sorted copy of an affix set, so the same affixes always form the same segment
+func SortedAffixes(affixes []int64) pq.Int64Array {
+	sorted := make(pq.Int64Array, len(affixes))
+	copy(sorted, affixes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// StatisticSegmentKey returns the key of the segment of a build: its patch, keystone level and sorted affix set
+func StatisticSegmentKey(patch string, keystoneLevel int, affixes []int64) string {
+	ids := make([]string, 0, len(affixes))
+	for _, affix := range SortedAffixes(affixes) {
+		ids = append(ids, strconv.FormatInt(affix, 10))
+	}
+	return patch + "|" + strconv.Itoa(keystoneLevel) + "|" + strings.Join(ids, ",")
+}
+
+// BuildStatisticSegment represents the usage of an item for a patch, a keystone level and an affix set
+// Segments are summed at query time to filter the item statistics by patch, affix and key level range.
+type BuildStatisticSegment struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Class and spec of the build
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Segment of the builds
+	EncounterID   uint          `gorm:"index"`
+	KeystoneLevel int           `gorm:"index"`
+	Affixes       pq.Int64Array `gorm:"type:integer[]"` // Sorted affix IDs
+	Patch         string        `gorm:"type:varchar(20);index"`
+
+	// Item information
+	ItemSlot    int     `gorm:"index"`
+	ItemID      int     `gorm:"index"`
+	ItemName    string  `gorm:"type:varchar(255)"`
+	ItemIcon    string  `gorm:"type:varchar(255)"`
+	ItemQuality int     `gorm:"default:0"`
+	ItemLevel   float64 `gorm:"default:0"`
+
+	// Usage statistics
+	UsageCount   int     `gorm:"default:0"`
+	AvgItemLevel float64 `gorm:"default:0"`
+}
+
+func (BuildStatisticSegment) TableName() string {
+	return "build_statistic_segments"
+}
+
+// TalentStatisticSegment represents the usage of a talent import for a patch, a keystone level and an affix set
+type TalentStatisticSegment struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Segment of the builds
+	EncounterID   uint          `gorm:"index"`
+	KeystoneLevel int           `gorm:"index"`
+	Affixes       pq.Int64Array `gorm:"type:integer[]"` // Sorted affix IDs
+	Patch         string        `gorm:"type:varchar(20);index"`
+
+	// Talent import
+	TalentImport string `gorm:"type:text"`
+
+	// Usage statistics
+	UsageCount   int     `gorm:"default:0"`
+	AvgItemLevel float64 `gorm:"default:0"`
+}
+
+func (TalentStatisticSegment) TableName() string {
+	return "talent_statistic_segments"
+}
+
+// StatStatisticSegment represents the value of a stat for a patch, a keystone level and an affix set
+type StatStatisticSegment struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Segment of the builds
+	EncounterID   uint          `gorm:"index"`
+	KeystoneLevel int           `gorm:"index"`
+	Affixes       pq.Int64Array `gorm:"type:integer[]"` // Sorted affix IDs
+	Patch         string        `gorm:"type:varchar(20);index"`
+
+	// Stat identification
+	StatName     string `gorm:"type:varchar(50);not null"`
+	StatCategory string `gorm:"type:varchar(50);not null"` // "secondary" or "minor"
+
+	// Stat value
+	AvgValue   float64 `gorm:"default:0"`
+	MinValue   float64 `gorm:"default:0"`
+	MaxValue   float64 `gorm:"default:0"`
+	SampleSize int     `gorm:"default:0"`
+
+	// Item level of the builds
+	AvgItemLevel float64 `gorm:"default:0"`
+}
+
+func (StatStatisticSegment) TableName() string {
+	return "stat_statistic_segments"
+}
@@ -0,0 +1,123 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Weekly reset of the snapshots, Tuesday 15:00 UTC (US region)
+const (
+	weeklyResetWeekday = time.Tuesday
+	weeklyResetHour    = 15
+)
+
+// WeeklyResetPeriod returns the start of the weekly reset period containing t
+// Snapshots taken during the same week share this period start, so a new run of a workflow replaces them.
+func WeeklyResetPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	reset := time.Date(t.Year(), t.Month(), t.Day(), weeklyResetHour, 0, 0, 0, time.UTC)
+
+	daysSinceReset := (int(t.Weekday()) - int(weeklyResetWeekday) + 7) % 7
+	reset = reset.AddDate(0, 0, -daysSinceReset)
+
+	// Before the reset hour on reset day, the period started a week earlier
+	if reset.After(t) {
+		reset = reset.AddDate(0, 0, -7)
+	}
+	return reset
+}
+
+// BuildStatisticSnapshot is the weekly copy of the popularity of an item
+type BuildStatisticSnapshot struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Start of the weekly reset period of the snapshot
+	PeriodStart time.Time `gorm:"not null;index"`
+
+	// Class and spec of the build
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Encounter ID of the build
+	EncounterID uint `gorm:"index"`
+
+	// Item information
+	ItemSlot int    `gorm:"index"`
+	ItemID   int    `gorm:"index"`
+	ItemName string `gorm:"type:varchar(255)"`
+	ItemIcon string `gorm:"type:varchar(255)"`
+
+	// Usage statistics at the time of the snapshot
+	UsageCount       int     `gorm:"default:0"`
+	UsagePercentage  float64 `gorm:"default:0"`
+	AvgItemLevel     float64 `gorm:"default:0"`
+	AvgKeystoneLevel float64 `gorm:"default:0"`
+}
+
+func (BuildStatisticSnapshot) TableName() string {
+	return "build_statistic_snapshots"
+}
+
+// TalentStatisticSnapshot is the weekly copy of the popularity of a talent import
+type TalentStatisticSnapshot struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Start of the weekly reset period of the snapshot
+	PeriodStart time.Time `gorm:"not null;index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Encounter information
+	EncounterID uint `gorm:"index"`
+
+	// Talent import
+	TalentImport string `gorm:"type:text"`
+
+	// Usage statistics at the time of the snapshot
+	UsageCount       int     `gorm:"default:0"`
+	UsagePercentage  float64 `gorm:"default:0"`
+	AvgKeystoneLevel float64 `gorm:"default:0"`
+}
+
+func (TalentStatisticSnapshot) TableName() string {
+	return "talent_statistic_snapshots"
+}
+
+// StatStatisticSnapshot is the weekly copy of the average value of a stat
+type StatStatisticSnapshot struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Start of the weekly reset period of the snapshot
+	PeriodStart time.Time `gorm:"not null;index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Encounter information
+	EncounterID uint `gorm:"index"`
+
+	// Stat identification
+	StatName     string `gorm:"type:varchar(50);not null"`
+	StatCategory string `gorm:"type:varchar(50);not null"`
+
+	// Stat value at the time of the snapshot
+	AvgValue   float64 `gorm:"default:0"`
+	SampleSize int     `gorm:"default:0"`
+}
+
+func (StatStatisticSnapshot) TableName() string {
+	return "stat_statistic_snapshots"
+}
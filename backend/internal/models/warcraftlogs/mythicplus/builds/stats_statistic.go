@@ -19,6 +19,7 @@ type StatStatistic struct {
 
 	// Encounter information
 	EncounterID uint `gorm:"index"`
+	Difficulty  uint `gorm:"not null;default:0"` // 0 for Mythic+, raid difficulty for the raid bosses
 
 	// Stat identification
 	StatName     string `gorm:"type:varchar(50);not null;index"`
@@ -0,0 +1,37 @@
+package warcraftlogsBuilds
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TalentNodeStatistic represents the pick rate of a talent entry of a node for a spec and a dungeon
+// Choice nodes have one row per entry, the pick rate of the node is the sum of its entries.
+type TalentNodeStatistic struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+
+	// Player classification
+	Class string `gorm:"type:varchar(255);not null;index"`
+	Spec  string `gorm:"type:varchar(255);not null;index"`
+
+	// Encounter information
+	EncounterID uint `gorm:"index"`
+
+	// Talent node and entry, as stored in the talent tree of the builds
+	NodeID  int `gorm:"index"`
+	EntryID int `gorm:"index"`
+
+	// Pick statistics
+	PickCount  int     `gorm:"default:0"` // Number of builds picking this entry
+	SampleSize int     `gorm:"default:0"` // Number of builds of the spec for the dungeon
+	PickRate   float64 `gorm:"default:0"` // Percentage of builds picking this entry
+	AvgRank    float64 `gorm:"default:0"` // Average rank among the builds picking this entry
+}
+
+func (TalentNodeStatistic) TableName() string {
+	return "talent_node_statistics"
+}
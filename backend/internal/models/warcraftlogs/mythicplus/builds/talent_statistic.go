@@ -19,6 +19,7 @@ type TalentStatistic struct {
 
 	// Encounter information
 	EncounterID uint `gorm:"index"`
+	Difficulty  uint `gorm:"not null;default:0"` // 0 for Mythic+, raid difficulty for the raid bosses
 
 	// Talent import
 	TalentImport string `gorm:"type:text;index"`
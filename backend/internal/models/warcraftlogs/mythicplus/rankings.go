@@ -43,6 +43,9 @@ type PlayerRanking struct {
 	Score       float64   `json:"score"`
 	Leaderboard int       `json:"leaderboard"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Identity of the player across renames and transfers, linked from the player aliases
+	PlayerIdentityID *uint `json:"player_identity_id" gorm:"index"`
 }
 
 type RankingsUpdateState struct {
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotRetention is the time the snapshots are kept before being pruned, the final snapshots of the seasons are kept
+const SnapshotRetention = 90 * 24 * time.Hour
+
+// PlayerRankingsSnapshot represents a version of the player rankings, written by a rankings update
+// The rankings of the snapshot are stored in player_rankings_history.
+type PlayerRankingsSnapshot struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time       `json:"-"`
+	UpdatedAt    time.Time       `json:"-"`
+	DeletedAt    *gorm.DeletedAt `json:"-" gorm:"index"`
+	SeasonID     *uint           `json:"season_id" gorm:"index"`
+	SeasonSlug   string          `json:"season_slug" gorm:"->;-:migration"`
+	CapturedAt   time.Time       `json:"captured_at" gorm:"not null"`
+	Source       string          `json:"source" gorm:"not null"`
+	RankingCount int             `json:"ranking_count"`
+	DungeonCount int             `json:"dungeon_count"`
+	IsFinal      bool            `json:"is_final"`
+	ArchivedAt   *time.Time      `json:"archived_at,omitempty"`
+}
+
+func (PlayerRankingsSnapshot) TableName() string {
+	return "player_rankings_snapshots"
+}
+
+// CreateRankingsSnapshot copies the current player rankings into a new snapshot of the active season
+// It must run in the transaction replacing the rankings, so the snapshot matches the rankings served.
+// The player, guild and realm leaderboard entries of the snapshot are computed with it.
+// The last snapshot of the previous seasons is archived as their final standings and the expired snapshots are pruned.
+func CreateRankingsSnapshot(tx *gorm.DB, source string) (*PlayerRankingsSnapshot, error) {
+	var snapshot PlayerRankingsSnapshot
+	if err := tx.Raw(`
+		INSERT INTO player_rankings_snapshots (created_at, updated_at, season_id, captured_at, source, ranking_count, dungeon_count)
+		SELECT NOW(), NOW(),
+			(
+				SELECT season_id
+				FROM active_season_dungeons
+				GROUP BY season_id
+				ORDER BY COUNT(DISTINCT region) DESC, season_id DESC
+				LIMIT 1
+			),
+			NOW(), ?,
+			(SELECT COUNT(*) FROM player_rankings WHERE deleted_at IS NULL),
+			active_season_dungeon_count()
+		RETURNING *`, source).Scan(&snapshot).Error; err != nil {
+		return nil, fmt.Errorf("failed to create rankings snapshot: %w", err)
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO player_rankings_history (
+			snapshot_id, dungeon_id, name, class, spec, role,
+			amount, hard_mode_level, duration, start_time, report_code,
+			report_fight_id, report_start_time, guild_id, guild_name,
+			guild_faction, server_id, server_name, server_region,
+			bracket_data, faction, affixes, medal, score, leaderboard, player_identity_id
+		)
+		SELECT
+			?, dungeon_id, name, class, spec, role,
+			amount, hard_mode_level, duration, start_time, report_code,
+			report_fight_id, report_start_time, guild_id, guild_name,
+			guild_faction, server_id, server_name, server_region,
+			bracket_data, faction, affixes, medal, score, leaderboard, player_identity_id
+		FROM player_rankings
+		WHERE deleted_at IS NULL`, snapshot.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to copy rankings into snapshot %d: %w", snapshot.ID, err)
+	}
+
+	// Leaderboard entries of the snapshot, for the paginated leaderboards and the guild and realm leaderboards
+	if err := tx.Exec("SELECT populate_player_leaderboard_entries(?, ?)", snapshot.ID, snapshot.DungeonCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute leaderboard entries of snapshot %d: %w", snapshot.ID, err)
+	}
+	if err := tx.Exec("SELECT populate_group_leaderboard_entries(?)", snapshot.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute guild and realm leaderboards of snapshot %d: %w", snapshot.ID, err)
+	}
+
+	// The last snapshot of a season without final standings is archived once another season is captured
+	if snapshot.SeasonID != nil {
+		if err := tx.Exec(`
+			UPDATE player_rankings_snapshots
+			SET is_final = TRUE, archived_at = NOW(), updated_at = NOW()
+			WHERE id IN (
+				SELECT MAX(id)
+				FROM player_rankings_snapshots
+				WHERE season_id IS NOT NULL
+					AND season_id <> ?
+					AND deleted_at IS NULL
+				GROUP BY season_id
+				HAVING NOT BOOL_OR(is_final)
+			)`, *snapshot.SeasonID).Error; err != nil {
+			return nil, fmt.Errorf("failed to archive previous seasons: %w", err)
+		}
+	}
+
+	if err := tx.Exec(`
+		DELETE FROM player_rankings_snapshots
+		WHERE NOT is_final AND captured_at < ?`, time.Now().Add(-SnapshotRetention)).Error; err != nil {
+		return nil, fmt.Errorf("failed to prune expired rankings snapshots: %w", err)
+	}
+
+	return &snapshot, nil
+}
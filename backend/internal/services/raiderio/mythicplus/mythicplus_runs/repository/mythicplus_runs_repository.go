@@ -40,6 +40,7 @@ func (r *MythicPlusRunsRepository) ProcessRuns(runs []*models.Run, batchID strin
 
 		// Collections pour batch processing
 		var validRuns []*models.MythicPlusRuns
+		var validRosters [][]models.RosterMember
 		teamCompositionCache := make(map[string]*models.MythicPlusTeamComposition)
 
 		// 1. Prépare toutes les données en mémoire d'abord
@@ -83,6 +84,7 @@ func (r *MythicPlusRunsRepository) ProcessRuns(runs []*models.Run, batchID strin
 			}
 
 			validRuns = append(validRuns, dbRun)
+			validRosters = append(validRosters, run.Roster)
 		}
 
 		// 2. Batch insert/update des runs avec stats détaillées
@@ -93,6 +95,11 @@ func (r *MythicPlusRunsRepository) ProcessRuns(runs []*models.Run, batchID strin
 			}
 			stats.NewRuns = newRuns
 			stats.UpdatedRuns = updatedRuns
+
+			// 3. Stocke les personnages des runs pour la recherche de joueurs
+			if err := r.replaceRunCharacters(tx, validRuns, validRosters); err != nil {
+				return fmt.Errorf("failed to store run characters: %w", err)
+			}
 		}
 
 		return nil
@@ -126,6 +133,7 @@ func (r *MythicPlusRunsRepository) batchUpsertRuns(tx *gorm.DB, runs []*models.M
 				newRuns++
 			} else if result.Error == nil {
 				// Run existe, on la met à jour avec les nouvelles données
+				run.ID = existingRun.ID
 				if err := tx.Model(&existingRun).
 					Updates(map[string]interface{}{
 						"score":               run.Score,
@@ -155,6 +163,39 @@ func (r *MythicPlusRunsRepository) batchUpsertRuns(tx *gorm.DB, runs []*models.M
 	return newRuns, updatedRuns, nil
 }
 
+// replaceRunCharacters remplace les personnages du roster des runs
+// runs et rosters sont alignés, les runs doivent avoir leur ID après l'upsert
+func (r *MythicPlusRunsRepository) replaceRunCharacters(tx *gorm.DB, runs []*models.MythicPlusRuns, rosters [][]models.RosterMember) error {
+	runIDs := make([]uint, 0, len(runs))
+	var characters []models.MythicPlusRunCharacter
+	for i, run := range runs {
+		runIDs = append(runIDs, run.ID)
+		for _, member := range rosters[i] {
+			characters = append(characters, models.MythicPlusRunCharacter{
+				RunID:       run.ID,
+				CharacterID: member.Character.ID,
+				Name:        member.Character.Name,
+				RealmName:   member.Character.Realm.Name,
+				RealmSlug:   member.Character.Realm.Slug,
+				Region:      strings.ToUpper(member.Character.Region.Slug),
+				ClassName:   member.Character.Class.Name,
+				SpecName:    member.Character.Spec.Name,
+				Role:        member.Role,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			})
+		}
+	}
+
+	if err := tx.Unscoped().Where("run_id IN ?", runIDs).Delete(&models.MythicPlusRunCharacter{}).Error; err != nil {
+		return fmt.Errorf("failed to delete run characters: %w", err)
+	}
+	if len(characters) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(characters, 100).Error
+}
+
 // getOrCreateTeamCompositionCached fait une mise en cache pour éviter les requêtes répétées
 func (r *MythicPlusRunsRepository) getOrCreateTeamCompositionCached(
 	tx *gorm.DB,
@@ -106,6 +106,27 @@ func TestMythicPlusRunsRepository(t *testing.T) {
 		assert.Equal(t, runsWithSameComp[0].TeamCompositionID, runsWithSameComp[1].TeamCompositionID)
 	})
 
+	t.Run("ProcessRuns - Run characters are replaced on update", func(t *testing.T) {
+		db := setupTestDB(t) // DB fraîche
+		repo := NewMythicPlusRunsRepository(db)
+
+		run := createTestRunWithUniqueComposition(444444, "Demon Hunter", "Vengeance", "Priest", "Discipline")
+		_, err := repo.ProcessRuns([]*models.Run{run}, "test-batch-characters")
+		require.NoError(t, err)
+
+		// La même run traitée à nouveau ne duplique pas ses personnages
+		_, err = repo.ProcessRuns([]*models.Run{run}, "test-batch-characters-update")
+		require.NoError(t, err)
+
+		var characters []models.MythicPlusRunCharacter
+		err = db.Order("id").Find(&characters).Error
+		require.NoError(t, err)
+		assert.Len(t, characters, 5)
+		assert.Equal(t, "EU", characters[0].Region)
+		assert.Equal(t, "tank", characters[0].Role)
+		assert.Equal(t, "Vengeance", characters[0].SpecName)
+	})
+
 	t.Run("ProcessRuns - Invalid runs are skipped", func(t *testing.T) {
 		db := setupTestDB(t) // DB fraîche
 		repo := NewMythicPlusRunsRepository(db)
@@ -347,6 +368,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.MythicPlusRuns{},
 		&models.MythicPlusTeamComposition{},
 		&models.MythicPlusRunRoster{},
+		&models.MythicPlusRunCharacter{},
 	)
 	require.NoError(t, err)
 
@@ -0,0 +1,439 @@
+package warcraftlogsAuth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+
+	"wowperf/internal/models"
+	"wowperf/internal/services/warcraftlogs"
+)
+
+const (
+	// Redis key prefix of the OAuth states
+	oauthStatePrefix = "warcraftlogs_oauth_state:"
+	// Validity of an OAuth state
+	oauthStateTTL = 15 * time.Minute
+)
+
+// GraphQL query to fetch the profile and the guilds of the current user
+const currentUserQuery = `query {
+    userData {
+        currentUser {
+            id
+            name
+            guilds {
+                id
+                name
+                server {
+                    slug
+                    region {
+                        slug
+                    }
+                }
+            }
+        }
+    }
+}`
+
+// GraphQL query to fetch the reports of a user or a guild, including the private ones
+const userReportsQuery = `query($userID: Int, $guildID: Int, $limit: Int, $page: Int) {
+    reportData {
+        reports(userID: $userID, guildID: $guildID, limit: $limit, page: $page) {
+            data {
+                code
+                title
+                startTime
+                endTime
+                visibility
+                zone {
+                    id
+                    name
+                }
+                guild {
+                    id
+                    name
+                }
+                owner {
+                    name
+                }
+            }
+        }
+    }
+}`
+
+// oauthState represents the state of a WarcraftLogs OAuth request
+type oauthState struct {
+	State     string    `json:"state"`
+	UserID    uint      `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WarcraftLogsAuthService handles the WarcraftLogs OAuth2 authorization code flow
+// The linked account gives access to the private reports of the user and of their guilds.
+type WarcraftLogsAuthService struct {
+	db          *gorm.DB
+	oauthConfig *oauth2.Config
+	redisClient *redis.Client
+}
+
+// NewWarcraftLogsAuthService creates a new WarcraftLogs authentication service
+func NewWarcraftLogsAuthService(db *gorm.DB, redisClient *redis.Client) *WarcraftLogsAuthService {
+	config := &oauth2.Config{
+		ClientID:     os.Getenv("WARCRAFTLOGS_CLIENT_ID"),
+		ClientSecret: os.Getenv("WARCRAFTLOGS_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("WARCRAFTLOGS_REDIRECT_URL"),
+		Scopes:       RequiredScopes,
+		Endpoint:     warcraftlogs.Endpoint,
+	}
+
+	return &WarcraftLogsAuthService{
+		db:          db,
+		oauthConfig: config,
+		redisClient: redisClient,
+	}
+}
+
+// InitiateAuth starts the OAuth2 flow by generating the authorization URL
+func (s *WarcraftLogsAuthService) InitiateAuth(ctx context.Context, userID uint) (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate random state: %w", err)
+	}
+
+	state := &oauthState{
+		State:     base64.URLEncoding.EncodeToString(bytes)[:32],
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(oauthStateTTL),
+	}
+
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal OAuth state: %w", err)
+	}
+
+	if err := s.redisClient.Set(ctx, oauthStatePrefix+state.State, stateJSON, oauthStateTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store OAuth state: %w", err)
+	}
+
+	return s.oauthConfig.AuthCodeURL(state.State), nil
+}
+
+// ExchangeCodeForToken validates the state and exchanges the authorization code for a token
+// Returns the token and the ID of the user who initiated the flow.
+func (s *WarcraftLogsAuthService) ExchangeCodeForToken(ctx context.Context, code, stateParam string) (*oauth2.Token, uint, error) {
+	redisKey := oauthStatePrefix + stateParam
+	stateJSON, err := s.redisClient.Get(ctx, redisKey).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	// Delete state immediately to prevent replay attacks
+	s.redisClient.Del(ctx, redisKey)
+
+	var state oauthState
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	if time.Now().After(state.ExpiresAt) {
+		return nil, 0, fmt.Errorf("%w: state has expired", ErrInvalidState)
+	}
+
+	token, err := s.oauthConfig.Exchange(ctx, code)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+
+	return token, state.UserID, nil
+}
+
+// GetCurrentUser retrieves the WarcraftLogs profile and guilds of the token owner
+func (s *WarcraftLogsAuthService) GetCurrentUser(ctx context.Context, client *warcraftlogs.Client) (*WarcraftLogsUser, error) {
+	response, err := client.MakeGraphQLRequest(currentUserQuery, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warcraftlogs user: %w", err)
+	}
+
+	var result struct {
+		UserData struct {
+			CurrentUser struct {
+				ID     int    `json:"id"`
+				Name   string `json:"name"`
+				Guilds []struct {
+					ID     int    `json:"id"`
+					Name   string `json:"name"`
+					Server struct {
+						Slug   string `json:"slug"`
+						Region struct {
+							Slug string `json:"slug"`
+						} `json:"region"`
+					} `json:"server"`
+				} `json:"guilds"`
+			} `json:"currentUser"`
+		} `json:"userData"`
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse warcraftlogs user: %w", err)
+	}
+
+	currentUser := result.UserData.CurrentUser
+	user := &WarcraftLogsUser{
+		ID:     currentUser.ID,
+		Name:   currentUser.Name,
+		Guilds: make([]WarcraftLogsGuild, 0, len(currentUser.Guilds)),
+	}
+	for _, guild := range currentUser.Guilds {
+		user.Guilds = append(user.Guilds, WarcraftLogsGuild{
+			ID:     guild.ID,
+			Name:   guild.Name,
+			Server: guild.Server.Slug,
+			Region: guild.Server.Region.Slug,
+		})
+	}
+
+	return user, nil
+}
+
+// LinkUserAccount links a WarcraftLogs account to a user account and stores the encrypted tokens
+func (s *WarcraftLogsAuthService) LinkUserAccount(ctx context.Context, token *oauth2.Token, userID uint) (*WarcraftLogsUser, error) {
+	client, err := warcraftlogs.NewUserClient(s.oauthConfig, token)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.GetCurrentUser(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := user.SetWarcraftLogsTokens(token.AccessToken, token.RefreshToken, token.Expiry); err != nil {
+		return nil, fmt.Errorf("failed to encrypt tokens: %w", err)
+	}
+
+	warcraftLogsID := fmt.Sprintf("%d", profile.ID)
+	user.WarcraftLogsID = &warcraftLogsID
+	user.WarcraftLogsName = &profile.Name
+
+	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	log.Printf("WarcraftLogs account %s linked to user %d", profile.Name, userID)
+	return profile, nil
+}
+
+// UnlinkUserAccount removes the WarcraftLogs account link from a user
+func (s *WarcraftLogsAuthService) UnlinkUserAccount(ctx context.Context, userID uint) error {
+	updates := map[string]interface{}{
+		"warcraft_logs_id":                      nil,
+		"warcraft_logs_name":                    nil,
+		"encrypted_warcraft_logs_access_token":  nil,
+		"encrypted_warcraft_logs_refresh_token": nil,
+		"warcraft_logs_expires_at":              nil,
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to unlink warcraftlogs account: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserWarcraftLogsStatus returns the current WarcraftLogs link status for a user
+func (s *WarcraftLogsAuthService) GetUserWarcraftLogsStatus(ctx context.Context, userID uint) (*WarcraftLogsStatus, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	status := &WarcraftLogsStatus{
+		Linked: user.IsWarcraftLogsLinked(),
+	}
+	if user.WarcraftLogsName != nil {
+		status.Name = *user.WarcraftLogsName
+	}
+
+	return status, nil
+}
+
+// GetUserClient returns a WarcraftLogs client acting on behalf of a user
+// Call SaveUserToken after the requests to persist a refreshed token.
+func (s *WarcraftLogsAuthService) GetUserClient(ctx context.Context, userID uint) (*warcraftlogs.Client, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if !user.IsWarcraftLogsLinked() {
+		return nil, ErrAccountNotLinked
+	}
+
+	accessToken, err := user.GetWarcraftLogsAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := user.GetWarcraftLogsRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+	}
+	if user.WarcraftLogsExpiresAt != nil {
+		token.Expiry = *user.WarcraftLogsExpiresAt
+	}
+
+	return warcraftlogs.NewUserClient(s.oauthConfig, token)
+}
+
+// SaveUserToken persists the token of a user client if it was refreshed
+func (s *WarcraftLogsAuthService) SaveUserToken(ctx context.Context, userID uint, client *warcraftlogs.Client) error {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	token := client.Token()
+	if user.WarcraftLogsExpiresAt != nil && !token.Expiry.After(*user.WarcraftLogsExpiresAt) {
+		return nil
+	}
+
+	if err := user.SetWarcraftLogsTokens(token.AccessToken, token.RefreshToken, token.Expiry); err != nil {
+		return fmt.Errorf("failed to encrypt tokens: %w", err)
+	}
+
+	updates := map[string]interface{}{
+		"encrypted_warcraft_logs_access_token":  user.EncryptedWarcraftLogsAccessToken,
+		"encrypted_warcraft_logs_refresh_token": user.EncryptedWarcraftLogsRefreshToken,
+		"warcraft_logs_expires_at":              user.WarcraftLogsExpiresAt,
+	}
+	if err := s.db.WithContext(ctx).Model(&user).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update warcraftlogs token: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserReports retrieves the most recent reports of the user and of their guilds, including the private ones
+// Reports uploaded to a guild by the user are only returned once.
+func (s *WarcraftLogsAuthService) GetUserReports(ctx context.Context, userID uint, limit int) ([]UserReport, error) {
+	client, err := s.GetUserClient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := s.SaveUserToken(ctx, userID, client); err != nil {
+			log.Printf("[WARN] Failed to save refreshed warcraftlogs token for user %d: %v", userID, err)
+		}
+	}()
+
+	profile, err := s.GetCurrentUser(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Own reports first, then the reports of each guild
+	filters := []map[string]interface{}{{"userID": profile.ID}}
+	for _, guild := range profile.Guilds {
+		filters = append(filters, map[string]interface{}{"guildID": guild.ID})
+	}
+
+	reports := make([]UserReport, 0)
+	seen := make(map[string]bool)
+	for _, filter := range filters {
+		filter["limit"] = limit
+		filter["page"] = 1
+
+		pageReports, err := s.fetchReports(client, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, report := range pageReports {
+			if seen[report.Code] {
+				continue
+			}
+			seen[report.Code] = true
+			reports = append(reports, report)
+		}
+	}
+
+	return reports, nil
+}
+
+// fetchReports fetches a page of reports from the user API
+func (s *WarcraftLogsAuthService) fetchReports(client *warcraftlogs.Client, variables map[string]interface{}) ([]UserReport, error) {
+	response, err := client.MakeGraphQLRequest(userReportsQuery, variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warcraftlogs reports: %w", err)
+	}
+
+	var result struct {
+		ReportData struct {
+			Reports struct {
+				Data []struct {
+					Code       string `json:"code"`
+					Title      string `json:"title"`
+					StartTime  int64  `json:"startTime"`
+					EndTime    int64  `json:"endTime"`
+					Visibility string `json:"visibility"`
+					Zone       *struct {
+						ID   int    `json:"id"`
+						Name string `json:"name"`
+					} `json:"zone"`
+					Guild *struct {
+						ID   int    `json:"id"`
+						Name string `json:"name"`
+					} `json:"guild"`
+					Owner struct {
+						Name string `json:"name"`
+					} `json:"owner"`
+				} `json:"data"`
+			} `json:"reports"`
+		} `json:"reportData"`
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse warcraftlogs reports: %w", err)
+	}
+
+	reports := make([]UserReport, 0, len(result.ReportData.Reports.Data))
+	for _, data := range result.ReportData.Reports.Data {
+		report := UserReport{
+			Code:       data.Code,
+			Title:      data.Title,
+			StartTime:  data.StartTime,
+			EndTime:    data.EndTime,
+			Visibility: data.Visibility,
+			OwnerName:  data.Owner.Name,
+		}
+		if data.Zone != nil {
+			report.ZoneID = data.Zone.ID
+			report.ZoneName = data.Zone.Name
+		}
+		if data.Guild != nil {
+			report.GuildID = data.Guild.ID
+			report.GuildName = data.Guild.Name
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,47 @@
+package warcraftlogsAuth
+
+import "errors"
+
+var (
+	// Auth errors
+	ErrAccountNotLinked = errors.New("warcraftlogs account not linked")
+	ErrInvalidState     = errors.New("invalid warcraftlogs oauth state")
+)
+
+// RequiredScopes are the scopes requested to read the profile and the private reports of the user
+var RequiredScopes = []string{"view-user-profile", "view-private-reports"}
+
+// WarcraftLogsStatus represents the current status of a WarcraftLogs account link
+type WarcraftLogsStatus struct {
+	Linked bool   `json:"linked"`
+	Name   string `json:"name,omitempty"`
+}
+
+// WarcraftLogsGuild represents a guild the WarcraftLogs user belongs to
+type WarcraftLogsGuild struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Server string `json:"server"`
+	Region string `json:"region"`
+}
+
+// WarcraftLogsUser represents the profile of the WarcraftLogs user
+type WarcraftLogsUser struct {
+	ID     int                 `json:"id"`
+	Name   string              `json:"name"`
+	Guilds []WarcraftLogsGuild `json:"guilds"`
+}
+
+// UserReport represents a report the user has access to, including the private ones
+type UserReport struct {
+	Code       string `json:"code"`
+	Title      string `json:"title"`
+	StartTime  int64  `json:"start_time"`
+	EndTime    int64  `json:"end_time"`
+	Visibility string `json:"visibility"`
+	ZoneID     int    `json:"zone_id"`
+	ZoneName   string `json:"zone_name"`
+	GuildID    int    `json:"guild_id,omitempty"`
+	GuildName  string `json:"guild_name,omitempty"`
+	OwnerName  string `json:"owner_name"`
+}
@@ -0,0 +1,265 @@
+// package warcraftlogs/batcher.go
+package warcraftlogs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	warcraftlogsTypes "wowperf/internal/services/warcraftlogs/types"
+)
+
+// DefaultBatchComplexityBudget is the complexity budget of a batched document when none is given
+const DefaultBatchComplexityBudget = 10
+
+// BatchQuery is a lookup combined with other lookups in a single aliased GraphQL document
+// Root is the root field of the lookup (e.g. "reportData") and Selection its selection set.
+// Lookups cannot use variables, their arguments are inlined (see GraphQLString).
+type BatchQuery struct {
+	Root       string
+	Selection  string
+	Complexity int // Estimated cost of the lookup, 1 when not set
+}
+
+// BatchResult is the response of a batched lookup
+// Data has the shape of the response of the lookup sent alone, so the usual parsers can read it.
+type BatchResult struct {
+	Data json.RawMessage
+	Err  error
+}
+
+// BatchRequestFunc sends a batched document and returns the response with its partial errors
+type BatchRequestFunc func(ctx context.Context, query string) (*GraphQLResponse, error)
+
+// QueryBatcher combines lookups into aliased GraphQL documents and splits the responses back
+// The lookups of a document never exceed the complexity budget, a lookup over budget is sent alone.
+type QueryBatcher struct {
+	request  BatchRequestFunc
+	budget   int
+	progress func(sent, total int)
+}
+
+// NewQueryBatcher creates a new instance of QueryBatcher
+func NewQueryBatcher(request BatchRequestFunc, budget int) *QueryBatcher {
+	if budget <= 0 {
+		budget = DefaultBatchComplexityBudget
+	}
+	return &QueryBatcher{
+		request: request,
+		budget:  budget,
+	}
+}
+
+// WithProgress sets a function called after each document with the number of documents sent
+// It lets long activities record a heartbeat per document.
+func (b *QueryBatcher) WithProgress(progress func(sent, total int)) *QueryBatcher {
+	b.progress = progress
+	return b
+}
+
+// Execute runs the lookups and returns their results in the same order
+// A rate limit error stops the batching, the remaining lookups get the same error.
+func (b *QueryBatcher) Execute(ctx context.Context, queries []BatchQuery) []BatchResult {
+	results := make([]BatchResult, len(queries))
+
+	batches := b.Plan(queries)
+	for i, batch := range batches {
+		document, aliases := buildBatchDocument(queries, batch)
+
+		response, err := b.request(ctx, document)
+		if b.progress != nil {
+			b.progress(i+1, len(batches))
+		}
+		if err != nil {
+			for _, index := range batch {
+				results[index].Err = err
+			}
+			if IsRateLimitOrQuota(err) || ctx.Err() != nil {
+				markRemaining(results, err)
+				return results
+			}
+			continue
+		}
+
+		splitBatchResponse(response, queries, batch, aliases, results)
+	}
+
+	return results
+}
+
+// Plan groups the lookups into documents within the complexity budget, keeping their order
+func (b *QueryBatcher) Plan(queries []BatchQuery) [][]int {
+	var batches [][]int
+	var current []int
+	cost := 0
+
+	for i, query := range queries {
+		complexity := max(query.Complexity, 1)
+		if len(current) > 0 && cost+complexity > b.budget {
+			batches = append(batches, current)
+			current = nil
+			cost = 0
+		}
+		current = append(current, i)
+		cost += complexity
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+// buildBatchDocument builds the aliased document of a batch
+func buildBatchDocument(queries []BatchQuery, batch []int) (string, []string) {
+	aliases := make([]string, len(batch))
+
+	var document strings.Builder
+	document.WriteString("query {\n")
+	for i, index := range batch {
+		aliases[i] = fmt.Sprintf("q%d", i)
+		fmt.Fprintf(&document, "%s: %s {\n%s\n}\n", aliases[i], queries[index].Root, queries[index].Selection)
+	}
+	document.WriteString("}")
+
+	return document.String(), aliases
+}
+
+// splitBatchResponse dispatches the aliased data and errors of a response to the lookups of the batch
+func splitBatchResponse(response *GraphQLResponse, queries []BatchQuery, batch []int, aliases []string, results []BatchResult) {
+	var data map[string]json.RawMessage
+	if len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, &data); err != nil {
+			for _, index := range batch {
+				results[index].Err = fmt.Errorf("failed to parse batched response: %w", err)
+			}
+			return
+		}
+	}
+
+	// Errors are attached to their lookup by the first element of their path
+	aliasErrors := make(map[string]string)
+	documentError := ""
+	for _, gqlErr := range response.Errors {
+		alias := ""
+		if len(gqlErr.Path) > 0 {
+			alias, _ = gqlErr.Path[0].(string)
+		}
+		if alias == "" {
+			if documentError == "" {
+				documentError = gqlErr.Message
+			}
+			continue
+		}
+		if _, exists := aliasErrors[alias]; !exists {
+			aliasErrors[alias] = gqlErr.Message
+		}
+	}
+
+	for i, index := range batch {
+		alias := aliases[i]
+		if message, exists := aliasErrors[alias]; exists {
+			results[index].Err = fmt.Errorf("GraphQL error: %s", message)
+			continue
+		}
+
+		value, exists := data[alias]
+		if !exists || string(value) == "null" {
+			if documentError != "" {
+				results[index].Err = fmt.Errorf("GraphQL error: %s", documentError)
+			} else {
+				results[index].Err = fmt.Errorf("no data returned for batched %s lookup", queries[index].Root)
+			}
+			continue
+		}
+
+		wrapped, err := json.Marshal(map[string]json.RawMessage{queries[index].Root: value})
+		if err != nil {
+			results[index].Err = fmt.Errorf("failed to split batched response: %w", err)
+			continue
+		}
+		results[index].Data = wrapped
+	}
+}
+
+// markRemaining sets an error on the lookups that have not been sent
+func markRemaining(results []BatchResult, err error) {
+	for i := range results {
+		if results[i].Data == nil && results[i].Err == nil {
+			results[i].Err = err
+		}
+	}
+}
+
+// IsRateLimitOrQuota reports whether an error is a WarcraftLogs rate limit or quota error
+// No more request should be sent once it is returned.
+func IsRateLimitOrQuota(err error) bool {
+	var wlErr *warcraftlogsTypes.WarcraftLogsError
+	if errors.As(err, &wlErr) {
+		return wlErr.Type == warcraftlogsTypes.ErrorTypeRateLimit || wlErr.Type == warcraftlogsTypes.ErrorTypeQuotaExceeded
+	}
+	return false
+}
+
+// BatchQueryFromDocument converts a document without variables and with a single root field into a lookup
+// Example: `query { reportData { report(code: "abc") { title } } }` -> Root "reportData", Selection `report(code: "abc") { title }`
+func BatchQueryFromDocument(document string, complexity int) (BatchQuery, error) {
+	document = strings.TrimSpace(document)
+
+	open := strings.Index(document, "{")
+	if open < 0 || strings.ContainsAny(document[:open], "($") {
+		return BatchQuery{}, fmt.Errorf("unsupported document for batching")
+	}
+	closing := matchingBrace(document, open)
+	if closing != len(document)-1 {
+		return BatchQuery{}, fmt.Errorf("unsupported document for batching")
+	}
+	body := strings.TrimSpace(document[open+1 : closing])
+
+	rootOpen := strings.Index(body, "{")
+	if rootOpen < 0 || matchingBrace(body, rootOpen) != len(body)-1 {
+		return BatchQuery{}, fmt.Errorf("document must have a single root field to be batched")
+	}
+	root := strings.TrimSpace(body[:rootOpen])
+	if root == "" || strings.ContainsAny(root, " \t\n(:") {
+		return BatchQuery{}, fmt.Errorf("unsupported root field for batching: %q", root)
+	}
+
+	return BatchQuery{
+		Root:       root,
+		Selection:  strings.TrimSpace(body[rootOpen+1 : len(body)-1]),
+		Complexity: complexity,
+	}, nil
+}
+
+// matchingBrace returns the index of the brace closing the one at open, -1 if there is none
+// Braces inside string literals are ignored.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	inString := false
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// GraphQLString returns a string literal to inline an argument in a batched lookup
+func GraphQLString(value string) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
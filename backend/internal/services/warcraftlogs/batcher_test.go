@@ -0,0 +1,98 @@
+package warcraftlogs
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	warcraftlogsTypes "wowperf/internal/services/warcraftlogs/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBatcherPlan(t *testing.T) {
+	batcher := NewQueryBatcher(nil, 5)
+
+	batches := batcher.Plan([]BatchQuery{
+		{Root: "reportData", Complexity: 2},
+		{Root: "reportData", Complexity: 2},
+		{Root: "reportData", Complexity: 2},
+		{Root: "reportData", Complexity: 8}, // Over budget, sent alone
+		{Root: "reportData"},                // Counts as 1
+	})
+
+	assert.Equal(t, [][]int{{0, 1}, {2}, {3}, {4}}, batches)
+}
+
+func TestQueryBatcherExecute(t *testing.T) {
+	var documents []string
+	batcher := NewQueryBatcher(func(ctx context.Context, query string) (*GraphQLResponse, error) {
+		documents = append(documents, query)
+		return &GraphQLResponse{
+			Data: json.RawMessage(`{"q0": {"report": {"title": "first"}}, "q1": null}`),
+			Errors: []GraphQLError{
+				{Message: "report not found", Path: []interface{}{"q1", "report"}},
+			},
+		}, nil
+	}, 10)
+
+	results := batcher.Execute(context.Background(), []BatchQuery{
+		{Root: "reportData", Selection: `report(code: "abc") { title }`},
+		{Root: "reportData", Selection: `report(code: "missing") { title }`},
+	})
+
+	require.Len(t, documents, 1)
+	assert.Contains(t, documents[0], `q0: reportData {`)
+	assert.Contains(t, documents[0], `q1: reportData {`)
+
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	assert.JSONEq(t, `{"reportData": {"report": {"title": "first"}}}`, string(results[0].Data))
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), "report not found")
+}
+
+func TestQueryBatcherExecuteStopsOnRateLimit(t *testing.T) {
+	calls := 0
+	batcher := NewQueryBatcher(func(ctx context.Context, query string) (*GraphQLResponse, error) {
+		calls++
+		return nil, warcraftlogsTypes.NewRateLimitError(nil, nil)
+	}, 1)
+	var progress [][2]int
+	batcher.WithProgress(func(sent, total int) {
+		progress = append(progress, [2]int{sent, total})
+	})
+
+	results := batcher.Execute(context.Background(), []BatchQuery{
+		{Root: "worldData", Selection: "a"},
+		{Root: "worldData", Selection: "b"},
+		{Root: "worldData", Selection: "c"},
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, [][2]int{{1, 3}}, progress)
+	for _, result := range results {
+		assert.True(t, IsRateLimitOrQuota(result.Err))
+	}
+}
+
+func TestBatchQueryFromDocument(t *testing.T) {
+	query, err := BatchQueryFromDocument(`
+	query {
+		reportData {
+			report(code: "a{b}c") { title }
+		}
+	}`, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "reportData", query.Root)
+	assert.True(t, strings.HasPrefix(query.Selection, `report(code: "a{b}c")`))
+	assert.Equal(t, 3, query.Complexity)
+
+	_, err = BatchQueryFromDocument(`query get($code: String!) { reportData { report(code: $code) { title } } }`, 1)
+	assert.Error(t, err)
+
+	_, err = BatchQueryFromDocument(`query { reportData { title } worldData { name } }`, 1)
+	assert.Error(t, err)
+}
@@ -21,14 +21,22 @@ import (
 
 const (
 	authURL      = "https://www.warcraftlogs.com/oauth/token"
+	authorizeURL = "https://www.warcraftlogs.com/oauth/authorize"
 	clientAPIURL = "https://www.warcraftlogs.com/api/v2/client"
 	userAPIURL   = "https://www.warcraftlogs.com/api/v2/user"
 )
 
 type Client struct {
-	httpClient *http.Client
-	token      *oauth2.Token
-	isPublic   bool
+	httpClient  *http.Client
+	token       *oauth2.Token
+	tokenSource oauth2.TokenSource // Only set for user clients, refreshes the user token
+	isPublic    bool
+}
+
+// Endpoint is the OAuth2 endpoint of WarcraftLogs for the authorization code flow
+var Endpoint = oauth2.Endpoint{
+	AuthURL:  authorizeURL,
+	TokenURL: authURL,
 }
 
 // GraphQLResponse is the response from the Warcraft Logs API
@@ -40,7 +48,7 @@ type GraphQLResponse struct {
 // GraphQLError is the error from the Warcraft Logs API
 type GraphQLError struct {
 	Message    string          `json:"message"`
-	Path       []string        `json:"path,omitempty"`
+	Path       []interface{}   `json:"path,omitempty"` // Field names and list indexes
 	Extensions json.RawMessage `json:"extensions,omitempty"`
 }
 
@@ -77,6 +85,49 @@ func NewClient() (*Client, error) {
 	return client, nil
 }
 
+// NewUserClient creates a new Warcraft Logs API client acting on behalf of a user
+// It uses the user API so the private reports of the user and of their guilds are accessible.
+// The token is refreshed with the refresh token when it expires, use Token to persist the refreshed token.
+func NewUserClient(config *oauth2.Config, token *oauth2.Token) (*Client, error) {
+	if token == nil || token.AccessToken == "" {
+		return nil, &warcraftlogsTypes.WarcraftLogsError{
+			Type:      warcraftlogsTypes.ErrorTypeValidation,
+			Message:   "missing user token",
+			Retryable: false,
+		}
+	}
+
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: time.Second * 60,
+		},
+		token:       token,
+		tokenSource: config.TokenSource(context.Background(), token),
+		isPublic:    false,
+	}, nil
+}
+
+// Token returns the current OAuth token of the client
+func (c *Client) Token() *oauth2.Token {
+	return c.token
+}
+
+// refreshUserToken refreshes the token of a user client
+func (c *Client) refreshUserToken() error {
+	token, err := c.tokenSource.Token()
+	if err != nil {
+		return &warcraftlogsTypes.WarcraftLogsError{
+			Type:      warcraftlogsTypes.ErrorTypeAPI,
+			Message:   "failed to refresh user OAuth token",
+			Cause:     err,
+			Retryable: false,
+		}
+	}
+
+	c.token = token
+	return nil
+}
+
 // refreshToken obtains or refreshes the OAuth token
 func (c *Client) refreshToken(config *clientcredentials.Config) error {
 	log.Println("[DEBUG] Refreshing WarcraftLogs token...")
@@ -100,13 +151,49 @@ func (c *Client) refreshToken(config *clientcredentials.Config) error {
 
 // MakeGraphQLRequest makes a GraphQL request to the Warcraft Logs API
 func (c *Client) MakeGraphQLRequest(query string, variables map[string]interface{}) ([]byte, error) {
-	if c.token.Expiry.Before(time.Now()) {
+	graphQLResp, statusCode, err := c.doGraphQLRequest(query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(graphQLResp.Errors) > 0 {
+		return nil, warcraftlogsTypes.NewAPIError(statusCode, fmt.Errorf(graphQLResp.Errors[0].Message))
+	}
+
+	return graphQLResp.Data, nil
+}
+
+// MakeGraphQLBatchRequest makes a GraphQL request whose errors can be partial
+// Used for batched documents: the errors of a lookup are returned with the data of the other lookups.
+func (c *Client) MakeGraphQLBatchRequest(query string) (*GraphQLResponse, error) {
+	graphQLResp, statusCode, err := c.doGraphQLRequest(query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(graphQLResp.Errors) > 0 && (len(graphQLResp.Data) == 0 || string(graphQLResp.Data) == "null") {
+		return nil, warcraftlogsTypes.NewAPIError(statusCode, fmt.Errorf(graphQLResp.Errors[0].Message))
+	}
+
+	return graphQLResp, nil
+}
+
+// doGraphQLRequest sends a GraphQL request and returns the decoded response
+// Rate limit errors are returned as errors, the other GraphQL errors are left in the response.
+func (c *Client) doGraphQLRequest(query string, variables map[string]interface{}) (*GraphQLResponse, int, error) {
+	if !c.isPublic {
+		if !c.token.Valid() {
+			if err := c.refreshUserToken(); err != nil {
+				return nil, 0, fmt.Errorf("failed to refresh token: %w", err)
+			}
+		}
+	} else if c.token.Expiry.Before(time.Now()) {
 		if err := c.refreshToken(&clientcredentials.Config{
 			ClientID:     os.Getenv("WARCRAFTLOGS_CLIENT_ID"),
 			ClientSecret: os.Getenv("WARCRAFTLOGS_CLIENT_SECRET"),
 			TokenURL:     authURL,
 		}); err != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w", err)
+			return nil, 0, fmt.Errorf("failed to refresh token: %w", err)
 		}
 	}
 
@@ -122,12 +209,12 @@ func (c *Client) MakeGraphQLRequest(query string, variables map[string]interface
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.token.AccessToken)
@@ -135,7 +222,7 @@ func (c *Client) MakeGraphQLRequest(query string, variables map[string]interface
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, &warcraftlogsTypes.WarcraftLogsError{
+		return nil, 0, &warcraftlogsTypes.WarcraftLogsError{
 			Type:      warcraftlogsTypes.ErrorTypeNetwork,
 			Message:   fmt.Sprintf("failed to send request: %v", err),
 			Cause:     err,
@@ -146,36 +233,33 @@ func (c *Client) MakeGraphQLRequest(query string, variables map[string]interface
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode >= 500 {
-		return nil, warcraftlogsTypes.NewAPIError(resp.StatusCode, nil)
+		return nil, resp.StatusCode, warcraftlogsTypes.NewAPIError(resp.StatusCode, nil)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, warcraftlogsTypes.NewAPIError(resp.StatusCode, nil)
+		return nil, resp.StatusCode, warcraftlogsTypes.NewAPIError(resp.StatusCode, nil)
 	}
 
 	var graphQLResp GraphQLResponse
 	if err := json.Unmarshal(body, &graphQLResp); err != nil {
-		return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		return nil, resp.StatusCode, fmt.Errorf("failed to parse GraphQL response: %w", err)
 	}
 
-	if len(graphQLResp.Errors) > 0 {
-		for _, gqlErr := range graphQLResp.Errors {
-			if isRateLimitError(gqlErr) {
-				return nil, &warcraftlogsTypes.WarcraftLogsError{
-					Type:      warcraftlogsTypes.ErrorTypeRateLimit,
-					Message:   gqlErr.Message,
-					Retryable: true,
-				}
+	for _, gqlErr := range graphQLResp.Errors {
+		if isRateLimitError(gqlErr) {
+			return nil, resp.StatusCode, &warcraftlogsTypes.WarcraftLogsError{
+				Type:      warcraftlogsTypes.ErrorTypeRateLimit,
+				Message:   gqlErr.Message,
+				Retryable: true,
 			}
 		}
-		return nil, warcraftlogsTypes.NewAPIError(resp.StatusCode, fmt.Errorf(graphQLResp.Errors[0].Message))
 	}
 
-	return graphQLResp.Data, nil
+	return &graphQLResp, resp.StatusCode, nil
 }
 
 // isRateLimitError checks if a GraphQL error is a rate limit error
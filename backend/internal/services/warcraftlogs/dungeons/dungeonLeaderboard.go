@@ -14,18 +14,6 @@ const (
 	DefaultLimit = 100  // Default results if not specified
 )
 
-// validDungeonIDs contains all valid dungeon IDs
-var validDungeonIDs = map[int]bool{
-	DungeonAraKara:       true,
-	DungeonCityOfThreads: true,
-	DungeonGrimBatol:     true,
-	DungeonMists:         true,
-	DungeonSiege:         true,
-	DungeonDawnbreaker:   true,
-	DungeonNecroticWake:  true,
-	DungeonStonevault:    true,
-}
-
 // DungeonLeaderboardEntry represents a single entry in the dungeon leaderboard
 type DungeonLeaderboardEntry struct {
 	Name         string        `json:"name"`
@@ -144,7 +132,7 @@ func (s *DungeonLeaderboardService) GetDungeonLeaderboardByPlayer(
 	dungeonID int,
 	limit int,
 ) ([]DungeonLeaderboardEntry, error) {
-	if err := s.validateDungeonInput(dungeonID, "", "", "", limit); err != nil {
+	if err := s.validateDungeonInput(ctx, dungeonID, "", "", "", limit); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -165,7 +153,7 @@ func (s *DungeonLeaderboardService) GetDungeonLeaderboardByRole(
 	role string,
 	limit int,
 ) ([]DungeonLeaderboardEntry, error) {
-	if err := s.validateDungeonInput(dungeonID, role, "", "", limit); err != nil {
+	if err := s.validateDungeonInput(ctx, dungeonID, role, "", "", limit); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -186,7 +174,7 @@ func (s *DungeonLeaderboardService) GetDungeonLeaderboardByClass(
 	class string,
 	limit int,
 ) ([]DungeonLeaderboardEntry, error) {
-	if err := s.validateDungeonInput(dungeonID, "", class, "", limit); err != nil {
+	if err := s.validateDungeonInput(ctx, dungeonID, "", class, "", limit); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -208,7 +196,7 @@ func (s *DungeonLeaderboardService) GetDungeonLeaderboardBySpec(
 	spec string,
 	limit int,
 ) ([]DungeonLeaderboardEntry, error) {
-	if err := s.validateDungeonInput(dungeonID, "", class, spec, limit); err != nil {
+	if err := s.validateDungeonInput(ctx, dungeonID, "", class, spec, limit); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -223,9 +211,13 @@ func (s *DungeonLeaderboardService) GetDungeonLeaderboardBySpec(
 }
 
 // validateDungeonInput validates the input for the dungeon leaderboard
-func (s *DungeonLeaderboardService) validateDungeonInput(dungeonID int, role, class, spec string, limit int) error {
-	// Validate dungeon ID against whitelist
-	if !validDungeonIDs[dungeonID] {
+func (s *DungeonLeaderboardService) validateDungeonInput(ctx context.Context, dungeonID int, role, class, spec string, limit int) error {
+	// Validate dungeon ID against the dungeons of the active seasons
+	active, err := IsActiveDungeon(ctx, s.db, dungeonID)
+	if err != nil {
+		return err
+	}
+	if !active {
 		return fmt.Errorf("invalid dungeon ID: %d", dungeonID)
 	}
 
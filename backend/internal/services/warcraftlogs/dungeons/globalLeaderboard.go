@@ -10,8 +10,6 @@ import (
 	"gorm.io/gorm"
 )
 
-const RequiredDungeonCount = 8
-
 // LeaderboardEntry represents a single entry in the leaderboard
 type LeaderboardEntry struct {
 	Name         string  `json:"name"`
@@ -74,6 +72,7 @@ func (s *GlobalLeaderboardService) createOptimizedIndexes() error {
 	indexes := []string{
 		// Index for uniquely identifying players
 		"CREATE INDEX IF NOT EXISTS idx_rankings_player_unique ON player_rankings(name, server_name, server_region)",
+		"CREATE INDEX IF NOT EXISTS idx_rankings_player_identity ON player_rankings(player_identity_id, dungeon_id)",
 		// Composite indexes for different query types
 		"CREATE INDEX IF NOT EXISTS idx_rankings_role_score ON player_rankings(role, score DESC, name, server_name, server_region)",
 		"CREATE INDEX IF NOT EXISTS idx_rankings_class_score ON player_rankings(class, score DESC, name, server_name, server_region)",
@@ -123,24 +122,49 @@ func (s *GlobalLeaderboardService) sanitizeOrderBy(column string, direction Orde
 }
 
 // getBaseLeaderboardQuery returns the base CTE query for all leaderboard types
+// Players must complete every dungeon of the active season.
 func (s *GlobalLeaderboardService) getBaseLeaderboardQuery(orderBy OrderByOption) string {
+	return s.getLeaderboardQueryFrom(orderBy, "player_rankings", "active_season_dungeon_count()")
+}
+
+// getLeaderboardQueryFrom returns the base CTE query on a rankings source, the live rankings or a snapshot
+// Players are grouped by identity, so a renamed or transferred player appears once under its latest name and realm.
+// Players without identity are still identified by their name and realm.
+// Players must complete the required number of dungeons.
+func (s *GlobalLeaderboardService) getLeaderboardQueryFrom(orderBy OrderByOption, source, requiredDungeons string) string {
 	return fmt.Sprintf(`
-		WITH PlayerScores AS (
+		WITH BestScores AS (
 			SELECT 
-				name,
-				server_name,
-				server_region,
+				COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) as player_key,
+				(ARRAY_AGG(name ORDER BY start_time DESC))[1] as name,
+				(ARRAY_AGG(server_name ORDER BY start_time DESC))[1] as server_name,
+				(ARRAY_AGG(server_region ORDER BY start_time DESC))[1] as server_region,
+				MAX(start_time) as last_start_time,
 				class,
 				spec,
 				role,
+				dungeon_id,
 				MAX(medal) as best_medal,
-				ROUND(CAST(SUM(score) AS numeric), 2) as total_score,
-				COUNT(DISTINCT dungeon_id) as dungeon_count
-			FROM player_rankings
+				MAX(score) as best_score
+			FROM %s
 			WHERE deleted_at IS NULL
 			%%s  -- Additional WHERE conditions placeholder
-			GROUP BY name, server_name, server_region, class, spec, role
-			HAVING COUNT(DISTINCT dungeon_id) = %%d
+			GROUP BY player_key, class, spec, role, dungeon_id
+		),
+		PlayerScores AS (
+			SELECT 
+				(ARRAY_AGG(name ORDER BY last_start_time DESC))[1] as name,
+				(ARRAY_AGG(server_name ORDER BY last_start_time DESC))[1] as server_name,
+				(ARRAY_AGG(server_region ORDER BY last_start_time DESC))[1] as server_region,
+				class,
+				spec,
+				role,
+				MAX(best_medal) as best_medal,
+				ROUND(CAST(SUM(best_score) AS numeric), 2) as total_score,
+				COUNT(DISTINCT dungeon_id) as dungeon_count
+			FROM BestScores
+			GROUP BY player_key, class, spec, role
+			HAVING COUNT(DISTINCT dungeon_id) = %s
 		)
 		SELECT 
 			*,
@@ -148,6 +172,8 @@ func (s *GlobalLeaderboardService) getBaseLeaderboardQuery(orderBy OrderByOption
 		FROM PlayerScores
 		ORDER BY %s %s, name ASC, server_name ASC
 		LIMIT ?`,
+		source,
+		requiredDungeons,
 		orderBy.Column, orderBy.Direction,
 		orderBy.Column, orderBy.Direction,
 	)
@@ -159,7 +185,6 @@ func (s *GlobalLeaderboardService) GetGlobalLeaderboard(ctx context.Context, lim
 	rankQuery := fmt.Sprintf(
 		s.getBaseLeaderboardQuery(sanitizedOrder),
 		"", // No additional WHERE conditions
-		RequiredDungeonCount,
 	)
 
 	var entries []LeaderboardEntry
@@ -180,7 +205,6 @@ func (s *GlobalLeaderboardService) GetGlobalLeaderboardByRole(ctx context.Contex
 	rankQuery := fmt.Sprintf(
 		s.getBaseLeaderboardQuery(sanitizedOrder),
 		whereClause,
-		RequiredDungeonCount,
 	)
 
 	var entries []LeaderboardEntry
@@ -201,7 +225,6 @@ func (s *GlobalLeaderboardService) GetGlobalLeaderboardByClass(ctx context.Conte
 	rankQuery := fmt.Sprintf(
 		s.getBaseLeaderboardQuery(sanitizedOrder),
 		whereClause,
-		RequiredDungeonCount,
 	)
 
 	var entries []LeaderboardEntry
@@ -223,7 +246,6 @@ func (s *GlobalLeaderboardService) GetGlobalLeaderboardBySpec(ctx context.Contex
 	rankQuery := fmt.Sprintf(
 		s.getBaseLeaderboardQuery(sanitizedOrder),
 		whereClause,
-		RequiredDungeonCount,
 	)
 
 	var entries []LeaderboardEntry
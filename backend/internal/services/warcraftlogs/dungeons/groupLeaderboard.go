@@ -0,0 +1,416 @@
+package warcraftlogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	playerRankingModels "wowperf/internal/models/warcraftlogs/mythicplus"
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// Scopes of the group leaderboards
+const (
+	GroupScopeGuild = "guild"
+	GroupScopeRealm = "realm"
+)
+
+// Metrics ranking the group leaderboards
+const (
+	GroupMetricTopAverage   = "top_average"
+	GroupMetricPlayersAbove = "players_above"
+	GroupMetricMemberCount  = "member_count"
+	GroupMetricBestScore    = "best_score"
+)
+
+const (
+	// DefaultGroupTopN is the number of best players averaged by default
+	DefaultGroupTopN = 5
+	// MaxGroupTopN is the maximum number of best players averaged
+	MaxGroupTopN = 50
+	// MaxGroupHistoryDays is the maximum period of a group history
+	MaxGroupHistoryDays = 365
+)
+
+// DefaultScoreThresholds are the score thresholds of the player counts by default
+var DefaultScoreThresholds = []float64{2500, 3000, 3500}
+
+// GroupLeaderboardQuery represents the options of a guild or realm leaderboard, empty values use the defaults
+// Players above thresholds are counted for every threshold, ranking by players_above uses the first one.
+// Realm filters the guilds by their main realm, Guild filters the guilds by name and keeps their rank.
+type GroupLeaderboardQuery struct {
+	Scope      string
+	Region     string
+	Realm      string
+	Guild      string
+	OrderBy    string
+	TopN       int
+	Thresholds []float64
+	AsOf       *time.Time
+	SeasonSlug string
+	Limit      int
+}
+
+// normalize validates the query and fills the default values
+func (q *GroupLeaderboardQuery) normalize() error {
+	if q.Scope != GroupScopeGuild && q.Scope != GroupScopeRealm {
+		return fmt.Errorf("%w: invalid scope: %s", ErrInvalidLeaderboardFilter, q.Scope)
+	}
+	switch q.OrderBy {
+	case "":
+		q.OrderBy = GroupMetricTopAverage
+	case GroupMetricTopAverage, GroupMetricPlayersAbove, GroupMetricMemberCount, GroupMetricBestScore:
+	default:
+		return fmt.Errorf("%w: invalid order: %s", ErrInvalidLeaderboardFilter, q.OrderBy)
+	}
+	if q.TopN == 0 {
+		q.TopN = DefaultGroupTopN
+	}
+	if q.TopN < 1 || q.TopN > MaxGroupTopN {
+		return fmt.Errorf("%w: top_n must be between 1 and %d", ErrInvalidLeaderboardFilter, MaxGroupTopN)
+	}
+	if len(q.Thresholds) == 0 {
+		q.Thresholds = DefaultScoreThresholds
+	}
+	if q.Limit < 0 || q.Limit > 1000 {
+		return fmt.Errorf("%w: limit must be between 1 and 1000", ErrInvalidLeaderboardFilter)
+	}
+	if q.Limit == 0 {
+		q.Limit = 100
+	}
+	return nil
+}
+
+// GroupTimedKey represents the best timed keystone of a group in a dungeon
+type GroupTimedKey struct {
+	EncounterID   int    `json:"encounter_id"`
+	DungeonName   string `json:"dungeon_name"`
+	KeystoneLevel int    `json:"keystone_level"`
+}
+
+// GroupLeaderboardEntry represents a guild or a realm of a group leaderboard
+// Only the players qualified for the global leaderboard are members, their scores are never partial.
+type GroupLeaderboardEntry struct {
+	Rank              int             `json:"rank"`
+	GuildID           int             `json:"guild_id,omitempty"`
+	GuildName         string          `json:"guild_name,omitempty"`
+	GuildFaction      *int            `json:"guild_faction,omitempty"`
+	ServerName        string          `json:"server_name"`
+	ServerRegion      string          `json:"server_region"`
+	MemberCount       int             `json:"member_count"`
+	BestScore         float64         `json:"best_score"`
+	TopAverage        float64         `json:"top_average"`
+	PlayersAbove      map[string]int  `json:"players_above"`
+	BestTimedKeys     []GroupTimedKey `json:"best_timed_keys"`
+	PlayerScoresText  string          `json:"-"`
+	BestTimedKeysText string          `json:"-"`
+}
+
+// GroupLeaderboard represents a guild or realm leaderboard of a rankings snapshot
+type GroupLeaderboard struct {
+	Snapshot   *playerRankingModels.PlayerRankingsSnapshot `json:"snapshot,omitempty"`
+	Scope      string                                      `json:"scope"`
+	OrderBy    string                                      `json:"order_by"`
+	TopN       int                                         `json:"top_n"`
+	Thresholds []float64                                   `json:"thresholds"`
+	Entries    []GroupLeaderboardEntry                     `json:"entries"`
+}
+
+// GroupHistoryPoint represents a guild or a realm in a rankings snapshot
+type GroupHistoryPoint struct {
+	SnapshotID uint      `json:"snapshot_id"`
+	CapturedAt time.Time `json:"captured_at"`
+	IsFinal    bool      `json:"is_final"`
+	GroupLeaderboardEntry
+}
+
+// GroupHistory represents the evolution of a guild or a realm over the rankings snapshots
+type GroupHistory struct {
+	Scope      string              `json:"scope"`
+	OrderBy    string              `json:"order_by"`
+	TopN       int                 `json:"top_n"`
+	Thresholds []float64           `json:"thresholds"`
+	Points     []GroupHistoryPoint `json:"points"`
+}
+
+// groupMetricExpression returns the SQL expression of a metric of group_leaderboard_entries
+func groupMetricExpression(metric string) string {
+	switch metric {
+	case GroupMetricPlayersAbove:
+		return "(SELECT COUNT(*) FROM UNNEST(g.player_scores) AS score WHERE score >= @threshold)"
+	case GroupMetricMemberCount:
+		return "g.member_count"
+	case GroupMetricBestScore:
+		return "g.best_score"
+	default:
+		return "COALESCE((SELECT AVG(score) FROM UNNEST(g.player_scores[1:@top_n]) AS score), 0)"
+	}
+}
+
+// groupRankingQuery returns the query ranking the groups of each snapshot matching the conditions
+// Groups are ranked among the groups of their snapshot matching the conditions, then filtered by the group conditions.
+func groupRankingQuery(q GroupLeaderboardQuery, conditions, groupConditions []string, suffix string) (string, map[string]interface{}) {
+	conditions = append(conditions, "g.scope = @scope")
+	args := map[string]interface{}{
+		"scope":     q.Scope,
+		"top_n":     q.TopN,
+		"threshold": q.Thresholds[0],
+	}
+	if q.Region != "" {
+		conditions = append(conditions, "g.server_region = UPPER(@region)")
+		args["region"] = q.Region
+	}
+	if q.Realm != "" && q.Scope == GroupScopeGuild {
+		conditions = append(conditions, "g.server_key = @realm")
+		args["realm"] = warcraftlogsBuilds.NormalizeServerName(q.Realm)
+	}
+
+	where := ""
+	if len(groupConditions) > 0 {
+		where = "WHERE " + strings.Join(groupConditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		WITH ranked AS (
+			SELECT
+				g.*,
+				snap.captured_at,
+				snap.is_final,
+				%s AS top_average,
+				RANK() OVER (PARTITION BY g.snapshot_id ORDER BY %s DESC) AS rank
+			FROM group_leaderboard_entries g
+			JOIN player_rankings_snapshots snap ON snap.id = g.snapshot_id AND snap.deleted_at IS NULL
+			WHERE %s
+		)
+		SELECT
+			id, snapshot_id, captured_at, is_final, rank,
+			guild_id, guild_name, guild_faction, server_name, server_region,
+			member_count, best_score, ROUND(top_average, 2) AS top_average,
+			player_scores::text AS player_scores_text,
+			best_timed_keys::text AS best_timed_keys_text
+		FROM ranked
+		%s
+		%s`,
+		groupMetricExpression(GroupMetricTopAverage),
+		groupMetricExpression(q.OrderBy),
+		strings.Join(conditions, " AND "),
+		where,
+		suffix,
+	)
+	return query, args
+}
+
+// GetGroupLeaderboard retrieves the guild or realm leaderboard of the latest snapshot, of the snapshot at a date or of the final standings of a season
+// Returns nil when no snapshot matches.
+func (s *GlobalLeaderboardService) GetGroupLeaderboard(ctx context.Context, q GroupLeaderboardQuery) (*GroupLeaderboard, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+
+	query := snapshotQuery
+	var snapshotArgs []interface{}
+	if q.SeasonSlug != "" {
+		query += " AND s.slug = ? AND prs.is_final"
+		snapshotArgs = append(snapshotArgs, q.SeasonSlug)
+	}
+	if q.AsOf != nil {
+		query += " AND prs.captured_at <= ?"
+		snapshotArgs = append(snapshotArgs, *q.AsOf)
+	}
+	var snapshots []playerRankingModels.PlayerRankingsSnapshot
+	if err := s.db.WithContext(ctx).Raw(query+" ORDER BY prs.captured_at DESC LIMIT 1", snapshotArgs...).
+		Scan(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s leaderboard snapshot: %w", q.Scope, err)
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+
+	var groupConditions []string
+	if q.Guild != "" && q.Scope == GroupScopeGuild {
+		groupConditions = append(groupConditions, "LOWER(guild_name) LIKE @guild")
+	}
+	rankQuery, args := groupRankingQuery(q, []string{"g.snapshot_id = @snapshot"}, groupConditions,
+		"ORDER BY rank, best_score DESC, id LIMIT @limit")
+	args["snapshot"] = snapshots[0].ID
+	args["limit"] = q.Limit
+	args["guild"] = "%" + strings.ToLower(strings.TrimSpace(q.Guild)) + "%"
+
+	leaderboard := &GroupLeaderboard{
+		Snapshot:   &snapshots[0],
+		Scope:      q.Scope,
+		OrderBy:    q.OrderBy,
+		TopN:       q.TopN,
+		Thresholds: q.Thresholds,
+		Entries:    make([]GroupLeaderboardEntry, 0),
+	}
+	if err := s.db.WithContext(ctx).Raw(rankQuery, args).Scan(&leaderboard.Entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s leaderboard of snapshot %d: %w", q.Scope, snapshots[0].ID, err)
+	}
+
+	dungeonNames, err := s.getDungeonNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range leaderboard.Entries {
+		if err := leaderboard.Entries[i].computeMetrics(q.Thresholds, dungeonNames); err != nil {
+			return nil, err
+		}
+	}
+
+	return leaderboard, nil
+}
+
+// GetGuildHistory retrieves the evolution of a guild over the snapshots of the last days
+// Ranks are computed among the guilds of each snapshot, within the region of the query when set.
+func (s *GlobalLeaderboardService) GetGuildHistory(ctx context.Context, q GroupLeaderboardQuery, guildID int, days int) (*GroupHistory, error) {
+	q.Scope = GroupScopeGuild
+	q.Realm = ""
+	if guildID < 1 {
+		return nil, fmt.Errorf("%w: invalid guild_id", ErrInvalidLeaderboardFilter)
+	}
+	return s.getGroupHistory(ctx, q, "guild_id = @guild_id", map[string]interface{}{"guild_id": guildID}, days)
+}
+
+// GetRealmHistory retrieves the evolution of a realm over the snapshots of the last days
+// Ranks are computed among the realms of the region of each snapshot.
+func (s *GlobalLeaderboardService) GetRealmHistory(ctx context.Context, q GroupLeaderboardQuery, realm, region string, days int) (*GroupHistory, error) {
+	q.Scope = GroupScopeRealm
+	q.Region = region
+	if realm == "" || region == "" {
+		return nil, fmt.Errorf("%w: realm and region are required", ErrInvalidLeaderboardFilter)
+	}
+	return s.getGroupHistory(ctx, q, "server_key = @server_key", map[string]interface{}{
+		"server_key": warcraftlogsBuilds.NormalizeServerName(realm),
+	}, days)
+}
+
+// getGroupHistory retrieves the points of a group over the snapshots of the last days, from the oldest one
+func (s *GlobalLeaderboardService) getGroupHistory(ctx context.Context, q GroupLeaderboardQuery, groupCondition string, groupArgs map[string]interface{}, days int) (*GroupHistory, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	if days < 1 || days > MaxGroupHistoryDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidLeaderboardFilter, MaxGroupHistoryDays)
+	}
+
+	historyQuery, args := groupRankingQuery(q, []string{"snap.captured_at >= @since"}, []string{groupCondition},
+		"ORDER BY captured_at ASC")
+	args["since"] = time.Now().AddDate(0, 0, -days)
+	for key, value := range groupArgs {
+		args[key] = value
+	}
+
+	history := &GroupHistory{
+		Scope:      q.Scope,
+		OrderBy:    q.OrderBy,
+		TopN:       q.TopN,
+		Thresholds: q.Thresholds,
+		Points:     make([]GroupHistoryPoint, 0),
+	}
+	if err := s.db.WithContext(ctx).Raw(historyQuery, args).Scan(&history.Points).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s history: %w", q.Scope, err)
+	}
+
+	dungeonNames, err := s.getDungeonNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history.Points {
+		if err := history.Points[i].computeMetrics(q.Thresholds, dungeonNames); err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
+
+// getDungeonNames retrieves the names of the dungeons by WarcraftLogs encounter ID
+func (s *GlobalLeaderboardService) getDungeonNames(ctx context.Context) (map[int]string, error) {
+	var dungeons []struct {
+		EncounterID int
+		Name        string
+	}
+	if err := s.db.WithContext(ctx).
+		Raw("SELECT encounter_id, name FROM dungeons WHERE encounter_id IS NOT NULL AND deleted_at IS NULL").
+		Scan(&dungeons).Error; err != nil {
+		return nil, fmt.Errorf("failed to get dungeon names: %w", err)
+	}
+
+	names := make(map[int]string, len(dungeons))
+	for _, dungeon := range dungeons {
+		names[dungeon.EncounterID] = dungeon.Name
+	}
+	return names, nil
+}
+
+// computeMetrics counts the players above the thresholds and decodes the best timed keys of the entry
+func (e *GroupLeaderboardEntry) computeMetrics(thresholds []float64, dungeonNames map[int]string) error {
+	scores, err := parseScoreArray(e.PlayerScoresText)
+	if err != nil {
+		return err
+	}
+	e.PlayersAbove = countPlayersAbove(scores, thresholds)
+
+	levels := make(map[string]int)
+	if e.BestTimedKeysText != "" {
+		if err := json.Unmarshal([]byte(e.BestTimedKeysText), &levels); err != nil {
+			return fmt.Errorf("failed to decode best timed keys: %w", err)
+		}
+	}
+	e.BestTimedKeys = make([]GroupTimedKey, 0, len(levels))
+	for encounter, level := range levels {
+		encounterID, err := strconv.Atoi(encounter)
+		if err != nil {
+			return fmt.Errorf("failed to decode best timed keys: invalid encounter %s", encounter)
+		}
+		e.BestTimedKeys = append(e.BestTimedKeys, GroupTimedKey{
+			EncounterID:   encounterID,
+			DungeonName:   dungeonNames[encounterID],
+			KeystoneLevel: level,
+		})
+	}
+	sort.Slice(e.BestTimedKeys, func(i, j int) bool {
+		return e.BestTimedKeys[i].EncounterID < e.BestTimedKeys[j].EncounterID
+	})
+
+	return nil
+}
+
+// parseScoreArray parses a postgres numeric array, such as {3210.50,3105.00}
+func parseScoreArray(value string) ([]float64, error) {
+	value = strings.Trim(value, "{}")
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	scores := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		score, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse player scores: %w", err)
+		}
+		scores = append(scores, score)
+	}
+	return scores, nil
+}
+
+// countPlayersAbove counts the scores reaching each threshold, keyed by threshold
+func countPlayersAbove(scores []float64, thresholds []float64) map[string]int {
+	counts := make(map[string]int, len(thresholds))
+	for _, threshold := range thresholds {
+		count := 0
+		for _, score := range scores {
+			if score >= threshold {
+				count++
+			}
+		}
+		counts[strconv.FormatFloat(threshold, 'f', -1, 64)] = count
+	}
+	return counts
+}
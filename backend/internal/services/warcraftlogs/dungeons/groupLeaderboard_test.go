@@ -0,0 +1,55 @@
+package warcraftlogs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLeaderboardMetrics(t *testing.T) {
+	t.Run("scores are parsed from the numeric array", func(t *testing.T) {
+		scores, err := parseScoreArray("{3210.50,3105.00,2499.99}")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{3210.50, 3105.00, 2499.99}, scores)
+
+		empty, err := parseScoreArray("{}")
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("players are counted for each threshold", func(t *testing.T) {
+		counts := countPlayersAbove([]float64{3500, 3100, 3000, 2499.99}, []float64{2500, 3000, 3500.5})
+		assert.Equal(t, map[string]int{"2500": 3, "3000": 3, "3500.5": 0}, counts)
+	})
+
+	t.Run("best timed keys are decoded by dungeon", func(t *testing.T) {
+		entry := GroupLeaderboardEntry{
+			PlayerScoresText:  "{3000.00}",
+			BestTimedKeysText: `{"62660": 12, "12660": 15}`,
+		}
+		require.NoError(t, entry.computeMetrics([]float64{2500}, map[int]string{12660: "Ara-Kara, City of Echoes"}))
+
+		assert.Equal(t, []GroupTimedKey{
+			{EncounterID: 12660, DungeonName: "Ara-Kara, City of Echoes", KeystoneLevel: 15},
+			{EncounterID: 62660, KeystoneLevel: 12},
+		}, entry.BestTimedKeys)
+		assert.Equal(t, map[string]int{"2500": 1}, entry.PlayersAbove)
+	})
+
+	t.Run("query defaults and validation", func(t *testing.T) {
+		query := GroupLeaderboardQuery{Scope: GroupScopeGuild}
+		require.NoError(t, query.normalize())
+		assert.Equal(t, GroupMetricTopAverage, query.OrderBy)
+		assert.Equal(t, DefaultGroupTopN, query.TopN)
+		assert.Equal(t, DefaultScoreThresholds, query.Thresholds)
+
+		for _, invalid := range []GroupLeaderboardQuery{
+			{Scope: "faction"},
+			{Scope: GroupScopeRealm, OrderBy: "name"},
+			{Scope: GroupScopeGuild, TopN: MaxGroupTopN + 1},
+		} {
+			assert.ErrorIs(t, invalid.normalize(), ErrInvalidLeaderboardFilter)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package warcraftlogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	playerRankingModels "wowperf/internal/models/warcraftlogs/mythicplus"
+)
+
+// ErrInvalidLeaderboardFilter is returned when a role, class, spec or limit filter of a leaderboard is invalid
+var ErrInvalidLeaderboardFilter = errors.New("invalid leaderboard filter")
+
+// HistoricalLeaderboard represents the global leaderboard of a rankings snapshot
+type HistoricalLeaderboard struct {
+	Snapshot playerRankingModels.PlayerRankingsSnapshot `json:"snapshot"`
+	Entries  []LeaderboardEntry                         `json:"entries"`
+}
+
+// snapshotQuery selects the snapshots with the slug of their season
+const snapshotQuery = `
+	SELECT prs.*, s.slug AS season_slug
+	FROM player_rankings_snapshots prs
+	LEFT JOIN seasons s ON s.id = prs.season_id
+	WHERE prs.deleted_at IS NULL`
+
+// GetRankingsSnapshots retrieves the rankings snapshots, from the latest one
+// The snapshots can be filtered by season slug, and restricted to the final standings of the seasons.
+func (s *GlobalLeaderboardService) GetRankingsSnapshots(ctx context.Context, seasonSlug string, finalOnly bool) ([]playerRankingModels.PlayerRankingsSnapshot, error) {
+	query := snapshotQuery
+	args := []interface{}{}
+	if seasonSlug != "" {
+		query += " AND s.slug = ?"
+		args = append(args, seasonSlug)
+	}
+	if finalOnly {
+		query += " AND prs.is_final"
+	}
+	query += " ORDER BY prs.captured_at DESC"
+
+	var snapshots []playerRankingModels.PlayerRankingsSnapshot
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rankings snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetGlobalLeaderboardAsOf retrieves the global leaderboard of the latest snapshot captured at the given date
+// Returns nil when no snapshot was captured before the date.
+func (s *GlobalLeaderboardService) GetGlobalLeaderboardAsOf(ctx context.Context, asOf time.Time, role, class, spec string, limit int, orderBy string, direction OrderDirection) (*HistoricalLeaderboard, error) {
+	var snapshots []playerRankingModels.PlayerRankingsSnapshot
+	if err := s.db.WithContext(ctx).
+		Raw(snapshotQuery+" AND prs.captured_at <= ? ORDER BY prs.captured_at DESC LIMIT 1", asOf).
+		Scan(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rankings snapshot as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+
+	return s.getSnapshotLeaderboard(ctx, snapshots[0], role, class, spec, limit, orderBy, direction)
+}
+
+// GetSeasonFinalLeaderboard retrieves the final standings of a season, from its archived snapshot
+// Returns nil when the season has no final standings yet.
+func (s *GlobalLeaderboardService) GetSeasonFinalLeaderboard(ctx context.Context, seasonSlug string, role, class, spec string, limit int, orderBy string, direction OrderDirection) (*HistoricalLeaderboard, error) {
+	snapshots, err := s.GetRankingsSnapshots(ctx, seasonSlug, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+
+	return s.getSnapshotLeaderboard(ctx, snapshots[0], role, class, spec, limit, orderBy, direction)
+}
+
+// getSnapshotLeaderboard retrieves the global leaderboard of a snapshot with optional role, class and spec filters
+// Players must complete every dungeon of the season captured by the snapshot.
+func (s *GlobalLeaderboardService) getSnapshotLeaderboard(ctx context.Context, snapshot playerRankingModels.PlayerRankingsSnapshot, role, class, spec string, limit int, orderBy string, direction OrderDirection) (*HistoricalLeaderboard, error) {
+	if err := s.validateInput(role, class, spec, limit); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLeaderboardFilter, err)
+	}
+
+	var conditions []string
+	var args []interface{}
+	if role != "" {
+		conditions = append(conditions, "AND role = ?")
+		args = append(args, formatRole(role))
+	}
+	if class != "" {
+		conditions = append(conditions, "AND class = ?")
+		args = append(args, formatNameCase(class))
+	}
+	if spec != "" {
+		conditions = append(conditions, "AND spec = ?")
+		args = append(args, formatNameCase(spec))
+	}
+	args = append(args, limit)
+
+	source := fmt.Sprintf("(SELECT * FROM player_rankings_history WHERE snapshot_id = %d) AS snapshot_rankings", snapshot.ID)
+	rankQuery := fmt.Sprintf(
+		s.getLeaderboardQueryFrom(s.sanitizeOrderBy(orderBy, direction), source, strconv.Itoa(snapshot.DungeonCount)),
+		strings.Join(conditions, " "),
+	)
+
+	leaderboard := &HistoricalLeaderboard{Snapshot: snapshot}
+	if err := s.db.WithContext(ctx).Raw(rankQuery, args...).Scan(&leaderboard.Entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard of snapshot %d: %w", snapshot.ID, err)
+	}
+
+	return leaderboard, nil
+}
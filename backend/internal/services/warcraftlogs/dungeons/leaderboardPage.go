@@ -0,0 +1,209 @@
+package warcraftlogs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+var (
+	// ErrInvalidLeaderboardCursor is returned when a leaderboard cursor cannot be decoded
+	ErrInvalidLeaderboardCursor = errors.New("invalid leaderboard cursor")
+	// ErrExpiredLeaderboardCursor is returned when the snapshot of a leaderboard cursor was pruned
+	ErrExpiredLeaderboardCursor = errors.New("expired leaderboard cursor")
+)
+
+// LeaderboardFilter represents the filters of a paginated leaderboard, empty values are ignored
+// DungeonID restricts the leaderboard to the best run of the players in a dungeon.
+type LeaderboardFilter struct {
+	Region    string
+	Realm     string
+	Class     string
+	Spec      string
+	Role      string
+	MinScore  *float64
+	MaxScore  *float64
+	DungeonID int
+}
+
+// leaderboardCursor is the position of a page, pinned to the snapshot of the first page
+// Entries are ordered by score then ID, so the position after the last entry of a page is unique.
+type leaderboardCursor struct {
+	SnapshotID uint   `json:"s"`
+	Score      string `json:"v"`
+	EntryID    uint64 `json:"i"`
+}
+
+// encode returns the opaque form of the cursor, the same position always gives the same cursor
+func (c leaderboardCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeLeaderboardCursor decodes an opaque cursor
+func decodeLeaderboardCursor(value string) (*leaderboardCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidLeaderboardCursor
+	}
+	var cursor leaderboardCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.SnapshotID == 0 || cursor.Score == "" {
+		return nil, ErrInvalidLeaderboardCursor
+	}
+	return &cursor, nil
+}
+
+// LeaderboardPageEntry represents a player of a paginated leaderboard
+type LeaderboardPageEntry struct {
+	ID               uint64  `json:"-"`
+	Rank             int     `json:"rank"`
+	PlayerIdentityID *uint   `json:"player_identity_id,omitempty"`
+	Name             string  `json:"name"`
+	ServerName       string  `json:"server_name"`
+	ServerRegion     string  `json:"server_region"`
+	Class            string  `json:"class"`
+	Spec             string  `json:"spec"`
+	Role             string  `json:"role"`
+	TotalScore       float64 `json:"total_score"`
+	TotalScoreText   string  `json:"-"`
+	DungeonCount     int     `json:"dungeon_count"`
+	Medal            string  `json:"medal"`
+}
+
+// LeaderboardPage represents a page of a leaderboard with the cursor of the next page
+type LeaderboardPage struct {
+	SnapshotID uint                   `json:"snapshot_id"`
+	CapturedAt *time.Time             `json:"captured_at,omitempty"`
+	Entries    []LeaderboardPageEntry `json:"entries"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+}
+
+// GetLeaderboardPage retrieves a page of the leaderboard, from the latest snapshot or from the snapshot of the cursor
+// Pages are read from the same snapshot, so paging stays consistent while the rankings are updated.
+// Ranks are computed among the players matching the filters.
+func (s *GlobalLeaderboardService) GetLeaderboardPage(ctx context.Context, filter LeaderboardFilter, cursorValue string, limit int) (*LeaderboardPage, error) {
+	if err := s.validateInput(filter.Role, filter.Class, filter.Spec, limit); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLeaderboardFilter, err)
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		return nil, fmt.Errorf("%w: min_score is greater than max_score", ErrInvalidLeaderboardFilter)
+	}
+
+	var cursor *leaderboardCursor
+	if cursorValue != "" {
+		var err error
+		if cursor, err = decodeLeaderboardCursor(cursorValue); err != nil {
+			return nil, err
+		}
+	}
+
+	// The first page reads the latest snapshot, the next pages the snapshot of their cursor
+	snapshotQuery := "SELECT id, captured_at FROM player_rankings_snapshots WHERE deleted_at IS NULL"
+	var snapshotArgs []interface{}
+	if cursor != nil {
+		snapshotQuery += " AND id = ?"
+		snapshotArgs = append(snapshotArgs, cursor.SnapshotID)
+	}
+	var snapshots []struct {
+		ID         uint
+		CapturedAt time.Time
+	}
+	if err := s.db.WithContext(ctx).Raw(snapshotQuery+" ORDER BY id DESC LIMIT 1", snapshotArgs...).
+		Scan(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard snapshot: %w", err)
+	}
+	page := &LeaderboardPage{Entries: make([]LeaderboardPageEntry, 0)}
+	if len(snapshots) == 0 {
+		if cursor != nil {
+			return nil, ErrExpiredLeaderboardCursor
+		}
+		return page, nil
+	}
+	page.SnapshotID = snapshots[0].ID
+	page.CapturedAt = &snapshots[0].CapturedAt
+
+	conditions := []string{"snapshot_id = @snapshot", "dungeon_id = @dungeon", "qualified"}
+	args := map[string]interface{}{
+		"snapshot": page.SnapshotID,
+		"dungeon":  filter.DungeonID,
+		"limit":    limit + 1,
+	}
+	if filter.Region != "" {
+		conditions = append(conditions, "server_region = UPPER(@region)")
+		args["region"] = filter.Region
+	}
+	if filter.Realm != "" {
+		conditions = append(conditions, "server_key = @realm")
+		args["realm"] = warcraftlogsBuilds.NormalizeServerName(filter.Realm)
+	}
+	if filter.Class != "" {
+		conditions = append(conditions, "class = @class")
+		args["class"] = formatNameCase(filter.Class)
+	}
+	if filter.Spec != "" {
+		conditions = append(conditions, "spec = @spec")
+		args["spec"] = formatNameCase(filter.Spec)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, "role = @role")
+		args["role"] = formatRole(filter.Role)
+	}
+	if filter.MinScore != nil {
+		conditions = append(conditions, "total_score >= @min_score")
+		args["min_score"] = *filter.MinScore
+	}
+	if filter.MaxScore != nil {
+		conditions = append(conditions, "total_score <= @max_score")
+		args["max_score"] = *filter.MaxScore
+	}
+
+	pageCondition := ""
+	if cursor != nil {
+		pageCondition = "WHERE total_score < CAST(@cursor_score AS numeric) OR (total_score = CAST(@cursor_score AS numeric) AND id > @cursor_id)"
+		args["cursor_score"] = cursor.Score
+		args["cursor_id"] = cursor.EntryID
+	}
+
+	query := fmt.Sprintf(`
+		WITH ranked AS (
+			SELECT
+				*,
+				DENSE_RANK() OVER (ORDER BY total_score DESC) AS rank
+			FROM player_leaderboard_entries
+			WHERE %s
+		)
+		SELECT
+			id, rank, player_identity_id, name, server_name, server_region, class, spec, role,
+			total_score, total_score::text AS total_score_text, dungeon_count, best_medal AS medal
+		FROM ranked
+		%s
+		ORDER BY total_score DESC, id ASC
+		LIMIT @limit`,
+		strings.Join(conditions, " AND "),
+		pageCondition,
+	)
+
+	if err := s.db.WithContext(ctx).Raw(query, args).Scan(&page.Entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard page of snapshot %d: %w", page.SnapshotID, err)
+	}
+
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[:limit]
+		last := page.Entries[limit-1]
+		page.HasMore = true
+		page.NextCursor = leaderboardCursor{
+			SnapshotID: page.SnapshotID,
+			Score:      last.TotalScoreText,
+			EntryID:    last.ID,
+		}.encode()
+	}
+
+	return page, nil
+}
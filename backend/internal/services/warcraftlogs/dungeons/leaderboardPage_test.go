@@ -0,0 +1,31 @@
+package warcraftlogs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderboardCursor(t *testing.T) {
+	t.Run("round trip keeps the position", func(t *testing.T) {
+		cursor := leaderboardCursor{SnapshotID: 12, Score: "3456.78", EntryID: 9001}
+
+		decoded, err := decodeLeaderboardCursor(cursor.encode())
+		require.NoError(t, err)
+		assert.Equal(t, cursor, *decoded)
+	})
+
+	t.Run("same position gives the same cursor", func(t *testing.T) {
+		first := leaderboardCursor{SnapshotID: 3, Score: "100.00", EntryID: 7}.encode()
+		second := leaderboardCursor{SnapshotID: 3, Score: "100.00", EntryID: 7}.encode()
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("invalid cursors are rejected", func(t *testing.T) {
+		for _, value := range []string{"not base64!", "e30", "eyJzIjowfQ"} {
+			_, err := decodeLeaderboardCursor(value)
+			assert.ErrorIs(t, err, ErrInvalidLeaderboardCursor, value)
+		}
+	})
+}
@@ -141,16 +141,15 @@ func (r *RankingsUpdater) checkAndUpdate(ctx context.Context) error {
 
 // UpdateRankings updates the rankings in the database
 func (r *RankingsUpdater) UpdateRankings(ctx context.Context) error {
-	dungeonIDs := []int{
-		DungeonCinderbrew,
-		DungeonDarkflame,
-		DungeonFloodgate,
-		DungeonMechagon,
-		DungeonPriory,
-		DungeonMotherlode,
-		DungeonRookery,
-		DungeonTheaterPain,
+	// Dungeons of the active seasons, a season rollover only needs a data change
+	dungeonIDs, err := GetActiveEncounterIDs(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to get active season dungeons: %w", err)
+	}
+	if len(dungeonIDs) == 0 {
+		return fmt.Errorf("no dungeon with an encounter ID in the active seasons")
 	}
+	log.Printf("Updating rankings for %d dungeons of the active seasons", len(dungeonIDs))
 	pagesPerDungeon := 2
 
 	rankings, err := GetGlobalRankings(r.service, ctx, dungeonIDs, pagesPerDungeon)
@@ -158,6 +157,7 @@ func (r *RankingsUpdater) UpdateRankings(ctx context.Context) error {
 		return fmt.Errorf("failed to get global rankings: %w", err)
 	}
 
+	// Rankings are replaced in a single transaction, a failed update keeps the previous rankings
 	err = r.db.Transaction(func(tx *gorm.DB) error {
 		// Delete existing data
 		if err := tx.Exec("DELETE FROM player_rankings").Error; err != nil {
@@ -203,8 +203,23 @@ func (r *RankingsUpdater) UpdateRankings(ctx context.Context) error {
 		processRoleRankings(rankings.Healers.Players)
 		processRoleRankings(rankings.DPS.Players)
 
+		// Keep the current rankings when the update returned nothing
+		if len(newRankings) == 0 {
+			return fmt.Errorf("no rankings returned, keeping the current rankings")
+		}
+
 		// Insert new data by batches
-		return r.insertRankingsInBatches(tx, newRankings)
+		if err := r.insertRankingsInBatches(tx, newRankings); err != nil {
+			return err
+		}
+
+		// Write the new rankings as a snapshot, archived with the season
+		snapshot, err := playerRankingModels.CreateRankingsSnapshot(tx, "updater")
+		if err != nil {
+			return err
+		}
+		log.Printf("Rankings snapshot %d created with %d rankings", snapshot.ID, snapshot.RankingCount)
+		return nil
 	})
 
 	if err != nil {
@@ -319,8 +334,6 @@ func (r *RankingsUpdater) calculateDailySpecMetrics(ctx context.Context) error {
 	processingDate := time.Now().Truncate(24 * time.Hour)
 	log.Printf("Calculating spec metrics for date: %s", processingDate.Format("2006-01-02"))
 
-	// Fixed number of dungeons (always 8)
-	const totalDungeonCount = 8
 	// Number of top players to consider for averages
 	const topPlayersCount = 10
 
@@ -430,7 +443,7 @@ func (r *RankingsUpdater) calculateDailySpecMetrics(ctx context.Context) error {
 		var playerScores []PlayerGlobalScore
 
 		// SQL query to calculate global scores by player
-		// Filter to keep only players who have completed all 8 dungeons
+		// Filter to keep only players who have completed every dungeon of the active season
 		if err := tx.Raw(`
 			SELECT 
 				spec, 
@@ -446,12 +459,12 @@ func (r *RankingsUpdater) calculateDailySpecMetrics(ctx context.Context) error {
 			FROM player_rankings
 			WHERE server_region != 'CN'
 			GROUP BY spec, class, role, name, server_name
-			HAVING COUNT(DISTINCT dungeon_id) = 8
+			HAVING COUNT(DISTINCT dungeon_id) = active_season_dungeon_count()
 		`).Scan(&playerScores).Error; err != nil {
 			return fmt.Errorf("error calculating player global scores: %w", err)
 		}
 
-		log.Printf("Calculated global scores for %d players who completed all 8 dungeons", len(playerScores))
+		log.Printf("Calculated global scores for %d players who completed every dungeon of the active season", len(playerScores))
 
 		// Group players by spec/class/role
 		specPlayerMap := make(map[string][]PlayerGlobalScore)
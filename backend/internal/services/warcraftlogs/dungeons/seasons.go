@@ -0,0 +1,67 @@
+package warcraftlogs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SeasonDungeon represents a dungeon of the active season of a region, from the active_season_dungeons view
+type SeasonDungeon struct {
+	Region      string `json:"region"`
+	SeasonID    uint   `json:"season_id"`
+	SeasonSlug  string `json:"season_slug"`
+	DungeonID   uint   `json:"dungeon_id"`
+	EncounterID int    `json:"encounter_id"`
+	DungeonName string `json:"dungeon_name"`
+	DungeonSlug string `json:"dungeon_slug"`
+}
+
+// GetActiveSeasonDungeons retrieves the dungeons of the active season of a region, or of every region when region is empty
+// The active season of a region is set in active_seasons, or else is the season running at the current date.
+func GetActiveSeasonDungeons(ctx context.Context, db *gorm.DB, region string) ([]SeasonDungeon, error) {
+	query := db.WithContext(ctx).Table("active_season_dungeons")
+	if region != "" {
+		query = query.Where("region = ?", strings.ToUpper(region))
+	}
+
+	var dungeons []SeasonDungeon
+	if err := query.Order("region, encounter_id").Scan(&dungeons).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active season dungeons: %w", err)
+	}
+	return dungeons, nil
+}
+
+// GetActiveEncounterIDs retrieves the WarcraftLogs encounter IDs of the dungeons of the active seasons of all regions
+func GetActiveEncounterIDs(ctx context.Context, db *gorm.DB) ([]int, error) {
+	dungeons, err := GetActiveSeasonDungeons(ctx, db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	encounterIDs := make([]int, 0, len(dungeons))
+	for _, dungeon := range dungeons {
+		if !seen[dungeon.EncounterID] {
+			seen[dungeon.EncounterID] = true
+			encounterIDs = append(encounterIDs, dungeon.EncounterID)
+		}
+	}
+	sort.Ints(encounterIDs)
+
+	return encounterIDs, nil
+}
+
+// IsActiveDungeon checks whether an encounter is a dungeon of an active season
+func IsActiveDungeon(ctx context.Context, db *gorm.DB, encounterID int) (bool, error) {
+	var count int64
+	if err := db.WithContext(ctx).Table("active_season_dungeons").
+		Where("encounter_id = ?", encounterID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check dungeon %d: %w", encounterID, err)
+	}
+	return count > 0, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	playerRankingModels "wowperf/internal/models/warcraftlogs/mythicplus"
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 
 	"gorm.io/gorm"
 )
@@ -21,8 +22,35 @@ func NewSpecEvolutionMetricsAnalysisService(db *gorm.DB) *SpecEvolutionMetricsAn
 	return &SpecEvolutionMetricsAnalysisService{db: db}
 }
 
+// GetPatch resolves a patch filter value: a patch name, "current" or "all"
+// "all" resolves to nil, as does "current" while the patch registry is empty.
+func (s *SpecEvolutionMetricsAnalysisService) GetPatch(ctx context.Context, name string) (*warcraftlogsBuilds.GamePatch, error) {
+	return warcraftlogsBuilds.FindGamePatch(s.db.WithContext(ctx), name)
+}
+
+// patchCondition restricts the capture dates of a metrics query to a patch, with its arguments
+// A nil patch returns no condition.
+func patchCondition(column string, patch *warcraftlogsBuilds.GamePatch) (string, []interface{}) {
+	if patch == nil {
+		return "", nil
+	}
+	if patch.EndsAt == nil {
+		return fmt.Sprintf(" AND %s >= ?", column), []interface{}{patch.StartsAt}
+	}
+	return fmt.Sprintf(" AND %s >= ? AND %s < ?", column, column), []interface{}{patch.StartsAt, *patch.EndsAt}
+}
+
+// clampToPatch moves a target date past the end of a patch back to the last day of the patch
+func clampToPatch(targetDate time.Time, patch *warcraftlogsBuilds.GamePatch) time.Time {
+	if patch == nil || patch.EndsAt == nil || targetDate.Before(*patch.EndsAt) {
+		return targetDate
+	}
+	return patch.EndsAt.Truncate(24*time.Hour).AddDate(0, 0, -1)
+}
+
 // buildEvolutionQuery builds a SQL query for the evolution of metrics
-func (s *SpecEvolutionMetricsAnalysisService) buildEvolutionQuery(daysInterval int, isGlobal bool, includeClassFilter bool) string {
+// prevCondition restricts the previous metrics joined for the comparison, its arguments come first.
+func (s *SpecEvolutionMetricsAnalysisService) buildEvolutionQuery(daysInterval int, isGlobal bool, includeClassFilter bool, prevCondition string) string {
 	query := `
 	SELECT
 		current.capture_date AS end_date,
@@ -61,6 +89,7 @@ func (s *SpecEvolutionMetricsAnalysisService) buildEvolutionQuery(daysInterval i
 		AND current.encounter_id = prev.encounter_id
 		AND current.is_global = prev.is_global
 		AND prev.capture_date = current.capture_date - INTERVAL '%d days'
+		%s
 	WHERE
 		current.spec = ?
 		AND current.capture_date = ?
@@ -75,30 +104,26 @@ func (s *SpecEvolutionMetricsAnalysisService) buildEvolutionQuery(daysInterval i
 		query += " AND current.encounter_id = ?"
 	}
 
-	return fmt.Sprintf(query, daysInterval, daysInterval)
+	return fmt.Sprintf(query, daysInterval, daysInterval, prevCondition)
 }
 
 // GetSpecEvolution retrieves the evolution of metrics for a specialization
-func (s *SpecEvolutionMetricsAnalysisService) GetSpecEvolution(ctx context.Context, spec string, class *string, daysInterval int, encounterID *int, targetDate time.Time, isGlobal bool) ([]playerRankingModels.SpecEvolutionMythicPlus, error) {
+// With a patch, the target date is clamped to the patch and metrics captured before the patch are not compared.
+func (s *SpecEvolutionMetricsAnalysisService) GetSpecEvolution(ctx context.Context, spec string, class *string, daysInterval int, encounterID *int, targetDate time.Time, isGlobal bool, patch *warcraftlogsBuilds.GamePatch) ([]playerRankingModels.SpecEvolutionMythicPlus, error) {
 	includeClassFilter := class != nil
-	query := s.buildEvolutionQuery(daysInterval, isGlobal, includeClassFilter)
+	prevCondition, params := patchCondition("prev.capture_date", patch)
+	query := s.buildEvolutionQuery(daysInterval, isGlobal, includeClassFilter, prevCondition)
 	var results []playerRankingModels.SpecEvolutionMythicPlus
-	var err error
 
-	if isGlobal {
-		if includeClassFilter {
-			err = s.db.WithContext(ctx).Raw(query, spec, targetDate, isGlobal, *class).Scan(&results).Error
-		} else {
-			err = s.db.WithContext(ctx).Raw(query, spec, targetDate, isGlobal).Scan(&results).Error
-		}
-	} else {
-		if includeClassFilter {
-			err = s.db.WithContext(ctx).Raw(query, spec, targetDate, isGlobal, *class, *encounterID).Scan(&results).Error
-		} else {
-			err = s.db.WithContext(ctx).Raw(query, spec, targetDate, isGlobal, *encounterID).Scan(&results).Error
-		}
+	params = append(params, spec, clampToPatch(targetDate, patch), isGlobal)
+	if includeClassFilter {
+		params = append(params, *class)
+	}
+	if !isGlobal {
+		params = append(params, *encounterID)
 	}
 
+	err := s.db.WithContext(ctx).Raw(query, params...).Scan(&results).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spec evolution: %w", err)
 	}
@@ -107,7 +132,10 @@ func (s *SpecEvolutionMetricsAnalysisService) GetSpecEvolution(ctx context.Conte
 }
 
 // GetCurrentRanking retrieves the current ranking of all specializations
-func (s *SpecEvolutionMetricsAnalysisService) GetCurrentRanking(ctx context.Context, role *string, targetDate time.Time, isGlobal bool) ([]playerRankingModels.DailySpecMetricMythicPlus, error) {
+// With a patch, the target date is clamped to the last day of the patch.
+func (s *SpecEvolutionMetricsAnalysisService) GetCurrentRanking(ctx context.Context, role *string, targetDate time.Time, isGlobal bool, patch *warcraftlogsBuilds.GamePatch) ([]playerRankingModels.DailySpecMetricMythicPlus, error) {
+	targetDate = clampToPatch(targetDate, patch)
+
 	query := `
 	SELECT * FROM daily_spec_metrics_mythic_plus 
 	WHERE capture_date = ? AND is_global = ?
@@ -135,16 +163,17 @@ func (s *SpecEvolutionMetricsAnalysisService) GetCurrentRanking(ctx context.Cont
 	return results, nil
 }
 
-// GetLatestMetricsDate retrieves the latest date for which metrics are available
-func (s *SpecEvolutionMetricsAnalysisService) GetLatestMetricsDate(ctx context.Context) (time.Time, error) {
+// GetLatestMetricsDate retrieves the latest date for which metrics are available, within the patch if any
+func (s *SpecEvolutionMetricsAnalysisService) GetLatestMetricsDate(ctx context.Context, patch *warcraftlogsBuilds.GamePatch) (time.Time, error) {
 	var result struct {
 		LatestDate time.Time
 	}
 
+	condition, params := patchCondition("capture_date", patch)
 	err := s.db.WithContext(ctx).Raw(`
 		SELECT MAX(capture_date) as latest_date 
 		FROM daily_spec_metrics_mythic_plus
-	`).Scan(&result).Error
+		WHERE TRUE`+condition, params...).Scan(&result).Error
 
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to get latest metrics date: %w", err)
@@ -212,14 +241,15 @@ func (s *SpecEvolutionMetricsAnalysisService) GetRoleSpecs(ctx context.Context,
 	return results, nil
 }
 
-// GetSpecHistoricalData retrieves historical data for a spec over multiple dates
-func (s *SpecEvolutionMetricsAnalysisService) GetSpecHistoricalData(ctx context.Context, spec string, class *string, days int, isGlobal bool, encounterID *int) ([]playerRankingModels.DailySpecMetricMythicPlus, error) {
+// GetSpecHistoricalData retrieves historical data for a spec over multiple dates, within the patch if any
+// The days of an ended patch are counted back from its end instead of the current date.
+func (s *SpecEvolutionMetricsAnalysisService) GetSpecHistoricalData(ctx context.Context, spec string, class *string, days int, isGlobal bool, encounterID *int, patch *warcraftlogsBuilds.GamePatch) ([]playerRankingModels.DailySpecMetricMythicPlus, error) {
 	query := `
 	SELECT *
 	FROM daily_spec_metrics_mythic_plus
 	WHERE spec = ?
 	AND is_global = ?
-	AND capture_date >= CURRENT_DATE - INTERVAL '%d days'
+	AND capture_date >= %s - INTERVAL '%d days'
 	`
 
 	if class != nil {
@@ -232,20 +262,30 @@ func (s *SpecEvolutionMetricsAnalysisService) GetSpecHistoricalData(ctx context.
 		query += " AND encounter_id = 0"
 	}
 
+	condition, patchParams := patchCondition("capture_date", patch)
+	query += condition
 	query += " ORDER BY capture_date"
 
-	query = fmt.Sprintf(query, days)
+	referenceDate := "CURRENT_DATE"
+	if patch != nil && patch.EndsAt != nil {
+		referenceDate = "?::date"
+	}
+	query = fmt.Sprintf(query, referenceDate, days)
 
 	var results []playerRankingModels.DailySpecMetricMythicPlus
 	var err error
 
 	params := []interface{}{spec, isGlobal}
+	if patch != nil && patch.EndsAt != nil {
+		params = append(params, *patch.EndsAt)
+	}
 	if class != nil {
 		params = append(params, *class)
 	}
 	if encounterID != nil {
 		params = append(params, *encounterID)
 	}
+	params = append(params, patchParams...)
 
 	err = s.db.WithContext(ctx).Raw(query, params...).Scan(&results).Error
 
@@ -282,19 +322,23 @@ func (s *SpecEvolutionMetricsAnalysisService) GetAvailableDungeons(ctx context.C
 	return results, nil
 }
 
-// GetTopSpecsForDungeon retrieves the top performing specs for a specific dungeon
-func (s *SpecEvolutionMetricsAnalysisService) GetTopSpecsForDungeon(ctx context.Context, dungeonID int, limit int) ([]playerRankingModels.DailySpecMetricMythicPlus, error) {
+// GetTopSpecsForDungeon retrieves the top performing specs for a specific dungeon, within the patch if any
+func (s *SpecEvolutionMetricsAnalysisService) GetTopSpecsForDungeon(ctx context.Context, dungeonID int, limit int, patch *warcraftlogsBuilds.GamePatch) ([]playerRankingModels.DailySpecMetricMythicPlus, error) {
+	condition, patchParams := patchCondition("capture_date", patch)
 	query := `
 	SELECT *
 	FROM daily_spec_metrics_mythic_plus
 	WHERE encounter_id = ?
-	AND is_global = false
+	AND is_global = false` + condition + `
 	ORDER BY avg_score DESC
 	LIMIT ?
 	`
 
+	params := append([]interface{}{dungeonID}, patchParams...)
+	params = append(params, limit)
+
 	var results []playerRankingModels.DailySpecMetricMythicPlus
-	err := s.db.WithContext(ctx).Raw(query, dungeonID, limit).Scan(&results).Error
+	err := s.db.WithContext(ctx).Raw(query, params...).Scan(&results).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top specs for dungeon: %w", err)
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 )
 
 // BuildAnalysisService handles analysis for build optimization and statistics
@@ -109,10 +112,22 @@ type GemUsage struct {
 	Rank             int64     `json:"rank"`
 }
 
+// mythicPlusEncounters restricts a statistics query to the Mythic+ dungeons, excluding the raid bosses
+// alias is the alias of the statistics table in the query
+func mythicPlusEncounters(alias string) string {
+	return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM raid_encounters re WHERE re.encounter_id = %s.encounter_id)", alias)
+}
+
 // GetGemUsage retrieves gem usage statistics for a specific class and spec
+func (s *BuildAnalysisService) GetGemUsage(ctx context.Context, class, spec string) ([]GemUsage, error) {
+	return s.getGemUsage(ctx, mythicPlusEncounters("bs"), class, spec)
+}
+
+// getGemUsage retrieves gem usage statistics for a specific class and spec, restricted by the encounter condition
+// Extra arguments of the condition are numbered from $3
 // Using raw SQL query to avoid deserialization issues with GORM
 // Deserialization issues are due to the fact that GORM is not able to deserialize the arrays correctly
-func (s *BuildAnalysisService) GetGemUsage(ctx context.Context, class, spec string) ([]GemUsage, error) {
+func (s *BuildAnalysisService) getGemUsage(ctx context.Context, encounterCondition string, args ...interface{}) ([]GemUsage, error) {
 	// Query to explicitly convert arrays to JSON
 	query := `
 	SELECT 
@@ -127,6 +142,7 @@ func (s *BuildAnalysisService) GetGemUsage(ctx context.Context, class, spec stri
 					ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC)::BIGINT as rank
 	FROM build_statistics bs
 	WHERE bs.class = $1 AND bs.spec = $2
+	AND ` + encounterCondition + `
 	AND bs.has_gems = true
 	GROUP BY bs.item_slot, bs.gems_count, bs.gem_ids, bs.gem_icons, bs.gem_levels
 	ORDER BY bs.item_slot, ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC)
@@ -139,7 +155,7 @@ func (s *BuildAnalysisService) GetGemUsage(ctx context.Context, class, spec stri
 	}
 
 	// Use the standard SQL database
-	rows, err := sqlDB.QueryContext(ctx, query, class, spec)
+	rows, err := sqlDB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get gem usage: %w", err)
 	}
@@ -264,6 +280,40 @@ func (s *BuildAnalysisService) GetStatPriorities(ctx context.Context, class, spe
 	return stats, nil
 }
 
+// StatWeight represents the estimated weight of a secondary stat, relative to the best stat of the spec
+// The confidence interval is the 95% interval of the weight.
+type StatWeight struct {
+	Metric      string  `json:"metric"`
+	StatName    string  `json:"stat_name"`
+	Weight      float64 `json:"weight"`
+	CILower     float64 `json:"ci_lower"`
+	CIUpper     float64 `json:"ci_upper"`
+	Coefficient float64 `json:"coefficient"`
+	StdError    float64 `json:"std_error"`
+	SampleSize  int     `json:"sample_size"`
+	RSquared    float64 `json:"r_squared"`
+}
+
+// GetStatWeights retrieves the stat weights of a specific class and spec, ordered by weight
+// An empty metric returns the weights of every metric
+func (s *BuildAnalysisService) GetStatWeights(ctx context.Context, class, spec, metric string) ([]StatWeight, error) {
+	var weights []StatWeight
+
+	query := s.db.WithContext(ctx).
+		Table("stat_weight_statistics").
+		Select("metric, stat_name, weight, ci_lower, ci_upper, coefficient, std_error, sample_size, r_squared").
+		Where("class = ? AND spec = ? AND deleted_at IS NULL", class, spec)
+
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	if err := query.Order("metric ASC, weight DESC").Scan(&weights).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stat weights: %w", err)
+	}
+	return weights, nil
+}
+
 // ItemDetails represents details about a specific item in the optimal build
 type ItemDetails struct {
 	Name       string `json:"name"`
@@ -281,19 +331,27 @@ type OptimalBuild struct {
 
 // GetOptimalBuild retrieves the optimal build for a specific class and spec
 func (s *BuildAnalysisService) GetOptimalBuild(ctx context.Context, class, spec string) (*OptimalBuild, error) {
+	return s.getOptimalBuild(ctx, mythicPlusEncounters, class, spec)
+}
+
+// getOptimalBuild retrieves the optimal build for a specific class and spec, restricted by the encounter condition
+// encounterCondition builds the condition for a table alias, its extra arguments follow the class and spec
+func (s *BuildAnalysisService) getOptimalBuild(ctx context.Context, encounterCondition func(alias string) string, class, spec string, args ...interface{}) (*OptimalBuild, error) {
+	queryArgs := append([]interface{}{class, spec}, args...)
+
 	// 1. Get the most popular talent import
 	var topTalent struct {
 		TalentImport string
 	}
 	talentQuery := `
 	SELECT talent_import
-	FROM talent_statistics
-	WHERE class = ? AND spec = ?
+	FROM talent_statistics ts
+	WHERE class = ? AND spec = ? AND ` + encounterCondition("ts") + `
 	GROUP BY talent_import
 	ORDER BY SUM(usage_count) DESC
 	LIMIT 1
 	`
-	err := s.db.WithContext(ctx).Raw(talentQuery, class, spec).Scan(&topTalent).Error
+	err := s.db.WithContext(ctx).Raw(talentQuery, queryArgs...).Scan(&topTalent).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top talent import: %w", err)
 	}
@@ -306,12 +364,12 @@ func (s *BuildAnalysisService) GetOptimalBuild(ctx context.Context, class, spec
 	SELECT STRING_AGG(stat_name, ' > ' ORDER BY avg_value DESC) as stat_priority
 	FROM (
 			SELECT stat_name, AVG(avg_value) as avg_value
-			FROM stat_statistics
-			WHERE class = ? AND spec = ? AND stat_category = 'secondary'
+			FROM stat_statistics ss
+			WHERE class = ? AND spec = ? AND stat_category = 'secondary' AND ` + encounterCondition("ss") + `
 			GROUP BY stat_name
 	) s
 	`
-	err = s.db.WithContext(ctx).Raw(statQuery, class, spec).Scan(&statPriority).Error
+	err = s.db.WithContext(ctx).Raw(statQuery, queryArgs...).Scan(&statPriority).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stat priority: %w", err)
 	}
@@ -329,11 +387,11 @@ func (s *BuildAnalysisService) GetOptimalBuild(ctx context.Context, class, spec
 	itemQuery := `
 	SELECT DISTINCT ON (item_slot)
 			item_slot, item_name, item_icon, item_quality, usage_count
-	FROM build_statistics
-	WHERE class = ? AND spec = ?
+	FROM build_statistics bs
+	WHERE class = ? AND spec = ? AND ` + encounterCondition("bs") + `
 	ORDER BY item_slot, usage_count DESC
 	`
-	err = s.db.WithContext(ctx).Raw(itemQuery, class, spec).Scan(&items).Error
+	err = s.db.WithContext(ctx).Raw(itemQuery, queryArgs...).Scan(&items).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top items: %w", err)
 	}
@@ -397,3 +455,615 @@ func (s *BuildAnalysisService) GetClassSpecSummary(ctx context.Context, class, s
 	}
 	return &summary, nil
 }
+
+// PerformancePercentiles represents the DPS or HPS distribution of a spec for a dungeon and a key level bracket
+type PerformancePercentiles struct {
+	EncounterID      int     `json:"encounter_id"`
+	KeyLevelBracket  string  `json:"key_level_bracket"`
+	Metric           string  `json:"metric"`
+	Role             string  `json:"role"`
+	SampleSize       int     `json:"sample_size"`
+	AvgValue         float64 `json:"avg_value"`
+	MinValue         float64 `json:"min_value"`
+	MaxValue         float64 `json:"max_value"`
+	P25              float64 `json:"p25"`
+	P50              float64 `json:"p50"`
+	P75              float64 `json:"p75"`
+	P95              float64 `json:"p95"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+}
+
+// GetPerformancePercentiles retrieves the DPS and HPS percentiles of a specific class and spec
+// An empty metric returns both DPS and HPS, a nil encounter ID returns every dungeon
+func (s *BuildAnalysisService) GetPerformancePercentiles(ctx context.Context, class, spec string, encounterID *int, bracket, metric string) ([]PerformancePercentiles, error) {
+	var percentiles []PerformancePercentiles
+
+	query := s.db.WithContext(ctx).
+		Table("performance_statistics").
+		Select("encounter_id, key_level_bracket, metric, role, sample_size, avg_value, min_value, max_value, p25, p50, p75, p95, avg_keystone_level").
+		Where("class = ? AND spec = ? AND key_level_bracket = ? AND deleted_at IS NULL", class, spec, bracket)
+
+	if encounterID != nil {
+		query = query.Where("encounter_id = ?", *encounterID)
+	}
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	if err := query.Order("encounter_id ASC, metric ASC").Scan(&percentiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get performance percentiles: %w", err)
+	}
+	return percentiles, nil
+}
+
+// AbilityUsagePercentiles represents the cooldown, interrupt or dispel usage distribution of a spec for a dungeon and a key level bracket
+// RolePercentile ranks the median of the spec among the specs of the same role, from 0 (lowest) to 100 (highest).
+type AbilityUsagePercentiles struct {
+	EncounterID      int     `json:"encounter_id"`
+	KeyLevelBracket  string  `json:"key_level_bracket"`
+	Metric           string  `json:"metric"`
+	Role             string  `json:"role"`
+	SampleSize       int     `json:"sample_size"`
+	AvgValue         float64 `json:"avg_value"`
+	MinValue         float64 `json:"min_value"`
+	MaxValue         float64 `json:"max_value"`
+	P25              float64 `json:"p25"`
+	P50              float64 `json:"p50"`
+	P75              float64 `json:"p75"`
+	P95              float64 `json:"p95"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+	RolePercentile   float64 `json:"role_percentile"`
+	RoleSpecCount    int     `json:"role_spec_count"`
+}
+
+// GetAbilityUsage retrieves the major cooldowns per minute, interrupts and dispels percentiles of a specific class and spec
+// An empty metric returns every metric, a nil encounter ID returns every dungeon
+func (s *BuildAnalysisService) GetAbilityUsage(ctx context.Context, class, spec string, encounterID *int, bracket, metric string) ([]AbilityUsagePercentiles, error) {
+	var percentiles []AbilityUsagePercentiles
+
+	query := `
+	WITH ranked AS (
+		SELECT
+			class, spec, encounter_id, key_level_bracket, metric, role, sample_size,
+			avg_value, min_value, max_value, p25, p50, p75, p95, avg_keystone_level,
+			COALESCE(ROUND(CAST(100 * PERCENT_RANK() OVER (PARTITION BY encounter_id, key_level_bracket, metric, role ORDER BY p50) AS numeric), 2), 0) as role_percentile,
+			COUNT(*) OVER (PARTITION BY encounter_id, key_level_bracket, metric, role) as role_spec_count
+		FROM ability_usage_statistics
+		WHERE key_level_bracket = ? AND deleted_at IS NULL
+	)
+	SELECT
+		encounter_id, key_level_bracket, metric, role, sample_size,
+		avg_value, min_value, max_value, p25, p50, p75, p95, avg_keystone_level,
+		role_percentile, role_spec_count
+	FROM ranked
+	WHERE class = ? AND spec = ?`
+	args := []interface{}{bracket, class, spec}
+
+	if encounterID != nil {
+		query += " AND encounter_id = ?"
+		args = append(args, *encounterID)
+	}
+	if metric != "" {
+		query += " AND metric = ?"
+		args = append(args, metric)
+	}
+	query += " ORDER BY encounter_id ASC, metric ASC"
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&percentiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ability usage: %w", err)
+	}
+	return percentiles, nil
+}
+
+// RunPacingSegment represents a trash or boss segment of a run, compared with the timed runs of the same dungeon and key level
+// Offsets and durations are in milliseconds. Medians and deltas are nil when no other timed run has the segment.
+type RunPacingSegment struct {
+	SegmentIndex    int      `json:"segment_index"`
+	SegmentType     string   `json:"segment_type"`
+	BossEncounterID int      `json:"boss_encounter_id"`
+	Name            string   `json:"name"`
+	StartOffset     int64    `json:"start_offset"`
+	EndOffset       int64    `json:"end_offset"`
+	Duration        int64    `json:"duration"`
+	SampleSize      int      `json:"sample_size"`
+	MedianDuration  *float64 `json:"median_duration"`
+	MedianEndOffset *float64 `json:"median_end_offset"`
+	DurationDelta   *float64 `json:"duration_delta"`   // Positive when the segment took longer than the median
+	EndOffsetDelta  *float64 `json:"end_offset_delta"` // Positive when the run is behind the median at the end of the segment
+}
+
+// RunPacing represents the pacing timeline of a run
+type RunPacing struct {
+	ReportCode    string             `json:"report_code"`
+	FightID       int                `json:"fight_id"`
+	EncounterID   int                `json:"encounter_id"`
+	KeystoneLevel int                `json:"keystone_level"`
+	KeystoneTime  int64              `json:"keystone_time"`
+	TimerMS       int64              `json:"timer_ms"`
+	Timed         bool               `json:"timed"`
+	Segments      []RunPacingSegment `json:"segments"`
+}
+
+// GetRunPacing retrieves the pacing timeline of a run with the delta of each segment versus the median timed run
+// Segments are matched on their type and boss, so routes killing the bosses in another order are still compared.
+// Returns nil if the run has no pacing.
+func (s *BuildAnalysisService) GetRunPacing(ctx context.Context, code string, fightID int) (*RunPacing, error) {
+	var rows []struct {
+		RunPacingSegment
+		EncounterID   int
+		KeystoneLevel int
+		KeystoneTime  int64
+		TimerMS       int64
+		Timed         bool
+	}
+
+	query := `
+	SELECT
+			s.encounter_id, s.keystone_level, s.keystone_time, s.timer_ms, s.timed,
+			s.segment_index, s.segment_type, s.boss_encounter_id, s.name,
+			s.start_offset, s.end_offset, s.duration,
+			m.sample_size, m.median_duration, m.median_end_offset
+	FROM run_pacing_segments s
+	LEFT JOIN LATERAL (
+			SELECT
+					COUNT(*) as sample_size,
+					PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY t.duration) as median_duration,
+					PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY t.end_offset) as median_end_offset
+			FROM run_pacing_segments t
+			WHERE t.encounter_id = s.encounter_id
+				AND t.keystone_level = s.keystone_level
+				AND t.segment_type = s.segment_type
+				AND t.boss_encounter_id = s.boss_encounter_id
+				AND t.timed
+				AND t.deleted_at IS NULL
+				AND NOT (t.report_code = s.report_code AND t.fight_id = s.fight_id)
+	) m ON TRUE
+	WHERE s.report_code = ? AND s.fight_id = ? AND s.deleted_at IS NULL
+	ORDER BY s.segment_index ASC`
+
+	if err := s.db.WithContext(ctx).Raw(query, code, fightID).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get run pacing: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	pacing := &RunPacing{
+		ReportCode:    code,
+		FightID:       fightID,
+		EncounterID:   rows[0].EncounterID,
+		KeystoneLevel: rows[0].KeystoneLevel,
+		KeystoneTime:  rows[0].KeystoneTime,
+		TimerMS:       rows[0].TimerMS,
+		Timed:         rows[0].Timed,
+		Segments:      make([]RunPacingSegment, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		segment := row.RunPacingSegment
+		if segment.MedianDuration != nil {
+			delta := float64(segment.Duration) - *segment.MedianDuration
+			segment.DurationDelta = &delta
+		}
+		if segment.MedianEndOffset != nil {
+			delta := float64(segment.EndOffset) - *segment.MedianEndOffset
+			segment.EndOffsetDelta = &delta
+		}
+		pacing.Segments = append(pacing.Segments, segment)
+	}
+
+	return pacing, nil
+}
+
+// BossPacing represents the median pacing to a boss of a dungeon for a key level
+// Times are in milliseconds from the start of the run. The timed medians only use the runs completed within the timer.
+type BossPacing struct {
+	KeystoneLevel         int      `json:"keystone_level"`
+	BossEncounterID       int      `json:"boss_encounter_id"`
+	Name                  string   `json:"name"`
+	SampleSize            int      `json:"sample_size"`
+	TimedSampleSize       int      `json:"timed_sample_size"`
+	MedianTimeToBoss      *float64 `json:"median_time_to_boss"`
+	MedianTimedTimeToBoss *float64 `json:"median_timed_time_to_boss"`
+	MedianBossDuration    *float64 `json:"median_boss_duration"`
+	MedianTrashBefore     *float64 `json:"median_trash_before"`
+}
+
+// GetDungeonPacing retrieves the median time to each boss of a dungeon per key level
+// The time to a boss is the time of its kill. A nil key level returns every key level.
+func (s *BuildAnalysisService) GetDungeonPacing(ctx context.Context, encounterID int, keystoneLevel *int) ([]BossPacing, error) {
+	var pacing []BossPacing
+
+	query := `
+	SELECT
+			keystone_level,
+			boss_encounter_id,
+			MAX(name) as name,
+			COUNT(*) FILTER (WHERE segment_type = 'boss') as sample_size,
+			COUNT(*) FILTER (WHERE segment_type = 'boss' AND timed) as timed_sample_size,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY end_offset) FILTER (WHERE segment_type = 'boss') as median_time_to_boss,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY end_offset) FILTER (WHERE segment_type = 'boss' AND timed) as median_timed_time_to_boss,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration) FILTER (WHERE segment_type = 'boss') as median_boss_duration,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration) FILTER (WHERE segment_type = 'trash') as median_trash_before
+	FROM run_pacing_segments
+	WHERE encounter_id = ? AND boss_encounter_id > 0 AND deleted_at IS NULL`
+	args := []interface{}{encounterID}
+
+	if keystoneLevel != nil {
+		query += " AND keystone_level = ?"
+		args = append(args, *keystoneLevel)
+	}
+	query += `
+	GROUP BY keystone_level, boss_encounter_id
+	HAVING COUNT(*) FILTER (WHERE segment_type = 'boss') > 0
+	ORDER BY keystone_level DESC, median_time_to_boss ASC`
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&pacing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get dungeon pacing: %w", err)
+	}
+	return pacing, nil
+}
+
+// ValidationReasonCount represents the number of builds rejected for a reason
+type ValidationReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// SpecValidationRate represents the validation rate of the builds of a class and spec
+// The validation rate is the percentage of extracted builds that passed the validation.
+type SpecValidationRate struct {
+	Class          string                  `json:"class"`
+	Spec           string                  `json:"spec"`
+	ValidBuilds    int                     `json:"valid_builds"`
+	RejectedBuilds int                     `json:"rejected_builds"`
+	ValidationRate float64                 `json:"validation_rate"`
+	Reasons        []ValidationReasonCount `json:"reasons"`
+}
+
+// GetBuildValidationRates retrieves the validation rate of the builds extracted during the last days per class and spec
+// Empty class and spec return every spec, sorted from the lowest validation rate.
+func (s *BuildAnalysisService) GetBuildValidationRates(ctx context.Context, class, spec string, days int) ([]SpecValidationRate, error) {
+	var rates []SpecValidationRate
+
+	filter := "created_at >= NOW() - make_interval(days => ?) AND deleted_at IS NULL"
+	filterArgs := []interface{}{days}
+	if class != "" {
+		filter += " AND class = ?"
+		filterArgs = append(filterArgs, class)
+	}
+	if spec != "" {
+		filter += " AND spec = ?"
+		filterArgs = append(filterArgs, spec)
+	}
+
+	query := `
+	WITH valid AS (
+			SELECT class, spec, COUNT(*) as valid_builds
+			FROM player_builds
+			WHERE ` + filter + `
+			GROUP BY class, spec
+	),
+	rejected AS (
+			SELECT class, spec, COUNT(*) as rejected_builds
+			FROM quarantined_player_builds
+			WHERE ` + filter + `
+			GROUP BY class, spec
+	)
+	SELECT
+			COALESCE(v.class, r.class) as class,
+			COALESCE(v.spec, r.spec) as spec,
+			COALESCE(v.valid_builds, 0) as valid_builds,
+			COALESCE(r.rejected_builds, 0) as rejected_builds,
+			ROUND(100.0 * COALESCE(v.valid_builds, 0) / (COALESCE(v.valid_builds, 0) + COALESCE(r.rejected_builds, 0)), 2) as validation_rate
+	FROM valid v
+	FULL OUTER JOIN rejected r ON r.class = v.class AND r.spec = v.spec
+	ORDER BY validation_rate ASC, class, spec`
+	args := append(append([]interface{}{}, filterArgs...), filterArgs...)
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get build validation rates: %w", err)
+	}
+
+	var reasons []struct {
+		Class  string
+		Spec   string
+		Reason string
+		Count  int
+	}
+	reasonsQuery := `
+	SELECT class, spec, reason, COUNT(*) as count
+	FROM quarantined_player_builds, unnest(reasons) as reason
+	WHERE ` + filter + `
+	GROUP BY class, spec, reason
+	ORDER BY count DESC, reason`
+
+	if err := s.db.WithContext(ctx).Raw(reasonsQuery, filterArgs...).Scan(&reasons).Error; err != nil {
+		return nil, fmt.Errorf("failed to get build validation reasons: %w", err)
+	}
+
+	reasonsBySpec := make(map[string][]ValidationReasonCount)
+	for _, reason := range reasons {
+		key := reason.Class + "-" + reason.Spec
+		reasonsBySpec[key] = append(reasonsBySpec[key], ValidationReasonCount{Reason: reason.Reason, Count: reason.Count})
+	}
+	for i := range rates {
+		rates[i].Reasons = reasonsBySpec[rates[i].Class+"-"+rates[i].Spec]
+		if rates[i].Reasons == nil {
+			rates[i].Reasons = []ValidationReasonCount{}
+		}
+	}
+
+	return rates, nil
+}
+
+// RunValidationRate represents the validation rate of the builds of a run
+type RunValidationRate struct {
+	ReportCode     string         `json:"report_code"`
+	FightID        int            `json:"fight_id"`
+	EncounterID    int            `json:"encounter_id"`
+	KeystoneLevel  int            `json:"keystone_level"`
+	ValidBuilds    int            `json:"valid_builds"`
+	RejectedBuilds int            `json:"rejected_builds"`
+	ValidationRate float64        `json:"validation_rate"`
+	Reasons        pq.StringArray `json:"reasons" gorm:"type:text[]"`
+}
+
+// GetRunValidationRates retrieves the validation rate of the runs extracted during the last days
+// Runs are sorted from the highest number of rejected builds. A nil encounter ID returns every dungeon.
+func (s *BuildAnalysisService) GetRunValidationRates(ctx context.Context, encounterID *int, days, limit int) ([]RunValidationRate, error) {
+	var rates []RunValidationRate
+
+	filter := "created_at >= NOW() - make_interval(days => ?) AND deleted_at IS NULL"
+	filterArgs := []interface{}{days}
+	if encounterID != nil {
+		filter += " AND encounter_id = ?"
+		filterArgs = append(filterArgs, *encounterID)
+	}
+
+	query := `
+	WITH valid AS (
+			SELECT report_code, fight_id, MAX(encounter_id) as encounter_id, MAX(keystone_level) as keystone_level, COUNT(*) as valid_builds
+			FROM player_builds
+			WHERE ` + filter + `
+			GROUP BY report_code, fight_id
+	),
+	rejected AS (
+			SELECT
+					report_code, fight_id,
+					MAX(encounter_id) as encounter_id,
+					MAX(keystone_level) as keystone_level,
+					COUNT(DISTINCT id) as rejected_builds,
+					ARRAY_AGG(DISTINCT reason ORDER BY reason) as reasons
+			FROM quarantined_player_builds, unnest(reasons) as reason
+			WHERE ` + filter + `
+			GROUP BY report_code, fight_id
+	)
+	SELECT
+			COALESCE(v.report_code, r.report_code) as report_code,
+			COALESCE(v.fight_id, r.fight_id) as fight_id,
+			COALESCE(v.encounter_id, r.encounter_id) as encounter_id,
+			COALESCE(v.keystone_level, r.keystone_level) as keystone_level,
+			COALESCE(v.valid_builds, 0) as valid_builds,
+			COALESCE(r.rejected_builds, 0) as rejected_builds,
+			ROUND(100.0 * COALESCE(v.valid_builds, 0) / (COALESCE(v.valid_builds, 0) + COALESCE(r.rejected_builds, 0)), 2) as validation_rate,
+			COALESCE(r.reasons, ARRAY[]::TEXT[]) as reasons
+	FROM valid v
+	FULL OUTER JOIN rejected r ON r.report_code = v.report_code AND r.fight_id = v.fight_id
+	ORDER BY rejected_builds DESC, validation_rate ASC, report_code, fight_id
+	LIMIT ?`
+	args := append(append([]interface{}{}, filterArgs...), filterArgs...)
+	args = append(args, limit)
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get run validation rates: %w", err)
+	}
+	return rates, nil
+}
+
+// ConsumableUsage represents the potion and healthstone usage of a spec for a dungeon
+// EncounterID is 0 for the row aggregating every dungeon. Usage rates are the percentage of players using at least one.
+// Correlations are Pearson coefficients, nil when they cannot be computed (no variance or not enough samples).
+type ConsumableUsage struct {
+	EncounterID      int     `json:"encounter_id"`
+	SampleSize       int     `json:"sample_size"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+
+	AvgPotionUse         float64  `json:"avg_potion_use"`
+	PotionUsageRate      float64  `json:"potion_usage_rate"`
+	AvgPotionUseTimed    *float64 `json:"avg_potion_use_timed"`
+	AvgPotionUseDepleted *float64 `json:"avg_potion_use_depleted"`
+	PotionKeyLevelCorr   *float64 `json:"potion_key_level_correlation"`
+	PotionSuccessCorr    *float64 `json:"potion_success_correlation"`
+
+	AvgHealthstoneUse         float64  `json:"avg_healthstone_use"`
+	HealthstoneUsageRate      float64  `json:"healthstone_usage_rate"`
+	AvgHealthstoneUseTimed    *float64 `json:"avg_healthstone_use_timed"`
+	AvgHealthstoneUseDepleted *float64 `json:"avg_healthstone_use_depleted"`
+	HealthstoneKeyLevelCorr   *float64 `json:"healthstone_key_level_correlation"`
+	HealthstoneSuccessCorr    *float64 `json:"healthstone_success_correlation"`
+}
+
+// GetConsumableUsage retrieves the consumable usage of a specific class and spec per dungeon
+// The success of a run comes from its group composition, runs without composition only count for the key level figures.
+// A nil encounter ID returns every dungeon, followed by the row aggregating them.
+func (s *BuildAnalysisService) GetConsumableUsage(ctx context.Context, class, spec string, encounterID *int, bracket string) ([]ConsumableUsage, error) {
+	var usages []ConsumableUsage
+
+	query := `
+	SELECT
+			COALESCE(pb.encounter_id, 0) as encounter_id,
+			COUNT(*) as sample_size,
+			COALESCE(AVG(pb.keystone_level), 0) as avg_keystone_level,
+			COALESCE(AVG(pb.potion_use), 0) as avg_potion_use,
+			COALESCE(ROUND(100.0 * AVG(CASE WHEN pb.potion_use > 0 THEN 1 ELSE 0 END), 2), 0) as potion_usage_rate,
+			AVG(pb.potion_use) FILTER (WHERE gc.success) as avg_potion_use_timed,
+			AVG(pb.potion_use) FILTER (WHERE NOT gc.success) as avg_potion_use_depleted,
+			CORR(pb.potion_use, pb.keystone_level) as potion_key_level_corr,
+			CORR(pb.potion_use, CASE WHEN gc.success THEN 1 WHEN NOT gc.success THEN 0 END) as potion_success_corr,
+			COALESCE(AVG(pb.healthstone_use), 0) as avg_healthstone_use,
+			COALESCE(ROUND(100.0 * AVG(CASE WHEN pb.healthstone_use > 0 THEN 1 ELSE 0 END), 2), 0) as healthstone_usage_rate,
+			AVG(pb.healthstone_use) FILTER (WHERE gc.success) as avg_healthstone_use_timed,
+			AVG(pb.healthstone_use) FILTER (WHERE NOT gc.success) as avg_healthstone_use_depleted,
+			CORR(pb.healthstone_use, pb.keystone_level) as healthstone_key_level_corr,
+			CORR(pb.healthstone_use, CASE WHEN gc.success THEN 1 WHEN NOT gc.success THEN 0 END) as healthstone_success_corr
+	FROM player_builds pb
+	LEFT JOIN group_compositions gc
+			ON gc.report_code = pb.report_code AND gc.fight_id = pb.fight_id AND gc.deleted_at IS NULL
+	WHERE pb.class = ? AND pb.spec = ?
+	AND pb.deleted_at IS NULL
+	AND pb.potion_use IS NOT NULL AND pb.healthstone_use IS NOT NULL
+	AND ` + mythicPlusEncounters("pb")
+	args := []interface{}{class, spec}
+
+	if encounterID != nil {
+		query += " AND pb.encounter_id = ?"
+		args = append(args, *encounterID)
+	}
+
+	minLevel, maxLevel := warcraftlogsBuilds.GetKeyLevelBracketBounds(bracket)
+	if minLevel > 0 {
+		query += " AND pb.keystone_level >= ?"
+		args = append(args, minLevel)
+	}
+	if maxLevel > 0 {
+		query += " AND pb.keystone_level <= ?"
+		args = append(args, maxLevel)
+	}
+
+	// The row aggregating every dungeon is only added when no dungeon is requested
+	if encounterID != nil {
+		query += " GROUP BY pb.encounter_id"
+	} else {
+		query += " GROUP BY GROUPING SETS ((pb.encounter_id), ())"
+	}
+	query += " HAVING COUNT(*) > 0 ORDER BY GROUPING(pb.encounter_id), pb.encounter_id"
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&usages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get consumable usage: %w", err)
+	}
+	return usages, nil
+}
+
+// Orderings of the items, enchants and gems routes
+const (
+	OrderPopularity  = "popularity"  // Most used choices first
+	OrderPerformance = "performance" // Choices over-represented in the top keys first
+)
+
+// IsValidOrder checks if an ordering is one of the known orderings
+func IsValidOrder(order string) bool {
+	return order == OrderPopularity || order == OrderPerformance
+}
+
+// ChoiceLift represents the usage of an item, enchant or gem among the top keys compared to the whole spec population
+// EncounterID is 0 when the choice is aggregated over every dungeon. Usage rates are percentages.
+type ChoiceLift struct {
+	EncounterID          int     `json:"encounter_id"`
+	ChoiceType           string  `json:"choice_type"`
+	ItemSlot             int     `json:"item_slot"`
+	ChoiceID             int     `json:"choice_id"`
+	ChoiceName           string  `json:"choice_name"`
+	ChoiceIcon           string  `json:"choice_icon"`
+	TopCount             int     `json:"top_count"`
+	TopSampleSize        int     `json:"top_sample_size"`
+	TopUsageRate         float64 `json:"top_usage_rate"`
+	PopulationCount      int     `json:"population_count"`
+	PopulationSampleSize int     `json:"population_sample_size"`
+	PopulationUsageRate  float64 `json:"population_usage_rate"`
+	Lift                 float64 `json:"lift"`
+	Confidence           float64 `json:"confidence"`
+	WeightedLift         float64 `json:"weighted_lift"`
+	Rank                 int64   `json:"rank"`
+}
+
+// GetPerformanceWeightedChoices retrieves the item, enchant or gem choices of a specific class and spec ordered by weighted lift per slot
+// A nil encounter ID sums the samples of every dungeon, the confidence is then the average weighted by the population.
+func (s *BuildAnalysisService) GetPerformanceWeightedChoices(ctx context.Context, class, spec, choiceType string, encounterID *int) ([]ChoiceLift, error) {
+	var choices []ChoiceLift
+
+	var query string
+	args := []interface{}{class, spec, choiceType}
+
+	if encounterID != nil {
+		query = `
+		SELECT
+				ils.encounter_id,
+				ils.choice_type,
+				ils.item_slot,
+				ils.choice_id,
+				ils.choice_name,
+				ils.choice_icon,
+				ils.top_count,
+				ils.top_sample_size,
+				ils.top_usage_rate,
+				ils.population_count,
+				ils.population_sample_size,
+				ils.population_usage_rate,
+				ils.lift,
+				ils.confidence,
+				ils.weighted_lift,
+				ROW_NUMBER() OVER (PARTITION BY ils.item_slot ORDER BY ils.weighted_lift DESC, ils.population_count DESC)::BIGINT as rank
+		FROM item_lift_statistics ils
+		WHERE ils.class = ? AND ils.spec = ? AND ils.choice_type = ?
+		AND ils.deleted_at IS NULL
+		AND ils.encounter_id = ?`
+		args = append(args, *encounterID)
+	} else {
+		// The samples of each dungeon are summed, so the rates are computed on the totals
+		query = `
+		WITH choices AS (
+				SELECT
+						ils.choice_type,
+						ils.item_slot,
+						ils.choice_id,
+						MAX(ils.choice_name) as choice_name,
+						MAX(ils.choice_icon) as choice_icon,
+						SUM(ils.top_count) as top_count,
+						SUM(ils.top_sample_size) as top_sample_size,
+						SUM(ils.population_count) as population_count,
+						SUM(ils.population_sample_size) as population_sample_size,
+						SUM(ils.confidence * ils.population_count) / NULLIF(SUM(ils.population_count), 0) as confidence
+				FROM item_lift_statistics ils
+				WHERE ils.class = ? AND ils.spec = ? AND ils.choice_type = ?
+				AND ils.deleted_at IS NULL
+				AND ` + mythicPlusEncounters("ils") + `
+				GROUP BY ils.choice_type, ils.item_slot, ils.choice_id
+		), rates AS (
+				SELECT
+						c.*,
+						100.0 * c.top_count / NULLIF(c.top_sample_size, 0) as top_usage_rate,
+						100.0 * c.population_count / NULLIF(c.population_sample_size, 0) as population_usage_rate
+				FROM choices c
+		), lifts AS (
+				SELECT
+						r.*,
+						COALESCE(r.top_usage_rate / NULLIF(r.population_usage_rate, 0), 0) as lift
+				FROM rates r
+		)
+		SELECT
+				0 as encounter_id,
+				l.choice_type,
+				l.item_slot,
+				l.choice_id,
+				l.choice_name,
+				l.choice_icon,
+				l.top_count,
+				l.top_sample_size,
+				COALESCE(l.top_usage_rate, 0) as top_usage_rate,
+				l.population_count,
+				l.population_sample_size,
+				COALESCE(l.population_usage_rate, 0) as population_usage_rate,
+				l.lift,
+				COALESCE(l.confidence, 0) as confidence,
+				1 + (l.lift - 1) * COALESCE(l.confidence, 0) as weighted_lift,
+				ROW_NUMBER() OVER (PARTITION BY l.item_slot ORDER BY 1 + (l.lift - 1) * COALESCE(l.confidence, 0) DESC, l.population_count DESC)::BIGINT as rank
+		FROM lifts l`
+	}
+	query += " ORDER BY item_slot, rank"
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&choices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get performance weighted %s choices: %w", choiceType, err)
+	}
+	return choices, nil
+}
@@ -0,0 +1,388 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// DungeonAnalysisService handles analysis of the combat data of the dungeons (deaths, damage taken...)
+type DungeonAnalysisService struct {
+	db *gorm.DB
+}
+
+// NewDungeonAnalysisService creates a new DungeonAnalysisService
+func NewDungeonAnalysisService(db *gorm.DB) *DungeonAnalysisService {
+	return &DungeonAnalysisService{db: db}
+}
+
+// DeathAbilityStat represents an ability killing players in a dungeon
+type DeathAbilityStat struct {
+	AbilityID        int     `json:"ability_id"`
+	AbilityName      string  `json:"ability_name"`
+	AbilityIcon      string  `json:"ability_icon"`
+	DeathCount       int     `json:"death_count"`
+	DeathsPerRun     float64 `json:"deaths_per_run"`
+	Percentage       float64 `json:"percentage"`
+	AvgDeathTime     float64 `json:"avg_death_time"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+}
+
+// DeathSpecStat represents the deaths of a spec in a dungeon
+type DeathSpecStat struct {
+	Class            string  `json:"class"`
+	Spec             string  `json:"spec"`
+	DeathCount       int     `json:"death_count"`
+	DeathsPerRun     float64 `json:"deaths_per_run"`
+	Percentage       float64 `json:"percentage"`
+	AvgDeathTime     float64 `json:"avg_death_time"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+}
+
+// DeathTimingStat represents the deaths happening during a part of the run
+type DeathTimingStat struct {
+	TimeBucketStart int     `json:"time_bucket_start"` // Percentage of the run elapsed
+	TimeBucketEnd   int     `json:"time_bucket_end"`
+	DeathCount      int     `json:"death_count"`
+	DeathsPerRun    float64 `json:"deaths_per_run"`
+	Percentage      float64 `json:"percentage"`
+	AvgDeathTime    float64 `json:"avg_death_time"`
+}
+
+// DungeonDeathAnalysis represents the death analysis of a dungeon for a key level bracket
+type DungeonDeathAnalysis struct {
+	EncounterID     int                `json:"encounter_id"`
+	KeyLevelBracket string             `json:"key_level_bracket"`
+	RunsAnalyzed    int                `json:"runs_analyzed"`
+	PeriodStart     *time.Time         `json:"period_start"`
+	PeriodEnd       *time.Time         `json:"period_end"`
+	TopAbilities    []DeathAbilityStat `json:"top_abilities"`
+	Specs           []DeathSpecStat    `json:"specs"`
+	Timings         []DeathTimingStat  `json:"timings"`
+}
+
+// GetDungeonDeathAnalysis retrieves the death analysis of a dungeon for a key level bracket
+func (s *DungeonAnalysisService) GetDungeonDeathAnalysis(ctx context.Context, encounterID int, bracket string, limit int) (*DungeonDeathAnalysis, error) {
+	analysis := &DungeonDeathAnalysis{
+		EncounterID:     encounterID,
+		KeyLevelBracket: bracket,
+		TopAbilities:    []DeathAbilityStat{},
+		Specs:           []DeathSpecStat{},
+		Timings:         []DeathTimingStat{},
+	}
+
+	// 1. Get the analysis window and the number of runs
+	var window struct {
+		RunsAnalyzed int
+		PeriodStart  *time.Time
+		PeriodEnd    *time.Time
+	}
+	windowQuery := `
+	SELECT MAX(runs_analyzed) as runs_analyzed, MIN(period_start) as period_start, MAX(period_end) as period_end
+	FROM death_statistics
+	WHERE encounter_id = ? AND key_level_bracket = ? AND deleted_at IS NULL
+	`
+	if err := s.db.WithContext(ctx).Raw(windowQuery, encounterID, bracket).Scan(&window).Error; err != nil {
+		return nil, fmt.Errorf("failed to get death analysis window: %w", err)
+	}
+	analysis.RunsAnalyzed = window.RunsAnalyzed
+	analysis.PeriodStart = window.PeriodStart
+	analysis.PeriodEnd = window.PeriodEnd
+
+	// 2. Get the abilities killing the most players
+	abilityQuery := `
+	SELECT ability_id, ability_name, ability_icon, death_count, deaths_per_run, percentage, avg_death_time, avg_keystone_level
+	FROM death_statistics
+	WHERE encounter_id = ? AND key_level_bracket = ? AND statistic_type = 'ability' AND deleted_at IS NULL
+	ORDER BY death_count DESC
+	LIMIT ?
+	`
+	if err := s.db.WithContext(ctx).Raw(abilityQuery, encounterID, bracket, limit).Scan(&analysis.TopAbilities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get top killing abilities: %w", err)
+	}
+
+	// 3. Get the specs dying the most
+	specQuery := `
+	SELECT class, spec, death_count, deaths_per_run, percentage, avg_death_time, avg_keystone_level
+	FROM death_statistics
+	WHERE encounter_id = ? AND key_level_bracket = ? AND statistic_type = 'spec' AND deleted_at IS NULL
+	ORDER BY death_count DESC
+	`
+	if err := s.db.WithContext(ctx).Raw(specQuery, encounterID, bracket).Scan(&analysis.Specs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get spec deaths: %w", err)
+	}
+
+	// 4. Get the moments of the run with the most deaths
+	timingQuery := `
+	SELECT time_bucket_start, time_bucket_end, death_count, deaths_per_run, percentage, avg_death_time
+	FROM death_statistics
+	WHERE encounter_id = ? AND key_level_bracket = ? AND statistic_type = 'timing' AND deleted_at IS NULL
+	ORDER BY time_bucket_start ASC
+	`
+	if err := s.db.WithContext(ctx).Raw(timingQuery, encounterID, bracket).Scan(&analysis.Timings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get death timings: %w", err)
+	}
+
+	return analysis, nil
+}
+
+// TopKiller represents one of the abilities killing the most players across all dungeons
+type TopKiller struct {
+	EncounterID      int     `json:"encounter_id"`
+	AbilityID        int     `json:"ability_id"`
+	AbilityName      string  `json:"ability_name"`
+	AbilityIcon      string  `json:"ability_icon"`
+	DeathCount       int     `json:"death_count"`
+	DeathsPerRun     float64 `json:"deaths_per_run"`
+	RunsAnalyzed     int     `json:"runs_analyzed"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+	Rank             int64   `json:"rank"`
+}
+
+// GetTopKillers retrieves the abilities killing the most players across all dungeons for the last analysis window
+// Deaths without a known killing ability are left out
+func (s *DungeonAnalysisService) GetTopKillers(ctx context.Context, bracket string, limit int) ([]TopKiller, error) {
+	var killers []TopKiller
+	query := `
+	SELECT
+		encounter_id,
+		ability_id,
+		ability_name,
+		ability_icon,
+		death_count,
+		deaths_per_run,
+		runs_analyzed,
+		avg_keystone_level,
+		ROW_NUMBER() OVER (ORDER BY death_count DESC)::BIGINT as rank
+	FROM death_statistics
+	WHERE key_level_bracket = ? AND statistic_type = 'ability' AND ability_id <> 0 AND deleted_at IS NULL
+	ORDER BY death_count DESC
+	LIMIT ?
+	`
+	if err := s.db.WithContext(ctx).Raw(query, bracket, limit).Scan(&killers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get top killers: %w", err)
+	}
+	return killers, nil
+}
+
+// DamageTakenAbilityStat represents an enemy ability dealing damage to the players in a dungeon
+type DamageTakenAbilityStat struct {
+	AbilityID          int     `json:"ability_id"`
+	AbilityName        string  `json:"ability_name"`
+	AbilityIcon        string  `json:"ability_icon"`
+	AbilityType        int     `json:"ability_type"`
+	AvgTotalDamage     float64 `json:"avg_total_damage"`
+	AvgDamagePerSecond float64 `json:"avg_damage_per_second"`
+	AvgNormalizedDPS   float64 `json:"avg_normalized_dps"`
+	Percentage         float64 `json:"percentage"`
+	OccurrenceRate     float64 `json:"occurrence_rate"`
+	AvgKeystoneLevel   float64 `json:"avg_keystone_level"`
+}
+
+// DamageTakenSpecStat represents the damage taken from an ability in the runs including a spec
+type DamageTakenSpecStat struct {
+	AbilityID         int     `json:"ability_id"`
+	Role              string  `json:"role"`
+	Class             string  `json:"class"`
+	Spec              string  `json:"spec"`
+	AvgNormalizedDPS  float64 `json:"avg_normalized_dps"`
+	RelativeToAverage float64 `json:"relative_to_average"`
+	OccurrenceRate    float64 `json:"occurrence_rate"`
+	RunsAnalyzed      int     `json:"runs_analyzed"`
+	AvgKeystoneLevel  float64 `json:"avg_keystone_level"`
+}
+
+// DamageTakenHotspot represents an enemy ability with the comparison of the specs taking damage from it
+type DamageTakenHotspot struct {
+	DamageTakenAbilityStat
+	Specs []DamageTakenSpecStat `json:"specs"`
+}
+
+// DungeonDamageTakenAnalysis represents the damage taken analysis of a dungeon for a key level bracket
+type DungeonDamageTakenAnalysis struct {
+	EncounterID     int                  `json:"encounter_id"`
+	KeyLevelBracket string               `json:"key_level_bracket"`
+	Role            string               `json:"role,omitempty"`
+	RunsAnalyzed    int                  `json:"runs_analyzed"`
+	PeriodStart     *time.Time           `json:"period_start"`
+	PeriodEnd       *time.Time           `json:"period_end"`
+	Hotspots        []DamageTakenHotspot `json:"hotspots"`
+}
+
+// GetDungeonDamageTakenAnalysis retrieves the most damaging abilities of a dungeon for a key level bracket
+// Each ability comes with the damage taken in the runs including each spec, optionally filtered on a role.
+// A relative_to_average above 1 means the groups with this spec take more damage than average from the ability.
+func (s *DungeonAnalysisService) GetDungeonDamageTakenAnalysis(ctx context.Context, encounterID int, bracket, role string, limit int) (*DungeonDamageTakenAnalysis, error) {
+	analysis := &DungeonDamageTakenAnalysis{
+		EncounterID:     encounterID,
+		KeyLevelBracket: bracket,
+		Role:            role,
+		Hotspots:        []DamageTakenHotspot{},
+	}
+
+	// 1. Get the analysis window and the number of runs
+	var window struct {
+		RunsAnalyzed int
+		PeriodStart  *time.Time
+		PeriodEnd    *time.Time
+	}
+	windowQuery := `
+	SELECT MAX(runs_analyzed) as runs_analyzed, MIN(period_start) as period_start, MAX(period_end) as period_end
+	FROM damage_taken_statistics
+	WHERE encounter_id = ? AND key_level_bracket = ? AND statistic_type = 'ability' AND deleted_at IS NULL
+	`
+	if err := s.db.WithContext(ctx).Raw(windowQuery, encounterID, bracket).Scan(&window).Error; err != nil {
+		return nil, fmt.Errorf("failed to get damage taken analysis window: %w", err)
+	}
+	analysis.RunsAnalyzed = window.RunsAnalyzed
+	analysis.PeriodStart = window.PeriodStart
+	analysis.PeriodEnd = window.PeriodEnd
+
+	// 2. Get the most damaging abilities, normalized by key level and run duration
+	var abilities []DamageTakenAbilityStat
+	abilityQuery := `
+	SELECT ability_id, ability_name, ability_icon, ability_type, avg_total_damage, avg_damage_per_second,
+		avg_normalized_dps, percentage, occurrence_rate, avg_keystone_level
+	FROM damage_taken_statistics
+	WHERE encounter_id = ? AND key_level_bracket = ? AND statistic_type = 'ability' AND deleted_at IS NULL
+	ORDER BY avg_normalized_dps DESC
+	LIMIT ?
+	`
+	if err := s.db.WithContext(ctx).Raw(abilityQuery, encounterID, bracket, limit).Scan(&abilities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get damage taken abilities: %w", err)
+	}
+	if len(abilities) == 0 {
+		return analysis, nil
+	}
+
+	// 3. Get the spec comparison of these abilities
+	abilityIDs := make([]int, 0, len(abilities))
+	for _, ability := range abilities {
+		abilityIDs = append(abilityIDs, ability.AbilityID)
+	}
+
+	var specs []DamageTakenSpecStat
+	specQuery := s.db.WithContext(ctx).
+		Table("damage_taken_statistics").
+		Select("ability_id, role, class, spec, avg_normalized_dps, relative_to_average, occurrence_rate, runs_analyzed, avg_keystone_level").
+		Where("encounter_id = ? AND key_level_bracket = ? AND statistic_type = 'spec' AND deleted_at IS NULL", encounterID, bracket).
+		Where("ability_id IN ?", abilityIDs)
+	if role != "" {
+		specQuery = specQuery.Where("role = ?", role)
+	}
+	if err := specQuery.Order("relative_to_average DESC").Scan(&specs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get damage taken spec comparison: %w", err)
+	}
+
+	specsByAbility := make(map[int][]DamageTakenSpecStat)
+	for _, spec := range specs {
+		specsByAbility[spec.AbilityID] = append(specsByAbility[spec.AbilityID], spec)
+	}
+
+	for _, ability := range abilities {
+		hotspot := DamageTakenHotspot{
+			DamageTakenAbilityStat: ability,
+			Specs:                  specsByAbility[ability.AbilityID],
+		}
+		if hotspot.Specs == nil {
+			hotspot.Specs = []DamageTakenSpecStat{}
+		}
+		analysis.Hotspots = append(analysis.Hotspots, hotspot)
+	}
+
+	return analysis, nil
+}
+
+// CompositionStat represents a group composition with its usage and success in the runs of the reports
+type CompositionStat struct {
+	EncounterID      int     `json:"encounter_id,omitempty"`
+	Tank             string  `json:"tank"`
+	Healer           string  `json:"healer"`
+	DPS1             string  `json:"dps1"`
+	DPS2             string  `json:"dps2"`
+	DPS3             string  `json:"dps3"`
+	UsageCount       int     `json:"usage_count"`
+	Percentage       float64 `json:"percentage"`
+	SuccessCount     int     `json:"success_count"`
+	SuccessRate      float64 `json:"success_rate"`
+	AvgKeystoneLevel float64 `json:"avg_keystone_level"`
+	MaxKeystoneLevel int     `json:"max_keystone_level"`
+	Rank             int     `json:"rank"`
+}
+
+// Sort orders of the composition statistics
+const (
+	CompositionSortUsage   = "usage"   // Most played compositions first
+	CompositionSortSuccess = "success" // Compositions timing the most keys first
+)
+
+// GetCompositionStats retrieves the group compositions of the runs, optionally for a dungeon (encounterID 0 for all)
+// Only the complete compositions (1 tank, 1 healer, 3 dps) are counted. The percentage is relative to all the
+// complete compositions of the dungeon and key level bracket. Specs are formatted as "Class - Spec" like the
+// Raider.IO composition analytics.
+func (s *DungeonAnalysisService) GetCompositionStats(ctx context.Context, encounterID int, bracket string, minUsage, limit int, sortBy string) ([]CompositionStat, error) {
+	conditions := "deleted_at IS NULL AND cardinality(tank_specs) = 1 AND cardinality(healer_specs) = 1 AND cardinality(dps_specs) = 3"
+	args := []interface{}{}
+
+	if encounterID > 0 {
+		conditions += " AND encounter_id = ?"
+		args = append(args, encounterID)
+	}
+
+	minLevel, maxLevel := warcraftlogsBuilds.GetKeyLevelBracketBounds(bracket)
+	if minLevel > 0 {
+		conditions += " AND keystone_level >= ?"
+		args = append(args, minLevel)
+	}
+	if maxLevel > 0 {
+		conditions += " AND keystone_level <= ?"
+		args = append(args, maxLevel)
+	}
+
+	orderBy := "usage_count DESC, success_rate DESC"
+	if sortBy == CompositionSortSuccess {
+		orderBy = "success_rate DESC, usage_count DESC"
+	}
+
+	query := fmt.Sprintf(`
+	WITH filtered AS (
+		SELECT tank_specs, healer_specs, dps_specs, success, keystone_level
+		FROM group_compositions
+		WHERE %s
+	)
+	SELECT
+		tank_specs[1] as tank,
+		healer_specs[1] as healer,
+		dps_specs[1] as dps1,
+		dps_specs[2] as dps2,
+		dps_specs[3] as dps3,
+		COUNT(*) as usage_count,
+		ROUND((COUNT(*) * 100.0 / NULLIF((SELECT COUNT(*) FROM filtered), 0))::numeric, 2) as percentage,
+		COUNT(*) FILTER (WHERE success) as success_count,
+		ROUND((COUNT(*) FILTER (WHERE success) * 100.0 / COUNT(*))::numeric, 2) as success_rate,
+		ROUND(AVG(keystone_level)::numeric, 2) as avg_keystone_level,
+		MAX(keystone_level) as max_keystone_level
+	FROM filtered
+	GROUP BY tank_specs[1], healer_specs[1], dps_specs[1], dps_specs[2], dps_specs[3]
+	HAVING COUNT(*) >= ?
+	ORDER BY %s
+	LIMIT ?
+	`, conditions, orderBy)
+	args = append(args, minUsage, limit)
+
+	var stats []CompositionStat
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get composition stats: %w", err)
+	}
+
+	for i := range stats {
+		stats[i].EncounterID = encounterID
+		stats[i].Rank = i + 1
+	}
+
+	return stats, nil
+}
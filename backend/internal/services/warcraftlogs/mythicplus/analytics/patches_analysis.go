@@ -0,0 +1,18 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// GetPatches retrieves the patch registry, ordered by start date
+func (s *BuildAnalysisService) GetPatches(ctx context.Context) ([]*warcraftlogsBuilds.GamePatch, error) {
+	return warcraftlogsBuilds.GetGamePatches(s.db.WithContext(ctx))
+}
+
+// ResolvePatch resolves a patch filter value: a patch name, "current" or "all"
+// "all" resolves to nil, as does "current" while the registry is empty.
+func (s *BuildAnalysisService) ResolvePatch(ctx context.Context, name string) (*warcraftlogsBuilds.GamePatch, error) {
+	return warcraftlogsBuilds.FindGamePatch(s.db.WithContext(ctx), name)
+}
@@ -0,0 +1,460 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// PlayerAnalysisService handles the queries on the canonical identities of the players
+type PlayerAnalysisService struct {
+	db *gorm.DB
+}
+
+// NewPlayerAnalysisService creates a new PlayerAnalysisService
+func NewPlayerAnalysisService(db *gorm.DB) *PlayerAnalysisService {
+	return &PlayerAnalysisService{db: db}
+}
+
+// PlayerAlias represents a name and realm used by a player
+type PlayerAlias struct {
+	Name        string    `json:"name"`
+	ServerName  string    `json:"server_name"`
+	Region      string    `json:"region"`
+	Source      string    `json:"source"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	IsCurrent   bool      `json:"is_current"`
+}
+
+// PlayerAliasHistory represents a player identity with the names and realms it used, from the latest one
+type PlayerAliasHistory struct {
+	IdentityID              uint          `json:"identity_id"`
+	Name                    string        `json:"name"`
+	ServerName              string        `json:"server_name"`
+	Region                  string        `json:"region"`
+	Class                   string        `json:"class"`
+	WarcraftLogsCharacterID *int64        `json:"warcraft_logs_character_id,omitempty"`
+	FirstSeenAt             time.Time     `json:"first_seen_at"`
+	LastSeenAt              time.Time     `json:"last_seen_at"`
+	RankingCount            int           `json:"ranking_count"`
+	BuildCount              int           `json:"build_count"`
+	Aliases                 []PlayerAlias `json:"aliases"`
+}
+
+// GetPlayerAliasHistory retrieves the alias history of a player, found by identity ID or by one of its names and realms
+// A name and realm used by several players returns the player that used it last. Returns nil when the player is unknown.
+func (s *PlayerAnalysisService) GetPlayerAliasHistory(ctx context.Context, name, server, region string, identityID *uint) (*PlayerAliasHistory, error) {
+	var id uint
+	if identityID != nil {
+		id = *identityID
+	} else {
+		query := `
+			SELECT pa.player_identity_id
+			FROM player_aliases pa
+			WHERE LOWER(pa.name) = LOWER(?)
+				AND LOWER(REGEXP_REPLACE(pa.server_name, '[^[:alnum:]]', '', 'g')) = ?
+				AND (? = '' OR pa.region = UPPER(?))
+				AND pa.deleted_at IS NULL
+			ORDER BY pa.last_seen_at DESC
+			LIMIT 1`
+
+		var ids []uint
+		if err := s.db.WithContext(ctx).Raw(query, name, warcraftlogsBuilds.NormalizeServerName(server), region, region).
+			Scan(&ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to find player %s-%s: %w", name, server, err)
+		}
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		id = ids[0]
+	}
+
+	// A merged identity is replaced by the identity it was merged into
+	var identities []warcraftlogsBuilds.PlayerIdentity
+	if err := s.db.WithContext(ctx).Unscoped().Where("id = ?", id).Limit(1).Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get player identity %d: %w", id, err)
+	}
+	if len(identities) == 0 {
+		return nil, nil
+	}
+	identity := identities[0]
+	if identity.MergedIntoID != nil {
+		identities = nil
+		if err := s.db.WithContext(ctx).Where("id = ?", *identity.MergedIntoID).Limit(1).Find(&identities).Error; err != nil {
+			return nil, fmt.Errorf("failed to get player identity %d: %w", *identity.MergedIntoID, err)
+		}
+		if len(identities) == 0 {
+			return nil, nil
+		}
+		identity = identities[0]
+	} else if identity.DeletedAt != nil && identity.DeletedAt.Valid {
+		return nil, nil
+	}
+
+	history := &PlayerAliasHistory{
+		IdentityID:              identity.ID,
+		Name:                    identity.Name,
+		ServerName:              identity.ServerName,
+		Region:                  identity.Region,
+		Class:                   identity.Class,
+		WarcraftLogsCharacterID: identity.WarcraftLogsCharacterID,
+		FirstSeenAt:             identity.FirstSeenAt,
+		LastSeenAt:              identity.LastSeenAt,
+		Aliases:                 make([]PlayerAlias, 0),
+	}
+
+	var aliases []warcraftlogsBuilds.PlayerAlias
+	if err := s.db.WithContext(ctx).
+		Where("player_identity_id = ?", identity.ID).
+		Order("last_seen_at DESC").
+		Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("failed to get aliases of player identity %d: %w", identity.ID, err)
+	}
+	for i, alias := range aliases {
+		history.Aliases = append(history.Aliases, PlayerAlias{
+			Name:        alias.Name,
+			ServerName:  alias.ServerName,
+			Region:      alias.Region,
+			Source:      alias.Source,
+			FirstSeenAt: alias.FirstSeenAt,
+			LastSeenAt:  alias.LastSeenAt,
+			IsCurrent:   i == 0,
+		})
+	}
+
+	countQuery := `
+		SELECT
+			(SELECT COUNT(*) FROM class_rankings WHERE player_identity_id = ? AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM player_rankings WHERE player_identity_id = ? AND deleted_at IS NULL) AS ranking_count,
+			(SELECT COUNT(*) FROM player_builds WHERE player_identity_id = ? AND deleted_at IS NULL) AS build_count`
+	if err := s.db.WithContext(ctx).Raw(countQuery, identity.ID, identity.ID, identity.ID).
+		Row().Scan(&history.RankingCount, &history.BuildCount); err != nil {
+		return nil, fmt.Errorf("failed to count rankings of player identity %d: %w", identity.ID, err)
+	}
+
+	return history, nil
+}
+
+// normalizedServerSQL normalizes a realm column the same way as warcraftlogsBuilds.NormalizeServerName
+const normalizedServerSQL = "LOWER(REGEXP_REPLACE(%s, '[^[:alnum:]]', '', 'g'))"
+
+// PlayerSearchResult represents a player matching a search, grouped by name, realm and region
+type PlayerSearchResult struct {
+	Name             string         `json:"name"`
+	ServerName       string         `json:"server_name"`
+	Region           string         `json:"region"`
+	Class            string         `json:"class"`
+	Spec             string         `json:"spec"`
+	PlayerIdentityID *uint          `json:"player_identity_id,omitempty"`
+	BestScore        float64        `json:"best_score"`
+	LastSeenAt       time.Time      `json:"last_seen_at"`
+	Sources          pq.StringArray `json:"sources" gorm:"type:text[]"`
+	Similarity       float64        `json:"similarity"`
+}
+
+// SearchPlayers searches the players by name in the Warcraft Logs rankings and the Raider.IO runs
+// Names are matched by prefix or by trigram similarity, so typos are tolerated. Exact names come first, then the closest ones.
+func (s *PlayerAnalysisService) SearchPlayers(ctx context.Context, name, server, region string, limit int) ([]PlayerSearchResult, error) {
+	serverKey := fmt.Sprintf(normalizedServerSQL, "server_name")
+	query := `
+		WITH matches AS (
+			SELECT *, similarity(LOWER(name), LOWER(@name)) AS name_similarity
+			FROM player_search_entries
+			WHERE (LOWER(name) % LOWER(@name) OR LOWER(name) LIKE @prefix)
+				AND (@server = '' OR ` + serverKey + ` = @server)
+				AND (@region = '' OR region = UPPER(@region))
+		)
+		SELECT
+			(ARRAY_AGG(name ORDER BY seen_at DESC))[1] AS name,
+			(ARRAY_AGG(server_name ORDER BY seen_at DESC))[1] AS server_name,
+			region,
+			(ARRAY_AGG(class ORDER BY seen_at DESC))[1] AS class,
+			(ARRAY_AGG(spec ORDER BY seen_at DESC))[1] AS spec,
+			MAX(player_identity_id) AS player_identity_id,
+			COALESCE(MAX(score), 0) AS best_score,
+			MAX(seen_at) AS last_seen_at,
+			ARRAY_AGG(DISTINCT source) AS sources,
+			MAX(name_similarity) AS similarity
+		FROM matches
+		GROUP BY LOWER(name), ` + serverKey + `, region
+		ORDER BY
+			BOOL_OR(LOWER(name) = LOWER(@name)) DESC,
+			MAX(name_similarity) DESC,
+			best_score DESC
+		LIMIT @limit`
+
+	likeEscaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	var results []PlayerSearchResult
+	if err := s.db.WithContext(ctx).Raw(query, map[string]interface{}{
+		"name":   name,
+		"prefix": strings.ToLower(likeEscaper.Replace(name)) + "%",
+		"server": warcraftlogsBuilds.NormalizeServerName(server),
+		"region": region,
+		"limit":  limit,
+	}).Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to search players %q: %w", name, err)
+	}
+
+	return results, nil
+}
+
+// PlayerBestRun represents the best run of a player in a dungeon
+type PlayerBestRun struct {
+	EncounterID   int       `json:"encounter_id"`
+	DungeonName   string    `json:"dungeon_name"`
+	DungeonSlug   string    `json:"dungeon_slug"`
+	Class         string    `json:"class"`
+	Spec          string    `json:"spec"`
+	KeystoneLevel int       `json:"keystone_level"`
+	Score         float64   `json:"score"`
+	Duration      int64     `json:"duration"`
+	Medal         string    `json:"medal"`
+	ReportCode    string    `json:"report_code,omitempty"`
+	ReportFightID int       `json:"report_fight_id,omitempty"`
+	CompletedAt   time.Time `json:"completed_at"`
+	Source        string    `json:"source"`
+}
+
+// PlayerSpecHistory represents the runs of a player with a spec
+type PlayerSpecHistory struct {
+	Class       string    `json:"class"`
+	Spec        string    `json:"spec"`
+	RunCount    int       `json:"run_count"`
+	BestScore   float64   `json:"best_score"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// PlayerBuildSnapshot represents a build of a player extracted from a report
+type PlayerBuildSnapshot struct {
+	ID            uint      `json:"id"`
+	Class         string    `json:"class"`
+	Spec          string    `json:"spec"`
+	EncounterID   uint      `json:"encounter_id"`
+	KeystoneLevel int       `json:"keystone_level"`
+	ItemLevel     float64   `json:"item_level"`
+	TalentImport  string    `json:"talent_import"`
+	ReportCode    string    `json:"report_code"`
+	FightID       int       `json:"fight_id"`
+	Patch         string    `json:"patch"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PlayerProfile represents a player with its best runs, score, spec history and builds
+type PlayerProfile struct {
+	PlayerIdentityID *uint                 `json:"player_identity_id,omitempty"`
+	Name             string                `json:"name"`
+	ServerName       string                `json:"server_name"`
+	Region           string                `json:"region"`
+	Class            string                `json:"class"`
+	TotalScore       float64               `json:"total_score"`
+	DungeonCount     int                   `json:"dungeon_count"`
+	BestRuns         []PlayerBestRun       `json:"best_runs"`
+	SpecHistory      []PlayerSpecHistory   `json:"spec_history"`
+	Builds           []PlayerBuildSnapshot `json:"builds"`
+	Aliases          []PlayerAlias         `json:"aliases"`
+}
+
+// profileBuildCount is the number of latest builds returned in a player profile
+const profileBuildCount = 10
+
+// playerCondition returns the condition matching the rows of a player in a table
+// A player with an identity matches its linked rows and the unlinked rows of its aliases, otherwise the name and realm are matched.
+// regionColumn and identity are optional, for the tables without region or identity.
+func playerCondition(alias, nameColumn, regionColumn string, hasIdentity, byIdentity bool) string {
+	serverKey := fmt.Sprintf(normalizedServerSQL, alias+".server_name")
+	if !byIdentity {
+		condition := fmt.Sprintf("LOWER(%s.%s) = LOWER(@name) AND %s = @server", alias, nameColumn, serverKey)
+		if regionColumn != "" {
+			condition += fmt.Sprintf(" AND (@region = '' OR UPPER(%s.%s) = UPPER(@region))", alias, regionColumn)
+		}
+		return condition
+	}
+
+	aliasMatch := fmt.Sprintf(`EXISTS (
+				SELECT 1 FROM player_aliases pa
+				WHERE pa.player_identity_id = @identity
+					AND pa.deleted_at IS NULL
+					AND LOWER(pa.name) = LOWER(%s.%s)
+					AND %s = %s`,
+		alias, nameColumn, fmt.Sprintf(normalizedServerSQL, "pa.server_name"), serverKey)
+	if regionColumn != "" {
+		aliasMatch += fmt.Sprintf(" AND pa.region = UPPER(%s.%s)", alias, regionColumn)
+	}
+	aliasMatch += ")"
+
+	if !hasIdentity {
+		return aliasMatch
+	}
+	return fmt.Sprintf("(%[1]s.player_identity_id = @identity OR (%[1]s.player_identity_id IS NULL AND %[2]s))", alias, aliasMatch)
+}
+
+// playerRunsQuery returns the runs of a player in the Warcraft Logs rankings and the Raider.IO runs
+func playerRunsQuery(byIdentity bool) string {
+	return `
+		SELECT pr.dungeon_id AS encounter_id, pr.name, pr.server_name, UPPER(pr.server_region) AS region,
+			pr.class, pr.spec, pr.hard_mode_level AS keystone_level, pr.score, pr.duration, pr.medal,
+			pr.report_code, pr.report_fight_id, TO_TIMESTAMP(pr.start_time / 1000.0) AS completed_at,
+			'warcraftlogs_rankings' AS source
+		FROM player_rankings pr
+		WHERE pr.deleted_at IS NULL AND ` + playerCondition("pr", "name", "server_region", true, byIdentity) + `
+		UNION ALL
+		SELECT cr.encounter_id, cr.player_name, cr.server_name, UPPER(cr.server_region),
+			cr.class, cr.spec, cr.hard_mode_level, cr.score, cr.duration, cr.medal,
+			cr.report_code, cr.report_fight_id, TO_TIMESTAMP(cr.start_time / 1000.0),
+			'warcraftlogs_class_rankings'
+		FROM class_rankings cr
+		WHERE cr.deleted_at IS NULL AND ` + playerCondition("cr", "player_name", "server_region", true, byIdentity) + `
+		UNION ALL
+		SELECT d.encounter_id, mrc.name, mrc.server_name, UPPER(mrc.region),
+			mrc.class_name, mrc.spec_name, mr.mythic_level, mr.score, mr.clear_time_ms, '',
+			'', 0, mr.completed_at,
+			'raiderio_runs'
+		FROM (
+			SELECT *, realm_name AS server_name FROM mythicplus_run_characters WHERE deleted_at IS NULL
+		) mrc
+		JOIN mythicplus_runs mr ON mr.id = mrc.run_id AND mr.deleted_at IS NULL
+		JOIN dungeons d ON d.slug = mr.dungeon_slug AND d.encounter_id IS NOT NULL
+		WHERE ` + playerCondition("mrc", "name", "region", false, byIdentity)
+}
+
+// GetPlayerProfile retrieves the profile of a player, found by identity ID or by its name and realm
+// The total score sums the best run of each dungeon of the active season of the player's region.
+// Returns nil when the player is unknown.
+func (s *PlayerAnalysisService) GetPlayerProfile(ctx context.Context, name, server, region string, identityID *uint) (*PlayerProfile, error) {
+	profile := &PlayerProfile{
+		BestRuns:    make([]PlayerBestRun, 0),
+		SpecHistory: make([]PlayerSpecHistory, 0),
+		Builds:      make([]PlayerBuildSnapshot, 0),
+		Aliases:     make([]PlayerAlias, 0),
+	}
+	args := map[string]interface{}{
+		"name":   name,
+		"server": warcraftlogsBuilds.NormalizeServerName(server),
+		"region": region,
+	}
+
+	// Players seen in the reports have an identity, the others are only known by their name and realm
+	history, err := s.GetPlayerAliasHistory(ctx, name, server, region, identityID)
+	if err != nil {
+		return nil, err
+	}
+	byIdentity := history != nil
+	if byIdentity {
+		args["identity"] = history.IdentityID
+		identity := history.IdentityID
+		profile.PlayerIdentityID = &identity
+		profile.Name = history.Name
+		profile.ServerName = history.ServerName
+		profile.Region = history.Region
+		profile.Class = history.Class
+		profile.Aliases = history.Aliases
+	} else if identityID != nil {
+		return nil, nil
+	}
+
+	runsQuery := playerRunsQuery(byIdentity)
+
+	if err := s.db.WithContext(ctx).Raw(`
+		WITH player_runs AS (`+runsQuery+`)
+		SELECT DISTINCT ON (pr.encounter_id)
+			pr.encounter_id, COALESCE(d.name, '') AS dungeon_name, COALESCE(d.slug, '') AS dungeon_slug,
+			pr.class, pr.spec, pr.keystone_level, pr.score, pr.duration, pr.medal,
+			pr.report_code, pr.report_fight_id, pr.completed_at, pr.source
+		FROM player_runs pr
+		LEFT JOIN dungeons d ON d.encounter_id = pr.encounter_id
+		ORDER BY pr.encounter_id, pr.score DESC, pr.completed_at DESC`, args).
+		Scan(&profile.BestRuns).Error; err != nil {
+		return nil, fmt.Errorf("failed to get best runs of player %s-%s: %w", name, server, err)
+	}
+
+	if err := s.db.WithContext(ctx).Raw(`
+		WITH player_runs AS (`+runsQuery+`)
+		SELECT class, spec, COUNT(*) AS run_count, MAX(score) AS best_score,
+			MIN(completed_at) AS first_seen_at, MAX(completed_at) AS last_seen_at
+		FROM player_runs
+		GROUP BY class, spec
+		ORDER BY last_seen_at DESC`, args).
+		Scan(&profile.SpecHistory).Error; err != nil {
+		return nil, fmt.Errorf("failed to get spec history of player %s-%s: %w", name, server, err)
+	}
+
+	if !byIdentity {
+		if len(profile.BestRuns) == 0 {
+			return nil, nil
+		}
+
+		// The latest appearance gives the name, realm and class of the player
+		var latest []struct {
+			Name       string
+			ServerName string
+			Region     string
+			Class      string
+		}
+		if err := s.db.WithContext(ctx).Raw(`
+			WITH player_runs AS (`+runsQuery+`)
+			SELECT name, server_name, region, class
+			FROM player_runs
+			ORDER BY completed_at DESC
+			LIMIT 1`, args).Scan(&latest).Error; err != nil {
+			return nil, fmt.Errorf("failed to get player %s-%s: %w", name, server, err)
+		}
+		if len(latest) > 0 {
+			profile.Name = latest[0].Name
+			profile.ServerName = latest[0].ServerName
+			profile.Region = latest[0].Region
+			profile.Class = latest[0].Class
+		}
+	}
+
+	// Only the dungeons of the active season count in the score
+	var activeEncounterIDs []int
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT encounter_id FROM active_season_dungeons WHERE region = UPPER(?)`, profile.Region).
+		Scan(&activeEncounterIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active season dungeons: %w", err)
+	}
+	active := make(map[int]bool, len(activeEncounterIDs))
+	for _, encounterID := range activeEncounterIDs {
+		active[encounterID] = true
+	}
+	for _, run := range profile.BestRuns {
+		if active[run.EncounterID] {
+			profile.TotalScore += run.Score
+			profile.DungeonCount++
+		}
+	}
+
+	buildCondition := playerCondition("pb", "player_name", "", true, byIdentity)
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT pb.id, pb.class, pb.spec, pb.encounter_id, pb.keystone_level, pb.item_level,
+			pb.talent_import, pb.report_code, pb.fight_id, pb.patch, pb.created_at
+		FROM player_builds pb
+		WHERE pb.deleted_at IS NULL AND `+buildCondition+`
+		ORDER BY pb.created_at DESC
+		LIMIT @limit`, mergeArgs(args, map[string]interface{}{"limit": profileBuildCount})).
+		Scan(&profile.Builds).Error; err != nil {
+		return nil, fmt.Errorf("failed to get builds of player %s-%s: %w", name, server, err)
+	}
+
+	return profile, nil
+}
+
+// mergeArgs returns the named arguments of both maps
+func mergeArgs(args, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(args)+len(extra))
+	for key, value := range args {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
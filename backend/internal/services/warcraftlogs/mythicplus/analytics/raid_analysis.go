@@ -0,0 +1,216 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	raidEncounterRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+)
+
+// ErrRaidBossNotFound is returned when the raid boss is not targeted by the builds pipeline
+var ErrRaidBossNotFound = errors.New("raid boss not found")
+
+// RaidAnalysisService handles the builds analysis of the raid bosses
+// The raid statistics are stored with the Mythic+ ones, each query is restricted to the encounter and the difficulty of the boss.
+type RaidAnalysisService struct {
+	db                      *gorm.DB
+	builds                  *BuildAnalysisService
+	raidEncounterRepository *raidEncounterRepository.RaidEncounterRepository
+}
+
+// NewRaidAnalysisService creates a new RaidAnalysisService
+func NewRaidAnalysisService(db *gorm.DB) *RaidAnalysisService {
+	return &RaidAnalysisService{
+		db:                      db,
+		builds:                  NewBuildAnalysisService(db),
+		raidEncounterRepository: raidEncounterRepository.NewRaidEncounterRepository(db),
+	}
+}
+
+// RaidBoss represents a raid boss targeted by the builds pipeline
+type RaidBoss struct {
+	EncounterID int    `json:"encounter_id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Difficulty  int    `json:"difficulty"`
+}
+
+// Raid represents a raid with its targeted bosses
+type Raid struct {
+	Name   string     `json:"name"`
+	Slug   string     `json:"slug"`
+	ZoneID int        `json:"zone_id"`
+	Bosses []RaidBoss `json:"bosses"`
+}
+
+// GetRaids retrieves the raids and the bosses targeted by the builds pipeline
+func (s *RaidAnalysisService) GetRaids(ctx context.Context) ([]Raid, error) {
+	encounters, err := s.raidEncounterRepository.GetRaidEncounters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raids := make([]Raid, 0)
+	raidIndex := make(map[string]int)
+	for _, encounter := range encounters {
+		index, ok := raidIndex[encounter.RaidSlug]
+		if !ok {
+			raids = append(raids, Raid{
+				Name:   encounter.RaidName,
+				Slug:   encounter.RaidSlug,
+				ZoneID: int(encounter.ZoneID),
+				Bosses: []RaidBoss{},
+			})
+			index = len(raids) - 1
+			raidIndex[encounter.RaidSlug] = index
+		}
+
+		raids[index].Bosses = append(raids[index].Bosses, RaidBoss{
+			EncounterID: int(encounter.EncounterID),
+			Name:        encounter.Name,
+			Slug:        encounter.Slug,
+			Difficulty:  int(encounter.Difficulty),
+		})
+	}
+
+	return raids, nil
+}
+
+// GetRaidBoss retrieves a raid boss by the slugs of the raid and the boss, at a difficulty
+func (s *RaidAnalysisService) GetRaidBoss(ctx context.Context, raidSlug, bossSlug string, difficulty int) (*RaidBoss, error) {
+	encounter, err := s.raidEncounterRepository.GetRaidEncounter(ctx, raidSlug, bossSlug, uint(difficulty))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRaidBossNotFound
+		}
+		return nil, err
+	}
+
+	return &RaidBoss{
+		EncounterID: int(encounter.EncounterID),
+		Name:        encounter.Name,
+		Slug:        encounter.Slug,
+		Difficulty:  int(encounter.Difficulty),
+	}, nil
+}
+
+// GetPopularItemsBySlot retrieves the most popular items for each slot for a specific class, spec and boss
+func (s *RaidAnalysisService) GetPopularItemsBySlot(ctx context.Context, class, spec string, encounterID, difficulty int) ([]ItemPopularity, error) {
+	var items []ItemPopularity
+	query := `
+	SELECT * FROM (
+			SELECT
+					bs.encounter_id,
+					bs.item_slot,
+					bs.item_id,
+					bs.item_name,
+					bs.item_icon,
+					bs.item_quality,
+					bs.item_level,
+					bs.usage_count,
+					bs.usage_percentage,
+					bs.avg_keystone_level,
+					ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY bs.usage_count DESC)::BIGINT as rank
+			FROM build_statistics bs
+			WHERE bs.class = ? AND bs.spec = ? AND bs.encounter_id = ? AND bs.difficulty = ?
+	) ranked_items
+	WHERE rank <= 4
+	ORDER BY item_slot, rank
+	`
+	err := s.db.WithContext(ctx).Raw(query, class, spec, encounterID, difficulty).Scan(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get popular items: %w", err)
+	}
+	return items, nil
+}
+
+// GetEnchantUsage retrieves enchant usage statistics for a specific class, spec and boss
+func (s *RaidAnalysisService) GetEnchantUsage(ctx context.Context, class, spec string, encounterID, difficulty int) ([]EnchantUsage, error) {
+	var enchants []EnchantUsage
+	query := `
+	SELECT
+			bs.item_slot,
+			bs.permanent_enchant_id,
+			bs.permanent_enchant_name,
+			COUNT(*)::BIGINT as usage_count,
+			AVG(bs.avg_keystone_level) as avg_keystone_level,
+			AVG(bs.avg_item_level) as avg_item_level,
+			MAX(bs.max_keystone_level)::BIGINT as max_keystone_level,
+			ROW_NUMBER() OVER (PARTITION BY bs.item_slot ORDER BY COUNT(*) DESC)::BIGINT as rank
+	FROM build_statistics bs
+	WHERE bs.class = ? AND bs.spec = ? AND bs.encounter_id = ? AND bs.difficulty = ?
+	AND bs.has_permanent_enchant = true
+	GROUP BY bs.item_slot, bs.permanent_enchant_id, bs.permanent_enchant_name
+	ORDER BY bs.item_slot, rank
+	`
+	err := s.db.WithContext(ctx).Raw(query, class, spec, encounterID, difficulty).Scan(&enchants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enchant usage: %w", err)
+	}
+	return enchants, nil
+}
+
+// GetGemUsage retrieves gem usage statistics for a specific class, spec and boss
+func (s *RaidAnalysisService) GetGemUsage(ctx context.Context, class, spec string, encounterID, difficulty int) ([]GemUsage, error) {
+	return s.builds.getGemUsage(ctx, "bs.encounter_id = $3 AND bs.difficulty = $4", class, spec, encounterID, difficulty)
+}
+
+// GetTopTalentBuilds retrieves the top talent builds for a specific class, spec and boss
+func (s *RaidAnalysisService) GetTopTalentBuilds(ctx context.Context, class, spec string, encounterID, difficulty int) ([]TalentBuild, error) {
+	var builds []TalentBuild
+	query := `
+	SELECT
+			ts.talent_import,
+			SUM(ts.usage_count)::BIGINT as total_usage,
+			AVG(ts.usage_percentage) as avg_usage_percentage,
+			AVG(ts.avg_keystone_level) as avg_keystone_level
+	FROM talent_statistics ts
+	WHERE ts.class = ? AND ts.spec = ? AND ts.encounter_id = ? AND ts.difficulty = ?
+	GROUP BY ts.talent_import
+	ORDER BY total_usage DESC
+	LIMIT 3
+	`
+	err := s.db.WithContext(ctx).Raw(query, class, spec, encounterID, difficulty).Scan(&builds).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top talent builds: %w", err)
+	}
+	return builds, nil
+}
+
+// GetStatPriorities retrieves stat priority statistics for a specific class, spec and boss
+func (s *RaidAnalysisService) GetStatPriorities(ctx context.Context, class, spec string, encounterID, difficulty int) ([]StatPriority, error) {
+	var stats []StatPriority
+	query := `
+	SELECT
+			ss.stat_name,
+			ss.stat_category,
+			AVG(ss.avg_value) as avg_value,
+			MIN(ss.min_value) as min_value,
+			MAX(ss.max_value) as max_value,
+			SUM(ss.sample_size)::BIGINT as total_samples,
+			AVG(ss.avg_keystone_level) as avg_keystone_level,
+			ROW_NUMBER() OVER (PARTITION BY ss.stat_category ORDER BY AVG(ss.avg_value) DESC)::BIGINT as priority_rank
+	FROM stat_statistics ss
+	WHERE ss.class = ? AND ss.spec = ? AND ss.encounter_id = ? AND ss.difficulty = ?
+	GROUP BY ss.stat_name, ss.stat_category
+	ORDER BY ss.stat_category, priority_rank
+	`
+	err := s.db.WithContext(ctx).Raw(query, class, spec, encounterID, difficulty).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stat priorities: %w", err)
+	}
+	return stats, nil
+}
+
+// GetOptimalBuild retrieves the optimal build for a specific class, spec and boss
+func (s *RaidAnalysisService) GetOptimalBuild(ctx context.Context, class, spec string, encounterID, difficulty int) (*OptimalBuild, error) {
+	return s.builds.getOptimalBuild(ctx, bossEncounter, class, spec, encounterID, difficulty)
+}
+
+// bossEncounter restricts a statistics query to a single encounter and difficulty, given as arguments
+func bossEncounter(alias string) string {
+	return fmt.Sprintf("%[1]s.encounter_id = ? AND %[1]s.difficulty = ?", alias)
+}
@@ -0,0 +1,142 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"gorm.io/gorm"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	reportsQueries "wowperf/internal/services/warcraftlogs/mythicplus/builds/queries"
+	reportAnalysisJobRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+var (
+	// ErrInvalidReportReference is returned when the submitted report is neither a report code nor a report URL
+	ErrInvalidReportReference = errors.New("invalid report reference")
+	// ErrReportAnalysisJobNotFound is returned when the report analysis job does not exist
+	ErrReportAnalysisJobNotFound = errors.New("report analysis job not found")
+)
+
+// ReportAnalysisService submits on-demand report analyses to Temporal and exposes their status
+type ReportAnalysisService struct {
+	temporalClient client.Client
+	jobRepository  *reportAnalysisJobRepository.ReportAnalysisJobRepository
+}
+
+// NewReportAnalysisService creates a new ReportAnalysisService
+func NewReportAnalysisService(db *gorm.DB, temporalClient client.Client) *ReportAnalysisService {
+	return &ReportAnalysisService{
+		temporalClient: temporalClient,
+		jobRepository:  reportAnalysisJobRepository.NewReportAnalysisJobRepository(db),
+	}
+}
+
+// ReportAnalysisJobStatus represents the status of a report analysis job, with its result once completed
+type ReportAnalysisJobStatus struct {
+	JobID       string                                   `json:"job_id"`
+	ReportCode  string                                   `json:"report_code"`
+	FightID     int                                      `json:"fight_id,omitempty"`
+	Status      string                                   `json:"status"`
+	Error       string                                   `json:"error,omitempty"`
+	CreatedAt   time.Time                                `json:"created_at"`
+	StartedAt   *time.Time                               `json:"started_at,omitempty"`
+	CompletedAt *time.Time                               `json:"completed_at,omitempty"`
+	Result      *warcraftlogsBuilds.ReportAnalysisResult `json:"result,omitempty"`
+}
+
+// SubmitReportAnalysis creates a job for a report code or URL and starts the analysis workflow
+// fightID overrides the fight of the URL, 0 analyzes every Mythic+ fight of the report.
+// userID is set to fetch the report with the WarcraftLogs account of the user (private reports).
+func (s *ReportAnalysisService) SubmitReportAnalysis(ctx context.Context, reference string, fightID int, userID *uint) (*ReportAnalysisJobStatus, error) {
+	reportCode, referenceFightID, err := reportsQueries.ParseReportReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportReference, err)
+	}
+	if fightID == 0 {
+		fightID = referenceFightID
+	}
+
+	job := &warcraftlogsBuilds.ReportAnalysisJob{
+		JobID:      uuid.New().String(),
+		ReportCode: reportCode,
+		FightID:    fightID,
+		UserID:     userID,
+		Status:     warcraftlogsBuilds.ReportAnalysisStatusPending,
+	}
+	if err := s.jobRepository.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("report-analysis-%s", job.JobID),
+		TaskQueue: models.DefaultTaskQueue,
+	}
+	params := models.ReportAnalysisWorkflowParams{
+		JobID:      job.JobID,
+		ReportCode: reportCode,
+		FightID:    fightID,
+		UserID:     userID,
+	}
+
+	run, err := s.temporalClient.ExecuteWorkflow(ctx, workflowOptions, definitions.ReportAnalysisWorkflowName, params)
+	if err != nil {
+		_ = s.jobRepository.FailJob(ctx, job.JobID, "failed to start the analysis")
+		return nil, fmt.Errorf("failed to start report analysis workflow: %w", err)
+	}
+
+	if err := s.jobRepository.SetWorkflowID(ctx, job.JobID, run.GetID()); err != nil {
+		return nil, err
+	}
+
+	return toReportAnalysisJobStatus(job)
+}
+
+// GetReportAnalysisJob returns the status of a report analysis job, with its result once completed
+// userID is the authenticated user, nil on the public route. The jobs submitted with the WarcraftLogs account
+// of a user are only returned to this user, as they may analyze a private report.
+func (s *ReportAnalysisService) GetReportAnalysisJob(ctx context.Context, jobID string, userID *uint) (*ReportAnalysisJobStatus, error) {
+	job, err := s.jobRepository.GetJob(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportAnalysisJobNotFound
+		}
+		return nil, err
+	}
+	if job.UserID != nil && (userID == nil || *job.UserID != *userID) {
+		return nil, ErrReportAnalysisJobNotFound
+	}
+
+	return toReportAnalysisJobStatus(job)
+}
+
+// toReportAnalysisJobStatus converts a job to its API representation
+func toReportAnalysisJobStatus(job *warcraftlogsBuilds.ReportAnalysisJob) (*ReportAnalysisJobStatus, error) {
+	status := &ReportAnalysisJobStatus{
+		JobID:       job.JobID,
+		ReportCode:  job.ReportCode,
+		FightID:     job.FightID,
+		Status:      job.Status,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
+
+	if job.Status == warcraftlogsBuilds.ReportAnalysisStatusCompleted && len(job.Result) > 0 {
+		var result warcraftlogsBuilds.ReportAnalysisResult
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse result of report analysis job %s: %w", job.JobID, err)
+		}
+		status.Result = &result
+	}
+
+	return status, nil
+}
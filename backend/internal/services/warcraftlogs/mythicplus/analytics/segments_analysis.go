@@ -0,0 +1,327 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// SegmentFilter restricts the build, talent and stat statistics to a patch, an affix set and a key level range
+// The statistics are then read from the segments precomputed per patch, keystone level and affix set.
+type SegmentFilter struct {
+	Patch       string  // Name of the patch of the builds, empty for every patch
+	Affixes     []int64 // Affixes every build must have, in any order
+	MinKeyLevel int     // Lowest keystone level, 0 for no lower bound
+	MaxKeyLevel int     // Highest keystone level, 0 for no upper bound
+}
+
+// IsSet reports whether the filter restricts the statistics
+func (f SegmentFilter) IsSet() bool {
+	return f.Patch != "" || len(f.Affixes) > 0 || f.MinKeyLevel > 0 || f.MaxKeyLevel > 0
+}
+
+// segmentCondition returns the condition restricting a segment table to the filter, with its arguments
+// alias is the alias of the segment table in the query
+func segmentCondition(alias string, filter SegmentFilter) (string, []interface{}) {
+	conditions := []string{alias + ".deleted_at IS NULL"}
+	var args []interface{}
+
+	if filter.Patch != "" {
+		conditions = append(conditions, alias+".patch = ?")
+		args = append(args, filter.Patch)
+	}
+	if len(filter.Affixes) > 0 {
+		conditions = append(conditions, alias+".affixes @> ?::integer[]")
+		args = append(args, pq.Int64Array(filter.Affixes))
+	}
+	if filter.MinKeyLevel > 0 {
+		conditions = append(conditions, alias+".keystone_level >= ?")
+		args = append(args, filter.MinKeyLevel)
+	}
+	if filter.MaxKeyLevel > 0 {
+		conditions = append(conditions, alias+".keystone_level <= ?")
+		args = append(args, filter.MaxKeyLevel)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// GetSegmentedPopularItemsBySlot retrieves the most popular items for each slot and encounter, restricted to a segment
+// A nil encounter ID returns every Mythic+ dungeon
+func (s *BuildAnalysisService) GetSegmentedPopularItemsBySlot(ctx context.Context, class, spec string, encounterID *int, filter SegmentFilter) ([]ItemPopularity, error) {
+	var items []ItemPopularity
+	if err := s.querySegmentedPopularItems(ctx, &items, class, spec, encounterID, filter, true); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetSegmentedGlobalPopularItemsBySlot retrieves the most popular items for each slot across all encounters, restricted to a segment
+func (s *BuildAnalysisService) GetSegmentedGlobalPopularItemsBySlot(ctx context.Context, class, spec string, filter SegmentFilter) ([]GlobalItemPopularity, error) {
+	var items []GlobalItemPopularity
+	if err := s.querySegmentedPopularItems(ctx, &items, class, spec, nil, filter, false); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// querySegmentedPopularItems sums the item segments and keeps the 4 most used items of each slot
+// perEncounter ranks the items of each encounter separately, otherwise every encounter is summed
+func (s *BuildAnalysisService) querySegmentedPopularItems(ctx context.Context, dest interface{}, class, spec string, encounterID *int, filter SegmentFilter, perEncounter bool) error {
+	condition, conditionArgs := segmentCondition("bs", filter)
+	args := []interface{}{class, spec}
+
+	encounterCondition := mythicPlusEncounters("bs")
+	if encounterID != nil {
+		encounterCondition = "bs.encounter_id = ?"
+		args = append(args, *encounterID)
+	}
+	args = append(args, conditionArgs...)
+
+	groupColumns := "bs.item_slot, bs.item_id"
+	partition := "i.item_slot"
+	encounterColumn := ""
+	if perEncounter {
+		groupColumns = "bs.encounter_id, " + groupColumns
+		partition = "i.encounter_id, " + partition
+		encounterColumn = "encounter_id, "
+	}
+
+	query := `
+	WITH items AS (
+			SELECT
+					` + groupColumns + `,
+					MAX(bs.item_name) as item_name,
+					MAX(bs.item_icon) as item_icon,
+					MAX(bs.item_quality) as item_quality,
+					MAX(bs.item_level) as item_level,
+					SUM(bs.usage_count) as usage_count,
+					SUM(bs.usage_count * bs.keystone_level)::NUMERIC / NULLIF(SUM(bs.usage_count), 0) as avg_keystone_level
+			FROM build_statistic_segments bs
+			WHERE bs.class = ? AND bs.spec = ?
+			AND ` + encounterCondition + `
+			AND ` + condition + `
+			GROUP BY ` + groupColumns + `
+	), ranked AS (
+			SELECT
+					i.*,
+					ROUND(100.0 * i.usage_count / NULLIF(SUM(i.usage_count) OVER (PARTITION BY ` + partition + `), 0), 2) as usage_percentage,
+					ROW_NUMBER() OVER (PARTITION BY ` + partition + ` ORDER BY i.usage_count DESC) as rank
+			FROM items i
+	)
+	SELECT
+			` + encounterColumn + `item_slot, item_id, item_name, item_icon, item_quality, item_level,
+			usage_count, usage_percentage, ROUND(avg_keystone_level, 2) as avg_keystone_level, rank
+	FROM ranked
+	WHERE rank <= 4
+	ORDER BY ` + encounterColumn + `item_slot, rank`
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(dest).Error; err != nil {
+		return fmt.Errorf("failed to get segmented popular items: %w", err)
+	}
+	return nil
+}
+
+// GetSegmentedTopTalentBuilds retrieves the top talent builds for a specific class and spec, restricted to a segment
+// The usage percentage is computed per dungeon, then averaged like the unsegmented talent builds.
+func (s *BuildAnalysisService) GetSegmentedTopTalentBuilds(ctx context.Context, class, spec string, filter SegmentFilter) ([]TalentBuild, error) {
+	var builds []TalentBuild
+
+	condition, conditionArgs := segmentCondition("ts", filter)
+	args := append([]interface{}{class, spec}, conditionArgs...)
+
+	query := `
+	WITH talents AS (
+			SELECT
+					ts.encounter_id,
+					ts.talent_import,
+					SUM(ts.usage_count) as usage_count,
+					SUM(ts.usage_count * ts.keystone_level) as keystone_levels
+			FROM talent_statistic_segments ts
+			WHERE ts.class = ? AND ts.spec = ?
+			AND ` + mythicPlusEncounters("ts") + `
+			AND ` + condition + `
+			GROUP BY ts.encounter_id, ts.talent_import
+	), shares AS (
+			SELECT
+					t.*,
+					100.0 * t.usage_count / NULLIF(SUM(t.usage_count) OVER (PARTITION BY t.encounter_id), 0) as usage_percentage
+			FROM talents t
+	)
+	SELECT
+			talent_import,
+			SUM(usage_count)::BIGINT as total_usage,
+			AVG(usage_percentage) as avg_usage_percentage,
+			SUM(keystone_levels)::NUMERIC / NULLIF(SUM(usage_count), 0) as avg_keystone_level
+	FROM shares
+	GROUP BY talent_import
+	ORDER BY SUM(usage_count) DESC
+	LIMIT 3`
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&builds).Error; err != nil {
+		return nil, fmt.Errorf("failed to get segmented top talent builds: %w", err)
+	}
+	return builds, nil
+}
+
+// GetSegmentedTalentBuildsByDungeon retrieves talent build statistics per dungeon for a specific class and spec, restricted to a segment
+func (s *BuildAnalysisService) GetSegmentedTalentBuildsByDungeon(ctx context.Context, class, spec string, filter SegmentFilter) ([]DungeonTalentBuild, error) {
+	var builds []DungeonTalentBuild
+
+	condition, conditionArgs := segmentCondition("ts", filter)
+	args := append([]interface{}{class, spec}, conditionArgs...)
+
+	query := `
+	WITH talents AS (
+			SELECT
+					ts.class,
+					ts.spec,
+					ts.encounter_id,
+					ts.talent_import,
+					SUM(ts.usage_count) as usage_count,
+					SUM(ts.usage_count * ts.keystone_level)::NUMERIC / NULLIF(SUM(ts.usage_count), 0) as avg_keystone_level
+			FROM talent_statistic_segments ts
+			WHERE ts.class = ? AND ts.spec = ?
+			AND ` + condition + `
+			GROUP BY ts.class, ts.spec, ts.encounter_id, ts.talent_import
+	)
+	SELECT
+			t.class,
+			t.spec,
+			t.encounter_id,
+			d.name as dungeon_name,
+			t.talent_import,
+			t.usage_count::BIGINT as total_usage,
+			100.0 * t.usage_count / NULLIF(SUM(t.usage_count) OVER (PARTITION BY t.encounter_id), 0) as avg_usage_percentage,
+			t.avg_keystone_level
+	FROM talents t
+	JOIN dungeons d ON t.encounter_id = d.encounter_id
+	ORDER BY t.encounter_id, t.usage_count DESC`
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&builds).Error; err != nil {
+		return nil, fmt.Errorf("failed to get segmented talent builds by dungeon: %w", err)
+	}
+	return builds, nil
+}
+
+// GetSegmentedStatPriorities retrieves stat priority statistics for a specific class and spec, restricted to a segment
+func (s *BuildAnalysisService) GetSegmentedStatPriorities(ctx context.Context, class, spec string, filter SegmentFilter) ([]StatPriority, error) {
+	var stats []StatPriority
+
+	condition, conditionArgs := segmentCondition("ss", filter)
+	args := append([]interface{}{class, spec}, conditionArgs...)
+
+	query := `
+	WITH stat_aggregates AS (
+			SELECT
+					ss.stat_name,
+					ss.stat_category,
+					SUM(ss.avg_value * ss.sample_size) / NULLIF(SUM(ss.sample_size), 0) as avg_value,
+					MIN(ss.min_value) as min_value,
+					MAX(ss.max_value) as max_value,
+					SUM(ss.sample_size)::BIGINT as total_samples,
+					SUM(ss.sample_size * ss.keystone_level)::NUMERIC / NULLIF(SUM(ss.sample_size), 0) as avg_keystone_level
+			FROM stat_statistic_segments ss
+			WHERE ss.class = ? AND ss.spec = ?
+			AND ` + mythicPlusEncounters("ss") + `
+			AND ` + condition + `
+			GROUP BY ss.stat_name, ss.stat_category
+	)
+	SELECT
+			sa.*,
+			ROW_NUMBER() OVER (PARTITION BY sa.stat_category ORDER BY sa.avg_value DESC)::BIGINT as priority_rank
+	FROM stat_aggregates sa
+	ORDER BY sa.stat_category, priority_rank`
+
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get segmented stat priorities: %w", err)
+	}
+	return stats, nil
+}
+
+// GetSegmentedOptimalBuild retrieves the optimal build for a specific class and spec, restricted to a segment
+func (s *BuildAnalysisService) GetSegmentedOptimalBuild(ctx context.Context, class, spec string, filter SegmentFilter) (*OptimalBuild, error) {
+	// 1. Get the most popular talent import
+	var topTalent struct {
+		TalentImport string
+	}
+	talentCondition, talentArgs := segmentCondition("ts", filter)
+	talentQuery := `
+	SELECT ts.talent_import
+	FROM talent_statistic_segments ts
+	WHERE ts.class = ? AND ts.spec = ? AND ` + mythicPlusEncounters("ts") + ` AND ` + talentCondition + `
+	GROUP BY ts.talent_import
+	ORDER BY SUM(ts.usage_count) DESC
+	LIMIT 1`
+	err := s.db.WithContext(ctx).Raw(talentQuery, append([]interface{}{class, spec}, talentArgs...)...).Scan(&topTalent).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segmented top talent import: %w", err)
+	}
+
+	// 2. Get the stat priority
+	var statPriority struct {
+		StatPriority string
+	}
+	statCondition, statArgs := segmentCondition("ss", filter)
+	statQuery := `
+	SELECT STRING_AGG(stat_name, ' > ' ORDER BY avg_value DESC) as stat_priority
+	FROM (
+			SELECT ss.stat_name, SUM(ss.avg_value * ss.sample_size) / NULLIF(SUM(ss.sample_size), 0) as avg_value
+			FROM stat_statistic_segments ss
+			WHERE ss.class = ? AND ss.spec = ? AND ss.stat_category = 'secondary'
+			AND ` + mythicPlusEncounters("ss") + ` AND ` + statCondition + `
+			GROUP BY ss.stat_name
+	) s`
+	err = s.db.WithContext(ctx).Raw(statQuery, append([]interface{}{class, spec}, statArgs...)...).Scan(&statPriority).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segmented stat priority: %w", err)
+	}
+
+	// 3. Get the most popular items
+	var items []struct {
+		ItemSlot    int
+		ItemName    string
+		ItemIcon    string
+		ItemQuality int
+		UsageCount  int
+	}
+	itemCondition, itemArgs := segmentCondition("bs", filter)
+	itemQuery := `
+	SELECT DISTINCT ON (item_slot)
+			item_slot, item_name, item_icon, item_quality, usage_count
+	FROM (
+			SELECT
+					bs.item_slot,
+					MAX(bs.item_name) as item_name,
+					MAX(bs.item_icon) as item_icon,
+					MAX(bs.item_quality) as item_quality,
+					SUM(bs.usage_count) as usage_count
+			FROM build_statistic_segments bs
+			WHERE bs.class = ? AND bs.spec = ?
+			AND ` + mythicPlusEncounters("bs") + ` AND ` + itemCondition + `
+			GROUP BY bs.item_slot, bs.item_id
+	) i
+	ORDER BY item_slot, usage_count DESC`
+	err = s.db.WithContext(ctx).Raw(itemQuery, append([]interface{}{class, spec}, itemArgs...)...).Scan(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segmented top items: %w", err)
+	}
+
+	build := &OptimalBuild{
+		TopTalentImport: topTalent.TalentImport,
+		StatPriority:    statPriority.StatPriority,
+		TopItems:        make(map[string]ItemDetails),
+	}
+	for _, item := range items {
+		build.TopItems[fmt.Sprintf("%d", item.ItemSlot)] = ItemDetails{
+			Name:       item.ItemName,
+			Icon:       item.ItemIcon,
+			Quality:    item.ItemQuality,
+			UsageCount: item.UsageCount,
+		}
+	}
+
+	return build, nil
+}
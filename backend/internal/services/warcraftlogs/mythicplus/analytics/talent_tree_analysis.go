@@ -0,0 +1,222 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	talents "wowperf/internal/models/talents"
+	wrapper "wowperf/internal/wrapper/blizzard"
+)
+
+// ErrTalentTreeNotFound is returned when no talent tree is stored for the class and spec
+var ErrTalentTreeNotFound = errors.New("talent tree not found")
+
+// TalentEntryPickRate represents the pick rate of an entry of a talent node
+type TalentEntryPickRate struct {
+	EntryID   int     `json:"entry_id"`
+	PickCount int     `json:"pick_count"`
+	PickRate  float64 `json:"pick_rate"`
+	AvgRank   float64 `json:"avg_rank"`
+}
+
+// TalentNodePickRate represents the pick rate of a talent node, with one entry per choice for choice nodes
+type TalentNodePickRate struct {
+	NodeID    int                   `json:"node_id"`
+	PickCount int                   `json:"pick_count"`
+	PickRate  float64               `json:"pick_rate"`
+	Entries   []TalentEntryPickRate `json:"entries"`
+}
+
+// HeroTreePickRate represents the share of the builds using a hero talent tree
+type HeroTreePickRate struct {
+	SubTreeID int     `json:"sub_tree_id"`
+	Name      string  `json:"name"`
+	PickCount int     `json:"pick_count"`
+	PickRate  float64 `json:"pick_rate"`
+}
+
+// AnnotatedTalentTree is the talent tree of a spec with the pick rates of its nodes
+// EncounterID is 0 when the pick rates are aggregated over every dungeon. Pick rates are percentages.
+type AnnotatedTalentTree struct {
+	EncounterID int                  `json:"encounter_id"`
+	SampleSize  int                  `json:"sample_size"`
+	Tree        *talents.TalentTree  `json:"tree"`
+	Nodes       []TalentNodePickRate `json:"nodes"`
+	HeroTrees   []HeroTreePickRate   `json:"hero_trees"`
+}
+
+// talentNodeRow is a stored talent node statistic
+type talentNodeRow struct {
+	EncounterID int
+	NodeID      int
+	EntryID     int
+	PickCount   int
+	SampleSize  int
+	AvgRank     float64
+}
+
+// GetTalentTreePickRates retrieves the talent tree of a specific class and spec annotated with the pick rate of each node
+// A nil encounter ID sums the builds of every dungeon.
+func (s *BuildAnalysisService) GetTalentTreePickRates(ctx context.Context, class, spec string, encounterID *int) (*AnnotatedTalentTree, error) {
+	var treeIDs struct {
+		TraitTreeID int
+		SpecID      int
+	}
+	// The builds store the class and spec names without spaces ("DeathKnight", "BeastMastery")
+	err := s.db.WithContext(ctx).
+		Table("talent_trees").
+		Select("trait_tree_id, spec_id").
+		Where("LOWER(REPLACE(class_name, ' ', '')) = LOWER(?) AND LOWER(REPLACE(spec_name, ' ', '')) = LOWER(?)", class, spec).
+		Where("deleted_at IS NULL").
+		Limit(1).
+		Scan(&treeIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find talent tree: %w", err)
+	}
+	if treeIDs.TraitTreeID == 0 {
+		return nil, ErrTalentTreeNotFound
+	}
+
+	tree, err := wrapper.GetFullTalentTree(s.db.WithContext(ctx), treeIDs.TraitTreeID, treeIDs.SpecID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTalentTreeNotFound
+		}
+		return nil, fmt.Errorf("failed to get talent tree: %w", err)
+	}
+
+	var rows []talentNodeRow
+	query := s.db.WithContext(ctx).
+		Table("talent_node_statistics tns").
+		Select("tns.encounter_id, tns.node_id, tns.entry_id, tns.pick_count, tns.sample_size, tns.avg_rank").
+		Where("tns.class = ? AND tns.spec = ? AND tns.deleted_at IS NULL", class, spec)
+
+	if encounterID != nil {
+		query = query.Where("tns.encounter_id = ?", *encounterID)
+	} else {
+		query = query.Where(mythicPlusEncounters("tns"))
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get talent node statistics: %w", err)
+	}
+
+	annotated := &AnnotatedTalentTree{Tree: tree}
+	if encounterID != nil {
+		annotated.EncounterID = *encounterID
+	}
+	annotated.SampleSize, annotated.Nodes = aggregateTalentNodes(rows)
+	annotated.HeroTrees = heroTreePickRates(tree, annotated.Nodes, annotated.SampleSize)
+
+	return annotated, nil
+}
+
+// aggregateTalentNodes sums the talent node statistics of the dungeons and groups the entries by node
+// The sample size of a dungeon is shared by all its rows, so it is only counted once per dungeon.
+func aggregateTalentNodes(rows []talentNodeRow) (int, []TalentNodePickRate) {
+	samples := make(map[int]int)
+	type entryKey struct{ NodeID, EntryID int }
+	picks := make(map[entryKey]int)
+	rankSums := make(map[entryKey]float64)
+
+	for _, row := range rows {
+		samples[row.EncounterID] = row.SampleSize
+		key := entryKey{row.NodeID, row.EntryID}
+		picks[key] += row.PickCount
+		rankSums[key] += row.AvgRank * float64(row.PickCount)
+	}
+
+	sampleSize := 0
+	for _, size := range samples {
+		sampleSize += size
+	}
+	if sampleSize == 0 {
+		return 0, []TalentNodePickRate{}
+	}
+
+	nodes := make(map[int]*TalentNodePickRate)
+	for key, count := range picks {
+		node, exists := nodes[key.NodeID]
+		if !exists {
+			node = &TalentNodePickRate{NodeID: key.NodeID}
+			nodes[key.NodeID] = node
+		}
+
+		node.PickCount += count
+		node.Entries = append(node.Entries, TalentEntryPickRate{
+			EntryID:   key.EntryID,
+			PickCount: count,
+			PickRate:  float64(count) / float64(sampleSize) * 100,
+			AvgRank:   rankSums[key] / float64(count),
+		})
+	}
+
+	result := make([]TalentNodePickRate, 0, len(nodes))
+	for _, node := range nodes {
+		node.PickRate = float64(node.PickCount) / float64(sampleSize) * 100
+		sort.Slice(node.Entries, func(i, j int) bool {
+			return node.Entries[i].PickCount > node.Entries[j].PickCount
+		})
+		result = append(result, *node)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodeID < result[j].NodeID
+	})
+
+	return sampleSize, result
+}
+
+// heroTreePickRates computes the share of each hero talent tree of the spec
+// The selection node is used when the builds store it, otherwise the most picked node of the hero tree.
+func heroTreePickRates(tree *talents.TalentTree, nodes []TalentNodePickRate, sampleSize int) []HeroTreePickRate {
+	heroTrees := []HeroTreePickRate{}
+	if sampleSize == 0 {
+		return heroTrees
+	}
+
+	nodesByID := make(map[int]TalentNodePickRate, len(nodes))
+	for _, node := range nodes {
+		nodesByID[node.NodeID] = node
+	}
+
+	for _, subTreeNode := range tree.SubTreeNodes {
+		selection, hasSelection := nodesByID[subTreeNode.SubTreeNodeID]
+
+		for _, entry := range subTreeNode.Entries {
+			count := 0
+			if hasSelection {
+				for _, picked := range selection.Entries {
+					if picked.EntryID == entry.EntryID {
+						count = picked.PickCount
+					}
+				}
+			} else {
+				for _, heroNode := range tree.HeroNodes {
+					if heroNode.SubTreeID != entry.TraitSubTreeID {
+						continue
+					}
+					if picked, ok := nodesByID[heroNode.NodeID]; ok && picked.PickCount > count {
+						count = picked.PickCount
+					}
+				}
+			}
+
+			heroTrees = append(heroTrees, HeroTreePickRate{
+				SubTreeID: entry.TraitSubTreeID,
+				Name:      entry.Name,
+				PickCount: count,
+				PickRate:  float64(count) / float64(sampleSize) * 100,
+			})
+		}
+	}
+
+	sort.Slice(heroTrees, func(i, j int) bool {
+		return heroTrees[i].PickCount > heroTrees[j].PickCount
+	})
+
+	return heroTrees
+}
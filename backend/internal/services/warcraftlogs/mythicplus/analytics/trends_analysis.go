@@ -0,0 +1,264 @@
+package WarcraftLogsMythicPlusBuildAnalysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrendFilter restricts the weekly snapshots used by the trends
+// A nil encounter ID aggregates every Mythic+ dungeon, nil bounds keep every period.
+type TrendFilter struct {
+	EncounterID *int
+	From        *time.Time
+	To          *time.Time
+}
+
+// UsageTrendPoint represents the usage of an item or a talent import for a weekly reset period
+// SampleSize is the number of builds of the period, UsagePercentage the share of them using the choice.
+type UsageTrendPoint struct {
+	PeriodStart      time.Time `json:"period_start"`
+	UsageCount       int       `json:"usage_count"`
+	SampleSize       int       `json:"sample_size"`
+	UsagePercentage  float64   `json:"usage_percentage"`
+	AvgKeystoneLevel float64   `json:"avg_keystone_level"`
+}
+
+// ItemTrend represents the weekly popularity of an item in a slot
+type ItemTrend struct {
+	ItemSlot int               `json:"item_slot"`
+	ItemID   int               `json:"item_id"`
+	ItemName string            `json:"item_name"`
+	ItemIcon string            `json:"item_icon"`
+	Points   []UsageTrendPoint `json:"points"`
+}
+
+// TalentTrend represents the weekly popularity of a talent import
+type TalentTrend struct {
+	TalentImport string            `json:"talent_import"`
+	Points       []UsageTrendPoint `json:"points"`
+}
+
+// StatTrendPoint represents the average value of a stat for a weekly reset period
+type StatTrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	AvgValue    float64   `json:"avg_value"`
+	SampleSize  int       `json:"sample_size"`
+}
+
+// StatTrend represents the weekly average value of a stat
+type StatTrend struct {
+	StatName     string           `json:"stat_name"`
+	StatCategory string           `json:"stat_category"`
+	Points       []StatTrendPoint `json:"points"`
+}
+
+// usageSnapshotRow is a snapshot row aggregated over the encounters of a period
+type usageSnapshotRow struct {
+	PeriodStart      time.Time
+	ChoiceKey        string
+	ItemID           int
+	ItemName         string
+	ItemIcon         string
+	UsageCount       int
+	AvgKeystoneLevel float64
+}
+
+// applyTrendFilter restricts a snapshot query to the encounter and the periods of the filter
+func applyTrendFilter(query *gorm.DB, alias string, filter TrendFilter) *gorm.DB {
+	if filter.EncounterID != nil {
+		query = query.Where(alias+".encounter_id = ?", *filter.EncounterID)
+	} else {
+		query = query.Where(mythicPlusEncounters(alias))
+	}
+	if filter.From != nil {
+		query = query.Where(alias+".period_start >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(alias+".period_start <= ?", *filter.To)
+	}
+	return query
+}
+
+// GetItemTrends retrieves the weekly popularity of the items of a slot for a specific class and spec
+// A nil item ID returns the limit most used items of the slot over the periods
+func (s *BuildAnalysisService) GetItemTrends(ctx context.Context, class, spec string, slot int, itemID *int, filter TrendFilter, limit int) ([]ItemTrend, error) {
+	var rows []usageSnapshotRow
+
+	query := s.db.WithContext(ctx).
+		Table("build_statistic_snapshots bss").
+		Select(`bss.period_start,
+			bss.item_id::text AS choice_key,
+			bss.item_id,
+			MAX(bss.item_name) AS item_name,
+			MAX(bss.item_icon) AS item_icon,
+			SUM(bss.usage_count) AS usage_count,
+			COALESCE(SUM(bss.usage_count * bss.avg_keystone_level) / NULLIF(SUM(bss.usage_count), 0), 0) AS avg_keystone_level`).
+		Where("bss.class = ? AND bss.spec = ? AND bss.item_slot = ? AND bss.deleted_at IS NULL", class, spec, slot)
+	query = applyTrendFilter(query, "bss", filter)
+
+	if err := query.Group("bss.period_start, bss.item_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get item trends: %w", err)
+	}
+
+	var selected map[string]bool
+	if itemID != nil {
+		selected = map[string]bool{fmt.Sprintf("%d", *itemID): true}
+	}
+
+	trends := make([]ItemTrend, 0)
+	for _, series := range buildUsageTrends(rows, selected, limit) {
+		trends = append(trends, ItemTrend{
+			ItemSlot: slot,
+			ItemID:   series.first.ItemID,
+			ItemName: series.first.ItemName,
+			ItemIcon: series.first.ItemIcon,
+			Points:   series.points,
+		})
+	}
+	return trends, nil
+}
+
+// GetTalentTrends retrieves the weekly popularity of the talent imports of a specific class and spec
+// An empty talent import returns the limit most used imports over the periods
+func (s *BuildAnalysisService) GetTalentTrends(ctx context.Context, class, spec, talentImport string, filter TrendFilter, limit int) ([]TalentTrend, error) {
+	var rows []usageSnapshotRow
+
+	query := s.db.WithContext(ctx).
+		Table("talent_statistic_snapshots tss").
+		Select(`tss.period_start,
+			tss.talent_import AS choice_key,
+			SUM(tss.usage_count) AS usage_count,
+			COALESCE(SUM(tss.usage_count * tss.avg_keystone_level) / NULLIF(SUM(tss.usage_count), 0), 0) AS avg_keystone_level`).
+		Where("tss.class = ? AND tss.spec = ? AND tss.deleted_at IS NULL", class, spec)
+	query = applyTrendFilter(query, "tss", filter)
+
+	if err := query.Group("tss.period_start, tss.talent_import").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get talent trends: %w", err)
+	}
+
+	var selected map[string]bool
+	if talentImport != "" {
+		selected = map[string]bool{talentImport: true}
+	}
+
+	trends := make([]TalentTrend, 0)
+	for _, series := range buildUsageTrends(rows, selected, limit) {
+		trends = append(trends, TalentTrend{
+			TalentImport: series.first.ChoiceKey,
+			Points:       series.points,
+		})
+	}
+	return trends, nil
+}
+
+// GetStatTrends retrieves the weekly average value of the stats of a specific class and spec
+func (s *BuildAnalysisService) GetStatTrends(ctx context.Context, class, spec string, filter TrendFilter) ([]StatTrend, error) {
+	var rows []struct {
+		PeriodStart  time.Time
+		StatName     string
+		StatCategory string
+		AvgValue     float64
+		SampleSize   int
+	}
+
+	query := s.db.WithContext(ctx).
+		Table("stat_statistic_snapshots sss").
+		Select(`sss.period_start,
+			sss.stat_name,
+			MAX(sss.stat_category) AS stat_category,
+			COALESCE(SUM(sss.avg_value * sss.sample_size) / NULLIF(SUM(sss.sample_size), 0), 0) AS avg_value,
+			SUM(sss.sample_size) AS sample_size`).
+		Where("sss.class = ? AND sss.spec = ? AND sss.deleted_at IS NULL", class, spec)
+	query = applyTrendFilter(query, "sss", filter)
+
+	if err := query.Group("sss.period_start, sss.stat_name").Order("sss.stat_name ASC, sss.period_start ASC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stat trends: %w", err)
+	}
+
+	trends := make([]StatTrend, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, exists := index[row.StatName]
+		if !exists {
+			i = len(trends)
+			index[row.StatName] = i
+			trends = append(trends, StatTrend{
+				StatName:     row.StatName,
+				StatCategory: row.StatCategory,
+			})
+		}
+		trends[i].Points = append(trends[i].Points, StatTrendPoint{
+			PeriodStart: row.PeriodStart,
+			AvgValue:    row.AvgValue,
+			SampleSize:  row.SampleSize,
+		})
+	}
+	return trends, nil
+}
+
+// usageSeries is the weekly usage of a choice, built from the snapshot rows
+type usageSeries struct {
+	first  usageSnapshotRow
+	total  int
+	points []UsageTrendPoint
+}
+
+// buildUsageTrends groups the snapshot rows by choice and computes the usage percentage of each period
+// The sample size of a period is the usage of every choice of the period. Only the selected choices are kept,
+// or the limit most used ones when there is no selection.
+func buildUsageTrends(rows []usageSnapshotRow, selected map[string]bool, limit int) []*usageSeries {
+	sampleSizes := make(map[time.Time]int)
+	for _, row := range rows {
+		sampleSizes[row.PeriodStart] += row.UsageCount
+	}
+
+	seriesByKey := make(map[string]*usageSeries)
+	for _, row := range rows {
+		if selected != nil && !selected[row.ChoiceKey] {
+			continue
+		}
+
+		series, exists := seriesByKey[row.ChoiceKey]
+		if !exists {
+			series = &usageSeries{first: row}
+			seriesByKey[row.ChoiceKey] = series
+		}
+
+		point := UsageTrendPoint{
+			PeriodStart:      row.PeriodStart,
+			UsageCount:       row.UsageCount,
+			SampleSize:       sampleSizes[row.PeriodStart],
+			AvgKeystoneLevel: row.AvgKeystoneLevel,
+		}
+		if point.SampleSize > 0 {
+			point.UsagePercentage = float64(point.UsageCount) / float64(point.SampleSize) * 100
+		}
+
+		series.total += row.UsageCount
+		series.points = append(series.points, point)
+	}
+
+	result := make([]*usageSeries, 0, len(seriesByKey))
+	for _, series := range seriesByKey {
+		sort.Slice(series.points, func(i, j int) bool {
+			return series.points[i].PeriodStart.Before(series.points[j].PeriodStart)
+		})
+		result = append(result, series)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].total != result[j].total {
+			return result[i].total > result[j].total
+		}
+		return result[i].first.ChoiceKey < result[j].first.ChoiceKey
+	})
+
+	if selected == nil && limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package warcraftlogsBuildsArchive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalObjectStore stores the archives as files under a root directory
+type LocalObjectStore struct {
+	root string
+}
+
+// NewLocalObjectStore creates a new instance of LocalObjectStore
+func NewLocalObjectStore(root string) *LocalObjectStore {
+	return &LocalObjectStore{
+		root: root,
+	}
+}
+
+// Name returns the store type
+func (s *LocalObjectStore) Name() string {
+	return StoreTypeLocal
+}
+
+// Put writes an object, the file is renamed in place once fully written
+func (s *LocalObjectStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write archive %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get reads an object
+func (s *LocalObjectStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to read archive %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// path resolves the file of a key, keys escaping the root directory are rejected
+func (s *LocalObjectStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if cleaned == "." || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid archive key: %s", key)
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
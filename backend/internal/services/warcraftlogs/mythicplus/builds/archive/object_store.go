@@ -0,0 +1,92 @@
+package warcraftlogsBuildsArchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// Object store types
+const (
+	StoreTypeLocal = "local"
+	StoreTypeS3    = "s3"
+)
+
+// ErrObjectNotFound is returned when an archive object does not exist in the store
+var ErrObjectNotFound = errors.New("archive object not found")
+
+// ObjectStore stores the compressed archives of the reports
+type ObjectStore interface {
+	// Put writes an object, replacing any object stored under the same key
+	Put(ctx context.Context, key string, data []byte) error
+	// Get reads an object, ErrObjectNotFound is returned if it does not exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Name returns the store type recorded in the archive manifest
+	Name() string
+}
+
+// NewObjectStore creates the object store described by the retention configuration
+// The S3 credentials are read from ARCHIVE_S3_ACCESS_KEY_ID and ARCHIVE_S3_SECRET_ACCESS_KEY.
+func NewObjectStore(config workflowsModels.ArchiveStoreConfig) (ObjectStore, error) {
+	switch config.Type {
+	case "", StoreTypeLocal:
+		path := config.LocalPath
+		if path == "" {
+			path = "data/archives"
+		}
+		return NewLocalObjectStore(path), nil
+	case StoreTypeS3:
+		if config.Bucket == "" || config.Endpoint == "" {
+			return nil, fmt.Errorf("s3 archive store requires a bucket and an endpoint")
+		}
+		accessKeyID := os.Getenv("ARCHIVE_S3_ACCESS_KEY_ID")
+		secretAccessKey := os.Getenv("ARCHIVE_S3_SECRET_ACCESS_KEY")
+		if accessKeyID == "" || secretAccessKey == "" {
+			return nil, fmt.Errorf("missing ARCHIVE_S3_ACCESS_KEY_ID or ARCHIVE_S3_SECRET_ACCESS_KEY for the s3 archive store")
+		}
+		return NewS3ObjectStore(S3Config{
+			Endpoint:        config.Endpoint,
+			Region:          config.Region,
+			Bucket:          config.Bucket,
+			Prefix:          config.Prefix,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown archive store type: %s", config.Type)
+	}
+}
+
+// Compress gzips the data of an archive
+func Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress reads the data of a gzipped archive
+func Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,95 @@
+package warcraftlogsBuildsArchive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalObjectStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalObjectStore(t.TempDir())
+
+	require.NoError(t, store.Put(ctx, "reports/abc/1/raw_data.json.gz", []byte("first")))
+	require.NoError(t, store.Put(ctx, "reports/abc/1/raw_data.json.gz", []byte("second")))
+
+	data, err := store.Get(ctx, "reports/abc/1/raw_data.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	_, err = store.Get(ctx, "reports/abc/2/raw_data.json.gz")
+	assert.True(t, errors.Is(err, ErrObjectNotFound))
+
+	assert.Error(t, store.Put(ctx, "../outside", []byte("data")))
+}
+
+func TestS3ObjectStore(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key-id/") ||
+			!strings.Contains(auth, "/eu-west-3/s3/aws4_request") ||
+			r.Header.Get("x-amz-content-sha256") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3ObjectStore(S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-3",
+		Bucket:          "archives",
+		Prefix:          "wowperf/",
+		AccessKeyID:     "key-id",
+		SecretAccessKey: "secret",
+	})
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "reports/abc/1/raw_data.json.gz", []byte("payload")))
+	assert.Contains(t, objects, "/archives/wowperf/reports/abc/1/raw_data.json.gz")
+
+	data, err := store.Get(ctx, "reports/abc/1/raw_data.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), data)
+
+	_, err = store.Get(ctx, "reports/abc/2/raw_data.json.gz")
+	assert.True(t, errors.Is(err, ErrObjectNotFound))
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"ability":"Shadow Word: Pain"}`, 100))
+
+	compressed, err := Compress(data)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(data))
+
+	decompressed, err := Decompress(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+}
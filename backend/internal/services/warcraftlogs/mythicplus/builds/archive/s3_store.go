@@ -0,0 +1,180 @@
+package warcraftlogsBuildsArchive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Config contains the settings of an S3-compatible object store
+type S3Config struct {
+	Endpoint        string // e.g. "https://s3.eu-west-3.amazonaws.com" or a MinIO URL
+	Region          string
+	Bucket          string
+	Prefix          string // Prepended to every object key
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3ObjectStore stores the archives in an S3-compatible bucket
+// Requests use path-style URLs and are signed with AWS Signature Version 4.
+type S3ObjectStore struct {
+	config     S3Config
+	httpClient *http.Client
+}
+
+// NewS3ObjectStore creates a new instance of S3ObjectStore
+func NewS3ObjectStore(config S3Config) *S3ObjectStore {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	config.Prefix = strings.Trim(config.Prefix, "/")
+
+	return &S3ObjectStore{
+		config:     config,
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Name returns the store type
+func (s *S3ObjectStore) Name() string {
+	return StoreTypeS3
+}
+
+// Put writes an object
+func (s *S3ObjectStore) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to put archive %s: status %d: %s", key, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Get reads an object
+func (s *S3ObjectStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to get archive %s: status %d: %s", key, resp.StatusCode, string(body))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// do sends a signed request for an object
+func (s *S3ObjectStore) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	objectKey := key
+	if s.config.Prefix != "" {
+		objectKey = s.config.Prefix + "/" + key
+	}
+	path := "/" + s.config.Bucket + "/" + objectKey
+
+	req, err := http.NewRequestWithContext(ctx, method, s.config.Endpoint+escapeS3Path(path), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive request for %s: %w", key, err)
+	}
+	req.ContentLength = int64(len(body))
+
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send archive request for %s: %w", key, err)
+	}
+	return resp, nil
+}
+
+// sign adds the Signature Version 4 headers to a request
+func (s *S3ObjectStore) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// escapeS3Path escapes a path the way Signature Version 4 expects, slashes are kept
+func escapeS3Path(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,117 @@
+package warcraftlogsBuildsQueries
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"wowperf/internal/services/warcraftlogs"
+)
+
+// Events data types fetched for the ability usage analysis
+const (
+	EventsDataTypeCasts      = "Casts"
+	EventsDataTypeInterrupts = "Interrupts"
+	EventsDataTypeDispels    = "Dispels"
+)
+
+// AbilityUsageEventsDataTypes lists the events data types of the ability usage analysis
+var AbilityUsageEventsDataTypes = []string{
+	EventsDataTypeCasts,
+	EventsDataTypeInterrupts,
+	EventsDataTypeDispels,
+}
+
+// reportEventsPageLimit is the maximum number of events of a page accepted by the API
+const reportEventsPageLimit = 10000
+
+// ReportEventsComplexity is the estimated complexity of an events page in a batched document
+const ReportEventsComplexity = 3
+
+// GetReportEventsQuery fetches a page of the friendly events of a fight
+// startTime is the nextPageTimestamp of the previous page, it is omitted for the first page
+const GetReportEventsQuery = `
+query getReportEvents($code: String!, $fightID: Int!, $dataType: EventDataType!, $startTime: Float) {
+    reportData {
+        report(code: $code) {
+            events(
+                fightIDs: [$fightID]
+                dataType: $dataType
+                hostilityType: Friendlies
+                startTime: $startTime
+                limit: 10000
+            ) {
+                data
+                nextPageTimestamp
+            }
+        }
+    }
+}`
+
+// ReportEvent represents an event of a report
+// Only the fields used by the ability usage analysis are kept.
+type ReportEvent struct {
+	Timestamp     int64  `json:"timestamp"`
+	Type          string `json:"type"` // e.g. "cast", "begincast", "interrupt", "dispel"
+	SourceID      int    `json:"sourceID"`
+	TargetID      int    `json:"targetID"`
+	AbilityGameID int64  `json:"abilityGameID"`
+}
+
+// ReportEventsBatchQuery returns the GetReportEventsQuery lookup of a fight for the query batcher
+// startTime is nil for the first page. The batched response is parsed with ParseReportEventsResponse.
+func ReportEventsBatchQuery(code string, fightID int, dataType string, startTime *int64) warcraftlogs.BatchQuery {
+	startTimeArgument := ""
+	if startTime != nil {
+		startTimeArgument = fmt.Sprintf("\n        startTime: %d", *startTime)
+	}
+
+	return warcraftlogs.BatchQuery{
+		Root: "reportData",
+		Selection: fmt.Sprintf(`report(code: %s) {
+    events(
+        fightIDs: [%d]
+        dataType: %s
+        hostilityType: Friendlies%s
+        limit: %d
+    ) {
+        data
+        nextPageTimestamp
+    }
+}`, warcraftlogs.GraphQLString(code), fightID, dataType, startTimeArgument, reportEventsPageLimit),
+		Complexity: ReportEventsComplexity,
+	}
+}
+
+// ParseReportEventsResponse parses a page of events
+// It returns the events and the start time of the next page, nil for the last page
+func ParseReportEventsResponse(response []byte) ([]ReportEvent, *int64, error) {
+	var result struct {
+		ReportData struct {
+			Report *struct {
+				Events *struct {
+					Data              []ReportEvent `json:"data"`
+					NextPageTimestamp *float64      `json:"nextPageTimestamp"`
+				} `json:"events"`
+			} `json:"report"`
+		} `json:"reportData"`
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal report events response: %w", err)
+	}
+
+	if result.ReportData.Report == nil {
+		return nil, nil, fmt.Errorf("report not found")
+	}
+	if result.ReportData.Report.Events == nil {
+		return nil, nil, fmt.Errorf("no events in report response")
+	}
+
+	events := result.ReportData.Report.Events
+	if events.NextPageTimestamp == nil {
+		return events.Data, nil, nil
+	}
+
+	next := int64(*events.NextPageTimestamp)
+	return events.Data, &next, nil
+}
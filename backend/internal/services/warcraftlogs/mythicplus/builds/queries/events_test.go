@@ -0,0 +1,55 @@
+package warcraftlogsBuildsQueries
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReportEventsResponse(t *testing.T) {
+	response := []byte(`{
+		"reportData": {
+			"report": {
+				"events": {
+					"data": [
+						{"timestamp": 1500, "type": "cast", "sourceID": 3, "targetID": 12, "abilityGameID": 1719},
+						{"timestamp": 2100, "type": "interrupt", "sourceID": 7, "targetID": 40, "abilityGameID": 6552, "extraAbilityGameID": 1234}
+					],
+					"nextPageTimestamp": 84210
+				}
+			}
+		}
+	}`)
+
+	events, next, err := ParseReportEventsResponse(response)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "cast", events[0].Type)
+	assert.Equal(t, int64(1719), events[0].AbilityGameID)
+	assert.Equal(t, 7, events[1].SourceID)
+	require.NotNil(t, next)
+	assert.Equal(t, int64(84210), *next)
+
+	// Last page
+	_, next, err = ParseReportEventsResponse([]byte(`{"reportData": {"report": {"events": {"data": [], "nextPageTimestamp": null}}}}`))
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	_, _, err = ParseReportEventsResponse([]byte(`{"reportData": {"report": null}}`))
+	assert.Error(t, err)
+}
+
+func TestReportEventsBatchQuery(t *testing.T) {
+	first := ReportEventsBatchQuery("HZMACFrw7P198yLb", 95, EventsDataTypeCasts, nil)
+	assert.Equal(t, "reportData", first.Root)
+	assert.Contains(t, first.Selection, `report(code: "HZMACFrw7P198yLb")`)
+	assert.Contains(t, first.Selection, "dataType: Casts")
+	assert.False(t, strings.Contains(first.Selection, "startTime"))
+
+	start := int64(84210)
+	next := ReportEventsBatchQuery("HZMACFrw7P198yLb", 95, EventsDataTypeInterrupts, &start)
+	assert.Contains(t, next.Selection, "startTime: 84210")
+	assert.Equal(t, ReportEventsComplexity, next.Complexity)
+}
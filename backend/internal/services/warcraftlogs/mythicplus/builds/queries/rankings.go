@@ -4,29 +4,69 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 
 	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	"wowperf/internal/services/warcraftlogs"
 
 	"github.com/lib/pq"
 )
 
+// classRankingsSelection returns the selection of a rankings page of an encounter
+// The arguments are GraphQL values, variables for ClassRankingsQuery and inlined literals for the batched lookups.
+// An empty difficulty omits the argument.
+func classRankingsSelection(encounterID, className, specName, page, difficulty string) string {
+	difficultyArgument := ""
+	if difficulty != "" {
+		difficultyArgument = "\n        difficulty: " + difficulty
+	}
+
+	return fmt.Sprintf(`encounter(id: %s) {
+    name
+    characterRankings(
+        leaderboard: LogsOnly
+        className: %s
+        specName: %s
+        page: %s%s
+    )
+}`, encounterID, className, specName, page, difficultyArgument)
+}
+
 // ClassRankingsQuery defines the GraphQL query to fetch rankings
 // Note : The API return 100 rankings but i filter after to only get the first 20 char
-const ClassRankingsQuery = `
-query getClassRankings($encounterId: Int!, $className: String!, $specName: String!, $page: Int!) {
-    worldData {
-        encounter(id: $encounterId) {
-            name
-            characterRankings(
-                leaderboard: LogsOnly
-                className: $className
-                specName: $specName
-                page: $page
-            )
-        }
-    }
+// difficulty is only set for raid bosses, Mythic+ dungeons use the default difficulty
+var ClassRankingsQuery = `
+query getClassRankings($encounterId: Int!, $className: String!, $specName: String!, $page: Int!, $difficulty: Int) {
+worldData {
+` + classRankingsSelection("$encounterId", "$className", "$specName", "$page", "$difficulty") + `
+}
 }`
 
+// ClassRankingsComplexity is the estimated complexity of a rankings page in a batched document
+const ClassRankingsComplexity = 2
+
+// ClassRankingsBatchQuery returns the ClassRankingsQuery lookup of an encounter for the query batcher
+// difficulty is only set for raid bosses, 0 keeps the default difficulty.
+// The batched response is parsed with ParseRankingsResponse.
+func ClassRankingsBatchQuery(encounterID uint32, className, specName string, page int, difficulty uint32) warcraftlogs.BatchQuery {
+	difficultyValue := ""
+	if difficulty != 0 {
+		difficultyValue = strconv.FormatUint(uint64(difficulty), 10)
+	}
+
+	return warcraftlogs.BatchQuery{
+		Root: "worldData",
+		Selection: classRankingsSelection(
+			strconv.FormatUint(uint64(encounterID), 10),
+			warcraftlogs.GraphQLString(className),
+			warcraftlogs.GraphQLString(specName),
+			strconv.Itoa(page),
+			difficultyValue,
+		),
+		Complexity: ClassRankingsComplexity,
+	}
+}
+
 // ParseRankingsResponse processes the API response and returns only the top 20 rankings
 // The rankings are already sorted by score from the API
 func ParseRankingsResponse(response []byte, encounterId uint) ([]*warcraftlogsBuilds.ClassRanking, error) {
@@ -165,3 +165,16 @@ func TestLiveRankingsQuery(t *testing.T) {
 	// Log the details
 	t.Logf("Found %d rankings", len(rankings))
 }
+
+func TestClassRankingsBatchQuery(t *testing.T) {
+	dungeon := ClassRankingsBatchQuery(12831, "Priest", "Discipline", 1, 0)
+	assert.Equal(t, "worldData", dungeon.Root)
+	assert.Contains(t, dungeon.Selection, `className: "Priest"`)
+	assert.NotContains(t, dungeon.Selection, "difficulty")
+
+	raid := ClassRankingsBatchQuery(3009, "Priest", "Discipline", 1, 5)
+	assert.Contains(t, raid.Selection, "difficulty: 5")
+
+	// The standalone document uses the same selection with variables
+	assert.Contains(t, ClassRankingsQuery, classRankingsSelection("$encounterId", "$className", "$specName", "$page", "$difficulty"))
+}
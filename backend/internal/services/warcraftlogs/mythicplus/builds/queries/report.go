@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
 	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	"wowperf/internal/services/warcraftlogs"
 )
 
 // PlayerSpec represents a player's specification details
@@ -22,8 +26,8 @@ type PlayerSpec struct {
 	// Additional fields from API
 	MinItemLevel   float64 `json:"minItemLevel,omitempty"`
 	MaxItemLevel   float64 `json:"maxItemLevel,omitempty"`
-	PotionUse      int     `json:"potionUse,omitempty"`
-	HealthstoneUse int     `json:"healthstoneUse,omitempty"`
+	PotionUse      *int    `json:"potionUse,omitempty"` // nil when the report does not track it
+	HealthstoneUse *int    `json:"healthstoneUse,omitempty"`
 
 	// Combat info
 	CombatantInfo json.RawMessage `json:"combatantInfo,omitempty"`
@@ -82,27 +86,169 @@ type GearItem struct {
 	SetID int `json:"setID,omitempty"`
 }
 
-const GetReportTableQuery = `
+// reportTableSelection returns the selection of the table, fights and ranked characters of a report fight
+// The arguments are GraphQL values, variables for GetReportTableQuery and inlined literals for the batched lookups.
+func reportTableSelection(code, fightID, encounterID string) string {
+	return fmt.Sprintf(`report(code: %[1]s) {
+    table(fightIDs: [%[2]s], encounterID: %[3]s)
+    fights(fightIDs: [%[2]s]) {
+        id
+        encounterID
+        friendlyPlayers
+        keystoneTime
+        keystoneLevel
+        keystoneAffixes
+        startTime
+        endTime
+        dungeonPulls {
+            id
+            encounterID
+            name
+            startTime
+            endTime
+            kill
+        }
+    }
+    rankedCharacters {
+        id
+        canonicalID
+        name
+        server {
+            name
+            slug
+            region {
+                compactName
+            }
+        }
+    }
+}`, code, fightID, encounterID)
+}
+
+// GetReportTableQuery fetches the table, the fights and the ranked characters of a report fight
+var GetReportTableQuery = `
 query getReportTableQuery($code: String!, $fightID: Int!, $encounterID: Int!) {
+reportData {
+` + reportTableSelection("$code", "$fightID", "$encounterID") + `
+}
+}
+`
+
+// Estimated complexity of the report lookups in a batched document
+const (
+	ReportTableComplexity   = 2 // The table of a fight is the largest lookup
+	ReportTalentsComplexity = 1
+)
+
+// ReportTableBatchQuery returns the GetReportTableQuery lookup of a fight for the query batcher
+// The batched response is parsed with ParseReportDetailsResponse.
+func ReportTableBatchQuery(code string, fightID int, encounterID uint) warcraftlogs.BatchQuery {
+	return warcraftlogs.BatchQuery{
+		Root:       "reportData",
+		Selection:  reportTableSelection(warcraftlogs.GraphQLString(code), strconv.Itoa(fightID), strconv.FormatUint(uint64(encounterID), 10)),
+		Complexity: ReportTableComplexity,
+	}
+}
+
+// ReportTalentsBatchQuery converts the talents query built by ParseReportDetailsResponse for the query batcher
+// The batched response is parsed with ParseReportTalentsResponse.
+func ReportTalentsBatchQuery(talentsQuery string) (warcraftlogs.BatchQuery, error) {
+	return warcraftlogs.BatchQueryFromDocument(talentsQuery, ReportTalentsComplexity)
+}
+
+const GetReportFightsQuery = `
+query getReportFightsQuery($code: String!) {
     reportData {
         report(code: $code) {
-            table(
-                fightIDs: [$fightID]
-                encounterID: $encounterID
-            )
-            fights(fightIDs: [$fightID]) {
+            fights {
                 id
                 encounterID
-                friendlyPlayers
+                name
+                startTime
                 keystoneTime
                 keystoneLevel
-                keystoneAffixes
             }
         }
     }
 }
 `
 
+// ReportFight represents a Mythic+ fight of a report
+type ReportFight struct {
+	ID            int    `json:"id"`
+	EncounterID   uint   `json:"encounterID"`
+	Name          string `json:"name"`
+	StartTime     int64  `json:"startTime"` // Relative to the start of the report, in milliseconds
+	KeystoneTime  int64  `json:"keystoneTime"`
+	KeystoneLevel int    `json:"keystoneLevel"`
+}
+
+// reportCodePattern matches a WarcraftLogs report code
+var reportCodePattern = regexp.MustCompile(`^[a-zA-Z0-9]{16}$`)
+
+// ParseReportReference extracts the report code and the fight ID from a report code or a report URL
+// Example: "https://www.warcraftlogs.com/reports/g9Lhy8JmkV1xQ3Gj#fight=26&type=damage-done" -> "g9Lhy8JmkV1xQ3Gj", 26
+// The fight ID is 0 when the reference does not target a fight.
+func ParseReportReference(reference string) (string, int, error) {
+	reference = strings.TrimSpace(reference)
+	if reportCodePattern.MatchString(reference) {
+		return reference, 0, nil
+	}
+
+	parsed, err := url.Parse(reference)
+	if err != nil || !strings.HasSuffix(parsed.Hostname(), "warcraftlogs.com") {
+		return "", 0, fmt.Errorf("invalid report reference: %s", reference)
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 2 || segments[len(segments)-2] != "reports" || !reportCodePattern.MatchString(segments[len(segments)-1]) {
+		return "", 0, fmt.Errorf("invalid report URL: %s", reference)
+	}
+	code := segments[len(segments)-1]
+
+	// The fight is in the fragment (#fight=26) or in the query (?fight=26)
+	fightID := 0
+	fight := parsed.Query().Get("fight")
+	if fragment, err := url.ParseQuery(parsed.Fragment); err == nil && fragment.Get("fight") != "" {
+		fight = fragment.Get("fight")
+	}
+	if fight != "" && fight != "last" {
+		if fightID, err = strconv.Atoi(fight); err != nil || fightID < 0 {
+			return "", 0, fmt.Errorf("invalid fight in report URL: %s", reference)
+		}
+	}
+
+	return code, fightID, nil
+}
+
+// ParseReportFightsResponse parses the fights of a report and keeps the Mythic+ fights
+func ParseReportFightsResponse(response []byte) ([]ReportFight, error) {
+	var result struct {
+		ReportData struct {
+			Report *struct {
+				Fights []ReportFight `json:"fights"`
+			} `json:"report"`
+		} `json:"reportData"`
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report fights response: %w", err)
+	}
+
+	if result.ReportData.Report == nil {
+		return nil, fmt.Errorf("report not found")
+	}
+
+	fights := make([]ReportFight, 0)
+	for _, fight := range result.ReportData.Report.Fights {
+		if fight.EncounterID == 0 || fight.KeystoneLevel == 0 {
+			continue
+		}
+		fights = append(fights, fight)
+	}
+
+	return fights, nil
+}
+
 // buildTalentsQuery to build the talents query
 func buildTalentsQuery(code string, fightID int, players []PlayerSpec) string {
 	var talentFields []string
@@ -156,12 +302,16 @@ func ParseReportDetailsResponse(response []byte, code string, fightID int, encou
 					} `json:"data"`
 				} `json:"table"`
 				Fights []struct {
-					ID              int   `json:"id"`
-					KeystoneTime    int64 `json:"keystoneTime"`
-					KeystoneLevel   int   `json:"keystoneLevel"`
-					KeystoneAffixes []int `json:"keystoneAffixes"`
-					FriendlyPlayers []int `json:"friendlyPlayers"`
+					ID              int                              `json:"id"`
+					KeystoneTime    int64                            `json:"keystoneTime"`
+					KeystoneLevel   int                              `json:"keystoneLevel"`
+					KeystoneAffixes []int                            `json:"keystoneAffixes"`
+					FriendlyPlayers []int                            `json:"friendlyPlayers"`
+					StartTime       int64                            `json:"startTime"`
+					EndTime         int64                            `json:"endTime"`
+					DungeonPulls    []warcraftlogsBuilds.DungeonPull `json:"dungeonPulls"`
 				} `json:"fights"`
+				RankedCharacters []warcraftlogsBuilds.RankedCharacter `json:"rankedCharacters"`
 			} `json:"report"`
 		} `json:"reportData"`
 		Errors []struct {
@@ -242,6 +392,19 @@ func ParseReportDetailsResponse(response []byte, code string, fightID int, encou
 	if report.PlayerDetailsTanks, err = json.Marshal(tableData.PlayerDetails.Tanks); err != nil {
 		return nil, "", fmt.Errorf("failed to marshal player details tanks data: %w", err)
 	}
+	if report.Fights, err = json.Marshal(warcraftlogsBuilds.ReportFightTimeline{
+		ID:           fight.ID,
+		StartTime:    fight.StartTime,
+		EndTime:      fight.EndTime,
+		DungeonPulls: fight.DungeonPulls,
+	}); err != nil {
+		return nil, "", fmt.Errorf("failed to marshal fights data: %w", err)
+	}
+	if len(reportData.RankedCharacters) > 0 {
+		if report.RankedCharacters, err = json.Marshal(reportData.RankedCharacters); err != nil {
+			return nil, "", fmt.Errorf("failed to marshal ranked characters data: %w", err)
+		}
+	}
 
 	report.FriendlyPlayers = intSliceToInt64Array(fight.FriendlyPlayers)
 
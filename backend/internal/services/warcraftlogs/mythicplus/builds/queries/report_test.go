@@ -89,3 +89,66 @@ func TestLiveReportQuery(t *testing.T) {
 	assert.NotNil(t, report.PlayerDetailsHealers)
 	assert.NotNil(t, report.PlayerDetailsTanks)
 }
+
+func TestParseReportReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		code      string
+		fightID   int
+		wantErr   bool
+	}{
+		{reference: "g9Lhy8JmkV1xQ3Gj", code: "g9Lhy8JmkV1xQ3Gj"},
+		{reference: " https://www.warcraftlogs.com/reports/g9Lhy8JmkV1xQ3Gj ", code: "g9Lhy8JmkV1xQ3Gj"},
+		{reference: "https://www.warcraftlogs.com/reports/g9Lhy8JmkV1xQ3Gj#fight=26&type=damage-done", code: "g9Lhy8JmkV1xQ3Gj", fightID: 26},
+		{reference: "https://fr.warcraftlogs.com/reports/g9Lhy8JmkV1xQ3Gj?fight=3", code: "g9Lhy8JmkV1xQ3Gj", fightID: 3},
+		{reference: "https://www.warcraftlogs.com/reports/g9Lhy8JmkV1xQ3Gj#fight=last", code: "g9Lhy8JmkV1xQ3Gj"},
+		{reference: "https://www.example.com/reports/g9Lhy8JmkV1xQ3Gj", wantErr: true},
+		{reference: "https://www.warcraftlogs.com/character/eu/hyjal/zorthar", wantErr: true},
+		{reference: "not-a-report", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		code, fightID, err := ParseReportReference(tt.reference)
+		if tt.wantErr {
+			assert.Error(t, err, tt.reference)
+			continue
+		}
+		require.NoError(t, err, tt.reference)
+		assert.Equal(t, tt.code, code, tt.reference)
+		assert.Equal(t, tt.fightID, fightID, tt.reference)
+	}
+}
+
+func TestParseReportFightsResponse(t *testing.T) {
+	response := []byte(`{
+		"reportData": {
+			"report": {
+				"fights": [
+					{"id": 1, "encounterID": 0, "name": "Trash", "keystoneTime": 0, "keystoneLevel": 0},
+					{"id": 26, "encounterID": 12660, "name": "Ara-Kara, City of Echoes", "keystoneTime": 1719094, "keystoneLevel": 18},
+					{"id": 27, "encounterID": 2902, "name": "Ulgrax the Devourer", "keystoneTime": 0, "keystoneLevel": 0}
+				]
+			}
+		}
+	}`)
+
+	fights, err := ParseReportFightsResponse(response)
+	require.NoError(t, err)
+	require.Len(t, fights, 1)
+	assert.Equal(t, 26, fights[0].ID)
+	assert.Equal(t, uint(12660), fights[0].EncounterID)
+	assert.Equal(t, 18, fights[0].KeystoneLevel)
+
+	_, err = ParseReportFightsResponse([]byte(`{"reportData": {"report": null}}`))
+	assert.Error(t, err)
+}
+
+func TestReportTableBatchQuery(t *testing.T) {
+	batch := ReportTableBatchQuery("Xq7RmT2vKc9LpW4n", 4, 12831)
+	assert.Equal(t, "reportData", batch.Root)
+	assert.Contains(t, batch.Selection, `report(code: "Xq7RmT2vKc9LpW4n")`)
+	assert.Contains(t, batch.Selection, "table(fightIDs: [4], encounterID: 12831)")
+
+	// The standalone document uses the same selection with variables
+	assert.Contains(t, GetReportTableQuery, reportTableSelection("$code", "$fightID", "$encounterID"))
+}
@@ -0,0 +1,145 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	AbilityUsageRepository handles database operations for the ability usages and their statistics.
+
+	Methods:
+	- GetReportsNeedingAbilityUsage: Retrieves the reports whose events have not been fetched yet.
+	- StoreReportAbilityUsages: Replaces the ability usages of a report and marks its events as fetched.
+	- GetAbilityUsagesForEncounterSince: Retrieves the ability usages of a dungeon for the reports stored since a date.
+	- DeleteAbilityUsageStatistics: Deletes ability usage statistics for a dungeon.
+	- StoreManyAbilityUsageStatistics: Persists multiple ability usage statistics to the database.
+*/
+
+// AbilityUsageRepository handles database operations for the ability usages and their statistics.
+type AbilityUsageRepository struct {
+	db *gorm.DB
+}
+
+// NewAbilityUsageRepository creates a new instance of AbilityUsageRepository.
+func NewAbilityUsageRepository(db *gorm.DB) *AbilityUsageRepository {
+	return &AbilityUsageRepository{
+		db: db,
+	}
+}
+
+// GetReportsNeedingAbilityUsage retrieves the Mythic+ reports stored since a date whose events have not been fetched yet
+// Only the columns needed to fetch the events and attribute them to the players are loaded.
+func (r *AbilityUsageRepository) GetReportsNeedingAbilityUsage(ctx context.Context, since time.Time, limit int) ([]*warcraftlogsBuilds.Report, error) {
+	var reports []*warcraftlogsBuilds.Report
+
+	err := r.db.WithContext(ctx).
+		Select("code, fight_id, encounter_id, total_time, keystonelevel, composition, created_at").
+		Where("ability_usage_fetched_at IS NULL").
+		Where("created_at >= ?", since).
+		Where("keystonelevel > 0 AND composition IS NOT NULL").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports needing ability usage: %w", err)
+	}
+
+	return reports, nil
+}
+
+// StoreReportAbilityUsages replaces the ability usages of a report and marks its events as fetched
+// Reports without any usage are marked too, so their events are not fetched again.
+func (r *AbilityUsageRepository) StoreReportAbilityUsages(ctx context.Context, code string, fightID int, usages []*warcraftlogsBuilds.ReportAbilityUsage) error {
+	const batchSize = 100
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("report_code = ? AND fight_id = ?", code, fightID).
+			Delete(&warcraftlogsBuilds.ReportAbilityUsage{}).Error; err != nil {
+			return fmt.Errorf("failed to delete ability usages of report %s-#%d: %w", code, fightID, err)
+		}
+
+		if len(usages) > 0 {
+			if err := tx.CreateInBatches(usages, batchSize).Error; err != nil {
+				return fmt.Errorf("failed to store ability usages of report %s-#%d: %w", code, fightID, err)
+			}
+		}
+
+		if err := tx.Model(&warcraftlogsBuilds.Report{}).
+			Where("code = ? AND fight_id = ?", code, fightID).
+			Update("ability_usage_fetched_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to mark ability usage of report %s-#%d as fetched: %w", code, fightID, err)
+		}
+
+		return nil
+	})
+}
+
+// GetAbilityUsagesForEncounterSince retrieves the ability usages of a dungeon for the reports stored since a date
+func (r *AbilityUsageRepository) GetAbilityUsagesForEncounterSince(ctx context.Context, encounterID uint, since time.Time) ([]*warcraftlogsBuilds.ReportAbilityUsage, error) {
+	var usages []*warcraftlogsBuilds.ReportAbilityUsage
+
+	err := r.db.WithContext(ctx).
+		Table("report_ability_usages u").
+		Select("u.*").
+		Joins("JOIN warcraft_logs_reports r ON r.code = u.report_code AND r.fight_id = u.fight_id AND r.deleted_at IS NULL").
+		Where("u.encounter_id = ? AND u.deleted_at IS NULL", encounterID).
+		Where("r.created_at >= ?", since).
+		Order("u.report_code ASC, u.fight_id ASC, u.actor_id ASC").
+		Scan(&usages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ability usages for encounter %d: %w", encounterID, err)
+	}
+
+	return usages, nil
+}
+
+// DeleteAbilityUsageStatistics removes ability usage statistics for a dungeon.
+// Statistics are fully recomputed on each run so a hard delete is used.
+func (r *AbilityUsageRepository) DeleteAbilityUsageStatistics(ctx context.Context, encounterID uint) error {
+	query := r.db.WithContext(ctx).Unscoped()
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	} else {
+		query = query.Where("1 = 1")
+	}
+
+	result := query.Delete(&warcraftlogsBuilds.AbilityUsageStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete ability usage statistics for encounter %d: %w", encounterID, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing ability usage statistics (encounterID: %d)", result.RowsAffected, encounterID)
+	return nil
+}
+
+// StoreManyAbilityUsageStatistics persists multiple ability usage statistics to the database.
+func (r *AbilityUsageRepository) StoreManyAbilityUsageStatistics(ctx context.Context, stats []*warcraftlogsBuilds.AbilityUsageStatistic) error {
+	if len(stats) == 0 {
+		log.Printf("[DEBUG] No ability usage statistics to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(stats, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store ability usage statistics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d ability usage statistics", len(stats))
+	return nil
+}
@@ -0,0 +1,116 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+	BuildValidationRepository handles database operations for the builds rejected by the validation stage.
+
+	Methods:
+	- StoreQuarantinedBuilds: Stores the rejected builds and removes their previously stored version.
+	- ReleaseQuarantinedBuilds: Removes the quarantined version of builds that passed the validation.
+*/
+
+// BuildValidationRepository handles database operations for the quarantined player builds.
+type BuildValidationRepository struct {
+	db *gorm.DB
+}
+
+// NewBuildValidationRepository creates a new instance of BuildValidationRepository.
+func NewBuildValidationRepository(db *gorm.DB) *BuildValidationRepository {
+	return &BuildValidationRepository{
+		db: db,
+	}
+}
+
+// StoreQuarantinedBuilds stores the rejected builds, a build extracted again updates its quarantined version.
+// A build stored before its validation is hard deleted from player_builds, so it no longer counts in the statistics.
+func (r *BuildValidationRepository) StoreQuarantinedBuilds(ctx context.Context, builds []*warcraftlogsBuilds.QuarantinedPlayerBuild) error {
+	if len(builds) == 0 {
+		log.Printf("[DEBUG] No quarantined builds to store")
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, build := range builds {
+			now := time.Now()
+			build.CreatedAt = now
+			build.UpdatedAt = now
+
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "report_code"},
+					{Name: "fight_id"},
+					{Name: "actor_id"},
+				},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"player_name",
+					"class",
+					"spec",
+					"encounter_id",
+					"keystone_level",
+					"patch",
+					"item_level",
+					"talent_import",
+					"talent_tree",
+					"gear",
+					"stats",
+					"reasons",
+					"batch_id",
+					"updated_at",
+					"deleted_at",
+				}),
+			}).Create(build).Error; err != nil {
+				return fmt.Errorf("failed to store quarantined build for player %s in report %s: %w",
+					build.PlayerName, build.ReportCode, err)
+			}
+
+			if err := tx.Unscoped().
+				Where("report_code = ? AND fight_id = ? AND actor_id = ?", build.ReportCode, build.FightID, build.ActorID).
+				Delete(&warcraftlogsBuilds.PlayerBuild{}).Error; err != nil {
+				return fmt.Errorf("failed to delete player build of quarantined build %s-#%d-%d: %w",
+					build.ReportCode, build.FightID, build.ActorID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Stored %d quarantined player builds", len(builds))
+	return nil
+}
+
+// ReleaseQuarantinedBuilds hard deletes the quarantined version of the builds that passed the validation.
+func (r *BuildValidationRepository) ReleaseQuarantinedBuilds(ctx context.Context, builds []*warcraftlogsBuilds.PlayerBuild) error {
+	if len(builds) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, build := range builds {
+			if err := tx.Unscoped().
+				Where("report_code = ? AND fight_id = ? AND actor_id = ?", build.ReportCode, build.FightID, build.ActorID).
+				Delete(&warcraftlogsBuilds.QuarantinedPlayerBuild{}).Error; err != nil {
+				return fmt.Errorf("failed to release quarantined build %s-#%d-%d: %w",
+					build.ReportCode, build.FightID, build.ActorID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
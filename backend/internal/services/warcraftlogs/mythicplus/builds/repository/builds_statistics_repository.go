@@ -20,6 +20,8 @@ import (
 	- GetBuildStatistics: Retrieves build statistics from the database based on filter criteria.
 	- StoreManyBuildStatistics: Persists multiple build statistics to the database.
 	- CountBuildStatistics: Returns the total count of build statistics in the database.
+	- SnapshotBuildStatistics: Copies the build statistics of a class, spec and encounter to the weekly snapshots.
+	- ReplaceBuildStatisticSegments: Replaces the build statistics of a class, spec and encounter segmented by keystone level and affix set.
 
 */
 
@@ -36,14 +38,15 @@ func NewBuildsStatisticsRepository(db *gorm.DB) *BuildsStatisticsRepository {
 }
 
 // DeleteBuildStatistics removes build statistics for a class and spec.
-func (r *BuildsStatisticsRepository) DeleteBuildStatistics(ctx context.Context, class, spec string, encounterID uint) error {
+// The raid bosses are restricted to a difficulty, 0 for the Mythic+ dungeons.
+func (r *BuildsStatisticsRepository) DeleteBuildStatistics(ctx context.Context, class, spec string, encounterID, difficulty uint) error {
 	// Unscoped() for the hard delete
 	query := r.db.WithContext(ctx).
 		Unscoped().
 		Where("class = ? AND spec = ?", class, spec)
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	result := query.Delete(&warcraftlogsBuilds.BuildStatistic{})
@@ -51,8 +54,8 @@ func (r *BuildsStatisticsRepository) DeleteBuildStatistics(ctx context.Context,
 		return fmt.Errorf("failed to delete build statistics for %s %s: %w", class, spec, result.Error)
 	}
 
-	log.Printf("[INFO] Deleted %d existing build statistics for %s-%s (encounterID: %d)",
-		result.RowsAffected, class, spec, encounterID)
+	log.Printf("[INFO] Deleted %d existing build statistics for %s-%s (encounterID: %d, difficulty: %d)",
+		result.RowsAffected, class, spec, encounterID, difficulty)
 
 	// reset of the status in the player_builds table
 	resetQuery := r.db.WithContext(ctx).
@@ -60,7 +63,7 @@ func (r *BuildsStatisticsRepository) DeleteBuildStatistics(ctx context.Context,
 		Where("class = ? AND spec = ?", class, spec)
 
 	if encounterID > 0 {
-		resetQuery = resetQuery.Where("encounter_id = ?", encounterID)
+		resetQuery = resetQuery.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	resetResult := resetQuery.
@@ -75,8 +78,8 @@ func (r *BuildsStatisticsRepository) DeleteBuildStatistics(ctx context.Context,
 		return fmt.Errorf("failed to reset equipment status for builds %s-%s: %w", class, spec, resetResult.Error)
 	}
 
-	log.Printf("[INFO] Reset equipment status to 'pending' for %d builds of %s-%s (encounterID: %d)",
-		resetResult.RowsAffected, class, spec, encounterID)
+	log.Printf("[INFO] Reset equipment status to 'pending' for %d builds of %s-%s (encounterID: %d, difficulty: %d)",
+		resetResult.RowsAffected, class, spec, encounterID, difficulty)
 
 	return nil
 }
@@ -131,6 +134,7 @@ func (r *BuildsStatisticsRepository) StoreManyBuildStatistics(ctx context.Contex
 						{Name: "class"},
 						{Name: "spec"},
 						{Name: "encounter_id"},
+						{Name: "difficulty"},
 						{Name: "item_slot"},
 						{Name: "item_id"},
 					},
@@ -212,3 +216,71 @@ func (r *BuildsStatisticsRepository) CountBuildStatistics(ctx context.Context, c
 
 	return count, nil
 }
+
+// SnapshotBuildStatistics copies the build statistics of a class, spec and encounter to the snapshot of a weekly period.
+// The snapshot of the period is replaced, so only the last run of the week is kept.
+func (r *BuildsStatisticsRepository) SnapshotBuildStatistics(ctx context.Context, class, spec string, encounterID uint, periodStart time.Time) error {
+	var copied int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("period_start = ? AND class = ? AND spec = ? AND encounter_id = ?", periodStart, class, spec, encounterID).
+			Delete(&warcraftlogsBuilds.BuildStatisticSnapshot{}).Error; err != nil {
+			return fmt.Errorf("failed to delete build statistic snapshots: %w", err)
+		}
+
+		result := tx.Exec(`
+			INSERT INTO build_statistic_snapshots (
+				created_at, updated_at, period_start, class, spec, encounter_id,
+				item_slot, item_id, item_name, item_icon,
+				usage_count, usage_percentage, avg_item_level, avg_keystone_level
+			)
+			SELECT NOW(), NOW(), ?, class, spec, encounter_id,
+				item_slot, item_id, item_name, item_icon,
+				usage_count, usage_percentage, avg_item_level, avg_keystone_level
+			FROM build_statistics
+			WHERE class = ? AND spec = ? AND encounter_id = ? AND deleted_at IS NULL`,
+			periodStart, class, spec, encounterID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to copy build statistics: %w", result.Error)
+		}
+		copied = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Snapshot %d build statistics for %s-%s (encounterID: %d, period: %s)",
+		copied, class, spec, encounterID, periodStart.Format("2006-01-02"))
+	return nil
+}
+
+// ReplaceBuildStatisticSegments replaces the segmented build statistics of a class, spec and encounter.
+// Segments are fully recomputed on each run so the previous ones are hard deleted in the same transaction.
+func (r *BuildsStatisticsRepository) ReplaceBuildStatisticSegments(ctx context.Context, class, spec string, encounterID uint, segments []*warcraftlogsBuilds.BuildStatisticSegment) error {
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("class = ? AND spec = ? AND encounter_id = ?", class, spec, encounterID).
+			Delete(&warcraftlogsBuilds.BuildStatisticSegment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete build statistic segments: %w", err)
+		}
+
+		if len(segments) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(segments, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store build statistic segments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Stored %d build statistic segments for %s-%s (encounterID: %d)",
+		len(segments), class, spec, encounterID)
+	return nil
+}
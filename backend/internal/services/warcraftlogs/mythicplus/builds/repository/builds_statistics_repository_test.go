@@ -42,7 +42,7 @@ func createTestBuildStatistics() *warcraftlogsBuilds.BuildStatistic {
 // clearTestData removes all test data from the database
 func clearTestData(t *testing.T, repo *BuildsStatisticsRepository, class, spec string, encounterID uint) {
 	ctx := context.Background()
-	err := repo.DeleteBuildStatistics(ctx, class, spec, encounterID)
+	err := repo.DeleteBuildStatistics(ctx, class, spec, encounterID, 0)
 	require.NoError(t, err, "Failed to clear test data")
 }
 
@@ -65,7 +65,7 @@ func TestDeleteBuildStatistics(t *testing.T) {
 	require.NotEmpty(t, stats, "Test data should be stored")
 
 	// Test: Delete the data
-	err = repo.DeleteBuildStatistics(ctx, buildStat.Class, buildStat.Spec, buildStat.EncounterID)
+	err = repo.DeleteBuildStatistics(ctx, buildStat.Class, buildStat.Spec, buildStat.EncounterID, buildStat.Difficulty)
 	assert.NoError(t, err)
 
 	// Verify data was deleted
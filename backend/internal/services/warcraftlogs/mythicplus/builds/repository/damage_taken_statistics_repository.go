@@ -0,0 +1,116 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	DamageTakenStatisticsRepository handles database operations for damage taken statistics.
+
+	Methods:
+	- DeleteDamageTakenStatistics: Deletes damage taken statistics for a dungeon.
+	- StoreManyDamageTakenStatistics: Persists multiple damage taken statistics to the database.
+	- GetDamageTakenStatistics: Retrieves damage taken statistics from the database based on filter criteria.
+	- CountDamageTakenStatistics: Returns the total count of damage taken statistics for a dungeon.
+*/
+
+// DamageTakenStatisticsRepository handles database operations for damage taken statistics.
+type DamageTakenStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewDamageTakenStatisticsRepository creates a new instance of DamageTakenStatisticsRepository.
+func NewDamageTakenStatisticsRepository(db *gorm.DB) *DamageTakenStatisticsRepository {
+	return &DamageTakenStatisticsRepository{
+		db: db,
+	}
+}
+
+// DeleteDamageTakenStatistics removes damage taken statistics for a dungeon.
+// Statistics are fully recomputed on each run so a hard delete is used.
+func (r *DamageTakenStatisticsRepository) DeleteDamageTakenStatistics(ctx context.Context, encounterID uint) error {
+	query := r.db.WithContext(ctx).Unscoped()
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	} else {
+		query = query.Where("1 = 1")
+	}
+
+	result := query.Delete(&warcraftlogsBuilds.DamageTakenStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete damage taken statistics for encounter %d: %w", encounterID, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing damage taken statistics (encounterID: %d)", result.RowsAffected, encounterID)
+	return nil
+}
+
+// StoreManyDamageTakenStatistics persists multiple damage taken statistics to the database.
+func (r *DamageTakenStatisticsRepository) StoreManyDamageTakenStatistics(ctx context.Context, damageTakenStats []*warcraftlogsBuilds.DamageTakenStatistic) error {
+	if len(damageTakenStats) == 0 {
+		log.Printf("[DEBUG] No damage taken statistics to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(damageTakenStats, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store damage taken statistics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d damage taken statistics", len(damageTakenStats))
+	return nil
+}
+
+// GetDamageTakenStatistics retrieves damage taken statistics based on filter criteria.
+// An empty bracket or statistic type returns every value for that field.
+func (r *DamageTakenStatisticsRepository) GetDamageTakenStatistics(ctx context.Context, encounterID uint, bracket, statisticType string) ([]*warcraftlogsBuilds.DamageTakenStatistic, error) {
+	var stats []*warcraftlogsBuilds.DamageTakenStatistic
+
+	query := r.db.WithContext(ctx).Model(&warcraftlogsBuilds.DamageTakenStatistic{})
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+	if bracket != "" {
+		query = query.Where("key_level_bracket = ?", bracket)
+	}
+	if statisticType != "" {
+		query = query.Where("statistic_type = ?", statisticType)
+	}
+
+	if err := query.Order("avg_normalized_dps DESC").Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch damage taken statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CountDamageTakenStatistics returns the total count of damage taken statistics for a dungeon.
+func (r *DamageTakenStatisticsRepository) CountDamageTakenStatistics(ctx context.Context, encounterID uint) (int64, error) {
+	var count int64
+
+	query := r.db.WithContext(ctx).Model(&warcraftlogsBuilds.DamageTakenStatistic{})
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count damage taken statistics: %w", err)
+	}
+
+	return count, nil
+}
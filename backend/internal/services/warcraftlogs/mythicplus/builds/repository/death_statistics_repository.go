@@ -0,0 +1,116 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	DeathStatisticsRepository handles database operations for death statistics.
+
+	Methods:
+	- DeleteDeathStatistics: Deletes death statistics for a dungeon.
+	- StoreManyDeathStatistics: Persists multiple death statistics to the database.
+	- GetDeathStatistics: Retrieves death statistics from the database based on filter criteria.
+	- CountDeathStatistics: Returns the total count of death statistics for a dungeon.
+*/
+
+// DeathStatisticsRepository handles database operations for death statistics.
+type DeathStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewDeathStatisticsRepository creates a new instance of DeathStatisticsRepository.
+func NewDeathStatisticsRepository(db *gorm.DB) *DeathStatisticsRepository {
+	return &DeathStatisticsRepository{
+		db: db,
+	}
+}
+
+// DeleteDeathStatistics removes death statistics for a dungeon.
+// Statistics are fully recomputed on each run so a hard delete is used.
+func (r *DeathStatisticsRepository) DeleteDeathStatistics(ctx context.Context, encounterID uint) error {
+	query := r.db.WithContext(ctx).Unscoped()
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	} else {
+		query = query.Where("1 = 1")
+	}
+
+	result := query.Delete(&warcraftlogsBuilds.DeathStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete death statistics for encounter %d: %w", encounterID, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing death statistics (encounterID: %d)", result.RowsAffected, encounterID)
+	return nil
+}
+
+// StoreManyDeathStatistics persists multiple death statistics to the database.
+func (r *DeathStatisticsRepository) StoreManyDeathStatistics(ctx context.Context, deathStats []*warcraftlogsBuilds.DeathStatistic) error {
+	if len(deathStats) == 0 {
+		log.Printf("[DEBUG] No death statistics to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(deathStats, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store death statistics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d death statistics", len(deathStats))
+	return nil
+}
+
+// GetDeathStatistics retrieves death statistics based on filter criteria.
+// An empty bracket or statistic type returns every value for that field.
+func (r *DeathStatisticsRepository) GetDeathStatistics(ctx context.Context, encounterID uint, bracket, statisticType string) ([]*warcraftlogsBuilds.DeathStatistic, error) {
+	var stats []*warcraftlogsBuilds.DeathStatistic
+
+	query := r.db.WithContext(ctx).Model(&warcraftlogsBuilds.DeathStatistic{})
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+	if bracket != "" {
+		query = query.Where("key_level_bracket = ?", bracket)
+	}
+	if statisticType != "" {
+		query = query.Where("statistic_type = ?", statisticType)
+	}
+
+	if err := query.Order("death_count DESC").Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch death statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CountDeathStatistics returns the total count of death statistics for a dungeon.
+func (r *DeathStatisticsRepository) CountDeathStatistics(ctx context.Context, encounterID uint) (int64, error) {
+	var count int64
+
+	query := r.db.WithContext(ctx).Model(&warcraftlogsBuilds.DeathStatistic{})
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count death statistics: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,118 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+	GroupCompositionRepository handles database operations for group compositions.
+
+	Methods:
+	- GetDungeonTimers: Retrieves the dungeon ID and timer of each dungeon by encounter ID.
+	- StoreGroupCompositions: Persists the compositions of the processed reports, one per fight.
+*/
+
+// GroupCompositionRepository handles database operations for group compositions.
+type GroupCompositionRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupCompositionRepository creates a new instance of GroupCompositionRepository.
+func NewGroupCompositionRepository(db *gorm.DB) *GroupCompositionRepository {
+	return &GroupCompositionRepository{
+		db: db,
+	}
+}
+
+// DungeonTimer holds the data needed to link a composition to its dungeon
+type DungeonTimer struct {
+	DungeonID uint
+	TimerMS   int64 // Qualifying duration of the +1 upgrade, in milliseconds
+}
+
+// GetDungeonTimers retrieves the dungeon ID and the timer of every dungeon with an encounter ID.
+// The timer is the qualifying duration of the first keystone upgrade.
+func (r *GroupCompositionRepository) GetDungeonTimers(ctx context.Context) (map[uint]DungeonTimer, error) {
+	var rows []struct {
+		DungeonID   uint
+		EncounterID uint
+		TimerMS     int64
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("dungeons d").
+		Select("d.id AS dungeon_id, d.encounter_id, COALESCE(MAX(k.qualifying_duration), 0) AS timer_ms").
+		Joins("LEFT JOIN key_stone_upgrades k ON k.challenge_mode_id = d.challenge_mode_id AND k.upgrade_level = 1 AND k.deleted_at IS NULL").
+		Where("d.encounter_id IS NOT NULL AND d.deleted_at IS NULL").
+		Group("d.id, d.encounter_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dungeon timers: %w", err)
+	}
+
+	timers := make(map[uint]DungeonTimer, len(rows))
+	for _, row := range rows {
+		timers[row.EncounterID] = DungeonTimer{
+			DungeonID: row.DungeonID,
+			TimerMS:   row.TimerMS,
+		}
+	}
+
+	return timers, nil
+}
+
+// StoreGroupCompositions persists the group compositions, updating the existing composition of a fight.
+func (r *GroupCompositionRepository) StoreGroupCompositions(ctx context.Context, compositions []*warcraftlogsBuilds.GroupComposition) error {
+	if len(compositions) == 0 {
+		log.Printf("[DEBUG] No group compositions to store")
+		return nil
+	}
+
+	// Deduplicate compositions, the upsert cannot touch the same row twice
+	unique := make(map[string]*warcraftlogsBuilds.GroupComposition, len(compositions))
+	for _, composition := range compositions {
+		unique[fmt.Sprintf("%s-%d", composition.ReportCode, composition.FightID)] = composition
+	}
+
+	deduplicated := make([]*warcraftlogsBuilds.GroupComposition, 0, len(unique))
+	now := time.Now()
+	for _, composition := range unique {
+		composition.CreatedAt = now
+		composition.UpdatedAt = now
+		deduplicated = append(deduplicated, composition)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "report_code"},
+				{Name: "fight_id"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"tank_specs",
+				"healer_specs",
+				"dps_specs",
+				"success",
+				"dungeon_id",
+				"encounter_id",
+				"keystone_level",
+				"updated_at",
+			}),
+		}).CreateInBatches(deduplicated, 100)
+
+		if result.Error != nil {
+			return fmt.Errorf("failed to store group compositions: %w", result.Error)
+		}
+
+		log.Printf("[INFO] Stored %d group compositions", len(deduplicated))
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	ItemLiftStatisticsRepository handles database operations for item lift statistics.
+
+	Methods:
+	- DeleteItemLiftStatistics: Deletes the item lift statistics of a class, spec and encounter.
+	- StoreManyItemLiftStatistics: Persists multiple item lift statistics to the database.
+	- GetItemLiftStatistics: Retrieves item lift statistics from the database based on filter criteria.
+*/
+
+// ItemLiftStatisticsRepository handles database operations for item lift statistics.
+type ItemLiftStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewItemLiftStatisticsRepository creates a new instance of ItemLiftStatisticsRepository.
+func NewItemLiftStatisticsRepository(db *gorm.DB) *ItemLiftStatisticsRepository {
+	return &ItemLiftStatisticsRepository{
+		db: db,
+	}
+}
+
+// DeleteItemLiftStatistics removes the item lift statistics of a class, spec and encounter.
+// Lift statistics are fully recomputed on each run so a hard delete is used.
+func (r *ItemLiftStatisticsRepository) DeleteItemLiftStatistics(ctx context.Context, class, spec string, encounterID uint) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("class = ? AND spec = ? AND encounter_id = ?", class, spec, encounterID).
+		Delete(&warcraftlogsBuilds.ItemLiftStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete item lift statistics for %s %s (encounter %d): %w", class, spec, encounterID, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing item lift statistics (class: %s, spec: %s, encounterID: %d)",
+		result.RowsAffected, class, spec, encounterID)
+	return nil
+}
+
+// StoreManyItemLiftStatistics persists multiple item lift statistics to the database.
+func (r *ItemLiftStatisticsRepository) StoreManyItemLiftStatistics(ctx context.Context, liftStats []*warcraftlogsBuilds.ItemLiftStatistic) error {
+	if len(liftStats) == 0 {
+		log.Printf("[DEBUG] No item lift statistics to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(liftStats, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store item lift statistics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d item lift statistics", len(liftStats))
+	return nil
+}
+
+// GetItemLiftStatistics retrieves item lift statistics based on filter criteria.
+// An encounter ID of 0 or an empty choice type returns every value for that field.
+func (r *ItemLiftStatisticsRepository) GetItemLiftStatistics(ctx context.Context, class, spec string, encounterID uint, choiceType string) ([]*warcraftlogsBuilds.ItemLiftStatistic, error) {
+	var stats []*warcraftlogsBuilds.ItemLiftStatistic
+
+	query := r.db.WithContext(ctx).
+		Model(&warcraftlogsBuilds.ItemLiftStatistic{}).
+		Where("class = ? AND spec = ?", class, spec)
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+	if choiceType != "" {
+		query = query.Where("choice_type = ?", choiceType)
+	}
+
+	if err := query.Order("choice_type ASC, item_slot ASC, weighted_lift DESC").Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch item lift statistics: %w", err)
+	}
+
+	return stats, nil
+}
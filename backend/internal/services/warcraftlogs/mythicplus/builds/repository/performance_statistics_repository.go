@@ -0,0 +1,116 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	PerformanceStatisticsRepository handles database operations for performance statistics.
+
+	Methods:
+	- DeletePerformanceStatistics: Deletes performance statistics for a dungeon.
+	- StoreManyPerformanceStatistics: Persists multiple performance statistics to the database.
+	- GetPerformanceStatistics: Retrieves performance statistics from the database based on filter criteria.
+	- CountPerformanceStatistics: Returns the total count of performance statistics for a dungeon.
+*/
+
+// PerformanceStatisticsRepository handles database operations for performance statistics.
+type PerformanceStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewPerformanceStatisticsRepository creates a new instance of PerformanceStatisticsRepository.
+func NewPerformanceStatisticsRepository(db *gorm.DB) *PerformanceStatisticsRepository {
+	return &PerformanceStatisticsRepository{
+		db: db,
+	}
+}
+
+// DeletePerformanceStatistics removes performance statistics for a dungeon.
+// Statistics are fully recomputed on each run so a hard delete is used.
+func (r *PerformanceStatisticsRepository) DeletePerformanceStatistics(ctx context.Context, encounterID uint) error {
+	query := r.db.WithContext(ctx).Unscoped()
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	} else {
+		query = query.Where("1 = 1")
+	}
+
+	result := query.Delete(&warcraftlogsBuilds.PerformanceStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete performance statistics for encounter %d: %w", encounterID, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing performance statistics (encounterID: %d)", result.RowsAffected, encounterID)
+	return nil
+}
+
+// StoreManyPerformanceStatistics persists multiple performance statistics to the database.
+func (r *PerformanceStatisticsRepository) StoreManyPerformanceStatistics(ctx context.Context, performanceStats []*warcraftlogsBuilds.PerformanceStatistic) error {
+	if len(performanceStats) == 0 {
+		log.Printf("[DEBUG] No performance statistics to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(performanceStats, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store performance statistics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d performance statistics", len(performanceStats))
+	return nil
+}
+
+// GetPerformanceStatistics retrieves performance statistics based on filter criteria.
+// An empty bracket or metric returns every value for that field.
+func (r *PerformanceStatisticsRepository) GetPerformanceStatistics(ctx context.Context, encounterID uint, bracket, metric string) ([]*warcraftlogsBuilds.PerformanceStatistic, error) {
+	var stats []*warcraftlogsBuilds.PerformanceStatistic
+
+	query := r.db.WithContext(ctx).Model(&warcraftlogsBuilds.PerformanceStatistic{})
+
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+	if bracket != "" {
+		query = query.Where("key_level_bracket = ?", bracket)
+	}
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	if err := query.Order("key_level_bracket ASC, metric ASC, p50 DESC").Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch performance statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CountPerformanceStatistics returns the total count of performance statistics for a dungeon.
+func (r *PerformanceStatisticsRepository) CountPerformanceStatistics(ctx context.Context, encounterID uint) (int64, error) {
+	var count int64
+
+	query := r.db.WithContext(ctx).Model(&warcraftlogsBuilds.PerformanceStatistic{})
+	if encounterID > 0 {
+		query = query.Where("encounter_id = ?", encounterID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count performance statistics: %w", err)
+	}
+
+	return count, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -72,9 +73,15 @@ func (r *PlayerBuildsRepository) StoreManyPlayerBuilds(ctx context.Context, buil
 						"item_level",
 						"gear",
 						"stats",
+						"potion_use",
+						"healthstone_use",
 						"encounter_id",
+						"difficulty",
 						"keystone_level",
 						"affixes",
+						"server_name",
+						"character_guid",
+						"player_identity_id",
 						"updated_at",
 					}),
 				}).Create(build)
@@ -199,13 +206,13 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsByFilter(
 func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingEquipmentAnalysis(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 	limit, offset int,
 ) ([]*warcraftlogsBuilds.PlayerBuild, error) {
 	var builds []*warcraftlogsBuilds.PlayerBuild
 	query := r.db.WithContext(ctx)
 
-	// Filter by class, spec and encounter_id if provided
+	// Filter by class, spec, encounter_id and difficulty if provided
 	if class != "" {
 		query = query.Where("class = ?", class)
 	}
@@ -215,7 +222,7 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingEquipmentAnalysis(
 	}
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	// Condition on equipment_status
@@ -235,12 +242,12 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingEquipmentAnalysis(
 func (r *PlayerBuildsRepository) CountPlayerBuildsNeedingEquipmentAnalysis(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 ) (int64, error) {
 	var count int64
 	query := r.db.Model(&warcraftlogsBuilds.PlayerBuild{})
 
-	// Filter by class, spec and encounter_id if provided
+	// Filter by class, spec, encounter_id and difficulty if provided
 	if class != "" {
 		query = query.Where("class = ?", class)
 	}
@@ -250,7 +257,7 @@ func (r *PlayerBuildsRepository) CountPlayerBuildsNeedingEquipmentAnalysis(
 	}
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	query = query.Where("equipment_status IS NULL OR equipment_status = 'pending' OR equipment_status = 'failed'")
@@ -296,7 +303,7 @@ func (r *PlayerBuildsRepository) MarkPlayerBuildsAsProcessedForEquipment(
 func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingTalentAnalysis(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 	limit, offset int,
 ) ([]*warcraftlogsBuilds.PlayerBuild, error) {
 	var builds []*warcraftlogsBuilds.PlayerBuild
@@ -311,7 +318,7 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingTalentAnalysis(
 	}
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	query = query.Where("talent_status IS NULL OR talent_status = 'pending' OR talent_status = 'failed'")
@@ -329,7 +336,7 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingTalentAnalysis(
 func (r *PlayerBuildsRepository) CountPlayerBuildsNeedingTalentAnalysis(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 ) (int64, error) {
 	var count int64
 	query := r.db.Model(&warcraftlogsBuilds.PlayerBuild{})
@@ -343,7 +350,7 @@ func (r *PlayerBuildsRepository) CountPlayerBuildsNeedingTalentAnalysis(
 	}
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	query = query.Where("talent_status IS NULL OR talent_status = 'pending' OR talent_status = 'failed'")
@@ -389,7 +396,7 @@ func (r *PlayerBuildsRepository) MarkPlayerBuildsAsProcessedForTalent(
 func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingStatAnalysis(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 	limit, offset int,
 ) ([]*warcraftlogsBuilds.PlayerBuild, error) {
 	var builds []*warcraftlogsBuilds.PlayerBuild
@@ -404,7 +411,7 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingStatAnalysis(
 	}
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	query = query.Where("stat_status IS NULL OR stat_status = 'pending' OR stat_status = 'failed'")
@@ -422,7 +429,7 @@ func (r *PlayerBuildsRepository) GetPlayerBuildsNeedingStatAnalysis(
 func (r *PlayerBuildsRepository) CountPlayerBuildsNeedingStatAnalysis(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 ) (int64, error) {
 	var count int64
 	query := r.db.Model(&warcraftlogsBuilds.PlayerBuild{})
@@ -436,7 +443,7 @@ func (r *PlayerBuildsRepository) CountPlayerBuildsNeedingStatAnalysis(
 	}
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	query = query.Where("stat_status IS NULL OR stat_status = 'pending' OR stat_status = 'failed'")
@@ -474,3 +481,81 @@ func (r *PlayerBuildsRepository) MarkPlayerBuildsAsProcessedForStat(
 	log.Printf("[INFO] Marked %d builds as '%s' for stat analysis", result.RowsAffected, status)
 	return nil
 }
+
+// == Stat weights methods ==
+
+// StatWeightSample is a player build with the performance value its stats are regressed against
+type StatWeightSample struct {
+	BuildID     uint
+	Stats       datatypes.JSON
+	ItemLevel   float64
+	Performance float64
+}
+
+// GetStatWeightSamples returns the Mythic+ builds of a spec with their value for a performance metric
+// Builds without a value for the metric, like builds without a matching ranking for the score, are skipped.
+func (r *PlayerBuildsRepository) GetStatWeightSamples(
+	ctx context.Context,
+	class, spec, metric string,
+	limit, offset int,
+) ([]StatWeightSample, error) {
+	query := r.db.WithContext(ctx).
+		Table("player_builds pb").
+		Where("pb.class = ? AND pb.spec = ?", class, spec).
+		Where("pb.deleted_at IS NULL AND pb.stats IS NOT NULL").
+		Where("pb.encounter_id NOT IN (SELECT encounter_id FROM raid_encounters WHERE deleted_at IS NULL)")
+
+	switch metric {
+	case warcraftlogsBuilds.StatWeightMetricKeystoneLevel:
+		query = query.Select("pb.id AS build_id, pb.stats, pb.item_level, pb.keystone_level AS performance")
+	case warcraftlogsBuilds.StatWeightMetricScore:
+		query = query.
+			Select("pb.id AS build_id, pb.stats, pb.item_level, cr.score AS performance").
+			Joins(`JOIN class_rankings cr ON cr.report_code = pb.report_code
+				AND cr.report_fight_id = pb.fight_id
+				AND cr.player_name = pb.player_name
+				AND cr.class = pb.class
+				AND cr.spec = pb.spec
+				AND cr.deleted_at IS NULL`).
+			Where("cr.score > 0")
+	case warcraftlogsBuilds.StatWeightMetricDPS, warcraftlogsBuilds.StatWeightMetricHPS:
+		column := "damage_done"
+		if metric == warcraftlogsBuilds.StatWeightMetricHPS {
+			column = "healing_done"
+		}
+		query = query.
+			Select("pb.id AS build_id, pb.stats, pb.item_level, (entry->>'total')::numeric / (r.total_time / 1000.0) AS performance").
+			Joins("JOIN warcraft_logs_reports r ON r.code = pb.report_code AND r.fight_id = pb.fight_id AND r.deleted_at IS NULL").
+			Joins(fmt.Sprintf("CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(r.%[1]s) = 'array' THEN r.%[1]s ELSE '[]'::jsonb END) AS entry", column)).
+			Where("r.total_time > 0 AND (entry->>'id')::int = pb.actor_id")
+	default:
+		return nil, fmt.Errorf("unknown stat weight metric: %s", metric)
+	}
+
+	var samples []StatWeightSample
+	if err := query.Order("pb.id").Limit(limit).Offset(offset).Scan(&samples).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch stat weight samples for %s %s (%s): %w", class, spec, metric, err)
+	}
+
+	return samples, nil
+}
+
+// TagPlayerBuildsWithPatch tags the untagged player builds with the patch of their report
+func (r *PlayerBuildsRepository) TagPlayerBuildsWithPatch(ctx context.Context) error {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE player_builds pb
+		SET patch = r.patch
+		FROM warcraft_logs_reports r
+		WHERE pb.report_code = r.code
+		  AND pb.fight_id = r.fight_id
+		  AND COALESCE(pb.patch, '') = ''
+		  AND COALESCE(r.patch, '') <> ''
+		  AND pb.deleted_at IS NULL
+	`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to tag player builds with patch: %w", result.Error)
+	}
+
+	log.Printf("[INFO] Tagged %d player builds with their patch", result.RowsAffected)
+	return nil
+}
@@ -0,0 +1,325 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	PlayerIdentityRepository handles database operations for the canonical identities of the players.
+
+	Methods:
+	- ResolvePlayerIdentities: Resolves the observed players to their identity, creating or merging identities.
+	- LinkClassRankings: Links the class rankings to the identities of their players.
+*/
+
+// normalizedServerSQL compares realm names as warcraftlogsBuilds.NormalizeServerName
+const normalizedServerSQL = "LOWER(REGEXP_REPLACE(%s, '[^[:alnum:]]', '', 'g'))"
+
+// PlayerIdentityRepository handles database operations for the player identities and their aliases.
+type PlayerIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewPlayerIdentityRepository creates a new instance of PlayerIdentityRepository.
+func NewPlayerIdentityRepository(db *gorm.DB) *PlayerIdentityRepository {
+	return &PlayerIdentityRepository{
+		db: db,
+	}
+}
+
+// runInfo is the region and the start of a run, read from its ranking
+type runInfo struct {
+	ServerRegion string
+	StartTime    int64
+}
+
+// ResolvePlayerIdentities resolves each observation to a player identity and records its alias
+// An observation is matched by its Warcraft Logs character ID, then by its game GUID, then by its name, realm and class.
+// When Warcraft Logs links two identities (a renamed or transferred character), they are merged.
+// The region and the time of the observations without them are read from the ranking of the run.
+func (r *PlayerIdentityRepository) ResolvePlayerIdentities(ctx context.Context, observations []*warcraftlogsBuilds.PlayerObservation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+
+	runs := make(map[string]runInfo)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, observation := range observations {
+			if observation.Region == "" || observation.SeenAt.IsZero() {
+				key := fmt.Sprintf("%s-%d", observation.ReportCode, observation.FightID)
+				run, ok := runs[key]
+				if !ok {
+					if err := tx.Table("class_rankings").
+						Select("server_region, start_time").
+						Where("report_code = ? AND report_fight_id = ? AND deleted_at IS NULL", observation.ReportCode, observation.FightID).
+						Limit(1).
+						Scan(&run).Error; err != nil {
+						return fmt.Errorf("failed to get ranking of report %s-#%d: %w", observation.ReportCode, observation.FightID, err)
+					}
+					runs[key] = run
+				}
+				if observation.Region == "" {
+					observation.Region = strings.ToUpper(run.ServerRegion)
+				}
+				if observation.SeenAt.IsZero() && run.StartTime > 0 {
+					observation.SeenAt = time.UnixMilli(run.StartTime)
+				}
+			}
+			if observation.SeenAt.IsZero() {
+				observation.SeenAt = time.Now()
+			}
+
+			identity, err := r.resolveIdentity(tx, observation)
+			if err != nil {
+				return err
+			}
+			if err := r.recordAlias(tx, identity.ID, observation); err != nil {
+				return err
+			}
+			observation.IdentityID = identity.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Resolved the identity of %d players", len(observations))
+	return nil
+}
+
+// resolveIdentity finds or creates the identity of an observation and updates it with the observation
+func (r *PlayerIdentityRepository) resolveIdentity(tx *gorm.DB, observation *warcraftlogsBuilds.PlayerObservation) (*warcraftlogsBuilds.PlayerIdentity, error) {
+	var byWarcraftLogs, byBlizzard *warcraftlogsBuilds.PlayerIdentity
+	var err error
+
+	if observation.WarcraftLogsCharacterID > 0 {
+		if byWarcraftLogs, err = r.findIdentity(tx, "warcraft_logs_character_id = ?", observation.WarcraftLogsCharacterID); err != nil {
+			return nil, err
+		}
+	}
+	if observation.BlizzardCharacterID > 0 {
+		if byBlizzard, err = r.findIdentity(tx, "blizzard_character_id = ? AND region = ?", observation.BlizzardCharacterID, observation.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	identity := byWarcraftLogs
+	switch {
+	case byWarcraftLogs != nil && byBlizzard != nil && byWarcraftLogs.ID != byBlizzard.ID:
+		// Warcraft Logs knows both characters are the same one
+		if err := r.mergeIdentities(tx, byBlizzard, byWarcraftLogs); err != nil {
+			return nil, err
+		}
+	case identity == nil:
+		identity = byBlizzard
+	}
+
+	// Heuristic: the identity seen last under this name and realm, with the same class and no conflicting ID
+	if identity == nil {
+		query := `
+		SELECT pi.*
+		FROM player_aliases pa
+		JOIN player_identities pi ON pi.id = pa.player_identity_id AND pi.deleted_at IS NULL
+		WHERE LOWER(pa.name) = LOWER(?)
+			AND ` + fmt.Sprintf(normalizedServerSQL, "pa.server_name") + ` = ?
+			AND pa.region = ?
+			AND pa.deleted_at IS NULL
+			AND pi.class = ?
+			AND (pi.warcraft_logs_character_id IS NULL OR ? = 0 OR pi.warcraft_logs_character_id = ?)
+			AND (pi.blizzard_character_id IS NULL OR ? = 0 OR pi.blizzard_character_id = ?)
+		ORDER BY pa.last_seen_at DESC
+		LIMIT 1`
+
+		var candidates []warcraftlogsBuilds.PlayerIdentity
+		if err := tx.Raw(query,
+			observation.Name,
+			warcraftlogsBuilds.NormalizeServerName(observation.ServerName),
+			observation.Region,
+			observation.Class,
+			observation.WarcraftLogsCharacterID, observation.WarcraftLogsCharacterID,
+			observation.BlizzardCharacterID, observation.BlizzardCharacterID,
+		).Scan(&candidates).Error; err != nil {
+			return nil, fmt.Errorf("failed to find identity of player %s-%s: %w", observation.Name, observation.ServerName, err)
+		}
+		if len(candidates) > 0 {
+			identity = &candidates[0]
+		}
+	}
+
+	if identity == nil {
+		identity = &warcraftlogsBuilds.PlayerIdentity{
+			Name:        observation.Name,
+			ServerName:  observation.ServerName,
+			Region:      observation.Region,
+			Class:       observation.Class,
+			FirstSeenAt: observation.SeenAt,
+			LastSeenAt:  observation.SeenAt,
+		}
+		if observation.WarcraftLogsCharacterID > 0 {
+			identity.WarcraftLogsCharacterID = &observation.WarcraftLogsCharacterID
+		}
+		if observation.BlizzardCharacterID > 0 {
+			identity.BlizzardCharacterID = &observation.BlizzardCharacterID
+		}
+		if err := tx.Create(identity).Error; err != nil {
+			return nil, fmt.Errorf("failed to create identity of player %s-%s: %w", observation.Name, observation.ServerName, err)
+		}
+		return identity, nil
+	}
+
+	// The identity keeps the IDs it is found by, and the latest name and realm
+	updates := make(map[string]interface{})
+	newer := !observation.SeenAt.Before(identity.LastSeenAt)
+	if observation.WarcraftLogsCharacterID > 0 && identity.WarcraftLogsCharacterID == nil {
+		updates["warcraft_logs_character_id"] = observation.WarcraftLogsCharacterID
+	}
+	if observation.BlizzardCharacterID > 0 && byBlizzard == nil &&
+		(identity.BlizzardCharacterID == nil || newer) {
+		updates["blizzard_character_id"] = observation.BlizzardCharacterID
+	}
+	if newer {
+		updates["name"] = observation.Name
+		updates["server_name"] = observation.ServerName
+		updates["region"] = observation.Region
+		updates["class"] = observation.Class
+		updates["last_seen_at"] = observation.SeenAt
+	}
+	if observation.SeenAt.Before(identity.FirstSeenAt) {
+		updates["first_seen_at"] = observation.SeenAt
+	}
+	if len(updates) > 0 {
+		updates["updated_at"] = time.Now()
+		if err := tx.Model(&warcraftlogsBuilds.PlayerIdentity{}).Where("id = ?", identity.ID).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update identity %d: %w", identity.ID, err)
+		}
+	}
+
+	return identity, nil
+}
+
+// findIdentity returns the identity matching the condition, nil when there is none
+func (r *PlayerIdentityRepository) findIdentity(tx *gorm.DB, condition string, args ...interface{}) (*warcraftlogsBuilds.PlayerIdentity, error) {
+	var identity warcraftlogsBuilds.PlayerIdentity
+	if err := tx.Where(condition, args...).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find identity: %w", err)
+	}
+	return &identity, nil
+}
+
+// mergeIdentities moves the aliases, rankings and builds of an identity to another one, and soft deletes it
+func (r *PlayerIdentityRepository) mergeIdentities(tx *gorm.DB, from, into *warcraftlogsBuilds.PlayerIdentity) error {
+	log.Printf("[INFO] Merging player identity %d (%s-%s) into %d (%s-%s)",
+		from.ID, from.Name, from.ServerName, into.ID, into.Name, into.ServerName)
+
+	statements := []string{
+		// Aliases already known by the target identity keep their widest bounds
+		`UPDATE player_aliases target
+		SET first_seen_at = LEAST(target.first_seen_at, source.first_seen_at),
+			last_seen_at = GREATEST(target.last_seen_at, source.last_seen_at),
+			updated_at = NOW()
+		FROM player_aliases source
+		WHERE source.player_identity_id = @from AND target.player_identity_id = @into
+			AND target.name = source.name AND target.server_name = source.server_name AND target.region = source.region`,
+		`DELETE FROM player_aliases source
+		WHERE source.player_identity_id = @from AND EXISTS (
+			SELECT 1 FROM player_aliases target
+			WHERE target.player_identity_id = @into
+				AND target.name = source.name AND target.server_name = source.server_name AND target.region = source.region
+		)`,
+		`UPDATE player_aliases SET player_identity_id = @into, updated_at = NOW() WHERE player_identity_id = @from`,
+		`UPDATE player_builds SET player_identity_id = @into WHERE player_identity_id = @from`,
+		`UPDATE class_rankings SET player_identity_id = @into WHERE player_identity_id = @from`,
+		`UPDATE player_rankings SET player_identity_id = @into WHERE player_identity_id = @from`,
+		`UPDATE player_identities SET merged_into_id = @into, updated_at = NOW() WHERE merged_into_id = @from`,
+		`UPDATE player_identities SET merged_into_id = @into, deleted_at = NOW(), updated_at = NOW() WHERE id = @from`,
+	}
+	args := map[string]interface{}{"from": from.ID, "into": into.ID}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return fmt.Errorf("failed to merge identity %d into %d: %w", from.ID, into.ID, err)
+		}
+	}
+
+	if from.FirstSeenAt.Before(into.FirstSeenAt) {
+		into.FirstSeenAt = from.FirstSeenAt
+		if err := tx.Model(&warcraftlogsBuilds.PlayerIdentity{}).Where("id = ?", into.ID).
+			Update("first_seen_at", from.FirstSeenAt).Error; err != nil {
+			return fmt.Errorf("failed to update identity %d: %w", into.ID, err)
+		}
+	}
+	return nil
+}
+
+// recordAlias records the name and realm of an observation for an identity
+func (r *PlayerIdentityRepository) recordAlias(tx *gorm.DB, identityID uint, observation *warcraftlogsBuilds.PlayerObservation) error {
+	query := `
+	INSERT INTO player_aliases (created_at, updated_at, player_identity_id, name, server_name, region, source, first_seen_at, last_seen_at)
+	VALUES (NOW(), NOW(), ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (player_identity_id, name, server_name, region) DO UPDATE SET
+		first_seen_at = LEAST(player_aliases.first_seen_at, EXCLUDED.first_seen_at),
+		last_seen_at = GREATEST(player_aliases.last_seen_at, EXCLUDED.last_seen_at),
+		updated_at = NOW(),
+		deleted_at = NULL`
+
+	if err := tx.Exec(query,
+		identityID,
+		observation.Name,
+		observation.ServerName,
+		observation.Region,
+		observation.Source,
+		observation.SeenAt,
+		observation.SeenAt,
+	).Error; err != nil {
+		return fmt.Errorf("failed to record alias %s-%s of identity %d: %w", observation.Name, observation.ServerName, identityID, err)
+	}
+	return nil
+}
+
+// LinkClassRankings links the class rankings without identity to the identities of their players
+// A ranking is linked to the build of its player in the same run, or else to the identity using its name and realm.
+func (r *PlayerIdentityRepository) LinkClassRankings(ctx context.Context) (int64, error) {
+	byRun := r.db.WithContext(ctx).Exec(`
+	UPDATE class_rankings cr
+	SET player_identity_id = pb.player_identity_id
+	FROM player_builds pb
+	WHERE cr.player_identity_id IS NULL
+		AND pb.player_identity_id IS NOT NULL
+		AND pb.report_code = cr.report_code
+		AND pb.fight_id = cr.report_fight_id
+		AND LOWER(pb.player_name) = LOWER(cr.player_name)
+		AND pb.deleted_at IS NULL`)
+	if byRun.Error != nil {
+		return 0, fmt.Errorf("failed to link class rankings to the builds identities: %w", byRun.Error)
+	}
+
+	byAlias := r.db.WithContext(ctx).Exec(`
+	UPDATE class_rankings cr
+	SET player_identity_id = lpa.player_identity_id
+	FROM latest_player_aliases lpa
+	WHERE cr.player_identity_id IS NULL
+		AND lpa.name_key = LOWER(cr.player_name)
+		AND lpa.server_key = ` + fmt.Sprintf(normalizedServerSQL, "cr.server_name") + `
+		AND lpa.region = UPPER(cr.server_region)`)
+	if byAlias.Error != nil {
+		return 0, fmt.Errorf("failed to link class rankings to the aliases identities: %w", byAlias.Error)
+	}
+
+	linked := byRun.RowsAffected + byAlias.RowsAffected
+	if linked > 0 {
+		log.Printf("[INFO] Linked %d class rankings to player identities", linked)
+	}
+	return linked, nil
+}
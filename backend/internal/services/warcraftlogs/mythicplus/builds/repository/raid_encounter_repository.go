@@ -0,0 +1,87 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+	RaidEncounterRepository handles database operations for the raid bosses targeted by the builds pipeline.
+
+	Methods:
+	- StoreRaidEncounters: Upserts the raid bosses of the configuration.
+	- GetRaidEncounters: Retrieves every raid boss, ordered by raid.
+	- GetRaidEncountersByRaid: Retrieves the bosses of a raid.
+	- GetRaidEncounter: Retrieves a raid boss by its slug within a raid, at a difficulty.
+*/
+
+// RaidEncounterRepository handles database operations for the raid encounters.
+type RaidEncounterRepository struct {
+	db *gorm.DB
+}
+
+// NewRaidEncounterRepository creates a new instance of RaidEncounterRepository.
+func NewRaidEncounterRepository(db *gorm.DB) *RaidEncounterRepository {
+	return &RaidEncounterRepository{
+		db: db,
+	}
+}
+
+// StoreRaidEncounters upserts the raid bosses, keyed by encounter ID and difficulty.
+func (r *RaidEncounterRepository) StoreRaidEncounters(ctx context.Context, encounters []*warcraftlogsBuilds.RaidEncounter) error {
+	if len(encounters) == 0 {
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "encounter_id"},
+			{Name: "difficulty"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"name",
+			"slug",
+			"raid_name",
+			"raid_slug",
+			"zone_id",
+			"updated_at",
+		}),
+	}).Create(&encounters)
+	if result.Error != nil {
+		return fmt.Errorf("failed to store raid encounters: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetRaidEncounters retrieves every raid boss, ordered by raid.
+func (r *RaidEncounterRepository) GetRaidEncounters(ctx context.Context) ([]warcraftlogsBuilds.RaidEncounter, error) {
+	var encounters []warcraftlogsBuilds.RaidEncounter
+	if err := r.db.WithContext(ctx).Order("raid_slug, id, difficulty").Find(&encounters).Error; err != nil {
+		return nil, fmt.Errorf("failed to get raid encounters: %w", err)
+	}
+	return encounters, nil
+}
+
+// GetRaidEncountersByRaid retrieves the bosses of a raid.
+func (r *RaidEncounterRepository) GetRaidEncountersByRaid(ctx context.Context, raidSlug string) ([]warcraftlogsBuilds.RaidEncounter, error) {
+	var encounters []warcraftlogsBuilds.RaidEncounter
+	if err := r.db.WithContext(ctx).Where("raid_slug = ?", raidSlug).Order("id").Find(&encounters).Error; err != nil {
+		return nil, fmt.Errorf("failed to get encounters of raid %s: %w", raidSlug, err)
+	}
+	return encounters, nil
+}
+
+// GetRaidEncounter retrieves a raid boss by its slug within a raid, at a difficulty.
+func (r *RaidEncounterRepository) GetRaidEncounter(ctx context.Context, raidSlug, bossSlug string, difficulty uint) (*warcraftlogsBuilds.RaidEncounter, error) {
+	var encounter warcraftlogsBuilds.RaidEncounter
+	if err := r.db.WithContext(ctx).Where("raid_slug = ? AND slug = ? AND difficulty = ?", raidSlug, bossSlug, difficulty).First(&encounter).Error; err != nil {
+		return nil, fmt.Errorf("failed to get boss %s of raid %s at difficulty %d: %w", bossSlug, raidSlug, difficulty, err)
+	}
+	return &encounter, nil
+}
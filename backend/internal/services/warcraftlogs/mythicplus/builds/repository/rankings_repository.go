@@ -50,9 +50,10 @@ func (r *RankingsRepository) StoreRankings(ctx context.Context, encounterID uint
 		return nil
 	}
 
-	// Extract class and spec information from the first ranking
+	// Extract class, spec and difficulty information from the first ranking
 	className := newRankings[0].Class
 	specName := newRankings[0].Spec
+	difficulty := newRankings[0].Difficulty
 
 	if len(newRankings) > r.maxRankingsPerSpec {
 		return fmt.Errorf("too many rankings provided: got %d, maximum allowed is %d", len(newRankings), r.maxRankingsPerSpec)
@@ -64,7 +65,7 @@ func (r *RankingsRepository) StoreRankings(ctx context.Context, encounterID uint
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		var existingRankings []*warcraftlogsBuilds.ClassRanking
 		if err := tx.WithContext(ctx).
-			Where("encounter_id = ? AND class = ? AND spec = ?", encounterID, className, specName).
+			Where("encounter_id = ? AND difficulty = ? AND class = ? AND spec = ?", encounterID, difficulty, className, specName).
 			Find(&existingRankings).Error; err != nil {
 			return fmt.Errorf("failed to fetch existing rankings: %w", err)
 		}
@@ -113,7 +114,7 @@ func (r *RankingsRepository) StoreRankings(ctx context.Context, encounterID uint
 		// Verify final count
 		var finalCount int64
 		if err := tx.Model(&warcraftlogsBuilds.ClassRanking{}).
-			Where("encounter_id = ?", encounterID).
+			Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty).
 			Count(&finalCount).Error; err != nil {
 			return fmt.Errorf("failed to count rankings: %w", err)
 		}
@@ -225,3 +226,26 @@ func (r *RankingsRepository) GetRankingsNeedingReportProcessing(ctx context.Cont
 
 	return rankings, nil
 }
+
+// TagRankingsWithPatch tags the untagged rankings of an encounter with the patch live when their run started
+// Rankings started outside of the patch registry stay untagged.
+func (r *RankingsRepository) TagRankingsWithPatch(ctx context.Context, encounterID uint) error {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE class_rankings cr
+		SET patch = p.name
+		FROM game_patches p
+		WHERE cr.encounter_id = ?
+		  AND COALESCE(cr.patch, '') = ''
+		  AND cr.start_time > 0
+		  AND cr.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND p.starts_at <= to_timestamp(cr.start_time / 1000.0) AT TIME ZONE 'UTC'
+		  AND (p.ends_at IS NULL OR to_timestamp(cr.start_time / 1000.0) AT TIME ZONE 'UTC' < p.ends_at)
+	`, encounterID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to tag rankings of encounter %d with patch: %w", encounterID, result.Error)
+	}
+
+	log.Printf("[INFO] Tagged %d rankings of encounter %d with their patch", result.RowsAffected, encounterID)
+	return nil
+}
@@ -0,0 +1,102 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+/*
+	ReportAnalysisJobRepository handles database operations for the on-demand report analysis jobs.
+
+	Methods:
+	- CreateJob: Persists a new job.
+	- GetJob: Retrieves a job by its job ID.
+	- SetWorkflowID: Stores the ID of the workflow running the job.
+	- MarkJobRunning: Marks a job as running.
+	- CompleteJob: Stores the result of a job and marks it as completed.
+	- FailJob: Marks a job as failed with the error message.
+*/
+
+// ReportAnalysisJobRepository handles database operations for the report analysis jobs.
+type ReportAnalysisJobRepository struct {
+	db *gorm.DB
+}
+
+// NewReportAnalysisJobRepository creates a new instance of ReportAnalysisJobRepository.
+func NewReportAnalysisJobRepository(db *gorm.DB) *ReportAnalysisJobRepository {
+	return &ReportAnalysisJobRepository{
+		db: db,
+	}
+}
+
+// CreateJob persists a new report analysis job.
+func (r *ReportAnalysisJobRepository) CreateJob(ctx context.Context, job *warcraftlogsBuilds.ReportAnalysisJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create report analysis job: %w", err)
+	}
+	return nil
+}
+
+// GetJob retrieves a report analysis job by its job ID.
+func (r *ReportAnalysisJobRepository) GetJob(ctx context.Context, jobID string) (*warcraftlogsBuilds.ReportAnalysisJob, error) {
+	var job warcraftlogsBuilds.ReportAnalysisJob
+	if err := r.db.WithContext(ctx).Where("job_id = ?", jobID).First(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to get report analysis job %s: %w", jobID, err)
+	}
+	return &job, nil
+}
+
+// SetWorkflowID stores the ID of the workflow running the job.
+func (r *ReportAnalysisJobRepository) SetWorkflowID(ctx context.Context, jobID, workflowID string) error {
+	return r.updateJob(ctx, jobID, map[string]interface{}{
+		"workflow_id": workflowID,
+	})
+}
+
+// MarkJobRunning marks a job as running.
+func (r *ReportAnalysisJobRepository) MarkJobRunning(ctx context.Context, jobID string) error {
+	return r.updateJob(ctx, jobID, map[string]interface{}{
+		"status":     warcraftlogsBuilds.ReportAnalysisStatusRunning,
+		"started_at": time.Now(),
+	})
+}
+
+// CompleteJob stores the result of a job and marks it as completed.
+func (r *ReportAnalysisJobRepository) CompleteJob(ctx context.Context, jobID string, result datatypes.JSON) error {
+	return r.updateJob(ctx, jobID, map[string]interface{}{
+		"status":       warcraftlogsBuilds.ReportAnalysisStatusCompleted,
+		"result":       result,
+		"error":        "",
+		"completed_at": time.Now(),
+	})
+}
+
+// FailJob marks a job as failed with the error message.
+func (r *ReportAnalysisJobRepository) FailJob(ctx context.Context, jobID, message string) error {
+	return r.updateJob(ctx, jobID, map[string]interface{}{
+		"status":       warcraftlogsBuilds.ReportAnalysisStatusFailed,
+		"error":        message,
+		"completed_at": time.Now(),
+	})
+}
+
+// updateJob updates the columns of a job
+func (r *ReportAnalysisJobRepository) updateJob(ctx context.Context, jobID string, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).
+		Model(&warcraftlogsBuilds.ReportAnalysisJob{}).
+		Where("job_id = ?", jobID).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update report analysis job %s: %w", jobID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("report analysis job %s not found", jobID)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportArchiveCandidate is the data of a report column to archive
+type ReportArchiveCandidate struct {
+	Code    string
+	FightID int
+	Data    []byte
+}
+
+// ReportArchiveRepository handles the database operations of the report archives
+type ReportArchiveRepository struct {
+	db *gorm.DB
+}
+
+// NewReportArchiveRepository creates a new instance of ReportArchiveRepository
+func NewReportArchiveRepository(db *gorm.DB) *ReportArchiveRepository {
+	return &ReportArchiveRepository{
+		db: db,
+	}
+}
+
+// GetReportsToArchive retrieves the data of a report column due for archiving
+// Only reports whose builds have been extracted and created before olderThan are returned,
+// reports restored after restoredBefore are left in place.
+func (r *ReportArchiveRepository) GetReportsToArchive(ctx context.Context, dataType string, olderThan, restoredBefore time.Time, limit int) ([]*ReportArchiveCandidate, error) {
+	if !warcraftlogsBuilds.IsValidReportArchiveDataType(dataType) {
+		return nil, fmt.Errorf("invalid report archive data type: %s", dataType)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT r.code, r.fight_id, r.%[1]s::text AS data
+		FROM warcraft_logs_reports r
+		WHERE r.build_extraction_status = 'processed'
+		  AND r.%[1]s IS NOT NULL
+		  AND r.%[1]s <> 'null'::jsonb
+		  AND r.created_at < ?
+		  AND r.deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM report_archives a
+			WHERE a.report_code = r.code
+			  AND a.fight_id = r.fight_id
+			  AND a.data_type = ?
+			  AND a.restored_at > ?
+			  AND a.deleted_at IS NULL
+		  )
+		ORDER BY r.created_at ASC, r.code ASC, r.fight_id ASC
+		LIMIT ?
+	`, dataType)
+
+	var candidates []*ReportArchiveCandidate
+	if err := r.db.WithContext(ctx).Raw(query, olderThan, dataType, restoredBefore, limit).Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reports to archive for %s: %w", dataType, err)
+	}
+	return candidates, nil
+}
+
+// StoreArchive records an archive in the manifest and clears the archived column of the report
+func (r *ReportArchiveRepository) StoreArchive(ctx context.Context, archive *warcraftlogsBuilds.ReportArchive) error {
+	if !warcraftlogsBuilds.IsValidReportArchiveDataType(archive.DataType) {
+		return fmt.Errorf("invalid report archive data type: %s", archive.DataType)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		archive.RestoredAt = nil
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "report_code"}, {Name: "fight_id"}, {Name: "data_type"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"storage",
+				"object_key",
+				"size_bytes",
+				"compressed_bytes",
+				"checksum",
+				"archived_at",
+				"restored_at",
+				"deleted_at",
+				"updated_at",
+			}),
+		}).Create(archive)
+		if result.Error != nil {
+			return fmt.Errorf("failed to store archive of %s-#%d %s: %w", archive.ReportCode, archive.FightID, archive.DataType, result.Error)
+		}
+
+		result = tx.Model(&warcraftlogsBuilds.Report{}).
+			Where("code = ? AND fight_id = ?", archive.ReportCode, archive.FightID).
+			Update(archive.DataType, gorm.Expr("NULL"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to clear %s of report %s-#%d: %w", archive.DataType, archive.ReportCode, archive.FightID, result.Error)
+		}
+		return nil
+	})
+}
+
+// GetArchives retrieves the archives of reports, all data types are returned when dataTypes is empty
+func (r *ReportArchiveRepository) GetArchives(ctx context.Context, identifiers []ReportIdentifier, dataTypes []string) ([]*warcraftlogsBuilds.ReportArchive, error) {
+	var archives []*warcraftlogsBuilds.ReportArchive
+	if len(identifiers) == 0 {
+		return archives, nil
+	}
+
+	pairs := make([][]interface{}, 0, len(identifiers))
+	for _, id := range identifiers {
+		pairs = append(pairs, []interface{}{id.Code, id.FightID})
+	}
+
+	query := r.db.WithContext(ctx).Where("(report_code, fight_id) IN ?", pairs)
+	if len(dataTypes) > 0 {
+		query = query.Where("data_type IN ?", dataTypes)
+	}
+
+	if err := query.Order("report_code, fight_id, data_type").Find(&archives).Error; err != nil {
+		return nil, fmt.Errorf("failed to get report archives: %w", err)
+	}
+	return archives, nil
+}
+
+// RestoreArchive writes the data of an archive back to its report column
+// The manifest entry is kept, so the data can be archived again without a new upload.
+func (r *ReportArchiveRepository) RestoreArchive(ctx context.Context, archive *warcraftlogsBuilds.ReportArchive, data []byte) error {
+	if !warcraftlogsBuilds.IsValidReportRestoreDataType(archive.DataType) {
+		return fmt.Errorf("invalid report archive data type: %s", archive.DataType)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&warcraftlogsBuilds.Report{}).
+			Where("code = ? AND fight_id = ?", archive.ReportCode, archive.FightID).
+			Update(archive.DataType, gorm.Expr("?::jsonb", string(data)))
+		if result.Error != nil {
+			return fmt.Errorf("failed to restore %s of report %s-#%d: %w", archive.DataType, archive.ReportCode, archive.FightID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			log.Printf("[WARN] No report found for %s-#%d when restoring %s", archive.ReportCode, archive.FightID, archive.DataType)
+		}
+
+		now := time.Now()
+		if err := tx.Model(archive).Updates(map[string]interface{}{
+			"restored_at": now,
+			"updated_at":  now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark archive %d as restored: %w", archive.ID, err)
+		}
+		archive.RestoredAt = &now
+		return nil
+	})
+}
+
+// GetReportsByIdentifiers retrieves reports by code and fight ID
+func (r *ReportArchiveRepository) GetReportsByIdentifiers(ctx context.Context, identifiers []ReportIdentifier) ([]*warcraftlogsBuilds.Report, error) {
+	var reports []*warcraftlogsBuilds.Report
+	if len(identifiers) == 0 {
+		return reports, nil
+	}
+
+	pairs := make([][]interface{}, 0, len(identifiers))
+	for _, id := range identifiers {
+		pairs = append(pairs, []interface{}{id.Code, id.FightID})
+	}
+
+	if err := r.db.WithContext(ctx).Where("(code, fight_id) IN ?", pairs).Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reports by identifiers: %w", err)
+	}
+	return reports, nil
+}
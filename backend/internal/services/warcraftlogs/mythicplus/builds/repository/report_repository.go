@@ -96,6 +96,7 @@ func (r *ReportRepository) processBatchBulk(ctx context.Context, batch []*warcra
 			},
 			DoUpdates: clause.AssignmentColumns([]string{
 				"encounter_id",
+				"difficulty",
 				"total_time",
 				"item_level",
 				"composition",
@@ -105,6 +106,8 @@ func (r *ReportRepository) processBatchBulk(ctx context.Context, batch []*warcra
 				"talent_codes",
 				"keystonelevel",
 				"affixes",
+				"fights",
+				"ranked_characters",
 				"updated_at",
 			}),
 		}).Create(&deduplicatedBatch)
@@ -340,3 +343,75 @@ func (r *ReportRepository) CountReportsNeedingBuildExtraction(ctx context.Contex
 	}
 	return count, nil
 }
+
+// CountReportsForEncounterSince counts the reports of an encounter stored after a given date
+func (r *ReportRepository) CountReportsForEncounterSince(ctx context.Context, encounterID uint, since time.Time) (int64, error) {
+	var count int64
+
+	result := r.db.WithContext(ctx).
+		Model(&warcraftlogsBuilds.Report{}).
+		Where("encounter_id = ? AND created_at > ?", encounterID, since).
+		Count(&count)
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count reports for encounter %d: %w", encounterID, result.Error)
+	}
+	return count, nil
+}
+
+// GetReportsForEncounterSince retrieves the combat data of the reports of an encounter stored after a given date
+// Only the columns needed by the combat analyses are loaded, player details are left out
+func (r *ReportRepository) GetReportsForEncounterSince(ctx context.Context, encounterID uint, since time.Time, limit int, offset int) ([]*warcraftlogsBuilds.Report, error) {
+	var reports []*warcraftlogsBuilds.Report
+
+	result := r.db.WithContext(ctx).
+		Select(
+			"code",
+			"fight_id",
+			"encounter_id",
+			"total_time",
+			"keystonelevel",
+			"keystonetime",
+			"affixes",
+			"composition",
+			"damage_done",
+			"healing_done",
+			"damage_taken",
+			"death_events",
+			"fights",
+			"created_at",
+		).
+		Where("encounter_id = ? AND created_at > ?", encounterID, since).
+		Order("created_at ASC, code ASC, fight_id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reports)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get reports for encounter %d: %w", encounterID, result.Error)
+	}
+	return reports, nil
+}
+
+// TagReportsWithPatch tags the untagged reports with the patch of their ranking
+// The patch is kept only if it matches the game version of the report.
+func (r *ReportRepository) TagReportsWithPatch(ctx context.Context) error {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE warcraft_logs_reports r
+		SET patch = p.name
+		FROM class_rankings cr, game_patches p
+		WHERE cr.report_code = r.code
+		  AND cr.report_fight_id = r.fight_id
+		  AND cr.patch = p.name
+		  AND COALESCE(r.patch, '') = ''
+		  AND r.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND (p.game_version IS NULL OR r.game_version = 0 OR p.game_version = r.game_version)
+	`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to tag reports with patch: %w", result.Error)
+	}
+
+	log.Printf("[INFO] Tagged %d reports with their patch", result.RowsAffected)
+	return nil
+}
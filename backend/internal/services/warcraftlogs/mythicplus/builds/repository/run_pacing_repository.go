@@ -0,0 +1,67 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	RunPacingRepository handles database operations for the run pacing segments.
+
+	Methods:
+	- StoreRunPacingSegments: Replaces the pacing segments of the runs.
+*/
+
+// RunPacingRepository handles database operations for the run pacing segments.
+type RunPacingRepository struct {
+	db *gorm.DB
+}
+
+// NewRunPacingRepository creates a new instance of RunPacingRepository.
+func NewRunPacingRepository(db *gorm.DB) *RunPacingRepository {
+	return &RunPacingRepository{
+		db: db,
+	}
+}
+
+// StoreRunPacingSegments replaces the pacing segments of the runs, the segments of a run are all stored together.
+// A fetched report replaces its previous timeline, so the existing segments of the runs are hard deleted first.
+func (r *RunPacingRepository) StoreRunPacingSegments(ctx context.Context, segments []*warcraftlogsBuilds.RunPacingSegment) error {
+	if len(segments) == 0 {
+		log.Printf("[DEBUG] No run pacing segments to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	runs := make(map[string][2]interface{})
+	for _, segment := range segments {
+		runs[fmt.Sprintf("%s-%d", segment.ReportCode, segment.FightID)] = [2]interface{}{segment.ReportCode, segment.FightID}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, run := range runs {
+			if err := tx.Unscoped().
+				Where("report_code = ? AND fight_id = ?", run[0], run[1]).
+				Delete(&warcraftlogsBuilds.RunPacingSegment{}).Error; err != nil {
+				return fmt.Errorf("failed to delete pacing segments of report %s-#%d: %w", run[0], run[1], err)
+			}
+		}
+
+		if err := tx.CreateInBatches(segments, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store run pacing segments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Stored %d pacing segments of %d runs", len(segments), len(runs))
+	return nil
+}
@@ -21,6 +21,8 @@ import (
 	- StoreManyStatStatistics: Persists multiple stat statistics to the database.
 	- CountStatStatistics: Returns the total count of stat statistics in the database.
 	- GetStatPriorities: Returns stat statistics sorted by average value (highest first) for a specific category.
+	- SnapshotStatStatistics: Copies the stat statistics of a class, spec and encounter to the weekly snapshots.
+	- ReplaceStatStatisticSegments: Replaces the stat statistics of a class, spec and encounter segmented by keystone level and affix set.
 */
 
 // StatStatisticsRepository handles database operations for character stat statistics.
@@ -36,14 +38,15 @@ func NewStatStatisticsRepository(db *gorm.DB) *StatStatisticsRepository {
 }
 
 // DeleteStatStatistics removes stat statistics for a class and spec.
-func (r *StatStatisticsRepository) DeleteStatStatistics(ctx context.Context, class, spec string, encounterID uint) error {
+// The raid bosses are restricted to a difficulty, 0 for the Mythic+ dungeons.
+func (r *StatStatisticsRepository) DeleteStatStatistics(ctx context.Context, class, spec string, encounterID, difficulty uint) error {
 	// Unscoped() for the hard delete
 	query := r.db.WithContext(ctx).
 		Unscoped().
 		Where("class = ? AND spec = ?", class, spec)
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	result := query.Delete(&warcraftlogsBuilds.StatStatistic{})
@@ -51,8 +54,8 @@ func (r *StatStatisticsRepository) DeleteStatStatistics(ctx context.Context, cla
 		return fmt.Errorf("failed to delete stat statistics for %s-%s: %w", class, spec, result.Error)
 	}
 
-	log.Printf("[INFO] Deleted %d existing stat statistics for %s-%s (encounterID: %d)",
-		result.RowsAffected, class, spec, encounterID)
+	log.Printf("[INFO] Deleted %d existing stat statistics for %s-%s (encounterID: %d, difficulty: %d)",
+		result.RowsAffected, class, spec, encounterID, difficulty)
 
 	// reset of the status in the player_builds table
 	resetQuery := r.db.WithContext(ctx).
@@ -60,7 +63,7 @@ func (r *StatStatisticsRepository) DeleteStatStatistics(ctx context.Context, cla
 		Where("class = ? AND spec = ?", class, spec)
 
 	if encounterID > 0 {
-		resetQuery = resetQuery.Where("encounter_id = ?", encounterID)
+		resetQuery = resetQuery.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	resetResult := resetQuery.
@@ -75,8 +78,8 @@ func (r *StatStatisticsRepository) DeleteStatStatistics(ctx context.Context, cla
 		return fmt.Errorf("failed to reset stats status for builds %s-%s: %w", class, spec, resetResult.Error)
 	}
 
-	log.Printf("[INFO] Reset stats status to 'pending' for %d builds of %s-%s (encounterID: %d)",
-		resetResult.RowsAffected, class, spec, encounterID)
+	log.Printf("[INFO] Reset stats status to 'pending' for %d builds of %s-%s (encounterID: %d, difficulty: %d)",
+		resetResult.RowsAffected, class, spec, encounterID, difficulty)
 
 	return nil
 }
@@ -151,6 +154,7 @@ func (r *StatStatisticsRepository) StoreManyStatStatistics(ctx context.Context,
 						{Name: "class"},
 						{Name: "spec"},
 						{Name: "encounter_id"},
+						{Name: "difficulty"},
 						{Name: "stat_name"},
 					},
 					DoUpdates: clause.Assignments(map[string]interface{}{
@@ -239,3 +243,69 @@ func (r *StatStatisticsRepository) GetStatPriorities(ctx context.Context, class,
 		class, spec, category, encounterID)
 	return stats, nil
 }
+
+// SnapshotStatStatistics copies the stat statistics of a class, spec and encounter to the snapshot of a weekly period.
+// The snapshot of the period is replaced, so only the last run of the week is kept.
+func (r *StatStatisticsRepository) SnapshotStatStatistics(ctx context.Context, class, spec string, encounterID uint, periodStart time.Time) error {
+	var copied int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("period_start = ? AND class = ? AND spec = ? AND encounter_id = ?", periodStart, class, spec, encounterID).
+			Delete(&warcraftlogsBuilds.StatStatisticSnapshot{}).Error; err != nil {
+			return fmt.Errorf("failed to delete stat statistic snapshots: %w", err)
+		}
+
+		result := tx.Exec(`
+			INSERT INTO stat_statistic_snapshots (
+				created_at, updated_at, period_start, class, spec, encounter_id,
+				stat_name, stat_category, avg_value, sample_size
+			)
+			SELECT NOW(), NOW(), ?, class, spec, encounter_id,
+				stat_name, stat_category, avg_value, sample_size
+			FROM stat_statistics
+			WHERE class = ? AND spec = ? AND encounter_id = ? AND deleted_at IS NULL`,
+			periodStart, class, spec, encounterID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to copy stat statistics: %w", result.Error)
+		}
+		copied = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Snapshot %d stat statistics for %s-%s (encounterID: %d, period: %s)",
+		copied, class, spec, encounterID, periodStart.Format("2006-01-02"))
+	return nil
+}
+
+// ReplaceStatStatisticSegments replaces the segmented stat statistics of a class, spec and encounter.
+// Segments are fully recomputed on each run so the previous ones are hard deleted in the same transaction.
+func (r *StatStatisticsRepository) ReplaceStatStatisticSegments(ctx context.Context, class, spec string, encounterID uint, segments []*warcraftlogsBuilds.StatStatisticSegment) error {
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("class = ? AND spec = ? AND encounter_id = ?", class, spec, encounterID).
+			Delete(&warcraftlogsBuilds.StatStatisticSegment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete stat statistic segments: %w", err)
+		}
+
+		if len(segments) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(segments, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store stat statistic segments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Stored %d stat statistic segments for %s-%s (encounterID: %d)",
+		len(segments), class, spec, encounterID)
+	return nil
+}
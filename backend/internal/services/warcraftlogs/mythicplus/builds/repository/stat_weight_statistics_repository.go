@@ -0,0 +1,91 @@
+package warcraftlogsBuildsRepository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+
+	"gorm.io/gorm"
+)
+
+/*
+	StatWeightStatisticsRepository handles database operations for stat weight statistics.
+
+	Methods:
+	- DeleteStatWeightStatistics: Deletes the stat weights of a class, spec and metric.
+	- StoreManyStatWeightStatistics: Persists multiple stat weights to the database.
+	- GetStatWeightStatistics: Retrieves the stat weights of a spec, optionally for a single metric.
+*/
+
+// StatWeightStatisticsRepository handles database operations for stat weight statistics.
+type StatWeightStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatWeightStatisticsRepository creates a new instance of StatWeightStatisticsRepository.
+func NewStatWeightStatisticsRepository(db *gorm.DB) *StatWeightStatisticsRepository {
+	return &StatWeightStatisticsRepository{
+		db: db,
+	}
+}
+
+// DeleteStatWeightStatistics removes the stat weights of a class, spec and metric.
+// Stat weights are fully recomputed on each run so a hard delete is used.
+func (r *StatWeightStatisticsRepository) DeleteStatWeightStatistics(ctx context.Context, class, spec, metric string) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("class = ? AND spec = ? AND metric = ?", class, spec, metric).
+		Delete(&warcraftlogsBuilds.StatWeightStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete stat weights for %s %s (%s): %w", class, spec, metric, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing stat weights (class: %s, spec: %s, metric: %s)",
+		result.RowsAffected, class, spec, metric)
+	return nil
+}
+
+// StoreManyStatWeightStatistics persists multiple stat weights to the database.
+func (r *StatWeightStatisticsRepository) StoreManyStatWeightStatistics(ctx context.Context, weights []*warcraftlogsBuilds.StatWeightStatistic) error {
+	if len(weights) == 0 {
+		log.Printf("[DEBUG] No stat weights to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(weights, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store stat weights: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d stat weights", len(weights))
+	return nil
+}
+
+// GetStatWeightStatistics retrieves the stat weights of a spec.
+// An empty metric returns the weights of every metric.
+func (r *StatWeightStatisticsRepository) GetStatWeightStatistics(ctx context.Context, class, spec, metric string) ([]*warcraftlogsBuilds.StatWeightStatistic, error) {
+	var weights []*warcraftlogsBuilds.StatWeightStatistic
+
+	query := r.db.WithContext(ctx).
+		Model(&warcraftlogsBuilds.StatWeightStatistic{}).
+		Where("class = ? AND spec = ?", class, spec)
+
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	if err := query.Order("metric ASC, weight DESC").Find(&weights).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch stat weights: %w", err)
+	}
+
+	return weights, nil
+}
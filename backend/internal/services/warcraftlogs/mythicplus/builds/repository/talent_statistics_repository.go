@@ -21,6 +21,10 @@ import (
 	- StoreManyTalentStatistics: Persists multiple talent statistics to the database.
 	- CountTalentStatistics: Returns the total count of talent statistics in the database.
 	- GetMostPopularTalentImport: Returns the most frequently used talent import for a class/spec.
+	- DeleteTalentNodeStatistics: Deletes the talent node statistics of a class, spec and encounter.
+	- StoreManyTalentNodeStatistics: Persists multiple talent node statistics to the database.
+	- SnapshotTalentStatistics: Copies the talent statistics of a class, spec and encounter to the weekly snapshots.
+	- ReplaceTalentStatisticSegments: Replaces the talent statistics of a class, spec and encounter segmented by keystone level and affix set.
 */
 
 // TalentStatisticsRepository handles database operations for talent statistics.
@@ -36,13 +40,14 @@ func NewTalentStatisticsRepository(db *gorm.DB) *TalentStatisticsRepository {
 }
 
 // DeleteTalentStatistics removes talent statistics for a class and spec.
-func (r *TalentStatisticsRepository) DeleteTalentStatistics(ctx context.Context, class, spec string, encounterID uint) error {
+// The raid bosses are restricted to a difficulty, 0 for the Mythic+ dungeons.
+func (r *TalentStatisticsRepository) DeleteTalentStatistics(ctx context.Context, class, spec string, encounterID, difficulty uint) error {
 	query := r.db.WithContext(ctx).
 		Unscoped().
 		Where("class = ? AND spec = ?", class, spec)
 
 	if encounterID > 0 {
-		query = query.Where("encounter_id = ?", encounterID)
+		query = query.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	result := query.Delete(&warcraftlogsBuilds.TalentStatistic{})
@@ -50,8 +55,8 @@ func (r *TalentStatisticsRepository) DeleteTalentStatistics(ctx context.Context,
 		return fmt.Errorf("failed to delete talent statistics for %s-%s: %w", class, spec, result.Error)
 	}
 
-	log.Printf("[INFO] Deleted %d existing talent statistics for %s-%s (encounterID: %d)",
-		result.RowsAffected, class, spec, encounterID)
+	log.Printf("[INFO] Deleted %d existing talent statistics for %s-%s (encounterID: %d, difficulty: %d)",
+		result.RowsAffected, class, spec, encounterID, difficulty)
 
 	// Reset the builds status to 'pending'
 	resetQuery := r.db.WithContext(ctx).
@@ -59,7 +64,7 @@ func (r *TalentStatisticsRepository) DeleteTalentStatistics(ctx context.Context,
 		Where("class = ? AND spec = ?", class, spec)
 
 	if encounterID > 0 {
-		resetQuery = resetQuery.Where("encounter_id = ?", encounterID)
+		resetQuery = resetQuery.Where("encounter_id = ? AND difficulty = ?", encounterID, difficulty)
 	}
 
 	resetResult := resetQuery.
@@ -74,8 +79,8 @@ func (r *TalentStatisticsRepository) DeleteTalentStatistics(ctx context.Context,
 		return fmt.Errorf("failed to reset talent status for builds %s-%s: %w", class, spec, resetResult.Error)
 	}
 
-	log.Printf("[INFO] Reset talent status to 'pending' for %d builds of %s-%s (encounterID: %d)",
-		resetResult.RowsAffected, class, spec, encounterID)
+	log.Printf("[INFO] Reset talent status to 'pending' for %d builds of %s-%s (encounterID: %d, difficulty: %d)",
+		resetResult.RowsAffected, class, spec, encounterID, difficulty)
 
 	return nil
 }
@@ -129,6 +134,7 @@ func (r *TalentStatisticsRepository) StoreManyTalentStatistics(ctx context.Conte
 						{Name: "class"},
 						{Name: "spec"},
 						{Name: "encounter_id"},
+						{Name: "difficulty"},
 						{Name: "talent_import"},
 					},
 					DoUpdates: clause.Assignments(map[string]interface{}{
@@ -211,3 +217,108 @@ func (r *TalentStatisticsRepository) GetMostPopularTalentImport(ctx context.Cont
 
 	return &stat, nil
 }
+
+// DeleteTalentNodeStatistics removes the talent node statistics of a class, spec and encounter.
+// Node statistics are fully recomputed on each run so a hard delete is used.
+func (r *TalentStatisticsRepository) DeleteTalentNodeStatistics(ctx context.Context, class, spec string, encounterID uint) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("class = ? AND spec = ? AND encounter_id = ?", class, spec, encounterID).
+		Delete(&warcraftlogsBuilds.TalentNodeStatistic{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete talent node statistics for %s-%s: %w", class, spec, result.Error)
+	}
+
+	log.Printf("[INFO] Deleted %d existing talent node statistics for %s-%s (encounterID: %d)",
+		result.RowsAffected, class, spec, encounterID)
+	return nil
+}
+
+// StoreManyTalentNodeStatistics persists multiple talent node statistics to the database.
+func (r *TalentStatisticsRepository) StoreManyTalentNodeStatistics(ctx context.Context, nodeStats []*warcraftlogsBuilds.TalentNodeStatistic) error {
+	if len(nodeStats) == 0 {
+		log.Printf("[DEBUG] No talent node statistics to store")
+		return nil
+	}
+
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(nodeStats, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store talent node statistics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully stored %d talent node statistics", len(nodeStats))
+	return nil
+}
+
+// SnapshotTalentStatistics copies the talent statistics of a class, spec and encounter to the snapshot of a weekly period.
+// The snapshot of the period is replaced, so only the last run of the week is kept.
+func (r *TalentStatisticsRepository) SnapshotTalentStatistics(ctx context.Context, class, spec string, encounterID uint, periodStart time.Time) error {
+	var copied int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("period_start = ? AND class = ? AND spec = ? AND encounter_id = ?", periodStart, class, spec, encounterID).
+			Delete(&warcraftlogsBuilds.TalentStatisticSnapshot{}).Error; err != nil {
+			return fmt.Errorf("failed to delete talent statistic snapshots: %w", err)
+		}
+
+		result := tx.Exec(`
+			INSERT INTO talent_statistic_snapshots (
+				created_at, updated_at, period_start, class, spec, encounter_id,
+				talent_import, usage_count, usage_percentage, avg_keystone_level
+			)
+			SELECT NOW(), NOW(), ?, class, spec, encounter_id,
+				talent_import, usage_count, usage_percentage, avg_keystone_level
+			FROM talent_statistics
+			WHERE class = ? AND spec = ? AND encounter_id = ? AND deleted_at IS NULL`,
+			periodStart, class, spec, encounterID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to copy talent statistics: %w", result.Error)
+		}
+		copied = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Snapshot %d talent statistics for %s-%s (encounterID: %d, period: %s)",
+		copied, class, spec, encounterID, periodStart.Format("2006-01-02"))
+	return nil
+}
+
+// ReplaceTalentStatisticSegments replaces the segmented talent statistics of a class, spec and encounter.
+// Segments are fully recomputed on each run so the previous ones are hard deleted in the same transaction.
+func (r *TalentStatisticsRepository) ReplaceTalentStatisticSegments(ctx context.Context, class, spec string, encounterID uint, segments []*warcraftlogsBuilds.TalentStatisticSegment) error {
+	const batchSize = 100
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("class = ? AND spec = ? AND encounter_id = ?", class, spec, encounterID).
+			Delete(&warcraftlogsBuilds.TalentStatisticSegment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete talent statistic segments: %w", err)
+		}
+
+		if len(segments) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(segments, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to store talent statistic segments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Stored %d talent statistic segments for %s-%s (encounterID: %d)",
+		len(segments), class, spec, encounterID)
+	return nil
+}
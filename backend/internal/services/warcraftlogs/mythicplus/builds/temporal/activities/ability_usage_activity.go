@@ -0,0 +1,458 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	"wowperf/internal/services/warcraftlogs"
+	eventsQueries "wowperf/internal/services/warcraftlogs/mythicplus/builds/queries"
+	abilityUsageRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// Limits of the events fetch
+const (
+	eventsBatchComplexityBudget = 12 // 4 events pages per batched document
+	maxEventPagesPerLookup      = 50 // Guard against a report paging forever
+)
+
+// AbilityUsageActivity fetches the casts, interrupts and dispels of the stored reports and computes their statistics
+type AbilityUsageActivity struct {
+	client     *warcraftlogs.WarcraftLogsClientService
+	repository *abilityUsageRepository.AbilityUsageRepository
+}
+
+// NewAbilityUsageActivity creates a new AbilityUsageActivity
+func NewAbilityUsageActivity(
+	client *warcraftlogs.WarcraftLogsClientService,
+	repository *abilityUsageRepository.AbilityUsageRepository,
+) *AbilityUsageActivity {
+	return &AbilityUsageActivity{
+		client:     client,
+		repository: repository,
+	}
+}
+
+// AbilityUsageAggregation aggregates the ability usage values of a spec for a key level bracket
+type AbilityUsageAggregation struct {
+	Class           string
+	Spec            string
+	Role            string
+	KeyLevelBracket string
+
+	Values             map[string][]float64 // Values per metric
+	TotalKeystoneLevel float64
+	Players            int
+}
+
+// FetchAbilityUsages fetches the events of a batch of reports and stores the ability usages of their players
+// Reports whose events cannot be fetched are left for the next run. A rate limit stops the batch,
+// the usages already fetched are kept.
+func (a *AbilityUsageActivity) FetchAbilityUsages(
+	ctx context.Context,
+	lookbackDays int,
+	batchSize int,
+	cooldowns []workflowsModels.SpecCooldowns,
+) (*workflowsModels.AbilityUsageAnalysisWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.AbilityUsageAnalysisWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if lookbackDays <= 0 {
+		lookbackDays = 7
+	}
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+
+	reports, err := a.repository.GetReportsNeedingAbilityUsage(ctx, time.Now().AddDate(0, 0, -lookbackDays), batchSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		result.CompletedAt = time.Now()
+		return result, nil
+	}
+
+	activity.RecordHeartbeat(ctx, map[string]interface{}{
+		"status":  "fetching_events",
+		"reports": len(reports),
+	})
+
+	batcher := a.client.NewQueryBatcher(eventsBatchComplexityBudget)
+	events, errs := FetchAbilityUsageEvents(ctx, batcher, reports)
+	cooldownSpells := NewCooldownSpellSet(cooldowns)
+
+	var rateLimitErr error
+	for i, report := range reports {
+		if errs[i] != nil {
+			if warcraftlogs.IsRateLimitOrQuota(errs[i]) {
+				rateLimitErr = errs[i]
+				continue
+			}
+			logger.Error("Failed to fetch report events",
+				"reportCode", report.Code,
+				"fightID", report.FightID,
+				"error", errs[i])
+			result.ReportsFailed++
+			continue
+		}
+
+		usages, err := BuildReportAbilityUsages(report, events[i], cooldownSpells)
+		if err != nil {
+			logger.Error("Failed to build ability usages",
+				"reportCode", report.Code,
+				"fightID", report.FightID,
+				"error", err)
+			result.ReportsFailed++
+			continue
+		}
+
+		if err := a.repository.StoreReportAbilityUsages(ctx, report.Code, report.FightID, usages); err != nil {
+			return nil, err
+		}
+		result.ReportsFetched++
+		result.PlayersAnalyzed += int32(len(usages))
+	}
+
+	if rateLimitErr != nil {
+		logger.Info("Rate limit reached while fetching report events",
+			"reportsFetched", result.ReportsFetched,
+			"error", rateLimitErr)
+		return nil, temporal.NewApplicationError(
+			fmt.Sprintf("Rate limit reached: %v", rateLimitErr),
+			"RATE_LIMIT_ERROR",
+		)
+	}
+
+	logger.Info("Fetched report events",
+		"reportsFetched", result.ReportsFetched,
+		"reportsFailed", result.ReportsFailed,
+		"playersAnalyzed", result.PlayersAnalyzed)
+
+	result.CompletedAt = time.Now()
+	return result, nil
+}
+
+// eventsLookup is an events page to fetch for a report
+type eventsLookup struct {
+	report    int
+	dataType  string
+	startTime *int64
+	pages     int
+}
+
+// FetchAbilityUsageEvents fetches the casts, interrupts and dispels events of reports with batched documents
+// The pages of every report and data type are fetched together, page after page.
+// It returns the events of each report and an error for each report that could not be fetched.
+func FetchAbilityUsageEvents(
+	ctx context.Context,
+	batcher *warcraftlogs.QueryBatcher,
+	reports []*warcraftlogsBuilds.Report,
+) ([][]eventsQueries.ReportEvent, []error) {
+	events := make([][]eventsQueries.ReportEvent, len(reports))
+	errs := make([]error, len(reports))
+
+	pending := make([]eventsLookup, 0, len(reports)*len(eventsQueries.AbilityUsageEventsDataTypes))
+	for i := range reports {
+		for _, dataType := range eventsQueries.AbilityUsageEventsDataTypes {
+			pending = append(pending, eventsLookup{report: i, dataType: dataType})
+		}
+	}
+
+	for len(pending) > 0 {
+		queries := make([]warcraftlogs.BatchQuery, len(pending))
+		for i, lookup := range pending {
+			report := reports[lookup.report]
+			queries[i] = eventsQueries.ReportEventsBatchQuery(report.Code, report.FightID, lookup.dataType, lookup.startTime)
+		}
+
+		var next []eventsLookup
+		for i, result := range batcher.Execute(ctx, queries) {
+			lookup := pending[i]
+			report := reports[lookup.report]
+			if errs[lookup.report] != nil {
+				continue
+			}
+
+			if result.Err != nil {
+				errs[lookup.report] = fmt.Errorf("failed to fetch %s events of report %s-#%d: %w", lookup.dataType, report.Code, report.FightID, result.Err)
+				continue
+			}
+
+			page, nextPage, err := eventsQueries.ParseReportEventsResponse(result.Data)
+			if err != nil {
+				errs[lookup.report] = fmt.Errorf("failed to parse %s events of report %s-#%d: %w", lookup.dataType, report.Code, report.FightID, err)
+				continue
+			}
+			events[lookup.report] = append(events[lookup.report], page...)
+
+			if nextPage != nil {
+				if lookup.pages+1 >= maxEventPagesPerLookup {
+					errs[lookup.report] = fmt.Errorf("too many %s events pages for report %s-#%d", lookup.dataType, report.Code, report.FightID)
+					continue
+				}
+				next = append(next, eventsLookup{
+					report:    lookup.report,
+					dataType:  lookup.dataType,
+					startTime: nextPage,
+					pages:     lookup.pages + 1,
+				})
+			}
+		}
+		pending = next
+	}
+
+	// The events of failed reports are incomplete
+	for i := range reports {
+		if errs[i] != nil {
+			events[i] = nil
+		}
+	}
+
+	return events, errs
+}
+
+// NewCooldownSpellSet indexes the major cooldowns spell IDs by "Class_Spec"
+func NewCooldownSpellSet(cooldowns []workflowsModels.SpecCooldowns) map[string]map[int64]bool {
+	spells := make(map[string]map[int64]bool, len(cooldowns))
+	for _, spec := range cooldowns {
+		key := fmt.Sprintf("%s_%s", spec.ClassName, spec.SpecName)
+		if spells[key] == nil {
+			spells[key] = make(map[int64]bool, len(spec.SpellIDs))
+		}
+		for _, spellID := range spec.SpellIDs {
+			spells[key][spellID] = true
+		}
+	}
+	return spells
+}
+
+// BuildReportAbilityUsages counts the casts, major cooldowns, interrupts and dispels of each player of a run
+// The players come from the composition of the report, the events of pets and other actors are ignored.
+func BuildReportAbilityUsages(
+	report *warcraftlogsBuilds.Report,
+	events []eventsQueries.ReportEvent,
+	cooldownSpells map[string]map[int64]bool,
+) ([]*warcraftlogsBuilds.ReportAbilityUsage, error) {
+	var composition []CompositionPlayer
+	if len(report.Composition) > 0 {
+		if err := json.Unmarshal(report.Composition, &composition); err != nil {
+			return nil, fmt.Errorf("error parsing composition for report %s-%d: %w", report.Code, report.FightID, err)
+		}
+	}
+
+	usagesByActor := make(map[int]*warcraftlogsBuilds.ReportAbilityUsage, len(composition))
+	usages := make([]*warcraftlogsBuilds.ReportAbilityUsage, 0, len(composition))
+	for _, player := range composition {
+		if len(player.Specs) == 0 || player.Specs[0].Spec == "" {
+			continue
+		}
+
+		_, tracked := cooldownSpells[fmt.Sprintf("%s_%s", player.Type, player.Specs[0].Spec)]
+		usage := &warcraftlogsBuilds.ReportAbilityUsage{
+			ReportCode:       report.Code,
+			FightID:          report.FightID,
+			EncounterID:      report.EncounterID,
+			KeystoneLevel:    report.KeystoneLevel,
+			ActorID:          player.ID,
+			PlayerName:       player.Name,
+			Class:            player.Type,
+			Spec:             player.Specs[0].Spec,
+			Role:             player.Specs[0].Role,
+			FightDuration:    report.TotalTime,
+			CooldownsTracked: tracked,
+		}
+		usagesByActor[player.ID] = usage
+		usages = append(usages, usage)
+	}
+
+	for _, event := range events {
+		usage, exists := usagesByActor[event.SourceID]
+		if !exists {
+			continue
+		}
+
+		switch event.Type {
+		case "cast":
+			usage.Casts++
+			if cooldownSpells[fmt.Sprintf("%s_%s", usage.Class, usage.Spec)][event.AbilityGameID] {
+				usage.CooldownCasts++
+			}
+		case "interrupt":
+			usage.Interrupts++
+		case "dispel":
+			usage.Dispels++
+		}
+	}
+
+	return usages, nil
+}
+
+// ProcessAbilityUsageStatistics computes the ability usage distributions of a dungeon from the usages of the reports stored during the lookback window
+func (a *AbilityUsageActivity) ProcessAbilityUsageStatistics(
+	ctx context.Context,
+	encounterID uint,
+	lookbackDays int,
+) (*workflowsModels.AbilityUsageAnalysisWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.AbilityUsageAnalysisWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if lookbackDays <= 0 {
+		lookbackDays = 7
+	}
+
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -lookbackDays)
+
+	// 1. Get the usages of the window
+	usages, err := a.repository.GetAbilityUsagesForEncounterSince(ctx, encounterID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	activity.RecordHeartbeat(ctx, map[string]interface{}{
+		"status":    "processing_ability_usage",
+		"encounter": encounterID,
+		"usages":    len(usages),
+	})
+
+	// 2. Delete existing statistics, they are fully recomputed for the window
+	if err := a.repository.DeleteAbilityUsageStatistics(ctx, encounterID); err != nil {
+		return nil, fmt.Errorf("failed to delete existing ability usage statistics: %w", err)
+	}
+
+	if len(usages) == 0 {
+		logger.Info("No ability usages found to analyze",
+			"encounterID", encounterID,
+			"lookbackDays", lookbackDays)
+		result.CompletedAt = time.Now()
+		return result, nil
+	}
+
+	// 3. Aggregate the usages and persist the statistics
+	usageData := make(map[string]*AbilityUsageAggregation)
+	AggregateAbilityUsages(usages, usageData)
+
+	usageStats := ConvertToAbilityUsageStatistics(usageData, encounterID, periodStart, periodEnd)
+	if err := a.repository.StoreManyAbilityUsageStatistics(ctx, usageStats); err != nil {
+		return nil, fmt.Errorf("failed to store ability usage statistics: %w", err)
+	}
+
+	for _, agg := range usageData {
+		if agg.KeyLevelBracket == warcraftlogsBuilds.KeyLevelBracketAll {
+			result.PlayersAnalyzed += int32(agg.Players)
+		}
+	}
+	result.StatisticsStored = int32(len(usageStats))
+	result.DungeonsProcessed = 1
+	result.CompletedAt = time.Now()
+
+	logger.Info("Completed ability usage analysis",
+		"encounter", encounterID,
+		"playersAnalyzed", result.PlayersAnalyzed,
+		"statisticsStored", len(usageStats),
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
+
+// AggregateAbilityUsages adds the usages of the players to the aggregation of their spec
+// Each usage is counted in its key level bracket and in the "all" bracket. The major cooldowns rate
+// is only kept for the specs with tracked cooldowns and the runs with a known duration.
+func AggregateAbilityUsages(usages []*warcraftlogsBuilds.ReportAbilityUsage, usageData map[string]*AbilityUsageAggregation) {
+	for _, usage := range usages {
+		brackets := []string{warcraftlogsBuilds.KeyLevelBracketAll, warcraftlogsBuilds.GetKeyLevelBracket(usage.KeystoneLevel)}
+
+		for _, bracket := range brackets {
+			key := fmt.Sprintf("%s_%s_%s", bracket, usage.Class, usage.Spec)
+			agg, exists := usageData[key]
+			if !exists {
+				agg = &AbilityUsageAggregation{
+					Class:           usage.Class,
+					Spec:            usage.Spec,
+					KeyLevelBracket: bracket,
+					Values:          make(map[string][]float64),
+				}
+				usageData[key] = agg
+			}
+			if agg.Role == "" {
+				agg.Role = usage.Role
+			}
+
+			agg.Players++
+			agg.TotalKeystoneLevel += float64(usage.KeystoneLevel)
+			agg.Values[warcraftlogsBuilds.AbilityUsageMetricInterrupts] = append(agg.Values[warcraftlogsBuilds.AbilityUsageMetricInterrupts], float64(usage.Interrupts))
+			agg.Values[warcraftlogsBuilds.AbilityUsageMetricDispels] = append(agg.Values[warcraftlogsBuilds.AbilityUsageMetricDispels], float64(usage.Dispels))
+
+			if usage.CooldownsTracked && usage.FightDuration > 0 {
+				minutes := float64(usage.FightDuration) / 60000
+				agg.Values[warcraftlogsBuilds.AbilityUsageMetricCooldownsPerMinute] = append(agg.Values[warcraftlogsBuilds.AbilityUsageMetricCooldownsPerMinute], float64(usage.CooldownCasts)/minutes)
+			}
+		}
+	}
+}
+
+// ConvertToAbilityUsageStatistics convert the aggregated data to AbilityUsageStatistic objects
+func ConvertToAbilityUsageStatistics(
+	usageData map[string]*AbilityUsageAggregation,
+	encounterID uint,
+	periodStart, periodEnd time.Time,
+) []*warcraftlogsBuilds.AbilityUsageStatistic {
+	result := make([]*warcraftlogsBuilds.AbilityUsageStatistic, 0)
+
+	for _, agg := range usageData {
+		avgKeystoneLevel := 0.0
+		if agg.Players > 0 {
+			avgKeystoneLevel = agg.TotalKeystoneLevel / float64(agg.Players)
+		}
+
+		for _, metric := range warcraftlogsBuilds.AbilityUsageMetrics {
+			values := agg.Values[metric]
+			if len(values) == 0 {
+				continue
+			}
+
+			sorted := make([]float64, len(values))
+			copy(sorted, values)
+			sort.Float64s(sorted)
+
+			total := 0.0
+			for _, value := range sorted {
+				total += value
+			}
+
+			result = append(result, &warcraftlogsBuilds.AbilityUsageStatistic{
+				Class:            agg.Class,
+				Spec:             agg.Spec,
+				Role:             agg.Role,
+				EncounterID:      encounterID,
+				KeyLevelBracket:  agg.KeyLevelBracket,
+				Metric:           metric,
+				SampleSize:       len(sorted),
+				AvgValue:         total / float64(len(sorted)),
+				MinValue:         sorted[0],
+				MaxValue:         sorted[len(sorted)-1],
+				P25:              percentile(sorted, 25),
+				P50:              percentile(sorted, 50),
+				P75:              percentile(sorted, 75),
+				P95:              percentile(sorted, 95),
+				AvgKeystoneLevel: avgKeystoneLevel,
+				PeriodStart:      periodStart,
+				PeriodEnd:        periodEnd,
+			})
+		}
+	}
+
+	return result
+}
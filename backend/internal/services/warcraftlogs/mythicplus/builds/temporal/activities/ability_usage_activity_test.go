@@ -0,0 +1,141 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	"wowperf/internal/services/warcraftlogs"
+	eventsQueries "wowperf/internal/services/warcraftlogs/mythicplus/builds/queries"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// TestAbilityUsageStatisticsTransformation tests the transformation of report events to AbilityUsageStatistic
+func TestAbilityUsageStatisticsTransformation(t *testing.T) {
+	// 1. Create a run of 10 minutes with a Fury Warrior and a Restoration Shaman
+	report := &warcraftlogsBuilds.Report{
+		Code:          "aaaa",
+		FightID:       1,
+		EncounterID:   12660,
+		TotalTime:     600000,
+		KeystoneLevel: 14,
+		Composition: datatypes.JSON(`[
+			{"id": 3, "guid": 198885302, "name": "Brakka", "type": "Warrior", "specs": [{"role": "dps", "spec": "Fury"}]},
+			{"id": 7, "guid": 223836762, "name": "Tidera", "type": "Shaman", "specs": [{"role": "healer", "spec": "Restoration"}]}
+		]`),
+	}
+
+	cooldownSpells := activities.NewCooldownSpellSet([]workflowsModels.SpecCooldowns{
+		{ClassName: "Warrior", SpecName: "Fury", SpellIDs: []int64{1719, 107574}},
+	})
+
+	events := []eventsQueries.ReportEvent{
+		{Type: "cast", SourceID: 3, AbilityGameID: 1719},
+		{Type: "cast", SourceID: 3, AbilityGameID: 107574},
+		{Type: "cast", SourceID: 3, AbilityGameID: 1719},
+		{Type: "cast", SourceID: 3, AbilityGameID: 23881},
+		{Type: "interrupt", SourceID: 3, AbilityGameID: 6552},
+		{Type: "interrupt", SourceID: 3, AbilityGameID: 6552},
+		{Type: "cast", SourceID: 7, AbilityGameID: 1064},
+		{Type: "dispel", SourceID: 7, AbilityGameID: 77130},
+		{Type: "interrupt", SourceID: 7, AbilityGameID: 57994},
+		// Events of pets and other actors are ignored
+		{Type: "interrupt", SourceID: 42, AbilityGameID: 119910},
+	}
+
+	// 2. Count the usages of each player
+	usages, err := activities.BuildReportAbilityUsages(report, events, cooldownSpells)
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+
+	warrior, shaman := usages[0], usages[1]
+	assert.Equal(t, "Fury", warrior.Spec)
+	assert.Equal(t, 4, warrior.Casts)
+	assert.Equal(t, 3, warrior.CooldownCasts)
+	assert.True(t, warrior.CooldownsTracked)
+	assert.Equal(t, 2, warrior.Interrupts)
+	assert.Equal(t, 0, warrior.Dispels)
+
+	assert.Equal(t, "Restoration", shaman.Spec)
+	assert.Equal(t, 1, shaman.Casts)
+	assert.False(t, shaman.CooldownsTracked)
+	assert.Equal(t, 1, shaman.Interrupts)
+	assert.Equal(t, 1, shaman.Dispels)
+
+	// 3. Aggregate a second run of 20 minutes of the warrior
+	second := *warrior
+	second.KeystoneLevel = 16
+	second.FightDuration = 1200000
+	second.CooldownCasts = 4
+	second.Interrupts = 6
+
+	usageData := make(map[string]*activities.AbilityUsageAggregation)
+	activities.AggregateAbilityUsages([]*warcraftlogsBuilds.ReportAbilityUsage{warrior, shaman, &second}, usageData)
+
+	stats := activities.ConvertToAbilityUsageStatistics(usageData, 12660, time.Now().AddDate(0, 0, -7), time.Now())
+
+	byKey := make(map[string]*warcraftlogsBuilds.AbilityUsageStatistic)
+	for _, stat := range stats {
+		byKey[stat.KeyLevelBracket+"_"+stat.Spec+"_"+stat.Metric] = stat
+	}
+
+	// 4. Verify the statistics of the warrior
+	cooldowns := byKey["12+_Fury_cooldowns_per_minute"]
+	require.NotNil(t, cooldowns)
+	assert.Equal(t, 2, cooldowns.SampleSize)
+	assert.InDelta(t, 0.2, cooldowns.MinValue, 0.0001)
+	assert.InDelta(t, 0.3, cooldowns.MaxValue, 0.0001)
+	assert.InDelta(t, 0.25, cooldowns.AvgValue, 0.0001)
+	assert.InDelta(t, 15.0, cooldowns.AvgKeystoneLevel, 0.0001)
+
+	interrupts := byKey["all_Fury_interrupts"]
+	require.NotNil(t, interrupts)
+	assert.Equal(t, "dps", interrupts.Role)
+	assert.InDelta(t, 4.0, interrupts.AvgValue, 0.0001)
+
+	// 5. The shaman has no tracked cooldowns
+	assert.Nil(t, byKey["all_Restoration_cooldowns_per_minute"])
+	require.NotNil(t, byKey["all_Restoration_dispels"])
+	assert.InDelta(t, 1.0, byKey["all_Restoration_dispels"].P50, 0.0001)
+}
+
+// TestFetchAbilityUsageEventsPaging tests that every events page of a report is fetched
+func TestFetchAbilityUsageEventsPaging(t *testing.T) {
+	reports := []*warcraftlogsBuilds.Report{{Code: "aaaa", FightID: 1}}
+
+	var documents []string
+	batcher := warcraftlogs.NewQueryBatcher(func(ctx context.Context, query string) (*warcraftlogs.GraphQLResponse, error) {
+		documents = append(documents, query)
+
+		// The casts have a second page, the other data types have a single page
+		page := `{"data": [{"type": "interrupt", "sourceID": 3}], "nextPageTimestamp": null}`
+		switch {
+		case strings.Contains(query, "dataType: Casts") && !strings.Contains(query, "startTime"):
+			page = `{"data": [{"type": "cast", "sourceID": 3, "abilityGameID": 1719}], "nextPageTimestamp": 5000}`
+		case strings.Contains(query, "dataType: Casts"):
+			page = `{"data": [{"type": "cast", "sourceID": 3, "abilityGameID": 107574}], "nextPageTimestamp": null}`
+		case strings.Contains(query, "dataType: Dispels"):
+			page = `{"data": [], "nextPageTimestamp": null}`
+		}
+
+		return &warcraftlogs.GraphQLResponse{
+			Data: json.RawMessage(`{"q0": {"report": {"events": ` + page + `}}}`),
+		}, nil
+	}, eventsQueries.ReportEventsComplexity)
+
+	events, errs := activities.FetchAbilityUsageEvents(context.Background(), batcher, reports)
+
+	require.NoError(t, errs[0])
+	assert.Len(t, documents, 4)
+	assert.Contains(t, documents[3], "startTime: 5000")
+	require.Len(t, events[0], 3)
+	assert.Equal(t, int64(107574), events[0][2].AbilityGameID)
+}
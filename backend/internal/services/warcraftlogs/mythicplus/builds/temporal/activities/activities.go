@@ -2,14 +2,20 @@ package warcraftlogsBuildsTemporalActivities
 
 // Activities is a struct that contains all the activities for the Temporal worker
 type Activities struct {
-	Rankings         *RankingsActivity
-	Reports          *ReportsActivity
-	PlayerBuilds     *PlayerBuildsActivity
-	RateLimit        *RateLimitActivity
-	BuildStatistics  *BuildsStatisticsActivity
-	TalentStatistics *TalentStatisticActivity
-	StatStatistics   *StatStatisticsActivity
-	WorkflowState    *WorkflowStateActivity
+	Rankings              *RankingsActivity
+	Reports               *ReportsActivity
+	PlayerBuilds          *PlayerBuildsActivity
+	RateLimit             *RateLimitActivity
+	BuildStatistics       *BuildsStatisticsActivity
+	TalentStatistics      *TalentStatisticActivity
+	StatStatistics        *StatStatisticsActivity
+	DeathStatistics       *DeathStatisticsActivity
+	DamageTakenStatistics *DamageTakenStatisticsActivity
+	PerformanceStatistics *PerformanceStatisticsActivity
+	AbilityUsage          *AbilityUsageActivity
+	ReportAnalysis        *ReportAnalysisActivity
+	ReportRetention       *ReportRetentionActivity
+	WorkflowState         *WorkflowStateActivity
 }
 
 // NewActivities creates a new instance of Activities
@@ -21,16 +27,28 @@ func NewActivities(
 	buildStatisticsActivity *BuildsStatisticsActivity,
 	statStatisticsActivity *StatStatisticsActivity,
 	talentStatisticsActivity *TalentStatisticActivity,
+	deathStatisticsActivity *DeathStatisticsActivity,
+	damageTakenStatisticsActivity *DamageTakenStatisticsActivity,
+	performanceStatisticsActivity *PerformanceStatisticsActivity,
+	abilityUsageActivity *AbilityUsageActivity,
+	reportAnalysisActivity *ReportAnalysisActivity,
+	reportRetentionActivity *ReportRetentionActivity,
 	workflowStateActivity *WorkflowStateActivity,
 ) *Activities {
 	return &Activities{
-		Rankings:         rankingsActivity,
-		Reports:          reportsActivity,
-		PlayerBuilds:     playerBuildsActivity,
-		RateLimit:        rateLimitActivity,
-		BuildStatistics:  buildStatisticsActivity,
-		StatStatistics:   statStatisticsActivity,
-		TalentStatistics: talentStatisticsActivity,
-		WorkflowState:    workflowStateActivity,
+		Rankings:              rankingsActivity,
+		Reports:               reportsActivity,
+		PlayerBuilds:          playerBuildsActivity,
+		RateLimit:             rateLimitActivity,
+		BuildStatistics:       buildStatisticsActivity,
+		StatStatistics:        statStatisticsActivity,
+		TalentStatistics:      talentStatisticsActivity,
+		DeathStatistics:       deathStatisticsActivity,
+		DamageTakenStatistics: damageTakenStatisticsActivity,
+		PerformanceStatistics: performanceStatisticsActivity,
+		AbilityUsage:          abilityUsageActivity,
+		ReportAnalysis:        reportAnalysisActivity,
+		ReportRetention:       reportRetentionActivity,
+		WorkflowState:         workflowStateActivity,
 	}
 }
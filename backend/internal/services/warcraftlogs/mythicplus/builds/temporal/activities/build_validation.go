@@ -0,0 +1,150 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// talentImportAlphabet is the base64 alphabet of the talent import strings of the game
+const talentImportAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// Bit widths of the header of a talent import string: version, spec ID and tree hash
+const (
+	talentImportVersionBits  = 8
+	talentImportSpecIDBits   = 16
+	talentImportTreeHashBits = 128
+)
+
+// DecodeTalentImportSpecID decodes the header of a talent import string and returns its specialization ID
+// Each character holds 6 bits and the values are read from the least significant bit first.
+func DecodeTalentImportSpecID(talentImport string) (int, error) {
+	headerBits := talentImportVersionBits + talentImportSpecIDBits + talentImportTreeHashBits
+	if len(talentImport)*6 < headerBits {
+		return 0, fmt.Errorf("talent import string too short: %d characters", len(talentImport))
+	}
+
+	values := make([]int, len(talentImport))
+	for i, char := range []byte(talentImport) {
+		value := strings.IndexByte(talentImportAlphabet, char)
+		if value < 0 {
+			return 0, fmt.Errorf("invalid character %q in talent import string", char)
+		}
+		values[i] = value
+	}
+
+	position := 0
+	readBits := func(width int) int {
+		result := 0
+		for bit := 0; bit < width; bit++ {
+			if values[position/6]>>(position%6)&1 == 1 {
+				result |= 1 << bit
+			}
+			position++
+		}
+		return result
+	}
+
+	if version := readBits(talentImportVersionBits); version == 0 {
+		return 0, fmt.Errorf("invalid talent import version")
+	}
+	return readBits(talentImportSpecIDBits), nil
+}
+
+// ValidatePlayerBuild applies the rule-based checks to a build and returns the reasons of its rejection
+// A build without reasons is valid.
+func ValidatePlayerBuild(build *warcraftlogsBuilds.PlayerBuild, rules warcraftlogsBuilds.BuildValidationRules) []string {
+	var reasons []string
+
+	// 1. Equipped items
+	var gear []GearItem
+	if len(build.Gear) > 0 {
+		_ = json.Unmarshal(build.Gear, &gear)
+	}
+	items := 0
+	for _, item := range gear {
+		if item.ID > 0 {
+			items++
+		}
+	}
+	if items < rules.MinItems {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonInsufficientItems)
+	}
+
+	// 2. Item level bounds
+	if build.ItemLevel < rules.MinItemLevel || build.ItemLevel > rules.MaxItemLevel {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonItemLevelOutOfBounds)
+	}
+
+	// 3. Stats
+	var stats map[string]Stat
+	if len(build.Stats) > 0 {
+		_ = json.Unmarshal(build.Stats, &stats)
+	}
+	hasStats := false
+	for _, stat := range stats {
+		if stat.Max > 0 {
+			hasStats = true
+			break
+		}
+	}
+	if !hasStats {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonZeroStats)
+	}
+
+	// 4. Talents and spec consistency
+	expectedSpecID, knownSpec := warcraftlogsBuilds.SpecIDs[build.Class][build.Spec]
+	if !knownSpec {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonUnknownSpecialization)
+	}
+	if build.TalentImport == "" {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonMissingTalents)
+	} else if specID, err := DecodeTalentImportSpecID(build.TalentImport); err != nil {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonUndecodableTalents)
+	} else if knownSpec && specID != expectedSpecID {
+		reasons = append(reasons, warcraftlogsBuilds.ValidationReasonSpecTalentMismatch)
+	}
+
+	return reasons
+}
+
+// ValidatePlayerBuilds splits the builds between the valid builds and the quarantined ones
+func ValidatePlayerBuilds(
+	builds []*warcraftlogsBuilds.PlayerBuild,
+	rules warcraftlogsBuilds.BuildValidationRules,
+	batchID string,
+) ([]*warcraftlogsBuilds.PlayerBuild, []*warcraftlogsBuilds.QuarantinedPlayerBuild) {
+	valid := make([]*warcraftlogsBuilds.PlayerBuild, 0, len(builds))
+	var quarantined []*warcraftlogsBuilds.QuarantinedPlayerBuild
+
+	for _, build := range builds {
+		reasons := ValidatePlayerBuild(build, rules)
+		if len(reasons) == 0 {
+			valid = append(valid, build)
+			continue
+		}
+
+		quarantined = append(quarantined, &warcraftlogsBuilds.QuarantinedPlayerBuild{
+			PlayerName:    build.PlayerName,
+			Class:         build.Class,
+			Spec:          build.Spec,
+			ReportCode:    build.ReportCode,
+			FightID:       build.FightID,
+			ActorID:       build.ActorID,
+			EncounterID:   build.EncounterID,
+			KeystoneLevel: build.KeystoneLevel,
+			Patch:         build.Patch,
+			ItemLevel:     build.ItemLevel,
+			TalentImport:  build.TalentImport,
+			TalentTree:    build.TalentTree,
+			Gear:          build.Gear,
+			Stats:         build.Stats,
+			Reasons:       reasons,
+			BatchID:       batchID,
+		})
+	}
+
+	return valid, quarantined
+}
@@ -0,0 +1,107 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// furyTalentImport is the header of a Fury Warrior talent import string: version 2, spec 72, empty tree hash
+var furyTalentImport = "CgEA" + strings.Repeat("A", 60)
+
+// validBuildGear returns the gear of a build with the given number of equipped items
+func validBuildGear(items int) datatypes.JSON {
+	gear := make([]string, 0, 16)
+	for slot := 0; slot < 16; slot++ {
+		id := 0
+		if slot < items {
+			id = 200000 + slot
+		}
+		gear = append(gear, fmt.Sprintf(`{"id": %d, "slot": %d, "itemLevel": 639}`, id, slot))
+	}
+	return datatypes.JSON("[" + strings.Join(gear, ",") + "]")
+}
+
+// TestDecodeTalentImportSpecID tests the decoding of the spec of a talent import string
+func TestDecodeTalentImportSpecID(t *testing.T) {
+	specID, err := activities.DecodeTalentImportSpecID(furyTalentImport)
+	require.NoError(t, err)
+	assert.Equal(t, 72, specID)
+
+	// Frost Death Knight: spec 251
+	specID, err = activities.DecodeTalentImportSpecID("CsPA" + strings.Repeat("A", 60))
+	require.NoError(t, err)
+	assert.Equal(t, 251, specID)
+
+	_, err = activities.DecodeTalentImportSpecID("CgEA")
+	assert.Error(t, err, "too short")
+
+	_, err = activities.DecodeTalentImportSpecID("CgE-" + strings.Repeat("A", 60))
+	assert.Error(t, err, "invalid character")
+}
+
+// TestValidatePlayerBuilds tests the rule-based checks of the builds and their quarantine
+func TestValidatePlayerBuilds(t *testing.T) {
+	rules := warcraftlogsBuilds.DefaultBuildValidationRules()
+	newBuild := func(actorID int) *warcraftlogsBuilds.PlayerBuild {
+		return &warcraftlogsBuilds.PlayerBuild{
+			PlayerName:   fmt.Sprintf("Player%d", actorID),
+			Class:        "Warrior",
+			Spec:         "Fury",
+			ReportCode:   "g9Lhy8JmkV1xQ3Gj",
+			FightID:      26,
+			ActorID:      actorID,
+			ItemLevel:    639.5,
+			TalentImport: furyTalentImport,
+			Gear:         validBuildGear(16),
+			Stats:        datatypes.JSON(`{"Crit": {"min": 8000, "max": 9000}, "Haste": {"min": 12000, "max": 12000}}`),
+		}
+	}
+
+	valid := newBuild(1)
+	assert.Empty(t, activities.ValidatePlayerBuild(valid, rules))
+
+	emptyGear := newBuild(2)
+	emptyGear.Gear = validBuildGear(3)
+	emptyGear.ItemLevel = 0
+	assert.Equal(t, []string{
+		warcraftlogsBuilds.ValidationReasonInsufficientItems,
+		warcraftlogsBuilds.ValidationReasonItemLevelOutOfBounds,
+	}, activities.ValidatePlayerBuild(emptyGear, rules))
+
+	zeroStats := newBuild(3)
+	zeroStats.Stats = datatypes.JSON(`{"Crit": {"min": 0, "max": 0}}`)
+	assert.Equal(t, []string{warcraftlogsBuilds.ValidationReasonZeroStats}, activities.ValidatePlayerBuild(zeroStats, rules))
+
+	mismatch := newBuild(4)
+	mismatch.Spec = "Arms"
+	assert.Equal(t, []string{warcraftlogsBuilds.ValidationReasonSpecTalentMismatch}, activities.ValidatePlayerBuild(mismatch, rules))
+
+	undecodable := newBuild(5)
+	undecodable.TalentImport = "not a talent string"
+	assert.Equal(t, []string{warcraftlogsBuilds.ValidationReasonUndecodableTalents}, activities.ValidatePlayerBuild(undecodable, rules))
+
+	missingTalents := newBuild(6)
+	missingTalents.TalentImport = ""
+	assert.Equal(t, []string{warcraftlogsBuilds.ValidationReasonMissingTalents}, activities.ValidatePlayerBuild(missingTalents, rules))
+
+	// Split of the builds
+	builds, quarantined := activities.ValidatePlayerBuilds(
+		[]*warcraftlogsBuilds.PlayerBuild{valid, emptyGear, zeroStats, mismatch},
+		rules,
+		"builds-activity-test",
+	)
+	require.Len(t, builds, 1)
+	assert.Equal(t, 1, builds[0].ActorID)
+	require.Len(t, quarantined, 3)
+	assert.Equal(t, 2, quarantined[0].ActorID)
+	assert.Equal(t, "builds-activity-test", quarantined[0].BatchID)
+	assert.ElementsMatch(t, []string{warcraftlogsBuilds.ValidationReasonSpecTalentMismatch}, []string(quarantined[2].Reasons))
+}
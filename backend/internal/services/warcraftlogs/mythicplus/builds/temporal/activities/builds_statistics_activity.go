@@ -18,27 +18,32 @@ import (
 
 // BuildsStatisticsActivity manages all operations related to builds statistics.
 type BuildsStatisticsActivity struct {
-	playerBuildsRepository     *playerBuildsRepository.PlayerBuildsRepository
-	buildsStatisticsRepository *buildsStatisticsRepository.BuildsStatisticsRepository
+	playerBuildsRepository       *playerBuildsRepository.PlayerBuildsRepository
+	buildsStatisticsRepository   *buildsStatisticsRepository.BuildsStatisticsRepository
+	itemLiftStatisticsRepository *buildsStatisticsRepository.ItemLiftStatisticsRepository
 }
 
 // NewBuildsStatisticsActivity creates a new BuildsStatisticsActivity.
 func NewBuildsStatisticsActivity(
 	playerBuildsRepository *playerBuildsRepository.PlayerBuildsRepository,
 	buildsStatisticsRepository *buildsStatisticsRepository.BuildsStatisticsRepository,
+	itemLiftStatisticsRepository *buildsStatisticsRepository.ItemLiftStatisticsRepository,
 ) *BuildsStatisticsActivity {
 	return &BuildsStatisticsActivity{
-		playerBuildsRepository:     playerBuildsRepository,
-		buildsStatisticsRepository: buildsStatisticsRepository,
+		playerBuildsRepository:       playerBuildsRepository,
+		buildsStatisticsRepository:   buildsStatisticsRepository,
+		itemLiftStatisticsRepository: itemLiftStatisticsRepository,
 	}
 }
 
 // ProcessItemStatistics processes equipment analysis for a specific class, spec and encounter_id
-// It is called by the workflow to process the equipment analysis for a specific class, spec and encounter_id
+// It is called by the workflow to process the equipment analysis for a specific class, spec and encounter_id.
+// difficulty is the raid difficulty of a raid boss, 0 for a Mythic+ dungeon.
+// Segments and weekly snapshots are Mythic+ analytics, they are skipped for the raid bosses.
 func (a *BuildsStatisticsActivity) ProcessItemStatistics(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 	batchSize int,
 ) (*workflowsModels.EquipmentAnalysisWorkflowResult, error) {
 	logger := activity.GetLogger(ctx)
@@ -47,12 +52,12 @@ func (a *BuildsStatisticsActivity) ProcessItemStatistics(
 	}
 
 	// 1. Delete existing build statistics
-	if err := a.buildsStatisticsRepository.DeleteBuildStatistics(ctx, class, spec, encounterID); err != nil {
+	if err := a.buildsStatisticsRepository.DeleteBuildStatistics(ctx, class, spec, encounterID, difficulty); err != nil {
 		return nil, fmt.Errorf("failed to delete existing build statistics: %w", err)
 	}
 
 	// 2. Get the total number of builds to process
-	count, err := a.playerBuildsRepository.CountPlayerBuildsNeedingEquipmentAnalysis(ctx, class, spec, encounterID)
+	count, err := a.playerBuildsRepository.CountPlayerBuildsNeedingEquipmentAnalysis(ctx, class, spec, encounterID, difficulty)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +78,9 @@ func (a *BuildsStatisticsActivity) ProcessItemStatistics(
 	// For storing the IDs of successfully processed builds
 	processedBuildIDs := make([]uint, 0)
 
+	// Item usage per patch, keystone level and affix set
+	itemSegments := make(map[string]*warcraftlogsBuilds.BuildStatisticSegment)
+
 	for offset < int(count) {
 		// Record heartbeat
 		activity.RecordHeartbeat(ctx, map[string]interface{}{
@@ -86,7 +94,7 @@ func (a *BuildsStatisticsActivity) ProcessItemStatistics(
 
 		// Get a batch of builds
 		builds, err := a.playerBuildsRepository.GetPlayerBuildsNeedingEquipmentAnalysis(
-			ctx, class, spec, encounterID, batchSize, offset)
+			ctx, class, spec, encounterID, difficulty, batchSize, offset)
 		if err != nil {
 			return nil, err
 		}
@@ -112,6 +120,15 @@ func (a *BuildsStatisticsActivity) ProcessItemStatistics(
 			return nil, err
 		}
 
+		// Segment the batch by patch, keystone level and affix set
+		if err := a.AggregateItemSegments(builds, itemSegments); err != nil {
+			if len(batchBuildIDs) > 0 {
+				_ = a.playerBuildsRepository.MarkPlayerBuildsAsProcessedForEquipment(
+					ctx, batchBuildIDs, "failed")
+			}
+			return nil, err
+		}
+
 		// Calculate the usage percentages
 		a.CalculateUsagePercentages(buildStats)
 
@@ -153,6 +170,27 @@ func (a *BuildsStatisticsActivity) ProcessItemStatistics(
 		}
 	}
 
+	// Persist the statistics segmented by patch, keystone level and affix set, for the Mythic+ dungeons only
+	if difficulty == 0 {
+		if err := a.buildsStatisticsRepository.ReplaceBuildStatisticSegments(
+			ctx, class, spec, encounterID, a.FinalizeItemSegments(itemSegments)); err != nil {
+			return nil, fmt.Errorf("failed to store build statistic segments: %w", err)
+		}
+	}
+
+	// Keep a copy of the statistics for the weekly trends
+	if totalItems > 0 && difficulty == 0 {
+		periodStart := warcraftlogsBuilds.WeeklyResetPeriod(time.Now())
+		if err := a.buildsStatisticsRepository.SnapshotBuildStatistics(ctx, class, spec, encounterID, periodStart); err != nil {
+			logger.Error("Failed to snapshot build statistics",
+				"error", err,
+				"class", class,
+				"spec", spec,
+				"encounter", encounterID)
+			// Continue despite the error
+		}
+	}
+
 	result.TotalBuilds = int32(totalProcessed)
 	result.ItemsAnalyzed = int32(totalItems)
 	result.CompletedAt = time.Now()
@@ -270,6 +308,7 @@ func (a *BuildsStatisticsActivity) ProcessBuildsBatch(
 							Class:       build.Class,
 							Spec:        build.Spec,
 							EncounterID: build.EncounterID,
+							Difficulty:  build.Difficulty,
 							ItemSlot:    item.Slot,
 							ItemID:      item.ID,
 							ItemName:    item.Name,
@@ -409,3 +448,64 @@ func (a *BuildsStatisticsActivity) CalculateUsagePercentages(
 		}
 	}
 }
+
+// AggregateItemSegments adds the items of a batch of builds to the segments of their patch, keystone level and affix set
+// The item level of the builds is summed in AvgItemLevel, FinalizeItemSegments turns the sums into averages.
+func (a *BuildsStatisticsActivity) AggregateItemSegments(
+	builds []*warcraftlogsBuilds.PlayerBuild,
+	segments map[string]*warcraftlogsBuilds.BuildStatisticSegment,
+) error {
+	for _, build := range builds {
+		var gearItems []GearItem
+		if err := json.Unmarshal([]byte(build.Gear), &gearItems); err != nil {
+			return fmt.Errorf("error parsing gear JSON for build %d: %w", build.ID, err)
+		}
+
+		segmentKey := warcraftlogsBuilds.StatisticSegmentKey(build.Patch, build.KeystoneLevel, build.Affixes)
+		for _, item := range gearItems {
+			// Ignore empty slots
+			if item.ID == 0 {
+				continue
+			}
+
+			key := fmt.Sprintf("%s_%d_%d_%d", segmentKey, build.EncounterID, item.Slot, item.ID)
+			segment, exists := segments[key]
+			if !exists {
+				segment = &warcraftlogsBuilds.BuildStatisticSegment{
+					Class:         build.Class,
+					Spec:          build.Spec,
+					EncounterID:   build.EncounterID,
+					KeystoneLevel: build.KeystoneLevel,
+					Affixes:       warcraftlogsBuilds.SortedAffixes(build.Affixes),
+					Patch:         build.Patch,
+					ItemSlot:      item.Slot,
+					ItemID:        item.ID,
+					ItemName:      item.Name,
+					ItemIcon:      item.Icon,
+					ItemQuality:   item.Quality,
+					ItemLevel:     item.ItemLevel,
+				}
+				segments[key] = segment
+			}
+
+			segment.UsageCount++
+			segment.AvgItemLevel += build.ItemLevel
+		}
+	}
+
+	return nil
+}
+
+// FinalizeItemSegments converts the aggregated item segments to a slice and computes their averages
+func (a *BuildsStatisticsActivity) FinalizeItemSegments(
+	segments map[string]*warcraftlogsBuilds.BuildStatisticSegment,
+) []*warcraftlogsBuilds.BuildStatisticSegment {
+	result := make([]*warcraftlogsBuilds.BuildStatisticSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.UsageCount > 0 {
+			segment.AvgItemLevel /= float64(segment.UsageCount)
+		}
+		result = append(result, segment)
+	}
+	return result
+}
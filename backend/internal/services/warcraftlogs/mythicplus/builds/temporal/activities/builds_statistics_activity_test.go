@@ -251,3 +251,63 @@ func printJSON(t *testing.T, label string, v interface{}) {
 	}
 	t.Logf("%s: %s", label, string(data))
 }
+
+// TestAggregateItemSegments checks that the items are segmented by keystone level and affix set
+func TestAggregateItemSegments(t *testing.T) {
+	gearJSON := datatypes.JSON(`[{"id": 178693, "icon": "inv_helm_cloth_oribosdungeon_c_01.jpg", "name": "Cocoonsilk Cowl", "slot": 0, "quality": 3, "itemLevel": 639}, {"id": 0, "slot": 3, "itemLevel": 0}]`)
+
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		{ID: 1, Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 636, Affixes: pq.Int64Array{10, 152, 9}, Gear: gearJSON},
+		// Same affixes in another order, same segment
+		{ID: 2, Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 640, Affixes: pq.Int64Array{9, 10, 152}, Gear: gearJSON},
+		{ID: 3, Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 7, ItemLevel: 620, Affixes: pq.Int64Array{9, 10, 152}, Gear: gearJSON},
+	}
+
+	activity := &activities.BuildsStatisticsActivity{}
+	segments := make(map[string]*warcraftlogsBuilds.BuildStatisticSegment)
+	assert.NoError(t, activity.AggregateItemSegments(builds, segments))
+
+	result := activity.FinalizeItemSegments(segments)
+	assert.Len(t, result, 2, "The empty slot is ignored and the two key levels form two segments")
+
+	for _, segment := range result {
+		assert.Equal(t, pq.Int64Array{9, 10, 152}, segment.Affixes)
+		assert.Equal(t, 178693, segment.ItemID)
+
+		switch segment.KeystoneLevel {
+		case 12:
+			assert.Equal(t, 2, segment.UsageCount)
+			assert.Equal(t, 638.0, segment.AvgItemLevel)
+		case 7:
+			assert.Equal(t, 1, segment.UsageCount)
+			assert.Equal(t, 620.0, segment.AvgItemLevel)
+		default:
+			t.Errorf("unexpected keystone level %d", segment.KeystoneLevel)
+		}
+	}
+
+	// Invalid gear is reported
+	builds[0].Gear = datatypes.JSON(`{invalid`)
+	assert.Error(t, activity.AggregateItemSegments(builds, segments))
+}
+
+// TestAggregateItemSegmentsSplitsPatches checks that the builds of different patches are not merged
+func TestAggregateItemSegmentsSplitsPatches(t *testing.T) {
+	gearJSON := datatypes.JSON(`[{"id": 178693, "name": "Cocoonsilk Cowl", "slot": 0, "quality": 3, "itemLevel": 639}]`)
+
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		{ID: 1, Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 636, Affixes: pq.Int64Array{9, 10}, Patch: "11.0.7", Gear: gearJSON},
+		{ID: 2, Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 640, Affixes: pq.Int64Array{9, 10}, Patch: "11.1.0", Gear: gearJSON},
+		{ID: 3, Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 642, Affixes: pq.Int64Array{10, 9}, Patch: "11.1.0", Gear: gearJSON},
+	}
+
+	activity := &activities.BuildsStatisticsActivity{}
+	segments := make(map[string]*warcraftlogsBuilds.BuildStatisticSegment)
+	assert.NoError(t, activity.AggregateItemSegments(builds, segments))
+
+	usage := make(map[string]int)
+	for _, segment := range activity.FinalizeItemSegments(segments) {
+		usage[segment.Patch] = segment.UsageCount
+	}
+	assert.Equal(t, map[string]int{"11.0.7": 1, "11.1.0": 2}, usage, "Builds of different patches form different segments")
+}
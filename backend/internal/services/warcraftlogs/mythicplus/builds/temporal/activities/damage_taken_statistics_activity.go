@@ -0,0 +1,414 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	damageTakenStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	reportsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// Number of most damaging abilities per bracket compared between specs
+const damageTakenTopAbilities = 10
+
+// DamageTakenStatisticsActivity manages all operations related to damage taken statistics.
+type DamageTakenStatisticsActivity struct {
+	reportsRepository               *reportsRepository.ReportRepository
+	damageTakenStatisticsRepository *damageTakenStatisticsRepository.DamageTakenStatisticsRepository
+}
+
+// NewDamageTakenStatisticsActivity creates a new DamageTakenStatisticsActivity.
+func NewDamageTakenStatisticsActivity(
+	reportsRepository *reportsRepository.ReportRepository,
+	damageTakenStatisticsRepository *damageTakenStatisticsRepository.DamageTakenStatisticsRepository,
+) *DamageTakenStatisticsActivity {
+	return &DamageTakenStatisticsActivity{
+		reportsRepository:               reportsRepository,
+		damageTakenStatisticsRepository: damageTakenStatisticsRepository,
+	}
+}
+
+// DamageTakenEntry represents the damage taken from an enemy ability in the report JSON
+type DamageTakenEntry struct {
+	GUID        int     `json:"guid"`
+	Name        string  `json:"name"`
+	Type        int     `json:"type"` // Spell school
+	Total       float64 `json:"total"`
+	AbilityIcon string  `json:"abilityIcon"`
+}
+
+// CompositionSpec represents a spec played by a player in the report composition JSON
+type CompositionSpec struct {
+	Role string `json:"role"` // "tank", "healer" or "dps"
+	Spec string `json:"spec"`
+}
+
+// CompositionPlayer represents a player of the report composition JSON
+type CompositionPlayer struct {
+	ID    int               `json:"id"`
+	GUID  int64             `json:"guid"`
+	Name  string            `json:"name"`
+	Type  string            `json:"type"` // Class
+	Specs []CompositionSpec `json:"specs"`
+}
+
+// DamageTakenAbilityAggregation aggregates the damage taken from an enemy ability
+type DamageTakenAbilityAggregation struct {
+	AbilityID   int
+	AbilityName string
+	AbilityIcon string
+	AbilityType int
+
+	Occurrences        int
+	TotalDamage        float64
+	TotalDPS           float64
+	TotalNormalizedDPS float64
+	TotalKeystoneLevel float64
+}
+
+// DamageTakenSpecAggregation aggregates the damage taken in the runs including a spec
+type DamageTakenSpecAggregation struct {
+	Role  string
+	Class string
+	Spec  string
+
+	Runs               int
+	TotalKeystoneLevel float64
+	Abilities          map[int]*DamageTakenAbilityAggregation
+}
+
+// DamageTakenAggregation aggregates the damage taken of a key level bracket
+type DamageTakenAggregation struct {
+	Runs               int
+	TotalDamage        float64
+	TotalKeystoneLevel float64
+
+	Abilities map[int]*DamageTakenAbilityAggregation
+	Specs     map[string]*DamageTakenSpecAggregation
+}
+
+// newDamageTakenAggregation creates an empty DamageTakenAggregation
+func newDamageTakenAggregation() *DamageTakenAggregation {
+	return &DamageTakenAggregation{
+		Abilities: make(map[int]*DamageTakenAbilityAggregation),
+		Specs:     make(map[string]*DamageTakenSpecAggregation),
+	}
+}
+
+// ProcessDamageTakenStatistics analyzes the damage taken of a dungeon from the reports stored during the lookback window
+func (a *DamageTakenStatisticsActivity) ProcessDamageTakenStatistics(
+	ctx context.Context,
+	encounterID uint,
+	lookbackDays int,
+	batchSize int,
+) (*workflowsModels.DamageTakenAnalysisWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.DamageTakenAnalysisWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if lookbackDays <= 0 {
+		lookbackDays = 7
+	}
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -lookbackDays)
+
+	// 1. Get the total number of reports to process
+	count, err := a.reportsRepository.CountReportsForEncounterSince(ctx, encounterID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Delete existing statistics, they are fully recomputed for the window
+	if err := a.damageTakenStatisticsRepository.DeleteDamageTakenStatistics(ctx, encounterID); err != nil {
+		return nil, fmt.Errorf("failed to delete existing damage taken statistics: %w", err)
+	}
+
+	if count == 0 {
+		logger.Info("No reports found to analyze for damage taken",
+			"encounterID", encounterID,
+			"lookbackDays", lookbackDays)
+		result.CompletedAt = time.Now()
+		return result, nil
+	}
+
+	// 3. Process the reports by batches
+	damageTakenData := make(map[string]*DamageTakenAggregation)
+	offset := 0
+	totalProcessed := 0
+
+	for offset < int(count) {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":     "processing_damage_taken",
+			"encounter":  encounterID,
+			"progress":   fmt.Sprintf("%d/%d", totalProcessed, count),
+			"percentage": float64(totalProcessed) / float64(count) * 100,
+		})
+
+		reports, err := a.reportsRepository.GetReportsForEncounterSince(ctx, encounterID, periodStart, batchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reports) == 0 {
+			break
+		}
+
+		if err := a.ProcessDamageTakenBatch(reports, damageTakenData); err != nil {
+			return nil, err
+		}
+
+		totalProcessed += len(reports)
+		offset += batchSize
+	}
+
+	// 4. Convert the aggregated data and persist it
+	damageTakenStats := a.ConvertToDamageTakenStatistics(damageTakenData, encounterID, periodStart, periodEnd)
+	if err := a.damageTakenStatisticsRepository.StoreManyDamageTakenStatistics(ctx, damageTakenStats); err != nil {
+		return nil, fmt.Errorf("failed to store damage taken statistics: %w", err)
+	}
+
+	if all, ok := damageTakenData[warcraftlogsBuilds.KeyLevelBracketAll]; ok {
+		result.RunsAnalyzed = int32(all.Runs)
+	}
+	result.StatisticsStored = int32(len(damageTakenStats))
+	result.DungeonsProcessed = 1
+	result.CompletedAt = time.Now()
+
+	logger.Info("Completed damage taken analysis",
+		"encounter", encounterID,
+		"reportsProcessed", totalProcessed,
+		"runsAnalyzed", result.RunsAnalyzed,
+		"statisticsStored", len(damageTakenStats),
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
+
+// ProcessDamageTakenBatch process a batch of reports to extract the damage taken and aggregate it
+// Each run is counted in its key level bracket and in the "all" bracket.
+// The damage taken of the reports is tracked for the whole group, so the spec comparison
+// is made between the runs including each spec.
+func (a *DamageTakenStatisticsActivity) ProcessDamageTakenBatch(
+	reports []*warcraftlogsBuilds.Report,
+	damageTakenData map[string]*DamageTakenAggregation,
+) error {
+	for _, report := range reports {
+		// The run duration is required to normalize the damage
+		if report.TotalTime <= 0 || len(report.DamageTaken) == 0 {
+			continue
+		}
+
+		var entries []DamageTakenEntry
+		if err := json.Unmarshal(report.DamageTaken, &entries); err != nil {
+			return fmt.Errorf("error parsing damage taken for report %s-%d: %w", report.Code, report.FightID, err)
+		}
+
+		var composition []CompositionPlayer
+		if len(report.Composition) > 0 {
+			if err := json.Unmarshal(report.Composition, &composition); err != nil {
+				return fmt.Errorf("error parsing composition for report %s-%d: %w", report.Code, report.FightID, err)
+			}
+		}
+
+		durationSeconds := float64(report.TotalTime) / 1000
+		scaling := keyLevelScaling(report.KeystoneLevel)
+		keystoneLevel := float64(report.KeystoneLevel)
+
+		brackets := []string{warcraftlogsBuilds.KeyLevelBracketAll, warcraftlogsBuilds.GetKeyLevelBracket(report.KeystoneLevel)}
+		for _, bracket := range brackets {
+			agg, exists := damageTakenData[bracket]
+			if !exists {
+				agg = newDamageTakenAggregation()
+				damageTakenData[bracket] = agg
+			}
+
+			agg.Runs++
+			agg.TotalKeystoneLevel += keystoneLevel
+
+			// Each spec is counted once per run even when played by several players
+			specs := make([]*DamageTakenSpecAggregation, 0, len(composition))
+			seen := make(map[string]bool)
+			for _, player := range composition {
+				if len(player.Specs) == 0 {
+					continue
+				}
+				role, spec := player.Specs[0].Role, player.Specs[0].Spec
+				specKey := fmt.Sprintf("%s_%s_%s", role, player.Type, spec)
+				if seen[specKey] {
+					continue
+				}
+				seen[specKey] = true
+
+				specAgg, exists := agg.Specs[specKey]
+				if !exists {
+					specAgg = &DamageTakenSpecAggregation{
+						Role:      role,
+						Class:     player.Type,
+						Spec:      spec,
+						Abilities: make(map[int]*DamageTakenAbilityAggregation),
+					}
+					agg.Specs[specKey] = specAgg
+				}
+				specAgg.Runs++
+				specAgg.TotalKeystoneLevel += keystoneLevel
+				specs = append(specs, specAgg)
+			}
+
+			for _, entry := range entries {
+				if entry.Total <= 0 {
+					continue
+				}
+				agg.TotalDamage += entry.Total
+
+				dps := entry.Total / durationSeconds
+				addDamageTakenEntry(agg.Abilities, entry, dps, dps/scaling, keystoneLevel)
+				for _, specAgg := range specs {
+					addDamageTakenEntry(specAgg.Abilities, entry, dps, dps/scaling, keystoneLevel)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// addDamageTakenEntry adds the damage taken from an ability during a run to an ability aggregation map
+func addDamageTakenEntry(abilities map[int]*DamageTakenAbilityAggregation, entry DamageTakenEntry, dps, normalizedDPS, keystoneLevel float64) {
+	abilityAgg, exists := abilities[entry.GUID]
+	if !exists {
+		abilityAgg = &DamageTakenAbilityAggregation{
+			AbilityID:   entry.GUID,
+			AbilityName: entry.Name,
+			AbilityIcon: entry.AbilityIcon,
+			AbilityType: entry.Type,
+		}
+		abilities[entry.GUID] = abilityAgg
+	}
+
+	abilityAgg.Occurrences++
+	abilityAgg.TotalDamage += entry.Total
+	abilityAgg.TotalDPS += dps
+	abilityAgg.TotalNormalizedDPS += normalizedDPS
+	abilityAgg.TotalKeystoneLevel += keystoneLevel
+}
+
+// keyLevelScaling returns the approximate enemy damage multiplier of a keystone level compared with a +2 key
+// Damage scales by 7% per level up to +10 and by 10% per level above.
+func keyLevelScaling(keystoneLevel int) float64 {
+	if keystoneLevel < 2 {
+		keystoneLevel = 2
+	}
+
+	scaling := math.Pow(1.07, float64(min(keystoneLevel, 10)-2))
+	if keystoneLevel > 10 {
+		scaling *= math.Pow(1.10, float64(keystoneLevel-10))
+	}
+	return scaling
+}
+
+// ConvertToDamageTakenStatistics convert the aggregated data to DamageTakenStatistic objects
+// Averages are computed over all the runs of the sample, runs without damage from the ability count as zero.
+func (a *DamageTakenStatisticsActivity) ConvertToDamageTakenStatistics(
+	damageTakenData map[string]*DamageTakenAggregation,
+	encounterID uint,
+	periodStart, periodEnd time.Time,
+) []*warcraftlogsBuilds.DamageTakenStatistic {
+	result := make([]*warcraftlogsBuilds.DamageTakenStatistic, 0)
+
+	for bracket, agg := range damageTakenData {
+		if agg.Runs == 0 || agg.TotalDamage == 0 {
+			continue
+		}
+		runs := float64(agg.Runs)
+
+		abilities := make([]*DamageTakenAbilityAggregation, 0, len(agg.Abilities))
+		for _, abilityAgg := range agg.Abilities {
+			abilities = append(abilities, abilityAgg)
+		}
+		sort.Slice(abilities, func(i, j int) bool {
+			if abilities[i].TotalNormalizedDPS != abilities[j].TotalNormalizedDPS {
+				return abilities[i].TotalNormalizedDPS > abilities[j].TotalNormalizedDPS
+			}
+			return abilities[i].AbilityID < abilities[j].AbilityID
+		})
+
+		for i, abilityAgg := range abilities {
+			avgNormalizedDPS := abilityAgg.TotalNormalizedDPS / runs
+
+			result = append(result, &warcraftlogsBuilds.DamageTakenStatistic{
+				EncounterID:        encounterID,
+				KeyLevelBracket:    bracket,
+				StatisticType:      warcraftlogsBuilds.DamageTakenStatisticTypeAbility,
+				AbilityID:          abilityAgg.AbilityID,
+				AbilityName:        abilityAgg.AbilityName,
+				AbilityIcon:        abilityAgg.AbilityIcon,
+				AbilityType:        abilityAgg.AbilityType,
+				AvgTotalDamage:     abilityAgg.TotalDamage / runs,
+				AvgDamagePerSecond: abilityAgg.TotalDPS / runs,
+				AvgNormalizedDPS:   avgNormalizedDPS,
+				Percentage:         abilityAgg.TotalDamage / agg.TotalDamage * 100,
+				OccurrenceRate:     float64(abilityAgg.Occurrences) / runs * 100,
+				RelativeToAverage:  1,
+				RunsAnalyzed:       agg.Runs,
+				AvgKeystoneLevel:   agg.TotalKeystoneLevel / runs,
+				PeriodStart:        periodStart,
+				PeriodEnd:          periodEnd,
+			})
+
+			// Only the most damaging abilities are compared between specs
+			if i >= damageTakenTopAbilities || avgNormalizedDPS == 0 {
+				continue
+			}
+
+			for _, specAgg := range agg.Specs {
+				if specAgg.Runs == 0 {
+					continue
+				}
+				specRuns := float64(specAgg.Runs)
+
+				stat := &warcraftlogsBuilds.DamageTakenStatistic{
+					EncounterID:      encounterID,
+					KeyLevelBracket:  bracket,
+					StatisticType:    warcraftlogsBuilds.DamageTakenStatisticTypeSpec,
+					AbilityID:        abilityAgg.AbilityID,
+					AbilityName:      abilityAgg.AbilityName,
+					AbilityIcon:      abilityAgg.AbilityIcon,
+					AbilityType:      abilityAgg.AbilityType,
+					Role:             specAgg.Role,
+					Class:            specAgg.Class,
+					Spec:             specAgg.Spec,
+					RunsAnalyzed:     specAgg.Runs,
+					AvgKeystoneLevel: specAgg.TotalKeystoneLevel / specRuns,
+					PeriodStart:      periodStart,
+					PeriodEnd:        periodEnd,
+				}
+
+				if specAbility, ok := specAgg.Abilities[abilityAgg.AbilityID]; ok {
+					stat.AvgTotalDamage = specAbility.TotalDamage / specRuns
+					stat.AvgDamagePerSecond = specAbility.TotalDPS / specRuns
+					stat.AvgNormalizedDPS = specAbility.TotalNormalizedDPS / specRuns
+					stat.Percentage = specAbility.TotalDamage / abilityAgg.TotalDamage * 100
+					stat.OccurrenceRate = float64(specAbility.Occurrences) / specRuns * 100
+				}
+				stat.RelativeToAverage = stat.AvgNormalizedDPS / avgNormalizedDPS
+
+				result = append(result, stat)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,128 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestDamageTakenStatisticsTransformation tests the transformation of the damage taken to DamageTakenStatistic
+func TestDamageTakenStatisticsTransformation(t *testing.T) {
+	// 1. Create reports with example damage taken and compositions
+	discComposition := `[
+		{"id": 4, "guid": 258456306, "name": "Yodafotm", "type": "Paladin", "specs": [{"role": "tank", "spec": "Protection"}]},
+		{"id": 7, "guid": 223836762, "name": "Gregxo", "type": "Priest", "specs": [{"role": "healer", "spec": "Discipline"}]},
+		{"id": 3, "guid": 198885302, "name": "Zorthar", "type": "Shaman", "specs": [{"role": "dps", "spec": "Enhancement"}]}
+	]`
+	restoComposition := `[
+		{"id": 4, "guid": 258456306, "name": "Yodafotm", "type": "Paladin", "specs": [{"role": "tank", "spec": "Protection"}]},
+		{"id": 8, "guid": 223836763, "name": "Treehug", "type": "Druid", "specs": [{"role": "healer", "spec": "Restoration"}]},
+		{"id": 3, "guid": 198885302, "name": "Zorthar", "type": "Shaman", "specs": [{"role": "dps", "spec": "Enhancement"}]}
+	]`
+
+	reports := []*warcraftlogsBuilds.Report{
+		{
+			Code:          "g9Lhy8JmkV1xQ3Gj",
+			FightID:       26,
+			EncounterID:   12660,
+			TotalTime:     1000000, // 1000 seconds
+			KeystoneLevel: 2,
+			Composition:   datatypes.JSON(discComposition),
+			DamageTaken: datatypes.JSON(`[
+				{"guid": 438960, "name": "Gossamer Onslaught", "type": 8, "total": 3000000, "abilityIcon": "inv_misc_web_02.jpg"},
+				{"guid": 453584, "name": "Charge", "type": 1, "total": 1000000, "abilityIcon": "ability_warrior_charge.jpg"}
+			]`),
+		},
+		{
+			Code:          "wVkK6XBjt2ygD8mR",
+			FightID:       11,
+			EncounterID:   12660,
+			TotalTime:     2000000, // 2000 seconds
+			KeystoneLevel: 3,
+			Composition:   datatypes.JSON(restoComposition),
+			DamageTaken: datatypes.JSON(`[
+				{"guid": 438960, "name": "Gossamer Onslaught", "type": 8, "total": 4280000, "abilityIcon": "inv_misc_web_02.jpg"}
+			]`),
+		},
+		{
+			// Runs without duration cannot be normalized and are ignored
+			Code:          "aBcD1234eFgH5678",
+			FightID:       3,
+			EncounterID:   12660,
+			TotalTime:     0,
+			KeystoneLevel: 4,
+			DamageTaken:   datatypes.JSON(`[{"guid": 438960, "name": "Gossamer Onslaught", "type": 8, "total": 1000000}]`),
+		},
+	}
+
+	activity := &activities.DamageTakenStatisticsActivity{}
+	damageTakenData := make(map[string]*activities.DamageTakenAggregation)
+
+	// 2. Aggregate the damage taken
+	err := activity.ProcessDamageTakenBatch(reports, damageTakenData)
+	require.NoError(t, err)
+
+	require.Contains(t, damageTakenData, warcraftlogsBuilds.KeyLevelBracketAll)
+	require.Contains(t, damageTakenData, warcraftlogsBuilds.KeyLevelBracketLow)
+	assert.Equal(t, 2, damageTakenData[warcraftlogsBuilds.KeyLevelBracketAll].Runs)
+	assert.Equal(t, 8280000.0, damageTakenData[warcraftlogsBuilds.KeyLevelBracketAll].TotalDamage)
+
+	// 3. Convert the aggregated data to statistics
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -7)
+	stats := activity.ConvertToDamageTakenStatistics(damageTakenData, 12660, periodStart, periodEnd)
+	require.NotEmpty(t, stats)
+
+	// 4. Verify the abilities of the "all" bracket
+	abilities := make(map[string]*warcraftlogsBuilds.DamageTakenStatistic)
+	specs := make(map[string]*warcraftlogsBuilds.DamageTakenStatistic)
+	for _, stat := range stats {
+		assert.Equal(t, uint(12660), stat.EncounterID)
+		if stat.KeyLevelBracket != warcraftlogsBuilds.KeyLevelBracketAll {
+			continue
+		}
+		switch stat.StatisticType {
+		case warcraftlogsBuilds.DamageTakenStatisticTypeAbility:
+			abilities[stat.AbilityName] = stat
+		case warcraftlogsBuilds.DamageTakenStatisticTypeSpec:
+			if stat.AbilityName == "Gossamer Onslaught" {
+				specs[stat.Class+"-"+stat.Spec] = stat
+			}
+		}
+	}
+
+	gossamer := abilities["Gossamer Onslaught"]
+	require.NotNil(t, gossamer)
+	assert.Equal(t, 438960, gossamer.AbilityID)
+	assert.Equal(t, 8, gossamer.AbilityType)
+	assert.Equal(t, 3640000.0, gossamer.AvgTotalDamage)
+	assert.Equal(t, 100.0, gossamer.OccurrenceRate)
+	// +2: 3000 DPS, +3: 2140 DPS / 1.07 = 2000 normalized DPS
+	assert.InDelta(t, 2570.0, gossamer.AvgDamagePerSecond, 0.01)
+	assert.InDelta(t, 2500.0, gossamer.AvgNormalizedDPS, 0.01)
+	assert.InDelta(t, 87.92, gossamer.Percentage, 0.01)
+	assert.Equal(t, 2, gossamer.RunsAnalyzed)
+
+	charge := abilities["Charge"]
+	require.NotNil(t, charge)
+	assert.Equal(t, 50.0, charge.OccurrenceRate)
+	assert.InDelta(t, 500.0, charge.AvgNormalizedDPS, 0.01)
+
+	// 5. Verify the spec comparison for the same ability
+	require.Contains(t, specs, "Priest-Discipline")
+	require.Contains(t, specs, "Druid-Restoration")
+	require.Contains(t, specs, "Paladin-Protection")
+
+	assert.Equal(t, "healer", specs["Priest-Discipline"].Role)
+	assert.Equal(t, 1, specs["Priest-Discipline"].RunsAnalyzed)
+	assert.InDelta(t, 3000.0, specs["Priest-Discipline"].AvgNormalizedDPS, 0.01)
+	assert.InDelta(t, 1.2, specs["Priest-Discipline"].RelativeToAverage, 0.001)
+	assert.InDelta(t, 0.8, specs["Druid-Restoration"].RelativeToAverage, 0.001)
+	assert.InDelta(t, 1.0, specs["Paladin-Protection"].RelativeToAverage, 0.001)
+}
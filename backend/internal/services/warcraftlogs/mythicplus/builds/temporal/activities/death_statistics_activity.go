@@ -0,0 +1,388 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	deathStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	reportsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// Size of a run progression bucket, in percentage of the run duration
+const deathTimeBucketSize = 20
+
+// DeathStatisticsActivity manages all operations related to death statistics.
+type DeathStatisticsActivity struct {
+	reportsRepository         *reportsRepository.ReportRepository
+	deathStatisticsRepository *deathStatisticsRepository.DeathStatisticsRepository
+}
+
+// NewDeathStatisticsActivity creates a new DeathStatisticsActivity.
+func NewDeathStatisticsActivity(
+	reportsRepository *reportsRepository.ReportRepository,
+	deathStatisticsRepository *deathStatisticsRepository.DeathStatisticsRepository,
+) *DeathStatisticsActivity {
+	return &DeathStatisticsActivity{
+		reportsRepository:         reportsRepository,
+		deathStatisticsRepository: deathStatisticsRepository,
+	}
+}
+
+// DeathAbility represents the ability responsible for a death in the report JSON
+type DeathAbility struct {
+	GUID        int    `json:"guid"`
+	Name        string `json:"name"`
+	AbilityIcon string `json:"abilityIcon"`
+}
+
+// DeathEvent represents a death event in the report JSON
+type DeathEvent struct {
+	ID          int           `json:"id"`
+	GUID        int64         `json:"guid"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"` // Class
+	Icon        string        `json:"icon"` // "Class-Spec"
+	DeathTime   int64         `json:"deathTime"`
+	Ability     *DeathAbility `json:"ability,omitempty"`
+	KillingBlow *DeathAbility `json:"killingBlow,omitempty"`
+}
+
+// DeathGroupAggregation aggregates the deaths of a group (ability, spec or run moment)
+type DeathGroupAggregation struct {
+	AbilityID       int
+	AbilityName     string
+	AbilityIcon     string
+	Class           string
+	Spec            string
+	TimeBucketStart int
+	TimeBucketEnd   int
+
+	Count              int
+	TimedCount         int // Deaths with a time since the fight start, the others are left out of the average time
+	TotalDeathTime     float64
+	TotalKeystoneLevel float64
+}
+
+// DeathAggregation aggregates the deaths of a key level bracket
+type DeathAggregation struct {
+	Runs        int
+	TotalDeaths int
+	TimedDeaths int // Deaths of the runs with a fight timeline, the base of the timing percentages
+
+	Abilities map[string]*DeathGroupAggregation
+	Specs     map[string]*DeathGroupAggregation
+	Timings   map[int]*DeathGroupAggregation
+}
+
+// newDeathAggregation creates an empty DeathAggregation
+func newDeathAggregation() *DeathAggregation {
+	return &DeathAggregation{
+		Abilities: make(map[string]*DeathGroupAggregation),
+		Specs:     make(map[string]*DeathGroupAggregation),
+		Timings:   make(map[int]*DeathGroupAggregation),
+	}
+}
+
+// ProcessDeathStatistics analyzes the deaths of a dungeon from the reports stored during the lookback window
+func (a *DeathStatisticsActivity) ProcessDeathStatistics(
+	ctx context.Context,
+	encounterID uint,
+	lookbackDays int,
+	batchSize int,
+) (*workflowsModels.DeathAnalysisWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.DeathAnalysisWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if lookbackDays <= 0 {
+		lookbackDays = 7
+	}
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -lookbackDays)
+
+	// 1. Get the total number of reports to process
+	count, err := a.reportsRepository.CountReportsForEncounterSince(ctx, encounterID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Delete existing statistics, they are fully recomputed for the window
+	if err := a.deathStatisticsRepository.DeleteDeathStatistics(ctx, encounterID); err != nil {
+		return nil, fmt.Errorf("failed to delete existing death statistics: %w", err)
+	}
+
+	if count == 0 {
+		logger.Info("No reports found to analyze for deaths",
+			"encounterID", encounterID,
+			"lookbackDays", lookbackDays)
+		result.CompletedAt = time.Now()
+		return result, nil
+	}
+
+	// 3. Process the reports by batches
+	deathData := make(map[string]*DeathAggregation)
+	offset := 0
+	totalProcessed := 0
+
+	for offset < int(count) {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":     "processing_deaths",
+			"encounter":  encounterID,
+			"progress":   fmt.Sprintf("%d/%d", totalProcessed, count),
+			"percentage": float64(totalProcessed) / float64(count) * 100,
+		})
+
+		reports, err := a.reportsRepository.GetReportsForEncounterSince(ctx, encounterID, periodStart, batchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reports) == 0 {
+			break
+		}
+
+		if err := a.ProcessDeathsBatch(reports, deathData); err != nil {
+			return nil, err
+		}
+
+		totalProcessed += len(reports)
+		offset += batchSize
+	}
+
+	// 4. Convert the aggregated data and persist it
+	deathStats := a.ConvertToDeathStatistics(deathData, encounterID, periodStart, periodEnd)
+	if err := a.deathStatisticsRepository.StoreManyDeathStatistics(ctx, deathStats); err != nil {
+		return nil, fmt.Errorf("failed to store death statistics: %w", err)
+	}
+
+	if all, ok := deathData[warcraftlogsBuilds.KeyLevelBracketAll]; ok {
+		result.DeathsAnalyzed = int32(all.TotalDeaths)
+		result.RunsAnalyzed = int32(all.Runs)
+	}
+	result.StatisticsStored = int32(len(deathStats))
+	result.DungeonsProcessed = 1
+	result.CompletedAt = time.Now()
+
+	logger.Info("Completed death analysis",
+		"encounter", encounterID,
+		"reportsProcessed", totalProcessed,
+		"deathsAnalyzed", result.DeathsAnalyzed,
+		"statisticsStored", len(deathStats),
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
+
+// ProcessDeathsBatch process a batch of reports to extract the death events and aggregate them
+// Each death is counted in its key level bracket and in the "all" bracket
+func (a *DeathStatisticsActivity) ProcessDeathsBatch(
+	reports []*warcraftlogsBuilds.Report,
+	deathData map[string]*DeathAggregation,
+) error {
+	for _, report := range reports {
+		var deaths []DeathEvent
+		if len(report.DeathEvents) > 0 {
+			if err := json.Unmarshal(report.DeathEvents, &deaths); err != nil {
+				return fmt.Errorf("error parsing death events for report %s-%d: %w", report.Code, report.FightID, err)
+			}
+		}
+
+		// Death times count from the start of the report, the fight start is subtracted to get the time in the run.
+		// The reports stored without fight timeline are only counted, their death times cannot be placed in the run.
+		var timeline *warcraftlogsBuilds.ReportFightTimeline
+		if len(report.Fights) > 0 {
+			timeline = &warcraftlogsBuilds.ReportFightTimeline{}
+			if err := json.Unmarshal(report.Fights, timeline); err != nil {
+				return fmt.Errorf("error parsing fights for report %s-%d: %w", report.Code, report.FightID, err)
+			}
+		}
+
+		brackets := []string{warcraftlogsBuilds.KeyLevelBracketAll, warcraftlogsBuilds.GetKeyLevelBracket(report.KeystoneLevel)}
+		for _, bracket := range brackets {
+			agg, exists := deathData[bracket]
+			if !exists {
+				agg = newDeathAggregation()
+				deathData[bracket] = agg
+			}
+
+			agg.Runs++
+			for _, death := range deaths {
+				agg.addDeath(death, report, timeline)
+			}
+		}
+	}
+
+	return nil
+}
+
+// addDeath adds a single death event to the bracket aggregation
+// The death is aggregated at its time since the start of the fight of the timeline. Without timeline,
+// the death is counted but left out of the average time and of the run moments.
+func (agg *DeathAggregation) addDeath(death DeathEvent, report *warcraftlogsBuilds.Report, timeline *warcraftlogsBuilds.ReportFightTimeline) {
+	agg.TotalDeaths++
+	timed := timeline != nil
+	deathTime := 0.0
+	if timed {
+		agg.TimedDeaths++
+		deathTime = float64(max(death.DeathTime-timeline.StartTime, 0))
+	}
+	keystoneLevel := float64(report.KeystoneLevel)
+
+	// Killing ability
+	ability := death.Ability
+	if ability == nil {
+		ability = death.KillingBlow
+	}
+	abilityID, abilityName, abilityIcon := 0, "Unknown", ""
+	if ability != nil {
+		abilityID, abilityName, abilityIcon = ability.GUID, ability.Name, ability.AbilityIcon
+	}
+	abilityKey := fmt.Sprintf("%d_%s", abilityID, abilityName)
+	abilityAgg, exists := agg.Abilities[abilityKey]
+	if !exists {
+		abilityAgg = &DeathGroupAggregation{
+			AbilityID:   abilityID,
+			AbilityName: abilityName,
+			AbilityIcon: abilityIcon,
+		}
+		agg.Abilities[abilityKey] = abilityAgg
+	}
+	abilityAgg.add(timed, deathTime, keystoneLevel)
+
+	// Class and spec of the dead player
+	class, spec := parseClassSpecIcon(death.Icon, death.Type)
+	specKey := fmt.Sprintf("%s_%s", class, spec)
+	specAgg, exists := agg.Specs[specKey]
+	if !exists {
+		specAgg = &DeathGroupAggregation{
+			Class: class,
+			Spec:  spec,
+		}
+		agg.Specs[specKey] = specAgg
+	}
+	specAgg.add(timed, deathTime, keystoneLevel)
+
+	// Moment of the run, only when the death time and the run duration are known
+	if timed && report.TotalTime > 0 {
+		progress := int(deathTime / float64(report.TotalTime) * 100)
+		if progress < 0 {
+			progress = 0
+		}
+		if progress >= 100 {
+			progress = 100 - deathTimeBucketSize
+		}
+		bucketStart := (progress / deathTimeBucketSize) * deathTimeBucketSize
+
+		timingAgg, exists := agg.Timings[bucketStart]
+		if !exists {
+			timingAgg = &DeathGroupAggregation{
+				TimeBucketStart: bucketStart,
+				TimeBucketEnd:   bucketStart + deathTimeBucketSize,
+			}
+			agg.Timings[bucketStart] = timingAgg
+		}
+		timingAgg.add(timed, deathTime, keystoneLevel)
+	}
+}
+
+// add adds a death to the group aggregation, deathTime is only kept for the timed deaths
+func (g *DeathGroupAggregation) add(timed bool, deathTime, keystoneLevel float64) {
+	g.Count++
+	if timed {
+		g.TimedCount++
+		g.TotalDeathTime += deathTime
+	}
+	g.TotalKeystoneLevel += keystoneLevel
+}
+
+// parseClassSpecIcon extracts the class and spec from a "Class-Spec" icon, falling back on the actor type
+func parseClassSpecIcon(icon, actorType string) (string, string) {
+	parts := strings.SplitN(icon, "-", 2)
+	if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+		return parts[0], parts[1]
+	}
+	return actorType, ""
+}
+
+// ConvertToDeathStatistics convert the aggregated data to DeathStatistic objects
+func (a *DeathStatisticsActivity) ConvertToDeathStatistics(
+	deathData map[string]*DeathAggregation,
+	encounterID uint,
+	periodStart, periodEnd time.Time,
+) []*warcraftlogsBuilds.DeathStatistic {
+	result := make([]*warcraftlogsBuilds.DeathStatistic, 0)
+
+	for bracket, agg := range deathData {
+		if agg.Runs == 0 || agg.TotalDeaths == 0 {
+			continue
+		}
+
+		groups := map[string][]*DeathGroupAggregation{
+			warcraftlogsBuilds.DeathStatisticTypeAbility: make([]*DeathGroupAggregation, 0, len(agg.Abilities)),
+			warcraftlogsBuilds.DeathStatisticTypeSpec:    make([]*DeathGroupAggregation, 0, len(agg.Specs)),
+			warcraftlogsBuilds.DeathStatisticTypeTiming:  make([]*DeathGroupAggregation, 0, len(agg.Timings)),
+		}
+		for _, g := range agg.Abilities {
+			groups[warcraftlogsBuilds.DeathStatisticTypeAbility] = append(groups[warcraftlogsBuilds.DeathStatisticTypeAbility], g)
+		}
+		for _, g := range agg.Specs {
+			groups[warcraftlogsBuilds.DeathStatisticTypeSpec] = append(groups[warcraftlogsBuilds.DeathStatisticTypeSpec], g)
+		}
+		for _, g := range agg.Timings {
+			groups[warcraftlogsBuilds.DeathStatisticTypeTiming] = append(groups[warcraftlogsBuilds.DeathStatisticTypeTiming], g)
+		}
+
+		for statisticType, list := range groups {
+			for _, g := range list {
+				if g.Count == 0 {
+					continue
+				}
+
+				// The run moments only cover the timed deaths
+				totalDeaths := agg.TotalDeaths
+				if statisticType == warcraftlogsBuilds.DeathStatisticTypeTiming {
+					totalDeaths = agg.TimedDeaths
+				}
+				avgDeathTime := 0.0
+				if g.TimedCount > 0 {
+					avgDeathTime = g.TotalDeathTime / float64(g.TimedCount)
+				}
+
+				result = append(result, &warcraftlogsBuilds.DeathStatistic{
+					EncounterID:      encounterID,
+					KeyLevelBracket:  bracket,
+					StatisticType:    statisticType,
+					AbilityID:        g.AbilityID,
+					AbilityName:      g.AbilityName,
+					AbilityIcon:      g.AbilityIcon,
+					Class:            g.Class,
+					Spec:             g.Spec,
+					TimeBucketStart:  g.TimeBucketStart,
+					TimeBucketEnd:    g.TimeBucketEnd,
+					DeathCount:       g.Count,
+					DeathsPerRun:     float64(g.Count) / float64(agg.Runs),
+					Percentage:       float64(g.Count) / float64(totalDeaths) * 100,
+					AvgDeathTime:     avgDeathTime,
+					RunsAnalyzed:     agg.Runs,
+					AvgKeystoneLevel: g.TotalKeystoneLevel / float64(g.Count),
+					PeriodStart:      periodStart,
+					PeriodEnd:        periodEnd,
+				})
+			}
+		}
+	}
+
+	return result
+}
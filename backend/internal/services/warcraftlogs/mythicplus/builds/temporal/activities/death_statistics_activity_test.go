@@ -0,0 +1,194 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestDeathStatisticsTransformation tests the transformation of death events to DeathStatistic
+func TestDeathStatisticsTransformation(t *testing.T) {
+	// 1. Create reports with example death events
+	highKeyDeaths := `[
+		{"id": 3, "guid": 198885302, "icon": "Shaman-Enhancement", "name": "Zorthar", "type": "Shaman", "deathTime": 287682,
+		 "ability": {"guid": 438960, "name": "Gossamer Onslaught", "abilityIcon": "inv_misc_web_02.jpg"}},
+		{"id": 7, "guid": 223836762, "icon": "Priest-Discipline", "name": "Gregxo", "type": "Priest", "deathTime": 1500000,
+		 "ability": {"guid": 438960, "name": "Gossamer Onslaught", "abilityIcon": "inv_misc_web_02.jpg"}},
+		{"id": 4, "guid": 258456306, "icon": "Paladin-Protection", "name": "Yodafotm", "type": "Paladin", "deathTime": 900000}
+	]`
+	lowKeyDeaths := `[
+		{"id": 3, "guid": 198885302, "icon": "Shaman-Enhancement", "name": "Zorthar", "type": "Shaman", "deathTime": 100000,
+		 "ability": {"guid": 434722, "name": "Subjugate", "abilityIcon": "spell_shadow_shadowwordpain.jpg"}}
+	]`
+
+	reports := []*warcraftlogsBuilds.Report{
+		{
+			Code:          "g9Lhy8JmkV1xQ3Gj",
+			FightID:       26,
+			EncounterID:   12660,
+			TotalTime:     1719094,
+			KeystoneLevel: 18,
+			Fights:        datatypes.JSON(`{"id": 26, "startTime": 0, "endTime": 1719094}`),
+			DeathEvents:   datatypes.JSON(highKeyDeaths),
+		},
+		{
+			Code:          "wVkK6XBjt2ygD8mR",
+			FightID:       11,
+			EncounterID:   12660,
+			TotalTime:     1000000,
+			KeystoneLevel: 5,
+			Fights:        datatypes.JSON(`{"id": 11, "startTime": 0, "endTime": 1000000}`),
+			DeathEvents:   datatypes.JSON(lowKeyDeaths),
+		},
+		{
+			Code:          "aBcD1234eFgH5678",
+			FightID:       3,
+			EncounterID:   12660,
+			TotalTime:     1200000,
+			KeystoneLevel: 14,
+			DeathEvents:   datatypes.JSON(`[]`),
+		},
+	}
+
+	activity := &activities.DeathStatisticsActivity{}
+	deathData := make(map[string]*activities.DeathAggregation)
+
+	// 2. Aggregate the death events
+	err := activity.ProcessDeathsBatch(reports, deathData)
+	require.NoError(t, err)
+
+	// Every death is counted in the "all" bracket and in its own bracket
+	require.Contains(t, deathData, warcraftlogsBuilds.KeyLevelBracketAll)
+	require.Contains(t, deathData, warcraftlogsBuilds.KeyLevelBracketHigh)
+	require.Contains(t, deathData, warcraftlogsBuilds.KeyLevelBracketLow)
+	assert.NotContains(t, deathData, warcraftlogsBuilds.KeyLevelBracketMid)
+
+	assert.Equal(t, 3, deathData[warcraftlogsBuilds.KeyLevelBracketAll].Runs)
+	assert.Equal(t, 4, deathData[warcraftlogsBuilds.KeyLevelBracketAll].TotalDeaths)
+	assert.Equal(t, 2, deathData[warcraftlogsBuilds.KeyLevelBracketHigh].Runs)
+	assert.Equal(t, 3, deathData[warcraftlogsBuilds.KeyLevelBracketHigh].TotalDeaths)
+
+	// 3. Convert the aggregated data to statistics
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -7)
+	stats := activity.ConvertToDeathStatistics(deathData, 12660, periodStart, periodEnd)
+	require.NotEmpty(t, stats)
+
+	// 4. Verify the top killing ability of the high keys
+	var gossamer *warcraftlogsBuilds.DeathStatistic
+	var unknown *warcraftlogsBuilds.DeathStatistic
+	for _, stat := range stats {
+		assert.Equal(t, uint(12660), stat.EncounterID)
+		if stat.KeyLevelBracket != warcraftlogsBuilds.KeyLevelBracketHigh ||
+			stat.StatisticType != warcraftlogsBuilds.DeathStatisticTypeAbility {
+			continue
+		}
+		switch stat.AbilityName {
+		case "Gossamer Onslaught":
+			gossamer = stat
+		case "Unknown":
+			unknown = stat
+		}
+	}
+
+	require.NotNil(t, gossamer, "Gossamer Onslaught should be a killing ability")
+	assert.Equal(t, 438960, gossamer.AbilityID)
+	assert.Equal(t, 2, gossamer.DeathCount)
+	assert.Equal(t, 1.0, gossamer.DeathsPerRun)
+	assert.InDelta(t, 66.67, gossamer.Percentage, 0.01)
+	assert.Equal(t, 18.0, gossamer.AvgKeystoneLevel)
+
+	// Deaths without ability information are kept as unknown
+	require.NotNil(t, unknown, "Deaths without ability should be grouped as Unknown")
+	assert.Equal(t, 0, unknown.AbilityID)
+	assert.Equal(t, 1, unknown.DeathCount)
+
+	// 5. Verify the specs and run timing of the "all" bracket
+	specDeaths := make(map[string]int)
+	timingDeaths := make(map[int]int)
+	for _, stat := range stats {
+		if stat.KeyLevelBracket != warcraftlogsBuilds.KeyLevelBracketAll {
+			continue
+		}
+		switch stat.StatisticType {
+		case warcraftlogsBuilds.DeathStatisticTypeSpec:
+			specDeaths[stat.Class+"-"+stat.Spec] = stat.DeathCount
+		case warcraftlogsBuilds.DeathStatisticTypeTiming:
+			assert.Equal(t, stat.TimeBucketStart+20, stat.TimeBucketEnd)
+			timingDeaths[stat.TimeBucketStart] = stat.DeathCount
+		}
+	}
+
+	assert.Equal(t, 2, specDeaths["Shaman-Enhancement"])
+	assert.Equal(t, 1, specDeaths["Priest-Discipline"])
+	assert.Equal(t, 1, specDeaths["Paladin-Protection"])
+
+	// 287682/1719094 = 16% and 100000/1000000 = 10% -> 0-20%, 900000/1719094 = 52% -> 40-60%, 1500000/1719094 = 87% -> 80-100%
+	assert.Equal(t, 2, timingDeaths[0])
+	assert.Equal(t, 1, timingDeaths[40])
+	assert.Equal(t, 1, timingDeaths[80])
+}
+
+// TestDeathStatisticsFightStart tests that the deaths are aggregated at their time since the start of the fight
+// The deaths of the reports stored without fight timeline are counted but not timed.
+func TestDeathStatisticsFightStart(t *testing.T) {
+	// The fight starts 40 minutes after the start of the report
+	reports := []*warcraftlogsBuilds.Report{
+		{
+			Code:          "kLmN5678pQrS9012",
+			FightID:       4,
+			EncounterID:   12660,
+			TotalTime:     1000000,
+			KeystoneLevel: 12,
+			Fights:        datatypes.JSON(`{"id": 4, "startTime": 2400000, "endTime": 3400000}`),
+			DeathEvents: datatypes.JSON(`[
+				{"id": 2, "icon": "Mage-Frost", "name": "Frostbyte", "type": "Mage", "deathTime": 2500000,
+				 "ability": {"guid": 434722, "name": "Subjugate", "abilityIcon": "spell_shadow_shadowwordpain.jpg"}},
+				{"id": 5, "icon": "Druid-Restoration", "name": "Leafy", "type": "Druid", "deathTime": 3300000,
+				 "ability": {"guid": 434722, "name": "Subjugate", "abilityIcon": "spell_shadow_shadowwordpain.jpg"}}
+			]`),
+		},
+		{
+			Code:          "tUvW3456xYzA7890",
+			FightID:       9,
+			EncounterID:   12660,
+			TotalTime:     1000000,
+			KeystoneLevel: 12,
+			DeathEvents: datatypes.JSON(`[
+				{"id": 2, "icon": "Mage-Frost", "name": "Frostbyte", "type": "Mage", "deathTime": 5200000,
+				 "ability": {"guid": 434722, "name": "Subjugate", "abilityIcon": "spell_shadow_shadowwordpain.jpg"}}
+			]`),
+		},
+	}
+
+	activity := &activities.DeathStatisticsActivity{}
+	deathData := make(map[string]*activities.DeathAggregation)
+	require.NoError(t, activity.ProcessDeathsBatch(reports, deathData))
+
+	periodEnd := time.Now()
+	stats := activity.ConvertToDeathStatistics(deathData, 12660, periodEnd.AddDate(0, 0, -7), periodEnd)
+
+	timingDeaths := make(map[int]int)
+	for _, stat := range stats {
+		if stat.KeyLevelBracket != warcraftlogsBuilds.KeyLevelBracketAll {
+			continue
+		}
+		switch stat.StatisticType {
+		case warcraftlogsBuilds.DeathStatisticTypeTiming:
+			timingDeaths[stat.TimeBucketStart] = stat.DeathCount
+		case warcraftlogsBuilds.DeathStatisticTypeAbility:
+			// The untimed death is counted, the average covers (100000 + 900000) / 2 since the fight start
+			assert.Equal(t, 3, stat.DeathCount)
+			assert.Equal(t, 500000.0, stat.AvgDeathTime)
+		}
+	}
+
+	// 100000/1000000 = 10% -> 0-20%, 900000/1000000 = 90% -> 80-100%, the untimed death has no moment
+	assert.Equal(t, map[int]int{0: 1, 80: 1}, timingDeaths)
+}
@@ -0,0 +1,88 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	groupCompositionRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+)
+
+// BuildGroupComposition extracts the group composition of a report
+// Specs are formatted as "Class - Spec" with the same display names as the Raider.IO analytics
+// so both sources can be compared. The run is successful if it was completed within the dungeon timer.
+// Returns nil if the composition of the report is empty.
+func BuildGroupComposition(
+	report *warcraftlogsBuilds.Report,
+	dungeon groupCompositionRepository.DungeonTimer,
+) (*warcraftlogsBuilds.GroupComposition, error) {
+	if len(report.Composition) == 0 {
+		return nil, nil
+	}
+
+	var players []CompositionPlayer
+	if err := json.Unmarshal(report.Composition, &players); err != nil {
+		return nil, fmt.Errorf("error parsing composition for report %s-%d: %w", report.Code, report.FightID, err)
+	}
+
+	composition := &warcraftlogsBuilds.GroupComposition{
+		ReportCode:    report.Code,
+		FightID:       report.FightID,
+		TankSpecs:     []string{},
+		HealerSpecs:   []string{},
+		DpsSpecs:      []string{},
+		Success:       report.KeystoneTime > 0 && dungeon.TimerMS > 0 && report.KeystoneTime <= dungeon.TimerMS,
+		DungeonID:     dungeon.DungeonID,
+		EncounterID:   report.EncounterID,
+		KeystoneLevel: report.KeystoneLevel,
+	}
+
+	for _, player := range players {
+		if len(player.Specs) == 0 {
+			continue
+		}
+
+		spec := formatCompositionSpec(player.Type, player.Specs[0].Spec)
+		switch player.Specs[0].Role {
+		case "tank":
+			composition.TankSpecs = append(composition.TankSpecs, spec)
+		case "healer":
+			composition.HealerSpecs = append(composition.HealerSpecs, spec)
+		case "dps":
+			composition.DpsSpecs = append(composition.DpsSpecs, spec)
+		}
+	}
+
+	if len(composition.TankSpecs)+len(composition.HealerSpecs)+len(composition.DpsSpecs) == 0 {
+		return nil, nil
+	}
+
+	// Sort by class then spec so the same composition always has the same order
+	sort.Strings(composition.TankSpecs)
+	sort.Strings(composition.HealerSpecs)
+	sort.Strings(composition.DpsSpecs)
+
+	return composition, nil
+}
+
+// formatCompositionSpec formats a class and a spec as "Class - Spec"
+// Example: "DeathKnight", "Blood" -> "Death Knight - Blood"
+func formatCompositionSpec(class, spec string) string {
+	return fmt.Sprintf("%s - %s", splitCamelCase(class), splitCamelCase(spec))
+}
+
+// splitCamelCase inserts a space before each inner upper case letter
+// Example: "BeastMastery" -> "Beast Mastery"
+func splitCamelCase(value string) string {
+	var builder strings.Builder
+	for i, r := range value {
+		if i > 0 && unicode.IsUpper(r) && value[i-1] != ' ' {
+			builder.WriteRune(' ')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,58 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	groupCompositionRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestBuildGroupComposition tests the extraction of the group composition from a report
+func TestBuildGroupComposition(t *testing.T) {
+	// 1. Create a timed report with a full composition
+	report := &warcraftlogsBuilds.Report{
+		Code:          "g9Lhy8JmkV1xQ3Gj",
+		FightID:       26,
+		EncounterID:   12660,
+		KeystoneLevel: 18,
+		KeystoneTime:  1719094,
+		Composition: datatypes.JSON(`[
+			{"id": 3, "guid": 198885302, "name": "Zorthar", "type": "Shaman", "specs": [{"role": "dps", "spec": "Enhancement"}]},
+			{"id": 7, "guid": 223836762, "name": "Gregxo", "type": "Priest", "specs": [{"role": "healer", "spec": "Discipline"}]},
+			{"id": 4, "guid": 258456306, "name": "Yodafotm", "type": "DeathKnight", "specs": [{"role": "tank", "spec": "Blood"}]},
+			{"id": 5, "guid": 258456307, "name": "Arrows", "type": "Hunter", "specs": [{"role": "dps", "spec": "BeastMastery"}]},
+			{"id": 6, "guid": 258456308, "name": "Sneaky", "type": "Rogue", "specs": [{"role": "dps", "spec": "Assassination"}]}
+		]`),
+	}
+	dungeon := groupCompositionRepository.DungeonTimer{DungeonID: 2, TimerMS: 1800000}
+
+	// 2. Build the composition
+	composition, err := activities.BuildGroupComposition(report, dungeon)
+	require.NoError(t, err)
+	require.NotNil(t, composition)
+
+	// 3. Verify the specs use the display names and the dps are sorted
+	assert.Equal(t, []string{"Death Knight - Blood"}, []string(composition.TankSpecs))
+	assert.Equal(t, []string{"Priest - Discipline"}, []string(composition.HealerSpecs))
+	assert.Equal(t, []string{"Hunter - Beast Mastery", "Rogue - Assassination", "Shaman - Enhancement"}, []string(composition.DpsSpecs))
+	assert.Equal(t, uint(2), composition.DungeonID)
+	assert.Equal(t, uint(12660), composition.EncounterID)
+	assert.Equal(t, 18, composition.KeystoneLevel)
+	assert.True(t, composition.Success)
+
+	// 4. A run over the timer is not successful
+	report.KeystoneTime = 1900000
+	composition, err = activities.BuildGroupComposition(report, dungeon)
+	require.NoError(t, err)
+	assert.False(t, composition.Success)
+
+	// 5. Reports without composition are skipped
+	composition, err = activities.BuildGroupComposition(&warcraftlogsBuilds.Report{Code: "empty"}, dungeon)
+	require.NoError(t, err)
+	assert.Nil(t, composition)
+}
@@ -0,0 +1,239 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+const (
+	// topKeysPercentile is the keystone level percentile from which a build belongs to the top keys
+	topKeysPercentile = 75.0
+	// minLiftSampleSize is the minimum number of builds needed to compute the lift statistics
+	minLiftSampleSize = 20
+)
+
+// ProcessItemLiftStatistics compares the item, enchant and gem choices of the top keys with the whole population
+// of a class, spec and encounter_id. Unlike ProcessItemStatistics, every stored build is used.
+// It returns the number of lift statistics stored.
+func (a *BuildsStatisticsActivity) ProcessItemLiftStatistics(
+	ctx context.Context,
+	class, spec string,
+	encounterID uint,
+	batchSize int,
+) (int, error) {
+	logger := activity.GetLogger(ctx)
+
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	count, err := a.countBuilds(ctx, class, spec, encounterID)
+	if err != nil {
+		return 0, err
+	}
+
+	builds := make([]*warcraftlogsBuilds.PlayerBuild, 0, count)
+	for offset := 0; offset < int(count); offset += batchSize {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":    "loading_builds_for_lift",
+			"class":     class,
+			"spec":      spec,
+			"encounter": encounterID,
+			"progress":  fmt.Sprintf("%d/%d", len(builds), count),
+		})
+
+		batch, err := a.getPlayerBuildsBatch(ctx, class, spec, encounterID, batchSize, offset)
+		if err != nil {
+			return 0, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		builds = append(builds, batch...)
+	}
+
+	liftStats, err := CalculateItemLiftStatistics(builds)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := a.itemLiftStatisticsRepository.DeleteItemLiftStatistics(ctx, class, spec, encounterID); err != nil {
+		return 0, err
+	}
+
+	if err := a.itemLiftStatisticsRepository.StoreManyItemLiftStatistics(ctx, liftStats); err != nil {
+		return 0, err
+	}
+
+	logger.Info("Completed item lift analysis",
+		"class", class,
+		"spec", spec,
+		"encounter", encounterID,
+		"builds", len(builds),
+		"choices", len(liftStats))
+
+	return len(liftStats), nil
+}
+
+// liftChoiceKey identifies an item, enchant or gem choice in a slot
+type liftChoiceKey struct {
+	ChoiceType string
+	ItemSlot   int
+	ChoiceID   int
+}
+
+// CalculateItemLiftStatistics computes the lift statistics of the item, enchant and gem choices of builds
+// sharing the same class, spec and encounter. Builds in the top quarter of keystone levels are the top keys.
+// No statistics are returned below the minimum sample size.
+func CalculateItemLiftStatistics(builds []*warcraftlogsBuilds.PlayerBuild) ([]*warcraftlogsBuilds.ItemLiftStatistic, error) {
+	if len(builds) < minLiftSampleSize {
+		return nil, nil
+	}
+
+	levels := make([]int, len(builds))
+	for i, build := range builds {
+		levels[i] = build.KeystoneLevel
+	}
+	topLevel := TopKeystoneLevel(levels, topKeysPercentile)
+
+	stats := make(map[liftChoiceKey]*warcraftlogsBuilds.ItemLiftStatistic)
+	topSampleSize := 0
+
+	for _, build := range builds {
+		var gearItems []GearItem
+		if err := json.Unmarshal([]byte(build.Gear), &gearItems); err != nil {
+			return nil, fmt.Errorf("error parsing gear JSON for build %d: %w", build.ID, err)
+		}
+
+		isTop := build.KeystoneLevel >= topLevel
+		if isTop {
+			topSampleSize++
+		}
+
+		// A build counts once per choice, even with the same gem socketed twice
+		seen := make(map[liftChoiceKey]bool)
+		track := func(key liftChoiceKey, name, icon string) {
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+
+			stat, exists := stats[key]
+			if !exists {
+				stat = &warcraftlogsBuilds.ItemLiftStatistic{
+					Class:       build.Class,
+					Spec:        build.Spec,
+					EncounterID: build.EncounterID,
+					ChoiceType:  key.ChoiceType,
+					ItemSlot:    key.ItemSlot,
+					ChoiceID:    key.ChoiceID,
+					ChoiceName:  name,
+					ChoiceIcon:  icon,
+				}
+				stats[key] = stat
+			}
+
+			stat.PopulationCount++
+			if isTop {
+				stat.TopCount++
+			}
+		}
+
+		for _, item := range gearItems {
+			// Ignore empty slots
+			if item.ID == 0 {
+				continue
+			}
+
+			track(liftChoiceKey{warcraftlogsBuilds.LiftChoiceItem, item.Slot, item.ID}, item.Name, item.Icon)
+
+			if item.PermanentEnchant > 0 {
+				track(liftChoiceKey{warcraftlogsBuilds.LiftChoiceEnchant, item.Slot, item.PermanentEnchant}, item.PermanentEnchantName, "")
+			}
+
+			for _, gem := range item.Gems {
+				if gem.ID > 0 {
+					track(liftChoiceKey{warcraftlogsBuilds.LiftChoiceGem, item.Slot, gem.ID}, "", gem.Icon)
+				}
+			}
+		}
+	}
+
+	populationSize := len(builds)
+	restSampleSize := populationSize - topSampleSize
+
+	result := make([]*warcraftlogsBuilds.ItemLiftStatistic, 0, len(stats))
+	for _, stat := range stats {
+		stat.TopKeystoneLevel = topLevel
+		stat.TopSampleSize = topSampleSize
+		stat.PopulationSampleSize = populationSize
+		stat.TopUsageRate = float64(stat.TopCount) / float64(topSampleSize) * 100
+		stat.PopulationUsageRate = float64(stat.PopulationCount) / float64(populationSize) * 100
+		stat.Lift = stat.TopUsageRate / stat.PopulationUsageRate
+		stat.Confidence = LiftConfidence(stat.TopCount, topSampleSize, stat.PopulationCount-stat.TopCount, restSampleSize)
+		stat.WeightedLift = 1 + (stat.Lift-1)*stat.Confidence
+
+		result = append(result, stat)
+	}
+
+	// Stable order for the storage and the tests
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ChoiceType != result[j].ChoiceType {
+			return result[i].ChoiceType < result[j].ChoiceType
+		}
+		if result[i].ItemSlot != result[j].ItemSlot {
+			return result[i].ItemSlot < result[j].ItemSlot
+		}
+		return result[i].ChoiceID < result[j].ChoiceID
+	})
+
+	return result, nil
+}
+
+// TopKeystoneLevel returns the lowest keystone level of the builds above the given percentile
+// Builds sharing that level are all part of the top keys, so ties can make the top sample larger.
+func TopKeystoneLevel(levels []int, percentile float64) int {
+	if len(levels) == 0 {
+		return 0
+	}
+
+	sorted := make([]int, len(levels))
+	copy(sorted, levels)
+	sort.Ints(sorted)
+
+	index := int(math.Floor(percentile / 100 * float64(len(sorted))))
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// LiftConfidence returns the confidence (0-1) that the usage rate of the top builds differs from the other builds
+// It is the two-sided confidence level of a two-proportion z-test, 0 when one of the samples is empty.
+func LiftConfidence(topCount, topSampleSize, restCount, restSampleSize int) float64 {
+	if topSampleSize == 0 || restSampleSize == 0 {
+		return 0
+	}
+
+	topRate := float64(topCount) / float64(topSampleSize)
+	restRate := float64(restCount) / float64(restSampleSize)
+	pooledRate := float64(topCount+restCount) / float64(topSampleSize+restSampleSize)
+
+	standardError := math.Sqrt(pooledRate * (1 - pooledRate) * (1/float64(topSampleSize) + 1/float64(restSampleSize)))
+	if standardError == 0 {
+		return 0
+	}
+
+	z := (topRate - restRate) / standardError
+	return math.Erf(math.Abs(z) / math.Sqrt2)
+}
@@ -0,0 +1,130 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// liftTestBuild creates a build with a helm and its gem, and optionally a ring enchant
+func liftTestBuild(id uint, keystoneLevel, helmID int, ringEnchant bool) *warcraftlogsBuilds.PlayerBuild {
+	enchant := ""
+	if ringEnchant {
+		enchant = `, "permanentEnchant": 7340, "permanentEnchantName": "+315 Haste"`
+	}
+	gear := fmt.Sprintf(`[{"id": %d, "slot": 0, "name": "Helm %d", "icon": "helm.jpg", "gems": [{"id": 213455, "icon": "gem.jpg"}, {"id": 213455, "icon": "gem.jpg"}]}, {"id": 228411, "slot": 10, "name": "Ring", "icon": "ring.jpg"%s}, {"id": 0, "slot": 16}]`,
+		helmID, helmID, enchant)
+
+	return &warcraftlogsBuilds.PlayerBuild{
+		ID:            id,
+		Class:         "Priest",
+		Spec:          "Discipline",
+		EncounterID:   62286,
+		KeystoneLevel: keystoneLevel,
+		Gear:          datatypes.JSON([]byte(gear)),
+	}
+}
+
+// TestCalculateItemLiftStatistics checks the lift of choices over-represented in the top keys
+func TestCalculateItemLiftStatistics(t *testing.T) {
+	var builds []*warcraftlogsBuilds.PlayerBuild
+
+	// 30 low keys: helm A, no enchant
+	for i := 0; i < 30; i++ {
+		builds = append(builds, liftTestBuild(uint(i+1), 10, 1001, false))
+	}
+	// 10 top keys: helm B with the ring enchant
+	for i := 0; i < 10; i++ {
+		builds = append(builds, liftTestBuild(uint(i+31), 15, 1002, true))
+	}
+
+	stats, err := activities.CalculateItemLiftStatistics(builds)
+	assert.NoError(t, err)
+
+	byKey := make(map[string]*warcraftlogsBuilds.ItemLiftStatistic)
+	for _, stat := range stats {
+		byKey[fmt.Sprintf("%s_%d_%d", stat.ChoiceType, stat.ItemSlot, stat.ChoiceID)] = stat
+	}
+
+	// 2 helms, 1 ring, 1 enchant and 1 gem (counted once per build)
+	assert.Len(t, stats, 5)
+
+	helmB := byKey["item_0_1002"]
+	if assert.NotNil(t, helmB) {
+		assert.Equal(t, 15, helmB.TopKeystoneLevel)
+		assert.Equal(t, 10, helmB.TopCount)
+		assert.Equal(t, 10, helmB.TopSampleSize)
+		assert.Equal(t, 10, helmB.PopulationCount)
+		assert.Equal(t, 40, helmB.PopulationSampleSize)
+		assert.InDelta(t, 100.0, helmB.TopUsageRate, 0.001)
+		assert.InDelta(t, 25.0, helmB.PopulationUsageRate, 0.001)
+		assert.InDelta(t, 4.0, helmB.Lift, 0.001)
+		assert.Greater(t, helmB.Confidence, 0.99)
+		assert.Greater(t, helmB.WeightedLift, 3.9)
+	}
+
+	helmA := byKey["item_0_1001"]
+	if assert.NotNil(t, helmA) {
+		assert.Equal(t, 0, helmA.TopCount)
+		assert.InDelta(t, 0.0, helmA.Lift, 0.001)
+		assert.Less(t, helmA.WeightedLift, 0.1)
+	}
+
+	enchant := byKey["enchant_10_7340"]
+	if assert.NotNil(t, enchant) {
+		assert.Equal(t, "+315 Haste", enchant.ChoiceName)
+		assert.InDelta(t, 4.0, enchant.Lift, 0.001)
+	}
+
+	// Everyone uses the ring and the gem: no lift and no confidence
+	for _, key := range []string{"item_10_228411", "gem_0_213455"} {
+		stat := byKey[key]
+		if assert.NotNil(t, stat, key) {
+			assert.Equal(t, 40, stat.PopulationCount, key)
+			assert.InDelta(t, 1.0, stat.Lift, 0.001, key)
+			assert.InDelta(t, 0.0, stat.Confidence, 0.001, key)
+			assert.InDelta(t, 1.0, stat.WeightedLift, 0.001, key)
+		}
+	}
+}
+
+// TestCalculateItemLiftStatistics_SmallSample checks that small samples are ignored
+func TestCalculateItemLiftStatistics_SmallSample(t *testing.T) {
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		liftTestBuild(1, 10, 1001, false),
+		liftTestBuild(2, 15, 1002, true),
+	}
+
+	stats, err := activities.CalculateItemLiftStatistics(builds)
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
+
+// TestTopKeystoneLevel checks the lowest keystone level above the percentile
+func TestTopKeystoneLevel(t *testing.T) {
+	assert.Equal(t, 0, activities.TopKeystoneLevel(nil, 75))
+	assert.Equal(t, 12, activities.TopKeystoneLevel([]int{12}, 75))
+	assert.Equal(t, 16, activities.TopKeystoneLevel([]int{16, 10, 12, 15}, 75))
+	assert.Equal(t, 10, activities.TopKeystoneLevel([]int{2, 4, 6, 8, 10, 12, 14, 16}, 50))
+	assert.Equal(t, 10, activities.TopKeystoneLevel([]int{10, 10, 10, 10}, 75))
+}
+
+// TestLiftConfidence checks the two-proportion z-test confidence
+func TestLiftConfidence(t *testing.T) {
+	// Empty samples
+	assert.Equal(t, 0.0, activities.LiftConfidence(5, 10, 0, 0))
+
+	// Same rates
+	assert.InDelta(t, 0.0, activities.LiftConfidence(5, 10, 50, 100), 0.001)
+
+	// 60% vs 40% with 100 builds each: z ≈ 2.83
+	assert.InDelta(t, 0.9953, activities.LiftConfidence(60, 100, 40, 100), 0.001)
+
+	// Symmetric
+	assert.InDelta(t, activities.LiftConfidence(60, 100, 40, 100), activities.LiftConfidence(40, 100, 60, 100), 0.0001)
+}
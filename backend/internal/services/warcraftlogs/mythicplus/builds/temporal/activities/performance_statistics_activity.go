@@ -0,0 +1,372 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	performanceStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	reportsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// PerformanceStatisticsActivity manages all operations related to performance statistics.
+type PerformanceStatisticsActivity struct {
+	reportsRepository               *reportsRepository.ReportRepository
+	performanceStatisticsRepository *performanceStatisticsRepository.PerformanceStatisticsRepository
+}
+
+// NewPerformanceStatisticsActivity creates a new PerformanceStatisticsActivity.
+func NewPerformanceStatisticsActivity(
+	reportsRepository *reportsRepository.ReportRepository,
+	performanceStatisticsRepository *performanceStatisticsRepository.PerformanceStatisticsRepository,
+) *PerformanceStatisticsActivity {
+	return &PerformanceStatisticsActivity{
+		reportsRepository:               reportsRepository,
+		performanceStatisticsRepository: performanceStatisticsRepository,
+	}
+}
+
+// ThroughputEntry represents the damage or healing done by a player in the report JSON
+type ThroughputEntry struct {
+	ID    int     `json:"id"`
+	GUID  int64   `json:"guid"`
+	Name  string  `json:"name"`
+	Type  string  `json:"type"` // Class
+	Icon  string  `json:"icon"` // "Class-Spec"
+	Total float64 `json:"total"`
+}
+
+// PerformanceAggregation aggregates the throughput values of a spec for a key level bracket
+type PerformanceAggregation struct {
+	Class           string
+	Spec            string
+	Role            string
+	KeyLevelBracket string
+
+	DPSValues          []float64
+	HPSValues          []float64
+	TotalKeystoneLevel float64 // Sum of the keystone levels of the DPS values
+	Players            int     // Number of players with a DPS value
+}
+
+// ProcessPerformanceStatistics computes the DPS and HPS distributions of a dungeon from the reports stored during the lookback window
+func (a *PerformanceStatisticsActivity) ProcessPerformanceStatistics(
+	ctx context.Context,
+	encounterID uint,
+	lookbackDays int,
+	batchSize int,
+) (*workflowsModels.PerformanceAnalysisWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.PerformanceAnalysisWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if lookbackDays <= 0 {
+		lookbackDays = 7
+	}
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -lookbackDays)
+
+	// 1. Get the total number of reports to process
+	count, err := a.reportsRepository.CountReportsForEncounterSince(ctx, encounterID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Delete existing statistics, they are fully recomputed for the window
+	if err := a.performanceStatisticsRepository.DeletePerformanceStatistics(ctx, encounterID); err != nil {
+		return nil, fmt.Errorf("failed to delete existing performance statistics: %w", err)
+	}
+
+	if count == 0 {
+		logger.Info("No reports found to analyze for performance",
+			"encounterID", encounterID,
+			"lookbackDays", lookbackDays)
+		result.CompletedAt = time.Now()
+		return result, nil
+	}
+
+	// 3. Process the reports by batches
+	performanceData := make(map[string]*PerformanceAggregation)
+	offset := 0
+	totalProcessed := 0
+
+	for offset < int(count) {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":     "processing_performance",
+			"encounter":  encounterID,
+			"progress":   fmt.Sprintf("%d/%d", totalProcessed, count),
+			"percentage": float64(totalProcessed) / float64(count) * 100,
+		})
+
+		reports, err := a.reportsRepository.GetReportsForEncounterSince(ctx, encounterID, periodStart, batchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reports) == 0 {
+			break
+		}
+
+		runs, err := a.ProcessPerformanceBatch(reports, performanceData)
+		if err != nil {
+			return nil, err
+		}
+
+		result.RunsAnalyzed += int32(runs)
+		totalProcessed += len(reports)
+		offset += batchSize
+	}
+
+	// 4. Convert the aggregated data and persist it
+	performanceStats := a.ConvertToPerformanceStatistics(performanceData, encounterID, periodStart, periodEnd)
+	if err := a.performanceStatisticsRepository.StoreManyPerformanceStatistics(ctx, performanceStats); err != nil {
+		return nil, fmt.Errorf("failed to store performance statistics: %w", err)
+	}
+
+	for _, agg := range performanceData {
+		if agg.KeyLevelBracket == warcraftlogsBuilds.KeyLevelBracketAll {
+			result.PlayersAnalyzed += int32(agg.Players)
+		}
+	}
+	result.StatisticsStored = int32(len(performanceStats))
+	result.DungeonsProcessed = 1
+	result.CompletedAt = time.Now()
+
+	logger.Info("Completed performance analysis",
+		"encounter", encounterID,
+		"reportsProcessed", totalProcessed,
+		"playersAnalyzed", result.PlayersAnalyzed,
+		"statisticsStored", len(performanceStats),
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
+
+// ProcessPerformanceBatch process a batch of reports to extract the DPS and HPS of each player
+// Each value is counted in its key level bracket and in the "all" bracket.
+// DPS is kept for every spec, HPS only for the healers. Returns the number of runs analyzed.
+// The roles come from the composition of the run, or from the spec of the players missing from it.
+func (a *PerformanceStatisticsActivity) ProcessPerformanceBatch(
+	reports []*warcraftlogsBuilds.Report,
+	performanceData map[string]*PerformanceAggregation,
+) (int, error) {
+	runs := 0
+
+	for _, report := range reports {
+		// The run duration is required to compute the throughput
+		if report.TotalTime <= 0 {
+			continue
+		}
+
+		var damageDone, healingDone []ThroughputEntry
+		if len(report.DamageDone) > 0 {
+			if err := json.Unmarshal(report.DamageDone, &damageDone); err != nil {
+				return runs, fmt.Errorf("error parsing damage done for report %s-%d: %w", report.Code, report.FightID, err)
+			}
+		}
+		if len(report.HealingDone) > 0 {
+			if err := json.Unmarshal(report.HealingDone, &healingDone); err != nil {
+				return runs, fmt.Errorf("error parsing healing done for report %s-%d: %w", report.Code, report.FightID, err)
+			}
+		}
+		if len(damageDone) == 0 && len(healingDone) == 0 {
+			continue
+		}
+
+		// The roles come from the composition of the run
+		var composition []CompositionPlayer
+		if len(report.Composition) > 0 {
+			if err := json.Unmarshal(report.Composition, &composition); err != nil {
+				return runs, fmt.Errorf("error parsing composition for report %s-%d: %w", report.Code, report.FightID, err)
+			}
+		}
+		roles := make(map[int]string, len(composition))
+		for _, player := range composition {
+			if len(player.Specs) > 0 {
+				roles[player.ID] = player.Specs[0].Role
+			}
+		}
+
+		runs++
+		durationSeconds := float64(report.TotalTime) / 1000
+		brackets := []string{warcraftlogsBuilds.KeyLevelBracketAll, warcraftlogsBuilds.GetKeyLevelBracket(report.KeystoneLevel)}
+
+		for _, entry := range damageDone {
+			class, spec := parseClassSpecIcon(entry.Icon, entry.Type)
+			if spec == "" {
+				continue
+			}
+			role := playerRole(roles, entry.ID, class, spec)
+			for _, bracket := range brackets {
+				agg := getPerformanceAggregation(performanceData, bracket, class, spec, role)
+				agg.DPSValues = append(agg.DPSValues, entry.Total/durationSeconds)
+				agg.TotalKeystoneLevel += float64(report.KeystoneLevel)
+				agg.Players++
+			}
+		}
+
+		for _, entry := range healingDone {
+			class, spec := parseClassSpecIcon(entry.Icon, entry.Type)
+			if spec == "" {
+				continue
+			}
+			role := playerRole(roles, entry.ID, class, spec)
+			if role != "healer" {
+				continue
+			}
+			for _, bracket := range brackets {
+				agg := getPerformanceAggregation(performanceData, bracket, class, spec, role)
+				agg.HPSValues = append(agg.HPSValues, entry.Total/durationSeconds)
+			}
+		}
+	}
+
+	return runs, nil
+}
+
+// playerRole returns the role of a player in the composition of the run
+// Players missing from the composition, in older or partial reports, get the role of their spec.
+func playerRole(roles map[int]string, actorID int, class, spec string) string {
+	if role, ok := roles[actorID]; ok {
+		return role
+	}
+	return specRole(class, spec)
+}
+
+// specRole returns the role of a spec, with the role names of the report composition
+func specRole(class, spec string) string {
+	tanks := map[string][]string{
+		"Warrior":     {"Protection"},
+		"Paladin":     {"Protection"},
+		"DeathKnight": {"Blood"},
+		"DemonHunter": {"Vengeance"},
+		"Druid":       {"Guardian"},
+		"Monk":        {"Brewmaster"},
+	}
+
+	healers := map[string][]string{
+		"Priest":  {"Holy", "Discipline"},
+		"Paladin": {"Holy"},
+		"Druid":   {"Restoration"},
+		"Shaman":  {"Restoration"},
+		"Monk":    {"Mistweaver"},
+		"Evoker":  {"Preservation"},
+	}
+
+	for _, tankSpec := range tanks[class] {
+		if spec == tankSpec {
+			return "tank"
+		}
+	}
+	for _, healSpec := range healers[class] {
+		if spec == healSpec {
+			return "healer"
+		}
+	}
+	return "dps"
+}
+
+// getPerformanceAggregation returns the aggregation of a spec for a bracket, creating it if needed
+func getPerformanceAggregation(performanceData map[string]*PerformanceAggregation, bracket, class, spec, role string) *PerformanceAggregation {
+	key := fmt.Sprintf("%s_%s_%s", bracket, class, spec)
+	agg, exists := performanceData[key]
+	if !exists {
+		agg = &PerformanceAggregation{
+			Class:           class,
+			Spec:            spec,
+			KeyLevelBracket: bracket,
+		}
+		performanceData[key] = agg
+	}
+	if agg.Role == "" {
+		agg.Role = role
+	}
+	return agg
+}
+
+// ConvertToPerformanceStatistics convert the aggregated data to PerformanceStatistic objects
+func (a *PerformanceStatisticsActivity) ConvertToPerformanceStatistics(
+	performanceData map[string]*PerformanceAggregation,
+	encounterID uint,
+	periodStart, periodEnd time.Time,
+) []*warcraftlogsBuilds.PerformanceStatistic {
+	result := make([]*warcraftlogsBuilds.PerformanceStatistic, 0)
+
+	for _, agg := range performanceData {
+		avgKeystoneLevel := 0.0
+		if agg.Players > 0 {
+			avgKeystoneLevel = agg.TotalKeystoneLevel / float64(agg.Players)
+		}
+
+		metrics := map[string][]float64{
+			warcraftlogsBuilds.PerformanceMetricDPS: agg.DPSValues,
+			warcraftlogsBuilds.PerformanceMetricHPS: agg.HPSValues,
+		}
+		for metric, values := range metrics {
+			if len(values) == 0 {
+				continue
+			}
+
+			sorted := make([]float64, len(values))
+			copy(sorted, values)
+			sort.Float64s(sorted)
+
+			total := 0.0
+			for _, value := range sorted {
+				total += value
+			}
+
+			result = append(result, &warcraftlogsBuilds.PerformanceStatistic{
+				Class:            agg.Class,
+				Spec:             agg.Spec,
+				Role:             agg.Role,
+				EncounterID:      encounterID,
+				KeyLevelBracket:  agg.KeyLevelBracket,
+				Metric:           metric,
+				SampleSize:       len(sorted),
+				AvgValue:         total / float64(len(sorted)),
+				MinValue:         sorted[0],
+				MaxValue:         sorted[len(sorted)-1],
+				P25:              percentile(sorted, 25),
+				P50:              percentile(sorted, 50),
+				P75:              percentile(sorted, 75),
+				P95:              percentile(sorted, 95),
+				AvgKeystoneLevel: avgKeystoneLevel,
+				PeriodStart:      periodStart,
+				PeriodEnd:        periodEnd,
+			})
+		}
+	}
+
+	return result
+}
+
+// percentile returns the p-th percentile of sorted values using linear interpolation between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,122 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestPerformanceStatisticsTransformation tests the transformation of the damage and healing done to PerformanceStatistic
+func TestPerformanceStatisticsTransformation(t *testing.T) {
+	// 1. Create reports of 1000 seconds with a Discipline Priest and an Enhancement Shaman
+	composition := datatypes.JSON(`[
+		{"id": 3, "guid": 198885302, "name": "Zorthar", "type": "Shaman", "specs": [{"role": "dps", "spec": "Enhancement"}]},
+		{"id": 7, "guid": 223836762, "name": "Gregxo", "type": "Priest", "specs": [{"role": "healer", "spec": "Discipline"}]}
+	]`)
+
+	newReport := func(code string, keystoneLevel int, shamanDamage, priestDamage, priestHealing, shamanHealing int) *warcraftlogsBuilds.Report {
+		return &warcraftlogsBuilds.Report{
+			Code:          code,
+			FightID:       1,
+			EncounterID:   12660,
+			TotalTime:     1000000,
+			KeystoneLevel: keystoneLevel,
+			Composition:   composition,
+			DamageDone: datatypes.JSON([]byte(`[
+				{"id": 3, "guid": 198885302, "icon": "Shaman-Enhancement", "name": "Zorthar", "type": "Shaman", "total": ` + strconv.Itoa(shamanDamage) + `},
+				{"id": 7, "guid": 223836762, "icon": "Priest-Discipline", "name": "Gregxo", "type": "Priest", "total": ` + strconv.Itoa(priestDamage) + `}
+			]`)),
+			HealingDone: datatypes.JSON([]byte(`[
+				{"id": 7, "guid": 223836762, "icon": "Priest-Discipline", "name": "Gregxo", "type": "Priest", "total": ` + strconv.Itoa(priestHealing) + `},
+				{"id": 3, "guid": 198885302, "icon": "Shaman-Enhancement", "name": "Zorthar", "type": "Shaman", "total": ` + strconv.Itoa(shamanHealing) + `}
+			]`)),
+		}
+	}
+
+	reports := []*warcraftlogsBuilds.Report{
+		newReport("aaaa", 14, 1000000, 500000, 2000000, 100000),
+		newReport("bbbb", 15, 2000000, 600000, 3000000, 100000),
+		newReport("cccc", 16, 3000000, 700000, 4000000, 100000),
+		newReport("dddd", 17, 4000000, 800000, 5000000, 100000),
+		newReport("eeee", 18, 5000000, 900000, 6000000, 100000),
+		newReport("ffff", 5, 500000, 100000, 1000000, 50000),
+		// The Restoration Druid is missing from the composition, its role comes from its spec
+		{
+			Code: "hhhh", FightID: 1, EncounterID: 12660, KeystoneLevel: 10, TotalTime: 1000000,
+			Composition: composition,
+			DamageDone:  datatypes.JSON(`[{"id": 9, "icon": "Druid-Restoration", "name": "Treehug", "type": "Druid", "total": 200000}]`),
+			HealingDone: datatypes.JSON(`[{"id": 9, "icon": "Druid-Restoration", "name": "Treehug", "type": "Druid", "total": 3000000}]`),
+		},
+		// Runs without duration are ignored
+		{Code: "gggg", FightID: 1, EncounterID: 12660, KeystoneLevel: 10, TotalTime: 0, DamageDone: datatypes.JSON(`[]`)},
+	}
+
+	activity := &activities.PerformanceStatisticsActivity{}
+	performanceData := make(map[string]*activities.PerformanceAggregation)
+
+	// 2. Aggregate the throughput values
+	runs, err := activity.ProcessPerformanceBatch(reports, performanceData)
+	require.NoError(t, err)
+	assert.Equal(t, 7, runs)
+
+	// 3. Convert the aggregated data to statistics
+	periodEnd := time.Now()
+	periodStart := periodEnd.AddDate(0, 0, -7)
+	stats := activity.ConvertToPerformanceStatistics(performanceData, 12660, periodStart, periodEnd)
+	require.NotEmpty(t, stats)
+
+	byKey := make(map[string]*warcraftlogsBuilds.PerformanceStatistic)
+	for _, stat := range stats {
+		assert.Equal(t, uint(12660), stat.EncounterID)
+		byKey[stat.KeyLevelBracket+"_"+stat.Spec+"_"+stat.Metric] = stat
+	}
+
+	// 4. Verify the DPS distribution of the high keys: 1000, 2000, 3000, 4000, 5000
+	shamanDPS := byKey["12+_Enhancement_dps"]
+	require.NotNil(t, shamanDPS)
+	assert.Equal(t, "Shaman", shamanDPS.Class)
+	assert.Equal(t, "dps", shamanDPS.Role)
+	assert.Equal(t, 5, shamanDPS.SampleSize)
+	assert.Equal(t, 3000.0, shamanDPS.AvgValue)
+	assert.Equal(t, 1000.0, shamanDPS.MinValue)
+	assert.Equal(t, 5000.0, shamanDPS.MaxValue)
+	assert.InDelta(t, 2000.0, shamanDPS.P25, 0.01)
+	assert.InDelta(t, 3000.0, shamanDPS.P50, 0.01)
+	assert.InDelta(t, 4000.0, shamanDPS.P75, 0.01)
+	assert.InDelta(t, 4800.0, shamanDPS.P95, 0.01)
+	assert.Equal(t, 16.0, shamanDPS.AvgKeystoneLevel)
+
+	// HPS is only computed for the healers
+	assert.NotContains(t, byKey, "12+_Enhancement_hps")
+
+	priestHPS := byKey["12+_Discipline_hps"]
+	require.NotNil(t, priestHPS)
+	assert.Equal(t, "healer", priestHPS.Role)
+	assert.Equal(t, 5, priestHPS.SampleSize)
+	assert.InDelta(t, 4000.0, priestHPS.P50, 0.01)
+
+	// 5. Verify the "all" bracket includes every run
+	allDPS := byKey["all_Enhancement_dps"]
+	require.NotNil(t, allDPS)
+	assert.Equal(t, 6, allDPS.SampleSize)
+	assert.Equal(t, 500.0, allDPS.MinValue)
+
+	lowDPS := byKey["2-6_Enhancement_dps"]
+	require.NotNil(t, lowDPS)
+	assert.Equal(t, 1, lowDPS.SampleSize)
+	assert.Equal(t, 500.0, lowDPS.P95)
+
+	// 6. Verify the healer missing from the composition has its HPS
+	druidHPS := byKey["all_Restoration_hps"]
+	require.NotNil(t, druidHPS)
+	assert.Equal(t, "healer", druidHPS.Role)
+	assert.Equal(t, 1, druidHPS.SampleSize)
+	assert.Equal(t, 3000.0, druidHPS.AvgValue)
+}
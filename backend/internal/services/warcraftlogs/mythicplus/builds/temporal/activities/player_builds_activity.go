@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,24 +21,40 @@ import (
 
 // PlayerDetails represents detailed player information from WarcraftLogs
 type PlayerDetails struct {
-	ID            int             `json:"id"`
-	Name          string          `json:"name"`
-	Type          string          `json:"type"`
-	Specs         []string        `json:"specs"`
-	MaxItemLevel  float64         `json:"maxItemLevel"`
-	CombatantInfo json.RawMessage `json:"combatantInfo"`
+	ID             int             `json:"id"`
+	Name           string          `json:"name"`
+	GUID           int64           `json:"guid"`
+	Server         string          `json:"server"`
+	Type           string          `json:"type"`
+	Specs          []string        `json:"specs"`
+	MaxItemLevel   float64         `json:"maxItemLevel"`
+	PotionUse      *int            `json:"potionUse"` // nil when missing from the report
+	HealthstoneUse *int            `json:"healthstoneUse"`
+	CombatantInfo  json.RawMessage `json:"combatantInfo"`
 }
 
 // PlayerBuildsActivity manages all operations related to player builds
 type PlayerBuildsActivity struct {
-	repository        *playerBuildsRepository.PlayerBuildsRepository
-	reportsRepository *reportsRepository.ReportRepository
+	repository           *playerBuildsRepository.PlayerBuildsRepository
+	reportsRepository    *reportsRepository.ReportRepository
+	validationRepository *playerBuildsRepository.BuildValidationRepository
+	validationRules      warcraftlogsBuilds.BuildValidationRules
+	identityRepository   *playerBuildsRepository.PlayerIdentityRepository
+	identityMutex        sync.Mutex // Serializes the identity resolution of the workers
 }
 
-func NewPlayerBuildsActivity(repository *playerBuildsRepository.PlayerBuildsRepository, reportsRepository *reportsRepository.ReportRepository) *PlayerBuildsActivity {
+func NewPlayerBuildsActivity(
+	repository *playerBuildsRepository.PlayerBuildsRepository,
+	reportsRepository *reportsRepository.ReportRepository,
+	validationRepository *playerBuildsRepository.BuildValidationRepository,
+	identityRepository *playerBuildsRepository.PlayerIdentityRepository,
+) *PlayerBuildsActivity {
 	return &PlayerBuildsActivity{
-		repository:        repository,
-		reportsRepository: reportsRepository,
+		repository:           repository,
+		reportsRepository:    reportsRepository,
+		validationRepository: validationRepository,
+		validationRules:      warcraftlogsBuilds.DefaultBuildValidationRules(),
+		identityRepository:   identityRepository,
 	}
 }
 
@@ -79,6 +96,7 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 	}, len(reports))
 
 	var wg sync.WaitGroup
+	var rejectedBuildsCount int32                    // Builds quarantined by the validation stage, updated by the workers
 	processingCtx, cancel := context.WithCancel(ctx) // Context to cancel workers
 	defer cancel()
 
@@ -107,6 +125,17 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 						continue
 					}
 
+					// Validation stage: the rejected builds are quarantined instead of being stored
+					builds, quarantined := ValidatePlayerBuilds(builds, a.validationRules, batchID)
+					if len(quarantined) > 0 {
+						logger.Info("Builds rejected by the validation", "reportCode", report.Code, "rejectedCount", len(quarantined))
+						if errQuarantine := a.validationRepository.StoreQuarantinedBuilds(processingCtx, quarantined); errQuarantine != nil {
+							// The rejected builds are still kept out of the statistics
+							logger.Error("Failed to store quarantined builds", "reportCode", report.Code, "error", errQuarantine)
+						}
+						atomic.AddInt32(&rejectedBuildsCount, int32(len(quarantined)))
+					}
+
 					if len(builds) == 0 {
 						logger.Info("No builds extracted (but no error)", "reportCode", report.Code)
 						resultChan <- struct {
@@ -117,6 +146,9 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 						continue
 					}
 
+					// Identity resolution: the builds are linked to the canonical identity of their player
+					a.resolvePlayerIdentities(processingCtx, report, builds)
+
 					errStore := a.repository.StoreManyPlayerBuilds(processingCtx, builds)
 					if errStore != nil {
 						logger.Error("Failed to store builds", "reportCode", report.Code, "buildsCount", len(builds), "error", errStore)
@@ -128,6 +160,10 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 						continue
 					}
 
+					if errRelease := a.validationRepository.ReleaseQuarantinedBuilds(processingCtx, builds); errRelease != nil {
+						logger.Error("Failed to release quarantined builds", "reportCode", report.Code, "error", errRelease)
+					}
+
 					activity.RecordHeartbeat(processingCtx, map[string]interface{}{"workerID": workerID, "reportCode": report.Code, "status": "completed", "buildsStored": len(builds)})
 					logger.Info("Successfully stored builds for report", "reportCode", report.Code, "buildsProcessed", len(builds))
 
@@ -213,9 +249,11 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 		})
 	}
 	result.BuildsByClassSpec = buildsByClassSpec
+	result.RejectedBuildsCount = atomic.LoadInt32(&rejectedBuildsCount)
 
 	logger.Info("Completed collecting results from workers",
 		"totalBuilds", result.ProcessedBuildsCount,
+		"rejectedBuilds", result.RejectedBuildsCount,
 		"successReports", result.SuccessCount,
 		"failedReports", result.FailureCount)
 
@@ -248,11 +286,22 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 		logger.Info("No reports were successfully processed to be marked.")
 	}
 
+	// Tag the builds whose report was tagged after their extraction
+	if result.ProcessedBuildsCount > 0 {
+		if err := a.repository.TagPlayerBuildsWithPatch(ctx); err != nil {
+			logger.Error("Failed to tag player builds with patch", "error", err)
+		}
+		if _, err := a.identityRepository.LinkClassRankings(ctx); err != nil {
+			logger.Error("Failed to link class rankings to player identities", "error", err)
+		}
+	}
+
 	// Finalization and Return
 	result.ProcessedAt = time.Now() // Final timestamp of the activity
 	logger.Info("Finished activity ProcessAllBuilds",
 		"duration", result.ProcessedAt.Sub(activityStart),
 		"buildsStored", result.ProcessedBuildsCount,
+		"buildsRejected", result.RejectedBuildsCount,
 		"successReports", result.SuccessCount,
 		"failedReports", result.FailureCount)
 
@@ -263,6 +312,31 @@ func (a *PlayerBuildsActivity) ProcessAllBuilds(ctx context.Context, reports []*
 	return result, nil // The activity has finished its cycle, returns nil as main error
 }
 
+// resolvePlayerIdentities resolves the players of the builds to their identity and sets it on the builds
+// A failure only leaves the builds without identity, they are linked again on their next extraction.
+func (a *PlayerBuildsActivity) resolvePlayerIdentities(ctx context.Context, report *warcraftlogsBuilds.Report, builds []*warcraftlogsBuilds.PlayerBuild) {
+	if a.identityRepository == nil || len(builds) == 0 {
+		return
+	}
+
+	observations := BuildPlayerObservations(report, builds)
+
+	a.identityMutex.Lock()
+	err := a.identityRepository.ResolvePlayerIdentities(ctx, observations)
+	a.identityMutex.Unlock()
+	if err != nil {
+		log.Printf("[ERROR] Failed to resolve player identities of report %s: %v", report.Code, err)
+		return
+	}
+
+	for i, observation := range observations {
+		if observation.IdentityID > 0 {
+			identityID := observation.IdentityID
+			builds[i].PlayerIdentityID = &identityID
+		}
+	}
+}
+
 // extractPlayerBuilds extracts all player builds from a report
 func (a *PlayerBuildsActivity) extractPlayerBuilds(report *warcraftlogsBuilds.Report) ([]*warcraftlogsBuilds.PlayerBuild, error) {
 	var builds []*warcraftlogsBuilds.PlayerBuild
@@ -370,14 +444,20 @@ func (a *PlayerBuildsActivity) createPlayerBuild(report *warcraftlogsBuilds.Repo
 		ReportCode:      report.Code,
 		FightID:         report.FightID,
 		ActorID:         player.ID,
+		ServerName:      player.Server,
+		CharacterGUID:   player.GUID,
 		ItemLevel:       player.MaxItemLevel,
 		TalentImport:    talentCode,
 		TalentTree:      datatypes.JSON(combatInfo.TalentTree),
 		Gear:            datatypes.JSON(combatInfo.Gear),
 		Stats:           datatypes.JSON(combatInfo.Stats),
+		PotionUse:       player.PotionUse,
+		HealthstoneUse:  player.HealthstoneUse,
 		EncounterID:     report.EncounterID,
+		Difficulty:      report.Difficulty,
 		KeystoneLevel:   report.KeystoneLevel,
 		Affixes:         report.Affixes,
+		Patch:           report.Patch,
 		EquipmentStatus: "pending",
 		TalentStatus:    "pending",
 		StatStatus:      "pending",
@@ -15,7 +15,10 @@ func TestCreatePlayerBuild(t *testing.T) {
 		reportData   string
 		playerData   string
 		expectedSpec string
-		shouldError  bool
+		// Consumables used during the fight, nil when missing from the report
+		expectedPotionUse      *int
+		expectedHealthstoneUse *int
+		shouldError            bool
 	}{
 		{
 			name: "Discipline Priest with active talents",
@@ -34,14 +37,18 @@ func TestCreatePlayerBuild(t *testing.T) {
                 "type": "Priest",
                 "specs": ["Discipline", "Holy", "Shadow"],
                 "maxItemLevel": 420,
+                "potionUse": 3,
+                "healthstoneUse": 1,
                 "combatantInfo": {
                     "stats": {},
                     "gear": {},
                     "talentTree": {}
                 }
             }`,
-			expectedSpec: "Discipline",
-			shouldError:  false,
+			expectedSpec:           "Discipline",
+			expectedPotionUse:      intPtr(3),
+			expectedHealthstoneUse: intPtr(1),
+			shouldError:            false,
 		},
 		{
 			name: "Holy Priest with active talents",
@@ -86,14 +93,18 @@ func TestCreatePlayerBuild(t *testing.T) {
                 "type": "Priest",
                 "specs": ["Shadow", "Discipline", "Holy"],
                 "maxItemLevel": 420,
+                "potionUse": 0,
+                "healthstoneUse": 0,
                 "combatantInfo": {
                     "stats": {},
                     "gear": {},
                     "talentTree": {}
                 }
             }`,
-			expectedSpec: "Shadow",
-			shouldError:  false,
+			expectedSpec:           "Shadow",
+			expectedPotionUse:      intPtr(0),
+			expectedHealthstoneUse: intPtr(0),
+			shouldError:            false,
 		},
 	}
 
@@ -127,7 +138,13 @@ func TestCreatePlayerBuild(t *testing.T) {
 				assert.Equal(t, playerDetails.Type, build.Class)
 				assert.Equal(t, playerDetails.Name, build.PlayerName)
 				assert.Equal(t, report.Code, build.ReportCode)
+				assert.Equal(t, tc.expectedPotionUse, build.PotionUse)
+				assert.Equal(t, tc.expectedHealthstoneUse, build.HealthstoneUse)
 			}
 		})
 	}
 }
+
+func intPtr(value int) *int {
+	return &value
+}
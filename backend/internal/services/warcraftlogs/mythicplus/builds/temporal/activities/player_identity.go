@@ -0,0 +1,57 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"encoding/json"
+	"strings"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// BuildPlayerObservations returns the observation of the player of each build, in the order of the builds
+// The Warcraft Logs character ID and the region come from the ranked characters of the report, matched by name and realm.
+// The region and the time of the run are left empty when unknown, they are read from the rankings by the resolution.
+func BuildPlayerObservations(report *warcraftlogsBuilds.Report, builds []*warcraftlogsBuilds.PlayerBuild) []*warcraftlogsBuilds.PlayerObservation {
+	var characters []warcraftlogsBuilds.RankedCharacter
+	if len(report.RankedCharacters) > 0 {
+		_ = json.Unmarshal(report.RankedCharacters, &characters)
+	}
+
+	byNameAndServer := make(map[string]warcraftlogsBuilds.RankedCharacter, len(characters))
+	byName := make(map[string][]warcraftlogsBuilds.RankedCharacter, len(characters))
+	for _, character := range characters {
+		name := strings.ToLower(character.Name)
+		byNameAndServer[name+"-"+warcraftlogsBuilds.NormalizeServerName(character.Server.Name)] = character
+		byName[name] = append(byName[name], character)
+	}
+
+	observations := make([]*warcraftlogsBuilds.PlayerObservation, len(builds))
+	for i, build := range builds {
+		observation := &warcraftlogsBuilds.PlayerObservation{
+			Name:                build.PlayerName,
+			ServerName:          build.ServerName,
+			Class:               build.Class,
+			BlizzardCharacterID: build.CharacterGUID,
+			ReportCode:          build.ReportCode,
+			FightID:             build.FightID,
+			Source:              warcraftlogsBuilds.PlayerSourceBuild,
+		}
+
+		name := strings.ToLower(build.PlayerName)
+		character, found := byNameAndServer[name+"-"+warcraftlogsBuilds.NormalizeServerName(build.ServerName)]
+		if !found && len(byName[name]) == 1 {
+			// A single character with this name in the run, the realm of the build may be missing or spelled differently
+			character, found = byName[name][0], true
+		}
+		if found {
+			observation.WarcraftLogsCharacterID = character.WarcraftLogsCharacterID()
+			observation.Region = strings.ToUpper(character.Server.Region.CompactName)
+			if observation.ServerName == "" {
+				observation.ServerName = character.Server.Name
+			}
+		}
+
+		observations[i] = observation
+	}
+
+	return observations
+}
@@ -0,0 +1,57 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestBuildPlayerObservations tests the matching of the builds with the ranked characters of the report
+func TestBuildPlayerObservations(t *testing.T) {
+	report := &warcraftlogsBuilds.Report{
+		Code:    "abc123",
+		FightID: 7,
+		RankedCharacters: datatypes.JSON(`[
+			{"id": 101, "canonicalID": 0, "name": "Thrall", "server": {"name": "Tarren Mill", "slug": "tarren-mill", "region": {"compactName": "EU"}}},
+			{"id": 102, "canonicalID": 55, "name": "Jaina", "server": {"name": "Kazzak", "slug": "kazzak", "region": {"compactName": "eu"}}},
+			{"id": 103, "canonicalID": 0, "name": "Anduin", "server": {"name": "Area 52", "slug": "area-52", "region": {"compactName": "US"}}},
+			{"id": 104, "canonicalID": 0, "name": "Anduin", "server": {"name": "Stormrage", "slug": "stormrage", "region": {"compactName": "US"}}}
+		]`),
+	}
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		{PlayerName: "Thrall", ServerName: "TarrenMill", Class: "Shaman", ReportCode: "abc123", FightID: 7, CharacterGUID: 9001},
+		{PlayerName: "jaina", Class: "Mage", ReportCode: "abc123", FightID: 7},
+		{PlayerName: "Anduin", ServerName: "Area52", Class: "Priest", ReportCode: "abc123", FightID: 7},
+		{PlayerName: "Sylvanas", ServerName: "Draenor", Class: "Hunter", ReportCode: "abc123", FightID: 7},
+	}
+
+	observations := activities.BuildPlayerObservations(report, builds)
+	require.Len(t, observations, len(builds))
+
+	// Matched by name and normalized realm
+	assert.Equal(t, int64(101), observations[0].WarcraftLogsCharacterID)
+	assert.Equal(t, int64(9001), observations[0].BlizzardCharacterID)
+	assert.Equal(t, "EU", observations[0].Region)
+	assert.Equal(t, "TarrenMill", observations[0].ServerName)
+	assert.Equal(t, warcraftlogsBuilds.PlayerSourceBuild, observations[0].Source)
+
+	// Matched by name alone, the canonical ID is preferred and the realm is filled
+	assert.Equal(t, int64(55), observations[1].WarcraftLogsCharacterID)
+	assert.Equal(t, "Kazzak", observations[1].ServerName)
+	assert.Equal(t, "EU", observations[1].Region)
+
+	// Two characters share the name, the realm decides
+	assert.Equal(t, int64(103), observations[2].WarcraftLogsCharacterID)
+	assert.Equal(t, "US", observations[2].Region)
+
+	// Not ranked: no Warcraft Logs ID and no region
+	assert.Zero(t, observations[3].WarcraftLogsCharacterID)
+	assert.Empty(t, observations[3].Region)
+	assert.Equal(t, "abc123", observations[3].ReportCode)
+	assert.Equal(t, 7, observations[3].FightID)
+}
@@ -10,6 +10,7 @@ import (
 	rankingsQueries "wowperf/internal/services/warcraftlogs/mythicplus/builds/queries"
 	rankingsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	workflows "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
 	warcraftlogsTypes "wowperf/internal/services/warcraftlogs/types"
 
 	"go.temporal.io/sdk/activity"
@@ -17,14 +18,16 @@ import (
 )
 
 type RankingsActivity struct {
-	client     *warcraftlogs.WarcraftLogsClientService
-	repository *rankingsRepository.RankingsRepository
+	client                  *warcraftlogs.WarcraftLogsClientService
+	repository              *rankingsRepository.RankingsRepository
+	raidEncounterRepository *rankingsRepository.RaidEncounterRepository
 }
 
-func NewRankingsActivity(client *warcraftlogs.WarcraftLogsClientService, repository *rankingsRepository.RankingsRepository) *RankingsActivity {
+func NewRankingsActivity(client *warcraftlogs.WarcraftLogsClientService, repository *rankingsRepository.RankingsRepository, raidEncounterRepository *rankingsRepository.RaidEncounterRepository) *RankingsActivity {
 	return &RankingsActivity{
-		client:     client,
-		repository: repository,
+		client:                  client,
+		repository:              repository,
+		raidEncounterRepository: raidEncounterRepository,
 	}
 }
 
@@ -66,6 +69,11 @@ func (a *RankingsActivity) FetchAndStore(ctx context.Context, spec workflows.Cla
 		if err := a.repository.StoreRankings(ctx, dungeon.EncounterID, rankings); err != nil {
 			return nil, fmt.Errorf("failed to store rankings: %w", err)
 		}
+
+		// Tag the stored rankings with their patch, untagged rankings are only excluded from the patch filters
+		if err := a.repository.TagRankingsWithPatch(ctx, dungeon.EncounterID); err != nil {
+			logger.Error("Failed to tag rankings with patch", "encounterId", dungeon.EncounterID, "error", err)
+		}
 		result.Rankings = rankings
 	}
 
@@ -75,6 +83,111 @@ func (a *RankingsActivity) FetchAndStore(ctx context.Context, spec workflows.Cla
 	return result, nil
 }
 
+// rankingsBatchComplexityBudget is the complexity budget of a batched rankings document
+const rankingsBatchComplexityBudget = 8
+
+// FetchAndStoreBatch fetches the rankings of a spec for several dungeons with batched documents and stores them
+// A result is returned for each dungeon whose rankings have been fetched, failed dungeons are logged and skipped.
+// On a rate limit, the results of the stored dungeons are the details of the RATE_LIMIT_ERROR.
+func (a *RankingsActivity) FetchAndStoreBatch(ctx context.Context, spec workflowsModels.ClassSpec, dungeons []workflowsModels.Dungeon, batchConfig workflowsModels.BatchConfig) ([]*workflowsModels.BatchResult, error) {
+	logger := activity.GetLogger(ctx)
+
+	if spec.ClassName == "" || spec.SpecName == "" {
+		return nil, fmt.Errorf("invalid spec configuration: class=%s, spec=%s", spec.ClassName, spec.SpecName)
+	}
+
+	logger.Info("Starting batched rankings fetch activity",
+		"class", spec.ClassName,
+		"spec", spec.SpecName,
+		"dungeonCount", len(dungeons))
+
+	queries := make([]warcraftlogs.BatchQuery, len(dungeons))
+	for i, dungeon := range dungeons {
+		queries[i] = rankingsQueries.ClassRankingsBatchQuery(dungeon.EncounterID, spec.ClassName, spec.SpecName, 1, dungeon.Difficulty)
+	}
+
+	batcher := a.client.NewQueryBatcher(rankingsBatchComplexityBudget).
+		WithProgress(func(sent, total int) {
+			activity.RecordHeartbeat(ctx, fmt.Sprintf("Fetched %d/%d rankings documents for %s %s",
+				sent, total, spec.ClassName, spec.SpecName))
+		})
+	batchResults := batcher.Execute(ctx, queries)
+
+	// The dungeons fetched before a rate limit are stored, the rate limit is returned after them
+	var rateLimitErr error
+	results := make([]*workflowsModels.BatchResult, 0, len(dungeons))
+	for i, dungeon := range dungeons {
+		activity.RecordHeartbeat(ctx, fmt.Sprintf("Storing rankings for %s %s in %s",
+			spec.ClassName, spec.SpecName, dungeon.Name))
+
+		if warcraftlogs.IsRateLimitOrQuota(batchResults[i].Err) {
+			if rateLimitErr == nil {
+				rateLimitErr = batchResults[i].Err
+			}
+			continue
+		}
+		if batchResults[i].Err != nil {
+			logger.Error("Failed to fetch rankings",
+				"class", spec.ClassName,
+				"spec", spec.SpecName,
+				"dungeon", dungeon.Name,
+				"error", batchResults[i].Err)
+			continue
+		}
+
+		rankings, err := rankingsQueries.ParseRankingsResponse(batchResults[i].Data, uint(dungeon.EncounterID))
+		if err != nil {
+			logger.Error("Failed to parse rankings",
+				"class", spec.ClassName,
+				"spec", spec.SpecName,
+				"dungeon", dungeon.Name,
+				"error", err)
+			continue
+		}
+		setRankingsDifficulty(rankings, dungeon.Difficulty)
+
+		if len(rankings) > 0 {
+			if err := a.repository.StoreRankings(ctx, uint(dungeon.EncounterID), rankings); err != nil {
+				return nil, fmt.Errorf("failed to store rankings: %w", err)
+			}
+
+			// Tag the stored rankings with their patch, untagged rankings are only excluded from the patch filters
+			if err := a.repository.TagRankingsWithPatch(ctx, uint(dungeon.EncounterID)); err != nil {
+				logger.Error("Failed to tag rankings with patch", "encounterId", dungeon.EncounterID, "error", err)
+			}
+		}
+
+		results = append(results, &workflowsModels.BatchResult{
+			ClassName:      spec.ClassName,
+			SpecName:       spec.SpecName,
+			EncounterID:    dungeon.EncounterID,
+			Difficulty:     dungeon.Difficulty,
+			ProcessedItems: int32(len(rankings)),
+			RankingsCount:  int32(len(rankings)),
+			ProcessedAt:    time.Now(),
+		})
+	}
+
+	logger.Info("Completed batched rankings fetch",
+		"class", spec.ClassName,
+		"spec", spec.SpecName,
+		"dungeonsFetched", len(results),
+		"dungeonsRequested", len(dungeons))
+
+	// Rate limits stop the batch so the workflow can wait for the reset,
+	// the results of the stored dungeons are sent with the error so they are not fetched again
+	if rateLimitErr != nil {
+		logger.Info("Rate limit reached", "error", rateLimitErr)
+		return results, temporal.NewApplicationError(
+			fmt.Sprintf("Rate limit reached: %v", rateLimitErr),
+			"RATE_LIMIT_ERROR",
+			results,
+		)
+	}
+
+	return results, nil
+}
+
 // fetchRankingsWithRetry fetches rankings with retry handling
 func (a *RankingsActivity) fetchRankingsWithRetry(ctx context.Context, spec workflows.ClassSpec, dungeon workflows.Dungeon, batchConfig workflows.BatchConfig) ([]*warcraftlogsBuilds.ClassRanking, error) {
 	logger := activity.GetLogger(ctx)
@@ -86,6 +199,9 @@ func (a *RankingsActivity) fetchRankingsWithRetry(ctx context.Context, spec work
 		"specName":    spec.SpecName,
 		"page":        1,
 	}
+	if dungeon.Difficulty != 0 {
+		variables["difficulty"] = int(dungeon.Difficulty)
+	}
 
 	logger.Debug("Making GraphQL request",
 		"class", spec.ClassName,
@@ -130,6 +246,7 @@ func (a *RankingsActivity) fetchRankingsWithRetry(ctx context.Context, spec work
 			err,
 		)
 	}
+	setRankingsDifficulty(fetchedRankings, uint32(dungeon.Difficulty))
 
 	rankings = append(rankings, fetchedRankings...)
 
@@ -143,6 +260,14 @@ func (a *RankingsActivity) fetchRankingsWithRetry(ctx context.Context, spec work
 	return rankings, nil
 }
 
+// setRankingsDifficulty sets the raid difficulty of the target on its rankings
+// so the rankings of a boss are stored apart for each targeted difficulty
+func setRankingsDifficulty(rankings []*warcraftlogsBuilds.ClassRanking, difficulty uint32) {
+	for _, ranking := range rankings {
+		ranking.Difficulty = uint(difficulty)
+	}
+}
+
 // GetStoredRankings retrieves rankings from the database
 func (a *RankingsActivity) GetStoredRankings(ctx context.Context, className, specName string, encounterID uint) ([]*warcraftlogsBuilds.ClassRanking, error) {
 	logger := activity.GetLogger(ctx)
@@ -212,3 +337,37 @@ func (a *RankingsActivity) MarkRankingsForReportProcessing(ctx context.Context,
 
 	return nil
 }
+
+// StoreRaidEncounters registers the raid bosses of the configuration
+// The analytics use them to separate the raid statistics from the Mythic+ ones.
+func (a *RankingsActivity) StoreRaidEncounters(ctx context.Context, raids []workflowsModels.Raid) error {
+	logger := activity.GetLogger(ctx)
+
+	encounters := BuildRaidEncounters(raids)
+	if len(encounters) == 0 {
+		return nil
+	}
+
+	logger.Info("Storing raid encounters", "count", len(encounters))
+
+	return a.raidEncounterRepository.StoreRaidEncounters(ctx, encounters)
+}
+
+// BuildRaidEncounters converts the configured raids to one raid encounter per boss and difficulty
+func BuildRaidEncounters(raids []workflowsModels.Raid) []*warcraftlogsBuilds.RaidEncounter {
+	var encounters []*warcraftlogsBuilds.RaidEncounter
+	for _, raid := range raids {
+		for _, boss := range raid.Bosses {
+			encounters = append(encounters, &warcraftlogsBuilds.RaidEncounter{
+				EncounterID: uint(boss.EncounterID),
+				Name:        boss.Name,
+				Slug:        boss.Slug,
+				RaidName:    raid.Name,
+				RaidSlug:    raid.Slug,
+				ZoneID:      uint(raid.ZoneID),
+				Difficulty:  uint(raid.BossDifficulty(boss)),
+			})
+		}
+	}
+	return encounters
+}
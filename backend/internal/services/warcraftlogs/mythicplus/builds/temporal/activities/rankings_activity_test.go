@@ -0,0 +1,50 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// TestBuildRaidEncounters tests the conversion of the configured raids to raid encounters
+func TestBuildRaidEncounters(t *testing.T) {
+	raids := []models.Raid{
+		{
+			ZoneID:     42,
+			Name:       "Liberation of Undermine",
+			Slug:       "liberation-of-undermine",
+			Difficulty: models.RaidDifficultyMythic,
+			Bosses: []models.RaidBoss{
+				{EncounterID: 3009, Name: "Vexie and the Geargrinders", Slug: "vexie-and-the-geargrinders"},
+				{EncounterID: 3016, Name: "Chrome King Gallywix", Slug: "chrome-king-gallywix", Difficulty: models.RaidDifficultyHeroic},
+				{EncounterID: 3016, Name: "Chrome King Gallywix", Slug: "chrome-king-gallywix"},
+			},
+		},
+	}
+
+	encounters := activities.BuildRaidEncounters(raids)
+	require.Len(t, encounters, 3)
+
+	// 1. The boss inherits the difficulty of the raid
+	assert.Equal(t, uint(3009), encounters[0].EncounterID)
+	assert.Equal(t, "vexie-and-the-geargrinders", encounters[0].Slug)
+	assert.Equal(t, "Liberation of Undermine", encounters[0].RaidName)
+	assert.Equal(t, "liberation-of-undermine", encounters[0].RaidSlug)
+	assert.Equal(t, uint(42), encounters[0].ZoneID)
+	assert.Equal(t, uint(models.RaidDifficultyMythic), encounters[0].Difficulty)
+
+	// 2. The difficulty of the boss overrides the one of the raid
+	assert.Equal(t, uint(3016), encounters[1].EncounterID)
+	assert.Equal(t, uint(models.RaidDifficultyHeroic), encounters[1].Difficulty)
+
+	// 3. The same boss is registered once for each difficulty
+	assert.Equal(t, uint(3016), encounters[2].EncounterID)
+	assert.Equal(t, uint(models.RaidDifficultyMythic), encounters[2].Difficulty)
+
+	// 4. No raid configured
+	assert.Empty(t, activities.BuildRaidEncounters(nil))
+}
@@ -0,0 +1,419 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	"wowperf/internal/services/warcraftlogs"
+	wclAuth "wowperf/internal/services/warcraftlogs/auth"
+	reportsQueries "wowperf/internal/services/warcraftlogs/mythicplus/builds/queries"
+	buildsStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	reportAnalysisJobRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	statStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	talentStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// ReportAnalysisActivity analyzes a report submitted by a user
+// Each player of the report is compared with the statistics of the top players of the same spec and dungeon
+type ReportAnalysisActivity struct {
+	client                     *warcraftlogs.WarcraftLogsClientService
+	authService                *wclAuth.WarcraftLogsAuthService
+	jobRepository              *reportAnalysisJobRepository.ReportAnalysisJobRepository
+	buildsStatisticsRepository *buildsStatisticsRepository.BuildsStatisticsRepository
+	talentStatisticsRepository *talentStatisticsRepository.TalentStatisticsRepository
+	statStatisticsRepository   *statStatisticsRepository.StatStatisticsRepository
+}
+
+// NewReportAnalysisActivity creates a new instance of ReportAnalysisActivity
+func NewReportAnalysisActivity(
+	client *warcraftlogs.WarcraftLogsClientService,
+	authService *wclAuth.WarcraftLogsAuthService,
+	jobRepository *reportAnalysisJobRepository.ReportAnalysisJobRepository,
+	buildsStatisticsRepository *buildsStatisticsRepository.BuildsStatisticsRepository,
+	talentStatisticsRepository *talentStatisticsRepository.TalentStatisticsRepository,
+	statStatisticsRepository *statStatisticsRepository.StatStatisticsRepository,
+) *ReportAnalysisActivity {
+	return &ReportAnalysisActivity{
+		client:                     client,
+		authService:                authService,
+		jobRepository:              jobRepository,
+		buildsStatisticsRepository: buildsStatisticsRepository,
+		talentStatisticsRepository: talentStatisticsRepository,
+		statStatisticsRepository:   statStatisticsRepository,
+	}
+}
+
+// specStatistics holds the statistics of a class, spec and dungeon
+type specStatistics struct {
+	items   []*warcraftlogsBuilds.BuildStatistic
+	talents []*warcraftlogsBuilds.TalentStatistic
+	stats   []*warcraftlogsBuilds.StatStatistic
+}
+
+// AnalyzeReport fetches the Mythic+ fights of a report and compares each player with the stored statistics
+// The report is fetched with the WarcraftLogs account of the user when a user is set, so private reports can be analyzed.
+func (a *ReportAnalysisActivity) AnalyzeReport(
+	ctx context.Context,
+	params models.ReportAnalysisWorkflowParams,
+) (*models.ReportAnalysisWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting report analysis",
+		"jobID", params.JobID,
+		"reportCode", params.ReportCode,
+		"fightID", params.FightID)
+
+	result := &models.ReportAnalysisWorkflowResult{
+		JobID:     params.JobID,
+		StartedAt: time.Now(),
+	}
+
+	if err := a.jobRepository.MarkJobRunning(ctx, params.JobID); err != nil {
+		return nil, err
+	}
+
+	request, release, err := a.getRequestFunc(ctx, params.UserID)
+	if err != nil {
+		if errors.Is(err, wclAuth.ErrAccountNotLinked) {
+			return nil, temporal.NewNonRetryableApplicationError(err.Error(), "ACCOUNT_NOT_LINKED", err)
+		}
+		return nil, err
+	}
+	defer release()
+
+	fightsResponse, err := request(ctx, reportsQueries.GetReportFightsQuery, map[string]interface{}{
+		"code": params.ReportCode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fights of report %s: %w", params.ReportCode, err)
+	}
+
+	fights, err := reportsQueries.ParseReportFightsResponse(fightsResponse)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("Failed to parse fights of report %s: %v", params.ReportCode, err),
+			"PARSE_ERROR",
+			err,
+		)
+	}
+
+	fights = filterReportFights(fights, params.FightID)
+	if len(fights) == 0 {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("No Mythic+ fight found in report %s", params.ReportCode),
+			"NO_MYTHIC_PLUS_FIGHT",
+			nil,
+		)
+	}
+
+	analysis := warcraftlogsBuilds.ReportAnalysisResult{
+		ReportCode: params.ReportCode,
+		Fights:     make([]warcraftlogsBuilds.FightAnalysis, 0, len(fights)),
+	}
+	statisticsCache := make(map[string]*specStatistics)
+
+	for _, fight := range fights {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"fightID":         fight.ID,
+			"fightsAnalyzed":  result.FightsAnalyzed,
+			"playersAnalyzed": result.PlayersAnalyzed,
+		})
+
+		report, err := fetchReportDetails(ctx, request, params.ReportCode, fight.ID, fight.EncounterID)
+		if err != nil {
+			return nil, err
+		}
+
+		builds, err := (&PlayerBuildsActivity{}).extractPlayerBuilds(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract builds of fight %d: %w", fight.ID, err)
+		}
+
+		fightAnalysis := warcraftlogsBuilds.FightAnalysis{
+			FightID:       fight.ID,
+			EncounterID:   fight.EncounterID,
+			KeystoneLevel: fight.KeystoneLevel,
+			KeystoneTime:  fight.KeystoneTime,
+			Players:       make([]warcraftlogsBuilds.PlayerBuildComparison, 0, len(builds)),
+		}
+
+		for _, build := range builds {
+			statistics, err := a.getSpecStatistics(ctx, statisticsCache, build.Class, build.Spec, build.EncounterID)
+			if err != nil {
+				return nil, err
+			}
+
+			comparison, err := ComparePlayerBuild(build, statistics.items, statistics.talents, statistics.stats)
+			if err != nil {
+				logger.Warn("Failed to compare player build",
+					"player", build.PlayerName,
+					"error", err)
+				continue
+			}
+
+			fightAnalysis.Players = append(fightAnalysis.Players, comparison)
+			result.PlayersAnalyzed++
+		}
+
+		analysis.Fights = append(analysis.Fights, fightAnalysis)
+		result.FightsAnalyzed++
+	}
+
+	resultJSON, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report analysis: %w", err)
+	}
+
+	if err := a.jobRepository.CompleteJob(ctx, params.JobID, resultJSON); err != nil {
+		return nil, err
+	}
+
+	result.CompletedAt = time.Now()
+	logger.Info("Report analysis completed",
+		"jobID", params.JobID,
+		"fightsAnalyzed", result.FightsAnalyzed,
+		"playersAnalyzed", result.PlayersAnalyzed)
+
+	return result, nil
+}
+
+// FailReportAnalysis marks a report analysis job as failed
+func (a *ReportAnalysisActivity) FailReportAnalysis(ctx context.Context, jobID, message string) error {
+	return a.jobRepository.FailJob(ctx, jobID, message)
+}
+
+// getRequestFunc returns the function used to query WarcraftLogs
+// The client of the user is used when a user is set, the token is saved back once the analysis is done
+// in case it was refreshed during the requests.
+func (a *ReportAnalysisActivity) getRequestFunc(ctx context.Context, userID *uint) (graphQLRequestFunc, func(), error) {
+	if userID == nil {
+		return a.client.MakeRequest, func() {}, nil
+	}
+
+	userClient, err := a.authService.GetUserClient(ctx, *userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request := func(_ context.Context, query string, variables map[string]interface{}) ([]byte, error) {
+		return userClient.MakeGraphQLRequest(query, variables)
+	}
+	release := func() {
+		if err := a.authService.SaveUserToken(ctx, *userID, userClient); err != nil {
+			activity.GetLogger(ctx).Warn("Failed to save WarcraftLogs token", "userID", *userID, "error", err)
+		}
+	}
+
+	return request, release, nil
+}
+
+// getSpecStatistics retrieves the statistics of a class, spec and dungeon, once per analysis
+func (a *ReportAnalysisActivity) getSpecStatistics(
+	ctx context.Context,
+	cache map[string]*specStatistics,
+	class, spec string,
+	encounterID uint,
+) (*specStatistics, error) {
+	key := fmt.Sprintf("%s-%s-%d", class, spec, encounterID)
+	if statistics, exists := cache[key]; exists {
+		return statistics, nil
+	}
+
+	items, err := a.buildsStatisticsRepository.GetBuildStatistics(ctx, class, spec, encounterID)
+	if err != nil {
+		return nil, err
+	}
+	talents, err := a.talentStatisticsRepository.GetTalentStatistics(ctx, class, spec, encounterID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := a.statStatisticsRepository.GetStatStatistics(ctx, class, spec, encounterID)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := &specStatistics{items: items, talents: talents, stats: stats}
+	cache[key] = statistics
+	return statistics, nil
+}
+
+// filterReportFights keeps the requested fight, or every fight when no fight is requested
+func filterReportFights(fights []reportsQueries.ReportFight, fightID int) []reportsQueries.ReportFight {
+	if fightID == 0 {
+		return fights
+	}
+
+	for _, fight := range fights {
+		if fight.ID == fightID {
+			return []reportsQueries.ReportFight{fight}
+		}
+	}
+	return nil
+}
+
+// ComparePlayerBuild compares the build of a player with the statistics of the top players of the same spec and dungeon
+// Items are compared slot by slot with the most used item, the talent loadout with the most used loadout
+// and the secondary and minor stats with the average of the top players.
+func ComparePlayerBuild(
+	build *warcraftlogsBuilds.PlayerBuild,
+	items []*warcraftlogsBuilds.BuildStatistic,
+	talents []*warcraftlogsBuilds.TalentStatistic,
+	stats []*warcraftlogsBuilds.StatStatistic,
+) (warcraftlogsBuilds.PlayerBuildComparison, error) {
+	comparison := warcraftlogsBuilds.PlayerBuildComparison{
+		PlayerName:          build.PlayerName,
+		Class:               build.Class,
+		Spec:                build.Spec,
+		ItemLevel:           build.ItemLevel,
+		StatisticsAvailable: len(items) > 0 || len(talents) > 0 || len(stats) > 0,
+		Items:               []warcraftlogsBuilds.ItemComparison{},
+		Stats:               []warcraftlogsBuilds.StatComparison{},
+	}
+
+	var gear []GearItem
+	if len(build.Gear) > 0 {
+		if err := json.Unmarshal(build.Gear, &gear); err != nil {
+			return comparison, fmt.Errorf("failed to parse gear of %s: %w", build.PlayerName, err)
+		}
+	}
+
+	var playerStats map[string]Stat
+	if len(build.Stats) > 0 {
+		if err := json.Unmarshal(build.Stats, &playerStats); err != nil {
+			return comparison, fmt.Errorf("failed to parse stats of %s: %w", build.PlayerName, err)
+		}
+	}
+
+	comparison.Items = compareItems(gear, items)
+	comparison.Talents = compareTalents(build.TalentImport, talents)
+	comparison.Stats = compareStats(playerStats, stats)
+
+	return comparison, nil
+}
+
+// compareItems compares each equipped item with the most used item of its slot
+func compareItems(gear []GearItem, items []*warcraftlogsBuilds.BuildStatistic) []warcraftlogsBuilds.ItemComparison {
+	// Most used item and usage of each item per slot
+	popularBySlot := make(map[int]*warcraftlogsBuilds.BuildStatistic)
+	usageBySlotItem := make(map[string]float64)
+	for _, item := range items {
+		if popular, exists := popularBySlot[item.ItemSlot]; !exists || item.UsagePercentage > popular.UsagePercentage {
+			popularBySlot[item.ItemSlot] = item
+		}
+		key := fmt.Sprintf("%d-%d", item.ItemSlot, item.ItemID)
+		if item.UsagePercentage > usageBySlotItem[key] {
+			usageBySlotItem[key] = item.UsagePercentage
+		}
+	}
+
+	comparisons := make([]warcraftlogsBuilds.ItemComparison, 0, len(gear))
+	for _, item := range gear {
+		if item.ID == 0 {
+			continue
+		}
+
+		comparison := warcraftlogsBuilds.ItemComparison{
+			Slot:               item.Slot,
+			ItemID:             item.ID,
+			ItemName:           item.Name,
+			ItemLevel:          int(item.ItemLevel),
+			UsagePercentage:    usageBySlotItem[fmt.Sprintf("%d-%d", item.Slot, item.ID)],
+			PermanentEnchantID: item.PermanentEnchant,
+			IsPopular:          true,
+			HasPopularEnchant:  true,
+		}
+
+		if popular, exists := popularBySlot[item.Slot]; exists {
+			comparison.PopularItemID = popular.ItemID
+			comparison.PopularItemName = popular.ItemName
+			comparison.PopularItemIcon = popular.ItemIcon
+			comparison.PopularUsage = popular.UsagePercentage
+			comparison.PopularEnchantID = popular.PermanentEnchantID
+			comparison.PopularEnchantName = popular.PermanentEnchantName
+			comparison.IsPopular = item.ID == popular.ItemID
+			comparison.HasPopularEnchant = popular.PermanentEnchantID == 0 || item.PermanentEnchant == popular.PermanentEnchantID
+		}
+
+		comparisons = append(comparisons, comparison)
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].Slot < comparisons[j].Slot
+	})
+
+	return comparisons
+}
+
+// compareTalents compares the talent loadout with the most used loadout
+func compareTalents(talentImport string, talents []*warcraftlogsBuilds.TalentStatistic) *warcraftlogsBuilds.TalentComparison {
+	if talentImport == "" {
+		return nil
+	}
+
+	comparison := &warcraftlogsBuilds.TalentComparison{
+		TalentImport: talentImport,
+		IsPopular:    true,
+	}
+
+	var popular *warcraftlogsBuilds.TalentStatistic
+	for _, talent := range talents {
+		if talent.TalentImport == talentImport {
+			comparison.UsagePercentage = talent.UsagePercentage
+		}
+		if popular == nil || talent.UsagePercentage > popular.UsagePercentage {
+			popular = talent
+		}
+	}
+
+	if popular != nil {
+		comparison.PopularTalentImport = popular.TalentImport
+		comparison.PopularUsage = popular.UsagePercentage
+		comparison.IsPopular = talentImport == popular.TalentImport
+	}
+
+	return comparison
+}
+
+// compareStats compares the secondary and minor stats with the average of the top players
+func compareStats(playerStats map[string]Stat, stats []*warcraftlogsBuilds.StatStatistic) []warcraftlogsBuilds.StatComparison {
+	comparisons := make([]warcraftlogsBuilds.StatComparison, 0, len(stats))
+	for _, stat := range stats {
+		// Use the average value of min/max like the stat statistics
+		value := 0.0
+		if playerStat, exists := playerStats[stat.StatName]; exists {
+			value = (playerStat.Min + playerStat.Max) / 2
+		}
+
+		comparison := warcraftlogsBuilds.StatComparison{
+			StatName:     stat.StatName,
+			StatCategory: stat.StatCategory,
+			Value:        value,
+			AvgValue:     stat.AvgValue,
+			MinValue:     stat.MinValue,
+			MaxValue:     stat.MaxValue,
+		}
+		if stat.AvgValue > 0 {
+			comparison.DifferencePct = (value - stat.AvgValue) / stat.AvgValue * 100
+		}
+
+		comparisons = append(comparisons, comparison)
+	}
+
+	// Secondary stats first, then by name
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].StatCategory != comparisons[j].StatCategory {
+			return comparisons[i].StatCategory == "secondary"
+		}
+		return comparisons[i].StatName < comparisons[j].StatName
+	})
+
+	return comparisons
+}
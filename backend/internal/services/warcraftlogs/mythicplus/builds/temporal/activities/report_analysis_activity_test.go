@@ -0,0 +1,99 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestComparePlayerBuild tests the comparison of a player build with the statistics of the top players
+func TestComparePlayerBuild(t *testing.T) {
+	build := &warcraftlogsBuilds.PlayerBuild{
+		PlayerName:   "Zorthar",
+		Class:        "Priest",
+		Spec:         "Shadow",
+		ItemLevel:    639,
+		TalentImport: "CIQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAgZbbmxMzMzYAAAAAAAA",
+		Gear: datatypes.JSON(`[
+			{"id": 212083, "slot": 0, "name": "Living Luster's Touch", "itemLevel": 639, "permanentEnchant": 0},
+			{"id": 0, "slot": 3, "name": "", "itemLevel": 0},
+			{"id": 219313, "slot": 10, "name": "Cyrce's Circlet", "itemLevel": 636, "permanentEnchant": 7340}
+		]`),
+		Stats: datatypes.JSON(`{
+			"Haste": {"min": 9000, "max": 9000},
+			"Mastery": {"min": 5000, "max": 6000},
+			"Leech": {"min": 300, "max": 300},
+			"Stamina": {"min": 200000, "max": 200000}
+		}`),
+	}
+
+	items := []*warcraftlogsBuilds.BuildStatistic{
+		{ItemSlot: 0, ItemID: 212083, ItemName: "Living Luster's Touch", UsagePercentage: 80},
+		{ItemSlot: 10, ItemID: 219313, ItemName: "Cyrce's Circlet", UsagePercentage: 20, PermanentEnchantID: 7340},
+		{ItemSlot: 10, ItemID: 215135, ItemName: "Ring of Earthen Craftsmanship", UsagePercentage: 60, PermanentEnchantID: 7334, PermanentEnchantName: "Radiant Haste"},
+	}
+	talents := []*warcraftlogsBuilds.TalentStatistic{
+		{TalentImport: build.TalentImport, UsagePercentage: 25},
+		{TalentImport: "CIQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAmZmZbbmZGAAAAAAAA", UsagePercentage: 55},
+	}
+	stats := []*warcraftlogsBuilds.StatStatistic{
+		{StatName: "Leech", StatCategory: "minor", AvgValue: 200, MinValue: 0, MaxValue: 1000},
+		{StatName: "Mastery", StatCategory: "secondary", AvgValue: 5000, MinValue: 3000, MaxValue: 9000},
+		{StatName: "Haste", StatCategory: "secondary", AvgValue: 10000, MinValue: 6000, MaxValue: 14000},
+	}
+
+	comparison, err := activities.ComparePlayerBuild(build, items, talents, stats)
+	require.NoError(t, err)
+	assert.True(t, comparison.StatisticsAvailable)
+
+	// Empty slots are ignored
+	require.Len(t, comparison.Items, 2)
+	assert.True(t, comparison.Items[0].IsPopular)
+	assert.Equal(t, 80.0, comparison.Items[0].UsagePercentage)
+
+	ring := comparison.Items[1]
+	assert.False(t, ring.IsPopular)
+	assert.Equal(t, 20.0, ring.UsagePercentage)
+	assert.Equal(t, 215135, ring.PopularItemID)
+	assert.Equal(t, 60.0, ring.PopularUsage)
+	assert.False(t, ring.HasPopularEnchant)
+	assert.Equal(t, "Radiant Haste", ring.PopularEnchantName)
+
+	require.NotNil(t, comparison.Talents)
+	assert.False(t, comparison.Talents.IsPopular)
+	assert.Equal(t, 25.0, comparison.Talents.UsagePercentage)
+	assert.Equal(t, 55.0, comparison.Talents.PopularUsage)
+
+	// Secondary stats first, then by name
+	require.Len(t, comparison.Stats, 3)
+	assert.Equal(t, "Haste", comparison.Stats[0].StatName)
+	assert.InDelta(t, -10.0, comparison.Stats[0].DifferencePct, 0.001)
+	assert.Equal(t, "Mastery", comparison.Stats[1].StatName)
+	assert.InDelta(t, 5500.0, comparison.Stats[1].Value, 0.001)
+	assert.Equal(t, "Leech", comparison.Stats[2].StatName)
+	assert.InDelta(t, 50.0, comparison.Stats[2].DifferencePct, 0.001)
+}
+
+// TestComparePlayerBuildWithoutStatistics tests the comparison when no statistics are stored for the spec and dungeon
+func TestComparePlayerBuildWithoutStatistics(t *testing.T) {
+	build := &warcraftlogsBuilds.PlayerBuild{
+		PlayerName:   "Zorthar",
+		TalentImport: "CIQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAgZbbmxMzMzYAAAAAAAA",
+		Gear:         datatypes.JSON(`[{"id": 212083, "slot": 0, "itemLevel": 639}]`),
+	}
+
+	comparison, err := activities.ComparePlayerBuild(build, nil, nil, nil)
+	require.NoError(t, err)
+	assert.False(t, comparison.StatisticsAvailable)
+	require.Len(t, comparison.Items, 1)
+	assert.Equal(t, 0, comparison.Items[0].PopularItemID)
+	assert.Empty(t, comparison.Stats)
+
+	_, err = activities.ComparePlayerBuild(&warcraftlogsBuilds.PlayerBuild{Gear: datatypes.JSON(`{`)}, nil, nil, nil)
+	assert.Error(t, err)
+}
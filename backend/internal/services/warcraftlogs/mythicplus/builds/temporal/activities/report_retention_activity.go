@@ -0,0 +1,236 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	archive "wowperf/internal/services/warcraftlogs/mythicplus/builds/archive"
+	reportArchiveRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// restoreHoldPeriod is how long restored report data is kept before it can be archived again
+const restoreHoldPeriod = 7 * 24 * time.Hour
+
+// ReportRetentionActivity moves the large report data to the object store and back
+type ReportRetentionActivity struct {
+	repository *reportArchiveRepository.ReportArchiveRepository
+	store      archive.ObjectStore
+}
+
+// NewReportRetentionActivity creates a new ReportRetentionActivity
+func NewReportRetentionActivity(
+	repository *reportArchiveRepository.ReportArchiveRepository,
+	store archive.ObjectStore,
+) *ReportRetentionActivity {
+	return &ReportRetentionActivity{
+		repository: repository,
+		store:      store,
+	}
+}
+
+// ArchiveReports archives a batch of reports for a retention policy
+// Each report column is compressed, written to the object store, recorded in the manifest and then cleared.
+func (a *ReportRetentionActivity) ArchiveReports(
+	ctx context.Context,
+	policy workflowsModels.RetentionPolicy,
+	batchSize int32,
+) (*workflowsModels.ReportRetentionWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.ReportRetentionWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if !warcraftlogsBuilds.IsValidReportArchiveDataType(policy.DataType) {
+		return nil, fmt.Errorf("invalid report archive data type: %s", policy.DataType)
+	}
+	if policy.MaxAge <= 0 {
+		return nil, fmt.Errorf("invalid max age for %s: %s", policy.DataType, policy.MaxAge)
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	now := time.Now()
+	candidates, err := a.repository.GetReportsToArchive(ctx, policy.DataType, now.Add(-policy.MaxAge), now.Add(-restoreHoldPeriod), int(batchSize))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, candidate := range candidates {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":   "archiving_reports",
+			"dataType": policy.DataType,
+			"progress": i,
+			"total":    len(candidates),
+		})
+
+		reportArchive, object, err := NewReportArchive(candidate.Code, candidate.FightID, policy.DataType, candidate.Data)
+		if err != nil {
+			return nil, err
+		}
+		reportArchive.Storage = a.store.Name()
+
+		if err := a.store.Put(ctx, reportArchive.ObjectKey, object); err != nil {
+			logger.Error("Failed to upload report archive",
+				"reportCode", candidate.Code,
+				"fightID", candidate.FightID,
+				"dataType", policy.DataType,
+				"error", err)
+			result.ReportsFailed++
+			continue
+		}
+
+		if err := a.repository.StoreArchive(ctx, reportArchive); err != nil {
+			return nil, err
+		}
+
+		result.ReportsArchived++
+		result.BytesArchived += reportArchive.SizeBytes
+		result.BytesCompressed += reportArchive.CompressedBytes
+	}
+
+	logger.Info("Archived report data",
+		"dataType", policy.DataType,
+		"archived", result.ReportsArchived,
+		"failed", result.ReportsFailed,
+		"bytesArchived", result.BytesArchived,
+		"bytesCompressed", result.BytesCompressed)
+
+	result.CompletedAt = time.Now()
+	return result, nil
+}
+
+// RestoreReports writes the archived data of reports back to their columns
+// All the archived data types of the reports are restored when dataTypes is empty.
+func (a *ReportRetentionActivity) RestoreReports(
+	ctx context.Context,
+	reports []workflowsModels.ReportReference,
+	dataTypes []string,
+) (*workflowsModels.ReportRetentionWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.ReportRetentionWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	for _, dataType := range dataTypes {
+		if !warcraftlogsBuilds.IsValidReportRestoreDataType(dataType) {
+			return nil, fmt.Errorf("invalid report archive data type: %s", dataType)
+		}
+	}
+
+	archives, err := a.repository.GetArchives(ctx, toReportIdentifiers(reports), dataTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, reportArchive := range archives {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":   "restoring_reports",
+			"progress": i,
+			"total":    len(archives),
+		})
+
+		// Data already back in the report
+		if reportArchive.RestoredAt != nil {
+			continue
+		}
+
+		object, err := a.store.Get(ctx, reportArchive.ObjectKey)
+		if err != nil {
+			logger.Error("Failed to download report archive",
+				"reportCode", reportArchive.ReportCode,
+				"fightID", reportArchive.FightID,
+				"dataType", reportArchive.DataType,
+				"error", err)
+			result.ReportsFailed++
+			continue
+		}
+
+		data, err := ReadReportArchive(reportArchive, object)
+		if err != nil {
+			logger.Error("Invalid report archive",
+				"reportCode", reportArchive.ReportCode,
+				"fightID", reportArchive.FightID,
+				"dataType", reportArchive.DataType,
+				"error", err)
+			result.ReportsFailed++
+			continue
+		}
+
+		if err := a.repository.RestoreArchive(ctx, reportArchive, data); err != nil {
+			return nil, err
+		}
+		result.ReportsRestored++
+	}
+
+	logger.Info("Restored report data",
+		"reports", len(reports),
+		"restored", result.ReportsRestored,
+		"failed", result.ReportsFailed)
+
+	result.CompletedAt = time.Now()
+	return result, nil
+}
+
+// GetRestoredReports loads reports so their builds can be extracted again
+func (a *ReportRetentionActivity) GetRestoredReports(ctx context.Context, reports []workflowsModels.ReportReference) ([]*warcraftlogsBuilds.Report, error) {
+	return a.repository.GetReportsByIdentifiers(ctx, toReportIdentifiers(reports))
+}
+
+// NewReportArchive compresses the data of a report column and builds its manifest entry
+// It returns the manifest entry, without its storage, and the object to upload.
+func NewReportArchive(code string, fightID int, dataType string, data []byte) (*warcraftlogsBuilds.ReportArchive, []byte, error) {
+	object, err := archive.Compress(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compress %s of report %s-#%d: %w", dataType, code, fightID, err)
+	}
+
+	checksum := sha256.Sum256(data)
+	return &warcraftlogsBuilds.ReportArchive{
+		ReportCode:      code,
+		FightID:         fightID,
+		DataType:        dataType,
+		ObjectKey:       ReportArchiveKey(code, fightID, dataType),
+		SizeBytes:       int64(len(data)),
+		CompressedBytes: int64(len(object)),
+		Checksum:        hex.EncodeToString(checksum[:]),
+		ArchivedAt:      time.Now(),
+	}, object, nil
+}
+
+// ReadReportArchive decompresses an archive object and checks it against its manifest entry
+func ReadReportArchive(reportArchive *warcraftlogsBuilds.ReportArchive, object []byte) ([]byte, error) {
+	data, err := archive.Decompress(object)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(data)
+	if hex.EncodeToString(checksum[:]) != reportArchive.Checksum {
+		return nil, fmt.Errorf("checksum mismatch for archive %s", reportArchive.ObjectKey)
+	}
+	return data, nil
+}
+
+// ReportArchiveKey returns the object key of the archive of a report column
+func ReportArchiveKey(code string, fightID int, dataType string) string {
+	return fmt.Sprintf("reports/%s/%d/%s.json.gz", code, fightID, dataType)
+}
+
+func toReportIdentifiers(reports []workflowsModels.ReportReference) []reportArchiveRepository.ReportIdentifier {
+	identifiers := make([]reportArchiveRepository.ReportIdentifier, 0, len(reports))
+	for _, report := range reports {
+		identifiers = append(identifiers, reportArchiveRepository.ReportIdentifier{
+			Code:    report.Code,
+			FightID: report.FightID,
+		})
+	}
+	return identifiers
+}
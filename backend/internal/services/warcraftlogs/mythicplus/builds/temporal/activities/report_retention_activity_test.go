@@ -0,0 +1,35 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportArchiveRoundTrip(t *testing.T) {
+	data := []byte(`{"playerDetails":[{"name":"TestPlayer","type":"Priest"}]}`)
+
+	reportArchive, object, err := NewReportArchive("abc123", 4, "player_details_dps", data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "reports/abc123/4/player_details_dps.json.gz", reportArchive.ObjectKey)
+	assert.Equal(t, int64(len(data)), reportArchive.SizeBytes)
+	assert.Equal(t, int64(len(object)), reportArchive.CompressedBytes)
+	assert.Len(t, reportArchive.Checksum, 64)
+
+	restored, err := ReadReportArchive(reportArchive, object)
+	require.NoError(t, err)
+	assert.Equal(t, data, restored)
+}
+
+func TestReadReportArchiveChecksumMismatch(t *testing.T) {
+	reportArchive, _, err := NewReportArchive("abc123", 4, "raw_data", []byte(`{"a":1}`))
+	require.NoError(t, err)
+
+	_, other, err := NewReportArchive("abc123", 4, "raw_data", []byte(`{"a":2}`))
+	require.NoError(t, err)
+
+	_, err = ReadReportArchive(reportArchive, other)
+	assert.Error(t, err)
+}
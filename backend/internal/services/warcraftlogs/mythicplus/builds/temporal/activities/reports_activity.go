@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"sync"
 	"time"
 
 	"go.temporal.io/sdk/activity"
@@ -12,30 +11,25 @@ import (
 	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 	"wowperf/internal/services/warcraftlogs"
 	reportsQueries "wowperf/internal/services/warcraftlogs/mythicplus/builds/queries"
+	groupCompositionRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	rankingsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	reportsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	runPacingRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 
 	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
 )
 
-// reportWorkItem represents a single unit of work to be processed by workers
-type reportWorkItem struct {
-	ranking *warcraftlogsBuilds.ClassRanking
-	index   int // Preserve ordering for final results
-}
-
-// reportWorkResult represents the result of processing a single report
-type reportWorkResult struct {
-	report *warcraftlogsBuilds.Report
-	index  int // Original position in the rankings slice
-	err    error
-}
+// reportsBatchComplexityBudget is the complexity budget of a batched reports document
+// A report table costs 2 and a talents lookup 1, so a document holds 5 tables or 10 talents lookups.
+const reportsBatchComplexityBudget = 10
 
 // ReportsActivity handles all report-related operations
 type ReportsActivity struct {
-	client             *warcraftlogs.WarcraftLogsClientService
-	repository         *reportsRepository.ReportRepository
-	rankingsRepository *rankingsRepository.RankingsRepository
+	client                     *warcraftlogs.WarcraftLogsClientService
+	repository                 *reportsRepository.ReportRepository
+	rankingsRepository         *rankingsRepository.RankingsRepository
+	groupCompositionRepository *groupCompositionRepository.GroupCompositionRepository
+	runPacingRepository        *runPacingRepository.RunPacingRepository
 }
 
 // NewReportsActivity creates a new instance of ReportsActivity
@@ -43,11 +37,15 @@ func NewReportsActivity(
 	client *warcraftlogs.WarcraftLogsClientService,
 	repository *reportsRepository.ReportRepository,
 	rankingsRepository *rankingsRepository.RankingsRepository,
+	groupCompositionRepository *groupCompositionRepository.GroupCompositionRepository,
+	runPacingRepository *runPacingRepository.RunPacingRepository,
 ) *ReportsActivity {
 	return &ReportsActivity{
-		client:             client,
-		repository:         repository,
-		rankingsRepository: rankingsRepository,
+		client:                     client,
+		repository:                 repository,
+		rankingsRepository:         rankingsRepository,
+		groupCompositionRepository: groupCompositionRepository,
+		runPacingRepository:        runPacingRepository,
 	}
 }
 
@@ -70,38 +68,89 @@ func (a *ReportsActivity) ProcessReports(
 	logger.Info("Starting reports processing", "rankingsCount", len(rankings))
 
 	// Fetch reports from API
-	reports, err := a.fetchReportsFromAPI(ctx, rankings)
-	if err != nil {
-		logger.Error("Failed to fetch reports from API", "error", err)
-		return nil, fmt.Errorf("failed to fetch reports: %w", err)
+	reports, fetchErr := a.fetchReportsFromAPI(ctx, rankings)
+	if fetchErr != nil {
+		// The reports fetched before the rate limit are kept, so the next run does not fetch them again
+		logger.Error("Failed to fetch reports from API", "error", fetchErr, "fetchedCount", len(reports))
+		if len(reports) > 0 {
+			fetchedRankings := rankingsOfReports(rankings, reports)
+			if err := a.storeFetchedReports(ctx, fetchedRankings, reports); err != nil {
+				logger.Error("Failed to store reports fetched before the rate limit", "error", err)
+			} else {
+				a.markRankingsAsProcessed(ctx, fetchedRankings)
+			}
+		}
+		return nil, fmt.Errorf("failed to fetch reports: %w", fetchErr)
+	}
+
+	if err := a.storeFetchedReports(ctx, rankings, reports); err != nil {
+		return nil, err
 	}
 
-	// Store fetched reports
+	result.ProcessedReports = reports
+	result.ProcessedCount = int32(len(reports))
+	result.SuccessCount = 1
+
+	logger.Info("Completed report processing",
+		"totalProcessed", len(reports),
+		"duration", time.Since(result.ProcessedAt))
+
+	// Mark rankings as processed
+	a.markRankingsAsProcessed(ctx, rankings)
+
+	return result, nil
+}
+
+// storeFetchedReports stores the reports fetched for rankings with their compositions and pacings
+// and synchronizes them with the rankings.
+func (a *ReportsActivity) storeFetchedReports(
+	ctx context.Context,
+	rankings []*warcraftlogsBuilds.ClassRanking,
+	reports []*warcraftlogsBuilds.Report,
+) error {
+	logger := activity.GetLogger(ctx)
+
 	if len(reports) > 0 {
 		if err := a.repository.StoreReports(ctx, reports); err != nil {
 			logger.Error("Failed to store reports",
 				"reportCount", len(reports),
 				"error", err)
-			return nil, fmt.Errorf("failed to store reports: %w", err)
+			return fmt.Errorf("failed to store reports: %w", err)
 		}
 		logger.Info("Successfully stored reports", "count", len(reports))
+
+		// Store the group compositions of the reports
+		if err := a.storeGroupCompositions(ctx, reports); err != nil {
+			logger.Error("Failed to store group compositions", "error", err)
+			// Continue even if compositions fail, they are not required by the builds processing
+		}
+
+		// Store the pacing timeline of the runs
+		if err := a.storeRunPacings(ctx, reports); err != nil {
+			logger.Error("Failed to store run pacings", "error", err)
+			// Continue even if pacings fail, they are not required by the builds processing
+		}
 	}
 
 	// Synchronize with rankings
 	if err := a.repository.SyncReportsWithRankings(ctx, rankings); err != nil {
 		logger.Error("Failed to sync reports with rankings", "error", err)
-		return nil, fmt.Errorf("failed to sync reports: %w", err)
+		return fmt.Errorf("failed to sync reports: %w", err)
 	}
 
-	result.ProcessedReports = reports
-	result.ProcessedCount = int32(len(reports))
-	result.SuccessCount = 1
+	// Tag the reports with the patch of their ranking
+	if err := a.repository.TagReportsWithPatch(ctx); err != nil {
+		logger.Error("Failed to tag reports with patch", "error", err)
+		// Continue even if tagging fails, the reports are tagged on the next run
+	}
 
-	logger.Info("Completed report processing",
-		"totalProcessed", len(reports),
-		"duration", time.Since(result.ProcessedAt))
+	return nil
+}
+
+// markRankingsAsProcessed marks the rankings as processed for reports, failures are only logged
+func (a *ReportsActivity) markRankingsAsProcessed(ctx context.Context, rankings []*warcraftlogsBuilds.ClassRanking) {
+	logger := activity.GetLogger(ctx)
 
-	// Mark rankings as processed
 	var rankingIDs []uint
 	for _, ranking := range rankings {
 		rankingIDs = append(rankingIDs, ranking.ID)
@@ -115,180 +164,246 @@ func (a *ReportsActivity) ProcessReports(
 			logger.Info("Successfully marked rankings as processed", "count", len(rankingIDs))
 		}
 	}
+}
 
-	return result, nil
+// rankingsOfReports returns the rankings whose report fight has been fetched
+func rankingsOfReports(rankings []*warcraftlogsBuilds.ClassRanking, reports []*warcraftlogsBuilds.Report) []*warcraftlogsBuilds.ClassRanking {
+	fetched := make(map[string]bool, len(reports))
+	for _, report := range reports {
+		fetched[fmt.Sprintf("%s-%d", report.Code, report.FightID)] = true
+	}
+
+	matching := make([]*warcraftlogsBuilds.ClassRanking, 0, len(reports))
+	for _, ranking := range rankings {
+		if fetched[fmt.Sprintf("%s-%d", ranking.ReportCode, ranking.ReportFightID)] {
+			matching = append(matching, ranking)
+		}
+	}
+	return matching
 }
 
-// fetchReportsFromAPI fetches reports from the WarcraftLogs API in parallel
-// It processes multiple rankings simultaneously while maintaining order and handling rate limits
+// storeGroupCompositions extracts the group composition of each report and persists them
+// Reports of dungeons unknown to the dungeons table are skipped.
+func (a *ReportsActivity) storeGroupCompositions(ctx context.Context, reports []*warcraftlogsBuilds.Report) error {
+	if a.groupCompositionRepository == nil {
+		return fmt.Errorf("internal error: groupCompositionRepository not injected")
+	}
+
+	dungeons, err := a.groupCompositionRepository.GetDungeonTimers(ctx)
+	if err != nil {
+		return err
+	}
+
+	compositions := make([]*warcraftlogsBuilds.GroupComposition, 0, len(reports))
+	for _, report := range reports {
+		dungeon, exists := dungeons[report.EncounterID]
+		if !exists {
+			continue
+		}
+
+		composition, err := BuildGroupComposition(report, dungeon)
+		if err != nil {
+			return err
+		}
+		if composition != nil {
+			compositions = append(compositions, composition)
+		}
+	}
+
+	return a.groupCompositionRepository.StoreGroupCompositions(ctx, compositions)
+}
+
+// storeRunPacings splits the timeline of each report into boss and trash segments and persists them
+// Reports without timeline are skipped, the timer of dungeons unknown to the dungeons table is 0.
+func (a *ReportsActivity) storeRunPacings(ctx context.Context, reports []*warcraftlogsBuilds.Report) error {
+	if a.runPacingRepository == nil || a.groupCompositionRepository == nil {
+		return fmt.Errorf("internal error: runPacingRepository not injected")
+	}
+
+	dungeons, err := a.groupCompositionRepository.GetDungeonTimers(ctx)
+	if err != nil {
+		return err
+	}
+
+	segments := make([]*warcraftlogsBuilds.RunPacingSegment, 0)
+	for _, report := range reports {
+		runSegments, err := BuildRunPacing(report, dungeons[report.EncounterID])
+		if err != nil {
+			return err
+		}
+		segments = append(segments, runSegments...)
+	}
+
+	return a.runPacingRepository.StoreRunPacingSegments(ctx, segments)
+}
+
+// fetchReportsFromAPI fetches reports from the WarcraftLogs API with batched documents
+// The report tables of the rankings are fetched together, then the talents of the reports.
+// On a rate limit, the reports fetched with their talents before it are returned with the rate limit error.
 func (a *ReportsActivity) fetchReportsFromAPI(
 	ctx context.Context,
 	rankings []*warcraftlogsBuilds.ClassRanking,
 ) ([]*warcraftlogsBuilds.Report, error) {
 	logger := activity.GetLogger(ctx)
 
-	// Configuration constants for parallel processing
-	const (
-		maxWorkers = 2  // Maximum number of concurrent workers
-		batchSize  = 10 // Size of processing batches
-	)
+	activity.RecordHeartbeat(ctx, map[string]interface{}{
+		"status":     "fetching_reports",
+		"totalCount": len(rankings),
+	})
 
-	// Pre-allocate slice to maintain order of reports
-	// This allows us to preserve the relationship between rankings and reports
-	reports := make([]*warcraftlogsBuilds.Report, len(rankings))
-
-	// Channel setup for work distribution and result collection
-	// Buffered channels are used to optimize throughput
-	workChan := make(chan reportWorkItem, batchSize)     // Channel for distributing work
-	resultChan := make(chan reportWorkResult, batchSize) // Channel for collecting results
-	doneChan := make(chan struct{})                      // Channel to signal completion
-
-	// Start worker pool
-	// Each worker processes items from workChan independently
-	var wg sync.WaitGroup
-	for i := 0; i < maxWorkers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			for work := range workChan {
-				// Check for context cancellation
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					// Record worker activity for monitoring
-					activity.RecordHeartbeat(ctx, map[string]interface{}{
-						"workerID":   workerID,
-						"reportCode": work.ranking.ReportCode,
-						"index":      work.index,
-						"status":     "processing",
-					})
-
-					// Process the report through the API
-					report, err := a.getReportDetails(ctx, work.ranking)
-
-					// Little delay to prevent 429 error
-					time.Sleep(time.Millisecond * 500)
-
-					// Send result back through result channel
-					resultChan <- reportWorkResult{
-						report: report,
-						index:  work.index,
-						err:    err,
-					}
-				}
-			}
-		}(i)
-	}
-
-	// Goroutine to close result channel when all workers are done
-	go func() {
-		wg.Wait()
-		close(resultChan)
-		close(doneChan)
-	}()
-
-	// Goroutine to distribute work to workers
-	// This runs independently of result collection to maintain steady worker utilization
-	go func() {
-		defer close(workChan)
-
-		for i, ranking := range rankings {
-			select {
-			case <-ctx.Done():
-				return
-			case workChan <- reportWorkItem{ranking: ranking, index: i}:
-				// Work successfully queued
+	batcher := a.client.NewQueryBatcher(reportsBatchComplexityBudget).
+		WithProgress(func(sent, total int) {
+			activity.RecordHeartbeat(ctx, map[string]interface{}{
+				"status":         "fetching_reports",
+				"totalCount":     len(rankings),
+				"documentsSent":  sent,
+				"documentsTotal": total,
+			})
+		})
+	reports, errs := FetchReportsBatched(ctx, batcher, rankings)
+
+	var rateLimitErr error
+	for _, err := range errs {
+		if warcraftlogs.IsRateLimitOrQuota(err) {
+			if rateLimitErr == nil {
+				rateLimitErr = err
 			}
+			continue
+		}
+		logger.Error("Failed to fetch report", "error", err)
+	}
+
+	// Log final processing statistics
+	logger.Info("Completed fetching reports",
+		"processedCount", len(reports),
+		"failureCount", len(errs),
+		"totalRequested", len(rankings),
+		"rateLimited", rateLimitErr != nil)
+
+	// Rate limits are returned so the workflow can wait for the reset
+	return reports, rateLimitErr
+}
+
+// FetchReportsBatched fetches the reports of rankings with batched documents
+// It returns the fetched reports, in the order of the rankings, and the errors of the failed reports.
+// Only the reports with their talents are returned. When the tables or the talents hit the rate limit,
+// the tables fetched without talents are dropped on purpose: the builds cannot be extracted without the talents,
+// and a stored report marks its ranking as processed, so its talents would never be fetched. Their rankings
+// stay pending and the tables are fetched again on the next run.
+func FetchReportsBatched(
+	ctx context.Context,
+	batcher *warcraftlogs.QueryBatcher,
+	rankings []*warcraftlogsBuilds.ClassRanking,
+) ([]*warcraftlogsBuilds.Report, []error) {
+	var errs []error
+
+	// 1. Fetch the report tables
+	tableQueries := make([]warcraftlogs.BatchQuery, len(rankings))
+	for i, ranking := range rankings {
+		tableQueries[i] = reportsQueries.ReportTableBatchQuery(ranking.ReportCode, ranking.ReportFightID, ranking.EncounterID)
+	}
+
+	reports := make([]*warcraftlogsBuilds.Report, 0, len(rankings))
+	talentQueries := make([]warcraftlogs.BatchQuery, 0, len(rankings))
+	for i, result := range batcher.Execute(ctx, tableQueries) {
+		ranking := rankings[i]
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch report %s: %w", ranking.ReportCode, result.Err))
+			continue
 		}
-	}()
-
-	// Collect and process results as they come in
-	processedCount := 0
-	failureCount := 0
-
-	// Process results as they arrive from workers
-	for result := range resultChan {
-		if result.err != nil {
-			logger.Error("Failed to fetch report",
-				"index", result.index,
-				"error", result.err)
-			failureCount++
+
+		report, talentsQuery, err := reportsQueries.ParseReportDetailsResponse(result.Data, ranking.ReportCode, ranking.ReportFightID, ranking.EncounterID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse report details: %w", err))
 			continue
 		}
+		report.Difficulty = ranking.Difficulty
 
-		if result.report != nil {
-			reports[result.index] = result.report
-			processedCount++
+		talentQuery, err := reportsQueries.ReportTalentsBatchQuery(talentsQuery)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to build talents query for report %s: %w", ranking.ReportCode, err))
+			continue
 		}
 
-		// Record progress for monitoring
-		activity.RecordHeartbeat(ctx, map[string]interface{}{
-			"processedCount": processedCount,
-			"failureCount":   failureCount,
-			"totalCount":     len(rankings),
-			"progress":       fmt.Sprintf("%d/%d", processedCount+failureCount, len(rankings)),
-		})
+		reports = append(reports, report)
+		talentQueries = append(talentQueries, talentQuery)
 	}
 
-	// Wait for all processing to complete
-	<-doneChan
-
-	// Clean up the results by removing any nil entries from failed processes
-	cleanReports := make([]*warcraftlogsBuilds.Report, 0, processedCount)
-	for _, report := range reports {
-		if report != nil {
-			cleanReports = append(cleanReports, report)
+	// 2. Fetch the talents of the reports, unless the tables already hit the rate limit
+	fetched := make([]*warcraftlogsBuilds.Report, 0, len(reports))
+	for _, err := range errs {
+		if warcraftlogs.IsRateLimitOrQuota(err) {
+			return fetched, errs
 		}
 	}
+	for i, result := range batcher.Execute(ctx, talentQueries) {
+		report := reports[i]
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch talents for report %s: %w", report.Code, result.Err))
+			continue
+		}
 
-	// Log final processing statistics
-	logger.Info("Completed fetching reports",
-		"processedCount", processedCount,
-		"failureCount", failureCount,
-		"totalRequested", len(rankings))
+		talentCodes, err := reportsQueries.ParseReportTalentsResponse(result.Data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse talents for report %s: %w", report.Code, err))
+			continue
+		}
 
-	return cleanReports, nil
+		if report.TalentCodes, err = json.Marshal(talentCodes); err != nil {
+			errs = append(errs, fmt.Errorf("failed to marshal talents for report %s: %w", report.Code, err))
+			continue
+		}
+		fetched = append(fetched, report)
+	}
+
+	return fetched, errs
 }
 
-// getReportDetails fetches and processes report details from WarcraftLogs API
-func (a *ReportsActivity) getReportDetails(
+// graphQLRequestFunc performs a WarcraftLogs GraphQL request
+// It allows to fetch reports with the public client or with the client of a user
+type graphQLRequestFunc func(ctx context.Context, query string, variables map[string]interface{}) ([]byte, error)
+
+// fetchReportDetails fetches the details and the talents of a report fight
+func fetchReportDetails(
 	ctx context.Context,
-	ranking *warcraftlogsBuilds.ClassRanking,
+	request graphQLRequestFunc,
+	code string,
+	fightID int,
+	encounterID uint,
 ) (*warcraftlogsBuilds.Report, error) {
-	response, err := a.client.MakeRequest(ctx, reportsQueries.GetReportTableQuery, map[string]interface{}{
-		"code":        ranking.ReportCode,
-		"fightID":     ranking.ReportFightID,
-		"encounterID": ranking.EncounterID,
+	response, err := request(ctx, reportsQueries.GetReportTableQuery, map[string]interface{}{
+		"code":        code,
+		"fightID":     fightID,
+		"encounterID": encounterID,
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch report %s: %w", ranking.ReportCode, err)
+		return nil, fmt.Errorf("failed to fetch report %s: %w", code, err)
 	}
 
 	report, talentsQuery, err := reportsQueries.ParseReportDetailsResponse(
 		response,
-		ranking.ReportCode,
-		ranking.ReportFightID,
-		ranking.EncounterID,
+		code,
+		fightID,
+		encounterID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse report details: %w", err)
 	}
 
-	talentsResponse, err := a.client.MakeRequest(ctx, talentsQuery, nil)
+	talentsResponse, err := request(ctx, talentsQuery, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch talents for report %s: %w", ranking.ReportCode, err)
+		return nil, fmt.Errorf("failed to fetch talents for report %s: %w", code, err)
 	}
 
 	talentCodes, err := reportsQueries.ParseReportTalentsResponse(talentsResponse)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse talents for report %s: %w", ranking.ReportCode, err)
+		return nil, fmt.Errorf("failed to parse talents for report %s: %w", code, err)
 	}
 
 	report.TalentCodes, err = json.Marshal(talentCodes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal talents for report %s: %w", ranking.ReportCode, err)
+		return nil, fmt.Errorf("failed to marshal talents for report %s: %w", code, err)
 	}
 
 	return report, nil
@@ -0,0 +1,92 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	groupCompositionRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+)
+
+// BuildRunPacing splits the timeline of a run into trash and boss segments
+// A boss segment goes from the first pull of the boss, wipes included, to its kill. A trash segment covers
+// the time between the previous boss kill (or the start of the run) and the first pull of the next boss,
+// and the run ends with the trash after the last boss when there is any.
+// Returns nil if the report has no timeline.
+func BuildRunPacing(
+	report *warcraftlogsBuilds.Report,
+	dungeon groupCompositionRepository.DungeonTimer,
+) ([]*warcraftlogsBuilds.RunPacingSegment, error) {
+	if len(report.Fights) == 0 {
+		return nil, nil
+	}
+
+	var timeline warcraftlogsBuilds.ReportFightTimeline
+	if err := json.Unmarshal(report.Fights, &timeline); err != nil {
+		return nil, fmt.Errorf("error parsing fights for report %s-%d: %w", report.Code, report.FightID, err)
+	}
+	if len(timeline.DungeonPulls) == 0 || timeline.EndTime <= timeline.StartTime {
+		return nil, nil
+	}
+
+	pulls := make([]warcraftlogsBuilds.DungeonPull, len(timeline.DungeonPulls))
+	copy(pulls, timeline.DungeonPulls)
+	sort.SliceStable(pulls, func(i, j int) bool {
+		return pulls[i].StartTime < pulls[j].StartTime
+	})
+
+	timed := report.KeystoneTime > 0 && dungeon.TimerMS > 0 && report.KeystoneTime <= dungeon.TimerMS
+	segments := make([]*warcraftlogsBuilds.RunPacingSegment, 0)
+	addSegment := func(segmentType string, pull warcraftlogsBuilds.DungeonPull, start, end int64) {
+		segments = append(segments, &warcraftlogsBuilds.RunPacingSegment{
+			ReportCode:      report.Code,
+			FightID:         report.FightID,
+			EncounterID:     report.EncounterID,
+			KeystoneLevel:   report.KeystoneLevel,
+			KeystoneTime:    report.KeystoneTime,
+			TimerMS:         dungeon.TimerMS,
+			Timed:           timed,
+			SegmentIndex:    len(segments),
+			SegmentType:     segmentType,
+			BossEncounterID: pull.EncounterID,
+			Name:            pull.Name,
+			StartOffset:     start - timeline.StartTime,
+			EndOffset:       end - timeline.StartTime,
+			Duration:        end - start,
+		})
+	}
+
+	cursor := timeline.StartTime
+	firstPulls := make(map[uint]int64) // First pull of each boss not killed yet
+	for _, pull := range pulls {
+		if pull.EncounterID == 0 {
+			continue
+		}
+
+		start, wiped := firstPulls[pull.EncounterID]
+		if !wiped {
+			start = pull.StartTime
+		}
+		if !pull.Kill {
+			firstPulls[pull.EncounterID] = start
+			continue
+		}
+		delete(firstPulls, pull.EncounterID)
+
+		if start < cursor {
+			start = cursor
+		}
+		if start > cursor {
+			addSegment(warcraftlogsBuilds.PacingSegmentTrash, pull, cursor, start)
+		}
+		addSegment(warcraftlogsBuilds.PacingSegmentBoss, pull, start, pull.EndTime)
+		cursor = pull.EndTime
+	}
+
+	if timeline.EndTime > cursor {
+		addSegment(warcraftlogsBuilds.PacingSegmentTrash, warcraftlogsBuilds.DungeonPull{}, cursor, timeline.EndTime)
+	}
+
+	return segments, nil
+}
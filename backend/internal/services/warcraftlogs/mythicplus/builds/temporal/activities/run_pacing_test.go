@@ -0,0 +1,79 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	groupCompositionRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestBuildRunPacing tests the split of a run timeline into trash and boss segments
+func TestBuildRunPacing(t *testing.T) {
+	// 1. Create a timed run with a wipe on the second boss
+	report := &warcraftlogsBuilds.Report{
+		Code:          "g9Lhy8JmkV1xQ3Gj",
+		FightID:       26,
+		EncounterID:   12660,
+		KeystoneLevel: 15,
+		KeystoneTime:  1700000,
+		Fights: datatypes.JSON(`{
+			"id": 26,
+			"startTime": 10000,
+			"endTime": 1710000,
+			"dungeonPulls": [
+				{"id": 1, "encounterID": 0, "name": "Trash", "startTime": 20000, "endTime": 80000},
+				{"id": 2, "encounterID": 2900, "name": "Avanoxx", "startTime": 200000, "endTime": 300000, "kill": true},
+				{"id": 5, "encounterID": 2901, "name": "Anub'zekt", "startTime": 700000, "endTime": 900000, "kill": true},
+				{"id": 3, "encounterID": 0, "name": "Trash", "startTime": 350000, "endTime": 400000},
+				{"id": 4, "encounterID": 2901, "name": "Anub'zekt", "startTime": 500000, "endTime": 550000, "kill": false},
+				{"id": 6, "encounterID": 2902, "name": "Ki'katal", "startTime": 1500000, "endTime": 1690000, "kill": true}
+			]
+		}`),
+	}
+
+	// 2. Split the timeline
+	segments, err := activities.BuildRunPacing(report, groupCompositionRepository.DungeonTimer{DungeonID: 3, TimerMS: 1800000})
+	require.NoError(t, err)
+	require.Len(t, segments, 7)
+
+	expected := []struct {
+		segmentType string
+		boss        uint
+		start, end  int64
+	}{
+		{warcraftlogsBuilds.PacingSegmentTrash, 2900, 0, 190000},
+		{warcraftlogsBuilds.PacingSegmentBoss, 2900, 190000, 290000},
+		{warcraftlogsBuilds.PacingSegmentTrash, 2901, 290000, 490000},
+		{warcraftlogsBuilds.PacingSegmentBoss, 2901, 490000, 890000}, // The wipe is part of the boss segment
+		{warcraftlogsBuilds.PacingSegmentTrash, 2902, 890000, 1490000},
+		{warcraftlogsBuilds.PacingSegmentBoss, 2902, 1490000, 1680000},
+		{warcraftlogsBuilds.PacingSegmentTrash, 0, 1680000, 1700000},
+	}
+	for i, segment := range segments {
+		assert.Equal(t, i, segment.SegmentIndex)
+		assert.Equal(t, expected[i].segmentType, segment.SegmentType, "segment %d", i)
+		assert.Equal(t, expected[i].boss, segment.BossEncounterID, "segment %d", i)
+		assert.Equal(t, expected[i].start, segment.StartOffset, "segment %d", i)
+		assert.Equal(t, expected[i].end, segment.EndOffset, "segment %d", i)
+		assert.Equal(t, expected[i].end-expected[i].start, segment.Duration, "segment %d", i)
+		assert.True(t, segment.Timed)
+		assert.Equal(t, int64(1800000), segment.TimerMS)
+	}
+	assert.Equal(t, "Anub'zekt", segments[3].Name)
+
+	// 3. A depleted run is not timed
+	report.KeystoneTime = 1900000
+	segments, err = activities.BuildRunPacing(report, groupCompositionRepository.DungeonTimer{DungeonID: 3, TimerMS: 1800000})
+	require.NoError(t, err)
+	assert.False(t, segments[0].Timed)
+
+	// 4. Reports without timeline have no pacing
+	segments, err = activities.BuildRunPacing(&warcraftlogsBuilds.Report{Code: "a", FightID: 1}, groupCompositionRepository.DungeonTimer{})
+	require.NoError(t, err)
+	assert.Nil(t, segments)
+}
@@ -17,18 +17,21 @@ import (
 
 // StatStatisticsActivity manages all operations related to stat statistics.
 type StatStatisticsActivity struct {
-	playerBuildsRepository   *playerBuildsRepository.PlayerBuildsRepository
-	statStatisticsRepository *statStatisticsRepository.StatStatisticsRepository
+	playerBuildsRepository         *playerBuildsRepository.PlayerBuildsRepository
+	statStatisticsRepository       *statStatisticsRepository.StatStatisticsRepository
+	statWeightStatisticsRepository *statStatisticsRepository.StatWeightStatisticsRepository
 }
 
 // NewStatStatisticsActivity creates a new StatStatisticsActivity.
 func NewStatStatisticsActivity(
 	playerBuildsRepository *playerBuildsRepository.PlayerBuildsRepository,
 	statStatisticsRepository *statStatisticsRepository.StatStatisticsRepository,
+	statWeightStatisticsRepository *statStatisticsRepository.StatWeightStatisticsRepository,
 ) *StatStatisticsActivity {
 	return &StatStatisticsActivity{
-		playerBuildsRepository:   playerBuildsRepository,
-		statStatisticsRepository: statStatisticsRepository,
+		playerBuildsRepository:         playerBuildsRepository,
+		statStatisticsRepository:       statStatisticsRepository,
+		statWeightStatisticsRepository: statWeightStatisticsRepository,
 	}
 }
 
@@ -55,10 +58,12 @@ type Stat struct {
 }
 
 // ProcessStatStatistics analyzes the stats for a class/spec/dungeon
+// difficulty is the raid difficulty of a raid boss, 0 for a Mythic+ dungeon.
+// Segments and weekly snapshots are Mythic+ analytics, they are skipped for the raid bosses.
 func (a *StatStatisticsActivity) ProcessStatStatistics(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 	batchSize int,
 ) (*workflowsModels.StatAnalysisWorkflowResult, error) {
 	logger := activity.GetLogger(ctx)
@@ -67,12 +72,12 @@ func (a *StatStatisticsActivity) ProcessStatStatistics(
 	}
 
 	// 1. Delete existing statistics
-	if err := a.statStatisticsRepository.DeleteStatStatistics(ctx, class, spec, encounterID); err != nil {
+	if err := a.statStatisticsRepository.DeleteStatStatistics(ctx, class, spec, encounterID, difficulty); err != nil {
 		return nil, fmt.Errorf("failed to delete existing stat statistics: %w", err)
 	}
 
 	// 2. Get the total number of builds to process
-	count, err := a.playerBuildsRepository.CountPlayerBuildsNeedingStatAnalysis(ctx, class, spec, encounterID)
+	count, err := a.playerBuildsRepository.CountPlayerBuildsNeedingStatAnalysis(ctx, class, spec, encounterID, difficulty)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +97,9 @@ func (a *StatStatisticsActivity) ProcessStatStatistics(
 	// Structures to store the aggregated statistics
 	statData := make(map[string]*StatAggregation)
 
+	// Stat values per patch, keystone level and affix set
+	statSegments := make(map[string]*warcraftlogsBuilds.StatStatisticSegment)
+
 	// For storing the IDs of successfully processed builds
 	processedBuildIDs := make([]uint, 0)
 
@@ -108,7 +116,7 @@ func (a *StatStatisticsActivity) ProcessStatStatistics(
 
 		// Get a batch of builds
 		builds, err := a.playerBuildsRepository.GetPlayerBuildsNeedingStatAnalysis(
-			ctx, class, spec, encounterID, batchSize, offset)
+			ctx, class, spec, encounterID, difficulty, batchSize, offset)
 		if err != nil {
 			return nil, err
 		}
@@ -134,6 +142,15 @@ func (a *StatStatisticsActivity) ProcessStatStatistics(
 			return nil, err
 		}
 
+		// Segment the batch by patch, keystone level and affix set
+		if err := a.AggregateStatSegments(builds, statSegments); err != nil {
+			if len(batchBuildIDs) > 0 {
+				_ = a.playerBuildsRepository.MarkPlayerBuildsAsProcessedForStat(
+					ctx, batchBuildIDs, "failed")
+			}
+			return nil, err
+		}
+
 		// Add the IDs of successfully processed builds
 		processedBuildIDs = append(processedBuildIDs, batchBuildIDs...)
 
@@ -149,7 +166,7 @@ func (a *StatStatisticsActivity) ProcessStatStatistics(
 	}
 
 	// Convert the aggregated data to final statistics
-	statStats := a.ConvertToStatStatistics(statData, class, spec, encounterID)
+	statStats := a.ConvertToStatStatistics(statData, class, spec, encounterID, difficulty)
 
 	// Persist the statistics
 	if len(statStats) > 0 {
@@ -169,6 +186,27 @@ func (a *StatStatisticsActivity) ProcessStatStatistics(
 		}
 	}
 
+	// Persist the statistics segmented by patch, keystone level and affix set, for the Mythic+ dungeons only
+	if difficulty == 0 {
+		if err := a.statStatisticsRepository.ReplaceStatStatisticSegments(
+			ctx, class, spec, encounterID, a.FinalizeStatSegments(statSegments)); err != nil {
+			return nil, fmt.Errorf("failed to store stat statistic segments: %w", err)
+		}
+	}
+
+	// Keep a copy of the statistics for the weekly trends
+	if len(statStats) > 0 && difficulty == 0 {
+		periodStart := warcraftlogsBuilds.WeeklyResetPeriod(time.Now())
+		if err := a.statStatisticsRepository.SnapshotStatStatistics(ctx, class, spec, encounterID, periodStart); err != nil {
+			logger.Error("Failed to snapshot stat statistics",
+				"error", err,
+				"class", class,
+				"spec", spec,
+				"encounter", encounterID)
+			// Continue despite the error
+		}
+	}
+
 	result.TotalBuilds = int32(totalProcessed)
 	result.StatsAnalyzed = int32(len(statStats))
 	result.CompletedAt = time.Now()
@@ -334,7 +372,7 @@ func (a *StatStatisticsActivity) ProcessStatsBatch(
 func (a *StatStatisticsActivity) ConvertToStatStatistics(
 	statData map[string]*StatAggregation,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 ) []*warcraftlogsBuilds.StatStatistic {
 	result := make([]*warcraftlogsBuilds.StatStatistic, 0, len(statData))
 
@@ -347,6 +385,7 @@ func (a *StatStatisticsActivity) ConvertToStatStatistics(
 			Class:            class,
 			Spec:             spec,
 			EncounterID:      encounterID,
+			Difficulty:       difficulty,
 			StatName:         statName,
 			StatCategory:     agg.Category,
 			AvgValue:         agg.TotalValue / float64(agg.Count),
@@ -366,3 +405,83 @@ func (a *StatStatisticsActivity) ConvertToStatStatistics(
 
 	return result
 }
+
+// AggregateStatSegments adds the stats of a batch of builds to the segments of their patch, keystone level and affix set
+// Values and item levels are summed in AvgValue and AvgItemLevel, FinalizeStatSegments turns the sums into averages.
+func (a *StatStatisticsActivity) AggregateStatSegments(
+	builds []*warcraftlogsBuilds.PlayerBuild,
+	segments map[string]*warcraftlogsBuilds.StatStatisticSegment,
+) error {
+	for _, build := range builds {
+		// Check that the stats field is not empty
+		if len(build.Stats) == 0 {
+			continue
+		}
+
+		var statsMap map[string]Stat
+		if err := json.Unmarshal([]byte(build.Stats), &statsMap); err != nil {
+			return fmt.Errorf("error parsing stats JSON for build %d: %w", build.ID, err)
+		}
+
+		segmentKey := warcraftlogsBuilds.StatisticSegmentKey(build.Patch, build.KeystoneLevel, build.Affixes)
+		for statName, statValue := range statsMap {
+			var category string
+			if secondaryStats[statName] {
+				category = "secondary"
+			} else if minorStats[statName] {
+				category = "minor"
+			} else {
+				continue
+			}
+
+			// Use the average value of min/max
+			value := (statValue.Min + statValue.Max) / 2
+
+			key := fmt.Sprintf("%s_%d_%s", segmentKey, build.EncounterID, statName)
+			segment, exists := segments[key]
+			if !exists {
+				segment = &warcraftlogsBuilds.StatStatisticSegment{
+					Class:         build.Class,
+					Spec:          build.Spec,
+					EncounterID:   build.EncounterID,
+					KeystoneLevel: build.KeystoneLevel,
+					Affixes:       warcraftlogsBuilds.SortedAffixes(build.Affixes),
+					Patch:         build.Patch,
+					StatName:      statName,
+					StatCategory:  category,
+					MinValue:      value,
+					MaxValue:      value,
+				}
+				segments[key] = segment
+			}
+
+			segment.AvgValue += value
+			segment.AvgItemLevel += build.ItemLevel
+			segment.SampleSize++
+
+			if value < segment.MinValue {
+				segment.MinValue = value
+			}
+			if value > segment.MaxValue {
+				segment.MaxValue = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// FinalizeStatSegments converts the aggregated stat segments to a slice and computes their averages
+func (a *StatStatisticsActivity) FinalizeStatSegments(
+	segments map[string]*warcraftlogsBuilds.StatStatisticSegment,
+) []*warcraftlogsBuilds.StatStatisticSegment {
+	result := make([]*warcraftlogsBuilds.StatStatisticSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.SampleSize > 0 {
+			segment.AvgValue /= float64(segment.SampleSize)
+			segment.AvgItemLevel /= float64(segment.SampleSize)
+		}
+		result = append(result, segment)
+	}
+	return result
+}
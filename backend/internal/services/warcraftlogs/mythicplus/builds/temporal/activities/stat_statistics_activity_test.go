@@ -54,7 +54,7 @@ func TestStatTransformation(t *testing.T) {
 
 	// 6. Convert the aggregated data to statistics
 	t.Log("Converting the aggregated data to statistics")
-	stats := activity.ConvertToStatStatistics(statData, "Priest", "Discipline", 62286)
+	stats := activity.ConvertToStatStatistics(statData, "Priest", "Discipline", 62286, 0)
 
 	// Verify the number of statistics extracted (secondary + minor)
 	// We expect: Crit, Haste, Mastery, Versatility (secondary) + Leech, Avoidance, Speed (minor)
@@ -166,7 +166,7 @@ func TestMultipleStatBuilds(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 4. Convert the aggregated data to statistics
-	stats := activity.ConvertToStatStatistics(statData, "Priest", "Discipline", 62286)
+	stats := activity.ConvertToStatStatistics(statData, "Priest", "Discipline", 62286, 0)
 	assert.Equal(t, 2, len(stats), "Two types of stats expected: Crit and Haste")
 
 	// 5. Map of stats by name
@@ -239,7 +239,7 @@ func TestSkippedStats(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 4. Convert the aggregated data to statistics
-	stats := activity.ConvertToStatStatistics(statData, "Priest", "Discipline", 62286)
+	stats := activity.ConvertToStatStatistics(statData, "Priest", "Discipline", 62286, 0)
 
 	// We expect only Crit (secondary) and not Intellect or Stamina (primary)
 	assert.Equal(t, 1, len(stats), "Only one stat (Crit) should be extracted")
@@ -250,3 +250,34 @@ func TestSkippedStats(t *testing.T) {
 
 	t.Log("End of TestSkippedStats")
 }
+
+// TestAggregateStatSegments checks that the stats are segmented by keystone level and affix set
+func TestAggregateStatSegments(t *testing.T) {
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 14, ItemLevel: 636, Affixes: pq.Int64Array{10, 152},
+			Stats: datatypes.JSON(`{"Haste": {"max": 20000, "min": 20000}, "Leech": {"max": 1000, "min": 1000}, "Intellect": {"max": 65128, "min": 65128}}`)},
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 14, ItemLevel: 640, Affixes: pq.Int64Array{10, 152},
+			Stats: datatypes.JSON(`{"Haste": {"max": 24000, "min": 22000}}`)},
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 5, ItemLevel: 610, Affixes: pq.Int64Array{10, 152},
+			Stats: datatypes.JSON(`{"Haste": {"max": 10000, "min": 10000}}`)},
+	}
+
+	activity := &activities.StatStatisticsActivity{}
+	segments := make(map[string]*warcraftlogsBuilds.StatStatisticSegment)
+	assert.NoError(t, activity.AggregateStatSegments(builds, segments))
+
+	result := activity.FinalizeStatSegments(segments)
+	assert.Len(t, result, 3, "Haste at +14 and +5, Leech at +14, primary stats ignored")
+
+	for _, segment := range result {
+		if segment.StatName != "Haste" || segment.KeystoneLevel != 14 {
+			continue
+		}
+		assert.Equal(t, "secondary", segment.StatCategory)
+		assert.Equal(t, 2, segment.SampleSize)
+		assert.Equal(t, 21500.0, segment.AvgValue)
+		assert.Equal(t, 20000.0, segment.MinValue)
+		assert.Equal(t, 23000.0, segment.MaxValue)
+		assert.Equal(t, 638.0, segment.AvgItemLevel)
+	}
+}
@@ -0,0 +1,375 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	workflowsModels "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+const (
+	// minStatWeightSampleSize is the minimum number of builds needed to fit the stat weights
+	minStatWeightSampleSize = 50
+	// statWeightZScore is the z-score of the 95% confidence interval of the weights
+	statWeightZScore = 1.96
+	// itemLevelRegressor is the name of the item level control in the regression
+	itemLevelRegressor = "Item Level"
+)
+
+// StatWeightStats lists the secondary stats regressed by the stat weights analysis, in a fixed order
+var StatWeightStats = []string{"Crit", "Haste", "Mastery", "Versatility"}
+
+// StatWeightObservation is a build reduced to the values used by the stat weights regression
+type StatWeightObservation struct {
+	Stats       map[string]float64 // Secondary stat ratings
+	ItemLevel   float64
+	Performance float64
+}
+
+// StatWeightFit holds the result of the stat weights regression
+type StatWeightFit struct {
+	Coefficients map[string]float64 // Metric points per rating point
+	StdErrors    map[string]float64
+	SampleSize   int
+	RSquared     float64
+}
+
+// ProcessStatWeights estimates the weight of each secondary stat of a class and spec for a performance metric
+// Every stored Mythic+ build with a value for the metric is used, the analysis status of the builds is left untouched.
+func (a *StatStatisticsActivity) ProcessStatWeights(
+	ctx context.Context,
+	class, spec, metric string,
+	batchSize int,
+) (*workflowsModels.StatWeightsWorkflowResult, error) {
+	logger := activity.GetLogger(ctx)
+	result := &workflowsModels.StatWeightsWorkflowResult{
+		StartedAt: time.Now(),
+	}
+
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	// 1. Load the builds with their performance value
+	observations := make([]StatWeightObservation, 0)
+	for offset := 0; ; offset += batchSize {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":   "loading_stat_weight_samples",
+			"class":    class,
+			"spec":     spec,
+			"metric":   metric,
+			"progress": len(observations),
+		})
+
+		samples, err := a.playerBuildsRepository.GetStatWeightSamples(ctx, class, spec, metric, batchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sample := range samples {
+			observation, ok, err := NewStatWeightObservation(sample.Stats, sample.ItemLevel, sample.Performance)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing stats JSON for build %d: %w", sample.BuildID, err)
+			}
+			if ok {
+				observations = append(observations, observation)
+			}
+		}
+
+		if len(samples) < batchSize {
+			break
+		}
+	}
+
+	// 2. Fit the regression, a spec without enough builds keeps its previous weights
+	if len(observations) < minStatWeightSampleSize {
+		logger.Info("Not enough builds to estimate the stat weights",
+			"class", class,
+			"spec", spec,
+			"metric", metric,
+			"builds", len(observations))
+		result.CompletedAt = time.Now()
+		return result, nil
+	}
+
+	fit, err := FitStatWeights(observations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit stat weights for %s %s (%s): %w", class, spec, metric, err)
+	}
+
+	weights := RelativeStatWeights(fit, class, spec, metric)
+
+	// 3. Replace the stored weights
+	if err := a.statWeightStatisticsRepository.DeleteStatWeightStatistics(ctx, class, spec, metric); err != nil {
+		return nil, err
+	}
+
+	if err := a.statWeightStatisticsRepository.StoreManyStatWeightStatistics(ctx, weights); err != nil {
+		return nil, err
+	}
+
+	result.SamplesAnalyzed = int32(fit.SampleSize)
+	result.WeightsStored = int32(len(weights))
+	result.CompletedAt = time.Now()
+
+	logger.Info("Completed stat weights analysis",
+		"class", class,
+		"spec", spec,
+		"metric", metric,
+		"builds", fit.SampleSize,
+		"rSquared", fit.RSquared,
+		"weights", len(weights))
+
+	return result, nil
+}
+
+// NewStatWeightObservation extracts the secondary stat ratings of a build stats JSON
+// It returns false when the build has no secondary stat or no performance value.
+func NewStatWeightObservation(statsJSON []byte, itemLevel, performance float64) (StatWeightObservation, bool, error) {
+	observation := StatWeightObservation{
+		Stats:       make(map[string]float64, len(StatWeightStats)),
+		ItemLevel:   itemLevel,
+		Performance: performance,
+	}
+
+	if len(statsJSON) == 0 || performance <= 0 {
+		return observation, false, nil
+	}
+
+	var statsMap map[string]Stat
+	if err := json.Unmarshal(statsJSON, &statsMap); err != nil {
+		return observation, false, err
+	}
+
+	found := false
+	for _, statName := range StatWeightStats {
+		stat, exists := statsMap[statName]
+		if !exists {
+			continue
+		}
+		// Use the average value of min/max, like the stat statistics
+		observation.Stats[statName] = (stat.Min + stat.Max) / 2
+		found = true
+	}
+
+	return observation, found, nil
+}
+
+// FitStatWeights regresses the performance on the secondary stat ratings with ordinary least squares
+// The item level is added as a control so that the weights are not just a proxy of the gear level,
+// it is dropped when every build has the same item level.
+func FitStatWeights(observations []StatWeightObservation) (*StatWeightFit, error) {
+	regressors := append([]string{}, StatWeightStats...)
+
+	n := len(observations)
+	if n == 0 {
+		return nil, fmt.Errorf("no observations to fit")
+	}
+
+	value := func(o StatWeightObservation, regressor string) float64 {
+		if regressor == itemLevelRegressor {
+			return o.ItemLevel
+		}
+		return o.Stats[regressor]
+	}
+
+	// Center the values, the intercept is then implied by the means
+	meanY := 0.0
+	for _, o := range observations {
+		meanY += o.Performance
+	}
+	meanY /= float64(n)
+
+	for _, o := range observations {
+		if o.ItemLevel != observations[0].ItemLevel {
+			regressors = append(regressors, itemLevelRegressor)
+			break
+		}
+	}
+
+	p := len(regressors)
+	if n <= p+1 {
+		return nil, fmt.Errorf("not enough observations to fit %d regressors: %d", p, n)
+	}
+
+	means := make([]float64, p)
+	for j, regressor := range regressors {
+		for _, o := range observations {
+			means[j] += value(o, regressor)
+		}
+		means[j] /= float64(n)
+	}
+
+	// Normal equations X'X b = X'y
+	xtx := make([][]float64, p)
+	for j := range xtx {
+		xtx[j] = make([]float64, p)
+	}
+	xty := make([]float64, p)
+	row := make([]float64, p)
+	sst := 0.0
+
+	for _, o := range observations {
+		for j, regressor := range regressors {
+			row[j] = value(o, regressor) - means[j]
+		}
+		y := o.Performance - meanY
+		sst += y * y
+
+		for j := 0; j < p; j++ {
+			xty[j] += row[j] * y
+			for k := 0; k < p; k++ {
+				xtx[j][k] += row[j] * row[k]
+			}
+		}
+	}
+
+	inverse, err := invertMatrix(xtx)
+	if err != nil {
+		return nil, err
+	}
+
+	coefficients := make([]float64, p)
+	for j := 0; j < p; j++ {
+		for k := 0; k < p; k++ {
+			coefficients[j] += inverse[j][k] * xty[k]
+		}
+	}
+
+	// Residual variance of the fit
+	ssr := 0.0
+	for _, o := range observations {
+		predicted := meanY
+		for j, regressor := range regressors {
+			predicted += coefficients[j] * (value(o, regressor) - means[j])
+		}
+		residual := o.Performance - predicted
+		ssr += residual * residual
+	}
+	variance := ssr / float64(n-p-1)
+
+	fit := &StatWeightFit{
+		Coefficients: make(map[string]float64, p),
+		StdErrors:    make(map[string]float64, p),
+		SampleSize:   n,
+	}
+	for j, regressor := range regressors {
+		fit.Coefficients[regressor] = coefficients[j]
+		fit.StdErrors[regressor] = math.Sqrt(math.Max(variance*inverse[j][j], 0))
+	}
+	if sst > 0 {
+		fit.RSquared = 1 - ssr/sst
+	}
+
+	return fit, nil
+}
+
+// RelativeStatWeights converts the regression coefficients to weights relative to the best secondary stat
+// The confidence intervals are scaled by the same factor, the uncertainty of the best stat itself is not propagated.
+// No weight is returned when no secondary stat has a positive coefficient.
+func RelativeStatWeights(fit *StatWeightFit, class, spec, metric string) []*warcraftlogsBuilds.StatWeightStatistic {
+	if fit == nil {
+		return nil
+	}
+
+	best := 0.0
+	for _, statName := range StatWeightStats {
+		if coefficient := fit.Coefficients[statName]; coefficient > best {
+			best = coefficient
+		}
+	}
+	if best <= 0 {
+		return nil
+	}
+
+	weights := make([]*warcraftlogsBuilds.StatWeightStatistic, 0, len(StatWeightStats))
+	for _, statName := range StatWeightStats {
+		coefficient, exists := fit.Coefficients[statName]
+		if !exists {
+			continue
+		}
+		stdError := fit.StdErrors[statName]
+
+		weights = append(weights, &warcraftlogsBuilds.StatWeightStatistic{
+			Class:       class,
+			Spec:        spec,
+			Metric:      metric,
+			StatName:    statName,
+			Coefficient: coefficient,
+			StdError:    stdError,
+			Weight:      coefficient / best,
+			CILower:     (coefficient - statWeightZScore*stdError) / best,
+			CIUpper:     (coefficient + statWeightZScore*stdError) / best,
+			SampleSize:  fit.SampleSize,
+			RSquared:    fit.RSquared,
+		})
+	}
+
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i].Weight > weights[j].Weight
+	})
+
+	return weights
+}
+
+// invertMatrix inverts a square matrix with Gauss-Jordan elimination and partial pivoting
+func invertMatrix(matrix [][]float64) ([][]float64, error) {
+	n := len(matrix)
+
+	// Pivots are compared with the scale of the matrix, the stat ratings are in the thousands
+	scale := 0.0
+	for i := range matrix {
+		scale = math.Max(scale, math.Abs(matrix[i][i]))
+	}
+	tolerance := scale * 1e-12
+
+	// Augmented matrix [A | I]
+	augmented := make([][]float64, n)
+	for i := range matrix {
+		augmented[i] = make([]float64, 2*n)
+		copy(augmented[i], matrix[i])
+		augmented[i][n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(augmented[r][col]) > math.Abs(augmented[pivot][col]) {
+				pivot = r
+			}
+		}
+		// A null pivot means a stat has no variance or is a combination of the others
+		if math.Abs(augmented[pivot][col]) <= tolerance {
+			return nil, fmt.Errorf("collinear regressors, the stat weights cannot be estimated")
+		}
+		augmented[col], augmented[pivot] = augmented[pivot], augmented[col]
+
+		divisor := augmented[col][col]
+		for k := range augmented[col] {
+			augmented[col][k] /= divisor
+		}
+
+		for r := 0; r < n; r++ {
+			if r == col || augmented[r][col] == 0 {
+				continue
+			}
+			factor := augmented[r][col]
+			for k := range augmented[r] {
+				augmented[r][k] -= factor * augmented[col][k]
+			}
+		}
+	}
+
+	inverse := make([][]float64, n)
+	for i := range augmented {
+		inverse[i] = augmented[i][n:]
+	}
+	return inverse, nil
+}
@@ -0,0 +1,133 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// statWeightTestObservations generates builds whose performance depends linearly on their stats
+func statWeightTestObservations(n int) []activities.StatWeightObservation {
+	random := rand.New(rand.NewSource(42))
+
+	observations := make([]activities.StatWeightObservation, 0, n)
+	for i := 0; i < n; i++ {
+		stats := map[string]float64{
+			"Crit":        2000 + random.Float64()*8000,
+			"Haste":       2000 + random.Float64()*8000,
+			"Mastery":     2000 + random.Float64()*8000,
+			"Versatility": 2000 + random.Float64()*8000,
+		}
+		itemLevel := 630 + random.Float64()*10
+
+		performance := 100 +
+			0.004*stats["Haste"] +
+			0.002*stats["Crit"] +
+			0.001*stats["Mastery"] +
+			0.5*itemLevel +
+			random.NormFloat64()*0.5
+
+		observations = append(observations, activities.StatWeightObservation{
+			Stats:       stats,
+			ItemLevel:   itemLevel,
+			Performance: performance,
+		})
+	}
+	return observations
+}
+
+// TestFitStatWeights checks that the regression recovers the coefficients of the generated data
+func TestFitStatWeights(t *testing.T) {
+	fit, err := activities.FitStatWeights(statWeightTestObservations(500))
+	require.NoError(t, err)
+
+	assert.Equal(t, 500, fit.SampleSize)
+	assert.InDelta(t, 0.004, fit.Coefficients["Haste"], 0.0002)
+	assert.InDelta(t, 0.002, fit.Coefficients["Crit"], 0.0002)
+	assert.InDelta(t, 0.001, fit.Coefficients["Mastery"], 0.0002)
+	assert.InDelta(t, 0, fit.Coefficients["Versatility"], 0.0002)
+	assert.InDelta(t, 0.5, fit.Coefficients["Item Level"], 0.05)
+	assert.Greater(t, fit.RSquared, 0.95)
+
+	for _, stat := range activities.StatWeightStats {
+		assert.Greater(t, fit.StdErrors[stat], 0.0, stat)
+	}
+}
+
+// TestFitStatWeights_Collinear checks that stats moving together cannot be separated
+func TestFitStatWeights_Collinear(t *testing.T) {
+	observations := statWeightTestObservations(100)
+	for i := range observations {
+		observations[i].Stats["Crit"] = observations[i].Stats["Haste"]
+	}
+
+	_, err := activities.FitStatWeights(observations)
+	assert.Error(t, err)
+}
+
+// TestRelativeStatWeights checks the normalization of the weights and their confidence intervals
+func TestRelativeStatWeights(t *testing.T) {
+	fit := &activities.StatWeightFit{
+		Coefficients: map[string]float64{
+			"Crit":        0.002,
+			"Haste":       0.004,
+			"Mastery":     0.001,
+			"Versatility": -0.0005,
+			"Item Level":  0.5,
+		},
+		StdErrors: map[string]float64{
+			"Crit":        0.0005,
+			"Haste":       0.0005,
+			"Mastery":     0.0005,
+			"Versatility": 0.0005,
+			"Item Level":  0.01,
+		},
+		SampleSize: 300,
+		RSquared:   0.4,
+	}
+
+	weights := activities.RelativeStatWeights(fit, "Priest", "Discipline", "score")
+
+	// The item level is a control, not a stat weight
+	require.Len(t, weights, 4)
+	assert.Equal(t, "Haste", weights[0].StatName)
+	assert.Equal(t, "Versatility", weights[3].StatName)
+
+	haste := weights[0]
+	assert.Equal(t, "score", haste.Metric)
+	assert.Equal(t, 300, haste.SampleSize)
+	assert.InDelta(t, 1.0, haste.Weight, 1e-9)
+	assert.InDelta(t, (0.004-1.96*0.0005)/0.004, haste.CILower, 1e-9)
+	assert.InDelta(t, (0.004+1.96*0.0005)/0.004, haste.CIUpper, 1e-9)
+
+	assert.InDelta(t, 0.5, weights[1].Weight, 1e-9)
+	assert.Less(t, weights[3].Weight, 0.0)
+
+	// No positive coefficient, no weight
+	fit.Coefficients = map[string]float64{"Crit": -1, "Haste": -2, "Mastery": -3, "Versatility": -4}
+	assert.Empty(t, activities.RelativeStatWeights(fit, "Priest", "Discipline", "score"))
+}
+
+// TestNewStatWeightObservation checks the extraction of the secondary stats of a build
+func TestNewStatWeightObservation(t *testing.T) {
+	statsJSON := []byte(`{"Crit": {"max": 3762, "min": 3762}, "Haste": {"max": 24047, "min": 24047}, "Leech": {"max": 2995, "min": 2995}, "Mastery": {"max": 3200, "min": 3100}, "Intellect": {"max": 65128, "min": 65128}, "Versatility": {"max": 11708, "min": 11708}}`)
+
+	observation, ok, err := activities.NewStatWeightObservation(statsJSON, 636, 3150)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, observation.Stats, 4)
+	assert.Equal(t, 3150.0, observation.Stats["Mastery"])
+	assert.Equal(t, 24047.0, observation.Stats["Haste"])
+
+	// A build without performance value is skipped
+	_, ok, err = activities.NewStatWeightObservation(statsJSON, 636, 0)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = activities.NewStatWeightObservation([]byte(`{invalid`), 636, 3150)
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package warcraftlogsBuildsTemporalActivities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.temporal.io/sdk/activity"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+)
+
+// ProcessTalentNodeStatistics computes the pick rate of each talent node entry for a class, spec and encounter_id
+// Unlike ProcessTalentStatistics, every stored build is used. It returns the number of node statistics stored.
+func (a *TalentStatisticActivity) ProcessTalentNodeStatistics(
+	ctx context.Context,
+	class, spec string,
+	encounterID uint,
+	batchSize int,
+) (int, error) {
+	logger := activity.GetLogger(ctx)
+
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	count, err := a.countBuilds(ctx, class, spec, encounterID)
+	if err != nil {
+		return 0, err
+	}
+
+	aggregation := NewTalentNodeAggregation()
+	for offset := 0; offset < int(count); offset += batchSize {
+		activity.RecordHeartbeat(ctx, map[string]interface{}{
+			"status":    "processing_talent_nodes",
+			"class":     class,
+			"spec":      spec,
+			"encounter": encounterID,
+			"progress":  fmt.Sprintf("%d/%d", offset, count),
+		})
+
+		builds, err := a.getPlayerBuildsBatch(ctx, class, spec, encounterID, batchSize, offset)
+		if err != nil {
+			return 0, err
+		}
+		if len(builds) == 0 {
+			break
+		}
+
+		for _, build := range builds {
+			if err := aggregation.Add(build); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	nodeStats := aggregation.Statistics()
+
+	if err := a.talentStatisticsRepository.DeleteTalentNodeStatistics(ctx, class, spec, encounterID); err != nil {
+		return 0, err
+	}
+
+	if err := a.talentStatisticsRepository.StoreManyTalentNodeStatistics(ctx, nodeStats); err != nil {
+		return 0, err
+	}
+
+	logger.Info("Completed talent node analysis",
+		"class", class,
+		"spec", spec,
+		"encounter", encounterID,
+		"builds", aggregation.SampleSize,
+		"entries", len(nodeStats))
+
+	return len(nodeStats), nil
+}
+
+// TalentTreeEntry represents a talent picked by a player in the talent tree JSON field
+type TalentTreeEntry struct {
+	ID     int `json:"id"`
+	Rank   int `json:"rank"`
+	NodeID int `json:"nodeID"`
+}
+
+// talentNodeKey identifies an entry of a talent node
+type talentNodeKey struct {
+	NodeID  int
+	EntryID int
+}
+
+// TalentNodeAggregation aggregates the talent node picks of builds sharing the same class, spec and encounter
+type TalentNodeAggregation struct {
+	SampleSize int // Number of builds with a talent tree
+
+	class       string
+	spec        string
+	encounterID uint
+	picks       map[talentNodeKey]int
+	ranks       map[talentNodeKey]int
+}
+
+// NewTalentNodeAggregation creates an empty TalentNodeAggregation
+func NewTalentNodeAggregation() *TalentNodeAggregation {
+	return &TalentNodeAggregation{
+		picks: make(map[talentNodeKey]int),
+		ranks: make(map[talentNodeKey]int),
+	}
+}
+
+// Add decodes the talent tree of a build and counts its picks
+// Builds without a talent tree are ignored.
+func (g *TalentNodeAggregation) Add(build *warcraftlogsBuilds.PlayerBuild) error {
+	if len(build.TalentTree) == 0 {
+		return nil
+	}
+
+	var entries []TalentTreeEntry
+	if err := json.Unmarshal([]byte(build.TalentTree), &entries); err != nil {
+		return fmt.Errorf("error parsing talent tree JSON for build %d: %w", build.ID, err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	g.class = build.Class
+	g.spec = build.Spec
+	g.encounterID = build.EncounterID
+	g.SampleSize++
+
+	seen := make(map[talentNodeKey]bool)
+	for _, entry := range entries {
+		if entry.NodeID == 0 || entry.Rank <= 0 {
+			continue
+		}
+
+		key := talentNodeKey{NodeID: entry.NodeID, EntryID: entry.ID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		g.picks[key]++
+		g.ranks[key] += entry.Rank
+	}
+
+	return nil
+}
+
+// Statistics returns one statistic per picked node entry, ordered by node and entry
+func (g *TalentNodeAggregation) Statistics() []*warcraftlogsBuilds.TalentNodeStatistic {
+	if g.SampleSize == 0 {
+		return nil
+	}
+
+	stats := make([]*warcraftlogsBuilds.TalentNodeStatistic, 0, len(g.picks))
+	for key, count := range g.picks {
+		stats = append(stats, &warcraftlogsBuilds.TalentNodeStatistic{
+			Class:       g.class,
+			Spec:        g.spec,
+			EncounterID: g.encounterID,
+			NodeID:      key.NodeID,
+			EntryID:     key.EntryID,
+			PickCount:   count,
+			SampleSize:  g.SampleSize,
+			PickRate:    float64(count) / float64(g.SampleSize) * 100,
+			AvgRank:     float64(g.ranks[key]) / float64(count),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].NodeID != stats[j].NodeID {
+			return stats[i].NodeID < stats[j].NodeID
+		}
+		return stats[i].EntryID < stats[j].EntryID
+	})
+
+	return stats
+}
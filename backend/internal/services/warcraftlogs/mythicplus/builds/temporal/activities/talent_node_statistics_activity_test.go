@@ -0,0 +1,69 @@
+package warcraftlogsBuildsTemporalActivities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	activities "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/activities"
+)
+
+// TestTalentNodeAggregation checks the node and choice node pick rates
+func TestTalentNodeAggregation(t *testing.T) {
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		{
+			ID: 1, Class: "Priest", Spec: "Discipline", EncounterID: 62286,
+			TalentTree: datatypes.JSON(`[{"id": 103678, "rank": 1, "nodeID": 82710}, {"id": 103700, "rank": 2, "nodeID": 82720}, {"id": 110001, "rank": 1, "nodeID": 90000}]`),
+		},
+		{
+			ID: 2, Class: "Priest", Spec: "Discipline", EncounterID: 62286,
+			TalentTree: datatypes.JSON(`[{"id": 103678, "rank": 1, "nodeID": 82710}, {"id": 103700, "rank": 1, "nodeID": 82720}, {"id": 110002, "rank": 1, "nodeID": 90000}]`),
+		},
+		{
+			ID: 3, Class: "Priest", Spec: "Discipline", EncounterID: 62286,
+			TalentTree: datatypes.JSON(`[{"id": 103678, "rank": 1, "nodeID": 82710}, {"id": 110001, "rank": 1, "nodeID": 90000}]`),
+		},
+		// Ignored: no talent tree
+		{ID: 4, Class: "Priest", Spec: "Discipline", EncounterID: 62286},
+	}
+
+	aggregation := activities.NewTalentNodeAggregation()
+	for _, build := range builds {
+		assert.NoError(t, aggregation.Add(build))
+	}
+
+	assert.Equal(t, 3, aggregation.SampleSize)
+
+	stats := aggregation.Statistics()
+	assert.Len(t, stats, 4)
+
+	// Ordered by node then entry
+	assert.Equal(t, 82710, stats[0].NodeID)
+	assert.Equal(t, 3, stats[0].PickCount)
+	assert.InDelta(t, 100.0, stats[0].PickRate, 0.001)
+	assert.Equal(t, "Priest", stats[0].Class)
+	assert.Equal(t, uint(62286), stats[0].EncounterID)
+
+	assert.Equal(t, 82720, stats[1].NodeID)
+	assert.Equal(t, 2, stats[1].PickCount)
+	assert.InDelta(t, 66.667, stats[1].PickRate, 0.001)
+	assert.InDelta(t, 1.5, stats[1].AvgRank, 0.001)
+
+	// Choice node: both entries are kept
+	assert.Equal(t, 90000, stats[2].NodeID)
+	assert.Equal(t, 110001, stats[2].EntryID)
+	assert.Equal(t, 2, stats[2].PickCount)
+	assert.Equal(t, 110002, stats[3].EntryID)
+	assert.Equal(t, 1, stats[3].PickCount)
+	assert.Equal(t, 3, stats[3].SampleSize)
+}
+
+// TestTalentNodeAggregation_InvalidJSON checks that a malformed talent tree is reported
+func TestTalentNodeAggregation_InvalidJSON(t *testing.T) {
+	aggregation := activities.NewTalentNodeAggregation()
+	err := aggregation.Add(&warcraftlogsBuilds.PlayerBuild{ID: 1, TalentTree: datatypes.JSON(`{"broken"`)})
+	assert.Error(t, err)
+	assert.Empty(t, aggregation.Statistics())
+}
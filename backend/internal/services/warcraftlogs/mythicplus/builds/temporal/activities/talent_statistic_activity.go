@@ -32,10 +32,12 @@ func NewTalentStatisticActivity(
 }
 
 // ProcessTalentStatistics analyzes talent configurations for a specific class, spec and dungeon
+// difficulty is the raid difficulty of a raid boss, 0 for a Mythic+ dungeon.
+// Segments and weekly snapshots are Mythic+ analytics, they are skipped for the raid bosses.
 func (a *TalentStatisticActivity) ProcessTalentStatistics(
 	ctx context.Context,
 	class, spec string,
-	encounterID uint,
+	encounterID, difficulty uint,
 	batchSize int,
 ) (*workflowsModels.TalentAnalysisWorkflowResult, error) {
 	logger := activity.GetLogger(ctx)
@@ -44,12 +46,12 @@ func (a *TalentStatisticActivity) ProcessTalentStatistics(
 	}
 
 	// 1. Delete existing statistics
-	if err := a.talentStatisticsRepository.DeleteTalentStatistics(ctx, class, spec, encounterID); err != nil {
+	if err := a.talentStatisticsRepository.DeleteTalentStatistics(ctx, class, spec, encounterID, difficulty); err != nil {
 		return nil, fmt.Errorf("failed to delete existing talent statistics: %w", err)
 	}
 
 	// 2. Get the total number of builds to process
-	count, err := a.playerBuildsRepository.CountPlayerBuildsNeedingTalentAnalysis(ctx, class, spec, encounterID)
+	count, err := a.playerBuildsRepository.CountPlayerBuildsNeedingTalentAnalysis(ctx, class, spec, encounterID, difficulty)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +72,9 @@ func (a *TalentStatisticActivity) ProcessTalentStatistics(
 	// For storing the IDs of successfully processed builds
 	processedBuildIDs := make([]uint, 0)
 
+	// Talent import usage per patch, keystone level and affix set
+	talentSegments := make(map[string]*warcraftlogsBuilds.TalentStatisticSegment)
+
 	for offset < int(count) {
 		// Record heartbeat
 		activity.RecordHeartbeat(ctx, map[string]interface{}{
@@ -83,7 +88,7 @@ func (a *TalentStatisticActivity) ProcessTalentStatistics(
 
 		// Get a batch of builds
 		builds, err := a.playerBuildsRepository.GetPlayerBuildsNeedingTalentAnalysis(
-			ctx, class, spec, encounterID, batchSize, offset)
+			ctx, class, spec, encounterID, difficulty, batchSize, offset)
 		if err != nil {
 			return nil, err
 		}
@@ -109,6 +114,9 @@ func (a *TalentStatisticActivity) ProcessTalentStatistics(
 			return nil, err
 		}
 
+		// Segment the batch by patch, keystone level and affix set
+		a.AggregateTalentSegments(builds, talentSegments)
+
 		// Calculate the usage percentages
 		a.CalculateUsagePercentages(talentStats, len(builds))
 
@@ -151,6 +159,27 @@ func (a *TalentStatisticActivity) ProcessTalentStatistics(
 		}
 	}
 
+	// Persist the statistics segmented by patch, keystone level and affix set, for the Mythic+ dungeons only
+	if difficulty == 0 {
+		if err := a.talentStatisticsRepository.ReplaceTalentStatisticSegments(
+			ctx, class, spec, encounterID, a.FinalizeTalentSegments(talentSegments)); err != nil {
+			return nil, fmt.Errorf("failed to store talent statistic segments: %w", err)
+		}
+	}
+
+	// Keep a copy of the statistics for the weekly trends
+	if totalTalentConfigs > 0 && difficulty == 0 {
+		periodStart := warcraftlogsBuilds.WeeklyResetPeriod(time.Now())
+		if err := a.talentStatisticsRepository.SnapshotTalentStatistics(ctx, class, spec, encounterID, periodStart); err != nil {
+			logger.Error("Failed to snapshot talent statistics",
+				"error", err,
+				"class", class,
+				"spec", spec,
+				"encounter", encounterID)
+			// Continue despite the error
+		}
+	}
+
 	result.TotalBuilds = int32(totalProcessed)
 	result.TalentsAnalyzed = int32(totalTalentConfigs)
 	result.CompletedAt = time.Now()
@@ -231,6 +260,7 @@ func (a *TalentStatisticActivity) ProcessTalentsBatch(
 						Class:        build.Class,
 						Spec:         build.Spec,
 						EncounterID:  build.EncounterID,
+						Difficulty:   build.Difficulty,
 						TalentImport: build.TalentImport,
 						// Initialize the fields for min/max item level and keystone level
 						MinItemLevel:     build.ItemLevel,
@@ -317,3 +347,50 @@ func (a *TalentStatisticActivity) CalculateUsagePercentages(
 		stat.UsagePercentage = float64(stat.UsageCount) / float64(totalBuilds) * 100
 	}
 }
+
+// AggregateTalentSegments adds the talent imports of a batch of builds to the segments of their patch, keystone level and affix set
+// The item level of the builds is summed in AvgItemLevel, FinalizeTalentSegments turns the sums into averages.
+func (a *TalentStatisticActivity) AggregateTalentSegments(
+	builds []*warcraftlogsBuilds.PlayerBuild,
+	segments map[string]*warcraftlogsBuilds.TalentStatisticSegment,
+) {
+	for _, build := range builds {
+		// Check that talent_import is not empty
+		if build.TalentImport == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_%d_%s",
+			warcraftlogsBuilds.StatisticSegmentKey(build.Patch, build.KeystoneLevel, build.Affixes), build.EncounterID, build.TalentImport)
+		segment, exists := segments[key]
+		if !exists {
+			segment = &warcraftlogsBuilds.TalentStatisticSegment{
+				Class:         build.Class,
+				Spec:          build.Spec,
+				EncounterID:   build.EncounterID,
+				KeystoneLevel: build.KeystoneLevel,
+				Affixes:       warcraftlogsBuilds.SortedAffixes(build.Affixes),
+				Patch:         build.Patch,
+				TalentImport:  build.TalentImport,
+			}
+			segments[key] = segment
+		}
+
+		segment.UsageCount++
+		segment.AvgItemLevel += build.ItemLevel
+	}
+}
+
+// FinalizeTalentSegments converts the aggregated talent segments to a slice and computes their averages
+func (a *TalentStatisticActivity) FinalizeTalentSegments(
+	segments map[string]*warcraftlogsBuilds.TalentStatisticSegment,
+) []*warcraftlogsBuilds.TalentStatisticSegment {
+	result := make([]*warcraftlogsBuilds.TalentStatisticSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.UsageCount > 0 {
+			segment.AvgItemLevel /= float64(segment.UsageCount)
+		}
+		result = append(result, segment)
+	}
+	return result
+}
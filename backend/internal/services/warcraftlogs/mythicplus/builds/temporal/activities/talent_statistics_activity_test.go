@@ -194,3 +194,34 @@ func TestMultipleTalentBuilds(t *testing.T) {
 
 	t.Log("End of TestMultipleTalentBuilds")
 }
+
+// TestAggregateTalentSegments checks that the talent imports are segmented by patch, keystone level and affix set
+func TestAggregateTalentSegments(t *testing.T) {
+	builds := []*warcraftlogsBuilds.PlayerBuild{
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 636, Affixes: pq.Int64Array{10, 152}, Patch: "11.1.0", TalentImport: "BUILD_A"},
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 640, Affixes: pq.Int64Array{152, 10}, Patch: "11.1.0", TalentImport: "BUILD_A"},
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 640, Affixes: pq.Int64Array{9, 152}, Patch: "11.1.0", TalentImport: "BUILD_A"},
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 630, Affixes: pq.Int64Array{10, 152}, Patch: "11.1.0", TalentImport: "BUILD_B"},
+		// Builds without talent import are ignored
+		{Class: "Priest", Spec: "Discipline", EncounterID: 62286, KeystoneLevel: 12, ItemLevel: 630, Affixes: pq.Int64Array{10, 152}},
+	}
+
+	activity := &activities.TalentStatisticActivity{}
+	segments := make(map[string]*warcraftlogsBuilds.TalentStatisticSegment)
+	activity.AggregateTalentSegments(builds, segments)
+
+	result := activity.FinalizeTalentSegments(segments)
+	assert.Len(t, result, 3)
+
+	usage := make(map[string]int)
+	for _, segment := range result {
+		usage[segment.TalentImport+" "+warcraftlogsBuilds.StatisticSegmentKey(segment.Patch, segment.KeystoneLevel, segment.Affixes)] = segment.UsageCount
+		if segment.TalentImport == "BUILD_A" && segment.UsageCount == 2 {
+			assert.Equal(t, 638.0, segment.AvgItemLevel)
+		}
+	}
+
+	assert.Equal(t, 2, usage["BUILD_A 11.1.0|12|10,152"])
+	assert.Equal(t, 1, usage["BUILD_A 11.1.0|12|9,152"])
+	assert.Equal(t, 1, usage["BUILD_B 11.1.0|12|10,152"])
+}
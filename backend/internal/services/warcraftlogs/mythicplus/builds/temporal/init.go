@@ -3,16 +3,30 @@ package temporal
 
 import (
 	"wowperf/internal/services/warcraftlogs"
+	wclAuth "wowperf/internal/services/warcraftlogs/auth"
 
 	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
 	"gorm.io/gorm"
 
+	// Archive store
+	archive "wowperf/internal/services/warcraftlogs/mythicplus/builds/archive"
+
 	// Repositories
+	abilityUsageRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	buildValidationRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	buildsStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	damageTakenStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	deathStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	groupCompositionRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	performanceStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	playerBuildsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	playerIdentityRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	rankingsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	reportAnalysisJobRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	reportArchiveRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	reportsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
+	runPacingRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	statStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	talentStatisticsRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
 	workflowStatesRepository "wowperf/internal/services/warcraftlogs/mythicplus/builds/repository"
@@ -25,15 +39,23 @@ import (
 
 	// Workflows
 	buildsWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds"
+	abilityUsageWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/ability_usage"
+	damageTakenAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/damage_taken_statistics"
+	deathAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/death_statistics"
 	equipmentAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/equipment_statistics"
+	performanceAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/performance_statistics"
+	statWeightsWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/stat_weights"
 	statAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/stats_statistics"
 	talentAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/builds_statistics/talent_statistics"
 	rankingsWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/rankings"
+	reportAnalysisWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/report_analysis"
+	reportRetentionWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/report_retention"
 	reportsWorkflow "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/reports"
 )
 
 // InitBuilds initialise les repositories et activités pour la feature builds
-func InitBuilds(db *gorm.DB, warcraftLogsClient *warcraftlogs.WarcraftLogsClientService, maxRankingsPerSpec int) (
+// archiveStore reçoit les archives des rapports compactés par le workflow de rétention
+func InitBuilds(db *gorm.DB, warcraftLogsClient *warcraftlogs.WarcraftLogsClientService, maxRankingsPerSpec int, archiveStore archive.ObjectStore) (
 	*reportsRepository.ReportRepository,
 	*rankingsRepository.RankingsRepository,
 	*playerBuildsRepository.PlayerBuildsRepository,
@@ -51,11 +73,28 @@ func InitBuilds(db *gorm.DB, warcraftLogsClient *warcraftlogs.WarcraftLogsClient
 	talentStatsRepo := talentStatisticsRepository.NewTalentStatisticsRepository(db)
 	statStatsRepo := statStatisticsRepository.NewStatStatisticsRepository(db)
 	workflowStatesRepo := workflowStatesRepository.NewWorkflowStateRepository(db)
+	deathStatsRepo := deathStatisticsRepository.NewDeathStatisticsRepository(db)
+	performanceStatsRepo := performanceStatisticsRepository.NewPerformanceStatisticsRepository(db)
+	damageTakenStatsRepo := damageTakenStatisticsRepository.NewDamageTakenStatisticsRepository(db)
+	groupCompositionRepo := groupCompositionRepository.NewGroupCompositionRepository(db)
+	reportAnalysisJobRepo := reportAnalysisJobRepository.NewReportAnalysisJobRepository(db)
+	raidEncounterRepo := rankingsRepository.NewRaidEncounterRepository(db)
+	itemLiftStatsRepo := buildsStatisticsRepository.NewItemLiftStatisticsRepository(db)
+	statWeightStatsRepo := statStatisticsRepository.NewStatWeightStatisticsRepository(db)
+	reportArchiveRepo := reportArchiveRepository.NewReportArchiveRepository(db)
+	abilityUsageRepo := abilityUsageRepository.NewAbilityUsageRepository(db)
+	runPacingRepo := runPacingRepository.NewRunPacingRepository(db)
+	buildValidationRepo := buildValidationRepository.NewBuildValidationRepository(db)
+	playerIdentityRepo := playerIdentityRepository.NewPlayerIdentityRepository(db)
+
+	// Service d'authentification WarcraftLogs pour les rapports privés
+	// Redis n'est utilisé que pour le flow OAuth, qui n'a pas lieu dans le worker
+	warcraftLogsAuthService := wclAuth.NewWarcraftLogsAuthService(db, nil)
 
 	// Initialiser les activités
-	rankingsActivity := activities.NewRankingsActivity(warcraftLogsClient, rankingsRepo)
-	reportsActivity := activities.NewReportsActivity(warcraftLogsClient, reportsRepo, rankingsRepo)
-	playerBuildsActivity := activities.NewPlayerBuildsActivity(playerBuildsRepo, reportsRepo)
+	rankingsActivity := activities.NewRankingsActivity(warcraftLogsClient, rankingsRepo, raidEncounterRepo)
+	reportsActivity := activities.NewReportsActivity(warcraftLogsClient, reportsRepo, rankingsRepo, groupCompositionRepo, runPacingRepo)
+	playerBuildsActivity := activities.NewPlayerBuildsActivity(playerBuildsRepo, reportsRepo, buildValidationRepo, playerIdentityRepo)
 	rateLimitActivity := activities.NewRateLimitActivity(warcraftLogsClient)
 	workflowStatesActivity := activities.NewWorkflowStateActivity(workflowStatesRepo)
 
@@ -63,6 +102,7 @@ func InitBuilds(db *gorm.DB, warcraftLogsClient *warcraftlogs.WarcraftLogsClient
 	buildsStatisticsActivity := activities.NewBuildsStatisticsActivity(
 		playerBuildsRepo,
 		buildsStatsRepo,
+		itemLiftStatsRepo,
 	)
 	talentStatisticActivity := activities.NewTalentStatisticActivity(
 		playerBuildsRepo,
@@ -71,18 +111,59 @@ func InitBuilds(db *gorm.DB, warcraftLogsClient *warcraftlogs.WarcraftLogsClient
 	statStatisticsActivity := activities.NewStatStatisticsActivity(
 		playerBuildsRepo,
 		statStatsRepo,
+		statWeightStatsRepo,
+	)
+
+	// Activities pour les analyses de combat
+	deathStatisticsActivity := activities.NewDeathStatisticsActivity(
+		reportsRepo,
+		deathStatsRepo,
+	)
+	damageTakenStatisticsActivity := activities.NewDamageTakenStatisticsActivity(
+		reportsRepo,
+		damageTakenStatsRepo,
+	)
+	performanceStatisticsActivity := activities.NewPerformanceStatisticsActivity(
+		reportsRepo,
+		performanceStatsRepo,
+	)
+	abilityUsageActivity := activities.NewAbilityUsageActivity(
+		warcraftLogsClient,
+		abilityUsageRepo,
+	)
+
+	// Activity pour l'analyse des rapports à la demande
+	reportAnalysisActivity := activities.NewReportAnalysisActivity(
+		warcraftLogsClient,
+		warcraftLogsAuthService,
+		reportAnalysisJobRepo,
+		buildsStatsRepo,
+		talentStatsRepo,
+		statStatsRepo,
+	)
+
+	// Activity pour la rétention des rapports
+	reportRetentionActivity := activities.NewReportRetentionActivity(
+		reportArchiveRepo,
+		archiveStore,
 	)
 
 	// Créer le service d'activités
 	activitiesService := &activities.Activities{
-		Rankings:         rankingsActivity,
-		Reports:          reportsActivity,
-		PlayerBuilds:     playerBuildsActivity,
-		RateLimit:        rateLimitActivity,
-		BuildStatistics:  buildsStatisticsActivity,
-		TalentStatistics: talentStatisticActivity,
-		StatStatistics:   statStatisticsActivity,
-		WorkflowState:    workflowStatesActivity,
+		Rankings:              rankingsActivity,
+		Reports:               reportsActivity,
+		PlayerBuilds:          playerBuildsActivity,
+		RateLimit:             rateLimitActivity,
+		BuildStatistics:       buildsStatisticsActivity,
+		TalentStatistics:      talentStatisticActivity,
+		StatStatistics:        statStatisticsActivity,
+		DeathStatistics:       deathStatisticsActivity,
+		DamageTakenStatistics: damageTakenStatisticsActivity,
+		PerformanceStatistics: performanceStatisticsActivity,
+		AbilityUsage:          abilityUsageActivity,
+		ReportAnalysis:        reportAnalysisActivity,
+		ReportRetention:       reportRetentionActivity,
+		WorkflowState:         workflowStatesActivity,
 	}
 
 	return reportsRepo, rankingsRepo, playerBuildsRepo, buildsStatsRepo, talentStatsRepo, statStatsRepo, workflowStatesRepo, activitiesService
@@ -98,6 +179,14 @@ func RegisterBuilds(w worker.Worker, activitiesService *activities.Activities) {
 	equipmentAnalysisWorkflowImpl := equipmentAnalysisWorkflow.NewEquipmentAnalysisWorkflow()
 	talentAnalysisWorkflowImpl := talentAnalysisWorkflow.NewTalentAnalysisWorkflow()
 	statAnalysisWorkflowImpl := statAnalysisWorkflow.NewStatAnalysisWorkflow()
+	deathAnalysisWorkflowImpl := deathAnalysisWorkflow.NewDeathAnalysisWorkflow()
+	damageTakenAnalysisWorkflowImpl := damageTakenAnalysisWorkflow.NewDamageTakenAnalysisWorkflow()
+	performanceAnalysisWorkflowImpl := performanceAnalysisWorkflow.NewPerformanceAnalysisWorkflow()
+	statWeightsWorkflowImpl := statWeightsWorkflow.NewStatWeightsWorkflow()
+	abilityUsageWorkflowImpl := abilityUsageWorkflow.NewAbilityUsageWorkflow()
+	reportAnalysisWorkflowImpl := reportAnalysisWorkflow.NewReportAnalysisWorkflow()
+	reportRetentionWorkflowImpl := reportRetentionWorkflow.NewReportRetentionWorkflow()
+	reportRestoreWorkflowImpl := reportRetentionWorkflow.NewReportRestoreWorkflow()
 
 	// Enregistrer les workflows
 	w.RegisterWorkflowWithOptions(rankingsWorkflowImpl.Execute, workflow.RegisterOptions{
@@ -121,12 +210,38 @@ func RegisterBuilds(w worker.Worker, activitiesService *activities.Activities) {
 	w.RegisterWorkflowWithOptions(statAnalysisWorkflowImpl.Execute, workflow.RegisterOptions{
 		Name: definitions.AnalyzeStatStatisticsWorkflowName,
 	})
+	w.RegisterWorkflowWithOptions(deathAnalysisWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.AnalyzeDeathsWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(damageTakenAnalysisWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.AnalyzeDamageTakenWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(performanceAnalysisWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.AnalyzePerformanceWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(statWeightsWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.AnalyzeStatWeightsWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(abilityUsageWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.AnalyzeAbilityUsageWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(reportAnalysisWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.ReportAnalysisWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(reportRetentionWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.ReportRetentionWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(reportRestoreWorkflowImpl.Execute, workflow.RegisterOptions{
+		Name: definitions.ReportRestoreWorkflowName,
+	})
 
 	// Enregistrer les activities
 	// Rankings activities
 	w.RegisterActivity(activitiesService.Rankings.FetchAndStore)
+	w.RegisterActivity(activitiesService.Rankings.FetchAndStoreBatch)
 	w.RegisterActivity(activitiesService.Rankings.GetStoredRankings)
 	w.RegisterActivity(activitiesService.Rankings.MarkRankingsForReportProcessing)
+	w.RegisterActivity(activitiesService.Rankings.StoreRaidEncounters)
 
 	// Reports activities
 	w.RegisterActivity(activitiesService.Reports.ProcessReports)
@@ -150,8 +265,29 @@ func RegisterBuilds(w worker.Worker, activitiesService *activities.Activities) {
 
 	// Build statistics activities
 	w.RegisterActivity(activitiesService.BuildStatistics.ProcessItemStatistics)
+	w.RegisterActivity(activitiesService.BuildStatistics.ProcessItemLiftStatistics)
 	w.RegisterActivity(activitiesService.TalentStatistics.ProcessTalentStatistics)
+	w.RegisterActivity(activitiesService.TalentStatistics.ProcessTalentNodeStatistics)
 	w.RegisterActivity(activitiesService.StatStatistics.ProcessStatStatistics)
+	w.RegisterActivity(activitiesService.StatStatistics.ProcessStatWeights)
+
+	// Combat analysis activities
+	w.RegisterActivity(activitiesService.DeathStatistics.ProcessDeathStatistics)
+	w.RegisterActivity(activitiesService.DamageTakenStatistics.ProcessDamageTakenStatistics)
+	w.RegisterActivity(activitiesService.PerformanceStatistics.ProcessPerformanceStatistics)
+
+	// Ability usage activities
+	w.RegisterActivity(activitiesService.AbilityUsage.FetchAbilityUsages)
+	w.RegisterActivity(activitiesService.AbilityUsage.ProcessAbilityUsageStatistics)
+
+	// Report analysis activities
+	w.RegisterActivity(activitiesService.ReportAnalysis.AnalyzeReport)
+	w.RegisterActivity(activitiesService.ReportAnalysis.FailReportAnalysis)
+
+	// Report retention activities
+	w.RegisterActivity(activitiesService.ReportRetention.ArchiveReports)
+	w.RegisterActivity(activitiesService.ReportRetention.RestoreReports)
+	w.RegisterActivity(activitiesService.ReportRetention.GetRestoredReports)
 
 	// Workflow state activities
 	w.RegisterActivity(activitiesService.WorkflowState.CreateWorkflowState)
@@ -120,6 +120,90 @@ func InitBuildsSchedules(ctx context.Context, scheduleManager *ScheduleManager,
 
 	logger.Printf("[INFO] Successfully created stat analysis schedule with batch ID: %s", statAnalysisParams.BatchID)
 
+	// 7. Schedule for DeathAnalysisWorkflow
+	deathAnalysisParams, err := definitions.LoadDeathAnalysisParams(configPath)
+	if err != nil {
+		logger.Printf("[ERROR] Failed to load death analysis params: %v", err)
+		return err
+	}
+
+	if err := scheduleManager.CreateDeathAnalysisSchedule(ctx, deathAnalysisParams, opts); err != nil {
+		logger.Printf("[ERROR] Failed to create death analysis schedule: %v", err)
+		return err
+	}
+
+	logger.Printf("[INFO] Successfully created death analysis schedule with batch ID: %s", deathAnalysisParams.BatchID)
+
+	// 8. Schedule for DamageTakenAnalysisWorkflow
+	damageTakenAnalysisParams, err := definitions.LoadDamageTakenAnalysisParams(configPath)
+	if err != nil {
+		logger.Printf("[ERROR] Failed to load damage taken analysis params: %v", err)
+		return err
+	}
+
+	if err := scheduleManager.CreateDamageTakenAnalysisSchedule(ctx, damageTakenAnalysisParams, opts); err != nil {
+		logger.Printf("[ERROR] Failed to create damage taken analysis schedule: %v", err)
+		return err
+	}
+
+	logger.Printf("[INFO] Successfully created damage taken analysis schedule with batch ID: %s", damageTakenAnalysisParams.BatchID)
+
+	// 9. Schedule for PerformanceAnalysisWorkflow
+	performanceAnalysisParams, err := definitions.LoadPerformanceAnalysisParams(configPath)
+	if err != nil {
+		logger.Printf("[ERROR] Failed to load performance analysis params: %v", err)
+		return err
+	}
+
+	if err := scheduleManager.CreatePerformanceAnalysisSchedule(ctx, performanceAnalysisParams, opts); err != nil {
+		logger.Printf("[ERROR] Failed to create performance analysis schedule: %v", err)
+		return err
+	}
+
+	logger.Printf("[INFO] Successfully created performance analysis schedule with batch ID: %s", performanceAnalysisParams.BatchID)
+
+	// 10. Schedule for StatWeightsWorkflow
+	statWeightsParams, err := definitions.LoadStatWeightsParams(configPath)
+	if err != nil {
+		logger.Printf("[ERROR] Failed to load stat weights params: %v", err)
+		return err
+	}
+
+	if err := scheduleManager.CreateStatWeightsSchedule(ctx, statWeightsParams, opts); err != nil {
+		logger.Printf("[ERROR] Failed to create stat weights schedule: %v", err)
+		return err
+	}
+
+	logger.Printf("[INFO] Successfully created stat weights schedule with batch ID: %s", statWeightsParams.BatchID)
+
+	// 11. Schedule for ReportRetentionWorkflow
+	reportRetentionParams, err := definitions.LoadReportRetentionParams(configPath)
+	if err != nil {
+		logger.Printf("[ERROR] Failed to load report retention params: %v", err)
+		return err
+	}
+
+	if err := scheduleManager.CreateReportRetentionSchedule(ctx, reportRetentionParams, opts); err != nil {
+		logger.Printf("[ERROR] Failed to create report retention schedule: %v", err)
+		return err
+	}
+
+	logger.Printf("[INFO] Successfully created report retention schedule with batch ID: %s", reportRetentionParams.BatchID)
+
+	// 12. Schedule for AbilityUsageWorkflow
+	abilityUsageParams, err := definitions.LoadAbilityUsageParams(configPath)
+	if err != nil {
+		logger.Printf("[ERROR] Failed to load ability usage params: %v", err)
+		return err
+	}
+
+	if err := scheduleManager.CreateAbilityUsageSchedule(ctx, abilityUsageParams, opts); err != nil {
+		logger.Printf("[ERROR] Failed to create ability usage schedule: %v", err)
+		return err
+	}
+
+	logger.Printf("[INFO] Successfully created ability usage schedule with batch ID: %s", abilityUsageParams.BatchID)
+
 	return nil
 }
 
@@ -132,4 +216,11 @@ func LogBuildsManualTriggerInstructions(logger *log.Logger) {
 	logger.Printf("[INFO] - Equipment Analysis: scheduleManager.TriggerEquipmentAnalysisNow(ctx)")
 	logger.Printf("[INFO] - Talent Analysis: scheduleManager.TriggerTalentAnalysisNow(ctx)")
 	logger.Printf("[INFO] - Stat Analysis: scheduleManager.TriggerStatAnalysisNow(ctx)")
+	logger.Printf("[INFO] - Death Analysis: scheduleManager.TriggerDeathAnalysisNow(ctx)")
+	logger.Printf("[INFO] - Damage Taken Analysis: scheduleManager.TriggerDamageTakenAnalysisNow(ctx)")
+	logger.Printf("[INFO] - Performance Analysis: scheduleManager.TriggerPerformanceAnalysisNow(ctx)")
+	logger.Printf("[INFO] - Stat Weights: scheduleManager.TriggerStatWeightsNow(ctx)")
+	logger.Printf("[INFO] - Report Retention: scheduleManager.TriggerReportRetentionNow(ctx)")
+	logger.Printf("[INFO] - Report Restore: scheduleManager.RestoreReportsNow(ctx, params)")
+	logger.Printf("[INFO] - Ability Usage: scheduleManager.TriggerAbilityUsageNow(ctx)")
 }
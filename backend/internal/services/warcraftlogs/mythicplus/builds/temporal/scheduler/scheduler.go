@@ -21,12 +21,18 @@ import (
 const (
 
 	// New schedules for decoupled workflows
-	rankingsScheduleID          = "warcraft-logs-rankings"
-	reportsScheduleID           = "warcraft-logs-reports" // Legacy schedule, will be replace by a schedule per class
-	buildsScheduleID            = "warcraft-logs-builds"
-	equipmentAnalysisScheduleID = "warcraft-logs-equipment-analysis"
-	talentAnalysisScheduleID    = "warcraft-logs-talent-analysis"
-	statAnalysisScheduleID      = "warcraft-logs-stat-analysis"
+	rankingsScheduleID            = "warcraft-logs-rankings"
+	reportsScheduleID             = "warcraft-logs-reports" // Legacy schedule, will be replace by a schedule per class
+	buildsScheduleID              = "warcraft-logs-builds"
+	equipmentAnalysisScheduleID   = "warcraft-logs-equipment-analysis"
+	talentAnalysisScheduleID      = "warcraft-logs-talent-analysis"
+	statAnalysisScheduleID        = "warcraft-logs-stat-analysis"
+	deathAnalysisScheduleID       = "warcraft-logs-death-analysis"
+	damageTakenAnalysisScheduleID = "warcraft-logs-damage-taken-analysis"
+	performanceAnalysisScheduleID = "warcraft-logs-performance-analysis"
+	statWeightsScheduleID         = "warcraft-logs-stat-weights"
+	reportRetentionScheduleID     = "warcraft-logs-report-retention"
+	abilityUsageScheduleID        = "warcraft-logs-ability-usage"
 )
 
 // ScheduleManager manages Temporal schedules for WarcraftLogs workflows
@@ -35,12 +41,18 @@ type ScheduleManager struct {
 	logger *log.Logger
 
 	// New handles (decoupled workflows, will be used instead of the existing ones)
-	rankingsSchedule          client.ScheduleHandle
-	reportsSchedule           client.ScheduleHandle // Legacy schedule, will be replace by a schedule per class
-	buildsSchedule            client.ScheduleHandle
-	equipmentAnalysisSchedule client.ScheduleHandle
-	talentAnalysisSchedule    client.ScheduleHandle
-	statAnalysisSchedule      client.ScheduleHandle
+	rankingsSchedule            client.ScheduleHandle
+	reportsSchedule             client.ScheduleHandle // Legacy schedule, will be replace by a schedule per class
+	buildsSchedule              client.ScheduleHandle
+	equipmentAnalysisSchedule   client.ScheduleHandle
+	talentAnalysisSchedule      client.ScheduleHandle
+	statAnalysisSchedule        client.ScheduleHandle
+	deathAnalysisSchedule       client.ScheduleHandle
+	damageTakenAnalysisSchedule client.ScheduleHandle
+	performanceAnalysisSchedule client.ScheduleHandle
+	statWeightsSchedule         client.ScheduleHandle
+	reportRetentionSchedule     client.ScheduleHandle
+	abilityUsageSchedule        client.ScheduleHandle
 
 	// New map for per class reports schedules
 	reportsSchedules map[string]client.ScheduleHandle
@@ -359,6 +371,240 @@ func (sm *ScheduleManager) CreateStatAnalysisSchedule(ctx context.Context, param
 	return nil
 }
 
+// CreateDeathAnalysisSchedule creates the death analysis workflow schedule
+func (sm *ScheduleManager) CreateDeathAnalysisSchedule(ctx context.Context, params *models.DeathAnalysisWorkflowParams, opts *ScheduleOptions) error {
+	if opts == nil {
+		opts = DefaultScheduleOptions()
+	}
+
+	scheduleID := deathAnalysisScheduleID
+	workflowID := fmt.Sprintf("warcraft-logs-death-analysis-%s", time.Now().UTC().Format("2006-01-02"))
+
+	// Create the schedule without automatic triggering (No CRON expressions)
+	scheduleOptions := client.ScheduleOptions{
+		ID: scheduleID,
+		// No CronExpressions to avoid automatic triggering
+		Action: &client.ScheduleWorkflowAction{
+			ID:        workflowID,
+			Workflow:  definitions.AnalyzeDeathsWorkflowName,
+			TaskQueue: DefaultScheduleConfig.TaskQueue,
+			Args:      []interface{}{params},
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:    opts.Retry.InitialInterval,
+				BackoffCoefficient: opts.Retry.BackoffCoefficient,
+				MaximumInterval:    opts.Retry.MaximumInterval,
+				MaximumAttempts:    int32(opts.Retry.MaximumAttempts),
+			},
+			WorkflowRunTimeout: opts.Timeout,
+		},
+		Paused: opts.Paused, // Paused by default if specified in options
+	}
+
+	handle, err := sm.client.ScheduleClient().Create(ctx, scheduleOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create death analysis schedule: %w", err)
+	}
+
+	sm.deathAnalysisSchedule = handle
+	sm.logger.Printf("[INFO] Created death analysis workflow schedule: %s", scheduleID)
+	return nil
+}
+
+// CreateDamageTakenAnalysisSchedule creates the damage taken analysis workflow schedule
+func (sm *ScheduleManager) CreateDamageTakenAnalysisSchedule(ctx context.Context, params *models.DamageTakenAnalysisWorkflowParams, opts *ScheduleOptions) error {
+	if opts == nil {
+		opts = DefaultScheduleOptions()
+	}
+
+	scheduleID := damageTakenAnalysisScheduleID
+	workflowID := fmt.Sprintf("warcraft-logs-damage-taken-analysis-%s", time.Now().UTC().Format("2006-01-02"))
+
+	// Create the schedule without automatic triggering (No CRON expressions)
+	scheduleOptions := client.ScheduleOptions{
+		ID: scheduleID,
+		// No CronExpressions to avoid automatic triggering
+		Action: &client.ScheduleWorkflowAction{
+			ID:        workflowID,
+			Workflow:  definitions.AnalyzeDamageTakenWorkflowName,
+			TaskQueue: DefaultScheduleConfig.TaskQueue,
+			Args:      []interface{}{params},
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:    opts.Retry.InitialInterval,
+				BackoffCoefficient: opts.Retry.BackoffCoefficient,
+				MaximumInterval:    opts.Retry.MaximumInterval,
+				MaximumAttempts:    int32(opts.Retry.MaximumAttempts),
+			},
+			WorkflowRunTimeout: opts.Timeout,
+		},
+		Paused: opts.Paused, // Paused by default if specified in options
+	}
+
+	handle, err := sm.client.ScheduleClient().Create(ctx, scheduleOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create damage taken analysis schedule: %w", err)
+	}
+
+	sm.damageTakenAnalysisSchedule = handle
+	sm.logger.Printf("[INFO] Created damage taken analysis workflow schedule: %s", scheduleID)
+	return nil
+}
+
+// CreatePerformanceAnalysisSchedule creates the performance analysis workflow schedule
+func (sm *ScheduleManager) CreatePerformanceAnalysisSchedule(ctx context.Context, params *models.PerformanceAnalysisWorkflowParams, opts *ScheduleOptions) error {
+	if opts == nil {
+		opts = DefaultScheduleOptions()
+	}
+
+	scheduleID := performanceAnalysisScheduleID
+	workflowID := fmt.Sprintf("warcraft-logs-performance-analysis-%s", time.Now().UTC().Format("2006-01-02"))
+
+	// Create the schedule without automatic triggering (No CRON expressions)
+	scheduleOptions := client.ScheduleOptions{
+		ID: scheduleID,
+		// No CronExpressions to avoid automatic triggering
+		Action: &client.ScheduleWorkflowAction{
+			ID:        workflowID,
+			Workflow:  definitions.AnalyzePerformanceWorkflowName,
+			TaskQueue: DefaultScheduleConfig.TaskQueue,
+			Args:      []interface{}{params},
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:    opts.Retry.InitialInterval,
+				BackoffCoefficient: opts.Retry.BackoffCoefficient,
+				MaximumInterval:    opts.Retry.MaximumInterval,
+				MaximumAttempts:    int32(opts.Retry.MaximumAttempts),
+			},
+			WorkflowRunTimeout: opts.Timeout,
+		},
+		Paused: opts.Paused, // Paused by default if specified in options
+	}
+
+	handle, err := sm.client.ScheduleClient().Create(ctx, scheduleOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create performance analysis schedule: %w", err)
+	}
+
+	sm.performanceAnalysisSchedule = handle
+	sm.logger.Printf("[INFO] Created performance analysis workflow schedule: %s", scheduleID)
+	return nil
+}
+
+// CreateStatWeightsSchedule creates the stat weights workflow schedule
+func (sm *ScheduleManager) CreateStatWeightsSchedule(ctx context.Context, params *models.StatWeightsWorkflowParams, opts *ScheduleOptions) error {
+	if opts == nil {
+		opts = DefaultScheduleOptions()
+	}
+
+	scheduleID := statWeightsScheduleID
+	workflowID := fmt.Sprintf("warcraft-logs-stat-weights-%s", time.Now().UTC().Format("2006-01-02"))
+
+	// Create the schedule without automatic triggering (No CRON expressions)
+	scheduleOptions := client.ScheduleOptions{
+		ID: scheduleID,
+		// No CronExpressions to avoid automatic triggering
+		Action: &client.ScheduleWorkflowAction{
+			ID:        workflowID,
+			Workflow:  definitions.AnalyzeStatWeightsWorkflowName,
+			TaskQueue: DefaultScheduleConfig.TaskQueue,
+			Args:      []interface{}{params},
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:    opts.Retry.InitialInterval,
+				BackoffCoefficient: opts.Retry.BackoffCoefficient,
+				MaximumInterval:    opts.Retry.MaximumInterval,
+				MaximumAttempts:    int32(opts.Retry.MaximumAttempts),
+			},
+			WorkflowRunTimeout: opts.Timeout,
+		},
+		Paused: opts.Paused, // Paused by default if specified in options
+	}
+
+	handle, err := sm.client.ScheduleClient().Create(ctx, scheduleOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create stat weights schedule: %w", err)
+	}
+
+	sm.statWeightsSchedule = handle
+	sm.logger.Printf("[INFO] Created stat weights workflow schedule: %s", scheduleID)
+	return nil
+}
+
+// CreateReportRetentionSchedule creates the report retention workflow schedule
+func (sm *ScheduleManager) CreateReportRetentionSchedule(ctx context.Context, params *models.ReportRetentionWorkflowParams, opts *ScheduleOptions) error {
+	if opts == nil {
+		opts = DefaultScheduleOptions()
+	}
+
+	scheduleID := reportRetentionScheduleID
+	workflowID := fmt.Sprintf("warcraft-logs-report-retention-%s", time.Now().UTC().Format("2006-01-02"))
+
+	// Create the schedule without automatic triggering (No CRON expressions)
+	scheduleOptions := client.ScheduleOptions{
+		ID: scheduleID,
+		// No CronExpressions to avoid automatic triggering
+		Action: &client.ScheduleWorkflowAction{
+			ID:        workflowID,
+			Workflow:  definitions.ReportRetentionWorkflowName,
+			TaskQueue: DefaultScheduleConfig.TaskQueue,
+			Args:      []interface{}{params},
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:    opts.Retry.InitialInterval,
+				BackoffCoefficient: opts.Retry.BackoffCoefficient,
+				MaximumInterval:    opts.Retry.MaximumInterval,
+				MaximumAttempts:    int32(opts.Retry.MaximumAttempts),
+			},
+			WorkflowRunTimeout: opts.Timeout,
+		},
+		Paused: opts.Paused, // Paused by default if specified in options
+	}
+
+	handle, err := sm.client.ScheduleClient().Create(ctx, scheduleOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create report retention schedule: %w", err)
+	}
+
+	sm.reportRetentionSchedule = handle
+	sm.logger.Printf("[INFO] Created report retention workflow schedule: %s", scheduleID)
+	return nil
+}
+
+// CreateAbilityUsageSchedule creates the ability usage analysis workflow schedule
+func (sm *ScheduleManager) CreateAbilityUsageSchedule(ctx context.Context, params *models.AbilityUsageAnalysisWorkflowParams, opts *ScheduleOptions) error {
+	if opts == nil {
+		opts = DefaultScheduleOptions()
+	}
+
+	scheduleID := abilityUsageScheduleID
+	workflowID := fmt.Sprintf("warcraft-logs-ability-usage-%s", time.Now().UTC().Format("2006-01-02"))
+
+	// Create the schedule without automatic triggering (No CRON expressions)
+	scheduleOptions := client.ScheduleOptions{
+		ID: scheduleID,
+		// No CronExpressions to avoid automatic triggering
+		Action: &client.ScheduleWorkflowAction{
+			ID:        workflowID,
+			Workflow:  definitions.AnalyzeAbilityUsageWorkflowName,
+			TaskQueue: DefaultScheduleConfig.TaskQueue,
+			Args:      []interface{}{params},
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:    opts.Retry.InitialInterval,
+				BackoffCoefficient: opts.Retry.BackoffCoefficient,
+				MaximumInterval:    opts.Retry.MaximumInterval,
+				MaximumAttempts:    int32(opts.Retry.MaximumAttempts),
+			},
+			WorkflowRunTimeout: opts.Timeout,
+		},
+		Paused: opts.Paused, // Paused by default if specified in options
+	}
+
+	handle, err := sm.client.ScheduleClient().Create(ctx, scheduleOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create ability usage schedule: %w", err)
+	}
+
+	sm.abilityUsageSchedule = handle
+	sm.logger.Printf("[INFO] Created ability usage workflow schedule: %s", scheduleID)
+	return nil
+}
+
 // == Triggering of schedules ==
 
 // TriggerRankingsNow triggers the immediate execution of the rankings schedule
@@ -424,6 +670,73 @@ func (sm *ScheduleManager) TriggerStatAnalysisNow(ctx context.Context) error {
 	return sm.statAnalysisSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
 }
 
+// TriggerDeathAnalysisNow triggers the immediate execution of the death analysis schedule
+func (sm *ScheduleManager) TriggerDeathAnalysisNow(ctx context.Context) error {
+	if sm.deathAnalysisSchedule == nil {
+		return fmt.Errorf("no death analysis schedule has been created")
+	}
+	return sm.deathAnalysisSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
+}
+
+// TriggerDamageTakenAnalysisNow triggers the immediate execution of the damage taken analysis schedule
+func (sm *ScheduleManager) TriggerDamageTakenAnalysisNow(ctx context.Context) error {
+	if sm.damageTakenAnalysisSchedule == nil {
+		return fmt.Errorf("no damage taken analysis schedule has been created")
+	}
+	return sm.damageTakenAnalysisSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
+}
+
+// TriggerPerformanceAnalysisNow triggers the immediate execution of the performance analysis schedule
+func (sm *ScheduleManager) TriggerPerformanceAnalysisNow(ctx context.Context) error {
+	if sm.performanceAnalysisSchedule == nil {
+		return fmt.Errorf("no performance analysis schedule has been created")
+	}
+	return sm.performanceAnalysisSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
+}
+
+// TriggerStatWeightsNow triggers the immediate execution of the stat weights schedule
+func (sm *ScheduleManager) TriggerStatWeightsNow(ctx context.Context) error {
+	if sm.statWeightsSchedule == nil {
+		return fmt.Errorf("no stat weights schedule has been created")
+	}
+	return sm.statWeightsSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
+}
+
+// TriggerReportRetentionNow triggers the immediate execution of the report retention schedule
+func (sm *ScheduleManager) TriggerReportRetentionNow(ctx context.Context) error {
+	if sm.reportRetentionSchedule == nil {
+		return fmt.Errorf("no report retention schedule has been created")
+	}
+	return sm.reportRetentionSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
+}
+
+// TriggerAbilityUsageNow triggers the immediate execution of the ability usage schedule
+func (sm *ScheduleManager) TriggerAbilityUsageNow(ctx context.Context) error {
+	if sm.abilityUsageSchedule == nil {
+		return fmt.Errorf("no ability usage schedule has been created")
+	}
+	return sm.abilityUsageSchedule.Trigger(ctx, client.ScheduleTriggerOptions{})
+}
+
+// RestoreReportsNow starts the restore workflow for archived reports
+// The builds of the restored reports are extracted again when reprocess is set.
+func (sm *ScheduleManager) RestoreReportsNow(ctx context.Context, params models.ReportRestoreWorkflowParams) error {
+	if params.BatchID == "" {
+		params.BatchID = fmt.Sprintf("report-restore-%s", time.Now().UTC().Format("20060102-150405"))
+	}
+
+	_, err := sm.client.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("warcraft-logs-%s", params.BatchID),
+		TaskQueue: DefaultScheduleConfig.TaskQueue,
+	}, definitions.ReportRestoreWorkflowName, params)
+	if err != nil {
+		return fmt.Errorf("failed to start report restore workflow: %w", err)
+	}
+
+	sm.logger.Printf("[INFO] Started report restore workflow for %d reports", len(params.Reports))
+	return nil
+}
+
 // == Pausing and unpausing of schedules ==
 
 // PauseRankingsSchedule pauses the rankings schedule
@@ -483,6 +796,54 @@ func (sm *ScheduleManager) PauseStatAnalysisSchedule(ctx context.Context) error
 	return sm.statAnalysisSchedule.Pause(ctx, client.SchedulePauseOptions{})
 }
 
+// PauseDeathAnalysisSchedule pauses the death analysis schedule
+func (sm *ScheduleManager) PauseDeathAnalysisSchedule(ctx context.Context) error {
+	if sm.deathAnalysisSchedule == nil {
+		return fmt.Errorf("no death analysis schedule has been created")
+	}
+	return sm.deathAnalysisSchedule.Pause(ctx, client.SchedulePauseOptions{})
+}
+
+// PauseDamageTakenAnalysisSchedule pauses the damage taken analysis schedule
+func (sm *ScheduleManager) PauseDamageTakenAnalysisSchedule(ctx context.Context) error {
+	if sm.damageTakenAnalysisSchedule == nil {
+		return fmt.Errorf("no damage taken analysis schedule has been created")
+	}
+	return sm.damageTakenAnalysisSchedule.Pause(ctx, client.SchedulePauseOptions{})
+}
+
+// PausePerformanceAnalysisSchedule pauses the performance analysis schedule
+func (sm *ScheduleManager) PausePerformanceAnalysisSchedule(ctx context.Context) error {
+	if sm.performanceAnalysisSchedule == nil {
+		return fmt.Errorf("no performance analysis schedule has been created")
+	}
+	return sm.performanceAnalysisSchedule.Pause(ctx, client.SchedulePauseOptions{})
+}
+
+// PauseStatWeightsSchedule pauses the stat weights schedule
+func (sm *ScheduleManager) PauseStatWeightsSchedule(ctx context.Context) error {
+	if sm.statWeightsSchedule == nil {
+		return fmt.Errorf("no stat weights schedule has been created")
+	}
+	return sm.statWeightsSchedule.Pause(ctx, client.SchedulePauseOptions{})
+}
+
+// PauseReportRetentionSchedule pauses the report retention schedule
+func (sm *ScheduleManager) PauseReportRetentionSchedule(ctx context.Context) error {
+	if sm.reportRetentionSchedule == nil {
+		return fmt.Errorf("no report retention schedule has been created")
+	}
+	return sm.reportRetentionSchedule.Pause(ctx, client.SchedulePauseOptions{})
+}
+
+// PauseAbilityUsageSchedule pauses the ability usage schedule
+func (sm *ScheduleManager) PauseAbilityUsageSchedule(ctx context.Context) error {
+	if sm.abilityUsageSchedule == nil {
+		return fmt.Errorf("no ability usage schedule has been created")
+	}
+	return sm.abilityUsageSchedule.Pause(ctx, client.SchedulePauseOptions{})
+}
+
 // UnpauseRankingsSchedule reactivates the rankings schedule
 func (sm *ScheduleManager) UnpauseRankingsSchedule(ctx context.Context) error {
 	if sm.rankingsSchedule == nil {
@@ -540,6 +901,54 @@ func (sm *ScheduleManager) UnpauseStatAnalysisSchedule(ctx context.Context) erro
 	return sm.statAnalysisSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
 }
 
+// UnpauseDeathAnalysisSchedule reactivates the death analysis schedule
+func (sm *ScheduleManager) UnpauseDeathAnalysisSchedule(ctx context.Context) error {
+	if sm.deathAnalysisSchedule == nil {
+		return fmt.Errorf("no death analysis schedule has been created")
+	}
+	return sm.deathAnalysisSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
+}
+
+// UnpauseDamageTakenAnalysisSchedule reactivates the damage taken analysis schedule
+func (sm *ScheduleManager) UnpauseDamageTakenAnalysisSchedule(ctx context.Context) error {
+	if sm.damageTakenAnalysisSchedule == nil {
+		return fmt.Errorf("no damage taken analysis schedule has been created")
+	}
+	return sm.damageTakenAnalysisSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
+}
+
+// UnpausePerformanceAnalysisSchedule reactivates the performance analysis schedule
+func (sm *ScheduleManager) UnpausePerformanceAnalysisSchedule(ctx context.Context) error {
+	if sm.performanceAnalysisSchedule == nil {
+		return fmt.Errorf("no performance analysis schedule has been created")
+	}
+	return sm.performanceAnalysisSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
+}
+
+// UnpauseStatWeightsSchedule reactivates the stat weights schedule
+func (sm *ScheduleManager) UnpauseStatWeightsSchedule(ctx context.Context) error {
+	if sm.statWeightsSchedule == nil {
+		return fmt.Errorf("no stat weights schedule has been created")
+	}
+	return sm.statWeightsSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
+}
+
+// UnpauseReportRetentionSchedule reactivates the report retention schedule
+func (sm *ScheduleManager) UnpauseReportRetentionSchedule(ctx context.Context) error {
+	if sm.reportRetentionSchedule == nil {
+		return fmt.Errorf("no report retention schedule has been created")
+	}
+	return sm.reportRetentionSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
+}
+
+// UnpauseAbilityUsageSchedule reactivates the ability usage schedule
+func (sm *ScheduleManager) UnpauseAbilityUsageSchedule(ctx context.Context) error {
+	if sm.abilityUsageSchedule == nil {
+		return fmt.Errorf("no ability usage schedule has been created")
+	}
+	return sm.abilityUsageSchedule.Unpause(ctx, client.ScheduleUnpauseOptions{})
+}
+
 // DeleteSchedule deletes a schedule by its ID
 func (sm *ScheduleManager) DeleteSchedule(ctx context.Context, scheduleID string) error {
 	handle := sm.client.ScheduleClient().GetHandle(ctx, scheduleID)
@@ -549,7 +958,7 @@ func (sm *ScheduleManager) DeleteSchedule(ctx context.Context, scheduleID string
 // CleanupDecoupledSchedules cleans up the decoupled schedules
 func (sm *ScheduleManager) CleanupDecoupledSchedules(ctx context.Context) error {
 	// List and delete decoupled schedules
-	schedules := []string{rankingsScheduleID, reportsScheduleID, buildsScheduleID, equipmentAnalysisScheduleID, talentAnalysisScheduleID, statAnalysisScheduleID}
+	schedules := []string{rankingsScheduleID, reportsScheduleID, buildsScheduleID, equipmentAnalysisScheduleID, talentAnalysisScheduleID, statAnalysisScheduleID, deathAnalysisScheduleID, damageTakenAnalysisScheduleID, performanceAnalysisScheduleID, statWeightsScheduleID, reportRetentionScheduleID, abilityUsageScheduleID}
 	for _, id := range schedules {
 		handle := sm.client.ScheduleClient().GetHandle(ctx, id)
 		if err := handle.Delete(ctx); err != nil {
@@ -579,6 +988,12 @@ func (sm *ScheduleManager) CleanupDecoupledSchedules(ctx context.Context) error
 	sm.equipmentAnalysisSchedule = nil
 	sm.talentAnalysisSchedule = nil
 	sm.statAnalysisSchedule = nil
+	sm.deathAnalysisSchedule = nil
+	sm.damageTakenAnalysisSchedule = nil
+	sm.performanceAnalysisSchedule = nil
+	sm.statWeightsSchedule = nil
+	sm.reportRetentionSchedule = nil
+	sm.abilityUsageSchedule = nil
 
 	return nil
 }
@@ -601,6 +1016,13 @@ func (sm *ScheduleManager) CleanupAllWorkflows(ctx context.Context) error {
 		definitions.AnalyzeBuildsWorkflowName,
 		definitions.AnalyzeTalentsWorkflowName,
 		definitions.AnalyzeStatStatisticsWorkflowName,
+		definitions.AnalyzeDeathsWorkflowName,
+		definitions.AnalyzeDamageTakenWorkflowName,
+		definitions.AnalyzePerformanceWorkflowName,
+		definitions.AnalyzeStatWeightsWorkflowName,
+		definitions.ReportRetentionWorkflowName,
+		definitions.ReportRestoreWorkflowName,
+		definitions.AnalyzeAbilityUsageWorkflowName,
 	}
 
 	// Process each workflow type separately
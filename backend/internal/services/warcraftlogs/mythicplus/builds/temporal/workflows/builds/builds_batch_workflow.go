@@ -153,6 +153,7 @@ func (w *BuildsBatchWorkflow) Execute(ctx workflow.Context, params models.Builds
 
 	// Update result
 	result.BuildsProcessed = activityResult.ProcessedBuildsCount
+	result.BuildsRejected = activityResult.RejectedBuildsCount
 	result.ReportsProcessed = activityResult.SuccessCount
 	result.BuildsByClassSpec = activityResult.BuildsByClassSpec
 	result.Status = "completed"
@@ -173,6 +174,7 @@ func (w *BuildsBatchWorkflow) Execute(ctx workflow.Context, params models.Builds
 		"batchID", params.BatchID,
 		"reportsProcessed", result.ReportsProcessed,
 		"buildsProcessed", result.BuildsProcessed,
+		"buildsRejected", result.BuildsRejected,
 		"duration", result.CompletedAt.Sub(result.StartedAt))
 
 	metrics.Finish("completed")
@@ -428,11 +428,13 @@ func (w *BuildsWorkflow) Execute(ctx workflow.Context, params models.BuildsWorkf
 	// Aggregate results from all child workflows for this page
 	pageProcessedReports := int32(0)
 	pageProcessedBuilds := int32(0)
+	pageRejectedBuilds := int32(0)
 	pageClassSpecBuilds := make(map[string]int32)
 
 	for _, batchResult := range results {
 		if batchResult != nil {
 			pageProcessedBuilds += batchResult.BuildsProcessed
+			pageRejectedBuilds += batchResult.BuildsRejected
 			pageProcessedReports += batchResult.ReportsProcessed
 
 			// Merge builds by class/spec maps
@@ -452,6 +454,7 @@ func (w *BuildsWorkflow) Execute(ctx workflow.Context, params models.BuildsWorkf
 	logger.Info("Page processing complete",
 		"pageProcessedReports", pageProcessedReports,
 		"pageProcessedBuilds", pageProcessedBuilds,
+		"pageRejectedBuilds", pageRejectedBuilds,
 		"totalProcessedReports", totalProcessedReports,
 		"totalToProcess", totalToProcess,
 		"newOffset", newOffset,
@@ -503,6 +506,7 @@ func (w *BuildsWorkflow) Execute(ctx workflow.Context, params models.BuildsWorkf
 
 	// If we've finished processing all reports, finalize the workflow
 	finalResult.BuildsProcessed = pageProcessedBuilds
+	finalResult.BuildsRejected = pageRejectedBuilds
 	finalResult.ReportsProcessed = pageProcessedReports
 	finalResult.BuildsByClassSpec = pageClassSpecBuilds
 	finalResult.CompletedAt = workflow.Now(ctx)
@@ -526,6 +530,7 @@ func (w *BuildsWorkflow) Execute(ctx workflow.Context, params models.BuildsWorkf
 		"status", "completed",
 		"totalReportsProcessed", totalProcessedReports,
 		"totalBuildsProcessed", pageProcessedBuilds,
+		"totalBuildsRejected", pageRejectedBuilds,
 		"duration", finalResult.CompletedAt.Sub(finalResult.StartedAt))
 
 	metrics.Finish("completed")
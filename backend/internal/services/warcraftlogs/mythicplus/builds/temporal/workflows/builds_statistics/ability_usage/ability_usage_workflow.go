@@ -0,0 +1,218 @@
+package warcraftlogsBuildsTemporalWorkflowsBuildsStatisticsAbilityUsage
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	common "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/common"
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// AbilityUsageWorkflow implements the ability usage analysis workflow
+type AbilityUsageWorkflow struct{}
+
+// NewAbilityUsageWorkflow creates a new instance of the ability usage analysis workflow
+func NewAbilityUsageWorkflow() definitions.AbilityUsageWorkflow {
+	return &AbilityUsageWorkflow{}
+}
+
+// Execute runs the ability usage analysis workflow
+// The events of the stored reports are fetched batch after batch, then the statistics of each dungeon are computed.
+func (w *AbilityUsageWorkflow) Execute(ctx workflow.Context, params models.AbilityUsageAnalysisWorkflowParams) (*models.AbilityUsageAnalysisWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting ability usage analysis workflow",
+		"dungeonCount", len(params.Dungeon),
+		"lookbackDays", params.LookbackDays,
+		"fetchBatchSize", params.FetchBatchSize)
+
+	// Initialize the result
+	result := &models.AbilityUsageAnalysisWorkflowResult{
+		StartedAt: workflow.Now(ctx),
+		BatchID:   params.BatchID,
+	}
+
+	// Validate the parameters
+	if len(params.Dungeon) == 0 {
+		return nil, fmt.Errorf("no dungeons found in parameters")
+	}
+
+	// Generate a unique ID for the workflow
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	workflowStateID := fmt.Sprintf("ability-usage-%s", workflowID)
+
+	// Options for the state management activities
+	stateOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 1.5,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	stateCtx := workflow.WithActivityOptions(ctx, stateOpts)
+
+	// Create the initial workflow state
+	err := workflow.ExecuteActivity(stateCtx, definitions.CreateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+		ID:              workflowStateID,
+		WorkflowType:    "ability-usage",
+		StartedAt:       workflow.Now(ctx),
+		Status:          "running",
+		ItemsProcessed:  0,
+		LastProcessedID: "",
+		CreatedAt:       workflow.Now(ctx),
+		UpdatedAt:       workflow.Now(ctx),
+	}).Get(ctx, nil)
+
+	if err != nil {
+		logger.Error("Failed to create workflow state", "error", err)
+		// Continue execution even if state tracking fails
+	}
+
+	// Options for the analysis activities
+	activityOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour * 2,
+		HeartbeatTimeout:    time.Minute * 10,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        time.Second * time.Duration(params.RetryDelay.Seconds()),
+			BackoffCoefficient:     2.0,
+			MaximumInterval:        time.Minute * 10,
+			MaximumAttempts:        int32(params.RetryAttempts),
+			NonRetryableErrorTypes: []string{"RATE_LIMIT_ERROR"},
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
+
+	// 1. Fetch the events of the reports not analyzed yet
+	for batch := int32(0); params.MaxFetchBatches <= 0 || batch < params.MaxFetchBatches; batch++ {
+		var fetchResult models.AbilityUsageAnalysisWorkflowResult
+		err := workflow.ExecuteActivity(activityCtx,
+			definitions.FetchAbilityUsagesActivity,
+			int(params.LookbackDays),
+			int(params.FetchBatchSize),
+			params.Cooldowns,
+		).Get(ctx, &fetchResult)
+
+		if err != nil {
+			if common.IsRateLimitError(err) {
+				workflowState := &warcraftlogsBuilds.WorkflowState{
+					ID:             workflowStateID,
+					Status:         "rate_limited",
+					ErrorMessage:   fmt.Sprintf("Rate limit reached: %v", err),
+					ItemsProcessed: int(result.ReportsFetched),
+					UpdatedAt:      workflow.Now(ctx),
+				}
+				_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+				result.CompletedAt = workflow.Now(ctx)
+				return result, err
+			}
+
+			logger.Error("Failed to fetch ability usages", "batch", batch, "error", err)
+
+			// Compute the statistics of the usages already fetched
+			workflowState := &warcraftlogsBuilds.WorkflowState{
+				ID:           workflowStateID,
+				ErrorMessage: fmt.Sprintf("Error fetching ability usages: %v", err),
+				UpdatedAt:    workflow.Now(ctx),
+			}
+			_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+			break
+		}
+
+		result.ReportsFetched += fetchResult.ReportsFetched
+		result.ReportsFailed += fetchResult.ReportsFailed
+
+		// Update the workflow state with progress
+		workflowState := &warcraftlogsBuilds.WorkflowState{
+			ID:             workflowStateID,
+			ItemsProcessed: int(result.ReportsFetched),
+			UpdatedAt:      workflow.Now(ctx),
+		}
+		_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+		// No report left to fetch
+		if fetchResult.ReportsFetched+fetchResult.ReportsFailed == 0 {
+			break
+		}
+	}
+
+	logger.Info("Fetched report events",
+		"reportsFetched", result.ReportsFetched,
+		"reportsFailed", result.ReportsFailed)
+
+	// 2. Compute the statistics of each dungeon
+	for _, dungeon := range params.Dungeon {
+		// Update the workflow state
+		err = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+			ID:              workflowStateID,
+			Status:          "running",
+			LastProcessedID: fmt.Sprintf("%d", dungeon.EncounterID),
+			UpdatedAt:       workflow.Now(ctx),
+		}).Get(ctx, nil)
+
+		if err != nil {
+			logger.Error("Failed to update workflow state", "error", err)
+		}
+
+		var activityResult models.AbilityUsageAnalysisWorkflowResult
+		err := workflow.ExecuteActivity(activityCtx,
+			definitions.ProcessAbilityUsageStatisticsActivity,
+			uint(dungeon.EncounterID),
+			int(params.LookbackDays),
+		).Get(ctx, &activityResult)
+
+		if err != nil {
+			logger.Error("Failed to process ability usage statistics",
+				"dungeon", dungeon.Name,
+				"encounterID", dungeon.EncounterID,
+				"error", err)
+
+			// Update workflow state with error
+			workflowState := &warcraftlogsBuilds.WorkflowState{
+				ID:           workflowStateID,
+				ErrorMessage: fmt.Sprintf("Error processing dungeon %d: %v", dungeon.EncounterID, err),
+				UpdatedAt:    workflow.Now(ctx),
+			}
+			_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+			// Continue with the next dungeon on error
+			continue
+		}
+
+		result.PlayersAnalyzed += activityResult.PlayersAnalyzed
+		result.StatisticsStored += activityResult.StatisticsStored
+		result.DungeonsProcessed += activityResult.DungeonsProcessed
+
+		logger.Info("Successfully processed ability usage statistics",
+			"dungeon", dungeon.Name,
+			"playersAnalyzed", activityResult.PlayersAnalyzed,
+			"statisticsStored", activityResult.StatisticsStored)
+	}
+
+	result.CompletedAt = workflow.Now(ctx)
+
+	// Complete the workflow state
+	workflowState := &warcraftlogsBuilds.WorkflowState{
+		ID:             workflowStateID,
+		Status:         "completed",
+		CompletedAt:    workflow.Now(ctx),
+		ItemsProcessed: int(result.ReportsFetched),
+		UpdatedAt:      workflow.Now(ctx),
+	}
+	_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+	logger.Info("Ability usage analysis workflow completed",
+		"reportsFetched", result.ReportsFetched,
+		"playersAnalyzed", result.PlayersAnalyzed,
+		"statisticsStored", result.StatisticsStored,
+		"dungeonsProcessed", result.DungeonsProcessed,
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
@@ -32,6 +32,7 @@ func TestEquipmentAnalysisWorkflow_HappyPath(t *testing.T) {
 		mockParams.Spec[0].ClassName,
 		mockParams.Spec[0].SpecName,
 		uint(mockParams.Dungeon[0].EncounterID),
+		uint(mockParams.Dungeon[0].Difficulty),
 		int(mockParams.BatchSize),
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   100,
@@ -39,6 +40,16 @@ func TestEquipmentAnalysisWorkflow_HappyPath(t *testing.T) {
 		CompletedAt:   time.Now(),
 	}, nil)
 
+	// Configure ProcessItemLiftStatistics to return success
+	testEnv.OnActivity(
+		definitions.ProcessItemLiftStatisticsActivity,
+		mock.Anything, // context
+		mockParams.Spec[0].ClassName,
+		mockParams.Spec[0].SpecName,
+		uint(mockParams.Dungeon[0].EncounterID),
+		int(mockParams.BatchSize),
+	).Return(120, nil)
+
 	// Execute workflow
 	testEnv.ExecuteWorkflow(equipmentWorkflow.NewEquipmentAnalysisWorkflow().Execute, *mockParams)
 
@@ -83,7 +94,7 @@ func TestEquipmentAnalysisWorkflow_MultipleSpecsAndDungeons(t *testing.T) {
 	testEnv.OnActivity(
 		definitions.ProcessBuildStatisticsActivity,
 		mock.Anything,
-		"Warrior", "Fury", uint(1001), mock.Anything,
+		"Warrior", "Fury", uint(1001), uint(0), mock.Anything,
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   50,
 		ItemsAnalyzed: 25,
@@ -93,7 +104,7 @@ func TestEquipmentAnalysisWorkflow_MultipleSpecsAndDungeons(t *testing.T) {
 	testEnv.OnActivity(
 		definitions.ProcessBuildStatisticsActivity,
 		mock.Anything,
-		"Warrior", "Fury", uint(1002), mock.Anything,
+		"Warrior", "Fury", uint(1002), uint(0), mock.Anything,
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   60,
 		ItemsAnalyzed: 30,
@@ -103,7 +114,7 @@ func TestEquipmentAnalysisWorkflow_MultipleSpecsAndDungeons(t *testing.T) {
 	testEnv.OnActivity(
 		definitions.ProcessBuildStatisticsActivity,
 		mock.Anything,
-		"Mage", "Frost", uint(1001), mock.Anything,
+		"Mage", "Frost", uint(1001), uint(0), mock.Anything,
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   70,
 		ItemsAnalyzed: 35,
@@ -113,7 +124,7 @@ func TestEquipmentAnalysisWorkflow_MultipleSpecsAndDungeons(t *testing.T) {
 	testEnv.OnActivity(
 		definitions.ProcessBuildStatisticsActivity,
 		mock.Anything,
-		"Mage", "Frost", uint(1002), mock.Anything,
+		"Mage", "Frost", uint(1002), uint(0), mock.Anything,
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   80,
 		ItemsAnalyzed: 40,
@@ -150,7 +161,7 @@ func TestEquipmentAnalysisWorkflow_ActivityError(t *testing.T) {
 	testEnv.OnActivity(
 		definitions.ProcessBuildStatisticsActivity,
 		mock.Anything,
-		"Warrior", "Fury", uint(1001), mock.Anything,
+		"Warrior", "Fury", uint(1001), uint(0), mock.Anything,
 	).Return(nil, errors.New("processing failed"))
 
 	// Add a second dungeon that will succeed
@@ -163,7 +174,7 @@ func TestEquipmentAnalysisWorkflow_ActivityError(t *testing.T) {
 	testEnv.OnActivity(
 		definitions.ProcessBuildStatisticsActivity,
 		mock.Anything,
-		"Warrior", "Fury", uint(1002), mock.Anything,
+		"Warrior", "Fury", uint(1002), uint(0), mock.Anything,
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   100,
 		ItemsAnalyzed: 50,
@@ -276,6 +287,7 @@ func TestEquipmentAnalysisWorkflow_WorkflowState(t *testing.T) {
 		mockParams.Spec[0].ClassName,
 		mockParams.Spec[0].SpecName,
 		uint(mockParams.Dungeon[0].EncounterID),
+		uint(mockParams.Dungeon[0].Difficulty),
 		int(mockParams.BatchSize),
 	).Return(&models.EquipmentAnalysisWorkflowResult{
 		TotalBuilds:   100,
@@ -320,12 +332,19 @@ func setupTestEnvironment() (env *testsuite.TestWorkflowEnvironment, params *mod
 	)
 
 	env.RegisterActivityWithOptions(
-		func(ctx context.Context, className, specName string, encounterID uint, batchSize int) (*models.EquipmentAnalysisWorkflowResult, error) {
+		func(ctx context.Context, className, specName string, encounterID, difficulty uint, batchSize int) (*models.EquipmentAnalysisWorkflowResult, error) {
 			return &models.EquipmentAnalysisWorkflowResult{}, nil
 		},
 		activity.RegisterOptions{Name: definitions.ProcessBuildStatisticsActivity},
 	)
 
+	env.RegisterActivityWithOptions(
+		func(ctx context.Context, className, specName string, encounterID uint, batchSize int) (int, error) {
+			return 0, nil
+		},
+		activity.RegisterOptions{Name: definitions.ProcessItemLiftStatisticsActivity},
+	)
+
 	// Create mock parameters
 	params = &models.EquipmentAnalysisWorkflowParams{
 		Spec: []models.ClassSpec{
@@ -365,3 +384,45 @@ func configureWorkflowStateMocks(testEnv *testsuite.TestWorkflowEnvironment) {
 		mock.Anything, // state
 	).Return(nil).Times(1) // Allow multiple calls
 }
+
+// TestEquipmentAnalysisWorkflow_LiftError tests that a lift analysis failure does not fail the combination
+func TestEquipmentAnalysisWorkflow_LiftError(t *testing.T) {
+	// Setup
+	testEnv, mockParams := setupTestEnvironment()
+
+	// Configure workflow state mocks
+	configureWorkflowStateMocks(testEnv)
+
+	testEnv.OnActivity(
+		definitions.ProcessBuildStatisticsActivity,
+		mock.Anything,
+		"Warrior", "Fury", uint(1001), uint(0), mock.Anything,
+	).Return(&models.EquipmentAnalysisWorkflowResult{
+		TotalBuilds:   100,
+		ItemsAnalyzed: 50,
+	}, nil)
+
+	// The lift analysis fails
+	testEnv.OnActivity(
+		definitions.ProcessItemLiftStatisticsActivity,
+		mock.Anything,
+		"Warrior", "Fury", uint(1001), mock.Anything,
+	).Return(0, errors.New("lift failed"))
+
+	// Execute workflow
+	testEnv.ExecuteWorkflow(equipmentWorkflow.NewEquipmentAnalysisWorkflow().Execute, *mockParams)
+
+	// Verify
+	assert.True(t, testEnv.IsWorkflowCompleted())
+	assert.NoError(t, testEnv.GetWorkflowError())
+
+	var result models.EquipmentAnalysisWorkflowResult
+	assert.NoError(t, testEnv.GetWorkflowResult(&result))
+
+	// The popularity statistics are still counted
+	assert.Equal(t, int32(100), result.TotalBuilds)
+	assert.Equal(t, int32(50), result.ItemsAnalyzed)
+	assert.Equal(t, int32(1), result.SpecsProcessed)
+
+	testEnv.AssertExpectations(t)
+}
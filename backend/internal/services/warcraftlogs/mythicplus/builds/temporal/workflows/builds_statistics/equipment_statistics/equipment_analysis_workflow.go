@@ -102,7 +102,7 @@ func (w *EquipmentAnalysisWorkflow) Execute(ctx workflow.Context, params models.
 
 		for _, dungeon := range params.Dungeon {
 			// Identify the combination
-			combinationKey := fmt.Sprintf("%s_%s_%d", spec.ClassName, spec.SpecName, dungeon.EncounterID)
+			combinationKey := fmt.Sprintf("%s_%s_%d_%d", spec.ClassName, spec.SpecName, dungeon.EncounterID, dungeon.Difficulty)
 
 			// Check if already processed
 			if processedCombinations[combinationKey] {
@@ -137,6 +137,7 @@ func (w *EquipmentAnalysisWorkflow) Execute(ctx workflow.Context, params models.
 				spec.ClassName,
 				spec.SpecName,
 				uint(dungeon.EncounterID),
+				uint(dungeon.Difficulty),
 				int(params.BatchSize),
 			).Get(ctx, &activityResult)
 
@@ -187,6 +188,28 @@ func (w *EquipmentAnalysisWorkflow) Execute(ctx workflow.Context, params models.
 			totalBuilds += activityResult.TotalBuilds
 			totalItems += activityResult.ItemsAnalyzed
 
+			// Compare the choices of the top keys with the whole population
+			// The popularity statistics are already stored, so a failure only skips the lift scores
+			// It compares keystone levels, so it only runs for the Mythic+ dungeons
+			if dungeon.Difficulty == 0 {
+				var liftStatistics int
+				err = workflow.ExecuteActivity(activityCtx,
+					definitions.ProcessItemLiftStatisticsActivity,
+					spec.ClassName,
+					spec.SpecName,
+					uint(dungeon.EncounterID),
+					int(params.BatchSize),
+				).Get(ctx, &liftStatistics)
+
+				if err != nil {
+					logger.Error("Failed to process item lift analysis",
+						"class", spec.ClassName,
+						"spec", spec.SpecName,
+						"dungeon", dungeon.Name,
+						"error", err)
+				}
+			}
+
 			// Update the workflow state with progress
 			workflowState := &warcraftlogsBuilds.WorkflowState{
 				ID:             workflowStateID,
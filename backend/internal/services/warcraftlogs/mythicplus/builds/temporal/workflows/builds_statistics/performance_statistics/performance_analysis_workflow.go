@@ -0,0 +1,198 @@
+package warcraftlogsBuildsTemporalWorkflowsBuildsStatisticsPerformanceStatistics
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	common "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/common"
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// PerformanceAnalysisWorkflow implements the performance analysis workflow
+type PerformanceAnalysisWorkflow struct{}
+
+// NewPerformanceAnalysisWorkflow creates a new instance of the performance analysis workflow
+func NewPerformanceAnalysisWorkflow() definitions.PerformanceAnalysisWorkflow {
+	return &PerformanceAnalysisWorkflow{}
+}
+
+// Execute runs the performance analysis workflow
+func (w *PerformanceAnalysisWorkflow) Execute(ctx workflow.Context, params models.PerformanceAnalysisWorkflowParams) (*models.PerformanceAnalysisWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting performance analysis workflow",
+		"dungeonCount", len(params.Dungeon),
+		"lookbackDays", params.LookbackDays,
+		"batchSize", params.BatchSize)
+
+	// Initialize the result
+	result := &models.PerformanceAnalysisWorkflowResult{
+		StartedAt: workflow.Now(ctx),
+		BatchID:   params.BatchID,
+	}
+
+	// Validate the parameters
+	if len(params.Dungeon) == 0 {
+		return nil, fmt.Errorf("no dungeons found in parameters")
+	}
+
+	// Generate a unique ID for the workflow
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	workflowStateID := fmt.Sprintf("performance-analysis-%s", workflowID)
+
+	// Options for the state management activities
+	stateOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 1.5,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	stateCtx := workflow.WithActivityOptions(ctx, stateOpts)
+
+	// Create the initial workflow state
+	err := workflow.ExecuteActivity(stateCtx, definitions.CreateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+		ID:              workflowStateID,
+		WorkflowType:    "performance-analysis",
+		StartedAt:       workflow.Now(ctx),
+		Status:          "running",
+		ItemsProcessed:  0,
+		LastProcessedID: "",
+		CreatedAt:       workflow.Now(ctx),
+		UpdatedAt:       workflow.Now(ctx),
+	}).Get(ctx, nil)
+
+	if err != nil {
+		logger.Error("Failed to create workflow state", "error", err)
+		// Continue execution even if state tracking fails
+	}
+
+	// Options for the analysis activities
+	activityOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour * 6,
+		HeartbeatTimeout:    time.Minute * 10,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second * time.Duration(params.RetryDelay.Seconds()),
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute * 10,
+			MaximumAttempts:    int32(params.RetryAttempts),
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
+
+	totalPlayers := int32(0)
+	totalRuns := int32(0)
+	totalStats := int32(0)
+	dungeonsProcessed := int32(0)
+
+	for _, dungeon := range params.Dungeon {
+		dungeonKey := fmt.Sprintf("%d", dungeon.EncounterID)
+
+		// Update the workflow state
+		err = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+			ID:              workflowStateID,
+			Status:          "running",
+			LastProcessedID: dungeonKey,
+			UpdatedAt:       workflow.Now(ctx),
+		}).Get(ctx, nil)
+
+		if err != nil {
+			logger.Error("Failed to update workflow state", "error", err)
+		}
+
+		logger.Info("Processing performance analysis", "dungeon", dungeon.Name)
+
+		// Execute the performance analysis activity
+		var activityResult models.PerformanceAnalysisWorkflowResult
+		err := workflow.ExecuteActivity(activityCtx,
+			definitions.ProcessPerformanceStatisticsActivity,
+			uint(dungeon.EncounterID),
+			int(params.LookbackDays),
+			int(params.BatchSize),
+		).Get(ctx, &activityResult)
+
+		if err != nil {
+			if common.IsRateLimitError(err) {
+				workflowState := &warcraftlogsBuilds.WorkflowState{
+					ID:           workflowStateID,
+					Status:       "rate_limited",
+					ErrorMessage: fmt.Sprintf("Rate limit reached: %v", err),
+					UpdatedAt:    workflow.Now(ctx),
+				}
+				_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+				result.CompletedAt = workflow.Now(ctx)
+				return result, err
+			}
+
+			logger.Error("Failed to process performance analysis",
+				"dungeon", dungeon.Name,
+				"error", err)
+
+			// Update workflow state with error
+			workflowState := &warcraftlogsBuilds.WorkflowState{
+				ID:           workflowStateID,
+				ErrorMessage: fmt.Sprintf("Error processing dungeon %s: %v", dungeonKey, err),
+				UpdatedAt:    workflow.Now(ctx),
+			}
+			_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+			// Continue with the next dungeon on error
+			continue
+		}
+
+		// Update the counters
+		dungeonsProcessed++
+		totalPlayers += activityResult.PlayersAnalyzed
+		totalRuns += activityResult.RunsAnalyzed
+		totalStats += activityResult.StatisticsStored
+
+		// Update the workflow state with progress
+		workflowState := &warcraftlogsBuilds.WorkflowState{
+			ID:             workflowStateID,
+			ItemsProcessed: int(totalRuns),
+			UpdatedAt:      workflow.Now(ctx),
+		}
+		_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+		logger.Info("Successfully processed performance analysis",
+			"dungeon", dungeon.Name,
+			"runsAnalyzed", activityResult.RunsAnalyzed,
+			"playersAnalyzed", activityResult.PlayersAnalyzed,
+			"statisticsStored", activityResult.StatisticsStored)
+
+		// Small delay between dungeons to avoid overloading the system
+		workflow.Sleep(ctx, time.Second*2)
+	}
+
+	// Finalize the result
+	result.PlayersAnalyzed = totalPlayers
+	result.RunsAnalyzed = totalRuns
+	result.StatisticsStored = totalStats
+	result.DungeonsProcessed = dungeonsProcessed
+	result.CompletedAt = workflow.Now(ctx)
+
+	// Complete the workflow state
+	workflowState := &warcraftlogsBuilds.WorkflowState{
+		ID:             workflowStateID,
+		Status:         "completed",
+		CompletedAt:    workflow.Now(ctx),
+		ItemsProcessed: int(totalRuns),
+		UpdatedAt:      workflow.Now(ctx),
+	}
+	_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+	logger.Info("Performance analysis workflow completed",
+		"runsAnalyzed", totalRuns,
+		"playersAnalyzed", totalPlayers,
+		"dungeonsProcessed", dungeonsProcessed,
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
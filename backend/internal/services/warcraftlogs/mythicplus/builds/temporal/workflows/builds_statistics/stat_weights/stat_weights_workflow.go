@@ -0,0 +1,220 @@
+package warcraftlogsBuildsTemporalWorkflowsBuildsStatisticsStatWeights
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	common "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/common"
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// StatWeightsWorkflow implements the stat weights workflow
+type StatWeightsWorkflow struct{}
+
+// NewStatWeightsWorkflow creates a new instance of the stat weights workflow
+func NewStatWeightsWorkflow() definitions.StatWeightsWorkflow {
+	return &StatWeightsWorkflow{}
+}
+
+// Execute runs the stat weights workflow
+func (w *StatWeightsWorkflow) Execute(ctx workflow.Context, params models.StatWeightsWorkflowParams) (*models.StatWeightsWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting stat weights workflow",
+		"specCount", len(params.Spec),
+		"metricCount", len(params.Metrics),
+		"batchSize", params.BatchSize)
+
+	// Initialize the result
+	result := &models.StatWeightsWorkflowResult{
+		StartedAt: workflow.Now(ctx),
+		BatchID:   params.BatchID,
+	}
+
+	// Validate the parameters
+	if len(params.Spec) == 0 {
+		return nil, fmt.Errorf("no specs found in parameters")
+	}
+
+	if len(params.Metrics) == 0 {
+		return nil, fmt.Errorf("no metrics found in parameters")
+	}
+
+	// Generate a unique ID for the workflow
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	workflowStateID := fmt.Sprintf("stat-weights-%s", workflowID)
+
+	// Options for the state management activities
+	stateOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 1.5,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	stateCtx := workflow.WithActivityOptions(ctx, stateOpts)
+
+	// Create the initial workflow state
+	err := workflow.ExecuteActivity(stateCtx, definitions.CreateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+		ID:              workflowStateID,
+		WorkflowType:    "stat-weights",
+		StartedAt:       workflow.Now(ctx),
+		Status:          "running",
+		ItemsProcessed:  0,
+		LastProcessedID: "",
+		CreatedAt:       workflow.Now(ctx),
+		UpdatedAt:       workflow.Now(ctx),
+	}).Get(ctx, nil)
+
+	if err != nil {
+		logger.Error("Failed to create workflow state", "error", err)
+		// Continue execution even if state tracking fails
+	}
+
+	// Options for the analysis activities
+	activityOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour * 6,
+		HeartbeatTimeout:    time.Minute * 10,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second * time.Duration(params.RetryDelay.Seconds()),
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute * 10,
+			MaximumAttempts:    int32(params.RetryAttempts),
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
+
+	totalSamples := int32(0)
+	totalWeights := int32(0)
+	specsProcessed := int32(0)
+	metricsProcessed := int32(0)
+
+	for _, spec := range params.Spec {
+		specProcessed := false
+
+		for _, metric := range params.Metrics {
+			combinationKey := fmt.Sprintf("%s_%s_%s", spec.ClassName, spec.SpecName, metric)
+
+			// Update the workflow state
+			err = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+				ID:              workflowStateID,
+				Status:          "running",
+				LastProcessedID: combinationKey,
+				UpdatedAt:       workflow.Now(ctx),
+			}).Get(ctx, nil)
+
+			if err != nil {
+				logger.Error("Failed to update workflow state", "error", err)
+			}
+
+			logger.Info("Processing stat weights",
+				"class", spec.ClassName,
+				"spec", spec.SpecName,
+				"metric", metric)
+
+			// Execute the stat weights activity
+			var activityResult models.StatWeightsWorkflowResult
+			err := workflow.ExecuteActivity(activityCtx,
+				definitions.ProcessStatWeightsActivity,
+				spec.ClassName,
+				spec.SpecName,
+				metric,
+				int(params.BatchSize),
+			).Get(ctx, &activityResult)
+
+			if err != nil {
+				if common.IsRateLimitError(err) {
+					workflowState := &warcraftlogsBuilds.WorkflowState{
+						ID:           workflowStateID,
+						Status:       "rate_limited",
+						ErrorMessage: fmt.Sprintf("Rate limit reached: %v", err),
+						UpdatedAt:    workflow.Now(ctx),
+					}
+					_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+					result.CompletedAt = workflow.Now(ctx)
+					return result, err
+				}
+
+				logger.Error("Failed to process stat weights",
+					"class", spec.ClassName,
+					"spec", spec.SpecName,
+					"metric", metric,
+					"error", err)
+
+				// Update workflow state with error
+				workflowState := &warcraftlogsBuilds.WorkflowState{
+					ID:           workflowStateID,
+					ErrorMessage: fmt.Sprintf("Error processing %s: %v", combinationKey, err),
+					UpdatedAt:    workflow.Now(ctx),
+				}
+				_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+				// Continue with the next combination on error
+				continue
+			}
+
+			// Update the counters, a regression without enough builds stores no weight
+			totalSamples += activityResult.SamplesAnalyzed
+			totalWeights += activityResult.WeightsStored
+			if activityResult.WeightsStored > 0 {
+				metricsProcessed++
+				specProcessed = true
+			}
+
+			// Update the workflow state with progress
+			workflowState := &warcraftlogsBuilds.WorkflowState{
+				ID:             workflowStateID,
+				ItemsProcessed: int(totalSamples),
+				UpdatedAt:      workflow.Now(ctx),
+			}
+			_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+			logger.Info("Successfully processed stat weights",
+				"class", spec.ClassName,
+				"spec", spec.SpecName,
+				"metric", metric,
+				"samplesAnalyzed", activityResult.SamplesAnalyzed,
+				"weightsStored", activityResult.WeightsStored)
+		}
+
+		// Increment the specs counter
+		if specProcessed {
+			specsProcessed++
+		}
+
+		// Small delay between specs to avoid overloading the system
+		workflow.Sleep(ctx, time.Second*2)
+	}
+
+	// Finalize the result
+	result.SamplesAnalyzed = totalSamples
+	result.WeightsStored = totalWeights
+	result.SpecsProcessed = specsProcessed
+	result.MetricsProcessed = metricsProcessed
+	result.CompletedAt = workflow.Now(ctx)
+
+	// Complete the workflow state
+	workflowState := &warcraftlogsBuilds.WorkflowState{
+		ID:             workflowStateID,
+		Status:         "completed",
+		CompletedAt:    workflow.Now(ctx),
+		ItemsProcessed: int(totalSamples),
+		UpdatedAt:      workflow.Now(ctx),
+	}
+	_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+	logger.Info("Stat weights workflow completed",
+		"samplesAnalyzed", totalSamples,
+		"weightsStored", totalWeights,
+		"specsProcessed", specsProcessed,
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
@@ -102,7 +102,7 @@ func (w *StatAnalysisWorkflow) Execute(ctx workflow.Context, params models.StatA
 
 		for _, dungeon := range params.Dungeon {
 			// Identify the combination
-			combinationKey := fmt.Sprintf("%s_%s_%d", spec.ClassName, spec.SpecName, dungeon.EncounterID)
+			combinationKey := fmt.Sprintf("%s_%s_%d_%d", spec.ClassName, spec.SpecName, dungeon.EncounterID, dungeon.Difficulty)
 
 			// Check if already processed
 			if processedCombinations[combinationKey] {
@@ -137,6 +137,7 @@ func (w *StatAnalysisWorkflow) Execute(ctx workflow.Context, params models.StatA
 				spec.ClassName,
 				spec.SpecName,
 				uint(dungeon.EncounterID),
+				uint(dungeon.Difficulty),
 				int(params.BatchSize),
 			).Get(ctx, &activityResult)
 
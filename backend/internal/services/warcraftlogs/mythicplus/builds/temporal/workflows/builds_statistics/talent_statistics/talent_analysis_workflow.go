@@ -102,7 +102,7 @@ func (w *TalentAnalysisWorkflow) Execute(ctx workflow.Context, params models.Tal
 
 		for _, dungeon := range params.Dungeon {
 			// Identify the combination
-			combinationKey := fmt.Sprintf("%s_%s_%d", spec.ClassName, spec.SpecName, dungeon.EncounterID)
+			combinationKey := fmt.Sprintf("%s_%s_%d_%d", spec.ClassName, spec.SpecName, dungeon.EncounterID, dungeon.Difficulty)
 
 			// Check if already processed
 			if processedCombinations[combinationKey] {
@@ -137,6 +137,7 @@ func (w *TalentAnalysisWorkflow) Execute(ctx workflow.Context, params models.Tal
 				spec.ClassName,
 				spec.SpecName,
 				uint(dungeon.EncounterID),
+				uint(dungeon.Difficulty),
 				int(params.BatchSize),
 			).Get(ctx, &activityResult)
 
@@ -187,6 +188,28 @@ func (w *TalentAnalysisWorkflow) Execute(ctx workflow.Context, params models.Tal
 			totalBuilds += activityResult.TotalBuilds
 			totalTalents += activityResult.TalentsAnalyzed
 
+			// Compute the pick rate of each talent node
+			// The talent imports are already stored, so a failure only skips the node statistics
+			// Node statistics are not keyed by difficulty, so they are only computed for the Mythic+ dungeons
+			if dungeon.Difficulty == 0 {
+				var nodeStatistics int
+				err = workflow.ExecuteActivity(activityCtx,
+					definitions.ProcessTalentNodeStatisticsActivity,
+					spec.ClassName,
+					spec.SpecName,
+					uint(dungeon.EncounterID),
+					int(params.BatchSize),
+				).Get(ctx, &nodeStatistics)
+
+				if err != nil {
+					logger.Error("Failed to process talent node analysis",
+						"class", spec.ClassName,
+						"spec", spec.SpecName,
+						"dungeon", dungeon.Name,
+						"error", err)
+				}
+			}
+
 			// Update the workflow state with progress
 			workflowState := &warcraftlogsBuilds.WorkflowState{
 				ID:             workflowStateID,
@@ -80,7 +80,11 @@ func GenerateSpecKey(spec models.ClassSpec) string {
 }
 
 // GenerateDungeonKey generates a unique key for a dungeon
+// Raid bosses are keyed by difficulty too, since a boss can be targeted at several difficulties
 func GenerateDungeonKey(spec models.ClassSpec, dungeon models.Dungeon) string {
+	if dungeon.Difficulty != 0 {
+		return fmt.Sprintf("%s_%s_%d_%d", spec.ClassName, spec.SpecName, dungeon.ID, dungeon.Difficulty)
+	}
 	return fmt.Sprintf("%s_%s_%d", spec.ClassName, spec.SpecName, dungeon.ID)
 }
 
@@ -247,6 +247,20 @@ func TestGenerateSpecKey(t *testing.T) {
 	}
 }
 
+// TestGenerateDungeonKey tests the generation of the dungeon keys.
+// It verifies that a raid boss gets a key for each targeted difficulty
+// while the Mythic+ dungeons keep their key without difficulty.
+func TestGenerateDungeonKey(t *testing.T) {
+	spec := models.ClassSpec{ClassName: "Priest", SpecName: "Shadow"}
+
+	assert.Equal(t, "Priest_Shadow_12660", GenerateDungeonKey(spec, models.Dungeon{ID: 12660, EncounterID: 12660}))
+
+	heroic := GenerateDungeonKey(spec, models.Dungeon{ID: 3009, EncounterID: 3009, Difficulty: models.RaidDifficultyHeroic})
+	mythic := GenerateDungeonKey(spec, models.Dungeon{ID: 3009, EncounterID: 3009, Difficulty: models.RaidDifficultyMythic})
+	assert.Equal(t, "Priest_Shadow_3009_4", heroic)
+	assert.Equal(t, "Priest_Shadow_3009_5", mythic)
+}
+
 // TestFilterSpecsForClass tests the filtering of specs by class name.
 // It verifies that:
 // - Only specs matching the requested class are returned
@@ -35,10 +35,10 @@ func ValidateConfig(config *models.WorkflowConfig) error {
 		}
 	}
 
-	if len(config.Dungeons) == 0 {
+	if len(config.Dungeons) == 0 && len(config.Raids) == 0 {
 		return &WorkflowError{
 			Type:    ErrorTypeConfiguration,
-			Message: "at least one dungeon must be configured",
+			Message: "at least one dungeon or raid must be configured",
 		}
 	}
 
@@ -41,6 +41,33 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Valid raid only configuration",
+			config: &models.WorkflowConfig{
+				Rankings: models.RankingsConfig{MaxRankingsPerSpec: 100},
+				Worker:   models.WorkerConfig{NumWorkers: 3},
+				Specs:    []models.ClassSpec{{ClassName: "Priest", SpecName: "Shadow"}},
+				Raids: []models.Raid{
+					{
+						Name:       "Test Raid",
+						Slug:       "test-raid",
+						Difficulty: models.RaidDifficultyMythic,
+						Bosses:     []models.RaidBoss{{EncounterID: 3009, Name: "Test Boss"}},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "No dungeons nor raids configured",
+			config: &models.WorkflowConfig{
+				Rankings: models.RankingsConfig{MaxRankingsPerSpec: 100},
+				Worker:   models.WorkerConfig{NumWorkers: 3},
+				Specs:    []models.ClassSpec{{ClassName: "Priest", SpecName: "Shadow"}},
+			},
+			expectError: true,
+			errorType:   ErrorTypeConfiguration,
+		},
 		{
 			name:        "Nil configuration",
 			config:      nil,
@@ -21,8 +21,10 @@ import (
 const (
 	// Rankings activities
 	FetchRankingsActivity         = "FetchAndStore"                   // Fetch and store rankings
+	FetchRankingsBatchActivity    = "FetchAndStoreBatch"              // Fetch and store the rankings of several dungeons in batched requests
 	GetStoredRankingsActivity     = "GetStoredRankings"               // Get stored rankings
 	MarkRankingsForReportActivity = "MarkRankingsForReportProcessing" // Mark rankings ready for reports processing
+	StoreRaidEncountersActivity   = "StoreRaidEncounters"             // Store the raid bosses targets
 
 	// Reports activities
 	ProcessReportsActivity                     = "ProcessReports"                     // Process reports
@@ -53,9 +55,30 @@ const (
 	DeleteOldWorkflowStatesActivity = "DeleteOldWorkflowStates"
 
 	// Build statistics activities
-	ProcessBuildStatisticsActivity  = "ProcessItemStatistics"   // Analyze equipment
-	ProcessTalentStatisticsActivity = "ProcessTalentStatistics" // Analyze talents
-	ProcessStatStatisticsActivity   = "ProcessStatStatistics"   // Analyze statistics
+	ProcessBuildStatisticsActivity      = "ProcessItemStatistics"       // Analyze equipment
+	ProcessItemLiftStatisticsActivity   = "ProcessItemLiftStatistics"   // Compare equipment choices of the top keys with the population
+	ProcessTalentStatisticsActivity     = "ProcessTalentStatistics"     // Analyze talents
+	ProcessTalentNodeStatisticsActivity = "ProcessTalentNodeStatistics" // Analyze talent node pick rates
+	ProcessStatStatisticsActivity       = "ProcessStatStatistics"       // Analyze statistics
+	ProcessStatWeightsActivity          = "ProcessStatWeights"          // Estimate the secondary stat weights
+
+	// Combat analysis activities
+	ProcessDeathStatisticsActivity       = "ProcessDeathStatistics"       // Analyze deaths
+	ProcessDamageTakenStatisticsActivity = "ProcessDamageTakenStatistics" // Analyze damage taken
+	ProcessPerformanceStatisticsActivity = "ProcessPerformanceStatistics" // Analyze DPS and HPS
+
+	// Ability usage activities
+	FetchAbilityUsagesActivity            = "FetchAbilityUsages"            // Fetch the casts, interrupts and dispels of the reports
+	ProcessAbilityUsageStatisticsActivity = "ProcessAbilityUsageStatistics" // Analyze cooldowns, interrupts and dispels
+
+	// Report analysis activities
+	AnalyzeReportActivity      = "AnalyzeReport"      // Compare the players of a report with the statistics
+	FailReportAnalysisActivity = "FailReportAnalysis" // Mark a report analysis job as failed
+
+	// Report retention activities
+	ArchiveReportsActivity     = "ArchiveReports"     // Move report data to the object store
+	RestoreReportsActivity     = "RestoreReports"     // Write archived report data back to the reports
+	GetRestoredReportsActivity = "GetRestoredReports" // Load restored reports for build extraction
 
 	// Sub-workflow names
 	RankingsWorkflowName              = "RankingsWorkflow"              // Rankings workflow
@@ -64,6 +87,14 @@ const (
 	AnalyzeBuildsWorkflowName         = "AnalyzeBuildsWorkflow"         // Analyze builds workflow
 	AnalyzeTalentsWorkflowName        = "AnalyzeTalentsWorkflow"        // Analyze talents workflow
 	AnalyzeStatStatisticsWorkflowName = "AnalyzeStatStatisticsWorkflow" // Analyze statistics workflow
+	AnalyzeDeathsWorkflowName         = "AnalyzeDeathsWorkflow"         // Analyze deaths workflow
+	AnalyzeDamageTakenWorkflowName    = "AnalyzeDamageTakenWorkflow"    // Analyze damage taken workflow
+	AnalyzePerformanceWorkflowName    = "AnalyzePerformanceWorkflow"    // Analyze performance workflow
+	AnalyzeStatWeightsWorkflowName    = "AnalyzeStatWeightsWorkflow"    // Analyze stat weights workflow
+	AnalyzeAbilityUsageWorkflowName   = "AnalyzeAbilityUsageWorkflow"   // Analyze ability usage workflow
+	ReportAnalysisWorkflowName        = "ReportAnalysisWorkflow"        // On-demand report analysis workflow
+	ReportRetentionWorkflowName       = "ReportRetentionWorkflow"       // Report archiving workflow
+	ReportRestoreWorkflowName         = "ReportRestoreWorkflow"         // Report restore workflow
 
 	// Builds Child Workflow
 	ProcessBuildsBatchWorkflow = "ProcessBuildsBatchWorkflow" // Child workflow for processing a batch of builds
@@ -80,8 +111,10 @@ const (
 // RankingsActivity defines the interface for rankings-related activities
 type RankingsActivity interface {
 	FetchAndStore(ctx context.Context, spec models.ClassSpec, dungeon models.Dungeon, batchConfig models.BatchConfig) (*models.BatchResult, error)
+	FetchAndStoreBatch(ctx context.Context, spec models.ClassSpec, dungeons []models.Dungeon, batchConfig models.BatchConfig) ([]*models.BatchResult, error)
 	GetStoredRankings(ctx context.Context, className, specName string, encounterID uint32) ([]*warcraftlogsBuilds.ClassRanking, error)
 	MarkRankingsForReportProcessing(ctx context.Context, className, specName string, encounterID uint32, batchID string) error
+	StoreRaidEncounters(ctx context.Context, raids []models.Raid) error
 }
 
 // ReportsActivity defines the interface for report-related activities
@@ -109,3 +142,10 @@ type RateLimitActivity interface {
 	ReleasePoints(ctx context.Context, params models.WorkflowConfig) error
 	CheckRemainingPoints(ctx context.Context, params models.WorkflowConfig) (float64, error)
 }
+
+// ReportRetentionActivity defines the interface for the report archiving operations
+type ReportRetentionActivity interface {
+	ArchiveReports(ctx context.Context, policy models.RetentionPolicy, batchSize int32) (*models.ReportRetentionWorkflowResult, error)
+	RestoreReports(ctx context.Context, reports []models.ReportReference, dataTypes []string) (*models.ReportRetentionWorkflowResult, error)
+	GetRestoredReports(ctx context.Context, reports []models.ReportReference) ([]*warcraftlogsBuilds.Report, error)
+}
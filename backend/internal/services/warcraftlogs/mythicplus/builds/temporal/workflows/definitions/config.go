@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
 
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
 	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
 )
 
@@ -31,7 +32,8 @@ func LoadRankingsParams(configPath string) (*models.RankingsWorkflowParams, erro
 
 	return &models.RankingsWorkflowParams{
 		Specs:              config.Specs,
-		Dungeons:           config.Dungeons,
+		Dungeons:           EncounterTargets(config),
+		Raids:              config.Raids,
 		MaxRankingsPerSpec: config.Rankings.MaxRankingsPerSpec,
 		BatchSize:          config.Rankings.Batch.Size,
 		RetryDelay:         config.Rankings.Batch.RetryDelay,
@@ -113,12 +115,12 @@ func LoadEquipmentAnalysisParams(configPath string) (*models.EquipmentAnalysisWo
 	}
 
 	return &models.EquipmentAnalysisWorkflowParams{
-		Spec:          config.Specs,    // Specs to analyze
-		Dungeon:       config.Dungeons, // Dungeons to analyze
-		BatchSize:     10,              // Batch size for the analysis
-		Concurrency:   4,               // Number of concurrent workers
-		RetryAttempts: 3,               // Number of retry attempts
-		RetryDelay:    5 * time.Second, // Retry delay
+		Spec:          config.Specs,             // Specs to analyze
+		Dungeon:       EncounterTargets(config), // Dungeons and raid bosses to analyze
+		BatchSize:     10,                       // Batch size for the analysis
+		Concurrency:   4,                        // Number of concurrent workers
+		RetryAttempts: 3,                        // Number of retry attempts
+		RetryDelay:    5 * time.Second,          // Retry delay
 		BatchID:       fmt.Sprintf("equipment-analysis-%s", uuid.New().String()),
 	}, nil
 }
@@ -131,12 +133,12 @@ func LoadStatAnalysisParams(configPath string) (*models.StatAnalysisWorkflowPara
 	}
 
 	return &models.StatAnalysisWorkflowParams{
-		Spec:          config.Specs,    // Specs to analyze
-		Dungeon:       config.Dungeons, // Dungeons to analyze
-		BatchSize:     10,              // Batch size for the analysis
-		Concurrency:   4,               // Number of concurrent workers
-		RetryAttempts: 3,               // Number of retry attempts
-		RetryDelay:    5 * time.Second, // Retry delay
+		Spec:          config.Specs,             // Specs to analyze
+		Dungeon:       EncounterTargets(config), // Dungeons and raid bosses to analyze
+		BatchSize:     10,                       // Batch size for the analysis
+		Concurrency:   4,                        // Number of concurrent workers
+		RetryAttempts: 3,                        // Number of retry attempts
+		RetryDelay:    5 * time.Second,          // Retry delay
 		BatchID:       fmt.Sprintf("stat-analysis-%s", uuid.New().String()),
 	}, nil
 }
@@ -149,16 +151,163 @@ func LoadTalentAnalysisParams(configPath string) (*models.TalentAnalysisWorkflow
 	}
 
 	return &models.TalentAnalysisWorkflowParams{
-		Spec:          config.Specs,    // Specs to analyze
+		Spec:          config.Specs,             // Specs to analyze
+		Dungeon:       EncounterTargets(config), // Dungeons and raid bosses to analyze
+		BatchSize:     10,                       // Batch size for the analysis
+		Concurrency:   4,                        // Number of concurrent workers
+		RetryAttempts: 3,                        // Number of retry attempts
+		RetryDelay:    5 * time.Second,          // Retry delay
+		BatchID:       fmt.Sprintf("talent-analysis-%s", uuid.New().String()),
+	}, nil
+}
+
+// LoadDeathAnalysisParams loads the parameters for the death analysis workflow
+func LoadDeathAnalysisParams(configPath string) (*models.DeathAnalysisWorkflowParams, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DeathAnalysisWorkflowParams{
 		Dungeon:       config.Dungeons, // Dungeons to analyze
-		BatchSize:     10,              // Batch size for the analysis
-		Concurrency:   4,               // Number of concurrent workers
+		LookbackDays:  7,               // Analyze the reports of the last week
+		BatchSize:     50,              // Batch size for the analysis
 		RetryAttempts: 3,               // Number of retry attempts
 		RetryDelay:    5 * time.Second, // Retry delay
-		BatchID:       fmt.Sprintf("talent-analysis-%s", uuid.New().String()),
+		BatchID:       fmt.Sprintf("death-analysis-%s", uuid.New().String()),
+	}, nil
+}
+
+// LoadDamageTakenAnalysisParams loads the parameters for the damage taken analysis workflow
+func LoadDamageTakenAnalysisParams(configPath string) (*models.DamageTakenAnalysisWorkflowParams, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DamageTakenAnalysisWorkflowParams{
+		Dungeon:       config.Dungeons, // Dungeons to analyze
+		LookbackDays:  7,               // Analyze the reports of the last week
+		BatchSize:     50,              // Batch size for the analysis
+		RetryAttempts: 3,               // Number of retry attempts
+		RetryDelay:    5 * time.Second, // Retry delay
+		BatchID:       fmt.Sprintf("damage-taken-analysis-%s", uuid.New().String()),
+	}, nil
+}
+
+// LoadPerformanceAnalysisParams loads the parameters for the performance analysis workflow
+func LoadPerformanceAnalysisParams(configPath string) (*models.PerformanceAnalysisWorkflowParams, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PerformanceAnalysisWorkflowParams{
+		Dungeon:       config.Dungeons, // Dungeons to analyze
+		LookbackDays:  7,               // Analyze the reports of the last week
+		BatchSize:     50,              // Batch size for the analysis
+		RetryAttempts: 3,               // Number of retry attempts
+		RetryDelay:    5 * time.Second, // Retry delay
+		BatchID:       fmt.Sprintf("performance-analysis-%s", uuid.New().String()),
+	}, nil
+}
+
+// LoadStatWeightsParams loads the parameters for the stat weights workflow
+func LoadStatWeightsParams(configPath string) (*models.StatWeightsWorkflowParams, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StatWeightsWorkflowParams{
+		Spec:          config.Specs,                         // Specs to analyze
+		Metrics:       warcraftlogsBuilds.StatWeightMetrics, // Performance metrics to regress
+		BatchSize:     500,                                  // Batch size for loading the builds
+		RetryAttempts: 3,                                    // Number of retry attempts
+		RetryDelay:    5 * time.Second,                      // Retry delay
+		BatchID:       fmt.Sprintf("stat-weights-%s", uuid.New().String()),
 	}, nil
 }
 
+// LoadAbilityUsageParams loads the parameters for the ability usage analysis workflow
+// The major cooldowns of each spec are read from the ability_usage section of the config.
+func LoadAbilityUsageParams(configPath string) (*models.AbilityUsageAnalysisWorkflowParams, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchBatchSize := config.AbilityUsage.FetchBatchSize
+	if fetchBatchSize <= 0 {
+		fetchBatchSize = 50
+	}
+
+	return &models.AbilityUsageAnalysisWorkflowParams{
+		Dungeon:         config.Dungeons,                     // Dungeons to analyze
+		Cooldowns:       config.AbilityUsage.Cooldowns,       // Major cooldowns of each spec
+		LookbackDays:    7,                                   // Analyze the reports of the last week
+		FetchBatchSize:  fetchBatchSize,                      // Reports whose events are fetched per activity
+		MaxFetchBatches: config.AbilityUsage.MaxFetchBatches, // Fetch batches per run
+		RetryAttempts:   3,                                   // Number of retry attempts
+		RetryDelay:      5 * time.Second,                     // Retry delay
+		BatchID:         fmt.Sprintf("ability-usage-%s", uuid.New().String()),
+	}, nil
+}
+
+// Default retention settings, used when the config file has no retention section
+var defaultRetentionPolicies = []models.RetentionPolicy{
+	{DataType: warcraftlogsBuilds.ReportDataRaw, MaxAge: 14 * 24 * time.Hour},
+}
+
+// LoadReportRetentionParams loads the parameters for the report retention workflow
+// Policies are read from the retention section of the config, data types without a policy are never archived.
+func LoadReportRetentionParams(configPath string) (*models.ReportRetentionWorkflowParams, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := config.Retention.Policies
+	if len(policies) == 0 {
+		policies = defaultRetentionPolicies
+	}
+
+	batchSize := config.Retention.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	return &models.ReportRetentionWorkflowParams{
+		Policies:      policies,        // Data types to archive and their max age
+		BatchSize:     batchSize,       // Reports archived per activity
+		MaxBatches:    50,              // Batches per policy and run
+		RetryAttempts: 3,               // Number of retry attempts
+		RetryDelay:    5 * time.Second, // Retry delay
+		BatchID:       fmt.Sprintf("report-retention-%s", uuid.New().String()),
+	}, nil
+}
+
+// EncounterTargets returns the Mythic+ dungeons followed by the configured raid bosses
+// Raid bosses are converted to dungeons targets, identified by their encounter ID and difficulty
+func EncounterTargets(config *models.WorkflowConfig) []models.Dungeon {
+	targets := make([]models.Dungeon, 0, len(config.Dungeons))
+	targets = append(targets, config.Dungeons...)
+
+	for _, raid := range config.Raids {
+		for _, boss := range raid.Bosses {
+			targets = append(targets, models.Dungeon{
+				ID:          boss.EncounterID,
+				EncounterID: boss.EncounterID,
+				Name:        boss.Name,
+				Slug:        boss.Slug,
+				Difficulty:  raid.BossDifficulty(boss),
+			})
+		}
+	}
+
+	return targets
+}
+
 // === LEGACY FUNCTIONS ===
 
 // LoadConfig loads configuration from file or returns default values
@@ -226,8 +375,87 @@ func ValidateConfig(config *models.WorkflowConfig) error {
 		return fmt.Errorf("at least one spec must be configured")
 	}
 
-	if len(config.Dungeons) == 0 {
-		return fmt.Errorf("at least one dungeon must be configured")
+	if len(config.Dungeons) == 0 && len(config.Raids) == 0 {
+		return fmt.Errorf("at least one dungeon or raid must be configured")
+	}
+
+	if err := validateRaids(config); err != nil {
+		return err
+	}
+
+	return validateRetention(config)
+}
+
+// validateRaids checks the raid bosses targets of the configuration
+// A boss can be configured at several difficulties, once per difficulty, and its encounter ID must not be used by a dungeon
+func validateRaids(config *models.WorkflowConfig) error {
+	dungeonEncounterIDs := make(map[uint32]bool, len(config.Dungeons))
+	for _, dungeon := range config.Dungeons {
+		dungeonEncounterIDs[dungeon.EncounterID] = true
+	}
+
+	// A boss can be targeted at several difficulties, each one once
+	type bossTarget struct {
+		encounterID uint32
+		difficulty  uint32
+	}
+	bossTargets := make(map[bossTarget]bool)
+
+	for _, raid := range config.Raids {
+		if raid.Slug == "" {
+			return fmt.Errorf("raid %q must have a slug", raid.Name)
+		}
+		if len(raid.Bosses) == 0 {
+			return fmt.Errorf("raid %s must have at least one boss", raid.Slug)
+		}
+
+		for _, boss := range raid.Bosses {
+			if boss.EncounterID == 0 {
+				return fmt.Errorf("boss %q of raid %s must have an encounter ID", boss.Name, raid.Slug)
+			}
+			if dungeonEncounterIDs[boss.EncounterID] {
+				return fmt.Errorf("encounter %d of raid %s is also configured as a dungeon", boss.EncounterID, raid.Slug)
+			}
+
+			difficulty := raid.BossDifficulty(boss)
+			switch difficulty {
+			case models.RaidDifficultyNormal, models.RaidDifficultyHeroic, models.RaidDifficultyMythic:
+			default:
+				return fmt.Errorf("invalid difficulty %d for boss %d of raid %s", difficulty, boss.EncounterID, raid.Slug)
+			}
+
+			target := bossTarget{encounterID: boss.EncounterID, difficulty: difficulty}
+			if bossTargets[target] {
+				return fmt.Errorf("encounter %d of raid %s is configured more than once at difficulty %d", boss.EncounterID, raid.Slug, difficulty)
+			}
+			bossTargets[target] = true
+		}
+	}
+
+	return nil
+}
+
+// validateRetention checks the report retention policies of the configuration
+// A data type must be archivable and have a single policy
+func validateRetention(config *models.WorkflowConfig) error {
+	dataTypes := make(map[string]bool, len(config.Retention.Policies))
+	for _, policy := range config.Retention.Policies {
+		if !warcraftlogsBuilds.IsValidReportArchiveDataType(policy.DataType) {
+			return fmt.Errorf("invalid retention data type: %s", policy.DataType)
+		}
+		if policy.MaxAge <= 0 {
+			return fmt.Errorf("retention max age of %s must be greater than 0", policy.DataType)
+		}
+		if dataTypes[policy.DataType] {
+			return fmt.Errorf("retention data type %s is configured more than once", policy.DataType)
+		}
+		dataTypes[policy.DataType] = true
+	}
+
+	switch config.Retention.Store.Type {
+	case "", "local", "s3":
+	default:
+		return fmt.Errorf("invalid archive store type: %s", config.Retention.Store.Type)
 	}
 
 	return nil
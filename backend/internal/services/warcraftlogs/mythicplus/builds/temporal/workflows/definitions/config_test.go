@@ -0,0 +1,72 @@
+package warcraftlogsBuildsTemporalWorkflowsDefinitions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// TestValidateRaids tests the validation of the raid bosses targets
+func TestValidateRaids(t *testing.T) {
+	raid := func(bosses ...models.RaidBoss) *models.WorkflowConfig {
+		return &models.WorkflowConfig{
+			Dungeons: []models.Dungeon{{ID: 12660, EncounterID: 12660, Name: "Ara-Kara"}},
+			Raids: []models.Raid{
+				{
+					Name:       "Liberation of Undermine",
+					Slug:       "liberation-of-undermine",
+					Difficulty: models.RaidDifficultyMythic,
+					Bosses:     bosses,
+				},
+			},
+		}
+	}
+
+	t.Run("a boss is accepted at several difficulties", func(t *testing.T) {
+		config := raid(
+			models.RaidBoss{EncounterID: 3016, Name: "Chrome King Gallywix", Difficulty: models.RaidDifficultyHeroic},
+			models.RaidBoss{EncounterID: 3016, Name: "Chrome King Gallywix"},
+		)
+		assert.NoError(t, validateRaids(config))
+
+		targets := EncounterTargets(config)
+		assert.Len(t, targets, 3)
+		assert.Equal(t, models.RaidDifficultyHeroic, targets[1].Difficulty)
+		assert.Equal(t, models.RaidDifficultyMythic, targets[2].Difficulty)
+	})
+
+	t.Run("a boss is rejected twice at the same difficulty", func(t *testing.T) {
+		config := raid(
+			models.RaidBoss{EncounterID: 3016, Name: "Chrome King Gallywix", Difficulty: models.RaidDifficultyMythic},
+			models.RaidBoss{EncounterID: 3016, Name: "Chrome King Gallywix"},
+		)
+		assert.Error(t, validateRaids(config))
+	})
+
+	t.Run("a boss is rejected when its encounter is a dungeon", func(t *testing.T) {
+		assert.Error(t, validateRaids(raid(models.RaidBoss{EncounterID: 12660, Name: "Avanoxx"})))
+	})
+
+	t.Run("a boss is rejected at an unknown difficulty", func(t *testing.T) {
+		assert.Error(t, validateRaids(raid(models.RaidBoss{EncounterID: 3009, Name: "Vexie", Difficulty: 8})))
+	})
+}
+
+// TestValidateRetention tests that only the data types no analysis reads can be archived
+func TestValidateRetention(t *testing.T) {
+	retention := func(dataType string) *models.WorkflowConfig {
+		return &models.WorkflowConfig{
+			Retention: models.RetentionConfig{
+				Policies: []models.RetentionPolicy{{DataType: dataType, MaxAge: 14 * 24 * time.Hour}},
+			},
+		}
+	}
+
+	assert.NoError(t, validateRetention(retention("raw_data")))
+	for _, dataType := range []string{"damage_taken", "death_events", "damage_done", "healing_done", "player_details_dps"} {
+		assert.Error(t, validateRetention(retention(dataType)), dataType)
+	}
+}
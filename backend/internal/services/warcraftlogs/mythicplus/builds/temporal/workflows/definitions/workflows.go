@@ -57,3 +57,51 @@ type TalentAnalysisWorkflow interface {
 type StatAnalysisWorkflow interface {
 	Execute(ctx workflow.Context, config models.StatAnalysisWorkflowParams) (*models.StatAnalysisWorkflowResult, error)
 }
+
+// DeathAnalysisWorkflow defines the interface for the death analysis workflow
+// This workflow analyzes the deaths of the stored reports for each dungeon and key level bracket
+type DeathAnalysisWorkflow interface {
+	Execute(ctx workflow.Context, config models.DeathAnalysisWorkflowParams) (*models.DeathAnalysisWorkflowResult, error)
+}
+
+// DamageTakenAnalysisWorkflow defines the interface for the damage taken analysis workflow
+// This workflow analyzes the damage taken of the stored reports for each dungeon and key level bracket
+type DamageTakenAnalysisWorkflow interface {
+	Execute(ctx workflow.Context, config models.DamageTakenAnalysisWorkflowParams) (*models.DamageTakenAnalysisWorkflowResult, error)
+}
+
+// PerformanceAnalysisWorkflow defines the interface for the performance analysis workflow
+// This workflow analyzes the performance of the stored reports for each dungeon and key level bracket
+type PerformanceAnalysisWorkflow interface {
+	Execute(ctx workflow.Context, config models.PerformanceAnalysisWorkflowParams) (*models.PerformanceAnalysisWorkflowResult, error)
+}
+
+// StatWeightsWorkflow defines the interface for the stat weights workflow
+// This workflow estimates the weight of the secondary stats of each spec from the stored builds
+type StatWeightsWorkflow interface {
+	Execute(ctx workflow.Context, config models.StatWeightsWorkflowParams) (*models.StatWeightsWorkflowResult, error)
+}
+
+// AbilityUsageWorkflow defines the interface for the ability usage analysis workflow
+// This workflow fetches the casts, interrupts and dispels of the stored reports and computes their statistics
+type AbilityUsageWorkflow interface {
+	Execute(ctx workflow.Context, config models.AbilityUsageAnalysisWorkflowParams) (*models.AbilityUsageAnalysisWorkflowResult, error)
+}
+
+// ReportAnalysisWorkflow defines the interface for the report analysis workflow
+// This workflow compares the players of a report submitted by a user with the stored statistics
+type ReportAnalysisWorkflow interface {
+	Execute(ctx workflow.Context, params models.ReportAnalysisWorkflowParams) (*models.ReportAnalysisWorkflowResult, error)
+}
+
+// ReportRetentionWorkflow defines the interface for the report retention workflow
+// This workflow moves the large report data of the processed reports to the object store
+type ReportRetentionWorkflow interface {
+	Execute(ctx workflow.Context, params models.ReportRetentionWorkflowParams) (*models.ReportRetentionWorkflowResult, error)
+}
+
+// ReportRestoreWorkflow defines the interface for the report restore workflow
+// This workflow writes archived report data back and can extract the builds of the reports again
+type ReportRestoreWorkflow interface {
+	Execute(ctx workflow.Context, params models.ReportRestoreWorkflowParams) (*models.ReportRetentionWorkflowResult, error)
+}
@@ -41,6 +41,63 @@ type StatAnalysisWorkflowParams struct {
 	BatchID       string        `json:"batch_id"`       // Batch ID for the workflow
 }
 
+// DeathAnalysisWorkflowParams contains the parameters for the death analysis workflow
+// It defines the input configuration for analyzing deaths from the stored reports.
+type DeathAnalysisWorkflowParams struct {
+	Dungeon       []Dungeon     `json:"dungeon"`        // Dungeon is a struct that contains the dungeon name and encounter ID
+	LookbackDays  int32         `json:"lookback_days"`  // Number of days of reports to analyze
+	BatchSize     int32         `json:"batch_size"`     // Batch size for processing
+	RetryAttempts int32         `json:"retry_attempts"` // Number of retries in case of failure
+	RetryDelay    time.Duration `json:"retry_delay"`    // Delay between retries
+	BatchID       string        `json:"batch_id"`       // Batch ID for the workflow
+}
+
+// DamageTakenAnalysisWorkflowParams contains the parameters for the damage taken analysis workflow
+// It defines the input configuration for analyzing the damage taken from the stored reports.
+type DamageTakenAnalysisWorkflowParams struct {
+	Dungeon       []Dungeon     `json:"dungeon"`        // Dungeon is a struct that contains the dungeon name and encounter ID
+	LookbackDays  int32         `json:"lookback_days"`  // Number of days of reports to analyze
+	BatchSize     int32         `json:"batch_size"`     // Batch size for processing
+	RetryAttempts int32         `json:"retry_attempts"` // Number of retries in case of failure
+	RetryDelay    time.Duration `json:"retry_delay"`    // Delay between retries
+	BatchID       string        `json:"batch_id"`       // Batch ID for the workflow
+}
+
+// PerformanceAnalysisWorkflowParams contains the parameters for the performance analysis workflow
+// It defines the input configuration for the performance analysis of the stored reports.
+type PerformanceAnalysisWorkflowParams struct {
+	Dungeon       []Dungeon     `json:"dungeon"`        // Dungeon is a struct that contains the dungeon name and encounter ID
+	LookbackDays  int32         `json:"lookback_days"`  // Number of days of reports to analyze
+	BatchSize     int32         `json:"batch_size"`     // Batch size for processing
+	RetryAttempts int32         `json:"retry_attempts"` // Number of retries in case of failure
+	RetryDelay    time.Duration `json:"retry_delay"`    // Delay between retries
+	BatchID       string        `json:"batch_id"`       // Batch ID for the workflow
+}
+
+// StatWeightsWorkflowParams contains the parameters for the stat weights workflow
+// It defines the specs and the performance metrics the secondary stats are regressed against.
+type StatWeightsWorkflowParams struct {
+	Spec          []ClassSpec   `json:"spec"`           // ClassSpec is a struct that contains the class name and spec name
+	Metrics       []string      `json:"metrics"`        // Performance metrics: keystone_level, score, dps or hps
+	BatchSize     int32         `json:"batch_size"`     // Batch size for loading the builds
+	RetryAttempts int32         `json:"retry_attempts"` // Number of retries in case of failure
+	RetryDelay    time.Duration `json:"retry_delay"`    // Delay between retries
+	BatchID       string        `json:"batch_id"`       // Batch ID for the workflow
+}
+
+// AbilityUsageAnalysisWorkflowParams contains the parameters for the ability usage analysis workflow
+// It defines the events fetch of the stored reports and the dungeons whose usage statistics are computed.
+type AbilityUsageAnalysisWorkflowParams struct {
+	Dungeon         []Dungeon       `json:"dungeon"`           // Dungeon is a struct that contains the dungeon name and encounter ID
+	Cooldowns       []SpecCooldowns `json:"cooldowns"`         // Major cooldowns of each spec
+	LookbackDays    int32           `json:"lookback_days"`     // Number of days of reports to analyze
+	FetchBatchSize  int32           `json:"fetch_batch_size"`  // Reports whose events are fetched per activity
+	MaxFetchBatches int32           `json:"max_fetch_batches"` // Fetch batches per run, 0 for no limit
+	RetryAttempts   int32           `json:"retry_attempts"`    // Number of retries in case of failure
+	RetryDelay      time.Duration   `json:"retry_delay"`       // Delay between retries
+	BatchID         string          `json:"batch_id"`          // Batch ID for the workflow
+}
+
 // == Legacy workflows ==
 
 // AnalysisWorkflowConfig contains the specific parameters for the analysis workflow
@@ -55,3 +112,12 @@ type AnalysisWorkflowConfig struct {
 	RetryAttempts int32         `json:"retry_attempts"` // Number of retries in case of failure
 	RetryDelay    time.Duration `json:"retry_delay"`    // Delay between retries
 }
+
+// ReportAnalysisWorkflowParams contains the parameters for the report analysis workflow
+// It defines the report submitted by a user and the job tracking the analysis.
+type ReportAnalysisWorkflowParams struct {
+	JobID      string `json:"job_id"`      // ID of the report analysis job
+	ReportCode string `json:"report_code"` // Code of the WarcraftLogs report
+	FightID    int    `json:"fight_id"`    // Fight to analyze, 0 for every Mythic+ fight
+	UserID     *uint  `json:"user_id"`     // User whose WarcraftLogs account is used, nil for public reports
+}
@@ -38,3 +38,73 @@ type StatAnalysisWorkflowResult struct {
 	CompletedAt       time.Time `json:"completed_at"`
 	BatchID           string    `json:"batch_id"`
 }
+
+// DeathAnalysisWorkflowResult represents the complete results of the death analysis
+// It contains statistics on analyzed deaths from the stored reports.
+type DeathAnalysisWorkflowResult struct {
+	DeathsAnalyzed    int32     `json:"deaths_analyzed"`    // Death events analyzed
+	RunsAnalyzed      int32     `json:"runs_analyzed"`      // Reports analyzed
+	StatisticsStored  int32     `json:"statistics_stored"`  // Death statistics persisted
+	DungeonsProcessed int32     `json:"dungeons_processed"` // Dungeons processed
+	StartedAt         time.Time `json:"started_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+	BatchID           string    `json:"batch_id"`
+}
+
+// DamageTakenAnalysisWorkflowResult represents the complete results of the damage taken analysis
+// It contains statistics on analyzed damage taken from the stored reports.
+type DamageTakenAnalysisWorkflowResult struct {
+	RunsAnalyzed      int32     `json:"runs_analyzed"`      // Reports analyzed
+	StatisticsStored  int32     `json:"statistics_stored"`  // Damage taken statistics persisted
+	DungeonsProcessed int32     `json:"dungeons_processed"` // Dungeons processed
+	StartedAt         time.Time `json:"started_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+	BatchID           string    `json:"batch_id"`
+}
+
+// PerformanceAnalysisWorkflowResult represents the complete results of the performance analysis
+// It contains statistics on the DPS and HPS analyzed from the stored reports.
+type PerformanceAnalysisWorkflowResult struct {
+	PlayersAnalyzed   int32     `json:"players_analyzed"`   // Players with a throughput value
+	RunsAnalyzed      int32     `json:"runs_analyzed"`      // Reports analyzed
+	StatisticsStored  int32     `json:"statistics_stored"`  // Performance statistics persisted
+	DungeonsProcessed int32     `json:"dungeons_processed"` // Dungeons processed
+	StartedAt         time.Time `json:"started_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+	BatchID           string    `json:"batch_id"`
+}
+
+// StatWeightsWorkflowResult represents the complete results of the stat weights analysis
+// It contains statistics on the builds used by the regressions.
+type StatWeightsWorkflowResult struct {
+	SamplesAnalyzed  int32     `json:"samples_analyzed"`  // Builds used by the regressions
+	WeightsStored    int32     `json:"weights_stored"`    // Stat weights persisted
+	SpecsProcessed   int32     `json:"specs_processed"`   // Specializations processed
+	MetricsProcessed int32     `json:"metrics_processed"` // Spec and metric regressions fitted
+	StartedAt        time.Time `json:"started_at"`
+	CompletedAt      time.Time `json:"completed_at"`
+	BatchID          string    `json:"batch_id"`
+}
+
+// AbilityUsageAnalysisWorkflowResult represents the complete results of the ability usage analysis
+// It contains statistics on the events fetched and the usages analyzed from the stored reports.
+type AbilityUsageAnalysisWorkflowResult struct {
+	ReportsFetched    int32     `json:"reports_fetched"`    // Reports whose events have been fetched
+	ReportsFailed     int32     `json:"reports_failed"`     // Reports whose events could not be fetched
+	PlayersAnalyzed   int32     `json:"players_analyzed"`   // Players with ability usages
+	StatisticsStored  int32     `json:"statistics_stored"`  // Ability usage statistics persisted
+	DungeonsProcessed int32     `json:"dungeons_processed"` // Dungeons processed
+	StartedAt         time.Time `json:"started_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+	BatchID           string    `json:"batch_id"`
+}
+
+// ReportAnalysisWorkflowResult represents the results of a report analysis
+// The comparison itself is stored in the report analysis job.
+type ReportAnalysisWorkflowResult struct {
+	JobID           string    `json:"job_id"`
+	FightsAnalyzed  int32     `json:"fights_analyzed"`  // Mythic+ fights analyzed
+	PlayersAnalyzed int32     `json:"players_analyzed"` // Players compared with the statistics
+	StartedAt       time.Time `json:"started_at"`
+	CompletedAt     time.Time `json:"completed_at"`
+}
@@ -19,6 +19,7 @@ type BatchResult struct {
 	ClassName         string                       `json:"class_name"`           // Class name
 	SpecName          string                       `json:"spec_name"`            // Spec name
 	EncounterID       uint32                       `json:"encounter_id"`         // Encounter ID
+	Difficulty        uint32                       `json:"difficulty,omitempty"` // Raid difficulty, 0 for Mythic+
 	ProcessedItems    int32                        `json:"processed_items"`      // Processed items
 	RankingsCount     int32                        `json:"rankings_count"`       // Rankings count
 	ProcessedAt       time.Time                    `json:"processed_at"`         // Processed at
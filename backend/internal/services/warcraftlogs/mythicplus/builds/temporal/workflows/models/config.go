@@ -16,6 +16,7 @@ const (
 type RankingsWorkflowParams struct {
 	Specs              []ClassSpec   `json:"specs"`
 	Dungeons           []Dungeon     `json:"dungeons"`
+	Raids              []Raid        `json:"raids,omitempty"`
 	MaxRankingsPerSpec int32         `json:"max_rankings_per_spec"`
 	BatchSize          int32         `json:"batch_size"`
 	RetryDelay         time.Duration `json:"retry_delay"`
@@ -62,6 +63,31 @@ type BuildsWorkflowParams struct {
 	ContinuationCount int32 `json:"continuation_count"` // Number of times the workflow has been continued
 }
 
+// ReportRetentionWorkflowParams contains the parameters for the report retention workflow
+type ReportRetentionWorkflowParams struct {
+	Policies      []RetentionPolicy `json:"policies"`       // Data types to archive and their max age
+	BatchSize     int32             `json:"batch_size"`     // Reports archived per activity
+	MaxBatches    int32             `json:"max_batches"`    // Batches per policy and run, 0 for no limit
+	RetryAttempts int32             `json:"retry_attempts"` // Number of retries in case of failure
+	RetryDelay    time.Duration     `json:"retry_delay"`    // Delay between retries
+	BatchID       string            `json:"batch_id"`
+}
+
+// ReportReference identifies a fight of a report
+type ReportReference struct {
+	Code    string `json:"code"`
+	FightID int    `json:"fight_id"`
+}
+
+// ReportRestoreWorkflowParams contains the parameters for the report restore workflow
+// Restored reports can be sent back through the build extraction with Reprocess.
+type ReportRestoreWorkflowParams struct {
+	Reports   []ReportReference `json:"reports"`
+	DataTypes []string          `json:"data_types"` // Data types to restore, empty for every archived data type
+	Reprocess bool              `json:"reprocess"`  // Extract the builds of the restored reports again
+	BatchID   string            `json:"batch_id"`
+}
+
 // WorkflowConfig represents the root configuration structure
 // Legacy config
 // TODO: Remove this when the new workflow struct is fully implemented
@@ -70,6 +96,49 @@ type WorkflowConfig struct {
 	Worker   WorkerConfig   `json:"worker" yaml:"worker"`
 	Specs    []ClassSpec    `json:"specs" yaml:"specs"`
 	Dungeons []Dungeon      `json:"dungeons" yaml:"dungeons"`
+	Raids    []Raid         `json:"raids" yaml:"raids"`
+
+	Retention    RetentionConfig    `json:"retention" yaml:"retention"`
+	AbilityUsage AbilityUsageConfig `json:"ability_usage" yaml:"ability_usage"`
+}
+
+// AbilityUsageConfig contains the settings of the ability usage analysis
+type AbilityUsageConfig struct {
+	FetchBatchSize  int32           `json:"fetch_batch_size" yaml:"fetch_batch_size"`   // Reports whose events are fetched per activity
+	MaxFetchBatches int32           `json:"max_fetch_batches" yaml:"max_fetch_batches"` // Fetch batches per run, 0 for no limit
+	Cooldowns       []SpecCooldowns `json:"cooldowns" yaml:"cooldowns"`
+}
+
+// SpecCooldowns lists the spell IDs of the major cooldowns of a spec
+type SpecCooldowns struct {
+	ClassName string  `json:"class_name" yaml:"class_name"`
+	SpecName  string  `json:"spec_name" yaml:"spec_name"`
+	SpellIDs  []int64 `json:"spell_ids" yaml:"spell_ids"`
+}
+
+// RetentionConfig contains the settings of the report retention workflow
+type RetentionConfig struct {
+	BatchSize int32              `json:"batch_size" yaml:"batch_size"`
+	Store     ArchiveStoreConfig `json:"store" yaml:"store"`
+	Policies  []RetentionPolicy  `json:"policies" yaml:"policies"`
+}
+
+// ArchiveStoreConfig describes the object store the report archives are written to
+// Type is "local" (LocalPath) or "s3" (Endpoint, Region, Bucket and Prefix), the S3 credentials come from the environment.
+type ArchiveStoreConfig struct {
+	Type      string `json:"type" yaml:"type"`
+	LocalPath string `json:"local_path" yaml:"local_path"`
+	Endpoint  string `json:"endpoint" yaml:"endpoint"`
+	Region    string `json:"region" yaml:"region"`
+	Bucket    string `json:"bucket" yaml:"bucket"`
+	Prefix    string `json:"prefix" yaml:"prefix"`
+}
+
+// RetentionPolicy defines after how long a report data type is archived
+// Only the reports whose builds have been extracted are archived.
+type RetentionPolicy struct {
+	DataType string        `json:"data_type" yaml:"data_type"` // Report column, e.g. "raw_data"
+	MaxAge   time.Duration `json:"max_age" yaml:"max_age"`     // Age of the report after which the data is archived
 }
 
 // RankingsConfig contains settings for rankings processing
@@ -92,9 +161,44 @@ type ClassSpec struct {
 }
 
 // Dungeon represents a Mythic+ dungeon
+// It is also used for raid bosses, Difficulty is then set to the raid difficulty (0 for Mythic+)
 type Dungeon struct {
 	ID          uint32 `json:"id" yaml:"id"`
 	EncounterID uint32 `json:"encounter_id" yaml:"encounter_id"`
 	Name        string `json:"name" yaml:"name"`
 	Slug        string `json:"slug" yaml:"slug"`
+	Difficulty  uint32 `json:"difficulty,omitempty" yaml:"-"`
+}
+
+// WarcraftLogs raid difficulties
+const (
+	RaidDifficultyNormal uint32 = 3
+	RaidDifficultyHeroic uint32 = 4
+	RaidDifficultyMythic uint32 = 5
+)
+
+// Raid represents a raid zone whose bosses are targeted by the builds pipeline
+type Raid struct {
+	ZoneID     uint32     `json:"zone_id" yaml:"zone_id"`
+	Name       string     `json:"name" yaml:"name"`
+	Slug       string     `json:"slug" yaml:"slug"`
+	Difficulty uint32     `json:"difficulty" yaml:"difficulty"`
+	Bosses     []RaidBoss `json:"bosses" yaml:"bosses"`
+}
+
+// RaidBoss represents a raid boss encounter
+// Difficulty overrides the difficulty of the raid when set
+type RaidBoss struct {
+	EncounterID uint32 `json:"encounter_id" yaml:"encounter_id"`
+	Name        string `json:"name" yaml:"name"`
+	Slug        string `json:"slug" yaml:"slug"`
+	Difficulty  uint32 `json:"difficulty,omitempty" yaml:"difficulty"`
+}
+
+// BossDifficulty returns the difficulty targeted for a boss of the raid
+func (r Raid) BossDifficulty(boss RaidBoss) uint32 {
+	if boss.Difficulty != 0 {
+		return boss.Difficulty
+	}
+	return r.Difficulty
 }
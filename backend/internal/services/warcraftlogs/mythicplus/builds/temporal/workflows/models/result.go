@@ -51,6 +51,7 @@ type BuildsBatchResult struct {
 	BatchID           string           `json:"batch_id"`             // Batch ID for tracking
 	ReportsProcessed  int32            `json:"reports_processed"`    // Number of reports processed
 	BuildsProcessed   int32            `json:"builds_processed"`     // Number of builds processed
+	BuildsRejected    int32            `json:"builds_rejected"`      // Number of builds quarantined by the validation
 	BuildsByClassSpec map[string]int32 `json:"builds_by_class_spec"` // Builds by class+spec
 	Status            string           `json:"status"`               // Status (completed, failed)
 	Error             string           `json:"error"`                // Error message if failure
@@ -59,6 +60,7 @@ type BuildsBatchResult struct {
 // BuildsWorkflowResult holds the results of the builds workflow
 type BuildsWorkflowResult struct {
 	BuildsProcessed   int32            `json:"builds_processed"`     // Number of builds processed
+	BuildsRejected    int32            `json:"builds_rejected"`      // Number of builds quarantined by the validation
 	ReportsProcessed  int32            `json:"reports_processed"`    // Number of reports processed
 	BuildsByClassSpec map[string]int32 `json:"builds_by_class_spec"` // Number of builds by class+spec
 	BatchID           string           `json:"batch_id"`             // Batch ID for tracking
@@ -66,6 +68,20 @@ type BuildsWorkflowResult struct {
 	CompletedAt       time.Time        `json:"completed_at"`         // Timestamp when the workflow completed
 }
 
+// ReportRetentionWorkflowResult holds the results of the report retention workflow
+// The same structure is returned by the archive and restore activities.
+type ReportRetentionWorkflowResult struct {
+	ReportsArchived   int32     `json:"reports_archived"`   // Report data types moved to the object store
+	ReportsRestored   int32     `json:"reports_restored"`   // Report data types written back to the reports
+	ReportsFailed     int32     `json:"reports_failed"`     // Report data types that could not be archived or restored
+	BytesArchived     int64     `json:"bytes_archived"`     // Size of the archived JSON
+	BytesCompressed   int64     `json:"bytes_compressed"`   // Size of the archive objects
+	BuildsReprocessed int32     `json:"builds_reprocessed"` // Builds extracted again from restored reports
+	BatchID           string    `json:"batch_id"`
+	StartedAt         time.Time `json:"started_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+}
+
 // Activities Results Models
 
 // ReportProcessingResult holds the results of processing a batch of rankings for reports
@@ -84,6 +100,7 @@ type BuildsActivityResult struct {
 	ProcessedBuildsCount int32            `json:"processed_builds_count"` // Builds successfully stored in this batch
 	SuccessCount         int32            `json:"success_count"`          // Reports successfully processed in this batch
 	FailureCount         int32            `json:"failure_count"`          // Reports that failed processing in this batch
+	RejectedBuildsCount  int32            `json:"rejected_builds_count"`  // Builds quarantined by the validation stage in this batch
 	BuildsByClassSpec    map[string]int32 `json:"builds_by_class_spec"`   // Builds counted by class+spec FOR THIS BATCH
 	ProcessedAt          time.Time        `json:"processed_at"`           // Timestamp for this batch activity completion
 }
@@ -1,6 +1,7 @@
 package warcraftlogsBuildsTemporalWorkflowsRankings
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -41,7 +42,7 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 	}
 
 	if len(params.Dungeons) == 0 {
-		return nil, fmt.Errorf("no dungeons found in parameters")
+		return nil, fmt.Errorf("no dungeons or raid bosses found in parameters")
 	}
 
 	// WorkflowState Tracking
@@ -77,6 +78,15 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 		// Continue execution even if state tracking fails
 	}
 
+	// Register the raid bosses before fetching their rankings
+	// so the Mythic+ analytics can exclude them from their aggregates
+	if len(params.Raids) > 0 {
+		if err := workflow.ExecuteActivity(stateCtx, definitions.StoreRaidEncountersActivity, params.Raids).Get(ctx, nil); err != nil {
+			logger.Error("Failed to store raid encounters", "error", err)
+			return nil, fmt.Errorf("failed to store raid encounters: %w", err)
+		}
+	}
+
 	// Track processed specs and dungeons
 	processedSpecs := make(map[string]bool)
 	processedDungeons := make(map[string]bool)
@@ -86,10 +96,11 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 		StartToCloseTimeout: time.Hour * 12,
 		HeartbeatTimeout:    time.Minute * 10,
 		RetryPolicy: &temporal.RetryPolicy{
-			InitialInterval:    time.Second * time.Duration(params.RetryDelay.Seconds()),
-			BackoffCoefficient: 2.0,
-			MaximumInterval:    time.Minute * 10,
-			MaximumAttempts:    int32(params.MaxAttempts),
+			InitialInterval:        time.Second * time.Duration(params.RetryDelay.Seconds()),
+			BackoffCoefficient:     2.0,
+			MaximumInterval:        time.Minute * 10,
+			MaximumAttempts:        int32(params.MaxAttempts),
+			NonRetryableErrorTypes: []string{"RATE_LIMIT_ERROR"},
 		},
 	}
 	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
@@ -120,7 +131,8 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 			logger.Error("Failed to update workflow state", "error", err)
 		}
 
-		// Process each dungeon for this spec
+		// Collect the dungeons of this spec not processed yet
+		var dungeons []models.Dungeon
 		for _, dungeon := range params.Dungeons {
 			dungeonKey := common.GenerateDungeonKey(spec, dungeon)
 			if processedDungeons[dungeonKey] {
@@ -129,9 +141,12 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 					"spec", spec.SpecName)
 				continue
 			}
+			dungeons = append(dungeons, dungeon)
+		}
 
-			logger.Info("Processing dungeon",
-				"dungeon", dungeon.Name,
+		if len(dungeons) > 0 {
+			logger.Info("Processing dungeons",
+				"dungeonCount", len(dungeons),
 				"class", spec.ClassName,
 				"spec", spec.SpecName)
 
@@ -142,14 +157,68 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 				MaxAttempts: params.MaxAttempts,
 			}
 
-			// Execute the activity to fetch and store rankings
-			var batchResult models.BatchResult
+			// Execute the activity to fetch and store the rankings of all the dungeons in batched requests
+			var batchResults []*models.BatchResult
 			err := workflow.ExecuteActivity(activityCtx,
-				definitions.FetchRankingsActivity,
-				spec, dungeon, batchConfig).Get(ctx, &batchResult)
+				definitions.FetchRankingsBatchActivity,
+				spec, dungeons, batchConfig).Get(ctx, &batchResults)
+
+			// recordBatchResults marks the rankings of the stored dungeons and the dungeons as processed
+			recordBatchResults := func(batchResults []*models.BatchResult) {
+				dungeonsByEncounter := make(map[[2]uint32]models.Dungeon, len(dungeons))
+				for _, dungeon := range dungeons {
+					dungeonsByEncounter[[2]uint32{dungeon.EncounterID, dungeon.Difficulty}] = dungeon
+				}
+
+				for _, batchResult := range batchResults {
+					dungeon := dungeonsByEncounter[[2]uint32{batchResult.EncounterID, batchResult.Difficulty}]
+
+					// Mark rankings with batch ID and status
+					if batchResult.RankingsCount > 0 {
+						markErr := workflow.ExecuteActivity(activityCtx, definitions.MarkRankingsForReportActivity,
+							batchResult.ClassName, batchResult.SpecName, batchResult.EncounterID, params.BatchID).Get(ctx, nil)
+
+						if markErr != nil {
+							logger.Error("Failed to mark rankings for report processing",
+								"class", spec.ClassName,
+								"spec", spec.SpecName,
+								"error", markErr)
+						}
+					}
+
+					// Mark dungeon as processed
+					processedDungeons[common.GenerateDungeonKey(spec, dungeon)] = true
+
+					// Update result with data from this batch
+					result.RankingsProcessed += batchResult.ProcessedItems
+
+					logger.Info("Successfully processed rankings",
+						"class", spec.ClassName,
+						"spec", spec.SpecName,
+						"dungeon", dungeon.Name,
+						"itemsProcessed", batchResult.ProcessedItems,
+						"totalProcessed", result.RankingsProcessed)
+				}
+			}
+
+			// The dungeons stored before a rate limit are the details of the error
+			rateLimited := common.IsRateLimitError(err)
+			var appErr *temporal.ApplicationError
+			if errors.As(err, &appErr) && appErr.Type() == "RATE_LIMIT_ERROR" {
+				rateLimited = true
+				if appErr.HasDetails() {
+					if detailsErr := appErr.Details(&batchResults); detailsErr != nil {
+						logger.Error("Failed to read the rankings stored before the rate limit", "error", detailsErr)
+					}
+				}
+			}
+
+			if err == nil || rateLimited {
+				recordBatchResults(batchResults)
+			}
 
 			if err != nil {
-				if common.IsRateLimitError(err) {
+				if rateLimited {
 					// Update workflow state for rate limit
 					workflowState := &warcraftlogsBuilds.WorkflowState{
 						ID:           workflowStateID,
@@ -161,9 +230,9 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 
 					logger.Info("Rate limit reached during rankings processing",
 						"class", spec.ClassName,
-						"spec", spec.SpecName,
-						"dungeon", dungeon.Name)
+						"spec", spec.SpecName)
 
+					result.DungeonsProcessed = int32(len(processedDungeons))
 					result.CompletedAt = workflow.Now(ctx)
 					return result, err
 				}
@@ -171,48 +240,20 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 				logger.Error("Failed to process rankings",
 					"class", spec.ClassName,
 					"spec", spec.SpecName,
-					"dungeon", dungeon.Name,
 					"error", err)
 
 				// Update workflow state with error
 				workflowState := &warcraftlogsBuilds.WorkflowState{
 					ID:           workflowStateID,
-					ErrorMessage: fmt.Sprintf("Error processing %s: %v", dungeonKey, err),
+					ErrorMessage: fmt.Sprintf("Error processing %s: %v", specKey, err),
 					UpdatedAt:    workflow.Now(ctx),
 				}
 				_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
 
-				// Continue with next dungeon on error
+				// Continue with next spec on error
 				continue
 			}
 
-			// Mark rankings with batch ID and status
-			if batchResult.RankingsCount > 0 {
-				// This activity would be a new one created to support the workflow
-				err = workflow.ExecuteActivity(activityCtx, definitions.MarkRankingsForReportActivity,
-					batchResult.ClassName, batchResult.SpecName, batchResult.EncounterID, params.BatchID).Get(ctx, nil)
-
-				if err != nil {
-					logger.Error("Failed to mark rankings for report processing",
-						"class", spec.ClassName,
-						"spec", spec.SpecName,
-						"error", err)
-				}
-			}
-
-			// Mark dungeon as processed
-			processedDungeons[dungeonKey] = true
-
-			// Update result with data from this batch
-			result.RankingsProcessed += batchResult.ProcessedItems
-
-			logger.Info("Successfully processed rankings",
-				"class", spec.ClassName,
-				"spec", spec.SpecName,
-				"dungeon", dungeon.Name,
-				"itemsProcessed", batchResult.ProcessedItems,
-				"totalProcessed", result.RankingsProcessed)
-
 			// Update workflow state with progress
 			workflowState := &warcraftlogsBuilds.WorkflowState{
 				ID:             workflowStateID,
@@ -221,7 +262,7 @@ func (w *RankingsWorkflow) Execute(ctx workflow.Context, params models.RankingsW
 			}
 			_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
 
-			// Small delay between dungeons to avoid overwhelming the API
+			// Small delay between specs to avoid overwhelming the API
 			workflow.Sleep(ctx, time.Second*2)
 		}
 
@@ -32,6 +32,7 @@ type Dungeon struct {
 	EncounterID uint   `json:"encounter_id" yaml:"encounter_id"`
 	Name        string `json:"name" yaml:"name"`
 	Slug        string `json:"slug" yaml:"slug"`
+	Difficulty  uint   `json:"difficulty,omitempty" yaml:"-"`
 }
 
 // BatchConfig defines parameters for batch processing
@@ -0,0 +1,80 @@
+package warcraftlogsBuildsTemporalWorkflowsReportAnalysis
+
+import (
+	"errors"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// ReportAnalysisWorkflow implements the on-demand report analysis workflow
+type ReportAnalysisWorkflow struct{}
+
+// NewReportAnalysisWorkflow creates a new instance of the report analysis workflow
+func NewReportAnalysisWorkflow() definitions.ReportAnalysisWorkflow {
+	return &ReportAnalysisWorkflow{}
+}
+
+// Execute runs the report analysis workflow
+// The progress and the result are tracked in the report analysis job, which is polled by the API.
+func (w *ReportAnalysisWorkflow) Execute(ctx workflow.Context, params models.ReportAnalysisWorkflowParams) (*models.ReportAnalysisWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting report analysis workflow",
+		"jobID", params.JobID,
+		"reportCode", params.ReportCode,
+		"fightID", params.FightID)
+
+	// Options for the analysis activity
+	activityOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 30,
+		HeartbeatTimeout:    time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second * 5,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute * 2,
+			MaximumAttempts:    3,
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
+
+	var result models.ReportAnalysisWorkflowResult
+	err := workflow.ExecuteActivity(activityCtx, definitions.AnalyzeReportActivity, params).Get(ctx, &result)
+	if err == nil {
+		logger.Info("Report analysis workflow completed",
+			"jobID", params.JobID,
+			"fightsAnalyzed", result.FightsAnalyzed,
+			"playersAnalyzed", result.PlayersAnalyzed)
+		return &result, nil
+	}
+
+	logger.Error("Failed to analyze report", "jobID", params.JobID, "error", err)
+
+	// Options for the job state activity
+	stateOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 1.5,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	stateCtx := workflow.WithActivityOptions(ctx, stateOpts)
+
+	// Keep the message of the activity error, without the Temporal wrapping
+	message := err.Error()
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		message = appErr.Error()
+	}
+
+	if failErr := workflow.ExecuteActivity(stateCtx, definitions.FailReportAnalysisActivity, params.JobID, message).Get(ctx, nil); failErr != nil {
+		logger.Error("Failed to mark report analysis job as failed", "jobID", params.JobID, "error", failErr)
+	}
+
+	return nil, err
+}
@@ -0,0 +1,97 @@
+package warcraftlogsBuildsTemporalWorkflowsReportRetention
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// reprocessBatchSize is the number of restored reports sent to each build extraction
+const reprocessBatchSize = 10
+
+// ReportRestoreWorkflow implements the report restore workflow
+type ReportRestoreWorkflow struct{}
+
+// NewReportRestoreWorkflow creates a new instance of the report restore workflow
+func NewReportRestoreWorkflow() definitions.ReportRestoreWorkflow {
+	return &ReportRestoreWorkflow{}
+}
+
+// Execute runs the report restore workflow
+// The archived data is written back to the reports, then their builds are extracted again if Reprocess is set.
+func (w *ReportRestoreWorkflow) Execute(ctx workflow.Context, params models.ReportRestoreWorkflowParams) (*models.ReportRetentionWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting report restore workflow",
+		"reportCount", len(params.Reports),
+		"dataTypes", params.DataTypes,
+		"reprocess", params.Reprocess)
+
+	if len(params.Reports) == 0 {
+		return nil, fmt.Errorf("no reports found in parameters")
+	}
+
+	startedAt := workflow.Now(ctx)
+
+	activityOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour,
+		HeartbeatTimeout:    time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second * 5,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute * 5,
+			MaximumAttempts:    3,
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
+
+	// 1. Restore the archived data
+	var result models.ReportRetentionWorkflowResult
+	if err := workflow.ExecuteActivity(activityCtx,
+		definitions.RestoreReportsActivity,
+		params.Reports,
+		params.DataTypes,
+	).Get(ctx, &result); err != nil {
+		return nil, fmt.Errorf("failed to restore reports: %w", err)
+	}
+	result.StartedAt = startedAt
+	result.BatchID = params.BatchID
+
+	// 2. Extract the builds again
+	if params.Reprocess {
+		for start := 0; start < len(params.Reports); start += reprocessBatchSize {
+			end := min(start+reprocessBatchSize, len(params.Reports))
+
+			var reports []*warcraftlogsBuilds.Report
+			if err := workflow.ExecuteActivity(activityCtx,
+				definitions.GetRestoredReportsActivity,
+				params.Reports[start:end],
+			).Get(ctx, &reports); err != nil {
+				return nil, fmt.Errorf("failed to load restored reports: %w", err)
+			}
+
+			var buildsResult models.BuildsActivityResult
+			if err := workflow.ExecuteActivity(activityCtx,
+				definitions.ProcessBuildsActivity,
+				reports,
+			).Get(ctx, &buildsResult); err != nil {
+				return nil, fmt.Errorf("failed to extract builds of restored reports: %w", err)
+			}
+			result.BuildsReprocessed += buildsResult.ProcessedBuildsCount
+		}
+	}
+
+	result.CompletedAt = workflow.Now(ctx)
+
+	logger.Info("Report restore workflow completed",
+		"reportsRestored", result.ReportsRestored,
+		"reportsFailed", result.ReportsFailed,
+		"buildsReprocessed", result.BuildsReprocessed)
+
+	return &result, nil
+}
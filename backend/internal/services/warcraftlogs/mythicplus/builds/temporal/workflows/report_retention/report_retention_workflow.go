@@ -0,0 +1,160 @@
+package warcraftlogsBuildsTemporalWorkflowsReportRetention
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	warcraftlogsBuilds "wowperf/internal/models/warcraftlogs/mythicplus/builds"
+	definitions "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/definitions"
+	models "wowperf/internal/services/warcraftlogs/mythicplus/builds/temporal/workflows/models"
+)
+
+// ReportRetentionWorkflow implements the report retention workflow
+type ReportRetentionWorkflow struct{}
+
+// NewReportRetentionWorkflow creates a new instance of the report retention workflow
+func NewReportRetentionWorkflow() definitions.ReportRetentionWorkflow {
+	return &ReportRetentionWorkflow{}
+}
+
+// Execute runs the report retention workflow
+// Each policy archives its data type by batches until no report is due or MaxBatches is reached.
+func (w *ReportRetentionWorkflow) Execute(ctx workflow.Context, params models.ReportRetentionWorkflowParams) (*models.ReportRetentionWorkflowResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting report retention workflow",
+		"policyCount", len(params.Policies),
+		"batchSize", params.BatchSize)
+
+	// Initialize the result
+	result := &models.ReportRetentionWorkflowResult{
+		StartedAt: workflow.Now(ctx),
+		BatchID:   params.BatchID,
+	}
+
+	// Validate the parameters
+	if len(params.Policies) == 0 {
+		return nil, fmt.Errorf("no retention policies found in parameters")
+	}
+
+	// Generate a unique ID for the workflow
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	workflowStateID := fmt.Sprintf("report-retention-%s", workflowID)
+
+	// Options for the state management activities
+	stateOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 1.5,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	stateCtx := workflow.WithActivityOptions(ctx, stateOpts)
+
+	// Create the initial workflow state
+	err := workflow.ExecuteActivity(stateCtx, definitions.CreateWorkflowStateActivity, &warcraftlogsBuilds.WorkflowState{
+		ID:              workflowStateID,
+		WorkflowType:    "report-retention",
+		StartedAt:       workflow.Now(ctx),
+		Status:          "running",
+		ItemsProcessed:  0,
+		LastProcessedID: "",
+		CreatedAt:       workflow.Now(ctx),
+		UpdatedAt:       workflow.Now(ctx),
+	}).Get(ctx, nil)
+
+	if err != nil {
+		logger.Error("Failed to create workflow state", "error", err)
+		// Continue execution even if state tracking fails
+	}
+
+	// Options for the archive activities
+	activityOpts := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour,
+		HeartbeatTimeout:    time.Minute * 5,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second * time.Duration(params.RetryDelay.Seconds()),
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute * 10,
+			MaximumAttempts:    int32(params.RetryAttempts),
+		},
+	}
+	activityCtx := workflow.WithActivityOptions(ctx, activityOpts)
+
+	for _, policy := range params.Policies {
+		logger.Info("Applying retention policy",
+			"dataType", policy.DataType,
+			"maxAge", policy.MaxAge)
+
+		for batch := int32(0); params.MaxBatches <= 0 || batch < params.MaxBatches; batch++ {
+			var batchResult models.ReportRetentionWorkflowResult
+			err := workflow.ExecuteActivity(activityCtx,
+				definitions.ArchiveReportsActivity,
+				policy,
+				params.BatchSize,
+			).Get(ctx, &batchResult)
+
+			if err != nil {
+				logger.Error("Failed to archive reports",
+					"dataType", policy.DataType,
+					"error", err)
+
+				// Update workflow state with error
+				workflowState := &warcraftlogsBuilds.WorkflowState{
+					ID:           workflowStateID,
+					ErrorMessage: fmt.Sprintf("Error archiving %s: %v", policy.DataType, err),
+					UpdatedAt:    workflow.Now(ctx),
+				}
+				_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+				// Continue with the next policy on error
+				break
+			}
+
+			result.ReportsArchived += batchResult.ReportsArchived
+			result.ReportsFailed += batchResult.ReportsFailed
+			result.BytesArchived += batchResult.BytesArchived
+			result.BytesCompressed += batchResult.BytesCompressed
+
+			// Update the workflow state with progress
+			workflowState := &warcraftlogsBuilds.WorkflowState{
+				ID:              workflowStateID,
+				LastProcessedID: policy.DataType,
+				ItemsProcessed:  int(result.ReportsArchived),
+				UpdatedAt:       workflow.Now(ctx),
+			}
+			_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+			// A partial batch means no report is left, a batch without progress only has failing reports
+			if batchResult.ReportsArchived == 0 || batchResult.ReportsArchived+batchResult.ReportsFailed < params.BatchSize {
+				break
+			}
+		}
+	}
+
+	// Finalize the result
+	result.CompletedAt = workflow.Now(ctx)
+
+	// Complete the workflow state
+	workflowState := &warcraftlogsBuilds.WorkflowState{
+		ID:             workflowStateID,
+		Status:         "completed",
+		CompletedAt:    workflow.Now(ctx),
+		ItemsProcessed: int(result.ReportsArchived),
+		UpdatedAt:      workflow.Now(ctx),
+	}
+	_ = workflow.ExecuteActivity(stateCtx, definitions.UpdateWorkflowStateActivity, workflowState).Get(ctx, nil)
+
+	logger.Info("Report retention workflow completed",
+		"reportsArchived", result.ReportsArchived,
+		"reportsFailed", result.ReportsFailed,
+		"bytesArchived", result.BytesArchived,
+		"bytesCompressed", result.BytesCompressed,
+		"duration", result.CompletedAt.Sub(result.StartedAt))
+
+	return result, nil
+}
@@ -34,8 +34,53 @@ func (r *PlayerRankingsRepository) DeleteExistingRankings(ctx context.Context) e
 	return r.db.WithContext(ctx).Exec("DELETE FROM player_rankings").Error
 }
 
+// ReplaceRankings replaces the rankings and writes them as a new snapshot in a single transaction
+// The rankings are linked to the player identities before the snapshot is written.
+// A failed update keeps the previous rankings, and an empty update never replaces them.
+func (r *PlayerRankingsRepository) ReplaceRankings(ctx context.Context, rankings []playerRankingModels.PlayerRanking, source string) (*playerRankingModels.PlayerRankingsSnapshot, error) {
+	if len(rankings) == 0 {
+		return nil, fmt.Errorf("no rankings to replace the current rankings")
+	}
+
+	var snapshot *playerRankingModels.PlayerRankingsSnapshot
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		log.Println("Replacing existing rankings")
+		if err := tx.Exec("DELETE FROM player_rankings").Error; err != nil {
+			return fmt.Errorf("failed to delete existing rankings: %w", err)
+		}
+
+		if err := storeRankingsByBatches(tx, rankings); err != nil {
+			return err
+		}
+
+		// Link the identities before the snapshot so its leaderboard groups players by identity
+		// A failed link is rolled back to its savepoint, the rankings stay usable without identities
+		if err := tx.Transaction(func(linkTx *gorm.DB) error {
+			_, err := linkPlayerIdentities(linkTx)
+			return err
+		}); err != nil {
+			log.Printf("Failed to link rankings to player identities: %v", err)
+		}
+
+		var err error
+		snapshot, err = playerRankingModels.CreateRankingsSnapshot(tx, source)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Rankings snapshot %d created with %d rankings", snapshot.ID, snapshot.RankingCount)
+	return snapshot, nil
+}
+
 // StoreRankingsByBatches inserts rankings in batches
 func (r *PlayerRankingsRepository) StoreRankingsByBatches(ctx context.Context, rankings []playerRankingModels.PlayerRanking) error {
+	return storeRankingsByBatches(r.db.WithContext(ctx), rankings)
+}
+
+// storeRankingsByBatches inserts rankings in batches with the given connection or transaction
+func storeRankingsByBatches(db *gorm.DB, rankings []playerRankingModels.PlayerRanking) error {
 	if len(rankings) == 0 {
 		log.Println("No rankings to store")
 		return nil
@@ -106,7 +151,7 @@ func (r *PlayerRankingsRepository) StoreRankingsByBatches(ctx context.Context, r
 		}
 
 		query := baseSQL + strings.Join(valueStrings, ",")
-		if err := r.db.WithContext(ctx).Exec(query, valueArgs...).Error; err != nil {
+		if err := db.Exec(query, valueArgs...).Error; err != nil {
 			return fmt.Errorf("failed to insert batch %d: %w", i+1, err)
 		}
 
@@ -116,14 +161,49 @@ func (r *PlayerRankingsRepository) StoreRankingsByBatches(ctx context.Context, r
 	return nil
 }
 
+// LinkPlayerIdentities links the rankings to the identity currently using the name and realm of their player
+// The identities are resolved from the player builds, the rankings of unknown players stay without identity.
+func (r *PlayerRankingsRepository) LinkPlayerIdentities(ctx context.Context) (int64, error) {
+	return linkPlayerIdentities(r.db.WithContext(ctx))
+}
+
+// linkPlayerIdentities links the rankings to the player identities with the given connection or transaction
+func linkPlayerIdentities(db *gorm.DB) (int64, error) {
+	result := db.Exec(`
+	UPDATE player_rankings pr
+	SET player_identity_id = lpa.player_identity_id
+	FROM latest_player_aliases lpa
+	WHERE pr.player_identity_id IS NULL
+		AND lpa.name_key = LOWER(pr.name)
+		AND lpa.server_key = LOWER(REGEXP_REPLACE(pr.server_name, '[^[:alnum:]]', '', 'g'))
+		AND lpa.region = UPPER(pr.server_region)`)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to link rankings to player identities: %w", result.Error)
+	}
+
+	// The latest snapshot keeps the identities of the rankings it copied
+	if err := db.Exec(`
+	UPDATE player_rankings_history prh
+	SET player_identity_id = lpa.player_identity_id
+	FROM latest_player_aliases lpa
+	WHERE prh.snapshot_id = (SELECT MAX(id) FROM player_rankings_snapshots WHERE deleted_at IS NULL)
+		AND prh.player_identity_id IS NULL
+		AND lpa.name_key = LOWER(prh.name)
+		AND lpa.server_key = LOWER(REGEXP_REPLACE(prh.server_name, '[^[:alnum:]]', '', 'g'))
+		AND lpa.region = UPPER(prh.server_region)`).Error; err != nil {
+		return result.RowsAffected, fmt.Errorf("failed to link latest rankings snapshot to player identities: %w", err)
+	}
+
+	log.Printf("Linked %d rankings to player identities", result.RowsAffected)
+	return result.RowsAffected, nil
+}
+
 // CalculateDailySpecMetrics calculates daily metrics for all specializations
 func (r *PlayerRankingsRepository) CalculateDailySpecMetrics(ctx context.Context) error {
 	// Unique date for the entire processing
 	processingDate := time.Now().Truncate(24 * time.Hour)
 	log.Printf("Calculating spec metrics for date: %s", processingDate.Format("2006-01-02"))
 
-	// Fixed number of dungeons (always 8)
-	const totalDungeonCount = 8
 	// Number of top players to consider for averages
 	const topPlayersCount = 10
 
@@ -232,15 +312,15 @@ func (r *PlayerRankingsRepository) CalculateDailySpecMetrics(ctx context.Context
 
 		var playerScores []PlayerGlobalScore
 
-		// SQL query to calculate global scores by player
-		// Filter to keep only players who have completed all 8 dungeons
+		// SQL query to calculate global scores by player, a player renamed or transferred is counted once through its identity
+		// Filter to keep only players who have completed every dungeon of the active season
 		if err := tx.Raw(`
 			SELECT 
     spec, 
     class, 
     role, 
-    name,
-    server_name,
+    MAX(name) AS name,
+    MAX(server_name) AS server_name,
     SUM(best_score) AS total_score,
     AVG(avg_key_level) AS avg_key_level,
     MAX(max_key_level) AS max_key_level,
@@ -251,8 +331,9 @@ FROM (
         spec, 
         class, 
         role, 
-        name,
-        server_name,
+        COALESCE(player_identity_id::text, CONCAT(name, '-', server_name, '-', server_region)) AS player_key,
+        MAX(name) AS name,
+        MAX(server_name) AS server_name,
         dungeon_id,
         MAX(score) as best_score,
         AVG(hard_mode_level) as avg_key_level,
@@ -260,15 +341,15 @@ FROM (
         MIN(hard_mode_level) as min_key_level
     FROM player_rankings
     WHERE server_region != 'CN'
-    GROUP BY spec, class, role, name, server_name, dungeon_id
+    GROUP BY spec, class, role, player_key, dungeon_id
 ) AS best_scores
-GROUP BY spec, class, role, name, server_name
-HAVING COUNT(DISTINCT dungeon_id) = 8
+GROUP BY spec, class, role, player_key
+HAVING COUNT(DISTINCT dungeon_id) = active_season_dungeon_count()
 		`).Scan(&playerScores).Error; err != nil {
 			return fmt.Errorf("error calculating player global scores: %w", err)
 		}
 
-		log.Printf("Calculated global scores for %d players who completed all 8 dungeons", len(playerScores))
+		log.Printf("Calculated global scores for %d players who completed every dungeon of the active season", len(playerScores))
 
 		// Group players by spec/class/role
 		specPlayerMap := make(map[string][]PlayerGlobalScore)
@@ -190,17 +190,8 @@ func (a *PlayerRankingsActivity) FetchAllDungeonRankings(
 
 	// Stockage direct en base de données
 	if len(allRankings) > 0 {
-		// D'abord supprimer les classements existants
-		if err := a.repository.DeleteExistingRankings(ctx); err != nil {
-			logger.Error("Failed to delete existing rankings", "error", err)
-			return nil, temporal.NewApplicationError(
-				fmt.Sprintf("Failed to delete existing rankings: %v", err),
-				"DB_ERROR",
-			)
-		}
-
-		// Stocker les nouveaux classements
-		if err := a.repository.StoreRankingsByBatches(ctx, allRankings); err != nil {
+		// Remplacer les classements existants par un nouveau snapshot
+		if _, err := a.repository.ReplaceRankings(ctx, allRankings, "workflow"); err != nil {
 			logger.Error("Failed to store rankings", "error", err)
 			return nil, temporal.NewApplicationError(
 				fmt.Sprintf("Failed to store rankings: %v", err),
@@ -225,7 +216,7 @@ func (a *PlayerRankingsActivity) FetchAllDungeonRankings(
 }
 
 // StoreRankings stores the rankings in the database
-// It first deletes existing rankings and then stores new ones in batches
+// It replaces the existing rankings and writes them as a new snapshot in a single transaction
 // Corresponds to definitions.StoreRankingsActivity
 func (a *PlayerRankingsActivity) StoreRankings(
 	ctx context.Context,
@@ -234,17 +225,14 @@ func (a *PlayerRankingsActivity) StoreRankings(
 	logger := activity.GetLogger(ctx)
 	logger.Info("Starting rankings storage", "rankingsCount", len(rankings))
 
-	// Delete existing rankings
-	if err := a.repository.DeleteExistingRankings(ctx); err != nil {
-		logger.Error("Failed to delete existing rankings", "error", err)
-		return temporal.NewApplicationError(
-			fmt.Sprintf("Failed to delete existing rankings: %v", err),
-			"DB_ERROR",
-		)
+	// Keep the current rankings when there is nothing to replace them with
+	if len(rankings) == 0 {
+		logger.Warn("No rankings to store, keeping the current rankings")
+		return nil
 	}
 
-	// Store new rankings
-	if err := a.repository.StoreRankingsByBatches(ctx, rankings); err != nil {
+	// Replace the existing rankings and write them as a new snapshot
+	if _, err := a.repository.ReplaceRankings(ctx, rankings, "workflow"); err != nil {
 		logger.Error("Failed to store rankings", "error", err)
 		return temporal.NewApplicationError(
 			fmt.Sprintf("Failed to store rankings: %v", err),
@@ -109,6 +109,46 @@ func (s *WarcraftLogsClientService) MakeRequest(ctx context.Context, query strin
 	if query == RateLimitQuery {
 		return s.Client.MakeGraphQLRequest(query, variables)
 	}
+
+	if err := s.checkRateLimit(); err != nil {
+		return nil, err
+	}
+
+	// Make the actual request
+	response, err := s.Client.MakeGraphQLRequest(query, variables)
+	if err != nil {
+		return nil, s.quotaError(err)
+	}
+
+	return response, nil
+}
+
+// MakeBatchRequest performs a rate-limited request for a batched document
+// The errors of a single lookup are returned in the response with the data of the other lookups.
+func (s *WarcraftLogsClientService) MakeBatchRequest(ctx context.Context, query string) (*GraphQLResponse, error) {
+	if query == "" {
+		return nil, fmt.Errorf("empty query not allowed")
+	}
+
+	if err := s.checkRateLimit(); err != nil {
+		return nil, err
+	}
+
+	response, err := s.Client.MakeGraphQLBatchRequest(query)
+	if err != nil {
+		return nil, s.quotaError(err)
+	}
+
+	return response, nil
+}
+
+// NewQueryBatcher creates a query batcher sending its documents through the rate-limited client
+func (s *WarcraftLogsClientService) NewQueryBatcher(budget int) *QueryBatcher {
+	return NewQueryBatcher(s.MakeBatchRequest, budget)
+}
+
+// checkRateLimit refreshes the rate limit info if needed and checks that enough points are left
+func (s *WarcraftLogsClientService) checkRateLimit() error {
 	// Check if we need to update rate limit info
 	shouldCheck := false
 	s.rateLimiter.mu.RLock()
@@ -148,25 +188,24 @@ func (s *WarcraftLogsClientService) MakeRequest(ctx context.Context, query strin
 			RemainingPoints: remaining,
 			ResetIn:         resetIn,
 		}
-		return nil, warcraftlogsTypes.NewQuotaExceededError(info)
+		return warcraftlogsTypes.NewQuotaExceededError(info)
 	}
 
-	// Make the actual request
-	response, err := s.Client.MakeGraphQLRequest(query, variables)
-	if err != nil {
-		if warcraftlogsTypes.IsRateLimit(err) {
-			s.rateLimiter.mu.RLock()
-			info := &warcraftlogsTypes.RateLimitInfo{
-				RemainingPoints: s.rateLimiter.maxPoints - s.rateLimiter.usedPoints,
-				ResetIn:         time.Until(s.rateLimiter.resetTime),
-			}
-			s.rateLimiter.mu.RUnlock()
-			return nil, warcraftlogsTypes.NewQuotaExceededError(info)
+	return nil
+}
+
+// quotaError converts a rate limit error of the API into a quota exceeded error
+func (s *WarcraftLogsClientService) quotaError(err error) error {
+	if warcraftlogsTypes.IsRateLimit(err) {
+		s.rateLimiter.mu.RLock()
+		info := &warcraftlogsTypes.RateLimitInfo{
+			RemainingPoints: s.rateLimiter.maxPoints - s.rateLimiter.usedPoints,
+			ResetIn:         time.Until(s.rateLimiter.resetTime),
 		}
-		return nil, err
+		s.rateLimiter.mu.RUnlock()
+		return warcraftlogsTypes.NewQuotaExceededError(info)
 	}
-
-	return response, nil
+	return err
 }
 
 // GetRateLimitInfo returns current rate limit information for monitoring